@@ -0,0 +1,422 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	vgcrypto "code.vegaprotocol.io/shared/libs/crypto"
+	vgfs "code.vegaprotocol.io/shared/libs/fs"
+	"code.vegaprotocol.io/vegawallet/network"
+	"code.vegaprotocol.io/vegawallet/service"
+	"code.vegaprotocol.io/vegawallet/wallet"
+)
+
+// LatestVersion is the version of the backup format produced by Create.
+const LatestVersion = 1
+
+const (
+	// SkipOnConflict keeps the existing element, and ignores the one from the
+	// backup.
+	SkipOnConflict = "skip"
+	// OverwriteOnConflict replaces the existing element by the one from the
+	// backup.
+	OverwriteOnConflict = "overwrite"
+	// RenameOnConflict restores the element from the backup under a new name.
+	// The service keys can't be renamed, so they are skipped.
+	RenameOnConflict = "rename"
+)
+
+// ConflictStrategies lists the supported ways to handle an element from the
+// backup that already exists.
+var ConflictStrategies = []string{
+	SkipOnConflict,
+	OverwriteOnConflict,
+	RenameOnConflict,
+}
+
+const (
+	ActionCreated     = "created"
+	ActionSkipped     = "skipped"
+	ActionOverwritten = "overwritten"
+	ActionRenamed     = "renamed"
+)
+
+const renameSuffix = "restored"
+
+type WalletStore interface {
+	ListWallets() ([]string, error)
+	GetRawWallet(name string) ([]byte, error)
+	SaveRawWallet(name string, buf []byte) error
+}
+
+type ServiceStore interface {
+	RSAKeysExists() (bool, error)
+	GetRsaKeys() (*service.RSAKeys, error)
+	SaveRSAKeys(*service.RSAKeys) error
+}
+
+// archive is the content of the backup file, once decrypted. The wallets are
+// kept as stored on disk, meaning they are still encrypted with their own
+// passphrase.
+type archive struct {
+	Version     uint32             `json:"version"`
+	CreatedAt   time.Time          `json:"createdAt"`
+	Wallets     []archivedWallet   `json:"wallets"`
+	Networks    []*network.Network `json:"networks"`
+	ServiceKeys *service.RSAKeys   `json:"serviceKeys,omitempty"`
+}
+
+type archivedWallet struct {
+	Name    string `json:"name"`
+	Content []byte `json:"content"`
+}
+
+type CreateRequest struct {
+	FilePath           string
+	Passphrase         string
+	IncludeServiceKeys bool
+	Force              bool
+}
+
+type CreateResponse struct {
+	FilePath           string    `json:"filePath"`
+	CreatedAt          time.Time `json:"createdAt"`
+	Wallets            []string  `json:"wallets"`
+	Networks           []string  `json:"networks"`
+	IncludeServiceKeys bool      `json:"includeServiceKeys"`
+}
+
+// Create gathers the wallets, the networks and, optionally, the service keys
+// in a single file encrypted with the backup passphrase.
+func Create(wStore WalletStore, nStore network.Store, sStore ServiceStore, req *CreateRequest) (*CreateResponse, error) {
+	exists, err := vgfs.PathExists(req.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't verify backup file existence: %w", err)
+	}
+	if exists && !req.Force {
+		return nil, ErrBackupFileAlreadyExists
+	}
+
+	a := &archive{
+		Version:   LatestVersion,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	walletNames, err := wStore.ListWallets()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list the wallets: %w", err)
+	}
+	a.Wallets = make([]archivedWallet, 0, len(walletNames))
+	for _, name := range walletNames {
+		content, err := wStore.GetRawWallet(name)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read wallet %s: %w", name, err)
+		}
+		a.Wallets = append(a.Wallets, archivedWallet{
+			Name:    name,
+			Content: content,
+		})
+	}
+
+	networkNames, err := nStore.ListNetworks()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list the networks: %w", err)
+	}
+	a.Networks = make([]*network.Network, 0, len(networkNames))
+	for _, name := range networkNames {
+		net, err := nStore.GetNetwork(name)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read network %s: %w", name, err)
+		}
+		a.Networks = append(a.Networks, net)
+	}
+
+	if req.IncludeServiceKeys {
+		keysExist, err := sStore.RSAKeysExists()
+		if err != nil {
+			return nil, fmt.Errorf("couldn't verify service keys existence: %w", err)
+		}
+		if !keysExist {
+			return nil, ErrServiceKeysDoNotExist
+		}
+
+		a.ServiceKeys, err = sStore.GetRsaKeys()
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read service keys: %w", err)
+		}
+	}
+
+	buf, err := json.Marshal(a)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't marshal backup: %w", err)
+	}
+
+	encBuf, err := vgcrypto.Encrypt(buf, req.Passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't encrypt backup: %w", err)
+	}
+
+	if err := vgfs.WriteFile(req.FilePath, encBuf); err != nil {
+		return nil, fmt.Errorf("couldn't write backup file at %s: %w", req.FilePath, err)
+	}
+
+	return &CreateResponse{
+		FilePath:           req.FilePath,
+		CreatedAt:          a.CreatedAt,
+		Wallets:            walletNames,
+		Networks:           networkNames,
+		IncludeServiceKeys: req.IncludeServiceKeys,
+	}, nil
+}
+
+type RestoreRequest struct {
+	FilePath   string
+	Passphrase string
+	OnConflict string
+	DryRun     bool
+}
+
+type RestoreResponse struct {
+	CreatedAt   time.Time       `json:"createdAt"`
+	DryRun      bool            `json:"dryRun"`
+	Wallets     []RestoredEntry `json:"wallets"`
+	Networks    []RestoredEntry `json:"networks"`
+	ServiceKeys *RestoredEntry  `json:"serviceKeys,omitempty"`
+}
+
+// RestoredEntry describes what is done, or would be done on a dry run, with
+// an element of the backup.
+type RestoredEntry struct {
+	Name       string `json:"name"`
+	RestoredAs string `json:"restoredAs,omitempty"`
+	Action     string `json:"action"`
+}
+
+// Restore decrypts the backup file and saves its content in the stores. The
+// elements that already exist are handled according to the conflict strategy.
+// On a dry run, nothing is saved, and the response describes what would be
+// done.
+//
+// The whole backup is verified, and the name of every element is resolved,
+// before saving anything, so an invalid backup doesn't leave a partial
+// restore behind.
+func Restore(wStore WalletStore, nStore network.Store, sStore ServiceStore, req *RestoreRequest) (*RestoreResponse, error) {
+	if err := ValidateConflictStrategy(req.OnConflict); err != nil {
+		return nil, err
+	}
+
+	a, err := readArchive(req.FilePath, req.Passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateArchive(a); err != nil {
+		return nil, err
+	}
+
+	resp := &RestoreResponse{
+		CreatedAt: a.CreatedAt,
+		DryRun:    req.DryRun,
+		Wallets:   make([]RestoredEntry, 0, len(a.Wallets)),
+		Networks:  make([]RestoredEntry, 0, len(a.Networks)),
+	}
+
+	existingWallets, err := wStore.ListWallets()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list the wallets: %w", err)
+	}
+	takenWalletNames := toSet(existingWallets)
+	for _, w := range a.Wallets {
+		entry := resolveConflict(w.Name, takenWalletNames, req.OnConflict, wallet.MaxNameLength)
+		resp.Wallets = append(resp.Wallets, entry)
+	}
+
+	existingNetworks, err := nStore.ListNetworks()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list the networks: %w", err)
+	}
+	takenNetworkNames := toSet(existingNetworks)
+	for _, net := range a.Networks {
+		entry := resolveConflict(net.Name, takenNetworkNames, req.OnConflict, network.MaxNameLength)
+		resp.Networks = append(resp.Networks, entry)
+	}
+
+	if a.ServiceKeys != nil {
+		keysExist, err := sStore.RSAKeysExists()
+		if err != nil {
+			return nil, fmt.Errorf("couldn't verify service keys existence: %w", err)
+		}
+
+		entry := RestoredEntry{
+			Name:   "service keys",
+			Action: ActionCreated,
+		}
+		if keysExist {
+			if req.OnConflict == OverwriteOnConflict {
+				entry.Action = ActionOverwritten
+			} else {
+				entry.Action = ActionSkipped
+			}
+		}
+		resp.ServiceKeys = &entry
+	}
+
+	if req.DryRun {
+		return resp, nil
+	}
+
+	for i, w := range a.Wallets {
+		entry := resp.Wallets[i]
+		if entry.Action == ActionSkipped {
+			continue
+		}
+		if err := wStore.SaveRawWallet(entry.RestoredAs, w.Content); err != nil {
+			return nil, fmt.Errorf("couldn't restore wallet %s: %w", w.Name, err)
+		}
+	}
+
+	for i, net := range a.Networks {
+		entry := resp.Networks[i]
+		if entry.Action == ActionSkipped {
+			continue
+		}
+		net.Name = entry.RestoredAs
+		if err := nStore.SaveNetwork(net); err != nil {
+			return nil, fmt.Errorf("couldn't restore network %s: %w", entry.Name, err)
+		}
+	}
+
+	if resp.ServiceKeys != nil && resp.ServiceKeys.Action != ActionSkipped {
+		if err := sStore.SaveRSAKeys(a.ServiceKeys); err != nil {
+			return nil, fmt.Errorf("couldn't restore service keys: %w", err)
+		}
+	}
+
+	return resp, nil
+}
+
+func ValidateConflictStrategy(strategy string) error {
+	for _, s := range ConflictStrategies {
+		if strategy == s {
+			return nil
+		}
+	}
+	return NewUnsupportedConflictStrategyError(strategy)
+}
+
+func readArchive(filePath, passphrase string) (*archive, error) {
+	exists, err := vgfs.FileExists(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't verify backup file existence: %w", err)
+	}
+	if !exists {
+		return nil, ErrBackupFileDoesNotExist
+	}
+
+	buf, err := vgfs.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read backup file at %s: %w", filePath, err)
+	}
+
+	decBuf, err := vgcrypto.Decrypt(buf, passphrase)
+	if err != nil {
+		if err.Error() == "cipher: message authentication failed" {
+			return nil, ErrWrongPassphrase
+		}
+		return nil, fmt.Errorf("couldn't decrypt backup: %w", err)
+	}
+
+	versioned := &struct {
+		Version uint32 `json:"version"`
+	}{}
+	if err := json.Unmarshal(decBuf, versioned); err != nil {
+		return nil, fmt.Errorf("couldn't unmarshal backup version: %w", err)
+	}
+	if versioned.Version != LatestVersion {
+		return nil, NewUnsupportedBackupVersionError(versioned.Version)
+	}
+
+	a := &archive{}
+	if err := json.Unmarshal(decBuf, a); err != nil {
+		return nil, fmt.Errorf("couldn't unmarshal backup: %w", err)
+	}
+
+	return a, nil
+}
+
+// validateArchive verifies the names coming from the backup, as they are
+// used to name the files the elements are saved in.
+func validateArchive(a *archive) error {
+	for _, w := range a.Wallets {
+		if err := wallet.ValidateName(w.Name); err != nil {
+			return fmt.Errorf("backup contains an invalid wallet name %q: %w", w.Name, err)
+		}
+	}
+
+	for _, net := range a.Networks {
+		if net == nil {
+			return ErrBackupContainsEmptyNetwork
+		}
+		if err := network.ValidateName(net.Name); err != nil {
+			return fmt.Errorf("backup contains an invalid network name %q: %w", net.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// resolveConflict decides what to do with the element named after `name`,
+// and registers the name it's restored under as taken. A new name never
+// exceeds `maxLength` characters.
+func resolveConflict(name string, taken map[string]struct{}, strategy string, maxLength int) RestoredEntry {
+	entry := RestoredEntry{
+		Name:       name,
+		RestoredAs: name,
+		Action:     ActionCreated,
+	}
+
+	if _, exists := taken[name]; exists {
+		switch strategy {
+		case SkipOnConflict:
+			return RestoredEntry{
+				Name:   name,
+				Action: ActionSkipped,
+			}
+		case OverwriteOnConflict:
+			entry.Action = ActionOverwritten
+		case RenameOnConflict:
+			newName := withSuffix(name, renameSuffix, maxLength)
+			for i := 2; ; i++ {
+				if _, exists := taken[newName]; !exists {
+					break
+				}
+				newName = withSuffix(name, fmt.Sprintf("%s-%d", renameSuffix, i), maxLength)
+			}
+			entry.RestoredAs = newName
+			entry.Action = ActionRenamed
+		}
+	}
+
+	taken[entry.RestoredAs] = struct{}{}
+	return entry
+}
+
+// withSuffix appends the suffix to the name, shortening the name if needed
+// so the result doesn't exceed `maxLength` characters.
+func withSuffix(name, suffix string, maxLength int) string {
+	runes := []rune(name)
+	if maxNameLength := maxLength - len([]rune(suffix)) - 1; len(runes) > maxNameLength {
+		name = strings.TrimSpace(string(runes[:maxNameLength]))
+	}
+	return fmt.Sprintf("%s-%s", name, suffix)
+}
+
+func toSet(names []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		set[name] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,472 @@
+package backup_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	vgcrypto "code.vegaprotocol.io/shared/libs/crypto"
+	vgrand "code.vegaprotocol.io/shared/libs/rand"
+	vgtest "code.vegaprotocol.io/shared/libs/test"
+	"code.vegaprotocol.io/shared/paths"
+	"code.vegaprotocol.io/vegawallet/backup"
+	"code.vegaprotocol.io/vegawallet/network"
+	netstore "code.vegaprotocol.io/vegawallet/network/store/v1"
+	"code.vegaprotocol.io/vegawallet/service"
+	svcstore "code.vegaprotocol.io/vegawallet/service/store/v1"
+	"code.vegaprotocol.io/vegawallet/wallet"
+	wstorev1 "code.vegaprotocol.io/vegawallet/wallet/store/v1"
+	"code.vegaprotocol.io/vegawallet/wallets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackup(t *testing.T) {
+	t.Run("Restoring a backup in an empty home succeeds", testRestoringBackupInEmptyHomeSucceeds)
+	t.Run("Creating a backup over an existing file fails", testCreatingBackupOverExistingFileFails)
+	t.Run("Restoring a backup with wrong passphrase fails", testRestoringBackupWithWrongPassphraseFails)
+	t.Run("Restoring a backup with unsupported conflict strategy fails", testRestoringBackupWithUnsupportedConflictStrategyFails)
+	t.Run("Restoring a backup with dry run does not change anything", testRestoringBackupWithDryRunDoesNotChangeAnything)
+	t.Run("Restoring a backup skips conflicting elements", testRestoringBackupSkipsConflictingElements)
+	t.Run("Restoring a backup overwrites conflicting elements", testRestoringBackupOverwritesConflictingElements)
+	t.Run("Restoring a backup renames conflicting elements", testRestoringBackupRenamesConflictingElements)
+	t.Run("Restoring a backup renames conflicting elements within the name length limit", testRestoringBackupRenamesConflictingElementsWithinNameLengthLimit)
+	t.Run("Restoring a backup with invalid network name fails without restoring anything", testRestoringBackupWithInvalidNetworkNameFailsWithoutRestoringAnything)
+	t.Run("Creating a backup includes the legacy wallets not migrated yet", testCreatingBackupIncludesLegacyWalletsNotMigratedYet)
+}
+
+func testRestoringBackupInEmptyHomeSucceeds(t *testing.T) {
+	// given
+	source := newTestHome(t)
+	source.saveWallet(t, "my-wallet", "wallet-content")
+	source.saveNetwork(t, "my-network", 8000)
+	passphrase := vgrand.RandomStr(5)
+	backupPath := filepath.Join(t.TempDir(), "vegawallet.backup")
+
+	// when
+	createResp, err := source.create(backupPath, passphrase, true)
+
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, createResp)
+	assert.Equal(t, []string{"my-wallet"}, createResp.Wallets)
+	assert.Equal(t, []string{"my-network"}, createResp.Networks)
+	assert.True(t, createResp.IncludeServiceKeys)
+	vgtest.AssertFileAccess(t, backupPath)
+
+	// given
+	destination := newTestHome(t)
+
+	// when
+	restoreResp, err := destination.restore(backupPath, passphrase, backup.SkipOnConflict, false)
+
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, restoreResp)
+	assert.Equal(t, []backup.RestoredEntry{{
+		Name:       "my-wallet",
+		RestoredAs: "my-wallet",
+		Action:     backup.ActionCreated,
+	}}, restoreResp.Wallets)
+	assert.Equal(t, []backup.RestoredEntry{{
+		Name:       "my-network",
+		RestoredAs: "my-network",
+		Action:     backup.ActionCreated,
+	}}, restoreResp.Networks)
+	require.NotNil(t, restoreResp.ServiceKeys)
+	assert.Equal(t, backup.ActionCreated, restoreResp.ServiceKeys.Action)
+	assert.Equal(t, "wallet-content", destination.rawWallet(t, "my-wallet"))
+	assert.Equal(t, 8000, destination.network(t, "my-network").Port)
+	assert.Equal(t, source.serviceKeys(t), destination.serviceKeys(t))
+}
+
+func testCreatingBackupOverExistingFileFails(t *testing.T) {
+	// given
+	source := newTestHome(t)
+	passphrase := vgrand.RandomStr(5)
+	backupPath := filepath.Join(t.TempDir(), "vegawallet.backup")
+	_, err := source.create(backupPath, passphrase, false)
+	require.NoError(t, err)
+
+	// when
+	resp, err := source.create(backupPath, passphrase, false)
+
+	// then
+	require.ErrorIs(t, err, backup.ErrBackupFileAlreadyExists)
+	assert.Nil(t, resp)
+}
+
+func testRestoringBackupWithWrongPassphraseFails(t *testing.T) {
+	// given
+	source := newTestHome(t)
+	source.saveWallet(t, "my-wallet", "wallet-content")
+	backupPath := filepath.Join(t.TempDir(), "vegawallet.backup")
+	_, err := source.create(backupPath, vgrand.RandomStr(5), false)
+	require.NoError(t, err)
+	destination := newTestHome(t)
+
+	// when
+	resp, err := destination.restore(backupPath, vgrand.RandomStr(5), backup.SkipOnConflict, false)
+
+	// then
+	require.ErrorIs(t, err, backup.ErrWrongPassphrase)
+	assert.Nil(t, resp)
+	assert.Empty(t, destination.wallets(t))
+}
+
+func testRestoringBackupWithUnsupportedConflictStrategyFails(t *testing.T) {
+	// given
+	source := newTestHome(t)
+	passphrase := vgrand.RandomStr(5)
+	backupPath := filepath.Join(t.TempDir(), "vegawallet.backup")
+	_, err := source.create(backupPath, passphrase, false)
+	require.NoError(t, err)
+
+	// when
+	resp, err := source.restore(backupPath, passphrase, "merge", false)
+
+	// then
+	require.ErrorIs(t, err, backup.NewUnsupportedConflictStrategyError("merge"))
+	assert.Nil(t, resp)
+}
+
+func testRestoringBackupWithDryRunDoesNotChangeAnything(t *testing.T) {
+	// given
+	source := newTestHome(t)
+	source.saveWallet(t, "my-wallet", "wallet-content")
+	source.saveNetwork(t, "my-network", 8000)
+	passphrase := vgrand.RandomStr(5)
+	backupPath := filepath.Join(t.TempDir(), "vegawallet.backup")
+	_, err := source.create(backupPath, passphrase, false)
+	require.NoError(t, err)
+	destination := newTestHome(t)
+	destination.saveWallet(t, "my-wallet", "other-wallet-content")
+
+	// when
+	resp, err := destination.restore(backupPath, passphrase, backup.RenameOnConflict, true)
+
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	assert.True(t, resp.DryRun)
+	assert.Equal(t, []backup.RestoredEntry{{
+		Name:       "my-wallet",
+		RestoredAs: "my-wallet-restored",
+		Action:     backup.ActionRenamed,
+	}}, resp.Wallets)
+	assert.Equal(t, []backup.RestoredEntry{{
+		Name:       "my-network",
+		RestoredAs: "my-network",
+		Action:     backup.ActionCreated,
+	}}, resp.Networks)
+	assert.Nil(t, resp.ServiceKeys)
+	assert.Equal(t, []string{"my-wallet"}, destination.wallets(t))
+	assert.Equal(t, "other-wallet-content", destination.rawWallet(t, "my-wallet"))
+	assert.Empty(t, destination.networks(t))
+}
+
+func testRestoringBackupSkipsConflictingElements(t *testing.T) {
+	// given
+	source := newTestHome(t)
+	source.saveWallet(t, "my-wallet", "wallet-content")
+	source.saveNetwork(t, "my-network", 8000)
+	passphrase := vgrand.RandomStr(5)
+	backupPath := filepath.Join(t.TempDir(), "vegawallet.backup")
+	_, err := source.create(backupPath, passphrase, true)
+	require.NoError(t, err)
+	destination := newTestHome(t)
+	destination.saveWallet(t, "my-wallet", "other-wallet-content")
+	destination.saveNetwork(t, "my-network", 9000)
+	destinationKeys := destination.serviceKeys(t)
+
+	// when
+	resp, err := destination.restore(backupPath, passphrase, backup.SkipOnConflict, false)
+
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, []backup.RestoredEntry{{
+		Name:   "my-wallet",
+		Action: backup.ActionSkipped,
+	}}, resp.Wallets)
+	assert.Equal(t, []backup.RestoredEntry{{
+		Name:   "my-network",
+		Action: backup.ActionSkipped,
+	}}, resp.Networks)
+	require.NotNil(t, resp.ServiceKeys)
+	assert.Equal(t, backup.ActionSkipped, resp.ServiceKeys.Action)
+	assert.Equal(t, "other-wallet-content", destination.rawWallet(t, "my-wallet"))
+	assert.Equal(t, 9000, destination.network(t, "my-network").Port)
+	assert.Equal(t, destinationKeys, destination.serviceKeys(t))
+}
+
+func testRestoringBackupOverwritesConflictingElements(t *testing.T) {
+	// given
+	source := newTestHome(t)
+	source.saveWallet(t, "my-wallet", "wallet-content")
+	source.saveNetwork(t, "my-network", 8000)
+	passphrase := vgrand.RandomStr(5)
+	backupPath := filepath.Join(t.TempDir(), "vegawallet.backup")
+	_, err := source.create(backupPath, passphrase, true)
+	require.NoError(t, err)
+	destination := newTestHome(t)
+	destination.saveWallet(t, "my-wallet", "other-wallet-content")
+	destination.saveNetwork(t, "my-network", 9000)
+
+	// when
+	resp, err := destination.restore(backupPath, passphrase, backup.OverwriteOnConflict, false)
+
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, backup.ActionOverwritten, resp.Wallets[0].Action)
+	assert.Equal(t, backup.ActionOverwritten, resp.Networks[0].Action)
+	require.NotNil(t, resp.ServiceKeys)
+	assert.Equal(t, backup.ActionOverwritten, resp.ServiceKeys.Action)
+	assert.Equal(t, []string{"my-wallet"}, destination.wallets(t))
+	assert.Equal(t, "wallet-content", destination.rawWallet(t, "my-wallet"))
+	assert.Equal(t, 8000, destination.network(t, "my-network").Port)
+	assert.Equal(t, source.serviceKeys(t), destination.serviceKeys(t))
+}
+
+func testRestoringBackupRenamesConflictingElements(t *testing.T) {
+	// given
+	source := newTestHome(t)
+	source.saveWallet(t, "my-wallet", "wallet-content")
+	source.saveNetwork(t, "my-network", 8000)
+	passphrase := vgrand.RandomStr(5)
+	backupPath := filepath.Join(t.TempDir(), "vegawallet.backup")
+	_, err := source.create(backupPath, passphrase, false)
+	require.NoError(t, err)
+	destination := newTestHome(t)
+	destination.saveWallet(t, "my-wallet", "other-wallet-content")
+	destination.saveWallet(t, "my-wallet-restored", "another-wallet-content")
+	destination.saveNetwork(t, "my-network", 9000)
+
+	// when
+	resp, err := destination.restore(backupPath, passphrase, backup.RenameOnConflict, false)
+
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, []backup.RestoredEntry{{
+		Name:       "my-wallet",
+		RestoredAs: "my-wallet-restored-2",
+		Action:     backup.ActionRenamed,
+	}}, resp.Wallets)
+	assert.Equal(t, []backup.RestoredEntry{{
+		Name:       "my-network",
+		RestoredAs: "my-network-restored",
+		Action:     backup.ActionRenamed,
+	}}, resp.Networks)
+	assert.Equal(t, "other-wallet-content", destination.rawWallet(t, "my-wallet"))
+	assert.Equal(t, "wallet-content", destination.rawWallet(t, "my-wallet-restored-2"))
+	assert.Equal(t, 9000, destination.network(t, "my-network").Port)
+	restoredNetwork := destination.network(t, "my-network-restored")
+	assert.Equal(t, "my-network-restored", restoredNetwork.Name)
+	assert.Equal(t, 8000, restoredNetwork.Port)
+}
+
+func testRestoringBackupRenamesConflictingElementsWithinNameLengthLimit(t *testing.T) {
+	// given
+	longName := strings.Repeat("a", wallet.MaxNameLength)
+	source := newTestHome(t)
+	source.saveWallet(t, longName, "wallet-content")
+	passphrase := vgrand.RandomStr(5)
+	backupPath := filepath.Join(t.TempDir(), "vegawallet.backup")
+	_, err := source.create(backupPath, passphrase, false)
+	require.NoError(t, err)
+	destination := newTestHome(t)
+	destination.saveWallet(t, longName, "other-wallet-content")
+
+	// when
+	resp, err := destination.restore(backupPath, passphrase, backup.RenameOnConflict, false)
+
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	expectedName := strings.Repeat("a", wallet.MaxNameLength-len("-restored")) + "-restored"
+	assert.Equal(t, []backup.RestoredEntry{{
+		Name:       longName,
+		RestoredAs: expectedName,
+		Action:     backup.ActionRenamed,
+	}}, resp.Wallets)
+	assert.NoError(t, wallet.ValidateName(expectedName))
+	assert.Equal(t, "wallet-content", destination.rawWallet(t, expectedName))
+}
+
+func testCreatingBackupIncludesLegacyWalletsNotMigratedYet(t *testing.T) {
+	// given
+	source := newTestHome(t)
+	legacyContent := strings.Repeat("legacy-wallet-content", 4)
+	legacyPath := filepath.Join(source.walletStore.GetWalletsPath(), "legacy-wallet")
+	require.NoError(t, os.WriteFile(legacyPath, []byte(legacyContent), 0o600))
+	passphrase := vgrand.RandomStr(5)
+	backupPath := filepath.Join(t.TempDir(), "vegawallet.backup")
+
+	// when
+	createResp, err := source.create(backupPath, passphrase, false)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"legacy-wallet"}, createResp.Wallets)
+	vgtest.AssertFileAccess(t, legacyPath)
+
+	// given
+	destination := newTestHome(t)
+
+	// when
+	_, err = destination.restore(backupPath, passphrase, backup.SkipOnConflict, false)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"legacy-wallet"}, destination.wallets(t))
+	assert.Equal(t, legacyContent, destination.rawWallet(t, "legacy-wallet"))
+}
+
+func testRestoringBackupWithInvalidNetworkNameFailsWithoutRestoringAnything(t *testing.T) {
+	// given
+	passphrase := vgrand.RandomStr(5)
+	backupPath := filepath.Join(t.TempDir(), "vegawallet.backup")
+	writeArchive(t, backupPath, passphrase, `{
+		"version": 1,
+		"wallets": [{"name": "my-wallet", "content": "d2FsbGV0LWNvbnRlbnQ="}],
+		"networks": [{"name": "../../escaped"}]
+	}`)
+	destination := newTestHome(t)
+
+	// when
+	resp, err := destination.restore(backupPath, passphrase, backup.SkipOnConflict, false)
+
+	// then
+	require.ErrorIs(t, err, network.ErrNetworkNameHasForbiddenCharacters)
+	assert.Nil(t, resp)
+	assert.Empty(t, destination.wallets(t))
+	assert.Empty(t, destination.networks(t))
+}
+
+func writeArchive(t *testing.T, filePath, passphrase, content string) {
+	t.Helper()
+	buf, err := vgcrypto.Encrypt([]byte(content), passphrase)
+	if err != nil {
+		t.Fatalf("couldn't encrypt archive: %v", err)
+	}
+	if err := os.WriteFile(filePath, buf, 0o600); err != nil {
+		t.Fatalf("couldn't write archive: %v", err)
+	}
+}
+
+type testHome struct {
+	walletStore *wstorev1.Store
+	netStore    *netstore.Store
+	svcStore    *svcstore.Store
+}
+
+func newTestHome(t *testing.T) *testHome {
+	t.Helper()
+
+	home := t.TempDir()
+	vegaPaths := paths.New(home)
+
+	walletStore, err := wallets.InitialiseStore(home)
+	if err != nil {
+		t.Fatalf("couldn't initialise wallets store: %v", err)
+	}
+
+	netStore, err := netstore.InitialiseStore(vegaPaths)
+	if err != nil {
+		t.Fatalf("couldn't initialise networks store: %v", err)
+	}
+
+	svcStore, err := svcstore.InitialiseStore(vegaPaths)
+	if err != nil {
+		t.Fatalf("couldn't initialise service store: %v", err)
+	}
+
+	if err := service.InitialiseService(svcStore, false); err != nil {
+		t.Fatalf("couldn't initialise service: %v", err)
+	}
+
+	return &testHome{
+		walletStore: walletStore,
+		netStore:    netStore,
+		svcStore:    svcStore,
+	}
+}
+
+func (h *testHome) create(filePath, passphrase string, withServiceKeys bool) (*backup.CreateResponse, error) {
+	return backup.Create(h.walletStore, h.netStore, h.svcStore, &backup.CreateRequest{
+		FilePath:           filePath,
+		Passphrase:         passphrase,
+		IncludeServiceKeys: withServiceKeys,
+	})
+}
+
+func (h *testHome) restore(filePath, passphrase, onConflict string, dryRun bool) (*backup.RestoreResponse, error) {
+	return backup.Restore(h.walletStore, h.netStore, h.svcStore, &backup.RestoreRequest{
+		FilePath:   filePath,
+		Passphrase: passphrase,
+		OnConflict: onConflict,
+		DryRun:     dryRun,
+	})
+}
+
+func (h *testHome) saveWallet(t *testing.T, name, content string) {
+	t.Helper()
+	if err := h.walletStore.SaveRawWallet(name, []byte(content)); err != nil {
+		t.Fatalf("couldn't save wallet: %v", err)
+	}
+}
+
+func (h *testHome) saveNetwork(t *testing.T, name string, port int) {
+	t.Helper()
+	if err := h.netStore.SaveNetwork(&network.Network{Name: name, Port: port}); err != nil {
+		t.Fatalf("couldn't save network: %v", err)
+	}
+}
+
+func (h *testHome) wallets(t *testing.T) []string {
+	t.Helper()
+	names, err := h.walletStore.ListWallets()
+	if err != nil {
+		t.Fatalf("couldn't list wallets: %v", err)
+	}
+	return names
+}
+
+func (h *testHome) rawWallet(t *testing.T, name string) string {
+	t.Helper()
+	buf, err := h.walletStore.GetRawWallet(name)
+	if err != nil {
+		t.Fatalf("couldn't read wallet: %v", err)
+	}
+	return string(buf)
+}
+
+func (h *testHome) networks(t *testing.T) []string {
+	t.Helper()
+	names, err := h.netStore.ListNetworks()
+	if err != nil {
+		t.Fatalf("couldn't list networks: %v", err)
+	}
+	return names
+}
+
+func (h *testHome) network(t *testing.T, name string) *network.Network {
+	t.Helper()
+	net, err := h.netStore.GetNetwork(name)
+	if err != nil {
+		t.Fatalf("couldn't read network: %v", err)
+	}
+	return net
+}
+
+func (h *testHome) serviceKeys(t *testing.T) *service.RSAKeys {
+	t.Helper()
+	keys, err := h.svcStore.GetRsaKeys()
+	if err != nil {
+		t.Fatalf("couldn't read service keys: %v", err)
+	}
+	return keys
+}
@@ -0,0 +1,42 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrBackupContainsEmptyNetwork = errors.New("backup contains an empty network configuration")
+	ErrBackupFileAlreadyExists    = errors.New("backup file already exists")
+	ErrBackupFileDoesNotExist     = errors.New("backup file doesn't exist")
+	ErrServiceKeysDoNotExist      = errors.New("service keys don't exist")
+	ErrWrongPassphrase            = errors.New("wrong backup passphrase")
+)
+
+type UnsupportedBackupVersionError struct {
+	Version uint32
+}
+
+func NewUnsupportedBackupVersionError(v uint32) UnsupportedBackupVersionError {
+	return UnsupportedBackupVersionError{
+		Version: v,
+	}
+}
+
+func (e UnsupportedBackupVersionError) Error() string {
+	return fmt.Sprintf("backup version %d is not supported, only version %d is", e.Version, LatestVersion)
+}
+
+type UnsupportedConflictStrategyError struct {
+	Strategy string
+}
+
+func NewUnsupportedConflictStrategyError(s string) UnsupportedConflictStrategyError {
+	return UnsupportedConflictStrategyError{
+		Strategy: s,
+	}
+}
+
+func (e UnsupportedConflictStrategyError) Error() string {
+	return fmt.Sprintf("conflict strategy \"%s\" is not supported, only %v", e.Strategy, ConflictStrategies)
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"code.vegaprotocol.io/shared/paths"
+	netstore "code.vegaprotocol.io/vegawallet/network/store/v1"
+	svcstore "code.vegaprotocol.io/vegawallet/service/store/v1"
+	wstorev1 "code.vegaprotocol.io/vegawallet/wallet/store/v1"
+	"code.vegaprotocol.io/vegawallet/wallets"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdBackup(w io.Writer, rf *RootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Back up and restore the wallets, networks and service keys",
+		Long:  "Back up and restore the wallets, networks and service keys",
+	}
+
+	cmd.AddCommand(NewCmdCreateBackup(w, rf))
+	cmd.AddCommand(NewCmdRestoreBackup(w, rf))
+	return cmd
+}
+
+func initialiseBackupStores(home string) (*wstorev1.Store, *netstore.Store, *svcstore.Store, error) {
+	walletStore, err := wallets.InitialiseStore(home)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("couldn't initialise wallets store: %w", err)
+	}
+
+	vegaPaths := paths.New(home)
+
+	netStore, err := netstore.InitialiseStore(vegaPaths)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("couldn't initialise networks store: %w", err)
+	}
+
+	svcStore, err := svcstore.InitialiseStore(vegaPaths)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("couldn't initialise service store: %w", err)
+	}
+
+	return walletStore, netStore, svcStore, nil
+}
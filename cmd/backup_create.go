@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"code.vegaprotocol.io/vegawallet/backup"
+	"code.vegaprotocol.io/vegawallet/cmd/cli"
+	"code.vegaprotocol.io/vegawallet/cmd/flags"
+	"code.vegaprotocol.io/vegawallet/cmd/printer"
+	"github.com/spf13/cobra"
+)
+
+var (
+	createBackupLong = cli.LongDesc(`
+		Gather the wallets, the network configurations and, optionally, the
+		service RSA keys in a single file encrypted with a backup passphrase.
+
+		The wallets are saved as is, meaning they are still protected by their
+		own passphrase once restored.
+
+		The --output flag is reserved to the output format, so the path of the
+		backup file is set with the --file flag.
+	`)
+
+	createBackupExample = cli.Examples(`
+		# Back up the wallets and the networks
+		vegawallet backup create --file PATH_TO_BACKUP
+
+		# Back up the wallets, the networks and the service keys
+		vegawallet backup create --file PATH_TO_BACKUP --with-service-keys
+
+		# Overwrite an existing backup file
+		vegawallet backup create --file PATH_TO_BACKUP --force
+	`)
+)
+
+type CreateBackupHandler func(*backup.CreateRequest) (*backup.CreateResponse, error)
+
+func NewCmdCreateBackup(w io.Writer, rf *RootFlags) *cobra.Command {
+	h := func(req *backup.CreateRequest) (*backup.CreateResponse, error) {
+		walletStore, netStore, svcStore, err := initialiseBackupStores(rf.Home)
+		if err != nil {
+			return nil, err
+		}
+
+		return backup.Create(walletStore, netStore, svcStore, req)
+	}
+
+	return BuildCmdCreateBackup(w, h, rf)
+}
+
+func BuildCmdCreateBackup(w io.Writer, handler CreateBackupHandler, rf *RootFlags) *cobra.Command {
+	f := &CreateBackupFlags{}
+
+	cmd := &cobra.Command{
+		Use:     "create",
+		Short:   "Create an encrypted backup",
+		Long:    createBackupLong,
+		Example: createBackupExample,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			req, err := f.Validate()
+			if err != nil {
+				return err
+			}
+
+			resp, err := handler(req)
+			if err != nil {
+				return err
+			}
+
+			switch rf.Output {
+			case flags.InteractiveOutput:
+				PrintCreateBackupResponse(w, resp)
+			case flags.JSONOutput:
+				return printer.FprintJSON(w, resp)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&f.FilePath,
+		"file",
+		"",
+		"Path to the backup file to create",
+	)
+	cmd.Flags().StringVarP(&f.PassphraseFile,
+		"passphrase-file", "p",
+		"",
+		"Path to the file containing the passphrase to encrypt the backup with",
+	)
+	cmd.Flags().StringVar(&f.PassphraseFD,
+		"passphrase-fd",
+		"",
+		"File descriptor to read the backup's passphrase from",
+	)
+	cmd.Flags().BoolVar(&f.WithServiceKeys,
+		"with-service-keys",
+		false,
+		"Include the service RSA keys in the backup",
+	)
+	cmd.Flags().BoolVarP(&f.Force,
+		"force", "f",
+		false,
+		"Overwrite the backup file if it already exists",
+	)
+
+	return cmd
+}
+
+type CreateBackupFlags struct {
+	FilePath        string
+	PassphraseFile  string
+	PassphraseFD    string
+	WithServiceKeys bool
+	Force           bool
+}
+
+func (f *CreateBackupFlags) Validate() (*backup.CreateRequest, error) {
+	if len(f.FilePath) == 0 {
+		return nil, flags.FlagMustBeSpecifiedError("file")
+	}
+
+	passphrase, err := flags.GetConfirmedPassphrase(flags.PassphraseSource{
+		File: f.PassphraseFile,
+		FD:   f.PassphraseFD,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &backup.CreateRequest{
+		FilePath:           f.FilePath,
+		Passphrase:         passphrase,
+		IncludeServiceKeys: f.WithServiceKeys,
+		Force:              f.Force,
+	}, nil
+}
+
+func PrintCreateBackupResponse(w io.Writer, resp *backup.CreateResponse) {
+	p := printer.NewInteractivePrinter(w)
+
+	p.CheckMark().SuccessText("Backup created at ").SuccessBold(resp.FilePath).NextSection()
+
+	p.Text("Wallets:").NextLine()
+	if len(resp.Wallets) == 0 {
+		p.WarningText("No wallet").NextLine()
+	}
+	for _, name := range resp.Wallets {
+		p.Text("- ").WarningText(name).NextLine()
+	}
+	p.NextLine()
+
+	p.Text("Networks:").NextLine()
+	if len(resp.Networks) == 0 {
+		p.WarningText("No network").NextLine()
+	}
+	for _, name := range resp.Networks {
+		p.Text("- ").WarningText(name).NextLine()
+	}
+	p.NextLine()
+
+	if resp.IncludeServiceKeys {
+		p.CheckMark().Text("Service keys included").NextSection()
+	}
+
+	p.RedArrow().DangerText("Important").NextLine()
+	p.Text("Keep the backup passphrase safe, it can't be recovered.").NextLine()
+	p.Text("To restore the backup, use the following command:").NextSection()
+	p.Code(fmt.Sprintf("%s backup restore %s", os.Args[0], resp.FilePath)).NextLine()
+}
@@ -0,0 +1,63 @@
+package cmd_test
+
+import (
+	"testing"
+
+	vgrand "code.vegaprotocol.io/shared/libs/rand"
+	"code.vegaprotocol.io/vegawallet/backup"
+	"code.vegaprotocol.io/vegawallet/cmd"
+	"code.vegaprotocol.io/vegawallet/cmd/flags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateBackupFlags(t *testing.T) {
+	t.Run("Valid flags succeeds", testCreateBackupFlagsValidFlagsSucceeds)
+	t.Run("Missing file fails", testCreateBackupFlagsMissingFileFails)
+}
+
+func testCreateBackupFlagsValidFlagsSucceeds(t *testing.T) {
+	testDir := t.TempDir()
+
+	// given
+	filePath := vgrand.RandomStr(10)
+	passphrase, passphraseFilePath := NewPassphraseFile(t, testDir)
+	f := &cmd.CreateBackupFlags{
+		FilePath:        filePath,
+		PassphraseFile:  passphraseFilePath,
+		WithServiceKeys: true,
+		Force:           true,
+	}
+
+	expectedReq := &backup.CreateRequest{
+		FilePath:           filePath,
+		Passphrase:         passphrase,
+		IncludeServiceKeys: true,
+		Force:              true,
+	}
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, req)
+	assert.Equal(t, expectedReq, req)
+}
+
+func testCreateBackupFlagsMissingFileFails(t *testing.T) {
+	testDir := t.TempDir()
+
+	// given
+	_, passphraseFilePath := NewPassphraseFile(t, testDir)
+	f := &cmd.CreateBackupFlags{
+		PassphraseFile: passphraseFilePath,
+	}
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	assert.ErrorIs(t, err, flags.FlagMustBeSpecifiedError("file"))
+	assert.Nil(t, req)
+}
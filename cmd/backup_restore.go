@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"io"
+
+	"code.vegaprotocol.io/vegawallet/backup"
+	"code.vegaprotocol.io/vegawallet/cmd/cli"
+	"code.vegaprotocol.io/vegawallet/cmd/flags"
+	"code.vegaprotocol.io/vegawallet/cmd/printer"
+	"github.com/spf13/cobra"
+)
+
+var (
+	restoreBackupLong = cli.LongDesc(`
+		Restore the wallets, the network configurations and, if included, the
+		service RSA keys from a backup created with "backup create".
+
+		When a wallet or a network with the same name already exists, the
+		--on-conflict flag decides what to do:
+		  - skip: keep the existing one,
+		  - overwrite: replace the existing one by the one from the backup,
+		  - rename: restore the one from the backup under a new name.
+		The service keys can't be renamed, so they are skipped with "rename".
+
+		Use the --dry-run flag to list what would be restored, without changing
+		anything.
+	`)
+
+	restoreBackupExample = cli.Examples(`
+		# List what would be restored from a backup
+		vegawallet backup restore PATH_TO_BACKUP --dry-run
+
+		# Restore a backup, keeping the existing wallets and networks
+		vegawallet backup restore PATH_TO_BACKUP
+
+		# Restore a backup, replacing the existing wallets and networks
+		vegawallet backup restore PATH_TO_BACKUP --on-conflict overwrite
+	`)
+)
+
+type RestoreBackupHandler func(*backup.RestoreRequest) (*backup.RestoreResponse, error)
+
+func NewCmdRestoreBackup(w io.Writer, rf *RootFlags) *cobra.Command {
+	h := func(req *backup.RestoreRequest) (*backup.RestoreResponse, error) {
+		walletStore, netStore, svcStore, err := initialiseBackupStores(rf.Home)
+		if err != nil {
+			return nil, err
+		}
+
+		return backup.Restore(walletStore, netStore, svcStore, req)
+	}
+
+	return BuildCmdRestoreBackup(w, h, rf)
+}
+
+func BuildCmdRestoreBackup(w io.Writer, handler RestoreBackupHandler, rf *RootFlags) *cobra.Command {
+	f := &RestoreBackupFlags{}
+
+	cmd := &cobra.Command{
+		Use:     "restore",
+		Short:   "Restore an encrypted backup",
+		Long:    restoreBackupLong,
+		Example: restoreBackupExample,
+		RunE: func(_ *cobra.Command, args []string) error {
+			if aLen := len(args); aLen == 0 {
+				return flags.ArgMustBeSpecifiedError("file")
+			} else if aLen > 1 {
+				return flags.TooManyArgsError("file")
+			}
+			f.FilePath = args[0]
+
+			req, err := f.Validate()
+			if err != nil {
+				return err
+			}
+
+			resp, err := handler(req)
+			if err != nil {
+				return err
+			}
+
+			switch rf.Output {
+			case flags.InteractiveOutput:
+				PrintRestoreBackupResponse(w, resp)
+			case flags.JSONOutput:
+				return printer.FprintJSON(w, resp)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&f.PassphraseFile,
+		"passphrase-file", "p",
+		"",
+		"Path to the file containing the passphrase the backup is encrypted with",
+	)
+	cmd.Flags().StringVar(&f.PassphraseFD,
+		"passphrase-fd",
+		"",
+		"File descriptor to read the backup's passphrase from",
+	)
+	cmd.Flags().StringVar(&f.OnConflict,
+		"on-conflict",
+		backup.SkipOnConflict,
+		"What to do when an element already exists: skip, overwrite or rename",
+	)
+	cmd.Flags().BoolVar(&f.DryRun,
+		"dry-run",
+		false,
+		"List what would be restored, without changing anything",
+	)
+
+	_ = cmd.RegisterFlagCompletionFunc("on-conflict", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return backup.ConflictStrategies, cobra.ShellCompDirectiveDefault
+	})
+
+	return cmd
+}
+
+type RestoreBackupFlags struct {
+	FilePath       string
+	PassphraseFile string
+	PassphraseFD   string
+	OnConflict     string
+	DryRun         bool
+}
+
+func (f *RestoreBackupFlags) Validate() (*backup.RestoreRequest, error) {
+	if len(f.FilePath) == 0 {
+		return nil, flags.ArgMustBeSpecifiedError("file")
+	}
+
+	if err := backup.ValidateConflictStrategy(f.OnConflict); err != nil {
+		supported := make([]interface{}, 0, len(backup.ConflictStrategies))
+		for _, s := range backup.ConflictStrategies {
+			supported = append(supported, s)
+		}
+		return nil, flags.UnsupportedFlagValueError("on-conflict", f.OnConflict, supported)
+	}
+
+	passphrase, err := flags.GetPassphrase(flags.PassphraseSource{
+		File: f.PassphraseFile,
+		FD:   f.PassphraseFD,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &backup.RestoreRequest{
+		FilePath:   f.FilePath,
+		Passphrase: passphrase,
+		OnConflict: f.OnConflict,
+		DryRun:     f.DryRun,
+	}, nil
+}
+
+func PrintRestoreBackupResponse(w io.Writer, resp *backup.RestoreResponse) {
+	p := printer.NewInteractivePrinter(w)
+
+	if resp.DryRun {
+		p.BangMark().WarningText("Dry run: nothing has been restored").NextSection()
+	}
+
+	p.Text("Wallets:").NextLine()
+	if len(resp.Wallets) == 0 {
+		p.WarningText("No wallet").NextLine()
+	}
+	for _, entry := range resp.Wallets {
+		printRestoredEntry(p, entry)
+	}
+	p.NextLine()
+
+	p.Text("Networks:").NextLine()
+	if len(resp.Networks) == 0 {
+		p.WarningText("No network").NextLine()
+	}
+	for _, entry := range resp.Networks {
+		printRestoredEntry(p, entry)
+	}
+
+	if resp.ServiceKeys != nil {
+		p.NextLine()
+		p.Text("Service keys:").NextLine()
+		printRestoredEntry(p, *resp.ServiceKeys)
+	}
+
+	if !resp.DryRun {
+		p.NextLine()
+		p.CheckMark().SuccessText("Backup restored").NextLine()
+	}
+}
+
+func printRestoredEntry(p *printer.InteractivePrinter, entry backup.RestoredEntry) {
+	switch entry.Action {
+	case backup.ActionSkipped:
+		p.Text("- ").WarningText(entry.Name).Text(" skipped, as it already exists").NextLine()
+	case backup.ActionOverwritten:
+		p.Text("- ").WarningText(entry.Name).Text(" overwritten").NextLine()
+	case backup.ActionRenamed:
+		p.Text("- ").WarningText(entry.Name).Text(" restored as ").WarningText(entry.RestoredAs).NextLine()
+	default:
+		p.Text("- ").WarningText(entry.Name).Text(" restored").NextLine()
+	}
+}
@@ -0,0 +1,89 @@
+package cmd_test
+
+import (
+	"testing"
+
+	vgrand "code.vegaprotocol.io/shared/libs/rand"
+	"code.vegaprotocol.io/vegawallet/backup"
+	"code.vegaprotocol.io/vegawallet/cmd"
+	"code.vegaprotocol.io/vegawallet/cmd/flags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestoreBackupFlags(t *testing.T) {
+	t.Run("Valid flags succeeds", testRestoreBackupFlagsValidFlagsSucceeds)
+	t.Run("Missing file fails", testRestoreBackupFlagsMissingFileFails)
+	t.Run("Unsupported conflict strategy fails", testRestoreBackupFlagsUnsupportedConflictStrategyFails)
+}
+
+func testRestoreBackupFlagsValidFlagsSucceeds(t *testing.T) {
+	testDir := t.TempDir()
+
+	// given
+	filePath := vgrand.RandomStr(10)
+	passphrase, passphraseFilePath := NewPassphraseFile(t, testDir)
+	f := &cmd.RestoreBackupFlags{
+		FilePath:       filePath,
+		PassphraseFile: passphraseFilePath,
+		OnConflict:     backup.RenameOnConflict,
+		DryRun:         true,
+	}
+
+	expectedReq := &backup.RestoreRequest{
+		FilePath:   filePath,
+		Passphrase: passphrase,
+		OnConflict: backup.RenameOnConflict,
+		DryRun:     true,
+	}
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, req)
+	assert.Equal(t, expectedReq, req)
+}
+
+func testRestoreBackupFlagsMissingFileFails(t *testing.T) {
+	// given
+	f := newRestoreBackupFlags(t)
+	f.FilePath = ""
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	assert.ErrorIs(t, err, flags.ArgMustBeSpecifiedError("file"))
+	assert.Nil(t, req)
+}
+
+func testRestoreBackupFlagsUnsupportedConflictStrategyFails(t *testing.T) {
+	// given
+	f := newRestoreBackupFlags(t)
+	f.OnConflict = "merge"
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	assert.ErrorIs(t, err, flags.UnsupportedFlagValueError("on-conflict", "merge", []interface{}{
+		backup.SkipOnConflict,
+		backup.OverwriteOnConflict,
+		backup.RenameOnConflict,
+	}))
+	assert.Nil(t, req)
+}
+
+func newRestoreBackupFlags(t *testing.T) *cmd.RestoreBackupFlags {
+	t.Helper()
+
+	_, passphraseFilePath := NewPassphraseFile(t, t.TempDir())
+
+	return &cmd.RestoreBackupFlags{
+		FilePath:       vgrand.RandomStr(10),
+		PassphraseFile: passphraseFilePath,
+		OnConflict:     backup.SkipOnConflict,
+	}
+}
@@ -38,6 +38,9 @@ var (
 		For vote submission, it will look like this:
 
 		'{"voteSubmission": {"proposalId": "some-id", "value": "VALUE_YES"}}'
+
+		When the wallet has the two-factor authentication enabled, a current code
+		is required.
 	`)
 
 	sendCommandExample = cli.Examples(`
@@ -52,6 +55,9 @@ var (
 
 		# Send a command with a maximum of 10 retries
 		vegawallet command send --network NETWORK --wallet WALLET --pubkey PUBKEY --retries 10 COMMAND
+
+		# Send a command from a wallet with the two-factor authentication enabled
+		vegawallet command send --network NETWORK --wallet WALLET --pubkey PUBKEY --2fa-code CODE COMMAND
 	`)
 )
 
@@ -115,6 +121,16 @@ func BuildCmdCommandSend(w io.Writer, handler SendCommandHandler, rf *RootFlags)
 		"",
 		"Path to the file containing the wallet's passphrase",
 	)
+	cmd.Flags().StringVar(&f.PassphraseFD,
+		"passphrase-fd",
+		"",
+		"File descriptor to read the wallet's passphrase from",
+	)
+	cmd.Flags().StringVar(&f.TwoFactorCode,
+		"2fa-code",
+		"",
+		"Current two-factor authentication code, or a recovery code. If required but not set, it's asked",
+	)
 	cmd.Flags().StringVar(&f.LogLevel,
 		"level",
 		zapcore.InfoLevel.String(),
@@ -139,6 +155,8 @@ type SendCommandFlags struct {
 	Wallet         string
 	PubKey         string
 	PassphraseFile string
+	PassphraseFD   string
+	TwoFactorCode  string
 	Retries        uint64
 	LogLevel       string
 	RawCommand     string
@@ -146,7 +164,8 @@ type SendCommandFlags struct {
 
 func (f *SendCommandFlags) Validate() (*SendCommandRequest, error) {
 	req := &SendCommandRequest{
-		Retries: f.Retries,
+		Retries:       f.Retries,
+		TwoFactorCode: f.TwoFactorCode,
 	}
 
 	if len(f.Wallet) == 0 {
@@ -171,7 +190,11 @@ func (f *SendCommandFlags) Validate() (*SendCommandRequest, error) {
 	req.NodeAddress = f.NodeAddress
 	req.Network = f.Network
 
-	passphrase, err := flags.GetPassphrase(f.PassphraseFile)
+	passphrase, err := flags.GetPassphrase(flags.PassphraseSource{
+		Wallet: f.Wallet,
+		File:   f.PassphraseFile,
+		FD:     f.PassphraseFD,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -201,13 +224,14 @@ func (f *SendCommandFlags) Validate() (*SendCommandRequest, error) {
 }
 
 type SendCommandRequest struct {
-	Network     string
-	NodeAddress string
-	Wallet      string
-	Passphrase  string
-	Retries     uint64
-	LogLevel    string
-	Request     *walletpb.SubmitTransactionRequest
+	Network       string
+	NodeAddress   string
+	Wallet        string
+	Passphrase    string
+	TwoFactorCode string
+	Retries       uint64
+	LogLevel      string
+	Request       *walletpb.SubmitTransactionRequest
 }
 
 func SendCommand(w io.Writer, rf *RootFlags, req *SendCommandRequest) error {
@@ -223,7 +247,9 @@ func SendCommand(w io.Writer, rf *RootFlags, req *SendCommandRequest) error {
 		return fmt.Errorf("couldn't initialise wallets store: %w", err)
 	}
 	handler := wallets.NewHandler(store)
-	err = handler.LoginWallet(req.Wallet, req.Passphrase)
+	err = retryWithTwoFactorCode(&req.TwoFactorCode, func() error {
+		return handler.LoginWallet(req.Wallet, req.Passphrase, req.TwoFactorCode)
+	})
 	if err != nil {
 		return fmt.Errorf("couldn't login to the wallet %s: %w", req.Wallet, err)
 	}
@@ -109,6 +109,11 @@ func BuildCmdCommandSign(w io.Writer, handler SignCommandHandler, rf *RootFlags)
 		"",
 		"Path to the file containing the wallet's passphrase",
 	)
+	cmd.Flags().StringVar(&f.PassphraseFD,
+		"passphrase-fd",
+		"",
+		"File descriptor to read the wallet's passphrase from",
+	)
 	cmd.Flags().Uint64Var(&f.TxBlockHeight,
 		"tx-height",
 		0,
@@ -124,6 +129,7 @@ type SignCommandFlags struct {
 	Wallet         string
 	PubKey         string
 	PassphraseFile string
+	PassphraseFD   string
 	RawCommand     string
 	TxBlockHeight  uint64
 }
@@ -136,7 +142,11 @@ func (f *SignCommandFlags) Validate() (*wallet.SignCommandRequest, error) {
 	}
 	req.Wallet = f.Wallet
 
-	passphrase, err := flags.GetPassphrase(f.PassphraseFile)
+	passphrase, err := flags.GetPassphrase(flags.PassphraseSource{
+		Wallet: f.Wallet,
+		File:   f.PassphraseFile,
+		FD:     f.PassphraseFD,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,312 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"code.vegaprotocol.io/shared/paths"
+	"code.vegaprotocol.io/vegawallet/cmd/cli"
+	"code.vegaprotocol.io/vegawallet/cmd/flags"
+	"code.vegaprotocol.io/vegawallet/cmd/printer"
+	"code.vegaprotocol.io/vegawallet/network"
+	netstore "code.vegaprotocol.io/vegawallet/network/store/v1"
+	"code.vegaprotocol.io/vegawallet/service"
+	svcstore "code.vegaprotocol.io/vegawallet/service/store/v1"
+	"code.vegaprotocol.io/vegawallet/wallet"
+	"code.vegaprotocol.io/vegawallet/wallets"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ErrDoctorFoundIssues = errors.New("the doctor found issues")
+
+	doctorLong = cli.LongDesc(`
+		Verify the consistency of the wallets, the network configurations and
+		the service RSA keys.
+
+		Every wallet is decrypted with the specified passphrase, and its keys are
+		compared with the ones derived from the wallet node. The wallets that
+		can't be decrypted with the passphrase are reported as such. To only
+		diagnose some wallets, use the --wallet flag.
+
+		The files located in the wallets folder that are not registered as wallets
+		are reported as unknown files. This includes the wallets created by
+		previous versions of the software that have not been opened since, as
+		the doctor never modifies the wallets folder.
+
+		The command exits with an error if an issue is found.
+	`)
+
+	doctorExample = cli.Examples(`
+		# Diagnose the wallets, the networks and the service
+		vegawallet doctor
+
+		# Diagnose a specific wallet
+		vegawallet doctor --wallet WALLET
+
+		# Diagnose and output the result as JSON
+		vegawallet doctor --output json
+	`)
+)
+
+type DoctorRequest struct {
+	Wallets    []string
+	Passphrase string
+}
+
+type DoctorResponse struct {
+	Healthy bool `json:"healthy"`
+	Wallets struct {
+		Path         string                           `json:"path"`
+		UnknownFiles []string                         `json:"unknownFiles"`
+		Wallets      []*wallet.DiagnoseWalletResponse `json:"wallets"`
+	} `json:"wallets"`
+	Networks struct {
+		Path     string                            `json:"path"`
+		Networks []network.DiagnoseNetworkResponse `json:"networks"`
+	} `json:"networks"`
+	Service struct {
+		RSAKeys struct {
+			PublicKeyFilePath  string   `json:"publicKeyFilePath"`
+			PrivateKeyFilePath string   `json:"privateKeyFilePath"`
+			Healthy            bool     `json:"healthy"`
+			Issues             []string `json:"issues"`
+		} `json:"rsaKeys"`
+	} `json:"service"`
+}
+
+type DoctorHandler func(*DoctorRequest) (*DoctorResponse, error)
+
+func NewCmdDoctor(w io.Writer, rf *RootFlags) *cobra.Command {
+	h := func(req *DoctorRequest) (*DoctorResponse, error) {
+		return Doctor(rf.Home, req)
+	}
+
+	return BuildCmdDoctor(w, h, rf)
+}
+
+func BuildCmdDoctor(w io.Writer, handler DoctorHandler, rf *RootFlags) *cobra.Command {
+	f := &DoctorFlags{}
+
+	cmd := &cobra.Command{
+		Use:     "doctor",
+		Short:   "Verify the consistency of the wallets, networks and service",
+		Long:    doctorLong,
+		Example: doctorExample,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			req, err := f.Validate()
+			if err != nil {
+				return err
+			}
+
+			resp, err := handler(req)
+			if err != nil {
+				return err
+			}
+
+			switch rf.Output {
+			case flags.InteractiveOutput:
+				PrintDoctorResponse(w, resp)
+			case flags.JSONOutput:
+				if err := printer.FprintJSON(w, resp); err != nil {
+					return err
+				}
+			}
+
+			if !resp.Healthy {
+				return ErrDoctorFoundIssues
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&f.Wallets,
+		"wallet", "w",
+		[]string{},
+		"Wallets to diagnose. All wallets are diagnosed, if not specified",
+	)
+	cmd.Flags().StringVarP(&f.PassphraseFile,
+		"passphrase-file", "p",
+		"",
+		"Path to the file containing the wallets' passphrase",
+	)
+	cmd.Flags().StringVar(&f.PassphraseFD,
+		"passphrase-fd",
+		"",
+		"File descriptor to read the wallets' passphrase from",
+	)
+
+	autoCompleteWallet(cmd, rf.Home)
+
+	return cmd
+}
+
+type DoctorFlags struct {
+	Wallets        []string
+	PassphraseFile string
+	PassphraseFD   string
+}
+
+func (f *DoctorFlags) Validate() (*DoctorRequest, error) {
+	req := &DoctorRequest{
+		Wallets: f.Wallets,
+	}
+
+	source := flags.PassphraseSource{
+		File: f.PassphraseFile,
+		FD:   f.PassphraseFD,
+	}
+	// The passphrase provider can only be used if a single wallet is diagnosed,
+	// as the passphrase is shared by all the wallets.
+	if len(f.Wallets) == 1 {
+		source.Wallet = f.Wallets[0]
+	}
+
+	passphrase, err := flags.GetPassphrase(source)
+	if err != nil {
+		return nil, err
+	}
+	req.Passphrase = passphrase
+
+	return req, nil
+}
+
+func Doctor(home string, req *DoctorRequest) (*DoctorResponse, error) {
+	resp := &DoctorResponse{}
+
+	// The doctor only reports the state of the wallets folder, so the store
+	// must not migrate or repair anything.
+	walletStore := wallets.InitialiseReadOnlyStore(home)
+
+	var err error
+	resp.Wallets.Path = walletStore.GetWalletsPath()
+	resp.Wallets.UnknownFiles, err = walletStore.ListUnindexedFiles()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list the unknown files: %w", err)
+	}
+
+	walletsToDiagnose := req.Wallets
+	if len(walletsToDiagnose) == 0 {
+		walletsToDiagnose, err = walletStore.ListWallets()
+		if err != nil {
+			return nil, fmt.Errorf("couldn't list the wallets: %w", err)
+		}
+	}
+
+	resp.Wallets.Wallets = make([]*wallet.DiagnoseWalletResponse, 0, len(walletsToDiagnose))
+	for _, name := range walletsToDiagnose {
+		diagnosis, err := wallet.DiagnoseWallet(walletStore, &wallet.DiagnoseWalletRequest{
+			Wallet:     name,
+			Passphrase: req.Passphrase,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("couldn't diagnose wallet %s: %w", name, err)
+		}
+		resp.Wallets.Wallets = append(resp.Wallets.Wallets, diagnosis)
+	}
+
+	vegaPaths := paths.New(home)
+	netStore, err := netstore.InitialiseStore(vegaPaths)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't initialise networks store: %w", err)
+	}
+
+	resp.Networks.Path = netStore.GetNetworksPath()
+	resp.Networks.Networks, err = network.DiagnoseNetworks(netStore)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't diagnose the networks: %w", err)
+	}
+
+	svcStore, err := svcstore.InitialiseStore(vegaPaths)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't initialise service store: %w", err)
+	}
+
+	resp.Service.RSAKeys.PublicKeyFilePath, resp.Service.RSAKeys.PrivateKeyFilePath = svcStore.GetRSAKeysPath()
+	resp.Service.RSAKeys.Issues = diagnoseRSAKeys(svcStore)
+	resp.Service.RSAKeys.Healthy = len(resp.Service.RSAKeys.Issues) == 0
+
+	resp.Healthy = len(resp.Wallets.UnknownFiles) == 0 && resp.Service.RSAKeys.Healthy
+	for _, w := range resp.Wallets.Wallets {
+		resp.Healthy = resp.Healthy && w.Healthy
+	}
+	for _, n := range resp.Networks.Networks {
+		resp.Healthy = resp.Healthy && n.Healthy
+	}
+
+	return resp, nil
+}
+
+func diagnoseRSAKeys(svcStore *svcstore.Store) []string {
+	isInit, err := service.IsInitialised(svcStore)
+	if err != nil {
+		return []string{fmt.Sprintf("couldn't verify service initialisation state: %v", err)}
+	}
+	if !isInit {
+		return []string{ErrProgramIsNotInitialised.Error()}
+	}
+
+	keys, err := svcStore.GetRsaKeys()
+	if err != nil {
+		return []string{fmt.Sprintf("couldn't read RSA keys: %v", err)}
+	}
+
+	if err := service.VerifyRSAKeys(keys); err != nil {
+		return []string{err.Error()}
+	}
+
+	return []string{}
+}
+
+func PrintDoctorResponse(w io.Writer, resp *DoctorResponse) {
+	p := printer.NewInteractivePrinter(w)
+
+	p.BlueArrow().InfoText("Wallets").Text(" located at ").Bold(resp.Wallets.Path).NextLine()
+	if len(resp.Wallets.Wallets) == 0 {
+		p.Text("No wallet registered").NextLine()
+	}
+	for _, diagnosis := range resp.Wallets.Wallets {
+		printDiagnosis(p, diagnosis.Wallet, diagnosis.Issues)
+	}
+	for _, file := range resp.Wallets.UnknownFiles {
+		p.CrossMark().Text("Unknown file ").Bold(file).Text(": it's not registered as a wallet").NextLine()
+	}
+	p.NextLine()
+
+	p.BlueArrow().InfoText("Networks").Text(" located at ").Bold(resp.Networks.Path).NextLine()
+	if len(resp.Networks.Networks) == 0 {
+		p.Text("No network registered").NextLine()
+	}
+	for _, diagnosis := range resp.Networks.Networks {
+		printDiagnosis(p, diagnosis.Name, diagnosis.Issues)
+	}
+	p.NextLine()
+
+	p.BlueArrow().InfoText("Service").NextLine()
+	printDiagnosis(p, "RSA keys", resp.Service.RSAKeys.Issues)
+	p.NextLine()
+
+	if resp.Healthy {
+		p.CheckMark().SuccessText("No issue found").NextLine()
+		return
+	}
+
+	p.CrossMark().DangerText("Issues found").NextSection()
+	p.Text("To get more information about the available commands, use:").NextSection()
+	p.Code(fmt.Sprintf("%s --help", os.Args[0])).NextLine()
+}
+
+func printDiagnosis(p *printer.InteractivePrinter, name string, issues []string) {
+	if len(issues) == 0 {
+		p.CheckMark().Bold(name).NextLine()
+		return
+	}
+
+	p.CrossMark().Bold(name).NextLine()
+	for _, issue := range issues {
+		p.Text("    - ").DangerText(issue).NextLine()
+	}
+}
@@ -0,0 +1,57 @@
+package cmd_test
+
+import (
+	"testing"
+
+	vgrand "code.vegaprotocol.io/shared/libs/rand"
+	"code.vegaprotocol.io/vegawallet/cmd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoctorFlags(t *testing.T) {
+	t.Run("Valid flags succeeds", testDoctorFlagsValidFlagsSucceeds)
+	t.Run("Missing passphrase file fails", testDoctorFlagsMissingPassphraseFileFails)
+}
+
+func testDoctorFlagsValidFlagsSucceeds(t *testing.T) {
+	testDir := t.TempDir()
+
+	// given
+	passphrase, passphraseFilePath := NewPassphraseFile(t, testDir)
+	walletName := vgrand.RandomStr(10)
+
+	f := &cmd.DoctorFlags{
+		Wallets:        []string{walletName},
+		PassphraseFile: passphraseFilePath,
+	}
+
+	expectedReq := &cmd.DoctorRequest{
+		Wallets:    []string{walletName},
+		Passphrase: passphrase,
+	}
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, req)
+	assert.Equal(t, expectedReq, req)
+}
+
+func testDoctorFlagsMissingPassphraseFileFails(t *testing.T) {
+	testDir := t.TempDir()
+
+	// given
+	f := &cmd.DoctorFlags{
+		PassphraseFile: testDir + "/does-not-exist.txt",
+	}
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	assert.Error(t, err)
+	assert.Nil(t, req)
+}
@@ -49,6 +49,16 @@ func OneOfFlagsMustBeSpecifiedError(n1, n2 string) error {
 	}
 }
 
+func OneOfManyFlagsMustBeSpecifiedError(names ...string) error {
+	fmtFlags := make([]string, len(names))
+	for i, name := range names {
+		fmtFlags[i] = fmt.Sprintf("--%s", name)
+	}
+	return FlagError{
+		message: fmt.Sprintf("one of %s or %s flags must be specified", strings.Join(fmtFlags[:len(fmtFlags)-1], ", "), fmtFlags[len(fmtFlags)-1]),
+	}
+}
+
 func InvalidFlagFormatError(name string) error {
 	return FlagError{
 		message: fmt.Sprintf("--%s flag has not a valid format", name),
@@ -3,11 +3,14 @@ package flags
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
 	"strings"
 
 	vgfs "code.vegaprotocol.io/shared/libs/fs"
 	vgterm "code.vegaprotocol.io/shared/libs/term"
+	"code.vegaprotocol.io/vegawallet/passphrase"
 	"golang.org/x/term"
 )
 
@@ -35,22 +38,70 @@ func BuildPassphraseGetterWithOps(passphraseFile string) PassphraseGetterWithOps
 	return ReadPassphraseInputWithOpts
 }
 
-func GetPassphrase(passphraseFile string) (string, error) {
-	if len(passphraseFile) != 0 {
-		return ReadPassphraseFile(passphraseFile)
+// PassphraseSource describes where the passphrase can be read from. The file
+// takes precedence over the file descriptor, that takes precedence over the
+// passphrase provider configured for the wallet. If none is set, the
+// passphrase is asked to the user.
+type PassphraseSource struct {
+	// Wallet is the name of the wallet the passphrase is for. If empty, the
+	// passphrase providers are not used.
+	Wallet string
+	// File is the path to the file holding the passphrase.
+	File string
+	// FD is the file descriptor to read the passphrase from.
+	FD string
+}
+
+// passphraseProviders is the configuration of the passphrase providers, loaded
+// once for all the commands.
+var passphraseProviders *passphrase.Config
+
+// SetPassphraseProviders sets the passphrase providers used when neither a
+// file nor a file descriptor is specified.
+func SetPassphraseProviders(cfg *passphrase.Config) {
+	passphraseProviders = cfg
+}
+
+func GetPassphrase(source PassphraseSource) (string, error) {
+	if pass, found, err := readPassphraseFromSource(source); found || err != nil {
+		return pass, err
 	}
 
 	return ReadPassphraseInput()
 }
 
-func GetConfirmedPassphrase(passphraseFile string) (string, error) {
-	if len(passphraseFile) != 0 {
-		return ReadPassphraseFile(passphraseFile)
+func GetConfirmedPassphrase(source PassphraseSource) (string, error) {
+	if pass, found, err := readPassphraseFromSource(source); found || err != nil {
+		return pass, err
 	}
 
 	return ReadConfirmedPassphraseInput()
 }
 
+func readPassphraseFromSource(source PassphraseSource) (string, bool, error) {
+	if len(source.File) != 0 {
+		pass, err := ReadPassphraseFile(source.File)
+		return pass, true, err
+	}
+
+	if len(source.FD) != 0 {
+		pass, err := ReadPassphraseFD(source.FD)
+		return pass, true, err
+	}
+
+	if len(source.Wallet) != 0 {
+		if provider := passphraseProviders.ProviderFor(source.Wallet); provider != nil {
+			pass, err := provider.GetPassphrase(source.Wallet)
+			if err != nil {
+				return "", true, fmt.Errorf("couldn't get passphrase from provider: %w", err)
+			}
+			return pass, true, nil
+		}
+	}
+
+	return "", false, nil
+}
+
 func ReadPassphraseFile(passphraseFilePath string) (string, error) {
 	rawPassphrase, err := vgfs.ReadFile(passphraseFilePath)
 	if err != nil {
@@ -67,6 +118,31 @@ func ReadPassphraseFile(passphraseFilePath string) (string, error) {
 	return cleanupPassphrase, nil
 }
 
+func ReadPassphraseFD(rawFD string) (string, error) {
+	fd, err := strconv.ParseUint(rawFD, 10, 0)
+	if err != nil {
+		return "", InvalidFlagFormatError("passphrase-fd")
+	}
+
+	file := os.NewFile(uintptr(fd), fmt.Sprintf("fd%d", fd))
+	if file == nil {
+		return "", InvalidFlagFormatError("passphrase-fd")
+	}
+	defer file.Close()
+
+	rawPassphrase, err := io.ReadAll(file)
+	if err != nil {
+		return "", fmt.Errorf("couldn't read passphrase from file descriptor %d: %w", fd, err)
+	}
+
+	cleanupPassphrase := strings.Trim(string(rawPassphrase), "\r\n")
+	if len(cleanupPassphrase) == 0 {
+		return "", ErrPassphraseMustBeSpecified
+	}
+
+	return cleanupPassphrase, nil
+}
+
 func ReadPassphraseInput() (string, error) {
 	return ReadPassphraseInputWithOpts(false)
 }
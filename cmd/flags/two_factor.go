@@ -0,0 +1,29 @@
+package flags
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	vgterm "code.vegaprotocol.io/shared/libs/term"
+)
+
+var ErrTwoFactorCodeRequiredWithoutTTY = errors.New("2fa-code flag is required without TTY")
+
+// ReadTwoFactorCode asks the user for the current code of the authenticator
+// application, or for one of the recovery codes.
+func ReadTwoFactorCode() (string, error) {
+	if vgterm.HasNoTTY() {
+		return "", ErrTwoFactorCodeRequiredWithoutTTY
+	}
+
+	fmt.Print("Enter two-factor authentication code: ") //nolint:forbidigo
+	code, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("couldn't read two-factor authentication code: %w", err)
+	}
+
+	return strings.TrimSpace(code), nil
+}
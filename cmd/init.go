@@ -20,6 +20,10 @@ var (
 	initLong = cli.LongDesc(`
 		Creates the folders, the configuration files and RSA keys needed by the service
 		to operate.
+
+		It also creates a local certificate authority, issuing the TLS certificates
+		of the networks configured with "tls.autoGenerate". The clients have to
+		trust it to connect to the service over HTTPS.
 	`)
 
 	initExample = cli.Examples(`
@@ -80,6 +84,9 @@ type InitResponse struct {
 		PublicKeyFilePath  string `json:"publicKeyFilePath"`
 		PrivateKeyFilePath string `json:"privateKeyFilePath"`
 	} `json:"rsaKeys"`
+	TLS struct {
+		CertificateAuthorityFilePath string `json:"certificateAuthorityFilePath"`
+	} `json:"tls"`
 }
 
 func Init(home string, f *InitFlags) (*InitResponse, error) {
@@ -97,10 +104,20 @@ func Init(home string, f *InitFlags) (*InitResponse, error) {
 		return nil, fmt.Errorf("couldn't initialise the service: %w", err)
 	}
 
+	tlsStore, err := svcstore.InitialiseTLSStore(paths.New(home))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't initialise TLS store: %w", err)
+	}
+
+	if err = service.InitialiseCertificateAuthority(tlsStore, f.Force); err != nil {
+		return nil, fmt.Errorf("couldn't initialise the certificate authority: %w", err)
+	}
+
 	resp := &InitResponse{}
 	pubRSAKeysPath, privRSAKeysPath := svcStore.GetRSAKeysPath()
 	resp.RSAKeys.PublicKeyFilePath = pubRSAKeysPath
 	resp.RSAKeys.PrivateKeyFilePath = privRSAKeysPath
+	resp.TLS.CertificateAuthorityFilePath = tlsStore.GetCertificateAuthorityPath()
 
 	return resp, nil
 }
@@ -110,6 +127,7 @@ func PrintInitResponse(w io.Writer, resp *InitResponse) {
 
 	p.CheckMark().Text("Service public RSA keys created at: ").SuccessText(resp.RSAKeys.PublicKeyFilePath).NextLine()
 	p.CheckMark().Text("Service private RSA keys created at: ").SuccessText(resp.RSAKeys.PrivateKeyFilePath).NextLine()
+	p.CheckMark().Text("Service TLS certificate authority created at: ").SuccessText(resp.TLS.CertificateAuthorityFilePath).NextLine()
 	p.CheckMark().SuccessText("Initialisation succeeded").NextSection()
 
 	p.BlueArrow().InfoText("Create a wallet").NextLine()
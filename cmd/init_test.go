@@ -33,6 +33,8 @@ func testInitialisingSoftwareSucceeds(t *testing.T) {
 	assert.FileExists(t, resp.RSAKeys.PublicKeyFilePath)
 	assert.True(t, strings.HasPrefix(resp.RSAKeys.PublicKeyFilePath, testDir))
 	assert.FileExists(t, resp.RSAKeys.PublicKeyFilePath)
+	assert.True(t, strings.HasPrefix(resp.TLS.CertificateAuthorityFilePath, testDir))
+	assert.FileExists(t, resp.TLS.CertificateAuthorityFilePath)
 }
 
 func testForcingSoftwareInitialisationSucceeds(t *testing.T) {
@@ -91,6 +91,11 @@ func BuildCmdAnnotateKey(w io.Writer, handler AnnotateKeyHandler, rf *RootFlags)
 		"",
 		"Path to the file containing the wallet's passphrase",
 	)
+	cmd.Flags().StringVar(&f.PassphraseFD,
+		"passphrase-fd",
+		"",
+		"File descriptor to read the wallet's passphrase from",
+	)
 	cmd.Flags().StringVarP(&f.PubKey,
 		"pubkey", "k",
 		"",
@@ -116,6 +121,7 @@ type AnnotateKeyFlags struct {
 	Wallet         string
 	PubKey         string
 	PassphraseFile string
+	PassphraseFD   string
 	Clear          bool
 	RawMetadata    []string
 }
@@ -146,7 +152,11 @@ func (f *AnnotateKeyFlags) Validate() (*wallet.AnnotateKeyRequest, error) {
 	}
 	req.Metadata = metadata
 
-	passphrase, err := flags.GetPassphrase(f.PassphraseFile)
+	passphrase, err := flags.GetPassphrase(flags.PassphraseSource{
+		Wallet: f.Wallet,
+		File:   f.PassphraseFile,
+		FD:     f.PassphraseFD,
+	})
 	if err != nil {
 		return nil, err
 	}
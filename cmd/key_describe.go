@@ -83,6 +83,11 @@ func BuildCmdDescribeKey(w io.Writer, handler DescribeKeyHandler, rf *RootFlags)
 		"",
 		"Path to the file containing the wallet's passphrase",
 	)
+	cmd.Flags().StringVar(&f.PassphraseFD,
+		"passphrase-fd",
+		"",
+		"File descriptor to read the wallet's passphrase from",
+	)
 
 	autoCompleteWallet(cmd, rf.Home)
 
@@ -92,6 +97,7 @@ func BuildCmdDescribeKey(w io.Writer, handler DescribeKeyHandler, rf *RootFlags)
 type DescribeKeyFlags struct {
 	Wallet         string
 	PassphraseFile string
+	PassphraseFD   string
 	PubKey         string
 }
 
@@ -108,7 +114,11 @@ func (f *DescribeKeyFlags) Validate() (*wallet.DescribeKeyRequest, error) {
 	}
 	req.PubKey = f.PubKey
 
-	passphrase, err := flags.GetPassphrase(f.PassphraseFile)
+	passphrase, err := flags.GetPassphrase(flags.PassphraseSource{
+		Wallet: f.Wallet,
+		File:   f.PassphraseFile,
+		FD:     f.PassphraseFD,
+	})
 	if err != nil {
 		return nil, err
 	}
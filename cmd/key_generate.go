@@ -85,6 +85,11 @@ func BuildCmdGenerateKey(w io.Writer, handler GenerateKeyHandler, rf *RootFlags)
 		"",
 		"Path to the file containing the wallet's passphrase",
 	)
+	cmd.Flags().StringVar(&f.PassphraseFD,
+		"passphrase-fd",
+		"",
+		"File descriptor to read the wallet's passphrase from",
+	)
 	cmd.Flags().StringSliceVarP(&f.RawMetadata,
 		"meta", "m",
 		[]string{},
@@ -99,6 +104,7 @@ func BuildCmdGenerateKey(w io.Writer, handler GenerateKeyHandler, rf *RootFlags)
 type GenerateKeyFlags struct {
 	Wallet         string
 	PassphraseFile string
+	PassphraseFD   string
 	RawMetadata    []string
 }
 
@@ -116,7 +122,11 @@ func (f *GenerateKeyFlags) Validate() (*wallet.GenerateKeyRequest, error) {
 	}
 	req.Metadata = metadata
 
-	passphrase, err := flags.GetPassphrase(f.PassphraseFile)
+	passphrase, err := flags.GetPassphrase(flags.PassphraseSource{
+		Wallet: f.Wallet,
+		File:   f.PassphraseFile,
+		FD:     f.PassphraseFD,
+	})
 	if err != nil {
 		return nil, err
 	}
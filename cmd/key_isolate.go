@@ -43,7 +43,12 @@ func NewCmdIsolateKey(w io.Writer, rf *RootFlags) *cobra.Command {
 			return nil, fmt.Errorf("couldn't initialise wallets store: %w", err)
 		}
 
-		return wallet.IsolateKey(s, req)
+		var resp *wallet.IsolateKeyResponse
+		err = retryWithTwoFactorCode(&req.TwoFactorCode, func() error {
+			resp, err = wallet.IsolateKey(s, req)
+			return err
+		})
+		return resp, err
 	}
 
 	return BuildCmdIsolateKey(w, h, rf)
@@ -94,6 +99,16 @@ func BuildCmdIsolateKey(w io.Writer, handler IsolateKeyHandler, rf *RootFlags) *
 		"",
 		"Path to the file containing the wallet's passphrase",
 	)
+	cmd.Flags().StringVar(&f.PassphraseFD,
+		"passphrase-fd",
+		"",
+		"File descriptor to read the wallet's passphrase from",
+	)
+	cmd.Flags().StringVar(&f.TwoFactorCode,
+		"2fa-code",
+		"",
+		"Current two-factor authentication code, or a recovery code. If required but not set, it's asked",
+	)
 
 	autoCompleteWallet(cmd, rf.Home)
 
@@ -104,10 +119,14 @@ type IsolateKeyFlags struct {
 	Wallet         string
 	PubKey         string
 	PassphraseFile string
+	PassphraseFD   string
+	TwoFactorCode  string
 }
 
 func (f *IsolateKeyFlags) Validate() (*wallet.IsolateKeyRequest, error) {
-	req := &wallet.IsolateKeyRequest{}
+	req := &wallet.IsolateKeyRequest{
+		TwoFactorCode: f.TwoFactorCode,
+	}
 
 	if len(f.Wallet) == 0 {
 		return nil, flags.FlagMustBeSpecifiedError("wallet")
@@ -119,7 +138,11 @@ func (f *IsolateKeyFlags) Validate() (*wallet.IsolateKeyRequest, error) {
 	}
 	req.PubKey = f.PubKey
 
-	passphrase, err := flags.GetPassphrase(f.PassphraseFile)
+	passphrase, err := flags.GetPassphrase(flags.PassphraseSource{
+		Wallet: f.Wallet,
+		File:   f.PassphraseFile,
+		FD:     f.PassphraseFD,
+	})
 	if err != nil {
 		return nil, err
 	}
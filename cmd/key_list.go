@@ -78,6 +78,11 @@ func BuildCmdListKeys(w io.Writer, handler ListKeysHandler, rf *RootFlags) *cobr
 		"",
 		"Path to the file containing the wallet's passphrase",
 	)
+	cmd.Flags().StringVar(&f.PassphraseFD,
+		"passphrase-fd",
+		"",
+		"File descriptor to read the wallet's passphrase from",
+	)
 
 	autoCompleteWallet(cmd, rf.Home)
 
@@ -87,6 +92,7 @@ func BuildCmdListKeys(w io.Writer, handler ListKeysHandler, rf *RootFlags) *cobr
 type ListKeysFlags struct {
 	Wallet         string
 	PassphraseFile string
+	PassphraseFD   string
 }
 
 func (f *ListKeysFlags) Validate() (*wallet.ListKeysRequest, error) {
@@ -97,7 +103,11 @@ func (f *ListKeysFlags) Validate() (*wallet.ListKeysRequest, error) {
 	}
 	req.Wallet = f.Wallet
 
-	passphrase, err := flags.GetPassphrase(f.PassphraseFile)
+	passphrase, err := flags.GetPassphrase(flags.PassphraseSource{
+		Wallet: f.Wallet,
+		File:   f.PassphraseFile,
+		FD:     f.PassphraseFD,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -1,11 +1,14 @@
 package cmd_test
 
 import (
+	"os"
+	"strconv"
 	"testing"
 
 	vgrand "code.vegaprotocol.io/shared/libs/rand"
 	"code.vegaprotocol.io/vegawallet/cmd"
 	"code.vegaprotocol.io/vegawallet/cmd/flags"
+	vgpassphrase "code.vegaprotocol.io/vegawallet/passphrase"
 	"code.vegaprotocol.io/vegawallet/wallet"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -14,6 +17,9 @@ import (
 func TestListKeysFlags(t *testing.T) {
 	t.Run("Valid flags succeeds", testListKeysFlagsValidFlagsSucceeds)
 	t.Run("Missing wallet fails", testListKeysFlagsMissingWalletFails)
+	t.Run("Passphrase from file descriptor succeeds", testListKeysFlagsPassphraseFromFileDescriptorSucceeds)
+	t.Run("Invalid file descriptor fails", testListKeysFlagsInvalidFileDescriptorFails)
+	t.Run("Passphrase from provider succeeds", testListKeysFlagsPassphraseFromProviderSucceeds)
 }
 
 func testListKeysFlagsValidFlagsSucceeds(t *testing.T) {
@@ -68,3 +74,66 @@ func newListKeysFlags(t *testing.T, testDir string) *cmd.ListKeysFlags {
 		PassphraseFile: passphraseFilePath,
 	}
 }
+
+func testListKeysFlagsPassphraseFromFileDescriptorSucceeds(t *testing.T) {
+	// given
+	passphrase := vgrand.RandomStr(10)
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	_, err = w.WriteString(passphrase + "\n")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	f := &cmd.ListKeysFlags{
+		Wallet:       vgrand.RandomStr(10),
+		PassphraseFD: strconv.Itoa(int(r.Fd())),
+	}
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, req)
+	assert.Equal(t, passphrase, req.Passphrase)
+}
+
+func testListKeysFlagsInvalidFileDescriptorFails(t *testing.T) {
+	// given
+	f := &cmd.ListKeysFlags{
+		Wallet:       vgrand.RandomStr(10),
+		PassphraseFD: "not-a-fd",
+	}
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	assert.ErrorIs(t, err, flags.InvalidFlagFormatError("passphrase-fd"))
+	assert.Nil(t, req)
+}
+
+func testListKeysFlagsPassphraseFromProviderSucceeds(t *testing.T) {
+	// given
+	walletName := vgrand.RandomStr(10)
+	passphrase := vgrand.RandomStr(10)
+	t.Setenv("TEST_VEGAWALLET_PASSPHRASE", passphrase)
+	flags.SetPassphraseProviders(&vgpassphrase.Config{
+		Wallets: map[string]vgpassphrase.Provider{
+			walletName: {Env: "TEST_VEGAWALLET_PASSPHRASE"},
+		},
+	})
+	defer flags.SetPassphraseProviders(nil)
+
+	f := &cmd.ListKeysFlags{
+		Wallet: walletName,
+	}
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, req)
+	assert.Equal(t, passphrase, req.Passphrase)
+}
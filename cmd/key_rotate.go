@@ -35,7 +35,12 @@ func NewCmdRotateKey(w io.Writer, rf *RootFlags) *cobra.Command {
 			return nil, fmt.Errorf("couldn't initialise wallets store: %w", err)
 		}
 
-		return wallet.RotateKey(s, req)
+		var resp *wallet.RotateKeyResponse
+		err = retryWithTwoFactorCode(&req.TwoFactorCode, func() error {
+			resp, err = wallet.RotateKey(s, req)
+			return err
+		})
+		return resp, err
 	}
 
 	return BuildCmdRotateKey(w, h, rf)
@@ -81,6 +86,11 @@ func BuildCmdRotateKey(w io.Writer, handler RotateKeyHandler, rf *RootFlags) *co
 		"",
 		"Path to the file containing the wallet's passphrase",
 	)
+	cmd.Flags().StringVar(&f.PassphraseFD,
+		"passphrase-fd",
+		"",
+		"File descriptor to read the wallet's passphrase from",
+	)
 	cmd.Flags().StringVar(&f.NewPublicKey,
 		"new-pubkey",
 		"",
@@ -101,6 +111,11 @@ func BuildCmdRotateKey(w io.Writer, handler RotateKeyHandler, rf *RootFlags) *co
 		0,
 		"Height of block where the public key change will take effect",
 	)
+	cmd.Flags().StringVar(&f.TwoFactorCode,
+		"2fa-code",
+		"",
+		"Current two-factor authentication code, or a recovery code. If required but not set, it's asked",
+	)
 
 	autoCompleteWallet(cmd, rf.Home)
 
@@ -110,14 +125,18 @@ func BuildCmdRotateKey(w io.Writer, handler RotateKeyHandler, rf *RootFlags) *co
 type RotateKeyFlags struct {
 	Wallet            string
 	PassphraseFile    string
+	PassphraseFD      string
 	NewPublicKey      string
 	CurrentPubKey     string
 	TxBlockHeight     uint64
 	TargetBlockHeight uint64
+	TwoFactorCode     string
 }
 
 func (f *RotateKeyFlags) Validate() (*wallet.RotateKeyRequest, error) {
-	req := &wallet.RotateKeyRequest{}
+	req := &wallet.RotateKeyRequest{
+		TwoFactorCode: f.TwoFactorCode,
+	}
 
 	if f.NewPublicKey == "" {
 		return nil, flags.FlagMustBeSpecifiedError("new-pubkey")
@@ -148,7 +167,11 @@ func (f *RotateKeyFlags) Validate() (*wallet.RotateKeyRequest, error) {
 	}
 	req.Wallet = f.Wallet
 
-	passphrase, err := flags.GetPassphrase(f.PassphraseFile)
+	passphrase, err := flags.GetPassphrase(flags.PassphraseSource{
+		Wallet: f.Wallet,
+		File:   f.PassphraseFile,
+		FD:     f.PassphraseFD,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -86,6 +86,11 @@ func BuildCmdTaintKey(w io.Writer, handler TaintKeyHandler, rf *RootFlags) *cobr
 		"",
 		"Path to the file containing the wallet's passphrase",
 	)
+	cmd.Flags().StringVar(&f.PassphraseFD,
+		"passphrase-fd",
+		"",
+		"File descriptor to read the wallet's passphrase from",
+	)
 
 	autoCompleteWallet(cmd, rf.Home)
 
@@ -96,6 +101,7 @@ type TaintKeyFlags struct {
 	Wallet         string
 	PubKey         string
 	PassphraseFile string
+	PassphraseFD   string
 }
 
 func (f *TaintKeyFlags) Validate() (*wallet.TaintKeyRequest, error) {
@@ -111,7 +117,11 @@ func (f *TaintKeyFlags) Validate() (*wallet.TaintKeyRequest, error) {
 	}
 	req.PubKey = f.PubKey
 
-	passphrase, err := flags.GetPassphrase(f.PassphraseFile)
+	passphrase, err := flags.GetPassphrase(flags.PassphraseSource{
+		Wallet: f.Wallet,
+		File:   f.PassphraseFile,
+		FD:     f.PassphraseFD,
+	})
 	if err != nil {
 		return nil, err
 	}
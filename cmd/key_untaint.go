@@ -85,6 +85,11 @@ func BuildCmdUntaintKey(w io.Writer, handler UntaintKeyHandler, rf *RootFlags) *
 		"",
 		"Path to the file containing the wallet's passphrase",
 	)
+	cmd.Flags().StringVar(&f.PassphraseFD,
+		"passphrase-fd",
+		"",
+		"File descriptor to read the wallet's passphrase from",
+	)
 
 	autoCompleteWallet(cmd, rf.Home)
 
@@ -95,6 +100,7 @@ type UntaintKeyFlags struct {
 	Wallet         string
 	PubKey         string
 	PassphraseFile string
+	PassphraseFD   string
 }
 
 func (f *UntaintKeyFlags) Validate() (*wallet.UntaintKeyRequest, error) {
@@ -110,7 +116,11 @@ func (f *UntaintKeyFlags) Validate() (*wallet.UntaintKeyRequest, error) {
 	}
 	req.PubKey = f.PubKey
 
-	passphrase, err := flags.GetPassphrase(f.PassphraseFile)
+	passphrase, err := flags.GetPassphrase(flags.PassphraseSource{
+		Wallet: f.Wallet,
+		File:   f.PassphraseFile,
+		FD:     f.PassphraseFD,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -57,14 +57,19 @@ func DefaultConfig() zap.Config {
 }
 
 func BuildJSONLogger(level string, vegaPaths paths.Paths, logsDir paths.StatePath) (*zap.Logger, string, error) {
-	cfg := DefaultConfig()
-
 	l, err := getLevel(level)
 	if err != nil {
 		return nil, "", err
 	}
 
-	cfg.Level = zap.NewAtomicLevelAt(*l)
+	return BuildJSONLoggerWithLevel(zap.NewAtomicLevelAt(*l), vegaPaths, logsDir)
+}
+
+// BuildJSONLoggerWithLevel builds a JSON logger whose level can be changed
+// while it's in use, through the specified atomic level.
+func BuildJSONLoggerWithLevel(level zap.AtomicLevel, vegaPaths paths.Paths, logsDir paths.StatePath) (*zap.Logger, string, error) {
+	cfg := DefaultConfig()
+	cfg.Level = level
 
 	pid := os.Getpid()
 	date := time.Now().UTC().Format("2006-01-02-15-04-05")
@@ -93,6 +93,11 @@ func BuildCmdSignMessage(w io.Writer, handler SignMessageHandler, rf *RootFlags)
 		"",
 		"Path to the file containing the wallet's passphrase",
 	)
+	cmd.Flags().StringVar(&f.PassphraseFD,
+		"passphrase-fd",
+		"",
+		"File descriptor to read the wallet's passphrase from",
+	)
 
 	autoCompleteWallet(cmd, rf.Home)
 
@@ -104,6 +109,7 @@ type SignMessageFlags struct {
 	PubKey         string
 	Message        string
 	PassphraseFile string
+	PassphraseFD   string
 }
 
 func (f *SignMessageFlags) Validate() (*wallet.SignMessageRequest, error) {
@@ -128,7 +134,11 @@ func (f *SignMessageFlags) Validate() (*wallet.SignMessageRequest, error) {
 	}
 	req.Message = decodedMessage
 
-	passphrase, err := flags.GetPassphrase(f.PassphraseFile)
+	passphrase, err := flags.GetPassphrase(flags.PassphraseSource{
+		Wallet: f.Wallet,
+		File:   f.PassphraseFile,
+		FD:     f.PassphraseFD,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -100,7 +100,29 @@ func PrintDescribeNetworkResponse(w io.Writer, resp *network.DescribeNetworkResp
 	p.NextLine().Text("Network").NextLine()
 	p.Text("  Name:         ").WarningText(resp.Name).NextLine()
 	p.Text("  Address:      ").WarningText(resp.Host).WarningText(":").WarningText(fmt.Sprint(resp.Port)).NextLine()
+	switch {
+	case resp.TLS.UsesCertificateFiles():
+		p.Text("  TLS:          ").WarningText(resp.TLS.CertFile).NextLine()
+	case resp.TLS.AutoGenerate:
+		p.Text("  TLS:          ").WarningText("generated certificate").NextLine()
+	default:
+		p.Text("  TLS:          ").WarningText("disabled").NextLine()
+	}
+	if resp.Metrics.IsEnabled() {
+		p.Text("  Metrics:      ").WarningText(resp.Metrics.Host).WarningText(":").WarningText(fmt.Sprint(resp.Metrics.Port)).NextLine()
+	} else {
+		p.Text("  Metrics:      ").WarningText("disabled").NextLine()
+	}
+	if resp.Admin.IsEnabled() {
+		p.Text("  Admin:        ").WarningText(resp.Admin.Host).WarningText(":").WarningText(fmt.Sprint(resp.Admin.Port)).NextLine()
+	} else {
+		p.Text("  Admin:        ").WarningText("disabled").NextLine()
+	}
 	p.Text("  Token expiry: ").WarningText(resp.TokenExpiry).NextLine()
+	if len(resp.WalletIdleTimeout) != 0 {
+		p.Text("  Idle timeout: ").WarningText(resp.WalletIdleTimeout).NextLine()
+	}
+	p.Text("  Idempotency:  ").WarningText(resp.IdempotencyWindow).NextLine()
 	p.Text("  Level:        ").WarningText(resp.Level)
 	p.NextSection()
 
@@ -8,9 +8,11 @@ import (
 	"code.vegaprotocol.io/vegawallet/cmd/cli"
 	"code.vegaprotocol.io/vegawallet/cmd/flags"
 	"code.vegaprotocol.io/vegawallet/cmd/printer"
+	"code.vegaprotocol.io/vegawallet/passphrase"
 	"code.vegaprotocol.io/vegawallet/version"
 
 	vgversion "code.vegaprotocol.io/shared/libs/version"
+	"code.vegaprotocol.io/shared/paths"
 	"github.com/blang/semver/v4"
 	"github.com/spf13/cobra"
 )
@@ -67,6 +69,12 @@ func BuildCmdRoot(w io.Writer, vh CheckVersionHandler) *cobra.Command {
 				return err
 			}
 
+			providers, err := passphrase.LoadConfig(paths.New(f.Home))
+			if err != nil {
+				return err
+			}
+			flags.SetPassphraseProviders(providers)
+
 			if !f.NoVersionCheck && f.Output == flags.InteractiveOutput {
 				p := printer.NewInteractivePrinter(w)
 				if version.IsUnreleased() {
@@ -115,15 +123,18 @@ func BuildCmdRoot(w io.Writer, vh CheckVersionHandler) *cobra.Command {
 	// Root commands
 	cmd.AddCommand(NewCmdInit(w, f))
 	cmd.AddCommand(NewCmdCompletion(w))
+	cmd.AddCommand(NewCmdDoctor(w, f))
 	cmd.AddCommand(NewCmdVersion(w, f))
 
 	// Sub-commands
+	cmd.AddCommand(NewCmdBackup(w, f))
 	cmd.AddCommand(NewCmdCommand(w, f))
 	cmd.AddCommand(NewCmdKey(w, f))
 	cmd.AddCommand(NewCmdNetwork(w, f))
 	cmd.AddCommand(NewCmdService(w, f))
 	cmd.AddCommand(NewCmdTx(w, f))
 	cmd.AddCommand(NewCmdMessage(w, f))
+	cmd.AddCommand(NewCmdTwoFactor(w, f))
 
 	// Wallet commands
 	// We don't have a wrapper sub-command for wallet commands.
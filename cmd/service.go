@@ -15,5 +15,11 @@ func NewCmdService(w io.Writer, rf *RootFlags) *cobra.Command {
 
 	cmd.AddCommand(NewCmdRunService(w, rf))
 	cmd.AddCommand(NewCmdListEndpoints(w, rf))
+	cmd.AddCommand(NewCmdSessions(w, rf))
+	cmd.AddCommand(NewCmdOrigins(w, rf))
+	cmd.AddCommand(NewCmdAPIKey(w, rf))
+	cmd.AddCommand(NewCmdAdminToken(w, rf))
+	cmd.AddCommand(NewCmdServiceKeys(w, rf))
+	cmd.AddCommand(NewCmdRequestService(w, rf))
 	return cmd
 }
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"code.vegaprotocol.io/shared/paths"
+	svcstore "code.vegaprotocol.io/vegawallet/service/store/v1"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdAdminToken(w io.Writer, rf *RootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "admin-token",
+		Short: "Manage the admin token of the service",
+		Long:  "Manage the token protecting the admin API, used to decide on the consent requests when the service runs without terminal",
+	}
+
+	cmd.AddCommand(NewCmdGenerateAdminToken(w, rf))
+	return cmd
+}
+
+func initialiseAdminTokenStore(home, networkName string) (*svcstore.AdminTokenStore, error) {
+	vegaPaths := paths.New(home)
+
+	if err := verifyNetworkExists(vegaPaths, networkName); err != nil {
+		return nil, err
+	}
+
+	adminTokenStore, err := svcstore.InitialiseAdminTokenStore(vegaPaths, networkName)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't initialise admin token store: %w", err)
+	}
+
+	return adminTokenStore, nil
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"code.vegaprotocol.io/vegawallet/cmd/cli"
+	"code.vegaprotocol.io/vegawallet/cmd/flags"
+	"code.vegaprotocol.io/vegawallet/cmd/printer"
+	"code.vegaprotocol.io/vegawallet/service"
+	"github.com/spf13/cobra"
+)
+
+var (
+	generateAdminTokenLong = cli.LongDesc(`
+		Generate the token protecting the admin API of the specified network.
+		The previous token, if any, is replaced, and refused right away, even
+		by a running service.
+
+		Only the hash of the token is saved, so it's only displayed once.
+	`)
+
+	generateAdminTokenExample = cli.Examples(`
+		# Generate the admin token
+		vegawallet service admin-token generate --network NETWORK
+	`)
+)
+
+type GenerateAdminTokenHandler func(network string) (*service.GenerateAdminTokenResponse, error)
+
+func NewCmdGenerateAdminToken(w io.Writer, rf *RootFlags) *cobra.Command {
+	h := func(network string) (*service.GenerateAdminTokenResponse, error) {
+		adminTokenStore, err := initialiseAdminTokenStore(rf.Home, network)
+		if err != nil {
+			return nil, err
+		}
+
+		return service.GenerateAdminToken(adminTokenStore)
+	}
+
+	return BuildCmdGenerateAdminToken(w, h, rf)
+}
+
+func BuildCmdGenerateAdminToken(w io.Writer, handler GenerateAdminTokenHandler, rf *RootFlags) *cobra.Command {
+	f := &GenerateAdminTokenFlags{}
+
+	cmd := &cobra.Command{
+		Use:     "generate",
+		Short:   "Generate the admin token",
+		Long:    generateAdminTokenLong,
+		Example: generateAdminTokenExample,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if err := f.Validate(); err != nil {
+				return err
+			}
+
+			resp, err := handler(f.Network)
+			if err != nil {
+				return err
+			}
+
+			switch rf.Output {
+			case flags.InteractiveOutput:
+				PrintGenerateAdminTokenResponse(w, resp)
+			case flags.JSONOutput:
+				return printer.FprintJSON(w, resp)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&f.Network,
+		"network", "n",
+		"",
+		"Network the admin token is used on",
+	)
+
+	autoCompleteNetwork(cmd, rf.Home)
+
+	return cmd
+}
+
+type GenerateAdminTokenFlags struct {
+	Network string
+}
+
+func (f *GenerateAdminTokenFlags) Validate() error {
+	if len(f.Network) == 0 {
+		return flags.FlagMustBeSpecifiedError("network")
+	}
+
+	return nil
+}
+
+func PrintGenerateAdminTokenResponse(w io.Writer, resp *service.GenerateAdminTokenResponse) {
+	p := printer.NewInteractivePrinter(w)
+
+	p.CheckMark().Text("Admin token generated").NextSection()
+	p.Text("Admin token:").NextLine()
+	p.WarningText(resp.Token).NextSection()
+	p.RedArrow().DangerText("Important").NextLine()
+	p.Text("1. Write down the admin token and store it somewhere safe and secure, now.").NextLine()
+	p.Text("2. The admin token ").DangerBold("will not").Text(" be displayed ever again.").NextLine()
+	p.Text("3. Do not share the admin token, as it lets approve the transactions of all the wallets.").NextSection()
+	p.BlueArrow().InfoText("Use it").NextLine()
+	p.Text("Send the admin token in the Authorization header of the admin API requests:").NextLine()
+	p.Code(fmt.Sprintf("Authorization: Bearer %s", resp.Token)).NextLine()
+}
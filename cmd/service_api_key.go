@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"code.vegaprotocol.io/shared/paths"
+	svcstore "code.vegaprotocol.io/vegawallet/service/store/v1"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdAPIKey(w io.Writer, rf *RootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "api-key",
+		Short: "Manage the API keys of the service",
+		Long:  "Manage the long-lived API keys used by headless clients, like trading bots, to authenticate to the service",
+	}
+
+	cmd.AddCommand(NewCmdCreateAPIKey(w, rf))
+	cmd.AddCommand(NewCmdListAPIKeys(w, rf))
+	cmd.AddCommand(NewCmdRevokeAPIKey(w, rf))
+	return cmd
+}
+
+func initialiseAPIKeysStore(home, networkName string) (*svcstore.APIKeysStore, error) {
+	vegaPaths := paths.New(home)
+
+	if err := verifyNetworkExists(vegaPaths, networkName); err != nil {
+		return nil, err
+	}
+
+	apiKeysStore, err := svcstore.InitialiseAPIKeysStore(vegaPaths, networkName)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't initialise API keys store: %w", err)
+	}
+
+	return apiKeysStore, nil
+}
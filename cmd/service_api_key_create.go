@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"code.vegaprotocol.io/vegawallet/cmd/cli"
+	"code.vegaprotocol.io/vegawallet/cmd/flags"
+	"code.vegaprotocol.io/vegawallet/cmd/printer"
+	wcommands "code.vegaprotocol.io/vegawallet/commands"
+	"code.vegaprotocol.io/vegawallet/service"
+	"code.vegaprotocol.io/vegawallet/wallets"
+	"github.com/spf13/cobra"
+)
+
+var (
+	createAPIKeyLong = cli.LongDesc(`
+		Create a long-lived API key, for headless clients like trading bots, to
+		authenticate to the service without a passphrase.
+
+		The API key is used in place of the token, in the Authorization header.
+		It's only displayed at creation, as only the hash of its secret is saved.
+
+		The passphrase file is used by the service to unlock the wallet on
+		startup. It's not copied, so it has to stay accessible to the service.
+
+		When the wallet has the two-factor authentication enabled, a current code
+		is required to create the API key, as the service won't ask for it.
+
+		The API key can be restricted to specific keys and commands, in the same
+		way as the tokens.
+	`)
+
+	createAPIKeyExample = cli.Examples(`
+		# Create an API key
+		vegawallet service api-key create --network NETWORK --wallet WALLET --passphrase-file PASSPHRASE_FILE
+
+		# Create an API key restricted to a key and to order submissions
+		vegawallet service api-key create --network NETWORK --wallet WALLET --passphrase-file PASSPHRASE_FILE --public-key PUBKEY --command orderSubmission
+
+		# Create a read-only API key
+		vegawallet service api-key create --network NETWORK --wallet WALLET --passphrase-file PASSPHRASE_FILE --read-only
+	`)
+)
+
+type CreateAPIKeyHandler func(network string, req *service.CreateAPIKeyRequest) (*service.CreateAPIKeyResponse, error)
+
+func NewCmdCreateAPIKey(w io.Writer, rf *RootFlags) *cobra.Command {
+	h := func(network string, req *service.CreateAPIKeyRequest) (*service.CreateAPIKeyResponse, error) {
+		passphrase, err := flags.ReadPassphraseFile(req.PassphraseFile)
+		if err != nil {
+			return nil, err
+		}
+
+		s, err := wallets.InitialiseStore(rf.Home)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't initialise wallets store: %w", err)
+		}
+
+		// The wallet is unlocked to ensure the service will be able to do it
+		// on startup. As the service won't verify the second factor, it's
+		// verified here.
+		handler := wallets.NewHandler(s)
+		err = retryWithTwoFactorCode(&req.TwoFactorCode, func() error {
+			return handler.LoginWallet(req.Wallet, passphrase, req.TwoFactorCode)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("couldn't unlock the wallet with the passphrase file: %w", err)
+		}
+
+		apiKeysStore, err := initialiseAPIKeysStore(rf.Home, network)
+		if err != nil {
+			return nil, err
+		}
+
+		return service.CreateAPIKey(apiKeysStore, req)
+	}
+
+	return BuildCmdCreateAPIKey(w, h, rf)
+}
+
+func BuildCmdCreateAPIKey(w io.Writer, handler CreateAPIKeyHandler, rf *RootFlags) *cobra.Command {
+	f := &CreateAPIKeyFlags{}
+
+	cmd := &cobra.Command{
+		Use:     "create",
+		Short:   "Create an API key",
+		Long:    createAPIKeyLong,
+		Example: createAPIKeyExample,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			req, err := f.Validate()
+			if err != nil {
+				return err
+			}
+
+			resp, err := handler(f.Network, req)
+			if err != nil {
+				return err
+			}
+
+			switch rf.Output {
+			case flags.InteractiveOutput:
+				PrintCreateAPIKeyResponse(w, resp)
+			case flags.JSONOutput:
+				return printer.FprintJSON(w, resp)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&f.Network,
+		"network", "n",
+		"",
+		"Network the API key is used on",
+	)
+	cmd.Flags().StringVarP(&f.Wallet,
+		"wallet", "w",
+		"",
+		"Wallet the API key gives access to",
+	)
+	cmd.Flags().StringVarP(&f.PassphraseFile,
+		"passphrase-file", "p",
+		"",
+		"Path to the file containing the wallet's passphrase, read by the service on startup",
+	)
+	cmd.Flags().StringVar(&f.TwoFactorCode,
+		"2fa-code",
+		"",
+		"Current two-factor authentication code, or a recovery code. If required but not set, it's asked",
+	)
+	cmd.Flags().StringVar(&f.Description,
+		"description",
+		"",
+		"Description of the API key, like the name of the bot using it",
+	)
+	cmd.Flags().StringSliceVar(&f.PublicKeys,
+		"public-key",
+		[]string{},
+		"Public key the API key is restricted to. Can be repeated",
+	)
+	cmd.Flags().StringSliceVar(&f.Commands,
+		"command",
+		[]string{},
+		"Command the API key is restricted to, like orderSubmission. Can be repeated",
+	)
+	cmd.Flags().BoolVar(&f.ReadOnly,
+		"read-only",
+		false,
+		"Only allow the API key to list and describe the keys",
+	)
+
+	autoCompleteNetwork(cmd, rf.Home)
+	autoCompleteWallet(cmd, rf.Home)
+
+	return cmd
+}
+
+type CreateAPIKeyFlags struct {
+	Network        string
+	Wallet         string
+	PassphraseFile string
+	TwoFactorCode  string
+	Description    string
+	PublicKeys     []string
+	Commands       []string
+	ReadOnly       bool
+}
+
+func (f *CreateAPIKeyFlags) Validate() (*service.CreateAPIKeyRequest, error) {
+	req := &service.CreateAPIKeyRequest{}
+
+	if len(f.Network) == 0 {
+		return nil, flags.FlagMustBeSpecifiedError("network")
+	}
+
+	if len(f.Wallet) == 0 {
+		return nil, flags.FlagMustBeSpecifiedError("wallet")
+	}
+	req.Wallet = f.Wallet
+
+	// The passphrase file is required, as the service reads it on startup.
+	if len(f.PassphraseFile) == 0 {
+		return nil, flags.FlagMustBeSpecifiedError("passphrase-file")
+	}
+	passphraseFile, err := filepath.Abs(f.PassphraseFile)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't resolve the passphrase file path: %w", err)
+	}
+	req.PassphraseFile = passphraseFile
+
+	supportedCommands := make([]interface{}, len(wcommands.CommandNames))
+	for i, name := range wcommands.CommandNames {
+		supportedCommands[i] = name
+	}
+	for _, command := range f.Commands {
+		if !wcommands.IsCommandName(command) {
+			return nil, flags.UnsupportedFlagValueError("command", command, supportedCommands)
+		}
+	}
+
+	if len(f.PublicKeys) != 0 || len(f.Commands) != 0 || f.ReadOnly {
+		req.Scopes = &service.Scopes{
+			PublicKeys: f.PublicKeys,
+			Commands:   f.Commands,
+			ReadOnly:   f.ReadOnly,
+		}
+	}
+
+	req.Description = f.Description
+	req.TwoFactorCode = f.TwoFactorCode
+
+	return req, nil
+}
+
+func PrintCreateAPIKeyResponse(w io.Writer, resp *service.CreateAPIKeyResponse) {
+	p := printer.NewInteractivePrinter(w)
+
+	p.CheckMark().Text("API key ").SuccessBold(resp.APIKey.ID).Text(" created for wallet ").SuccessBold(resp.APIKey.Wallet).NextSection()
+	p.Text("API key:").NextLine()
+	p.WarningText(resp.Key).NextSection()
+	p.RedArrow().DangerText("Important").NextLine()
+	p.Text("1. Write down the API key and store it somewhere safe and secure, now.").NextLine()
+	p.Text("2. The API key ").DangerBold("will not").Text(" be displayed ever again.").NextLine()
+	p.Text("3. Do not share the API key, as it gives access to the wallet without passphrase.").NextSection()
+	p.BlueArrow().InfoText("Use it").NextLine()
+	p.Text("Send the API key in the Authorization header, in place of the token:").NextLine()
+	p.Code(fmt.Sprintf("Authorization: Bearer %s", resp.Key)).NextLine()
+}
@@ -0,0 +1,120 @@
+package cmd_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	vgrand "code.vegaprotocol.io/shared/libs/rand"
+	"code.vegaprotocol.io/vegawallet/cmd"
+	"code.vegaprotocol.io/vegawallet/cmd/flags"
+	"code.vegaprotocol.io/vegawallet/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAPIKeyFlags(t *testing.T) {
+	t.Run("Valid flags succeeds", testCreateAPIKeyFlagsValidFlagsSucceeds)
+	t.Run("Missing network fails", testCreateAPIKeyFlagsMissingNetworkFails)
+	t.Run("Missing wallet fails", testCreateAPIKeyFlagsMissingWalletFails)
+	t.Run("Missing passphrase file fails", testCreateAPIKeyFlagsMissingPassphraseFileFails)
+	t.Run("Unsupported command fails", testCreateAPIKeyFlagsUnsupportedCommandFails)
+}
+
+func testCreateAPIKeyFlagsValidFlagsSucceeds(t *testing.T) {
+	testDir := t.TempDir()
+
+	// given
+	_, passphraseFilePath := NewPassphraseFile(t, testDir)
+	walletName := vgrand.RandomStr(10)
+	pubKey := vgrand.RandomStr(20)
+
+	f := &cmd.CreateAPIKeyFlags{
+		Network:        vgrand.RandomStr(10),
+		Wallet:         walletName,
+		PassphraseFile: passphraseFilePath,
+		Description:    "my bot",
+		PublicKeys:     []string{pubKey},
+		Commands:       []string{"orderSubmission"},
+	}
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	require.NoError(t, err)
+	absPassphraseFilePath, err := filepath.Abs(passphraseFilePath)
+	require.NoError(t, err)
+	assert.Equal(t, &service.CreateAPIKeyRequest{
+		Wallet:         walletName,
+		Description:    "my bot",
+		PassphraseFile: absPassphraseFilePath,
+		Scopes: &service.Scopes{
+			PublicKeys: []string{pubKey},
+			Commands:   []string{"orderSubmission"},
+		},
+	}, req)
+}
+
+func testCreateAPIKeyFlagsMissingNetworkFails(t *testing.T) {
+	// given
+	f := newCreateAPIKeyFlags(t)
+	f.Network = ""
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	assert.ErrorIs(t, err, flags.FlagMustBeSpecifiedError("network"))
+	assert.Nil(t, req)
+}
+
+func testCreateAPIKeyFlagsMissingWalletFails(t *testing.T) {
+	// given
+	f := newCreateAPIKeyFlags(t)
+	f.Wallet = ""
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	assert.ErrorIs(t, err, flags.FlagMustBeSpecifiedError("wallet"))
+	assert.Nil(t, req)
+}
+
+func testCreateAPIKeyFlagsMissingPassphraseFileFails(t *testing.T) {
+	// given
+	f := newCreateAPIKeyFlags(t)
+	f.PassphraseFile = ""
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	assert.ErrorIs(t, err, flags.FlagMustBeSpecifiedError("passphrase-file"))
+	assert.Nil(t, req)
+}
+
+func testCreateAPIKeyFlagsUnsupportedCommandFails(t *testing.T) {
+	// given
+	f := newCreateAPIKeyFlags(t)
+	f.Commands = []string{"doSomethingShady"}
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	assert.Error(t, err)
+	assert.Nil(t, req)
+}
+
+func newCreateAPIKeyFlags(t *testing.T) *cmd.CreateAPIKeyFlags {
+	t.Helper()
+
+	_, passphraseFilePath := NewPassphraseFile(t, t.TempDir())
+
+	return &cmd.CreateAPIKeyFlags{
+		Network:        vgrand.RandomStr(10),
+		Wallet:         vgrand.RandomStr(10),
+		PassphraseFile: passphraseFilePath,
+	}
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"io"
+	"strings"
+	"time"
+
+	"code.vegaprotocol.io/vegawallet/cmd/cli"
+	"code.vegaprotocol.io/vegawallet/cmd/flags"
+	"code.vegaprotocol.io/vegawallet/cmd/printer"
+	"code.vegaprotocol.io/vegawallet/service"
+	"github.com/spf13/cobra"
+)
+
+var (
+	listAPIKeysLong = cli.LongDesc(`
+		List the API keys of the service for the specified network. The secrets
+		of the API keys are not displayed, as they are not saved.
+	`)
+
+	listAPIKeysExample = cli.Examples(`
+		# List the API keys
+		vegawallet service api-key list --network NETWORK
+	`)
+)
+
+type ListAPIKeysHandler func(network string) (*service.ListAPIKeysResponse, error)
+
+func NewCmdListAPIKeys(w io.Writer, rf *RootFlags) *cobra.Command {
+	h := func(network string) (*service.ListAPIKeysResponse, error) {
+		apiKeysStore, err := initialiseAPIKeysStore(rf.Home, network)
+		if err != nil {
+			return nil, err
+		}
+
+		return service.ListAPIKeys(apiKeysStore)
+	}
+
+	return BuildCmdListAPIKeys(w, h, rf)
+}
+
+func BuildCmdListAPIKeys(w io.Writer, handler ListAPIKeysHandler, rf *RootFlags) *cobra.Command {
+	f := &ListAPIKeysFlags{}
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List the API keys",
+		Long:    listAPIKeysLong,
+		Example: listAPIKeysExample,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if err := f.Validate(); err != nil {
+				return err
+			}
+
+			resp, err := handler(f.Network)
+			if err != nil {
+				return err
+			}
+
+			switch rf.Output {
+			case flags.InteractiveOutput:
+				PrintListAPIKeysResponse(w, resp)
+			case flags.JSONOutput:
+				return printer.FprintJSON(w, resp)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&f.Network,
+		"network", "n",
+		"",
+		"Network the API keys are used on",
+	)
+
+	autoCompleteNetwork(cmd, rf.Home)
+
+	return cmd
+}
+
+type ListAPIKeysFlags struct {
+	Network string
+}
+
+func (f *ListAPIKeysFlags) Validate() error {
+	if len(f.Network) == 0 {
+		return flags.FlagMustBeSpecifiedError("network")
+	}
+
+	return nil
+}
+
+func PrintListAPIKeysResponse(w io.Writer, resp *service.ListAPIKeysResponse) {
+	p := printer.NewInteractivePrinter(w)
+
+	if len(resp.APIKeys) == 0 {
+		p.InfoText("No API key").NextLine()
+		return
+	}
+
+	for i, key := range resp.APIKeys {
+		if i != 0 {
+			p.NextLine()
+		}
+		p.Text("API key:         ").WarningText(key.ID).NextLine()
+		p.Text("Wallet:          ").WarningText(key.Wallet).NextLine()
+		if len(key.Description) != 0 {
+			p.Text("Description:     ").WarningText(key.Description).NextLine()
+		}
+		p.Text("Created at:      ").WarningText(key.CreatedAt.Format(time.RFC3339)).NextLine()
+		p.Text("Passphrase file: ").WarningText(key.PassphraseFile).NextLine()
+		if key.Scopes != nil {
+			if len(key.Scopes.PublicKeys) != 0 {
+				p.Text("Public keys:     ").WarningText(strings.Join(key.Scopes.PublicKeys, ", ")).NextLine()
+			}
+			if len(key.Scopes.Commands) != 0 {
+				p.Text("Commands:        ").WarningText(strings.Join(key.Scopes.Commands, ", ")).NextLine()
+			}
+			if key.Scopes.ReadOnly {
+				p.Text("Read-only:       ").WarningText("true").NextLine()
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"io"
+
+	"code.vegaprotocol.io/vegawallet/cmd/cli"
+	"code.vegaprotocol.io/vegawallet/cmd/flags"
+	"code.vegaprotocol.io/vegawallet/cmd/printer"
+	"code.vegaprotocol.io/vegawallet/service"
+	"github.com/spf13/cobra"
+)
+
+var (
+	revokeAPIKeyLong = cli.LongDesc(`
+		Revoke an API key. The API key is refused right away, even by a running
+		service.
+	`)
+
+	revokeAPIKeyExample = cli.Examples(`
+		# Revoke an API key
+		vegawallet service api-key revoke --network NETWORK --id API_KEY_ID
+	`)
+)
+
+type RevokeAPIKeyHandler func(network string, req *service.RevokeAPIKeyRequest) (*service.RevokeAPIKeyResponse, error)
+
+func NewCmdRevokeAPIKey(w io.Writer, rf *RootFlags) *cobra.Command {
+	h := func(network string, req *service.RevokeAPIKeyRequest) (*service.RevokeAPIKeyResponse, error) {
+		apiKeysStore, err := initialiseAPIKeysStore(rf.Home, network)
+		if err != nil {
+			return nil, err
+		}
+
+		return service.RevokeAPIKey(apiKeysStore, req)
+	}
+
+	return BuildCmdRevokeAPIKey(w, h, rf)
+}
+
+func BuildCmdRevokeAPIKey(w io.Writer, handler RevokeAPIKeyHandler, rf *RootFlags) *cobra.Command {
+	f := &RevokeAPIKeyFlags{}
+
+	cmd := &cobra.Command{
+		Use:     "revoke",
+		Short:   "Revoke an API key",
+		Long:    revokeAPIKeyLong,
+		Example: revokeAPIKeyExample,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			req, err := f.Validate()
+			if err != nil {
+				return err
+			}
+
+			resp, err := handler(f.Network, req)
+			if err != nil {
+				return err
+			}
+
+			switch rf.Output {
+			case flags.InteractiveOutput:
+				PrintRevokeAPIKeyResponse(w, resp)
+			case flags.JSONOutput:
+				return printer.FprintJSON(w, resp)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&f.Network,
+		"network", "n",
+		"",
+		"Network the API key is used on",
+	)
+	cmd.Flags().StringVar(&f.ID,
+		"id",
+		"",
+		"ID of the API key to revoke",
+	)
+
+	autoCompleteNetwork(cmd, rf.Home)
+
+	return cmd
+}
+
+type RevokeAPIKeyFlags struct {
+	Network string
+	ID      string
+}
+
+func (f *RevokeAPIKeyFlags) Validate() (*service.RevokeAPIKeyRequest, error) {
+	if len(f.Network) == 0 {
+		return nil, flags.FlagMustBeSpecifiedError("network")
+	}
+
+	if len(f.ID) == 0 {
+		return nil, flags.FlagMustBeSpecifiedError("id")
+	}
+
+	return &service.RevokeAPIKeyRequest{
+		ID: f.ID,
+	}, nil
+}
+
+func PrintRevokeAPIKeyResponse(w io.Writer, resp *service.RevokeAPIKeyResponse) {
+	p := printer.NewInteractivePrinter(w)
+	p.CheckMark().Text("API key ").SuccessBold(resp.Revoked.ID).Text(" of wallet ").SuccessBold(resp.Revoked.Wallet).Text(" revoked").NextLine()
+}
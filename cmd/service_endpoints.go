@@ -3,56 +3,33 @@ package cmd
 import (
 	"fmt"
 	"io"
-	"os"
-	"text/template"
+	"strings"
 
 	"code.vegaprotocol.io/vegawallet/cmd/cli"
 
 	"code.vegaprotocol.io/shared/paths"
 	"code.vegaprotocol.io/vegawallet/cmd/flags"
 	"code.vegaprotocol.io/vegawallet/cmd/printer"
+	"code.vegaprotocol.io/vegawallet/network"
 	netstore "code.vegaprotocol.io/vegawallet/network/store/v1"
+	"code.vegaprotocol.io/vegawallet/service"
 	"github.com/spf13/cobra"
 )
 
-const startupT = ` # Authentication
- - login:                   POST   {{.WalletServiceLocalAddress}}/api/v1/auth/token
- - logout:                  DELETE {{.WalletServiceLocalAddress}}/api/v1/auth/token
-
- # Network management
- - network:                 GET    {{.WalletServiceLocalAddress}}/api/v1/network
-
- # Wallet management
- - create a wallet:         POST   {{.WalletServiceLocalAddress}}/api/v1/wallets
- - import a wallet:         POST   {{.WalletServiceLocalAddress}}/api/v1/wallets/import
-
- # Key pair management
- - generate a key pair:     POST   {{.WalletServiceLocalAddress}}/api/v1/keys
- - list keys:               GET    {{.WalletServiceLocalAddress}}/api/v1/keys
- - describe a key pair:     GET    {{.WalletServiceLocalAddress}}/api/v1/keys/:keyid
- - taint a key pair:        PUT    {{.WalletServiceLocalAddress}}/api/v1/keys/:keyid/taint
- - annotate a key pair:     PUT    {{.WalletServiceLocalAddress}}/api/v1/keys/:keyid/metadata
-
- # Commands
- - sign a command:          POST   {{.WalletServiceLocalAddress}}/api/v1/command
- - sign a command (sync):   POST   {{.WalletServiceLocalAddress}}/api/v1/command/sync
- - sign a command (commit): POST   {{.WalletServiceLocalAddress}}/api/v1/command/commit
- - sign data:               POST   {{.WalletServiceLocalAddress}}/api/v1/sign
- - verify data:             POST   {{.WalletServiceLocalAddress}}/api/v1/verify
-
- # Information
- - get service status:      GET    {{.WalletServiceLocalAddress}}/api/v1/status
- - get the version:         GET    {{.WalletServiceLocalAddress}}/api/v1/version
-`
-
 var (
 	listEndpointsLong = cli.LongDesc(`
-		List the Vega wallet service HTTP endpoints
+		List the Vega wallet service HTTP endpoints.
+
+		With --openapi, the endpoints are described by an OpenAPI 3 document, as
+		served by the service at /api/v1/openapi.json.
 	`)
 
 	listEndpointsExample = cli.Examples(`
 		# List service endpoints
-		vegawallet endpoints --network NETWORK
+		vegawallet service endpoints --network NETWORK
+
+		# Print the OpenAPI document of the service
+		vegawallet service endpoints --network NETWORK --openapi
 	`)
 )
 
@@ -88,12 +65,18 @@ func BuildCmdListEndpoints(w io.Writer, handler ListEndpointsHandler, rf *RootFl
 		"",
 		"Network configuration to use",
 	)
+	cmd.Flags().BoolVar(&f.OpenAPI,
+		"openapi",
+		false,
+		"Print the OpenAPI document of the service",
+	)
 
 	return cmd
 }
 
 type ListEndpointsFlags struct {
 	Network string
+	OpenAPI bool
 }
 
 func (f *ListEndpointsFlags) Validate() error {
@@ -105,8 +88,6 @@ func (f *ListEndpointsFlags) Validate() error {
 }
 
 func ListEndpoints(w io.Writer, rf *RootFlags, f *ListEndpointsFlags) error {
-	p := printer.NewInteractivePrinter(w)
-
 	vegaPaths := paths.New(rf.Home)
 	netStore, err := netstore.InitialiseStore(vegaPaths)
 	if err != nil {
@@ -118,28 +99,51 @@ func ListEndpoints(w io.Writer, rf *RootFlags, f *ListEndpointsFlags) error {
 		return fmt.Errorf("couldn't initialise network store: %w", err)
 	}
 
-	serviceHost := fmt.Sprintf("http://%v:%v", cfg.Host, cfg.Port)
+	serviceHost := serviceURL(cfg)
+
+	if f.OpenAPI {
+		return printer.FprintJSON(w, service.NewOpenAPIDocument(serviceHost))
+	}
 
+	p := printer.NewInteractivePrinter(w)
 	p.BlueArrow().InfoText("Available endpoints").NextLine()
-	printServiceEndpoints(serviceHost)
+	printServiceEndpoints(w, serviceHost)
 	p.NextLine()
 
 	return nil
 }
 
-func printServiceEndpoints(serviceHost string) {
-	params := struct {
-		WalletServiceLocalAddress string
-	}{
-		WalletServiceLocalAddress: serviceHost,
+// serviceURL returns the URL of the service, with the scheme matching its TLS
+// configuration.
+func serviceURL(cfg *network.Network) string {
+	scheme := "http"
+	if cfg.TLS.IsEnabled() {
+		scheme = "https"
 	}
+	return fmt.Sprintf("%s://%v:%v", scheme, cfg.Host, cfg.Port)
+}
 
-	tmpl, err := template.New("wallet-cmdline").Parse(startupT)
-	if err != nil {
-		panic(err)
+// printServiceEndpoints lists the endpoints described by the OpenAPI document,
+// grouped as they are registered.
+func printServiceEndpoints(w io.Writer, serviceHost string) {
+	endpoints := service.Endpoints()
+
+	width := 0
+	for _, e := range endpoints {
+		if len(e.Summary) > width {
+			width = len(e.Summary)
+		}
 	}
-	err = tmpl.Execute(os.Stdout, params)
-	if err != nil {
-		panic(err)
+
+	group := ""
+	for _, e := range endpoints {
+		if e.Group != group {
+			if len(group) != 0 {
+				fmt.Fprintln(w)
+			}
+			group = e.Group
+			fmt.Fprintf(w, " # %s\n", group)
+		}
+		fmt.Fprintf(w, " - %-*s %-6s %s%s\n", width+1, strings.ToLower(e.Summary[:1])+e.Summary[1:]+":", e.Method, serviceHost, e.Path)
 	}
 }
@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+)
+
+func NewCmdServiceKeys(w io.Writer, rf *RootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "keys",
+		Short: "Manage the keys signing the tokens",
+		Long:  "Manage the keys the service signs and verifies the tokens with",
+	}
+
+	cmd.AddCommand(NewCmdRotateServiceKeys(w, rf))
+	return cmd
+}
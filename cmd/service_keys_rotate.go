@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"code.vegaprotocol.io/shared/paths"
+	"code.vegaprotocol.io/vegawallet/cmd/cli"
+	"code.vegaprotocol.io/vegawallet/cmd/flags"
+	"code.vegaprotocol.io/vegawallet/cmd/printer"
+	"code.vegaprotocol.io/vegawallet/network"
+	netstore "code.vegaprotocol.io/vegawallet/network/store/v1"
+	"code.vegaprotocol.io/vegawallet/service"
+	svcstore "code.vegaprotocol.io/vegawallet/service/store/v1"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rotateServiceKeysLong = cli.LongDesc(`
+		Replace the key pair the service signs the tokens with.
+
+		The tokens issued before the rotation remain valid until the end of the
+		grace period, as the previous public key keeps verifying them. By
+		default, the grace period is the longest token expiry of the networks,
+		so these tokens can be used until they expire. The new key is used once
+		the service is restarted.
+
+		The tokens can be signed with PS256, using an RSA-4096 key, or with
+		EdDSA, using an Ed25519 key. EdDSA keys are much faster to generate and
+		to verify.
+	`)
+
+	rotateServiceKeysExample = cli.Examples(`
+		# Rotate the keys
+		vegawallet service keys rotate
+
+		# Rotate the keys, and sign the tokens with Ed25519
+		vegawallet service keys rotate --algorithm EdDSA
+
+		# Rotate the keys, and refuse the previous tokens after 1 hour
+		vegawallet service keys rotate --grace-period 1h
+	`)
+)
+
+type RotateServiceKeysHandler func(*service.RotateServiceKeysRequest) (*service.RotateServiceKeysResponse, error)
+
+func NewCmdRotateServiceKeys(w io.Writer, rf *RootFlags) *cobra.Command {
+	h := func(req *service.RotateServiceKeysRequest) (*service.RotateServiceKeysResponse, error) {
+		svcStore, err := svcstore.InitialiseStore(paths.New(rf.Home))
+		if err != nil {
+			return nil, fmt.Errorf("couldn't initialise service store: %w", err)
+		}
+
+		if isInit, err := service.IsInitialised(svcStore); err != nil {
+			return nil, fmt.Errorf("couldn't verify service initialisation state: %w", err)
+		} else if !isInit {
+			return nil, ErrProgramIsNotInitialised
+		}
+
+		if req.GracePeriod == 0 {
+			gracePeriod, err := longestTokenExpiry(rf.Home)
+			if err != nil {
+				return nil, err
+			}
+			req.GracePeriod = gracePeriod
+		}
+
+		return service.RotateServiceKeys(svcStore, req)
+	}
+
+	return BuildCmdRotateServiceKeys(w, h, rf)
+}
+
+func BuildCmdRotateServiceKeys(w io.Writer, handler RotateServiceKeysHandler, rf *RootFlags) *cobra.Command {
+	f := &RotateServiceKeysFlags{}
+
+	cmd := &cobra.Command{
+		Use:     "rotate",
+		Short:   "Rotate the keys signing the tokens",
+		Long:    rotateServiceKeysLong,
+		Example: rotateServiceKeysExample,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			req, err := f.Validate()
+			if err != nil {
+				return err
+			}
+
+			resp, err := handler(req)
+			if err != nil {
+				return err
+			}
+
+			switch rf.Output {
+			case flags.InteractiveOutput:
+				PrintRotateServiceKeysResponse(w, resp)
+			case flags.JSONOutput:
+				return printer.FprintJSON(w, resp)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&f.Algorithm,
+		"algorithm",
+		service.SigningAlgorithmPS256,
+		fmt.Sprintf("Algorithm to sign the tokens with: %v", service.SupportedSigningAlgorithms),
+	)
+	cmd.Flags().DurationVar(&f.GracePeriod,
+		"grace-period",
+		0,
+		"Duration the previous public key keeps verifying the tokens it signed (default: the longest token expiry of the networks)",
+	)
+
+	return cmd
+}
+
+type RotateServiceKeysFlags struct {
+	Algorithm   string
+	GracePeriod time.Duration
+}
+
+func (f *RotateServiceKeysFlags) Validate() (*service.RotateServiceKeysRequest, error) {
+	if !isSupportedSigningAlgorithm(f.Algorithm) {
+		supported := make([]interface{}, 0, len(service.SupportedSigningAlgorithms))
+		for _, algorithm := range service.SupportedSigningAlgorithms {
+			supported = append(supported, algorithm)
+		}
+		return nil, flags.UnsupportedFlagValueError("algorithm", f.Algorithm, supported)
+	}
+
+	if f.GracePeriod < 0 {
+		return nil, flags.InvalidFlagFormatError("grace-period")
+	}
+
+	return &service.RotateServiceKeysRequest{
+		Algorithm:   f.Algorithm,
+		GracePeriod: f.GracePeriod,
+	}, nil
+}
+
+func longestTokenExpiry(home string) (time.Duration, error) {
+	netStore, err := netstore.InitialiseStore(paths.New(home))
+	if err != nil {
+		return 0, fmt.Errorf("couldn't initialise network store: %w", err)
+	}
+
+	names, err := netStore.ListNetworks()
+	if err != nil {
+		return 0, fmt.Errorf("couldn't list the networks: %w", err)
+	}
+
+	nets := make([]*network.Network, 0, len(names))
+	for _, name := range names {
+		net, err := netStore.GetNetwork(name)
+		if err != nil {
+			return 0, fmt.Errorf("couldn't get network %s: %w", name, err)
+		}
+		nets = append(nets, net)
+	}
+
+	return service.KeysGracePeriod(nets), nil
+}
+
+func isSupportedSigningAlgorithm(algorithm string) bool {
+	for _, supported := range service.SupportedSigningAlgorithms {
+		if supported == algorithm {
+			return true
+		}
+	}
+	return false
+}
+
+func PrintRotateServiceKeysResponse(w io.Writer, resp *service.RotateServiceKeysResponse) {
+	p := printer.NewInteractivePrinter(w)
+
+	p.CheckMark().Text("Tokens are now signed with key ").SuccessBold(resp.CurrentKey.ID).Text(" (").Text(resp.CurrentKey.Algorithm).Text(")").NextSection()
+
+	p.Text("Previous keys still verifying the tokens they signed:").NextLine()
+	for _, key := range resp.PreviousKeys {
+		p.Text("- ").Bold(key.ID).Text(" (").Text(key.Algorithm).Text("), until ").WarningText(key.ExpiresAt.Format(time.RFC3339)).NextLine()
+	}
+	p.NextLine()
+
+	p.BlueArrow().InfoText("Restart the service").NextLine()
+	p.Text("A running service keeps signing the tokens with the previous key until it's restarted.").NextLine()
+}
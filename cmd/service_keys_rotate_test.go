@@ -0,0 +1,69 @@
+package cmd_test
+
+import (
+	"testing"
+	"time"
+
+	"code.vegaprotocol.io/vegawallet/cmd"
+	"code.vegaprotocol.io/vegawallet/cmd/flags"
+	"code.vegaprotocol.io/vegawallet/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotateServiceKeysFlags(t *testing.T) {
+	t.Run("Valid flags succeeds", testRotateServiceKeysFlagsValidFlagsSucceeds)
+	t.Run("Unsupported algorithm fails", testRotateServiceKeysFlagsUnsupportedAlgorithmFails)
+	t.Run("Negative grace period fails", testRotateServiceKeysFlagsNegativeGracePeriodFails)
+}
+
+func testRotateServiceKeysFlagsValidFlagsSucceeds(t *testing.T) {
+	// given
+	f := &cmd.RotateServiceKeysFlags{
+		Algorithm:   service.SigningAlgorithmEdDSA,
+		GracePeriod: time.Hour,
+	}
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, &service.RotateServiceKeysRequest{
+		Algorithm:   service.SigningAlgorithmEdDSA,
+		GracePeriod: time.Hour,
+	}, req)
+}
+
+func testRotateServiceKeysFlagsUnsupportedAlgorithmFails(t *testing.T) {
+	// given
+	f := &cmd.RotateServiceKeysFlags{
+		Algorithm:   "HS256",
+		GracePeriod: time.Hour,
+	}
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	assert.ErrorIs(t, err, flags.UnsupportedFlagValueError("algorithm", "HS256", []interface{}{
+		service.SigningAlgorithmPS256,
+		service.SigningAlgorithmEdDSA,
+	}))
+	assert.Nil(t, req)
+}
+
+func testRotateServiceKeysFlagsNegativeGracePeriodFails(t *testing.T) {
+	// given
+	f := &cmd.RotateServiceKeysFlags{
+		Algorithm:   service.SigningAlgorithmPS256,
+		GracePeriod: -time.Hour,
+	}
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	assert.ErrorIs(t, err, flags.InvalidFlagFormatError("grace-period"))
+	assert.Nil(t, req)
+}
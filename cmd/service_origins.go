@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"code.vegaprotocol.io/shared/paths"
+	svcstore "code.vegaprotocol.io/vegawallet/service/store/v1"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdOrigins(w io.Writer, rf *RootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "origins",
+		Short: "Manage the origins approved to connect to the service",
+		Long:  "Manage the origins of the web pages approved to connect to the service",
+	}
+
+	cmd.AddCommand(NewCmdListOrigins(w, rf))
+	cmd.AddCommand(NewCmdRevokeOrigins(w, rf))
+	return cmd
+}
+
+func initialiseOriginsStore(home, networkName string) (*svcstore.OriginsStore, error) {
+	vegaPaths := paths.New(home)
+
+	if err := verifyNetworkExists(vegaPaths, networkName); err != nil {
+		return nil, err
+	}
+
+	originsStore, err := svcstore.InitialiseOriginsStore(vegaPaths, networkName)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't initialise origins store: %w", err)
+	}
+
+	return originsStore, nil
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"io"
+	"time"
+
+	"code.vegaprotocol.io/vegawallet/cmd/cli"
+	"code.vegaprotocol.io/vegawallet/cmd/flags"
+	"code.vegaprotocol.io/vegawallet/cmd/printer"
+	"code.vegaprotocol.io/vegawallet/service"
+	"github.com/spf13/cobra"
+)
+
+var (
+	listOriginsLong = cli.LongDesc(`
+		List the origins approved to connect to the service for the specified
+		network.
+	`)
+
+	listOriginsExample = cli.Examples(`
+		# List the approved origins
+		vegawallet service origins list --network NETWORK
+	`)
+)
+
+type ListOriginsHandler func(network string) (*service.ListApprovedOriginsResponse, error)
+
+func NewCmdListOrigins(w io.Writer, rf *RootFlags) *cobra.Command {
+	h := func(network string) (*service.ListApprovedOriginsResponse, error) {
+		originsStore, err := initialiseOriginsStore(rf.Home, network)
+		if err != nil {
+			return nil, err
+		}
+
+		return service.ListApprovedOrigins(originsStore)
+	}
+
+	return BuildCmdListOrigins(w, h, rf)
+}
+
+func BuildCmdListOrigins(w io.Writer, handler ListOriginsHandler, rf *RootFlags) *cobra.Command {
+	f := &ListOriginsFlags{}
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List the approved origins",
+		Long:    listOriginsLong,
+		Example: listOriginsExample,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if err := f.Validate(); err != nil {
+				return err
+			}
+
+			resp, err := handler(f.Network)
+			if err != nil {
+				return err
+			}
+
+			switch rf.Output {
+			case flags.InteractiveOutput:
+				PrintListOriginsResponse(w, resp)
+			case flags.JSONOutput:
+				return printer.FprintJSON(w, resp)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&f.Network,
+		"network", "n",
+		"",
+		"Network the origins have been approved for",
+	)
+
+	autoCompleteNetwork(cmd, rf.Home)
+
+	return cmd
+}
+
+type ListOriginsFlags struct {
+	Network string
+}
+
+func (f *ListOriginsFlags) Validate() error {
+	if len(f.Network) == 0 {
+		return flags.FlagMustBeSpecifiedError("network")
+	}
+
+	return nil
+}
+
+func PrintListOriginsResponse(w io.Writer, resp *service.ListApprovedOriginsResponse) {
+	p := printer.NewInteractivePrinter(w)
+
+	if len(resp.Origins) == 0 {
+		p.InfoText("No origin approved").NextLine()
+		return
+	}
+
+	for _, origin := range resp.Origins {
+		p.Text("- ").WarningText(origin.Origin).Text(" approved at ").Text(origin.ApprovedAt.Format(time.RFC3339)).NextLine()
+	}
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"io"
+
+	"code.vegaprotocol.io/vegawallet/cmd/cli"
+	"code.vegaprotocol.io/vegawallet/cmd/flags"
+	"code.vegaprotocol.io/vegawallet/cmd/printer"
+	"code.vegaprotocol.io/vegawallet/service"
+	"github.com/spf13/cobra"
+)
+
+var (
+	revokeOriginsLong = cli.LongDesc(`
+		Revoke the approval of origins for the specified network. The next
+		connection from a revoked origin has to be approved again.
+
+		A running service reloads the approved origins every few seconds, so the
+		revoked origins are no longer let through, without restart.
+	`)
+
+	revokeOriginsExample = cli.Examples(`
+		# Revoke the specified origin
+		vegawallet service origins revoke --network NETWORK --origin ORIGIN
+
+		# Revoke all the origins
+		vegawallet service origins revoke --network NETWORK --all
+	`)
+)
+
+type RevokeOriginsHandler func(network string, req *service.RevokeOriginsRequest) (*service.RevokeOriginsResponse, error)
+
+func NewCmdRevokeOrigins(w io.Writer, rf *RootFlags) *cobra.Command {
+	h := func(network string, req *service.RevokeOriginsRequest) (*service.RevokeOriginsResponse, error) {
+		originsStore, err := initialiseOriginsStore(rf.Home, network)
+		if err != nil {
+			return nil, err
+		}
+
+		return service.RevokeOrigins(originsStore, req)
+	}
+
+	return BuildCmdRevokeOrigins(w, h, rf)
+}
+
+func BuildCmdRevokeOrigins(w io.Writer, handler RevokeOriginsHandler, rf *RootFlags) *cobra.Command {
+	f := &RevokeOriginsFlags{}
+
+	cmd := &cobra.Command{
+		Use:     "revoke",
+		Short:   "Revoke approved origins",
+		Long:    revokeOriginsLong,
+		Example: revokeOriginsExample,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			req, err := f.Validate()
+			if err != nil {
+				return err
+			}
+
+			resp, err := handler(f.Network, req)
+			if err != nil {
+				return err
+			}
+
+			switch rf.Output {
+			case flags.InteractiveOutput:
+				PrintRevokeOriginsResponse(w, resp)
+			case flags.JSONOutput:
+				return printer.FprintJSON(w, resp)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&f.Network,
+		"network", "n",
+		"",
+		"Network the origins have been approved for",
+	)
+	cmd.Flags().StringVar(&f.Origin,
+		"origin",
+		"",
+		"Origin to revoke, like https://console.vega.xyz",
+	)
+	cmd.Flags().BoolVar(&f.All,
+		"all",
+		false,
+		"Revoke all the origins",
+	)
+
+	autoCompleteNetwork(cmd, rf.Home)
+
+	return cmd
+}
+
+type RevokeOriginsFlags struct {
+	Network string
+	Origin  string
+	All     bool
+}
+
+func (f *RevokeOriginsFlags) Validate() (*service.RevokeOriginsRequest, error) {
+	req := &service.RevokeOriginsRequest{}
+
+	if len(f.Network) == 0 {
+		return nil, flags.FlagMustBeSpecifiedError("network")
+	}
+
+	if len(f.Origin) == 0 && !f.All {
+		return nil, flags.OneOfFlagsMustBeSpecifiedError("origin", "all")
+	}
+	if len(f.Origin) != 0 && f.All {
+		return nil, flags.FlagsMutuallyExclusiveError("origin", "all")
+	}
+	req.Origin = f.Origin
+	req.All = f.All
+
+	return req, nil
+}
+
+func PrintRevokeOriginsResponse(w io.Writer, resp *service.RevokeOriginsResponse) {
+	p := printer.NewInteractivePrinter(w)
+
+	if len(resp.Revoked) == 0 {
+		p.InfoText("No origin to revoke").NextLine()
+		return
+	}
+
+	for _, origin := range resp.Revoked {
+		p.CheckMark().Text("Origin ").SuccessBold(origin.Origin).Text(" revoked").NextLine()
+	}
+	p.NextLine()
+	p.Text("The running services take this into account within a few seconds.").NextLine()
+}
@@ -0,0 +1,82 @@
+package cmd_test
+
+import (
+	"testing"
+
+	vgrand "code.vegaprotocol.io/shared/libs/rand"
+	"code.vegaprotocol.io/vegawallet/cmd"
+	"code.vegaprotocol.io/vegawallet/cmd/flags"
+	"code.vegaprotocol.io/vegawallet/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevokeOriginsFlags(t *testing.T) {
+	t.Run("Valid flags succeeds", testRevokeOriginsFlagsValidFlagsSucceeds)
+	t.Run("Missing network fails", testRevokeOriginsFlagsMissingNetworkFails)
+	t.Run("Missing selector fails", testRevokeOriginsFlagsMissingSelectorFails)
+	t.Run("Both selectors fails", testRevokeOriginsFlagsBothSelectorsFails)
+}
+
+func testRevokeOriginsFlagsValidFlagsSucceeds(t *testing.T) {
+	// given
+	origin := "https://" + vgrand.RandomStr(5) + ".com"
+
+	f := &cmd.RevokeOriginsFlags{
+		Network: vgrand.RandomStr(10),
+		Origin:  origin,
+	}
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, &service.RevokeOriginsRequest{
+		Origin: origin,
+	}, req)
+}
+
+func testRevokeOriginsFlagsMissingNetworkFails(t *testing.T) {
+	// given
+	f := &cmd.RevokeOriginsFlags{
+		All: true,
+	}
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	assert.ErrorIs(t, err, flags.FlagMustBeSpecifiedError("network"))
+	assert.Nil(t, req)
+}
+
+func testRevokeOriginsFlagsMissingSelectorFails(t *testing.T) {
+	// given
+	f := &cmd.RevokeOriginsFlags{
+		Network: vgrand.RandomStr(10),
+	}
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	assert.ErrorIs(t, err, flags.OneOfFlagsMustBeSpecifiedError("origin", "all"))
+	assert.Nil(t, req)
+}
+
+func testRevokeOriginsFlagsBothSelectorsFails(t *testing.T) {
+	// given
+	f := &cmd.RevokeOriginsFlags{
+		Network: vgrand.RandomStr(10),
+		Origin:  "https://" + vgrand.RandomStr(5) + ".com",
+		All:     true,
+	}
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	assert.ErrorIs(t, err, flags.FlagsMutuallyExclusiveError("origin", "all"))
+	assert.Nil(t, req)
+}
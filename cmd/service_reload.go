@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"reflect"
+	"strings"
+	"time"
+
+	vgfs "code.vegaprotocol.io/shared/libs/fs"
+	"code.vegaprotocol.io/vegawallet/network"
+	netstore "code.vegaprotocol.io/vegawallet/network/store/v1"
+	"code.vegaprotocol.io/vegawallet/node"
+	"code.vegaprotocol.io/vegawallet/service"
+	"code.vegaprotocol.io/vegawallet/wallets"
+	"go.uber.org/zap"
+)
+
+// hotReloadInterval is the interval at which the wallets, the network
+// configuration and the approved origins files are checked for changes.
+const hotReloadInterval = 2 * time.Second
+
+// hotReloader watches the changes made to the wallets, the network
+// configuration and the approved origins, while the service is running, and
+// applies them.
+type hotReloader struct {
+	log *zap.Logger
+
+	handler *wallets.Handler
+
+	netStore           *netstore.Store
+	network            *network.Network
+	networkFingerprint [sha256.Size]byte
+	logLevel           zap.AtomicLevel
+	forwarder          *node.Forwarder
+	service            *service.Service
+}
+
+func newHotReloader(
+	log *zap.Logger,
+	handler *wallets.Handler,
+	netStore *netstore.Store,
+	net *network.Network,
+	logLevel zap.AtomicLevel,
+	forwarder *node.Forwarder,
+	svc *service.Service,
+) *hotReloader {
+	r := &hotReloader{
+		log:       log,
+		handler:   handler,
+		netStore:  netStore,
+		network:   net,
+		logLevel:  logLevel,
+		forwarder: forwarder,
+		service:   svc,
+	}
+
+	if buf, err := vgfs.ReadFile(netStore.GetNetworkPath(net.Name)); err == nil {
+		r.networkFingerprint = sha256.Sum256(buf)
+	}
+
+	return r
+}
+
+func (r *hotReloader) Watch(ctx context.Context) {
+	ticker := time.NewTicker(hotReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.reloadWallets()
+			r.reloadNetwork()
+			r.reloadOrigins()
+		}
+	}
+}
+
+func (r *hotReloader) reloadWallets() {
+	for _, reloaded := range r.handler.ReloadChangedWallets() {
+		if reloaded.LoggedOut {
+			r.log.Warn("wallet changed on disk and couldn't be reloaded, it has been logged out",
+				zap.String("wallet", reloaded.Name),
+				zap.Error(reloaded.Reason),
+			)
+		} else {
+			r.log.Info("wallet changed on disk and has been reloaded", zap.String("wallet", reloaded.Name))
+		}
+	}
+}
+
+func (r *hotReloader) reloadOrigins() {
+	if err := r.service.ReloadApprovedOrigins(); err != nil {
+		r.log.Error("couldn't reload the approved origins", zap.Error(err))
+	}
+}
+
+func (r *hotReloader) reloadNetwork() {
+	networkPath := r.netStore.GetNetworkPath(r.network.Name)
+	buf, err := vgfs.ReadFile(networkPath)
+	if err != nil {
+		r.log.Error("couldn't read the network configuration", zap.String("path", networkPath), zap.Error(err))
+		return
+	}
+
+	fingerprint := sha256.Sum256(buf)
+	if fingerprint == r.networkFingerprint {
+		return
+	}
+	r.networkFingerprint = fingerprint
+
+	updated, err := r.netStore.GetNetwork(r.network.Name)
+	if err != nil {
+		r.log.Error("couldn't load the updated network configuration, the current one is kept", zap.Error(err))
+		return
+	}
+
+	merged, applied, ignored := network.MergeReloadableFields(r.network, updated)
+
+	for _, change := range ignored {
+		r.log.Warn("network configuration field changed, but the service has to be restarted to apply it",
+			zap.String("field", change.Field),
+			zap.Any("previous", change.Previous),
+			zap.Any("current", change.Current),
+		)
+	}
+
+	if len(applied) == 0 {
+		return
+	}
+
+	if err := merged.EnsureCanConnectGRPCNode(); err != nil {
+		r.log.Error("the updated network configuration is invalid, the current one is kept", zap.Error(err))
+		return
+	}
+
+	nodesUpdated := true
+	if !reflect.DeepEqual(r.network.API.GRPC, merged.API.GRPC) {
+		if err := r.forwarder.UpdateNodes(merged.API.GRPC); err != nil {
+			r.log.Error("couldn't connect to the updated nodes, the current ones are kept", zap.Error(err))
+			merged.API.GRPC = r.network.API.GRPC
+			nodesUpdated = false
+		}
+	}
+
+	r.logLevel.SetLevel(merged.Level.Get())
+
+	for _, change := range applied {
+		if !nodesUpdated && strings.HasPrefix(change.Field, "API.GRPC.") {
+			continue
+		}
+		r.log.Info("network configuration field reloaded",
+			zap.String("field", change.Field),
+			zap.Any("previous", change.Previous),
+			zap.Any("current", change.Current),
+		)
+	}
+
+	r.network = merged
+	r.service.UpdateNetwork(merged)
+}
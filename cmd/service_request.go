@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	vgfs "code.vegaprotocol.io/shared/libs/fs"
+	"code.vegaprotocol.io/shared/paths"
+	"code.vegaprotocol.io/vegawallet/cmd/cli"
+	"code.vegaprotocol.io/vegawallet/cmd/flags"
+	"code.vegaprotocol.io/vegawallet/network"
+	netstore "code.vegaprotocol.io/vegawallet/network/store/v1"
+	"code.vegaprotocol.io/vegawallet/service"
+	svcstore "code.vegaprotocol.io/vegawallet/service/store/v1"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ErrDataIsNotValidJSON = errors.New("the data is not valid JSON")
+
+	supportedRequestMethods = []interface{}{
+		http.MethodGet,
+		http.MethodPost,
+		http.MethodPut,
+		http.MethodDelete,
+	}
+
+	requestServiceLong = cli.LongDesc(`
+		Call an endpoint of a running Vega wallet service, and print the
+		response.
+
+		When the network configuration sets a "socket" path, the service is
+		called on its Unix domain socket. Otherwise, it's called on its host and
+		port, trusting the local certificate authority if TLS is enabled. The
+		--socket flag calls the service on the specified socket, whatever the
+		network.
+
+		The token, or the API key, is sent in the Authorization header.
+	`)
+
+	requestServiceExample = cli.Examples(`
+		# Get the version of the service
+		vegawallet service request --network NETWORK /api/v1/version
+
+		# List the keys, on a specific socket
+		vegawallet service request --socket PATH --token TOKEN /api/v1/keys
+
+		# Sign a command
+		vegawallet service request --network NETWORK --token TOKEN --method POST --data DATA /api/v1/command
+	`)
+)
+
+type RequestServiceHandler func(*RequestServiceRequest) (*service.ClientResponse, error)
+
+func NewCmdRequestService(w io.Writer, rf *RootFlags) *cobra.Command {
+	h := func(req *RequestServiceRequest) (*service.ClientResponse, error) {
+		client, err := serviceClient(rf.Home, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return client.Do(req.Method, req.Endpoint, req.Token, req.Data)
+	}
+
+	return BuildCmdRequestService(w, h, rf)
+}
+
+func BuildCmdRequestService(w io.Writer, handler RequestServiceHandler, rf *RootFlags) *cobra.Command {
+	f := &RequestServiceFlags{}
+
+	cmd := &cobra.Command{
+		Use:     "request",
+		Short:   "Call an endpoint of a running service",
+		Long:    requestServiceLong,
+		Example: requestServiceExample,
+		RunE: func(_ *cobra.Command, args []string) error {
+			if aLen := len(args); aLen == 0 {
+				return flags.ArgMustBeSpecifiedError("endpoint")
+			} else if aLen > 1 {
+				return flags.TooManyArgsError("endpoint")
+			}
+			f.Endpoint = args[0]
+
+			req, err := f.Validate()
+			if err != nil {
+				return err
+			}
+
+			resp, err := handler(req)
+			if err != nil {
+				return err
+			}
+
+			if err := printServiceResponse(w, rf, resp); err != nil {
+				return err
+			}
+
+			if resp.StatusCode >= http.StatusBadRequest {
+				return fmt.Errorf("the service responded with the status %d", resp.StatusCode)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&f.Network,
+		"network", "n",
+		"",
+		"Network configuration of the service to call",
+	)
+	cmd.Flags().StringVar(&f.Socket,
+		"socket",
+		"",
+		"Path to the Unix domain socket of the service to call",
+	)
+	cmd.Flags().StringVarP(&f.Method,
+		"method", "X",
+		http.MethodGet,
+		fmt.Sprintf("HTTP method of the request: %v", supportedRequestMethods),
+	)
+	cmd.Flags().StringVarP(&f.Data,
+		"data", "d",
+		"",
+		"JSON body of the request",
+	)
+	cmd.Flags().StringVarP(&f.Token,
+		"token", "t",
+		"",
+		"Token, or API key, to authenticate with",
+	)
+
+	autoCompleteNetwork(cmd, rf.Home)
+
+	return cmd
+}
+
+type RequestServiceFlags struct {
+	Network  string
+	Socket   string
+	Method   string
+	Data     string
+	Token    string
+	Endpoint string
+}
+
+func (f *RequestServiceFlags) Validate() (*RequestServiceRequest, error) {
+	req := &RequestServiceRequest{
+		Token: f.Token,
+	}
+
+	if len(f.Network) == 0 && len(f.Socket) == 0 {
+		return nil, flags.OneOfFlagsMustBeSpecifiedError("network", "socket")
+	}
+	if len(f.Network) != 0 && len(f.Socket) != 0 {
+		return nil, flags.FlagsMutuallyExclusiveError("network", "socket")
+	}
+	req.Network = f.Network
+	req.Socket = f.Socket
+
+	method := strings.ToUpper(f.Method)
+	if !isSupportedRequestMethod(method) {
+		return nil, flags.UnsupportedFlagValueError("method", f.Method, supportedRequestMethods)
+	}
+	req.Method = method
+
+	if len(f.Data) != 0 {
+		if !json.Valid([]byte(f.Data)) {
+			return nil, ErrDataIsNotValidJSON
+		}
+		req.Data = []byte(f.Data)
+	}
+
+	if len(f.Endpoint) == 0 {
+		return nil, flags.ArgMustBeSpecifiedError("endpoint")
+	}
+	req.Endpoint = f.Endpoint
+
+	return req, nil
+}
+
+func isSupportedRequestMethod(method string) bool {
+	for _, m := range supportedRequestMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+type RequestServiceRequest struct {
+	Network  string
+	Socket   string
+	Method   string
+	Endpoint string
+	Token    string
+	Data     []byte
+}
+
+// serviceClient returns a client calling the service on the specified socket,
+// or on the address of the network.
+func serviceClient(home string, req *RequestServiceRequest) (*service.Client, error) {
+	if len(req.Socket) != 0 {
+		return service.NewUnixSocketClient(req.Socket), nil
+	}
+
+	vegaPaths := paths.New(home)
+	netStore, err := netstore.InitialiseStore(vegaPaths)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't initialise network store: %w", err)
+	}
+
+	exists, err := netStore.NetworkExists(req.Network)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't verify the network existence: %w", err)
+	}
+	if !exists {
+		return nil, network.NewNetworkDoesNotExistError(req.Network)
+	}
+
+	cfg, err := netStore.GetNetwork(req.Network)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get the network: %w", err)
+	}
+
+	if cfg.Socket.IsEnabled() {
+		return service.NewUnixSocketClient(cfg.Socket.Path), nil
+	}
+
+	if !cfg.TLS.IsEnabled() || cfg.TLS.UsesCertificateFiles() {
+		return service.NewClient(serviceURL(cfg)), nil
+	}
+
+	tlsStore, err := svcstore.InitialiseTLSStore(vegaPaths)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't initialise TLS store: %w", err)
+	}
+
+	caCert, err := vgfs.ReadFile(tlsStore.GetCertificateAuthorityPath())
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read the certificate authority: %w", err)
+	}
+
+	return service.NewTLSClient(serviceURL(cfg), caCert)
+}
+
+func printServiceResponse(w io.Writer, rf *RootFlags, resp *service.ClientResponse) error {
+	body := resp.Body
+	if rf.Output == flags.InteractiveOutput && json.Valid(body) {
+		indented := &bytes.Buffer{}
+		if err := json.Indent(indented, body, "", "  "); err == nil {
+			body = indented.Bytes()
+		}
+	}
+
+	if len(body) == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintf(w, "%s\n", bytes.TrimSpace(body)); err != nil {
+		return fmt.Errorf("couldn't print the response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package cmd_test
+
+import (
+	"testing"
+
+	vgrand "code.vegaprotocol.io/shared/libs/rand"
+	"code.vegaprotocol.io/vegawallet/cmd"
+	"code.vegaprotocol.io/vegawallet/cmd/flags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestServiceFlags(t *testing.T) {
+	t.Run("Valid flags succeeds", testRequestServiceFlagsValidFlagsSucceeds)
+	t.Run("Missing network and socket fails", testRequestServiceFlagsMissingNetworkAndSocketFails)
+	t.Run("Both network and socket specified fails", testRequestServiceFlagsBothNetworkAndSocketSpecifiedFails)
+	t.Run("Unsupported method fails", testRequestServiceFlagsUnsupportedMethodFails)
+	t.Run("Invalid data fails", testRequestServiceFlagsInvalidDataFails)
+	t.Run("Missing endpoint fails", testRequestServiceFlagsMissingEndpointFails)
+}
+
+func testRequestServiceFlagsValidFlagsSucceeds(t *testing.T) {
+	// given
+	socket := vgrand.RandomStr(10)
+	token := vgrand.RandomStr(10)
+
+	f := &cmd.RequestServiceFlags{
+		Socket:   socket,
+		Method:   "post",
+		Data:     `{"wallet": "my-wallet"}`,
+		Token:    token,
+		Endpoint: "/api/v1/keys",
+	}
+
+	expectedReq := &cmd.RequestServiceRequest{
+		Socket:   socket,
+		Method:   "POST",
+		Endpoint: "/api/v1/keys",
+		Token:    token,
+		Data:     []byte(`{"wallet": "my-wallet"}`),
+	}
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, expectedReq, req)
+}
+
+func testRequestServiceFlagsMissingNetworkAndSocketFails(t *testing.T) {
+	// given
+	f := newRequestServiceFlags(t)
+	f.Network = ""
+	f.Socket = ""
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	assert.ErrorIs(t, err, flags.OneOfFlagsMustBeSpecifiedError("network", "socket"))
+	assert.Nil(t, req)
+}
+
+func testRequestServiceFlagsBothNetworkAndSocketSpecifiedFails(t *testing.T) {
+	// given
+	f := newRequestServiceFlags(t)
+	f.Socket = vgrand.RandomStr(10)
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	assert.ErrorIs(t, err, flags.FlagsMutuallyExclusiveError("network", "socket"))
+	assert.Nil(t, req)
+}
+
+func testRequestServiceFlagsUnsupportedMethodFails(t *testing.T) {
+	// given
+	f := newRequestServiceFlags(t)
+	f.Method = "PATCH"
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	assert.Error(t, err)
+	assert.Nil(t, req)
+}
+
+func testRequestServiceFlagsInvalidDataFails(t *testing.T) {
+	// given
+	f := newRequestServiceFlags(t)
+	f.Data = `{"wallet":`
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	assert.ErrorIs(t, err, cmd.ErrDataIsNotValidJSON)
+	assert.Nil(t, req)
+}
+
+func testRequestServiceFlagsMissingEndpointFails(t *testing.T) {
+	// given
+	f := newRequestServiceFlags(t)
+	f.Endpoint = ""
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	assert.ErrorIs(t, err, flags.ArgMustBeSpecifiedError("endpoint"))
+	assert.Nil(t, req)
+}
+
+func newRequestServiceFlags(t *testing.T) *cmd.RequestServiceFlags {
+	t.Helper()
+
+	return &cmd.RequestServiceFlags{
+		Network:  vgrand.RandomStr(10),
+		Method:   "GET",
+		Endpoint: "/api/v1/version",
+	}
+}
@@ -2,20 +2,26 @@ package cmd
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
+	vgfs "code.vegaprotocol.io/shared/libs/fs"
 	vgterm "code.vegaprotocol.io/shared/libs/term"
 	vglog "code.vegaprotocol.io/shared/libs/zap"
 	"code.vegaprotocol.io/shared/paths"
 	"code.vegaprotocol.io/vegawallet/cmd/cli"
 	"code.vegaprotocol.io/vegawallet/cmd/flags"
 	"code.vegaprotocol.io/vegawallet/cmd/printer"
+	wcommands "code.vegaprotocol.io/vegawallet/commands"
+	"code.vegaprotocol.io/vegawallet/metrics"
 	"code.vegaprotocol.io/vegawallet/network"
 	netstore "code.vegaprotocol.io/vegawallet/network/store/v1"
 	"code.vegaprotocol.io/vegawallet/node"
@@ -32,7 +38,7 @@ import (
 
 const MaxConsentRequests = 100
 
-var ErrEnableAutomaticConsentFlagIsRequiredWithoutTTY = errors.New("--automatic-consent flag is required without TTY")
+var ErrEnableAutomaticConsentFlagIsRequiredWithoutTTY = errors.New("--automatic-consent or --admin-consent flag is required without TTY")
 
 var (
 	ErrProgramIsNotInitialised = errors.New("first, you need initialise the program, using the `init` command")
@@ -43,8 +49,61 @@ var (
 		By default, every incoming transactions will have to be reviewed in the
 		terminal.
 
+		The first connection from a web page, identified by its origin, has to be
+		approved in the terminal as well. The approved origins are saved per
+		network, and can be managed with the "service origins" commands. The
+		tokens can only be used from the origin that obtained them. When the
+		--automatic-consent flag is set, all the origins are approved.
+
 		To terminate the service, hit ctrl+c. 
 
+		While the service is running, the changes made to the wallets and to the
+		network configuration, by the command line for example, are detected. The
+		node hosts and the log level are reloaded, the other network fields
+		require a restart. The logged wallets that changed are logged out, unless
+		the --cache-passphrases flag is set, in which case they are decrypted
+		again with the passphrase used to log in, kept in memory.
+
+		By default, the sessions are lost when the service stops, and the
+		applications have to log in again. When the --persist-sessions flag is
+		set, the sessions are saved in a file, encrypted with a passphrase, and
+		the sessions whose tokens haven't expired are restored on startup. The
+		wallets of the restored sessions are unlocked with their passphrases,
+		saved alongside the sessions, unless the --relock-wallets flag is set,
+		in which case their passphrases are asked again on startup.
+
+		The wallets of the API keys, created with the "service api-key create"
+		command, are unlocked on startup with the passphrase files set at their
+		creation.
+
+		When the network configuration sets a "walletIdleTimeout", the wallets
+		that haven't been used for this duration are locked: their keys are
+		removed from memory. Their sessions are kept, but the wallets have to
+		be unlocked with their passphrase before signing again. The wallets
+		unlocked for the API keys are never locked, as headless clients can't
+		unlock them.
+
+		When the network configuration sets "tls", the service serves HTTPS,
+		either with the configured certificate files, or, with "autoGenerate",
+		with a certificate issued for the host by the local certificate
+		authority created at initialisation. The certificate fingerprint is
+		printed on startup. Without TLS, the service refuses to serve plain HTTP
+		on a host that isn't a loopback address, as the tokens and passphrases
+		would cross the network in clear text, unless the --force-plaintext
+		flag is set.
+
+		When the network configuration sets a "socket" path, the service is
+		also served on a Unix domain socket, whose access is restricted by its
+		file mode and ownership. With "disableTcp", it's only served on the
+		socket. The "service request" command calls the service on its socket.
+
+		When the --admin-consent flag is set, the consent requests are not asked
+		in the terminal, but queued on the admin API, served on the "admin" host
+		and port of the network configuration. It lets an operator console, or
+		another process, decide on them when the service runs without terminal.
+		The admin API is protected by the token generated with the "service
+		admin-token generate" command.
+
 		NOTE: The --output flag is ignored in this command.
 	`)
 
@@ -66,6 +125,21 @@ var (
 
 		# Start the service with automatic consent of incoming transactions
 		vegawallet service run --network NETWORK --automatic-consent
+
+		# Start the service and decide on the incoming transactions through the admin API
+		vegawallet service run --network NETWORK --admin-consent
+
+		# Start the service and reload the wallets changed during the sessions
+		vegawallet service run --network NETWORK --cache-passphrases
+
+		# Start the service and restore the sessions of the previous run
+		vegawallet service run --network NETWORK --persist-sessions
+
+		# Start the service, restore the sessions, and ask for the wallets' passphrases
+		vegawallet service run --network NETWORK --persist-sessions --relock-wallets
+
+		# Start the service on a non-loopback host, without TLS
+		vegawallet service run --network NETWORK --force-plaintext
 	`)
 )
 
@@ -121,6 +195,36 @@ func BuildCmdRunService(w io.Writer, handler RunServiceHandler, rf *RootFlags) *
 		false,
 		"Automatically approve incoming transaction. Only use this flag when you have absolute trust in incoming transactions! No logs on standard output.",
 	)
+	cmd.Flags().BoolVar(&f.EnableAdminConsent,
+		"admin-consent",
+		false,
+		"Queue the incoming transactions and connections on the admin API, to be approved by an operator, instead of asking in the terminal",
+	)
+	cmd.Flags().BoolVar(&f.CachePassphrases,
+		"cache-passphrases",
+		false,
+		"Keep the passphrases of the logged wallets in memory, to reload the wallets that changed on disk instead of logging them out",
+	)
+	cmd.Flags().BoolVar(&f.PersistSessions,
+		"persist-sessions",
+		false,
+		"Save the sessions in an encrypted file, to restore them when the service restarts",
+	)
+	cmd.Flags().StringVar(&f.SessionsPassphraseFile,
+		"sessions-passphrase-file",
+		"",
+		"Path to the file containing the passphrase of the sessions file",
+	)
+	cmd.Flags().BoolVar(&f.RelockWallets,
+		"relock-wallets",
+		false,
+		"Do not save the wallets' passphrases with the sessions, and ask for them when the sessions are restored",
+	)
+	cmd.Flags().BoolVar(&f.ForcePlaintext,
+		"force-plaintext",
+		false,
+		"Serve plain HTTP even if the host isn't a loopback address. The tokens and the passphrases cross the network in clear text",
+	)
 
 	autoCompleteNetwork(cmd, rf.Home)
 
@@ -133,6 +237,12 @@ type RunServiceFlags struct {
 	WithTokenDApp          bool
 	NoBrowser              bool
 	EnableAutomaticConsent bool
+	EnableAdminConsent     bool
+	CachePassphrases       bool
+	PersistSessions        bool
+	SessionsPassphraseFile string
+	RelockWallets          bool
+	ForcePlaintext         bool
 }
 
 func (f *RunServiceFlags) Validate() error {
@@ -140,10 +250,23 @@ func (f *RunServiceFlags) Validate() error {
 		return flags.FlagMustBeSpecifiedError("network")
 	}
 
+	if f.EnableAutomaticConsent && f.EnableAdminConsent {
+		return flags.FlagsMutuallyExclusiveError("automatic-consent", "admin-consent")
+	}
+
 	if f.NoBrowser && !f.WithConsole && !f.WithTokenDApp {
 		return flags.OneOfParentsFlagMustBeSpecifiedError("no-browser", "with-console", "with-token-dapp")
 	}
 
+	if !f.PersistSessions {
+		if len(f.SessionsPassphraseFile) != 0 {
+			return flags.OneOfParentsFlagMustBeSpecifiedError("sessions-passphrase-file", "persist-sessions")
+		}
+		if f.RelockWallets {
+			return flags.OneOfParentsFlagMustBeSpecifiedError("relock-wallets", "persist-sessions")
+		}
+	}
+
 	return nil
 }
 
@@ -156,6 +279,9 @@ func RunService(w io.Writer, rf *RootFlags, f *RunServiceFlags) error {
 	}
 
 	handler := wallets.NewHandler(store)
+	if f.CachePassphrases {
+		handler.EnablePassphraseCaching()
+	}
 
 	vegaPaths := paths.New(rf.Home)
 	netStore, err := netstore.InitialiseStore(vegaPaths)
@@ -179,7 +305,15 @@ func RunService(w io.Writer, rf *RootFlags, f *RunServiceFlags) error {
 	if err := verifyNetworkConfig(cfg, f); err != nil {
 		return err
 	}
-	svcLog, svcLogPath, err := BuildJSONLogger(cfg.Level.String(), vegaPaths, paths.WalletServiceLogsHome)
+
+	// The log level is shared by the loggers, so it can be reloaded.
+	level, err := getLevel(cfg.Level.String())
+	if err != nil {
+		return err
+	}
+	logLevel := zap.NewAtomicLevelAt(*level)
+
+	svcLog, svcLogPath, err := BuildJSONLoggerWithLevel(logLevel, vegaPaths, paths.WalletServiceLogsHome)
 	if err != nil {
 		return err
 	}
@@ -206,12 +340,35 @@ func RunService(w io.Writer, rf *RootFlags, f *RunServiceFlags) error {
 		return fmt.Errorf("couldn't initialise authentication: %w", err)
 	}
 
+	previousKeys, err := svcStore.GetPreviousSigningKeys()
+	if err != nil {
+		return fmt.Errorf("couldn't get the previous signing keys: %w", err)
+	}
+	if err := auth.UsePreviousSigningKeys(previousKeys); err != nil {
+		return fmt.Errorf("couldn't use the previous signing keys: %w", err)
+	}
+
+	if err := enableAPIKeys(p, svcLog.Named("api-keys"), vegaPaths, cfg.Name, auth, handler); err != nil {
+		return err
+	}
+
+	if f.PersistSessions {
+		if err := restoreSessions(p, svcLog.Named("sessions"), vegaPaths, cfg.Name, auth, handler, f); err != nil {
+			return err
+		}
+	}
+
+	// The wallets are kept unlocked per session, so revoking a token doesn't
+	// lock the wallet for the other sessions. It's set once the restored
+	// sessions' wallets are unlocked, so these sessions can retain them.
+	auth.UseWalletKeeper(handler)
+
 	forwarder, err := node.NewForwarder(svcLog.Named("forwarder"), cfg.API.GRPC)
 	if err != nil {
 		return fmt.Errorf("couldn't initialise the node forwarder: %w", err)
 	}
 
-	cliLog, cliLogPath, err := BuildJSONLogger(cfg.Level.String(), vegaPaths, paths.WalletCLILogsHome)
+	cliLog, cliLogPath, err := BuildJSONLoggerWithLevel(logLevel, vegaPaths, paths.WalletCLILogsHome)
 	if err != nil {
 		return err
 	}
@@ -221,18 +378,25 @@ func RunService(w io.Writer, rf *RootFlags, f *RunServiceFlags) error {
 
 	consentRequests := make(chan service.ConsentRequest, MaxConsentRequests)
 	defer close(consentRequests)
+	connectionRequests := make(chan service.ConnectionRequest, MaxConsentRequests)
+	defer close(connectionRequests)
 	sentTransactions := make(chan service.SentTransaction)
 	defer close(sentTransactions)
 
 	var policy service.Policy
-	if vgterm.HasTTY() {
+	var pendingConsents *service.PendingConsentPolicy
+	if f.EnableAdminConsent {
+		cliLog.Info("Admin consent enabled")
+		pendingConsents = service.NewPendingConsentPolicy(ctx)
+		policy = pendingConsents
+	} else if vgterm.HasTTY() {
 		cliLog.Info("TTY detected")
 		if f.EnableAutomaticConsent {
 			cliLog.Info("Automatic consent enabled")
 			policy = service.NewAutomaticConsentPolicy()
 		} else {
 			cliLog.Info("Explicit consent enabled")
-			policy = service.NewExplicitConsentPolicy(ctx, consentRequests, sentTransactions)
+			policy = service.NewExplicitConsentPolicy(ctx, consentRequests, connectionRequests, sentTransactions)
 		}
 	} else {
 		cliLog.Info("No TTY detected")
@@ -244,23 +408,143 @@ func RunService(w io.Writer, rf *RootFlags, f *RunServiceFlags) error {
 		policy = service.NewAutomaticConsentPolicy()
 	}
 
-	srv, err := service.NewService(svcLog.Named("api"), cfg, handler, auth, forwarder, policy)
+	var m *metrics.Metrics
+	if cfg.Metrics.IsEnabled() {
+		cliLog.Info("Metrics enabled")
+		m = metrics.New()
+		m.TrackActiveSessions(auth.CountActiveSessions)
+		m.TrackUnlockedWallets(handler.CountUnlockedWallets)
+		forwarder.UseMetrics(m)
+		policy = service.NewMeteredPolicy(policy, m)
+	}
+
+	originsStore, err := svcstore.InitialiseOriginsStore(vegaPaths, cfg.Name)
+	if err != nil {
+		return fmt.Errorf("couldn't initialise origins store: %w", err)
+	}
+
+	historyStore, err := svcstore.InitialiseHistoryStore(svcLog.Named("history"), vegaPaths, cfg.Name)
+	if err != nil {
+		return fmt.Errorf("couldn't initialise transaction history store: %w", err)
+	}
+
+	cert, err := getServiceCertificate(vegaPaths, cfg, f.EnableAdminConsent)
 	if err != nil {
 		return err
 	}
 
-	go func() {
-		defer cancel()
-		if err := srv.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			cliLog.Error("Error while starting HTTP server", zap.Error(err))
+	var adminSrv *service.AdminService
+	if f.EnableAdminConsent {
+		adminSrv, err = newAdminService(svcLog.Named("admin"), vegaPaths, cfg, pendingConsents)
+		if err != nil {
+			return err
 		}
-	}()
+	}
 
-	serviceHost := fmt.Sprintf("http://%v:%v", cfg.Host, cfg.Port)
-	if !f.EnableAutomaticConsent {
-		p.CheckMark().Text("HTTP service started at: ").SuccessText(serviceHost).NextLine()
+	srv, err := service.NewService(svcLog.Named("api"), cfg, handler, auth, forwarder, policy, originsStore, historyStore)
+	if err != nil {
+		return err
+	}
+	var metricsSrv *metrics.Server
+	if m != nil {
+		srv.UseMetrics(m)
+		metricsSrv = metrics.NewServer(cfg.Metrics, m)
+	}
+
+	if cfg.ServesTCP() {
+		go func() {
+			defer cancel()
+			var err error
+			if cert != nil {
+				err = srv.StartTLS(cert.CertFile, cert.KeyFile)
+			} else {
+				err = srv.Start()
+			}
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				cliLog.Error("Error while starting HTTP server", zap.Error(err))
+			}
+		}()
+	}
+
+	if cfg.Socket.IsEnabled() {
+		go func() {
+			defer cancel()
+			if err := srv.StartUnixSocket(cfg.Socket); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				cliLog.Error("Error while starting Unix socket server", zap.Error(err))
+			}
+		}()
+	}
+
+	if adminSrv != nil {
+		go func() {
+			defer cancel()
+			var err error
+			if cert != nil {
+				err = adminSrv.StartTLS(cert.CertFile, cert.KeyFile)
+			} else {
+				err = adminSrv.Start()
+			}
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				cliLog.Error("Error while starting admin HTTP server", zap.Error(err))
+			}
+		}()
+	}
+
+	if metricsSrv != nil {
+		go func() {
+			defer cancel()
+			if err := metricsSrv.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				cliLog.Error("Error while starting metrics HTTP server", zap.Error(err))
+			}
+		}()
+	}
+
+	reloader := newHotReloader(cliLog.Named("reloader"), handler, netStore, cfg, logLevel, forwarder, srv)
+	go reloader.Watch(ctx)
+
+	if idleTimeout := cfg.WalletIdleTimeout.Get(); idleTimeout > 0 {
+		go lockIdleWallets(ctx, cliLog.Named("idle-lock"), handler, idleTimeout)
+	}
+
+	if cfg.ServesTCP() {
+		serviceHost := serviceURL(cfg)
+		if !f.EnableAutomaticConsent {
+			p.CheckMark().Text("HTTP service started at: ").SuccessText(serviceHost).NextLine()
+			if cert != nil {
+				p.CheckMark().Text("TLS certificate fingerprint (SHA-256): ").SuccessText(cert.Fingerprint).NextLine()
+			}
+		}
+		cliLog.Info(fmt.Sprintf("HTTP service started at: %s", serviceHost))
+		if cert != nil {
+			cliLog.Info("TLS enabled", zap.String("certificate", cert.CertFile), zap.String("fingerprint", cert.Fingerprint))
+		}
+	}
+	if cfg.Socket.IsEnabled() {
+		if !f.EnableAutomaticConsent {
+			p.CheckMark().Text("Unix socket service started at: ").SuccessText(cfg.Socket.Path).NextLine()
+		}
+		cliLog.Info(fmt.Sprintf("Unix socket service started at: %s", cfg.Socket.Path))
+	}
+
+	if adminSrv != nil {
+		adminHost := adminURL(cfg, cert)
+		p.CheckMark().Text("Admin API started at: ").SuccessText(adminHost).NextLine()
+		cliLog.Info(fmt.Sprintf("Admin API started at: %s", adminHost))
+		defer func() {
+			if err = adminSrv.Stop(); err != nil {
+				cliLog.Error("Error while stopping admin HTTP server", zap.Error(err))
+			}
+		}()
+	}
+
+	if metricsSrv != nil {
+		cliLog.Info(fmt.Sprintf("Metrics served at: http://%v:%v/metrics", cfg.Metrics.Host, cfg.Metrics.Port))
+		defer func() {
+			if err = metricsSrv.Stop(); err != nil {
+				cliLog.Error("Error while stopping metrics HTTP server", zap.Error(err))
+			}
+		}()
 	}
-	cliLog.Info(fmt.Sprintf("HTTP service started at: %s", serviceHost))
 
 	defer func() {
 		if err = srv.Stop(); err != nil {
@@ -300,11 +584,236 @@ func RunService(w io.Writer, rf *RootFlags, f *RunServiceFlags) error {
 		p.NextLine()
 	}
 
-	waitSig(ctx, cancel, cliLog, consentRequests, sentTransactions, p)
+	waitSig(ctx, cancel, cliLog, consentRequests, connectionRequests, sentTransactions, p)
+
+	return nil
+}
+
+// newAdminService serves the pending consent requests on the admin API. The
+// admin token has to be generated beforehand, so the API is never left
+// unprotected.
+func newAdminService(log *zap.Logger, vegaPaths paths.Paths, cfg *network.Network, pendingConsents *service.PendingConsentPolicy) (*service.AdminService, error) {
+	adminTokenStore, err := svcstore.InitialiseAdminTokenStore(vegaPaths, cfg.Name)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't initialise admin token store: %w", err)
+	}
+
+	token, err := adminTokenStore.GetAdminToken()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get the admin token: %w", err)
+	}
+	if token == nil {
+		return nil, service.ErrAdminTokenNotGenerated
+	}
+
+	return service.NewAdminService(log, cfg.Admin, pendingConsents, adminTokenStore), nil
+}
+
+// adminURL returns the URL of the admin API. It's served with the certificate
+// of the service, if any.
+func adminURL(cfg *network.Network, cert *serviceCertificate) string {
+	scheme := "http"
+	if cert != nil {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%v:%v", scheme, cfg.Admin.Host, cfg.Admin.Port)
+}
+
+type sessionsAuth interface {
+	PersistSessions(service.SessionStore, service.PassphraseGetter) ([]service.Session, error)
+	RevokeWalletSessions(wallet string)
+}
+
+// restoreSessions restores the sessions persisted by the previous run of the
+// service, unlocks their wallets, and persists the upcoming sessions.
+func restoreSessions(
+	p *printer.InteractivePrinter,
+	log *zap.Logger,
+	vegaPaths paths.Paths,
+	networkName string,
+	auth sessionsAuth,
+	handler *wallets.Handler,
+	f *RunServiceFlags,
+) error {
+	passphrase, err := flags.GetPassphrase(flags.PassphraseSource{
+		File: f.SessionsPassphraseFile,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't get the sessions passphrase: %w", err)
+	}
+
+	sessionsStore, err := svcstore.InitialiseSessionsStore(vegaPaths, networkName, passphrase)
+	if err != nil {
+		return fmt.Errorf("couldn't initialise sessions store: %w", err)
+	}
+
+	var passphrases service.PassphraseGetter
+	if !f.RelockWallets {
+		handler.EnablePassphraseCaching()
+		passphrases = handler.CachedPassphrase
+	}
+
+	restored, err := auth.PersistSessions(sessionsStore, passphrases)
+	if err != nil {
+		return fmt.Errorf("couldn't restore the sessions: %w", err)
+	}
+
+	unlocked := map[string]bool{}
+	for _, session := range restored {
+		for _, name := range session.WalletNames() {
+			if _, ok := unlocked[name]; ok {
+				continue
+			}
+
+			if err := unlockRestoredWallet(p, handler, name, session.PassphraseFor(name)); err != nil {
+				auth.RevokeWalletSessions(name)
+				p.BangMark().WarningText("The sessions of the wallet \"").WarningText(name).WarningText("\" couldn't be restored: ").WarningText(err.Error()).NextLine()
+				log.Warn("couldn't unlock the wallet, its sessions have been revoked", zap.String("wallet", name), zap.Error(err))
+				unlocked[name] = false
+				continue
+			}
+			unlocked[name] = true
+		}
+	}
+
+	restoredCount := 0
+	for _, session := range restored {
+		if allUnlocked(unlocked, session.WalletNames()) {
+			restoredCount++
+		}
+	}
+	p.CheckMark().Text("Sessions restored: ").SuccessText(fmt.Sprintf("%d", restoredCount)).NextLine()
+	log.Info("sessions restored", zap.Int("count", restoredCount))
+
+	return nil
+}
+
+// unlockRestoredWallet logs in the wallet of a restored session, with the
+// passphrase saved alongside the session. If there is none, it's asked again.
+func unlockRestoredWallet(p *printer.InteractivePrinter, handler *wallets.Handler, name, passphrase string) error {
+	if len(passphrase) == 0 {
+		p.BlueArrow().Text("Unlocking the wallet ").Bold(name).Text(" to restore its sessions").NextLine()
+		var err error
+		passphrase, err = flags.GetPassphrase(flags.PassphraseSource{
+			Wallet: name,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return handler.LoginAuthorisedWallet(name, passphrase)
+}
+
+// allUnlocked verifies all the wallets of a restored session have been
+// unlocked. A session is revoked as soon as one of its wallets can't be.
+func allUnlocked(unlocked map[string]bool, names []string) bool {
+	for _, name := range names {
+		if !unlocked[name] {
+			return false
+		}
+	}
+	return true
+}
+
+type apiKeysAuth interface {
+	UseAPIKeys(service.APIKeyStore)
+}
+
+// enableAPIKeys enables the authentication with the API keys of the network,
+// and unlocks their wallets with the passphrase files set at their creation.
+func enableAPIKeys(
+	p *printer.InteractivePrinter,
+	log *zap.Logger,
+	vegaPaths paths.Paths,
+	networkName string,
+	auth apiKeysAuth,
+	handler *wallets.Handler,
+) error {
+	apiKeysStore, err := svcstore.InitialiseAPIKeysStore(vegaPaths, networkName)
+	if err != nil {
+		return fmt.Errorf("couldn't initialise API keys store: %w", err)
+	}
+
+	keys, err := apiKeysStore.GetAPIKeys()
+	if err != nil {
+		return fmt.Errorf("couldn't get the API keys: %w", err)
+	}
+
+	auth.UseAPIKeys(apiKeysStore)
+
+	unlocked := map[string]bool{}
+	for _, key := range keys {
+		if _, ok := unlocked[key.Wallet]; !ok {
+			if err := unlockAPIKeyWallet(handler, key); err != nil {
+				p.BangMark().WarningText("The wallet \"").WarningText(key.Wallet).WarningText("\" of the API keys couldn't be unlocked: ").WarningText(err.Error()).NextLine()
+				log.Warn("couldn't unlock the wallet of the API key", zap.String("api-key", key.ID), zap.String("wallet", key.Wallet), zap.Error(err))
+				unlocked[key.Wallet] = false
+				continue
+			}
+			log.Info("wallet of the API key unlocked", zap.String("api-key", key.ID), zap.String("wallet", key.Wallet))
+			unlocked[key.Wallet] = true
+		}
+
+		// Each API key keeps the wallet unlocked, whatever happens to the
+		// sessions of the wallet, and however long it's idle, as the headless
+		// clients can't unlock it.
+		if unlocked[key.Wallet] {
+			if err := handler.PinWallet(apiKeyHandle(key), key.Wallet); err != nil {
+				log.Warn("couldn't retain the wallet of the API key", zap.String("api-key", key.ID), zap.String("wallet", key.Wallet), zap.Error(err))
+			}
+		}
+	}
+
+	if len(keys) != 0 {
+		p.CheckMark().Text("API keys enabled: ").SuccessText(fmt.Sprintf("%d", len(keys))).NextLine()
+	}
 
 	return nil
 }
 
+// apiKeyHandle returns the handle under which the API key retains its wallet,
+// so it can't collide with the session IDs.
+func apiKeyHandle(key service.APIKey) string {
+	return "api-key:" + key.ID
+}
+
+func unlockAPIKeyWallet(handler *wallets.Handler, key service.APIKey) error {
+	passphrase, err := flags.ReadPassphraseFile(key.PassphraseFile)
+	if err != nil {
+		return err
+	}
+
+	return handler.LoginAuthorisedWallet(key.Wallet, passphrase)
+}
+
+// maxIdleLockInterval is the longest interval at which the idle wallets are
+// looked for.
+const maxIdleLockInterval = 10 * time.Second
+
+// lockIdleWallets locks the wallets that haven't been used for the idle
+// timeout, until the context is cancelled.
+func lockIdleWallets(ctx context.Context, log *zap.Logger, handler *wallets.Handler, idleTimeout time.Duration) {
+	interval := idleTimeout
+	if interval > maxIdleLockInterval {
+		interval = maxIdleLockInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, name := range handler.LockIdleWallets(idleTimeout) {
+				log.Info("wallet idle for too long, it has been locked", zap.String("wallet", name))
+			}
+		}
+	}
+}
+
 func verifyNetworkConfig(cfg *network.Network, f *RunServiceFlags) error {
 	if err := cfg.EnsureCanConnectGRPCNode(); err != nil {
 		return err
@@ -319,9 +828,77 @@ func verifyNetworkConfig(cfg *network.Network, f *RunServiceFlags) error {
 			return err
 		}
 	}
+	if !f.ForcePlaintext {
+		if err := cfg.EnsureServesSecurely(); err != nil {
+			return err
+		}
+	}
+	if f.EnableAdminConsent {
+		if err := cfg.EnsureCanServeAdmin(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+type serviceCertificate struct {
+	CertFile    string
+	KeyFile     string
+	Fingerprint string
+}
+
+// getServiceCertificate returns the certificate the service serves HTTPS
+// with. It's nil if the network doesn't enable TLS, or only serves the
+// service on a Unix domain socket. As the admin API is served with the same
+// certificate, the generated one also covers the admin host when served.
+func getServiceCertificate(vegaPaths paths.Paths, cfg *network.Network, servesAdmin bool) (*serviceCertificate, error) {
+	if !cfg.ServesTCP() || !cfg.TLS.IsEnabled() {
+		return nil, nil
+	}
+
+	cert := &serviceCertificate{
+		CertFile: cfg.TLS.CertFile,
+		KeyFile:  cfg.TLS.KeyFile,
+	}
+
+	if !cfg.TLS.UsesCertificateFiles() {
+		tlsStore, err := svcstore.InitialiseTLSStore(vegaPaths)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't initialise TLS store: %w", err)
+		}
+
+		hosts := []string{cfg.Host}
+		if servesAdmin && cfg.Admin.Host != cfg.Host {
+			hosts = append(hosts, cfg.Admin.Host)
+		}
+
+		if _, err := service.EnsureServerCertificate(tlsStore, cfg.Name, hosts...); err != nil {
+			return nil, fmt.Errorf("couldn't issue the server certificate: %w", err)
+		}
+
+		cert.CertFile, cert.KeyFile, err = tlsStore.GetServerCertificatePaths(cfg.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := tls.LoadX509KeyPair(cert.CertFile, cert.KeyFile); err != nil {
+		return nil, fmt.Errorf("couldn't load the TLS certificate: %w", err)
+	}
+
+	certPEM, err := vgfs.ReadFile(cert.CertFile)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read the TLS certificate: %w", err)
+	}
+
+	cert.Fingerprint, err = service.CertificateFingerprint(certPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	return cert, nil
+}
+
 func startConsole(log *zap.Logger, f *RunServiceFlags, cfg *network.Network, cancel context.CancelFunc, p *printer.InteractivePrinter) *proxy.Proxy {
 	cs := proxy.NewProxy(cfg.Console.LocalPort, cfg.Console.URL, cfg.API.GRPC.Hosts[0])
 	go func() {
@@ -375,6 +952,7 @@ func waitSig(
 	cancelFunc context.CancelFunc,
 	log *zap.Logger,
 	consentRequests chan service.ConsentRequest,
+	connectionRequests chan service.ConnectionRequest,
 	sentTransactions chan service.SentTransaction,
 	p *printer.InteractivePrinter,
 ) {
@@ -386,7 +964,7 @@ func waitSig(
 	signal.Notify(gracefulStop, syscall.SIGQUIT)
 
 	go func() {
-		if err := handleConsentRequests(ctx, log, consentRequests, sentTransactions, p); err != nil {
+		if err := handleConsentRequests(ctx, log, consentRequests, connectionRequests, sentTransactions, p); err != nil {
 			cancelFunc()
 		}
 	}()
@@ -404,13 +982,32 @@ func waitSig(
 	}
 }
 
-func handleConsentRequests(ctx context.Context, log *zap.Logger, consentRequests chan service.ConsentRequest, sentTransactions chan service.SentTransaction, p *printer.InteractivePrinter) error {
+func handleConsentRequests(ctx context.Context, log *zap.Logger, consentRequests chan service.ConsentRequest, connectionRequests chan service.ConnectionRequest, sentTransactions chan service.SentTransaction, p *printer.InteractivePrinter) error {
 	for {
 		select {
 		case <-ctx.Done():
 			// nothing to do
 			return nil
+		case connectionRequest := <-connectionRequests:
+			p.BlueArrow().Text("New connection from: ").InfoText(connectionRequest.Origin).NextLine()
+
+			if flags.YesOrNo("Do you approve the connection of this origin?") {
+				log.Info("user approved the connection of the origin", zap.String("origin", connectionRequest.Origin))
+				connectionRequest.Confirmation <- service.ConsentConfirmation{Decision: true}
+				p.CheckMark().SuccessText("Connection approved").NextSection()
+			} else {
+				log.Info("user rejected the connection of the origin", zap.String("origin", connectionRequest.Origin))
+				connectionRequest.Confirmation <- service.ConsentConfirmation{Decision: false}
+				p.BangMark().DangerText("Connection rejected").NextSection()
+			}
 		case consentRequest := <-consentRequests:
+			if len(consentRequest.Batch) != 0 {
+				if err := handleBatchConsentRequest(log, consentRequest, sentTransactions, p); err != nil {
+					return err
+				}
+				continue
+			}
+
 			m := jsonpb.Marshaler{Indent: "    "}
 			marshalledTx, err := m.MarshalToString(consentRequest.Tx)
 			if err != nil {
@@ -444,3 +1041,68 @@ func handleConsentRequests(ctx context.Context, log *zap.Logger, consentRequests
 		}
 	}
 }
+
+// handleBatchConsentRequest summarises the transactions of the batch, so they
+// can be approved at once, and reports the outcome of each of them.
+func handleBatchConsentRequest(log *zap.Logger, consentRequest service.ConsentRequest, sentTransactions chan service.SentTransaction, p *printer.InteractivePrinter) error {
+	m := jsonpb.Marshaler{}
+	commandCounts := map[string]int{}
+	commandNames := []string{}
+	marshalledTxs := make([]string, 0, len(consentRequest.Batch))
+	for _, tx := range consentRequest.Batch {
+		marshalledTx, err := m.MarshalToString(tx)
+		if err != nil {
+			log.Error("couldn't marshal transaction from consent request", zap.Error(err))
+			return err
+		}
+		marshalledTxs = append(marshalledTxs, marshalledTx)
+
+		name := wcommands.CommandName(tx)
+		if _, ok := commandCounts[name]; !ok {
+			commandNames = append(commandNames, name)
+		}
+		commandCounts[name]++
+	}
+
+	summary := make([]string, 0, len(commandNames))
+	for _, name := range commandNames {
+		summary = append(summary, fmt.Sprintf("%d %s", commandCounts[name], name))
+	}
+
+	p.BlueArrow().Text("New batch of ").InfoText(fmt.Sprintf("%d", len(consentRequest.Batch))).Text(" transactions received: ").InfoText(strings.Join(summary, ", ")).NextLine()
+	for i, marshalledTx := range marshalledTxs {
+		p.Text(fmt.Sprintf("  %d. ", i+1)).InfoText(marshalledTx).NextLine()
+	}
+
+	if !flags.DoYouApproveTx() {
+		log.Info("user rejected the signing of the batch", zap.String("batch-id", consentRequest.TxID))
+		consentRequest.Confirmation <- service.ConsentConfirmation{Decision: false}
+		p.BangMark().DangerText("Batch rejected").NextSection()
+		return nil
+	}
+
+	log.Info("user approved the signing of the batch", zap.String("batch-id", consentRequest.TxID))
+	consentRequest.Confirmation <- service.ConsentConfirmation{Decision: true}
+	p.CheckMark().SuccessText("Batch approved").NextLine()
+
+	failures := 0
+	for i := range consentRequest.Batch {
+		sentTx := <-sentTransactions
+		if sentTx.Error != nil {
+			failures++
+			log.Error("transaction failed", zap.String("ID", sentTx.TxID), zap.Error(sentTx.Error))
+			p.BangMark().DangerText(fmt.Sprintf("Transaction %d failed: ", i+1)).DangerText(sentTx.Error.Error()).NextLine()
+		} else {
+			log.Info("transaction sent", zap.String("ID", sentTx.TxID), zap.String("hash", sentTx.TxHash))
+			p.CheckMark().Text(fmt.Sprintf("Transaction %d with hash ", i+1)).SuccessText(sentTx.TxHash).Text(" sent!").NextLine()
+		}
+	}
+
+	if failures != 0 {
+		p.BangMark().DangerText(fmt.Sprintf("%d of %d transactions failed", failures, len(consentRequest.Batch))).NextSection()
+	} else {
+		p.CheckMark().SuccessText("Batch sent").NextSection()
+	}
+
+	return nil
+}
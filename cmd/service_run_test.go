@@ -14,6 +14,9 @@ func TestRunServiceFlags(t *testing.T) {
 	t.Run("Valid flags succeeds", testRunServiceFlagsValidFlagsSucceeds)
 	t.Run("Missing network fails", testRunServiceFlagsMissingNetworkFails)
 	t.Run("No browser without console nor token dApp fails", testRunServiceFlagsNoBrowserWithoutConsoleNorTokenDAppFails)
+	t.Run("Relocking wallets without persisting sessions fails", testRunServiceFlagsRelockingWalletsWithoutPersistingSessionsFails)
+	t.Run("Sessions passphrase without persisting sessions fails", testRunServiceFlagsSessionsPassphraseWithoutPersistingSessionsFails)
+	t.Run("Automatic and admin consents fails", testRunServiceFlagsAutomaticAndAdminConsentsFails)
 }
 
 func testRunServiceFlagsValidFlagsSucceeds(t *testing.T) {
@@ -60,6 +63,43 @@ func testRunServiceFlagsNoBrowserWithoutConsoleNorTokenDAppFails(t *testing.T) {
 	assert.ErrorIs(t, err, flags.OneOfParentsFlagMustBeSpecifiedError("no-browser", "with-console", "with-token-dapp"))
 }
 
+func testRunServiceFlagsRelockingWalletsWithoutPersistingSessionsFails(t *testing.T) {
+	// given
+	f := newRunServiceFlags(t)
+	f.RelockWallets = true
+
+	// when
+	err := f.Validate()
+
+	// then
+	assert.ErrorIs(t, err, flags.OneOfParentsFlagMustBeSpecifiedError("relock-wallets", "persist-sessions"))
+}
+
+func testRunServiceFlagsSessionsPassphraseWithoutPersistingSessionsFails(t *testing.T) {
+	// given
+	f := newRunServiceFlags(t)
+	f.SessionsPassphraseFile = vgrand.RandomStr(10)
+
+	// when
+	err := f.Validate()
+
+	// then
+	assert.ErrorIs(t, err, flags.OneOfParentsFlagMustBeSpecifiedError("sessions-passphrase-file", "persist-sessions"))
+}
+
+func testRunServiceFlagsAutomaticAndAdminConsentsFails(t *testing.T) {
+	// given
+	f := newRunServiceFlags(t)
+	f.EnableAutomaticConsent = true
+	f.EnableAdminConsent = true
+
+	// when
+	err := f.Validate()
+
+	// then
+	assert.ErrorIs(t, err, flags.FlagsMutuallyExclusiveError("automatic-consent", "admin-consent"))
+}
+
 func newRunServiceFlags(t *testing.T) *cmd.RunServiceFlags {
 	t.Helper()
 
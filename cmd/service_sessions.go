@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"code.vegaprotocol.io/shared/paths"
+	"code.vegaprotocol.io/vegawallet/network"
+	netstore "code.vegaprotocol.io/vegawallet/network/store/v1"
+	svcstore "code.vegaprotocol.io/vegawallet/service/store/v1"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdSessions(w io.Writer, rf *RootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sessions",
+		Short: "Manage the persisted sessions of the service",
+		Long:  "Manage the sessions persisted by the service, when started with the --persist-sessions flag",
+	}
+
+	cmd.AddCommand(NewCmdListSessions(w, rf))
+	cmd.AddCommand(NewCmdRevokeSessions(w, rf))
+	return cmd
+}
+
+// SessionsStoreRequest describes the sessions store to open.
+type SessionsStoreRequest struct {
+	Network    string
+	Passphrase string
+}
+
+func initialiseSessionsStore(home string, req *SessionsStoreRequest) (*svcstore.SessionsStore, error) {
+	vegaPaths := paths.New(home)
+
+	if err := verifyNetworkExists(vegaPaths, req.Network); err != nil {
+		return nil, err
+	}
+
+	sessionsStore, err := svcstore.InitialiseSessionsStore(vegaPaths, req.Network, req.Passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't initialise sessions store: %w", err)
+	}
+
+	return sessionsStore, nil
+}
+
+// verifyNetworkExists ensures the data saved by the service, for the specified
+// network, belongs to an existing network.
+func verifyNetworkExists(vegaPaths paths.Paths, name string) error {
+	netStore, err := netstore.InitialiseStore(vegaPaths)
+	if err != nil {
+		return fmt.Errorf("couldn't initialise network store: %w", err)
+	}
+
+	exists, err := netStore.NetworkExists(name)
+	if err != nil {
+		return fmt.Errorf("couldn't verify the network existence: %w", err)
+	}
+	if !exists {
+		return network.NewNetworkDoesNotExistError(name)
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"code.vegaprotocol.io/vegawallet/cmd/cli"
+	"code.vegaprotocol.io/vegawallet/cmd/flags"
+	"code.vegaprotocol.io/vegawallet/cmd/printer"
+	"code.vegaprotocol.io/vegawallet/service"
+	"github.com/spf13/cobra"
+)
+
+var (
+	listSessionsLong = cli.LongDesc(`
+		List the sessions persisted by the service for the specified network,
+		whose tokens haven't expired.
+	`)
+
+	listSessionsExample = cli.Examples(`
+		# List the persisted sessions
+		vegawallet service sessions list --network NETWORK
+	`)
+)
+
+type ListSessionsHandler func(*SessionsStoreRequest) (*service.ListSessionsResponse, error)
+
+func NewCmdListSessions(w io.Writer, rf *RootFlags) *cobra.Command {
+	h := func(req *SessionsStoreRequest) (*service.ListSessionsResponse, error) {
+		sessionsStore, err := initialiseSessionsStore(rf.Home, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return service.ListSessions(sessionsStore)
+	}
+
+	return BuildCmdListSessions(w, h, rf)
+}
+
+func BuildCmdListSessions(w io.Writer, handler ListSessionsHandler, rf *RootFlags) *cobra.Command {
+	f := &ListSessionsFlags{}
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List the persisted sessions",
+		Long:    listSessionsLong,
+		Example: listSessionsExample,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			req, err := f.Validate()
+			if err != nil {
+				return err
+			}
+
+			resp, err := handler(req)
+			if err != nil {
+				return err
+			}
+
+			switch rf.Output {
+			case flags.InteractiveOutput:
+				PrintListSessionsResponse(w, resp)
+			case flags.JSONOutput:
+				return printer.FprintJSON(w, resp)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&f.Network,
+		"network", "n",
+		"",
+		"Network the sessions belong to",
+	)
+	cmd.Flags().StringVarP(&f.PassphraseFile,
+		"passphrase-file", "p",
+		"",
+		"Path to the file containing the sessions' passphrase",
+	)
+	cmd.Flags().StringVar(&f.PassphraseFD,
+		"passphrase-fd",
+		"",
+		"File descriptor to read the sessions' passphrase from",
+	)
+
+	autoCompleteNetwork(cmd, rf.Home)
+
+	return cmd
+}
+
+type ListSessionsFlags struct {
+	Network        string
+	PassphraseFile string
+	PassphraseFD   string
+}
+
+func (f *ListSessionsFlags) Validate() (*SessionsStoreRequest, error) {
+	req := &SessionsStoreRequest{}
+
+	if len(f.Network) == 0 {
+		return nil, flags.FlagMustBeSpecifiedError("network")
+	}
+	req.Network = f.Network
+
+	passphrase, err := flags.GetPassphrase(flags.PassphraseSource{
+		File: f.PassphraseFile,
+		FD:   f.PassphraseFD,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req.Passphrase = passphrase
+
+	return req, nil
+}
+
+func PrintListSessionsResponse(w io.Writer, resp *service.ListSessionsResponse) {
+	p := printer.NewInteractivePrinter(w)
+
+	if len(resp.Sessions) == 0 {
+		p.InfoText("No session persisted").NextLine()
+		return
+	}
+
+	for i, session := range resp.Sessions {
+		if i != 0 {
+			p.NextLine()
+		}
+		p.Text("Session:          ").WarningText(session.ID).NextLine()
+		if len(session.Wallets) > 1 {
+			p.Text("Wallets:          ").WarningText(strings.Join(session.Wallets, ", ")).NextLine()
+		} else {
+			p.Text("Wallet:           ").WarningText(session.Wallet).NextLine()
+		}
+		p.Text("Created at:       ").WarningText(session.CreatedAt.Format(time.RFC3339)).NextLine()
+		p.Text("Expires at:       ").WarningText(session.ExpiresAt.Format(time.RFC3339)).NextLine()
+		if len(session.Origin) != 0 {
+			p.Text("Origin:           ").WarningText(session.Origin).NextLine()
+		}
+		p.Text("Passphrase saved: ").WarningText(fmt.Sprintf("%t", session.PassphraseSaved)).NextLine()
+	}
+}
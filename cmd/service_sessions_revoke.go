@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"io"
+
+	"code.vegaprotocol.io/vegawallet/cmd/cli"
+	"code.vegaprotocol.io/vegawallet/cmd/flags"
+	"code.vegaprotocol.io/vegawallet/cmd/printer"
+	"code.vegaprotocol.io/vegawallet/service"
+	"github.com/spf13/cobra"
+)
+
+var (
+	revokeSessionsLong = cli.LongDesc(`
+		Revoke the sessions persisted by the service for the specified network.
+		The tokens of the revoked sessions can't be used anymore, once the
+		service is restarted.
+
+		This command should be used while the service is stopped, as a running
+		service overwrites the persisted sessions with the ones it knows.
+	`)
+
+	revokeSessionsExample = cli.Examples(`
+		# Revoke the specified session
+		vegawallet service sessions revoke --network NETWORK --session SESSION
+
+		# Revoke all the sessions of the specified wallet
+		vegawallet service sessions revoke --network NETWORK --wallet WALLET
+
+		# Revoke all the sessions
+		vegawallet service sessions revoke --network NETWORK --all
+	`)
+)
+
+type RevokeSessionsHandler func(*SessionsStoreRequest, *service.RevokeSessionsRequest) (*service.RevokeSessionsResponse, error)
+
+func NewCmdRevokeSessions(w io.Writer, rf *RootFlags) *cobra.Command {
+	h := func(storeReq *SessionsStoreRequest, req *service.RevokeSessionsRequest) (*service.RevokeSessionsResponse, error) {
+		sessionsStore, err := initialiseSessionsStore(rf.Home, storeReq)
+		if err != nil {
+			return nil, err
+		}
+
+		return service.RevokeSessions(sessionsStore, req)
+	}
+
+	return BuildCmdRevokeSessions(w, h, rf)
+}
+
+func BuildCmdRevokeSessions(w io.Writer, handler RevokeSessionsHandler, rf *RootFlags) *cobra.Command {
+	f := &RevokeSessionsFlags{}
+
+	cmd := &cobra.Command{
+		Use:     "revoke",
+		Short:   "Revoke persisted sessions",
+		Long:    revokeSessionsLong,
+		Example: revokeSessionsExample,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			storeReq, req, err := f.Validate()
+			if err != nil {
+				return err
+			}
+
+			resp, err := handler(storeReq, req)
+			if err != nil {
+				return err
+			}
+
+			switch rf.Output {
+			case flags.InteractiveOutput:
+				PrintRevokeSessionsResponse(w, resp)
+			case flags.JSONOutput:
+				return printer.FprintJSON(w, resp)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&f.Network,
+		"network", "n",
+		"",
+		"Network the sessions belong to",
+	)
+	cmd.Flags().StringVar(&f.Session,
+		"session",
+		"",
+		"Session to revoke",
+	)
+	cmd.Flags().StringVarP(&f.Wallet,
+		"wallet", "w",
+		"",
+		"Wallet whose sessions should be revoked",
+	)
+	cmd.Flags().BoolVar(&f.All,
+		"all",
+		false,
+		"Revoke all the sessions",
+	)
+	cmd.Flags().StringVarP(&f.PassphraseFile,
+		"passphrase-file", "p",
+		"",
+		"Path to the file containing the sessions' passphrase",
+	)
+	cmd.Flags().StringVar(&f.PassphraseFD,
+		"passphrase-fd",
+		"",
+		"File descriptor to read the sessions' passphrase from",
+	)
+
+	autoCompleteNetwork(cmd, rf.Home)
+	autoCompleteWallet(cmd, rf.Home)
+
+	return cmd
+}
+
+type RevokeSessionsFlags struct {
+	Network        string
+	Session        string
+	Wallet         string
+	All            bool
+	PassphraseFile string
+	PassphraseFD   string
+}
+
+func (f *RevokeSessionsFlags) Validate() (*SessionsStoreRequest, *service.RevokeSessionsRequest, error) {
+	storeReq := &SessionsStoreRequest{}
+	req := &service.RevokeSessionsRequest{}
+
+	if len(f.Network) == 0 {
+		return nil, nil, flags.FlagMustBeSpecifiedError("network")
+	}
+	storeReq.Network = f.Network
+
+	selectors := 0
+	if len(f.Session) != 0 {
+		selectors++
+	}
+	if len(f.Wallet) != 0 {
+		selectors++
+	}
+	if f.All {
+		selectors++
+	}
+	if selectors == 0 {
+		return nil, nil, flags.OneOfManyFlagsMustBeSpecifiedError("session", "wallet", "all")
+	}
+	if len(f.Session) != 0 && len(f.Wallet) != 0 {
+		return nil, nil, flags.FlagsMutuallyExclusiveError("session", "wallet")
+	}
+	if f.All && len(f.Session) != 0 {
+		return nil, nil, flags.FlagsMutuallyExclusiveError("all", "session")
+	}
+	if f.All && len(f.Wallet) != 0 {
+		return nil, nil, flags.FlagsMutuallyExclusiveError("all", "wallet")
+	}
+	req.Session = f.Session
+	req.Wallet = f.Wallet
+	req.All = f.All
+
+	passphrase, err := flags.GetPassphrase(flags.PassphraseSource{
+		File: f.PassphraseFile,
+		FD:   f.PassphraseFD,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	storeReq.Passphrase = passphrase
+
+	return storeReq, req, nil
+}
+
+func PrintRevokeSessionsResponse(w io.Writer, resp *service.RevokeSessionsResponse) {
+	p := printer.NewInteractivePrinter(w)
+
+	if len(resp.Revoked) == 0 {
+		p.InfoText("No session to revoke").NextLine()
+		return
+	}
+
+	for _, session := range resp.Revoked {
+		p.CheckMark().Text("Session ").SuccessBold(session.ID).Text(" of wallet ").SuccessBold(session.Wallet).Text(" revoked").NextLine()
+	}
+	p.NextLine()
+	p.BangMark().WarningText("The running services have to be restarted to take this into account.").NextLine()
+}
@@ -0,0 +1,108 @@
+package cmd_test
+
+import (
+	"testing"
+
+	vgrand "code.vegaprotocol.io/shared/libs/rand"
+	"code.vegaprotocol.io/vegawallet/cmd"
+	"code.vegaprotocol.io/vegawallet/cmd/flags"
+	"code.vegaprotocol.io/vegawallet/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevokeSessionsFlags(t *testing.T) {
+	t.Run("Valid flags succeeds", testRevokeSessionsFlagsValidFlagsSucceeds)
+	t.Run("Missing network fails", testRevokeSessionsFlagsMissingNetworkFails)
+	t.Run("Missing selector fails", testRevokeSessionsFlagsMissingSelectorFails)
+	t.Run("Multiple selectors fails", testRevokeSessionsFlagsMultipleSelectorsFails)
+}
+
+func testRevokeSessionsFlagsValidFlagsSucceeds(t *testing.T) {
+	testDir := t.TempDir()
+
+	// given
+	passphrase, passphraseFilePath := NewPassphraseFile(t, testDir)
+	networkName := vgrand.RandomStr(10)
+	walletName := vgrand.RandomStr(10)
+
+	f := &cmd.RevokeSessionsFlags{
+		Network:        networkName,
+		Wallet:         walletName,
+		PassphraseFile: passphraseFilePath,
+	}
+
+	// when
+	storeReq, req, err := f.Validate()
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, &cmd.SessionsStoreRequest{
+		Network:    networkName,
+		Passphrase: passphrase,
+	}, storeReq)
+	assert.Equal(t, &service.RevokeSessionsRequest{
+		Wallet: walletName,
+	}, req)
+}
+
+func testRevokeSessionsFlagsMissingNetworkFails(t *testing.T) {
+	testDir := t.TempDir()
+
+	// given
+	f := newRevokeSessionsFlags(t, testDir)
+	f.Network = ""
+
+	// when
+	storeReq, req, err := f.Validate()
+
+	// then
+	assert.ErrorIs(t, err, flags.FlagMustBeSpecifiedError("network"))
+	assert.Nil(t, storeReq)
+	assert.Nil(t, req)
+}
+
+func testRevokeSessionsFlagsMissingSelectorFails(t *testing.T) {
+	testDir := t.TempDir()
+
+	// given
+	f := newRevokeSessionsFlags(t, testDir)
+	f.All = false
+
+	// when
+	storeReq, req, err := f.Validate()
+
+	// then
+	assert.ErrorIs(t, err, flags.OneOfManyFlagsMustBeSpecifiedError("session", "wallet", "all"))
+	assert.Nil(t, storeReq)
+	assert.Nil(t, req)
+}
+
+func testRevokeSessionsFlagsMultipleSelectorsFails(t *testing.T) {
+	testDir := t.TempDir()
+
+	// given
+	f := newRevokeSessionsFlags(t, testDir)
+	f.Wallet = vgrand.RandomStr(10)
+
+	// when
+	storeReq, req, err := f.Validate()
+
+	// then
+	assert.ErrorIs(t, err, flags.FlagsMutuallyExclusiveError("all", "wallet"))
+	assert.Nil(t, storeReq)
+	assert.Nil(t, req)
+}
+
+func newRevokeSessionsFlags(t *testing.T, testDir string) *cmd.RevokeSessionsFlags {
+	t.Helper()
+
+	_, passphraseFilePath := NewPassphraseFile(t, testDir)
+	networkName := vgrand.RandomStr(10)
+
+	return &cmd.RevokeSessionsFlags{
+		Network:        networkName,
+		All:            true,
+		PassphraseFile: passphraseFilePath,
+	}
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+
+	"code.vegaprotocol.io/vegawallet/cmd/flags"
+	"code.vegaprotocol.io/vegawallet/wallet"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdTwoFactor(w io.Writer, rf *RootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "2fa",
+		Short: "Manage the two-factor authentication of a wallet",
+		Long:  "Manage the TOTP two-factor authentication required to log in to a wallet, and for sensitive operations",
+	}
+
+	cmd.AddCommand(NewCmdEnableTwoFactor(w, rf))
+	cmd.AddCommand(NewCmdDisableTwoFactor(w, rf))
+	return cmd
+}
+
+// retryWithTwoFactorCode runs the operation again, with a code asked to the
+// user, when the wallet requires one and none has been specified.
+func retryWithTwoFactorCode(code *string, op func() error) error {
+	err := op()
+	if !errors.Is(err, wallet.ErrTwoFactorCodeRequired) || len(*code) != 0 {
+		return err
+	}
+
+	c, err := flags.ReadTwoFactorCode()
+	if err != nil {
+		return err
+	}
+	*code = c
+
+	return op()
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"code.vegaprotocol.io/vegawallet/cmd/cli"
+	"code.vegaprotocol.io/vegawallet/cmd/flags"
+	"code.vegaprotocol.io/vegawallet/cmd/printer"
+	"code.vegaprotocol.io/vegawallet/wallet"
+	"code.vegaprotocol.io/vegawallet/wallets"
+	"github.com/spf13/cobra"
+)
+
+var (
+	disableTwoFactorLong = cli.LongDesc(`
+		Disable the two-factor authentication on the specified wallet.
+
+		It requires a current code, or one of the recovery codes.
+	`)
+
+	disableTwoFactorExample = cli.Examples(`
+		# Disable the two-factor authentication
+		vegawallet 2fa disable --wallet WALLET
+
+		# Disable the two-factor authentication, specifying the code
+		vegawallet 2fa disable --wallet WALLET --2fa-code CODE
+	`)
+)
+
+type DisableTwoFactorHandler func(*wallet.DisableTwoFactorRequest) error
+
+func NewCmdDisableTwoFactor(w io.Writer, rf *RootFlags) *cobra.Command {
+	h := func(req *wallet.DisableTwoFactorRequest) error {
+		s, err := wallets.InitialiseStore(rf.Home)
+		if err != nil {
+			return fmt.Errorf("couldn't initialise wallets store: %w", err)
+		}
+
+		return retryWithTwoFactorCode(&req.TwoFactorCode, func() error {
+			return wallet.DisableTwoFactor(s, req)
+		})
+	}
+
+	return BuildCmdDisableTwoFactor(w, h, rf)
+}
+
+func BuildCmdDisableTwoFactor(w io.Writer, handler DisableTwoFactorHandler, rf *RootFlags) *cobra.Command {
+	f := &DisableTwoFactorFlags{}
+
+	cmd := &cobra.Command{
+		Use:     "disable",
+		Short:   "Disable the two-factor authentication",
+		Long:    disableTwoFactorLong,
+		Example: disableTwoFactorExample,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			req, err := f.Validate()
+			if err != nil {
+				return err
+			}
+
+			if err := handler(req); err != nil {
+				return err
+			}
+
+			if rf.Output == flags.InteractiveOutput {
+				p := printer.NewInteractivePrinter(w)
+				p.CheckMark().SuccessText("Two-factor authentication disabled on wallet ").SuccessBold(req.Wallet).NextLine()
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&f.Wallet,
+		"wallet", "w",
+		"",
+		"Name of the wallet to use",
+	)
+	cmd.Flags().StringVarP(&f.PassphraseFile,
+		"passphrase-file", "p",
+		"",
+		"Path to the file containing the wallet's passphrase",
+	)
+	cmd.Flags().StringVar(&f.PassphraseFD,
+		"passphrase-fd",
+		"",
+		"File descriptor to read the wallet's passphrase from",
+	)
+	cmd.Flags().StringVar(&f.TwoFactorCode,
+		"2fa-code",
+		"",
+		"Current two-factor authentication code, or a recovery code. If not set, it's asked",
+	)
+
+	autoCompleteWallet(cmd, rf.Home)
+
+	return cmd
+}
+
+type DisableTwoFactorFlags struct {
+	Wallet         string
+	PassphraseFile string
+	PassphraseFD   string
+	TwoFactorCode  string
+}
+
+func (f *DisableTwoFactorFlags) Validate() (*wallet.DisableTwoFactorRequest, error) {
+	req := &wallet.DisableTwoFactorRequest{
+		TwoFactorCode: f.TwoFactorCode,
+	}
+
+	if len(f.Wallet) == 0 {
+		return nil, flags.FlagMustBeSpecifiedError("wallet")
+	}
+	req.Wallet = f.Wallet
+
+	passphrase, err := flags.GetPassphrase(flags.PassphraseSource{
+		Wallet: f.Wallet,
+		File:   f.PassphraseFile,
+		FD:     f.PassphraseFD,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req.Passphrase = passphrase
+
+	return req, nil
+}
@@ -0,0 +1,63 @@
+package cmd_test
+
+import (
+	"testing"
+
+	vgrand "code.vegaprotocol.io/shared/libs/rand"
+	"code.vegaprotocol.io/vegawallet/cmd"
+	"code.vegaprotocol.io/vegawallet/cmd/flags"
+	"code.vegaprotocol.io/vegawallet/wallet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisableTwoFactorFlags(t *testing.T) {
+	t.Run("Valid flags succeeds", testDisableTwoFactorFlagsValidFlagsSucceeds)
+	t.Run("Missing wallet fails", testDisableTwoFactorFlagsMissingWalletFails)
+}
+
+func testDisableTwoFactorFlagsValidFlagsSucceeds(t *testing.T) {
+	testDir := t.TempDir()
+
+	// given
+	passphrase, passphraseFilePath := NewPassphraseFile(t, testDir)
+	walletName := vgrand.RandomStr(10)
+
+	f := &cmd.DisableTwoFactorFlags{
+		Wallet:         walletName,
+		PassphraseFile: passphraseFilePath,
+		TwoFactorCode:  "123456",
+	}
+
+	expectedReq := &wallet.DisableTwoFactorRequest{
+		Wallet:        walletName,
+		Passphrase:    passphrase,
+		TwoFactorCode: "123456",
+	}
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, req)
+	assert.Equal(t, expectedReq, req)
+}
+
+func testDisableTwoFactorFlagsMissingWalletFails(t *testing.T) {
+	testDir := t.TempDir()
+
+	// given
+	_, passphraseFilePath := NewPassphraseFile(t, testDir)
+	f := &cmd.DisableTwoFactorFlags{
+		PassphraseFile: passphraseFilePath,
+		TwoFactorCode:  "123456",
+	}
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	assert.ErrorIs(t, err, flags.FlagMustBeSpecifiedError("wallet"))
+	assert.Nil(t, req)
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"code.vegaprotocol.io/vegawallet/cmd/cli"
+	"code.vegaprotocol.io/vegawallet/cmd/flags"
+	"code.vegaprotocol.io/vegawallet/cmd/printer"
+	"code.vegaprotocol.io/vegawallet/wallet"
+	"code.vegaprotocol.io/vegawallet/wallets"
+	"github.com/spf13/cobra"
+)
+
+var (
+	enableTwoFactorLong = cli.LongDesc(`
+		Enable the TOTP two-factor authentication on the specified wallet.
+
+		The generated secret has to be registered in an authenticator
+		application. From then on, a current code is required to log in to the
+		wallet through the API, and to run sensitive commands like "key rotate"
+		or "key isolate".
+
+		Recovery codes are issued as well. Each of them can be used once, in
+		place of a code, if the authenticator application is lost. Keep them
+		somewhere safe, they are only shown once. They are only valid for this
+		wallet, and not for the wallets isolated from it.
+
+		The secret is stored in the wallet file, encrypted with the wallet
+		passphrase.
+	`)
+
+	enableTwoFactorExample = cli.Examples(`
+		# Enable the two-factor authentication
+		vegawallet 2fa enable --wallet WALLET
+	`)
+)
+
+type EnableTwoFactorHandler func(*wallet.EnableTwoFactorRequest) (*wallet.EnableTwoFactorResponse, error)
+
+func NewCmdEnableTwoFactor(w io.Writer, rf *RootFlags) *cobra.Command {
+	h := func(req *wallet.EnableTwoFactorRequest) (*wallet.EnableTwoFactorResponse, error) {
+		s, err := wallets.InitialiseStore(rf.Home)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't initialise wallets store: %w", err)
+		}
+
+		return wallet.EnableTwoFactor(s, req)
+	}
+
+	return BuildCmdEnableTwoFactor(w, h, rf)
+}
+
+func BuildCmdEnableTwoFactor(w io.Writer, handler EnableTwoFactorHandler, rf *RootFlags) *cobra.Command {
+	f := &EnableTwoFactorFlags{}
+
+	cmd := &cobra.Command{
+		Use:     "enable",
+		Short:   "Enable the two-factor authentication",
+		Long:    enableTwoFactorLong,
+		Example: enableTwoFactorExample,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			req, err := f.Validate()
+			if err != nil {
+				return err
+			}
+
+			resp, err := handler(req)
+			if err != nil {
+				return err
+			}
+
+			switch rf.Output {
+			case flags.InteractiveOutput:
+				PrintEnableTwoFactorResponse(w, resp)
+			case flags.JSONOutput:
+				return printer.FprintJSON(w, resp)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&f.Wallet,
+		"wallet", "w",
+		"",
+		"Name of the wallet to use",
+	)
+	cmd.Flags().StringVarP(&f.PassphraseFile,
+		"passphrase-file", "p",
+		"",
+		"Path to the file containing the wallet's passphrase",
+	)
+	cmd.Flags().StringVar(&f.PassphraseFD,
+		"passphrase-fd",
+		"",
+		"File descriptor to read the wallet's passphrase from",
+	)
+
+	autoCompleteWallet(cmd, rf.Home)
+
+	return cmd
+}
+
+type EnableTwoFactorFlags struct {
+	Wallet         string
+	PassphraseFile string
+	PassphraseFD   string
+}
+
+func (f *EnableTwoFactorFlags) Validate() (*wallet.EnableTwoFactorRequest, error) {
+	req := &wallet.EnableTwoFactorRequest{}
+
+	if len(f.Wallet) == 0 {
+		return nil, flags.FlagMustBeSpecifiedError("wallet")
+	}
+	req.Wallet = f.Wallet
+
+	passphrase, err := flags.GetPassphrase(flags.PassphraseSource{
+		Wallet: f.Wallet,
+		File:   f.PassphraseFile,
+		FD:     f.PassphraseFD,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req.Passphrase = passphrase
+
+	return req, nil
+}
+
+func PrintEnableTwoFactorResponse(w io.Writer, resp *wallet.EnableTwoFactorResponse) {
+	p := printer.NewInteractivePrinter(w)
+
+	p.CheckMark().Text("Two-factor authentication enabled on wallet ").Bold(resp.Wallet).NextSection()
+	p.Text("Secret:").NextLine().WarningText(resp.Secret).NextLine()
+	p.Text("Provisioning URI:").NextLine().WarningText(resp.ProvisioningURI).NextSection()
+	p.Text("Recovery codes:").NextLine()
+	for _, code := range resp.RecoveryCodes {
+		p.WarningText(code).NextLine()
+	}
+	p.NextLine()
+	p.RedArrow().DangerText("Important").NextLine()
+	p.Text("Register the secret in your authenticator application, and write down the recovery codes.").NextLine()
+	p.DangerText("They won't be displayed ever again.").NextLine()
+}
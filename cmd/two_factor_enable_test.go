@@ -0,0 +1,60 @@
+package cmd_test
+
+import (
+	"testing"
+
+	vgrand "code.vegaprotocol.io/shared/libs/rand"
+	"code.vegaprotocol.io/vegawallet/cmd"
+	"code.vegaprotocol.io/vegawallet/cmd/flags"
+	"code.vegaprotocol.io/vegawallet/wallet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnableTwoFactorFlags(t *testing.T) {
+	t.Run("Valid flags succeeds", testEnableTwoFactorFlagsValidFlagsSucceeds)
+	t.Run("Missing wallet fails", testEnableTwoFactorFlagsMissingWalletFails)
+}
+
+func testEnableTwoFactorFlagsValidFlagsSucceeds(t *testing.T) {
+	testDir := t.TempDir()
+
+	// given
+	passphrase, passphraseFilePath := NewPassphraseFile(t, testDir)
+	walletName := vgrand.RandomStr(10)
+
+	f := &cmd.EnableTwoFactorFlags{
+		Wallet:         walletName,
+		PassphraseFile: passphraseFilePath,
+	}
+
+	expectedReq := &wallet.EnableTwoFactorRequest{
+		Wallet:     walletName,
+		Passphrase: passphrase,
+	}
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, req)
+	assert.Equal(t, expectedReq, req)
+}
+
+func testEnableTwoFactorFlagsMissingWalletFails(t *testing.T) {
+	testDir := t.TempDir()
+
+	// given
+	_, passphraseFilePath := NewPassphraseFile(t, testDir)
+	f := &cmd.EnableTwoFactorFlags{
+		PassphraseFile: passphraseFilePath,
+	}
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	assert.ErrorIs(t, err, flags.FlagMustBeSpecifiedError("wallet"))
+	assert.Nil(t, req)
+}
@@ -18,6 +18,7 @@ func NewCmdTx(w io.Writer, rf *RootFlags) *cobra.Command {
 	}
 
 	cmd.AddCommand(NewCmdTxSend(w, rf))
+	cmd.AddCommand(NewCmdTxHistory(w, rf))
 	return cmd
 }
 
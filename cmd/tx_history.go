@@ -0,0 +1,408 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	vgfs "code.vegaprotocol.io/shared/libs/fs"
+	vglog "code.vegaprotocol.io/shared/libs/zap"
+	"code.vegaprotocol.io/shared/paths"
+	"code.vegaprotocol.io/vegawallet/cmd/cli"
+	"code.vegaprotocol.io/vegawallet/cmd/flags"
+	"code.vegaprotocol.io/vegawallet/cmd/printer"
+	wcommands "code.vegaprotocol.io/vegawallet/commands"
+	"code.vegaprotocol.io/vegawallet/service"
+	svcstore "code.vegaprotocol.io/vegawallet/service/store/v1"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+const (
+	JSONExport = "json"
+	CSVExport  = "csv"
+)
+
+var (
+	supportedExports = []interface{}{
+		JSONExport,
+		CSVExport,
+	}
+
+	ErrExportFileAlreadyExists = errors.New("the export file already exists, use --force to overwrite it")
+
+	txHistoryLong = cli.LongDesc(`
+		List the transactions signed, sent, rejected or failed by the service for
+		the specified network, from the oldest to the latest.
+
+		The history can be exported as JSON or CSV, to the standard output or to
+		a file.
+	`)
+
+	txHistoryExample = cli.Examples(`
+		# List the transaction history
+		vegawallet tx history --network NETWORK
+
+		# List the transactions of a wallet sent since a date
+		vegawallet tx history --network NETWORK --wallet WALLET --status sent --since 2022-06-01T00:00:00Z
+
+		# List the latest 10 transactions of a public key
+		vegawallet tx history --network NETWORK --pubkey PUBLIC_KEY --limit 10
+
+		# Export the transaction history to a CSV file
+		vegawallet tx history --network NETWORK --export csv --file PATH_TO_FILE
+	`)
+)
+
+type TxHistoryHandler func(*TxHistoryRequest) (*service.TransactionsResponse, error)
+
+func NewCmdTxHistory(w io.Writer, rf *RootFlags) *cobra.Command {
+	h := func(req *TxHistoryRequest) (*service.TransactionsResponse, error) {
+		log, err := BuildLogger(rf.Output, zap.WarnLevel.String())
+		if err != nil {
+			return nil, err
+		}
+		defer vglog.Sync(log)
+
+		historyStore, err := initialiseHistoryStore(log, rf.Home, req.Network)
+		if err != nil {
+			return nil, err
+		}
+
+		return service.ListTransactions(historyStore, req.Filter)
+	}
+
+	return BuildCmdTxHistory(w, h, rf)
+}
+
+func BuildCmdTxHistory(w io.Writer, handler TxHistoryHandler, rf *RootFlags) *cobra.Command {
+	f := &TxHistoryFlags{}
+
+	cmd := &cobra.Command{
+		Use:     "history",
+		Short:   "List the transaction history",
+		Long:    txHistoryLong,
+		Example: txHistoryExample,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			req, err := f.Validate()
+			if err != nil {
+				return err
+			}
+
+			resp, err := handler(req)
+			if err != nil {
+				return err
+			}
+
+			if len(req.Export) != 0 {
+				return ExportTxHistory(w, req, resp)
+			}
+
+			switch rf.Output {
+			case flags.InteractiveOutput:
+				PrintTxHistoryResponse(w, resp)
+			case flags.JSONOutput:
+				return printer.FprintJSON(w, resp)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&f.Network,
+		"network", "n",
+		"",
+		"Network the transactions have been sent to",
+	)
+	cmd.Flags().StringSliceVarP(&f.Wallets,
+		"wallet", "w",
+		[]string{},
+		"Only list the transactions of these wallets",
+	)
+	cmd.Flags().StringSliceVarP(&f.PubKeys,
+		"pubkey", "k",
+		[]string{},
+		"Only list the transactions of these public keys",
+	)
+	cmd.Flags().StringVar(&f.Command,
+		"command",
+		"",
+		"Only list the transactions holding this command, like \"orderSubmission\"",
+	)
+	cmd.Flags().StringVar(&f.TxID,
+		"tx-id",
+		"",
+		"Only list the transaction with this ID",
+	)
+	cmd.Flags().StringVar(&f.Status,
+		"status",
+		"",
+		fmt.Sprintf("Only list the transactions with this status: %v", service.TransactionStatuses),
+	)
+	cmd.Flags().StringVar(&f.Since,
+		"since",
+		"",
+		"Only list the transactions received since this RFC3339 time",
+	)
+	cmd.Flags().StringVar(&f.Until,
+		"until",
+		"",
+		"Only list the transactions received until this RFC3339 time",
+	)
+	cmd.Flags().IntVar(&f.Limit,
+		"limit",
+		0,
+		"Only list the latest transactions, up to this number",
+	)
+	cmd.Flags().StringVar(&f.Export,
+		"export",
+		"",
+		fmt.Sprintf("Export the transactions in one of these formats: %v", supportedExports),
+	)
+	cmd.Flags().StringVar(&f.FilePath,
+		"file",
+		"",
+		"Path to the file the transactions are exported to",
+	)
+	cmd.Flags().BoolVarP(&f.Force,
+		"force", "f",
+		false,
+		"Overwrite the export file if it already exists",
+	)
+
+	autoCompleteNetwork(cmd, rf.Home)
+
+	return cmd
+}
+
+type TxHistoryFlags struct {
+	Network  string
+	Wallets  []string
+	PubKeys  []string
+	Command  string
+	TxID     string
+	Status   string
+	Since    string
+	Until    string
+	Limit    int
+	Export   string
+	FilePath string
+	Force    bool
+}
+
+func (f *TxHistoryFlags) Validate() (*TxHistoryRequest, error) {
+	req := &TxHistoryRequest{
+		Filter: service.TransactionFilter{
+			Wallets: f.Wallets,
+			PubKeys: f.PubKeys,
+			TxID:    f.TxID,
+		},
+	}
+
+	if len(f.Network) == 0 {
+		return nil, flags.FlagMustBeSpecifiedError("network")
+	}
+	req.Network = f.Network
+
+	if len(f.Command) != 0 {
+		if !wcommands.IsCommandName(f.Command) {
+			supported := make([]interface{}, len(wcommands.CommandNames))
+			for i, name := range wcommands.CommandNames {
+				supported[i] = name
+			}
+			return nil, flags.UnsupportedFlagValueError("command", f.Command, supported)
+		}
+		req.Filter.Command = f.Command
+	}
+
+	if len(f.Status) != 0 {
+		if !service.IsTransactionStatus(f.Status) {
+			supported := make([]interface{}, 0, len(service.TransactionStatuses))
+			for _, s := range service.TransactionStatuses {
+				supported = append(supported, s)
+			}
+			return nil, flags.UnsupportedFlagValueError("status", f.Status, supported)
+		}
+		req.Filter.Status = service.TransactionStatus(f.Status)
+	}
+
+	if len(f.Since) != 0 {
+		since, err := time.Parse(time.RFC3339, f.Since)
+		if err != nil {
+			return nil, flags.InvalidFlagFormatError("since")
+		}
+		req.Filter.Since = &since
+	}
+
+	if len(f.Until) != 0 {
+		until, err := time.Parse(time.RFC3339, f.Until)
+		if err != nil {
+			return nil, flags.InvalidFlagFormatError("until")
+		}
+		req.Filter.Until = &until
+	}
+
+	if f.Limit < 0 {
+		return nil, flags.InvalidFlagFormatError("limit")
+	}
+	req.Filter.Limit = f.Limit
+
+	if len(f.Export) != 0 {
+		if f.Export != JSONExport && f.Export != CSVExport {
+			return nil, flags.UnsupportedFlagValueError("export", f.Export, supportedExports)
+		}
+		req.Export = f.Export
+	}
+
+	if len(f.FilePath) != 0 {
+		if len(f.Export) == 0 {
+			return nil, flags.OneOfParentsFlagMustBeSpecifiedError("file", "export")
+		}
+		req.FilePath = f.FilePath
+		req.Force = f.Force
+	}
+
+	return req, nil
+}
+
+type TxHistoryRequest struct {
+	Network string
+	Filter  service.TransactionFilter
+	// Export is the format of the export, if the transactions are exported.
+	Export string
+	// FilePath is the file the export is written to. If not set, the export
+	// is written to the standard output.
+	FilePath string
+	Force    bool
+}
+
+// ExportTxHistory writes the transactions in the requested format, to the
+// export file if set.
+func ExportTxHistory(w io.Writer, req *TxHistoryRequest, resp *service.TransactionsResponse) error {
+	buf := &bytes.Buffer{}
+
+	switch req.Export {
+	case JSONExport:
+		content, err := json.MarshalIndent(resp.Transactions, "", "  ")
+		if err != nil {
+			return fmt.Errorf("couldn't marshal the transactions: %w", err)
+		}
+		buf.Write(content)
+		buf.WriteString("\n")
+	case CSVExport:
+		if err := writeTxHistoryCSV(buf, resp.Transactions); err != nil {
+			return err
+		}
+	}
+
+	if len(req.FilePath) == 0 {
+		_, err := w.Write(buf.Bytes())
+		return err
+	}
+
+	if !req.Force {
+		exists, err := vgfs.FileExists(req.FilePath)
+		if err != nil {
+			return fmt.Errorf("couldn't verify the export file existence: %w", err)
+		}
+		if exists {
+			return ErrExportFileAlreadyExists
+		}
+	}
+
+	if err := vgfs.WriteFile(req.FilePath, buf.Bytes()); err != nil {
+		return fmt.Errorf("couldn't write the export file: %w", err)
+	}
+
+	return nil
+}
+
+func writeTxHistoryCSV(w io.Writer, txs []service.TransactionRecord) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{"txId", "wallet", "pubKey", "command", "status", "signed", "txHash", "receivedAt", "sentAt", "error"}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("couldn't write the CSV header: %w", err)
+	}
+
+	for _, tx := range txs {
+		sentAt := ""
+		if tx.SentAt != nil {
+			sentAt = tx.SentAt.Format(time.RFC3339Nano)
+		}
+		record := []string{
+			tx.TxID,
+			tx.Wallet,
+			tx.PubKey,
+			tx.Command,
+			string(tx.Status),
+			strconv.FormatBool(tx.Signed),
+			tx.TxHash,
+			tx.ReceivedAt.Format(time.RFC3339Nano),
+			sentAt,
+			tx.Error,
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("couldn't write the transaction %s: %w", tx.TxID, err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func PrintTxHistoryResponse(w io.Writer, resp *service.TransactionsResponse) {
+	p := printer.NewInteractivePrinter(w)
+
+	if len(resp.Transactions) == 0 {
+		p.InfoText("No transaction").NextLine()
+		return
+	}
+
+	for i, tx := range resp.Transactions {
+		if i != 0 {
+			p.NextLine()
+		}
+		p.Text("Transaction ID: ").WarningText(tx.TxID).NextLine()
+		p.Text("Status:         ")
+		switch tx.Status {
+		case service.TransactionStatusSent:
+			p.SuccessText(string(tx.Status)).NextLine()
+		default:
+			p.DangerText(string(tx.Status)).NextLine()
+		}
+		p.Text("Wallet:         ").WarningText(tx.Wallet).NextLine()
+		p.Text("Public key:     ").WarningText(tx.PubKey).NextLine()
+		p.Text("Command:        ").WarningText(tx.Command).NextLine()
+		p.Text("Received at:    ").WarningText(tx.ReceivedAt.Format(time.RFC3339)).NextLine()
+		if tx.SentAt != nil {
+			p.Text("Sent at:        ").WarningText(tx.SentAt.Format(time.RFC3339)).NextLine()
+		}
+		if len(tx.TxHash) != 0 {
+			p.Text("Hash:           ").WarningText(tx.TxHash).NextLine()
+		}
+		if len(tx.Error) != 0 {
+			p.Text("Error:          ").DangerText(tx.Error).NextLine()
+		}
+	}
+}
+
+func initialiseHistoryStore(log *zap.Logger, home, networkName string) (*svcstore.HistoryStore, error) {
+	vegaPaths := paths.New(home)
+
+	if err := verifyNetworkExists(vegaPaths, networkName); err != nil {
+		return nil, err
+	}
+
+	historyStore, err := svcstore.InitialiseHistoryStore(log, vegaPaths, networkName)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't initialise transaction history store: %w", err)
+	}
+
+	return historyStore, nil
+}
@@ -0,0 +1,164 @@
+package cmd_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	vgrand "code.vegaprotocol.io/shared/libs/rand"
+	"code.vegaprotocol.io/vegawallet/cmd"
+	"code.vegaprotocol.io/vegawallet/cmd/flags"
+	"code.vegaprotocol.io/vegawallet/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxHistoryFlags(t *testing.T) {
+	t.Run("Valid flags succeeds", testTxHistoryFlagsValidFlagsSucceeds)
+	t.Run("Missing network fails", testTxHistoryFlagsMissingNetworkFails)
+	t.Run("Unsupported status fails", testTxHistoryFlagsUnsupportedStatusFails)
+	t.Run("Invalid time fails", testTxHistoryFlagsInvalidTimeFails)
+	t.Run("Unsupported export fails", testTxHistoryFlagsUnsupportedExportFails)
+	t.Run("File without export fails", testTxHistoryFlagsFileWithoutExportFails)
+}
+
+func testTxHistoryFlagsValidFlagsSucceeds(t *testing.T) {
+	// given
+	network := vgrand.RandomStr(10)
+	walletName := vgrand.RandomStr(10)
+	filePath := vgrand.RandomStr(10)
+
+	f := &cmd.TxHistoryFlags{
+		Network:  network,
+		Wallets:  []string{walletName},
+		Command:  "orderSubmission",
+		Status:   "sent",
+		Since:    "2022-06-01T00:00:00Z",
+		Limit:    10,
+		Export:   "csv",
+		FilePath: filePath,
+	}
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	require.NoError(t, err)
+	since := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, &cmd.TxHistoryRequest{
+		Network: network,
+		Filter: service.TransactionFilter{
+			Wallets: []string{walletName},
+			Command: "orderSubmission",
+			Status:  service.TransactionStatusSent,
+			Since:   &since,
+			Limit:   10,
+		},
+		Export:   cmd.CSVExport,
+		FilePath: filePath,
+	}, req)
+}
+
+func testTxHistoryFlagsMissingNetworkFails(t *testing.T) {
+	// given
+	f := &cmd.TxHistoryFlags{}
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	assert.ErrorIs(t, err, flags.FlagMustBeSpecifiedError("network"))
+	assert.Nil(t, req)
+}
+
+func testTxHistoryFlagsUnsupportedStatusFails(t *testing.T) {
+	// given
+	f := &cmd.TxHistoryFlags{
+		Network: vgrand.RandomStr(10),
+		Status:  "pending",
+	}
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	assert.Error(t, err)
+	assert.Nil(t, req)
+}
+
+func testTxHistoryFlagsInvalidTimeFails(t *testing.T) {
+	// given
+	f := &cmd.TxHistoryFlags{
+		Network: vgrand.RandomStr(10),
+		Until:   "yesterday",
+	}
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	assert.ErrorIs(t, err, flags.InvalidFlagFormatError("until"))
+	assert.Nil(t, req)
+}
+
+func testTxHistoryFlagsUnsupportedExportFails(t *testing.T) {
+	// given
+	f := &cmd.TxHistoryFlags{
+		Network: vgrand.RandomStr(10),
+		Export:  "xml",
+	}
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	assert.Error(t, err)
+	assert.Nil(t, req)
+}
+
+func testTxHistoryFlagsFileWithoutExportFails(t *testing.T) {
+	// given
+	f := &cmd.TxHistoryFlags{
+		Network:  vgrand.RandomStr(10),
+		FilePath: vgrand.RandomStr(10),
+	}
+
+	// when
+	req, err := f.Validate()
+
+	// then
+	assert.ErrorIs(t, err, flags.OneOfParentsFlagMustBeSpecifiedError("file", "export"))
+	assert.Nil(t, req)
+}
+
+func TestExportTxHistory(t *testing.T) {
+	t.Run("Exporting as CSV succeeds", testExportTxHistoryAsCSVSucceeds)
+}
+
+func testExportTxHistoryAsCSVSucceeds(t *testing.T) {
+	// given
+	w := &bytes.Buffer{}
+	receivedAt := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	resp := &service.TransactionsResponse{
+		Transactions: []service.TransactionRecord{
+			{
+				TxID:       "txID",
+				Wallet:     "wallet",
+				PubKey:     "pubKey",
+				Command:    "orderSubmission",
+				Status:     service.TransactionStatusFailed,
+				Signed:     true,
+				ReceivedAt: receivedAt,
+				Error:      "node is unavailable, try later",
+			},
+		},
+	}
+
+	// when
+	err := cmd.ExportTxHistory(w, &cmd.TxHistoryRequest{Export: cmd.CSVExport}, resp)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, "txId,wallet,pubKey,command,status,signed,txHash,receivedAt,sentAt,error\n"+
+		"txID,wallet,pubKey,orderSubmission,failed,true,,2022-06-01T00:00:00Z,,\"node is unavailable, try later\"\n", w.String())
+}
@@ -83,6 +83,11 @@ func BuildCmdCreateWallet(w io.Writer, handler CreateWalletHandler, rf *RootFlag
 		"",
 		"Path to the file containing the wallet's passphrase",
 	)
+	cmd.Flags().StringVar(&f.PassphraseFD,
+		"passphrase-fd",
+		"",
+		"File descriptor to read the wallet's passphrase from",
+	)
 
 	return cmd
 }
@@ -90,6 +95,7 @@ func BuildCmdCreateWallet(w io.Writer, handler CreateWalletHandler, rf *RootFlag
 type CreateWalletFlags struct {
 	Wallet         string
 	PassphraseFile string
+	PassphraseFD   string
 }
 
 func (f *CreateWalletFlags) Validate() (*wallet.CreateWalletRequest, error) {
@@ -98,9 +104,16 @@ func (f *CreateWalletFlags) Validate() (*wallet.CreateWalletRequest, error) {
 	if len(f.Wallet) == 0 {
 		return nil, flags.FlagMustBeSpecifiedError("wallet")
 	}
+	if err := wallet.ValidateName(f.Wallet); err != nil {
+		return nil, err
+	}
 	req.Wallet = f.Wallet
 
-	passphrase, err := flags.GetConfirmedPassphrase(f.PassphraseFile)
+	passphrase, err := flags.GetConfirmedPassphrase(flags.PassphraseSource{
+		Wallet: f.Wallet,
+		File:   f.PassphraseFile,
+		FD:     f.PassphraseFD,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -90,6 +90,11 @@ func BuildCmdImportWallet(w io.Writer, handler ImportWalletHandler, rf *RootFlag
 		"",
 		"Path to the file containing the passphrase to access the wallet",
 	)
+	cmd.Flags().StringVar(&f.PassphraseFD,
+		"passphrase-fd",
+		"",
+		"File descriptor to read the wallet's passphrase from",
+	)
 	cmd.Flags().StringVar(&f.RecoveryPhraseFile,
 		"recovery-phrase-file",
 		"",
@@ -115,6 +120,7 @@ func BuildCmdImportWallet(w io.Writer, handler ImportWalletHandler, rf *RootFlag
 type ImportWalletFlags struct {
 	Wallet             string
 	PassphraseFile     string
+	PassphraseFD       string
 	RecoveryPhraseFile string
 	Version            uint32
 }
@@ -127,6 +133,9 @@ func (f *ImportWalletFlags) Validate() (*wallet.ImportWalletRequest, error) {
 	if len(f.Wallet) == 0 {
 		return nil, flags.FlagMustBeSpecifiedError("wallet")
 	}
+	if err := wallet.ValidateName(f.Wallet); err != nil {
+		return nil, err
+	}
 	req.Wallet = f.Wallet
 
 	if len(f.RecoveryPhraseFile) == 0 {
@@ -138,7 +147,11 @@ func (f *ImportWalletFlags) Validate() (*wallet.ImportWalletRequest, error) {
 	}
 	req.RecoveryPhrase = strings.Trim(string(recoveryPhrase), "\n")
 
-	passphrase, err := flags.GetConfirmedPassphrase(f.PassphraseFile)
+	passphrase, err := flags.GetConfirmedPassphrase(flags.PassphraseSource{
+		Wallet: f.Wallet,
+		File:   f.PassphraseFile,
+		FD:     f.PassphraseFD,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -78,6 +78,11 @@ func BuildCmdGetInfoWallet(w io.Writer, handler GetInfoWalletHandler, rf *RootFl
 		"",
 		"Path to the file containing the wallet's passphrase",
 	)
+	cmd.Flags().StringVar(&f.PassphraseFD,
+		"passphrase-fd",
+		"",
+		"File descriptor to read the wallet's passphrase from",
+	)
 
 	autoCompleteWallet(cmd, rf.Home)
 
@@ -87,6 +92,7 @@ func BuildCmdGetInfoWallet(w io.Writer, handler GetInfoWalletHandler, rf *RootFl
 type GetWalletInfoFlags struct {
 	Wallet         string
 	PassphraseFile string
+	PassphraseFD   string
 }
 
 func (f *GetWalletInfoFlags) Validate() (*wallet.GetWalletInfoRequest, error) {
@@ -97,7 +103,11 @@ func (f *GetWalletInfoFlags) Validate() (*wallet.GetWalletInfoRequest, error) {
 	}
 	req.Wallet = f.Wallet
 
-	passphrase, err := flags.GetPassphrase(f.PassphraseFile)
+	passphrase, err := flags.GetPassphrase(flags.PassphraseSource{
+		Wallet: f.Wallet,
+		File:   f.PassphraseFile,
+		FD:     f.PassphraseFD,
+	})
 	if err != nil {
 		return nil, err
 	}
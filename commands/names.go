@@ -0,0 +1,89 @@
+package commands
+
+import (
+	walletpb "code.vegaprotocol.io/protos/vega/wallet/v1"
+)
+
+// CommandNames lists the names of the commands supported in a
+// SubmitTransactionRequest, as they appear in its JSON representation.
+var CommandNames = []string{
+	"orderSubmission",
+	"orderCancellation",
+	"orderAmendment",
+	"voteSubmission",
+	"withdrawSubmission",
+	"liquidityProvisionSubmission",
+	"proposalSubmission",
+	"announceNode",
+	"nodeVote",
+	"nodeSignature",
+	"chainEvent",
+	"oracleDataSubmission",
+	"undelegateSubmission",
+	"delegateSubmission",
+	"liquidityProvisionCancellation",
+	"liquidityProvisionAmendment",
+	"transfer",
+	"cancelTransfer",
+	"keyRotateSubmission",
+	"ethereumKeyRotateSubmission",
+}
+
+// IsCommandName verifies the specified name is one of the supported commands.
+func IsCommandName(name string) bool {
+	for _, n := range CommandNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// CommandName returns the name of the command held by the request. It returns
+// an empty string if the command is not supported.
+func CommandName(req *walletpb.SubmitTransactionRequest) string {
+	switch req.Command.(type) {
+	case *walletpb.SubmitTransactionRequest_OrderSubmission:
+		return "orderSubmission"
+	case *walletpb.SubmitTransactionRequest_OrderCancellation:
+		return "orderCancellation"
+	case *walletpb.SubmitTransactionRequest_OrderAmendment:
+		return "orderAmendment"
+	case *walletpb.SubmitTransactionRequest_VoteSubmission:
+		return "voteSubmission"
+	case *walletpb.SubmitTransactionRequest_WithdrawSubmission:
+		return "withdrawSubmission"
+	case *walletpb.SubmitTransactionRequest_LiquidityProvisionSubmission:
+		return "liquidityProvisionSubmission"
+	case *walletpb.SubmitTransactionRequest_ProposalSubmission:
+		return "proposalSubmission"
+	case *walletpb.SubmitTransactionRequest_AnnounceNode:
+		return "announceNode"
+	case *walletpb.SubmitTransactionRequest_NodeVote:
+		return "nodeVote"
+	case *walletpb.SubmitTransactionRequest_NodeSignature:
+		return "nodeSignature"
+	case *walletpb.SubmitTransactionRequest_ChainEvent:
+		return "chainEvent"
+	case *walletpb.SubmitTransactionRequest_OracleDataSubmission:
+		return "oracleDataSubmission"
+	case *walletpb.SubmitTransactionRequest_UndelegateSubmission:
+		return "undelegateSubmission"
+	case *walletpb.SubmitTransactionRequest_DelegateSubmission:
+		return "delegateSubmission"
+	case *walletpb.SubmitTransactionRequest_LiquidityProvisionCancellation:
+		return "liquidityProvisionCancellation"
+	case *walletpb.SubmitTransactionRequest_LiquidityProvisionAmendment:
+		return "liquidityProvisionAmendment"
+	case *walletpb.SubmitTransactionRequest_Transfer:
+		return "transfer"
+	case *walletpb.SubmitTransactionRequest_CancelTransfer:
+		return "cancelTransfer"
+	case *walletpb.SubmitTransactionRequest_KeyRotateSubmission:
+		return "keyRotateSubmission"
+	case *walletpb.SubmitTransactionRequest_EthereumKeyRotateSubmission:
+		return "ethereumKeyRotateSubmission"
+	default:
+		return ""
+	}
+}
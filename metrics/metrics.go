@@ -0,0 +1,173 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Outcomes of the consent requests.
+const (
+	ConsentApproved = "approved"
+	ConsentRejected = "rejected"
+	ConsentFailed   = "failed"
+)
+
+var (
+	// durationBuckets suit the requests to the service and to the nodes, from
+	// a few milliseconds to a few seconds.
+	durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+	// consentBuckets suit the time a user takes to review a request, up to a
+	// few minutes.
+	consentBuckets = []float64{0.1, 0.5, 1, 5, 10, 30, 60, 120, 300}
+)
+
+// Metrics holds the metrics of the wallet service and of the node forwarder.
+// All its methods can be called on a nil instance, so the metrics can be
+// disabled without checking it at every call site.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests        *prometheus.CounterVec
+	httpRequestDuration *prometheus.HistogramVec
+	consentWait         *prometheus.HistogramVec
+	powDuration         prometheus.Histogram
+	powDifficulty       prometheus.Gauge
+	nodeRequestDuration *prometheus.HistogramVec
+	nodeRequestErrors   *prometheus.CounterVec
+	nodeRequestRetries  *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "vegawallet_http_requests_total",
+			Help: "Number of HTTP requests served, per route and status code.",
+		}, []string{"method", "route", "status"}),
+		httpRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "vegawallet_http_request_duration_seconds",
+			Help:    "Time taken to serve the HTTP requests, per route.",
+			Buckets: durationBuckets,
+		}, []string{"method", "route"}),
+		consentWait: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "vegawallet_consent_wait_seconds",
+			Help:    "Time waited for the consent of the user, per kind of request and outcome.",
+			Buckets: consentBuckets,
+		}, []string{"request", "outcome"}),
+		powDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "vegawallet_pow_duration_seconds",
+			Help:    "Time taken to compute the proof of work of the transactions.",
+			Buckets: durationBuckets,
+		}),
+		powDifficulty: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "vegawallet_pow_difficulty",
+			Help: "Difficulty of the last proof of work computed.",
+		}),
+		nodeRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "vegawallet_node_request_duration_seconds",
+			Help:    "Time taken by the calls to the nodes, per host and method.",
+			Buckets: durationBuckets,
+		}, []string{"host", "method"}),
+		nodeRequestErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "vegawallet_node_request_errors_total",
+			Help: "Number of failed calls to the nodes, per host and method.",
+		}, []string{"host", "method"}),
+		nodeRequestRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "vegawallet_node_request_retries_total",
+			Help: "Number of calls to the nodes retried after a failure, per host and method.",
+		}, []string{"host", "method"}),
+	}
+
+	m.registry.MustRegister(
+		m.httpRequests,
+		m.httpRequestDuration,
+		m.consentWait,
+		m.powDuration,
+		m.powDifficulty,
+		m.nodeRequestDuration,
+		m.nodeRequestErrors,
+		m.nodeRequestRetries,
+	)
+
+	return m
+}
+
+// ObserveHTTPRequest records a request served on the route, identified by its
+// pattern, like `/api/v1/keys/:keyid`, so the path parameters don't create a
+// series per value.
+func (m *Metrics) ObserveHTTPRequest(method, route string, status int, d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.httpRequestDuration.WithLabelValues(method, route).Observe(d.Seconds())
+}
+
+// ObserveConsent records the time the user took to answer a consent request,
+// like "transaction", "batch" or "connection".
+func (m *Metrics) ObserveConsent(request, outcome string, d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.consentWait.WithLabelValues(request, outcome).Observe(d.Seconds())
+}
+
+func (m *Metrics) ObserveProofOfWork(difficulty uint, d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.powDuration.Observe(d.Seconds())
+	m.powDifficulty.Set(float64(difficulty))
+}
+
+// ObserveNodeRequest records a call to a node. The attempt starts at 1, so
+// the following ones are counted as retries.
+func (m *Metrics) ObserveNodeRequest(host, method string, attempt int, d time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.nodeRequestDuration.WithLabelValues(host, method).Observe(d.Seconds())
+	if err != nil {
+		m.nodeRequestErrors.WithLabelValues(host, method).Inc()
+	}
+	if attempt > 1 {
+		m.nodeRequestRetries.WithLabelValues(host, method).Inc()
+	}
+}
+
+// TrackActiveSessions exposes the number of active sessions, read when the
+// metrics are collected.
+func (m *Metrics) TrackActiveSessions(count func() int) {
+	if m == nil {
+		return
+	}
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "vegawallet_active_sessions",
+		Help: "Number of active sessions.",
+	}, func() float64 { return float64(count()) }))
+}
+
+// TrackUnlockedWallets exposes the number of unlocked wallets, read when the
+// metrics are collected.
+func (m *Metrics) TrackUnlockedWallets(count func() int) {
+	if m == nil {
+		return
+	}
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "vegawallet_unlocked_wallets",
+		Help: "Number of wallets whose key material is in memory.",
+	}, func() float64 { return float64(count()) }))
+}
+
+// ServeHTTP serves the metrics in the Prometheus exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if m == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+}
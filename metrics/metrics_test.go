@@ -0,0 +1,55 @@
+package metrics_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"code.vegaprotocol.io/vegawallet/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Run("Serving the metrics succeeds", testServingMetricsSucceeds)
+	t.Run("Disabled metrics are ignored", testDisabledMetricsAreIgnored)
+}
+
+func testServingMetricsSucceeds(t *testing.T) {
+	// given
+	m := metrics.New()
+	m.TrackActiveSessions(func() int { return 2 })
+
+	// when
+	m.ObserveNodeRequest("localhost:3002", "SubmitTransaction", 1, time.Second, nil)
+	m.ObserveNodeRequest("localhost:3002", "SubmitTransaction", 2, time.Second, errors.New("unavailable"))
+	m.ObserveProofOfWork(5, time.Millisecond)
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	// then
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/plain; version=0.0.4")
+	body := w.Body.String()
+	assert.Contains(t, body, `vegawallet_node_request_duration_seconds_count{host="localhost:3002",method="SubmitTransaction"} 2`)
+	assert.Contains(t, body, `vegawallet_node_request_errors_total{host="localhost:3002",method="SubmitTransaction"} 1`)
+	assert.Contains(t, body, `vegawallet_node_request_retries_total{host="localhost:3002",method="SubmitTransaction"} 1`)
+	assert.Contains(t, body, "vegawallet_pow_difficulty 5\n")
+	assert.Contains(t, body, "vegawallet_active_sessions 2\n")
+}
+
+func testDisabledMetricsAreIgnored(t *testing.T) {
+	// given
+	var m *metrics.Metrics
+
+	// when
+	m.ObserveHTTPRequest(http.MethodGet, "/api/v1/status", http.StatusOK, time.Second)
+	m.ObserveConsent("transaction", metrics.ConsentApproved, time.Second)
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	// then
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"code.vegaprotocol.io/vegawallet/network"
+)
+
+// Server serves the metrics at `/metrics`, on a listener of its own, so they
+// aren't exposed alongside the wallet API.
+type Server struct {
+	server *http.Server
+}
+
+func NewServer(cfg network.MetricsConfig, m *Metrics) *Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m)
+
+	return &Server{
+		server: &http.Server{
+			Addr:    fmt.Sprintf("%s:%v", cfg.Host, cfg.Port),
+			Handler: mux,
+		},
+	}
+}
+
+func (s *Server) Start() error {
+	return s.server.ListenAndServe()
+}
+
+func (s *Server) Stop() error {
+	return s.server.Shutdown(context.Background())
+}
@@ -126,9 +126,16 @@ func DescribeNetwork(store Store, req *DescribeNetworkRequest) (*DescribeNetwork
 
 	resp.Name = net.Name
 	resp.TokenExpiry = net.TokenExpiry.String()
+	if net.WalletIdleTimeout.Get() > 0 {
+		resp.WalletIdleTimeout = net.WalletIdleTimeout.String()
+	}
+	resp.IdempotencyWindow = net.GetIdempotencyWindow().String()
 	resp.Level = net.Level.String()
 	resp.Host = net.Host
 	resp.Port = net.Port
+	resp.TLS = net.TLS
+	resp.Metrics = net.Metrics
+	resp.Admin = net.Admin
 	resp.API.GRPCConfig.Hosts = net.API.GRPC.Hosts
 	resp.API.GRPCConfig.Retries = net.API.GRPC.Retries
 	resp.API.RESTConfig.Hosts = net.API.REST.Hosts
@@ -147,9 +154,16 @@ type DescribeNetworkResponse struct {
 	Name        string `json:"name"`
 	Level       string `json:"logLevel"`
 	TokenExpiry string `json:"tokenExpiry"`
-	Port        int    `json:"port"`
-	Host        string `json:"host"`
-	API         struct {
+	// WalletIdleTimeout is empty when the wallets are never locked for
+	// inactivity.
+	WalletIdleTimeout string        `json:"walletIdleTimeout,omitempty"`
+	IdempotencyWindow string        `json:"idempotencyWindow"`
+	Port              int           `json:"port"`
+	Host              string        `json:"host"`
+	TLS               TLSConfig     `json:"tls"`
+	Metrics           MetricsConfig `json:"metrics"`
+	Admin             AdminConfig   `json:"admin"`
+	API               struct {
 		GRPCConfig struct {
 			Hosts   []string `json:"hosts"`
 			Retries uint64   `json:"retries"`
@@ -185,3 +199,42 @@ func DeleteNetwork(store Store, req *DeleteNetworkRequest) error {
 
 	return nil
 }
+
+type DiagnoseNetworkResponse struct {
+	Name    string   `json:"name"`
+	Healthy bool     `json:"healthy"`
+	Issues  []string `json:"issues"`
+}
+
+// DiagnoseNetworks verifies every network configuration can be loaded and
+// holds what is required to start the service.
+func DiagnoseNetworks(store Store) ([]DiagnoseNetworkResponse, error) {
+	nets, err := store.ListNetworks()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list networks: %w", err)
+	}
+
+	resps := make([]DiagnoseNetworkResponse, 0, len(nets))
+	for _, name := range nets {
+		resp := DiagnoseNetworkResponse{
+			Name:   name,
+			Issues: []string{},
+		}
+
+		net, err := store.GetNetwork(name)
+		if err != nil {
+			resp.Issues = append(resp.Issues, err.Error())
+			resps = append(resps, resp)
+			continue
+		}
+
+		for _, issue := range net.Diagnose() {
+			resp.Issues = append(resp.Issues, issue.Error())
+		}
+
+		resp.Healthy = len(resp.Issues) == 0
+		resps = append(resps, resp)
+	}
+
+	return resps, nil
+}
@@ -0,0 +1,42 @@
+package network
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MaxNameLength is the maximum number of characters a network name can have.
+const MaxNameLength = 128
+
+// forbiddenNameCharacters are the characters that can't be used in a network
+// name, because they are path separators, or they are not supported by the
+// file systems of some platforms.
+const forbiddenNameCharacters = `/\<>:"|?*`
+
+// ValidateName verifies the network name can be safely used as a file name,
+// as the network configuration is stored in a file named after it.
+func ValidateName(name string) error {
+	if len(name) == 0 {
+		return ErrNetworkNameIsEmpty
+	}
+
+	if len([]rune(name)) > MaxNameLength {
+		return ErrNetworkNameIsTooLong
+	}
+
+	if strings.TrimSpace(name) != name {
+		return ErrNetworkNameHasSurroundingSpaces
+	}
+
+	if name == "." || name == ".." {
+		return ErrNetworkNameIsReserved
+	}
+
+	for _, c := range name {
+		if unicode.IsControl(c) || strings.ContainsRune(forbiddenNameCharacters, c) {
+			return ErrNetworkNameHasForbiddenCharacters
+		}
+	}
+
+	return nil
+}
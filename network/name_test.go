@@ -0,0 +1,65 @@
+package network_test
+
+import (
+	"strings"
+	"testing"
+
+	"code.vegaprotocol.io/vegawallet/network"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateName(t *testing.T) {
+	tcs := []struct {
+		name        string
+		networkName string
+		err         error
+	}{
+		{
+			name:        "simple name",
+			networkName: "mainnet1",
+			err:         nil,
+		}, {
+			name:        "name with dots",
+			networkName: "fairground.restored",
+			err:         nil,
+		}, {
+			name:        "empty name",
+			networkName: "",
+			err:         network.ErrNetworkNameIsEmpty,
+		}, {
+			name:        "too long name",
+			networkName: strings.Repeat("a", network.MaxNameLength+1),
+			err:         network.ErrNetworkNameIsTooLong,
+		}, {
+			name:        "name with surrounding spaces",
+			networkName: " mainnet1 ",
+			err:         network.ErrNetworkNameHasSurroundingSpaces,
+		}, {
+			name:        "parent directory",
+			networkName: "..",
+			err:         network.ErrNetworkNameIsReserved,
+		}, {
+			name:        "relative path",
+			networkName: "../mainnet1",
+			err:         network.ErrNetworkNameHasForbiddenCharacters,
+		}, {
+			name:        "windows path",
+			networkName: `..\mainnet1`,
+			err:         network.ErrNetworkNameHasForbiddenCharacters,
+		}, {
+			name:        "name with control character",
+			networkName: "main\nnet",
+			err:         network.ErrNetworkNameHasForbiddenCharacters,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			// when
+			err := network.ValidateName(tc.networkName)
+
+			// then
+			assert.ErrorIs(tt, err, tc.err)
+		})
+	}
+}
@@ -2,6 +2,11 @@ package network
 
 import (
 	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
 
 	"code.vegaprotocol.io/vegawallet/service/encoding"
 )
@@ -12,17 +17,138 @@ var (
 	ErrNetworkDoesNotHaveLocalPortConfiguredForConsole   = errors.New("network configuration does not have any local port set for console")
 	ErrNetworkDoesNotHaveHostConfiguredForTokenDApp      = errors.New("network configuration does not have any host set for token dApp")
 	ErrNetworkDoesNotHaveLocalPortConfiguredForTokenDApp = errors.New("network configuration does not have any local port set for token dApp")
+	ErrNetworkDoesNotHaveHostConfigured                  = errors.New("network configuration does not have any host set for the service")
+	ErrNetworkDoesNotHavePortConfigured                  = errors.New("network configuration does not have any port set for the service")
+	ErrNetworkDoesNotHaveTokenExpiryConfigured           = errors.New("network configuration does not have any token expiry set")
+	ErrNetworkTLSRequiresCertificateAndKey               = errors.New("network configuration should set both the TLS certificate and key files")
+	ErrNetworkServesPlaintextOnNonLoopbackHost           = errors.New("network configuration serves plain HTTP on a host that isn't a loopback address")
+	ErrNetworkDisablesTCPWithoutSocket                   = errors.New("network configuration disables the TCP listener without setting a socket path")
+	ErrNetworkSocketModeIsInvalid                        = errors.New("network configuration does not have a valid octal socket mode")
+	ErrNetworkDoesNotHaveAdminConfigured                 = errors.New("network configuration does not have any host and port set for the admin API")
+	ErrNetworkServesAdminPlaintextOnNonLoopbackHost      = errors.New("network configuration serves the admin API in plain HTTP on a host that isn't a loopback address")
+	ErrNetworkNameHasForbiddenCharacters                 = errors.New("network name contains forbidden characters")
+	ErrNetworkNameHasSurroundingSpaces                   = errors.New("network name can't start or end with spaces")
+	ErrNetworkNameIsEmpty                                = errors.New("network name can't be empty")
+	ErrNetworkNameIsReserved                             = errors.New("network name can't be \".\" or \"..\"")
+	ErrNetworkNameIsTooLong                              = errors.New("network name is too long")
 )
 
 type Network struct {
 	Name        string            `json:"name"`
 	Level       encoding.LogLevel `json:"level"`
 	TokenExpiry encoding.Duration `json:"tokenExpiry"`
-	Port        int               `json:"port"`
-	Host        string            `json:"host"`
-	API         APIConfig         `json:"api"`
-	TokenDApp   TokenDAppConfig   `json:"tokenDApp"`
-	Console     ConsoleConfig     `json:"console"`
+	// WalletIdleTimeout is the time after which the wallets that haven't been
+	// used are locked by the service. Zero disables it.
+	WalletIdleTimeout encoding.Duration `json:"walletIdleTimeout"`
+	// IdempotencyWindow is the time the outcome of the command submissions
+	// holding an idempotency key is remembered. Zero uses the default window.
+	IdempotencyWindow encoding.Duration `json:"idempotencyWindow"`
+	Port              int               `json:"port"`
+	Host              string            `json:"host"`
+	TLS               TLSConfig         `json:"tls"`
+	Socket            SocketConfig      `json:"socket"`
+	Metrics           MetricsConfig     `json:"metrics"`
+	Admin             AdminConfig       `json:"admin"`
+	API               APIConfig         `json:"api"`
+	TokenDApp         TokenDAppConfig   `json:"tokenDApp"`
+	Console           ConsoleConfig     `json:"console"`
+}
+
+// DefaultIdempotencyWindow is used when the network configuration doesn't set
+// the idempotency window.
+const DefaultIdempotencyWindow = 24 * time.Hour
+
+// GetIdempotencyWindow returns the idempotency window of the network, or the
+// default one if not set.
+func (n *Network) GetIdempotencyWindow() time.Duration {
+	if window := n.IdempotencyWindow.Get(); window > 0 {
+		return window
+	}
+	return DefaultIdempotencyWindow
+}
+
+// TLSConfig secures the connections to the service. The certificate is either
+// read from the specified files, or issued for the host of the service by the
+// local certificate authority created at initialisation. Without TLS, the
+// service serves plain HTTP.
+type TLSConfig struct {
+	CertFile string `json:"certFile"`
+	KeyFile  string `json:"keyFile"`
+	// AutoGenerate issues the certificate with the local certificate
+	// authority. It's ignored if the certificate files are set.
+	AutoGenerate bool `json:"autoGenerate"`
+}
+
+func (c TLSConfig) IsEnabled() bool {
+	return c.AutoGenerate || c.UsesCertificateFiles()
+}
+
+func (c TLSConfig) UsesCertificateFiles() bool {
+	return len(c.CertFile) != 0 || len(c.KeyFile) != 0
+}
+
+// DefaultSocketMode only allows the user running the service to connect to
+// the socket.
+const DefaultSocketMode os.FileMode = 0o600
+
+// SocketConfig serves the service on a Unix domain socket, protected by the
+// filesystem permissions. It's served alongside the TCP listener, unless the
+// latter is disabled.
+type SocketConfig struct {
+	Path string `json:"path"`
+	// Mode is the octal permission of the socket file, like "0660". It
+	// defaults to DefaultSocketMode.
+	Mode string `json:"mode"`
+	// Owner and Group own the socket file, by name or by ID. If empty, they
+	// are left to the user running the service.
+	Owner string `json:"owner"`
+	Group string `json:"group"`
+	// DisableTCP only serves the service on the socket.
+	DisableTCP bool `json:"disableTcp"`
+}
+
+func (c SocketConfig) IsEnabled() bool {
+	return len(c.Path) != 0
+}
+
+func (c SocketConfig) FileMode() (os.FileMode, error) {
+	if len(c.Mode) == 0 {
+		return DefaultSocketMode, nil
+	}
+
+	mode, err := strconv.ParseUint(c.Mode, 8, 32)
+	if err != nil || mode > 0o777 {
+		return 0, fmt.Errorf("%w: %s", ErrNetworkSocketModeIsInvalid, c.Mode)
+	}
+
+	return os.FileMode(mode), nil
+}
+
+// MetricsConfig exposes the metrics of the service at `/metrics`, in the
+// Prometheus text exposition format, on a listener of its own. They are not
+// protected by a token, so the host should only be reachable by the
+// monitoring system.
+type MetricsConfig struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+func (c MetricsConfig) IsEnabled() bool {
+	return len(c.Host) != 0 && c.Port != 0
+}
+
+// AdminConfig serves the admin API, used to decide on the consent requests
+// when the service runs without terminal, on a listener of its own. It's
+// protected by the admin token. It's served with the TLS certificate of the
+// service, if the service serves HTTPS, so the certificate has to cover the
+// admin host. The generated certificate does.
+type AdminConfig struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+func (c AdminConfig) IsEnabled() bool {
+	return len(c.Host) != 0 && c.Port != 0
 }
 
 type APIConfig struct {
@@ -80,3 +206,71 @@ func (n *Network) EnsureCanConnectTokenDApp() error {
 	}
 	return nil
 }
+
+// EnsureServesSecurely verifies the service doesn't serve plain HTTP on a host
+// other than a loopback address, as the tokens and the passphrases would cross
+// the network in clear text.
+func (n *Network) EnsureServesSecurely() error {
+	if !n.ServesTCP() || n.TLS.IsEnabled() || IsLoopbackHost(n.Host) {
+		return nil
+	}
+	return ErrNetworkServesPlaintextOnNonLoopbackHost
+}
+
+// EnsureCanServeAdmin verifies the admin API can be served, and that the
+// admin token wouldn't cross the network in clear text.
+func (n *Network) EnsureCanServeAdmin() error {
+	if !n.Admin.IsEnabled() {
+		return ErrNetworkDoesNotHaveAdminConfigured
+	}
+	servesTLS := n.ServesTCP() && n.TLS.IsEnabled()
+	if !servesTLS && !IsLoopbackHost(n.Admin.Host) {
+		return ErrNetworkServesAdminPlaintextOnNonLoopbackHost
+	}
+	return nil
+}
+
+// ServesTCP verifies the service listens on its host and port. It doesn't when
+// it's only served on a Unix domain socket.
+func (n *Network) ServesTCP() bool {
+	return !n.Socket.DisableTCP
+}
+
+// IsLoopbackHost verifies the host only accepts connections from the local
+// machine.
+func IsLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Diagnose returns all the issues that would prevent the service from running
+// with this network configuration.
+func (n *Network) Diagnose() []error {
+	issues := []error{}
+	if n.ServesTCP() {
+		if len(n.Host) == 0 {
+			issues = append(issues, ErrNetworkDoesNotHaveHostConfigured)
+		}
+		if n.Port == 0 {
+			issues = append(issues, ErrNetworkDoesNotHavePortConfigured)
+		}
+	} else if !n.Socket.IsEnabled() {
+		issues = append(issues, ErrNetworkDisablesTCPWithoutSocket)
+	}
+	if _, err := n.Socket.FileMode(); err != nil {
+		issues = append(issues, err)
+	}
+	if n.TokenExpiry.Get() <= 0 {
+		issues = append(issues, ErrNetworkDoesNotHaveTokenExpiryConfigured)
+	}
+	if n.TLS.UsesCertificateFiles() && (len(n.TLS.CertFile) == 0 || len(n.TLS.KeyFile) == 0) {
+		issues = append(issues, ErrNetworkTLSRequiresCertificateAndKey)
+	}
+	if err := n.EnsureCanConnectGRPCNode(); err != nil {
+		issues = append(issues, err)
+	}
+	return issues
+}
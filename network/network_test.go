@@ -1,16 +1,27 @@
 package network_test
 
 import (
+	"os"
 	"testing"
 
 	"code.vegaprotocol.io/vegawallet/network"
+	"code.vegaprotocol.io/vegawallet/service/encoding"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
 )
 
 func Test(t *testing.T) {
 	t.Run("Ensure network can connect to a gRPC node fails", testEnsureNetworkCanConnectGRPCNodeFails)
 	t.Run("Ensure network can connect to a console fails", testEnsureNetworkCanConnectConsoleFails)
 	t.Run("Ensure network can connect to a token dApp fails", testEnsureNetworkCanConnectTokenDAppFails)
+	t.Run("Merging reloadable fields only applies safe changes", testMergingReloadableFieldsOnlyAppliesSafeChanges)
+	t.Run("Ensure network serves securely", testEnsureNetworkServesSecurely)
+	t.Run("Ensure network can serve admin", testEnsureNetworkCanServeAdmin)
+	t.Run("Diagnosing TLS without key fails", testDiagnosingTLSWithoutKeyFails)
+	t.Run("Diagnosing disabled TCP without socket fails", testDiagnosingDisabledTCPWithoutSocketFails)
+	t.Run("Diagnosing socket only network does not require host and port", testDiagnosingSocketOnlyNetworkDoesNotRequireHostAndPort)
+	t.Run("Getting socket file mode", testGettingSocketFileMode)
 }
 
 func testEnsureNetworkCanConnectGRPCNodeFails(t *testing.T) {
@@ -104,3 +115,269 @@ func testEnsureNetworkCanConnectTokenDAppFails(t *testing.T) {
 		})
 	}
 }
+
+func testMergingReloadableFieldsOnlyAppliesSafeChanges(t *testing.T) {
+	// given
+	current := &network.Network{
+		Name:  "test",
+		Level: encoding.LogLevel{Level: zapcore.InfoLevel},
+		Host:  "127.0.0.1",
+		Port:  8000,
+		API: network.APIConfig{
+			GRPC: network.GRPCConfig{
+				Hosts:   []string{"n01.example.com:3002"},
+				Retries: 5,
+			},
+		},
+	}
+	updated := &network.Network{
+		Name:  "test",
+		Level: encoding.LogLevel{Level: zapcore.DebugLevel},
+		Host:  "127.0.0.1",
+		Port:  9000,
+		API: network.APIConfig{
+			GRPC: network.GRPCConfig{
+				Hosts:   []string{"n02.example.com:3002"},
+				Retries: 5,
+			},
+		},
+	}
+
+	// when
+	merged, applied, ignored := network.MergeReloadableFields(current, updated)
+
+	// then
+	assert.Equal(t, zapcore.DebugLevel, merged.Level.Get())
+	assert.Equal(t, []string{"n02.example.com:3002"}, merged.API.GRPC.Hosts)
+	assert.Equal(t, 8000, merged.Port)
+	assert.Equal(t, []network.FieldChange{
+		{Field: "Level", Previous: "info", Current: "debug"},
+		{Field: "API.GRPC.Hosts", Previous: []string{"n01.example.com:3002"}, Current: []string{"n02.example.com:3002"}},
+	}, applied)
+	assert.Equal(t, []network.FieldChange{
+		{Field: "Port", Previous: 8000, Current: 9000},
+	}, ignored)
+	assert.Equal(t, zapcore.InfoLevel, current.Level.Get())
+}
+
+func testEnsureNetworkServesSecurely(t *testing.T) {
+	tcs := []struct {
+		name   string
+		host   string
+		tls    network.TLSConfig
+		socket network.SocketConfig
+		err    error
+	}{
+		{
+			name: "plaintext on IPv4 loopback",
+			host: "127.0.0.1",
+		}, {
+			name: "plaintext on IPv6 loopback",
+			host: "::1",
+		}, {
+			name: "plaintext on localhost",
+			host: "localhost",
+		}, {
+			name: "plaintext on all interfaces",
+			host: "0.0.0.0",
+			err:  network.ErrNetworkServesPlaintextOnNonLoopbackHost,
+		}, {
+			name: "plaintext on domain",
+			host: "wallet.example.com",
+			err:  network.ErrNetworkServesPlaintextOnNonLoopbackHost,
+		}, {
+			name: "generated certificate on all interfaces",
+			host: "0.0.0.0",
+			tls: network.TLSConfig{
+				AutoGenerate: true,
+			},
+		}, {
+			name: "certificate files on domain",
+			host: "wallet.example.com",
+			tls: network.TLSConfig{
+				CertFile: "cert.pem",
+				KeyFile:  "key.pem",
+			},
+		}, {
+			name: "socket only on all interfaces",
+			host: "0.0.0.0",
+			socket: network.SocketConfig{
+				Path:       "/run/vegawallet.sock",
+				DisableTCP: true,
+			},
+		}, {
+			name: "socket alongside plaintext on all interfaces",
+			host: "0.0.0.0",
+			socket: network.SocketConfig{
+				Path: "/run/vegawallet.sock",
+			},
+			err: network.ErrNetworkServesPlaintextOnNonLoopbackHost,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			// given
+			net := &network.Network{
+				Host:   tc.host,
+				TLS:    tc.tls,
+				Socket: tc.socket,
+			}
+
+			// when
+			err := net.EnsureServesSecurely()
+
+			// then
+			if tc.err == nil {
+				require.NoError(tt, err)
+			} else {
+				require.ErrorIs(tt, err, tc.err)
+			}
+		})
+	}
+}
+
+func testEnsureNetworkCanServeAdmin(t *testing.T) {
+	tcs := []struct {
+		name  string
+		admin network.AdminConfig
+		tls   network.TLSConfig
+		err   error
+	}{
+		{
+			name:  "plaintext on loopback",
+			admin: network.AdminConfig{Host: "127.0.0.1", Port: 1790},
+		}, {
+			name:  "plaintext on all interfaces",
+			admin: network.AdminConfig{Host: "0.0.0.0", Port: 1790},
+			err:   network.ErrNetworkServesAdminPlaintextOnNonLoopbackHost,
+		}, {
+			name:  "generated certificate on all interfaces",
+			admin: network.AdminConfig{Host: "0.0.0.0", Port: 1790},
+			tls: network.TLSConfig{
+				AutoGenerate: true,
+			},
+		}, {
+			name:  "without port",
+			admin: network.AdminConfig{Host: "127.0.0.1"},
+			err:   network.ErrNetworkDoesNotHaveAdminConfigured,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			// given
+			net := &network.Network{
+				Host:  "127.0.0.1",
+				Admin: tc.admin,
+				TLS:   tc.tls,
+			}
+
+			// when
+			err := net.EnsureCanServeAdmin()
+
+			// then
+			if tc.err == nil {
+				require.NoError(tt, err)
+			} else {
+				require.ErrorIs(tt, err, tc.err)
+			}
+		})
+	}
+}
+
+func testDiagnosingTLSWithoutKeyFails(t *testing.T) {
+	// given
+	net := &network.Network{
+		Host: "127.0.0.1",
+		TLS: network.TLSConfig{
+			CertFile: "cert.pem",
+		},
+	}
+
+	// when
+	issues := net.Diagnose()
+
+	// then
+	assert.Contains(t, issues, network.ErrNetworkTLSRequiresCertificateAndKey)
+}
+
+func testDiagnosingDisabledTCPWithoutSocketFails(t *testing.T) {
+	// given
+	net := &network.Network{
+		Socket: network.SocketConfig{
+			DisableTCP: true,
+		},
+	}
+
+	// when
+	issues := net.Diagnose()
+
+	// then
+	assert.Contains(t, issues, network.ErrNetworkDisablesTCPWithoutSocket)
+}
+
+func testDiagnosingSocketOnlyNetworkDoesNotRequireHostAndPort(t *testing.T) {
+	// given
+	net := &network.Network{
+		Socket: network.SocketConfig{
+			Path:       "/run/vegawallet.sock",
+			DisableTCP: true,
+		},
+	}
+
+	// when
+	issues := net.Diagnose()
+
+	// then
+	assert.NotContains(t, issues, network.ErrNetworkDoesNotHaveHostConfigured)
+	assert.NotContains(t, issues, network.ErrNetworkDoesNotHavePortConfigured)
+	assert.NotContains(t, issues, network.ErrNetworkDisablesTCPWithoutSocket)
+}
+
+func testGettingSocketFileMode(t *testing.T) {
+	tcs := []struct {
+		name string
+		mode string
+		want os.FileMode
+		err  error
+	}{
+		{
+			name: "default mode",
+			want: network.DefaultSocketMode,
+		}, {
+			name: "octal mode",
+			mode: "0660",
+			want: 0o660,
+		}, {
+			name: "non-octal mode",
+			mode: "0880",
+			err:  network.ErrNetworkSocketModeIsInvalid,
+		}, {
+			name: "mode with special bits",
+			mode: "4777",
+			err:  network.ErrNetworkSocketModeIsInvalid,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			// given
+			cfg := network.SocketConfig{
+				Path: "/run/vegawallet.sock",
+				Mode: tc.mode,
+			}
+
+			// when
+			mode, err := cfg.FileMode()
+
+			// then
+			if tc.err == nil {
+				require.NoError(tt, err)
+				assert.Equal(tt, tc.want, mode)
+			} else {
+				require.ErrorIs(tt, err, tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package network
+
+import (
+	"reflect"
+)
+
+// FieldChange describes the change of a field in a network configuration.
+type FieldChange struct {
+	Field    string      `json:"field"`
+	Previous interface{} `json:"previous"`
+	Current  interface{} `json:"current"`
+}
+
+// MergeReloadableFields returns a copy of the current network configuration
+// updated with the fields of the updated one that can safely be changed while
+// the service is running: the node hosts, the log level and the idempotency
+// window. It also returns the list of these applied changes, and the list of
+// the changes that are ignored because they require a restart of the service,
+// like the service address.
+func MergeReloadableFields(current, updated *Network) (*Network, []FieldChange, []FieldChange) {
+	merged := *current
+	applied := []FieldChange{}
+	ignored := []FieldChange{}
+
+	apply := func(field string, previous, current interface{}, set func()) {
+		if reflect.DeepEqual(previous, current) {
+			return
+		}
+		set()
+		applied = append(applied, FieldChange{Field: field, Previous: previous, Current: current})
+	}
+
+	ignore := func(field string, previous, current interface{}) {
+		if reflect.DeepEqual(previous, current) {
+			return
+		}
+		ignored = append(ignored, FieldChange{Field: field, Previous: previous, Current: current})
+	}
+
+	apply("Level", current.Level.String(), updated.Level.String(), func() {
+		merged.Level = updated.Level
+	})
+	apply("IdempotencyWindow", current.IdempotencyWindow.String(), updated.IdempotencyWindow.String(), func() {
+		merged.IdempotencyWindow = updated.IdempotencyWindow
+	})
+	apply("API.GRPC.Hosts", current.API.GRPC.Hosts, updated.API.GRPC.Hosts, func() {
+		merged.API.GRPC.Hosts = updated.API.GRPC.Hosts
+	})
+	apply("API.GRPC.Retries", current.API.GRPC.Retries, updated.API.GRPC.Retries, func() {
+		merged.API.GRPC.Retries = updated.API.GRPC.Retries
+	})
+	apply("API.REST.Hosts", current.API.REST.Hosts, updated.API.REST.Hosts, func() {
+		merged.API.REST.Hosts = updated.API.REST.Hosts
+	})
+	apply("API.GraphQL.Hosts", current.API.GraphQL.Hosts, updated.API.GraphQL.Hosts, func() {
+		merged.API.GraphQL.Hosts = updated.API.GraphQL.Hosts
+	})
+
+	ignore("Host", current.Host, updated.Host)
+	ignore("Port", current.Port, updated.Port)
+	ignore("TLS", current.TLS, updated.TLS)
+	ignore("Socket", current.Socket, updated.Socket)
+	ignore("Metrics", current.Metrics, updated.Metrics)
+	ignore("Admin", current.Admin, updated.Admin)
+	ignore("TokenExpiry", current.TokenExpiry.String(), updated.TokenExpiry.String())
+	ignore("WalletIdleTimeout", current.WalletIdleTimeout.String(), updated.WalletIdleTimeout.String())
+	ignore("Console", current.Console, updated.Console)
+	ignore("TokenDApp", current.TokenDApp, updated.TokenDApp)
+
+	return &merged, applied, ignored
+}
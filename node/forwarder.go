@@ -2,11 +2,13 @@ package node
 
 import (
 	"context"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	api "code.vegaprotocol.io/protos/vega/api/v1"
 	commandspb "code.vegaprotocol.io/protos/vega/commands/v1"
+	"code.vegaprotocol.io/vegawallet/metrics"
 	"code.vegaprotocol.io/vegawallet/network"
 	"github.com/cenkalti/backoff/v4"
 	"go.uber.org/zap"
@@ -21,23 +23,18 @@ type Forwarder struct {
 	clts     []api.CoreServiceClient
 	conns    []*grpc.ClientConn
 	next     uint64
+	// metrics records the calls to the nodes, if set.
+	metrics *metrics.Metrics
+
+	// mu protects the nodes configuration and clients, as they can be
+	// replaced while the forwarder is in use.
+	mu sync.RWMutex
 }
 
 func NewForwarder(log *zap.Logger, nodeConfigs network.GRPCConfig) (*Forwarder, error) {
-	if len(nodeConfigs.Hosts) == 0 {
-		return nil, ErrNoHostSpecified
-	}
-
-	clts := make([]api.CoreServiceClient, 0, len(nodeConfigs.Hosts))
-	conns := make([]*grpc.ClientConn, 0, len(nodeConfigs.Hosts))
-	for _, v := range nodeConfigs.Hosts {
-		conn, err := grpc.Dial(v, grpc.WithTransportCredentials(insecure.NewCredentials()))
-		if err != nil {
-			log.Debug("Couldn't dial gRPC host", zap.String("address", v))
-			return nil, err
-		}
-		conns = append(conns, conn)
-		clts = append(clts, api.NewCoreServiceClient(conn))
+	clts, conns, err := dial(log, nodeConfigs)
+	if err != nil {
+		return nil, err
 	}
 
 	return &Forwarder{
@@ -48,13 +45,38 @@ func NewForwarder(log *zap.Logger, nodeConfigs network.GRPCConfig) (*Forwarder,
 	}, nil
 }
 
+// UpdateNodes replaces the nodes the transactions are forwarded to. The
+// connections to the previous nodes are closed. If the new nodes can't be
+// dialed, the previous ones are kept.
+func (n *Forwarder) UpdateNodes(nodeConfigs network.GRPCConfig) error {
+	clts, conns, err := dial(n.log, nodeConfigs)
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	previousCfgs, previousConns := n.nodeCfgs, n.conns
+	n.nodeCfgs, n.clts, n.conns = nodeConfigs, clts, conns
+	n.mu.Unlock()
+
+	return closeConns(n.log, previousCfgs, previousConns)
+}
+
+// UseMetrics records the latency, the errors and the retries of the calls to
+// the nodes, per host.
+func (n *Forwarder) UseMetrics(m *metrics.Metrics) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.metrics = m
+}
+
 func (n *Forwarder) Stop() error {
-	for i, v := range n.nodeCfgs.Hosts {
-		n.log.Debug("Closing gRPC client", zap.String("address", v))
-		if err := n.conns[i].Close(); err != nil {
-			n.log.Warn("Couldn't close gRPC client", zap.Error(err))
-			return err
-		}
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	if err := closeConns(n.log, n.nodeCfgs, n.conns); err != nil {
+		return err
 	}
 	n.log.Info("gRPC clients successfully closed")
 	return nil
@@ -62,17 +84,21 @@ func (n *Forwarder) Stop() error {
 
 func (n *Forwarder) HealthCheck(ctx context.Context) error {
 	req := api.GetVegaTimeRequest{}
+	attempt := 0
 	return backoff.Retry(
 		func() error {
-			clt := n.clts[n.nextClt()]
-			resp, err := clt.GetVegaTime(ctx, &req)
+			attempt++
+			cltIdx := n.nextClt()
+			startedAt := time.Now()
+			resp, err := n.client(cltIdx).GetVegaTime(ctx, &req)
+			n.observe(cltIdx, "GetVegaTime", attempt, startedAt, err)
 			if err != nil {
 				return err
 			}
 			n.log.Debug("Response from GetVegaTime", zap.Int64("timestamp", resp.Timestamp))
 			return nil
 		},
-		backoff.WithMaxRetries(backoff.NewExponentialBackOff(), n.nodeCfgs.Retries),
+		backoff.WithMaxRetries(backoff.NewExponentialBackOff(), n.retries()),
 	)
 }
 
@@ -81,10 +107,14 @@ func (n *Forwarder) LastBlockHeightAndHash(ctx context.Context) (*api.LastBlockH
 	req := api.LastBlockHeightRequest{}
 	var resp *api.LastBlockHeightResponse
 	clt := -1
+	attempt := 0
 	err := backoff.Retry(
 		func() error {
+			attempt++
 			clt = n.nextClt()
-			r, err := n.clts[clt].LastBlockHeight(ctx, &req)
+			startedAt := time.Now()
+			r, err := n.client(clt).LastBlockHeight(ctx, &req)
+			n.observe(clt, "LastBlockHeight", attempt, startedAt, err)
 			if err != nil {
 				n.log.Debug("Couldn't get last block", zap.Error(err))
 				return err
@@ -93,7 +123,7 @@ func (n *Forwarder) LastBlockHeightAndHash(ctx context.Context) (*api.LastBlockH
 			n.log.Info("", zap.Uint64("block-height", r.Height), zap.String("block-hash", r.Hash), zap.Uint32("difficulty", r.SpamPowDifficulty), zap.String("function", r.SpamPowHashFunction))
 			return nil
 		},
-		backoff.WithMaxRetries(backoff.NewExponentialBackOff(), n.nodeCfgs.Retries),
+		backoff.WithMaxRetries(backoff.NewExponentialBackOff(), n.retries()),
 	)
 
 	if err != nil {
@@ -117,10 +147,13 @@ func (n *Forwarder) CheckTx(ctx context.Context, tx *commandspb.Transaction, clt
 	if cltIdx < 0 {
 		cltIdx = n.nextClt()
 	}
+	attempt := 0
 	err := backoff.Retry(
 		func() error {
-			clt := n.clts[cltIdx]
-			r, err := clt.CheckTransaction(ctx, &req)
+			attempt++
+			startedAt := time.Now()
+			r, err := n.client(cltIdx).CheckTransaction(ctx, &req)
+			n.observe(cltIdx, "CheckTransaction", attempt, startedAt, err)
 			if err != nil {
 				n.log.Error("Couldn't check transaction", zap.Error(err))
 				return err
@@ -131,7 +164,7 @@ func (n *Forwarder) CheckTx(ctx context.Context, tx *commandspb.Transaction, clt
 			resp = r
 			return nil
 		},
-		backoff.WithMaxRetries(backoff.NewExponentialBackOff(), n.nodeCfgs.Retries),
+		backoff.WithMaxRetries(backoff.NewExponentialBackOff(), n.retries()),
 	)
 
 	return resp, err
@@ -146,10 +179,13 @@ func (n *Forwarder) SendTx(ctx context.Context, tx *commandspb.Transaction, ty a
 	if cltIdx < 0 {
 		cltIdx = n.nextClt()
 	}
+	attempt := 0
 	if err := backoff.Retry(
 		func() error {
-			clt := n.clts[cltIdx]
-			r, err := clt.SubmitTransaction(ctx, &req)
+			attempt++
+			startedAt := time.Now()
+			r, err := n.client(cltIdx).SubmitTransaction(ctx, &req)
+			n.observe(cltIdx, "SubmitTransaction", attempt, startedAt, err)
 			if err != nil {
 				return n.handleSubmissionError(err)
 			}
@@ -160,7 +196,7 @@ func (n *Forwarder) SendTx(ctx context.Context, tx *commandspb.Transaction, ty a
 			resp = r
 			return nil
 		},
-		backoff.WithMaxRetries(backoff.NewExponentialBackOff(), n.nodeCfgs.Retries),
+		backoff.WithMaxRetries(backoff.NewExponentialBackOff(), n.retries()),
 	); err != nil {
 		return "", err
 	}
@@ -194,9 +230,79 @@ func (n *Forwarder) handleSubmissionError(err error) error {
 }
 
 func (n *Forwarder) nextClt() int {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
 	i := atomic.AddUint64(&n.next, 1)
 	n.log.Info("Sending transaction to Vega node",
 		zap.String("host", n.nodeCfgs.Hosts[(int(i)-1)%len(n.clts)]),
 	)
 	return (int(i) - 1) % len(n.clts)
 }
+
+// client returns the client at the specified index. As the nodes can be
+// updated between the selection of a client and its use, an index that is
+// no longer valid falls back on the first client.
+func (n *Forwarder) client(i int) api.CoreServiceClient {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	if i < 0 || i >= len(n.clts) {
+		return n.clts[0]
+	}
+	return n.clts[i]
+}
+
+// observe records the call to the node at the specified index, if the metrics
+// are enabled.
+func (n *Forwarder) observe(i int, method string, attempt int, startedAt time.Time, err error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	if n.metrics == nil {
+		return
+	}
+	if i < 0 || i >= len(n.nodeCfgs.Hosts) {
+		i = 0
+	}
+	n.metrics.ObserveNodeRequest(n.nodeCfgs.Hosts[i], method, attempt, time.Since(startedAt), err)
+}
+
+func (n *Forwarder) retries() uint64 {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	return n.nodeCfgs.Retries
+}
+
+func dial(log *zap.Logger, nodeConfigs network.GRPCConfig) ([]api.CoreServiceClient, []*grpc.ClientConn, error) {
+	if len(nodeConfigs.Hosts) == 0 {
+		return nil, nil, ErrNoHostSpecified
+	}
+
+	clts := make([]api.CoreServiceClient, 0, len(nodeConfigs.Hosts))
+	conns := make([]*grpc.ClientConn, 0, len(nodeConfigs.Hosts))
+	for _, v := range nodeConfigs.Hosts {
+		conn, err := grpc.Dial(v, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			log.Debug("Couldn't dial gRPC host", zap.String("address", v))
+			_ = closeConns(log, nodeConfigs, conns)
+			return nil, nil, err
+		}
+		conns = append(conns, conn)
+		clts = append(clts, api.NewCoreServiceClient(conn))
+	}
+
+	return clts, conns, nil
+}
+
+func closeConns(log *zap.Logger, nodeConfigs network.GRPCConfig, conns []*grpc.ClientConn) error {
+	for i, conn := range conns {
+		log.Debug("Closing gRPC client", zap.String("address", nodeConfigs.Hosts[i]))
+		if err := conn.Close(); err != nil {
+			log.Warn("Couldn't close gRPC client", zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package passphrase
+
+import (
+	"fmt"
+
+	vgfs "code.vegaprotocol.io/shared/libs/fs"
+	"code.vegaprotocol.io/shared/paths"
+)
+
+// ConfigFile is the name of the file, in the wallet CLI configuration home,
+// holding the passphrase providers configuration.
+const ConfigFile = "passphrase-providers.toml"
+
+// Config holds the passphrase providers. The provider configured for a wallet
+// takes precedence over the default one.
+type Config struct {
+	Default *Provider           `json:"default"`
+	Wallets map[string]Provider `json:"wallets"`
+}
+
+func (c *Config) Validate() error {
+	if c.Default != nil {
+		if err := c.Default.Validate(); err != nil {
+			return NewInvalidProviderError("", err)
+		}
+	}
+	for wallet, provider := range c.Wallets {
+		provider := provider
+		if err := provider.Validate(); err != nil {
+			return NewInvalidProviderError(wallet, err)
+		}
+	}
+	return nil
+}
+
+// ProviderFor returns the provider configured for the specified wallet, or the
+// default one. It returns nil if there is none.
+func (c *Config) ProviderFor(wallet string) *Provider {
+	if c == nil {
+		return nil
+	}
+	if provider, ok := c.Wallets[wallet]; ok {
+		return &provider
+	}
+	return c.Default
+}
+
+// LoadConfig loads the passphrase providers configuration. The configuration
+// file is optional, so an empty configuration is returned if it doesn't exist.
+func LoadConfig(vegaPaths paths.Paths) (*Config, error) {
+	configPath := vegaPaths.ConfigPathFor(paths.JoinConfigPath(paths.WalletCLIConfigHome, ConfigFile))
+
+	exists, err := vgfs.FileExists(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't verify the passphrase providers configuration exists: %w", err)
+	}
+
+	cfg := &Config{}
+	if !exists {
+		return cfg, nil
+	}
+
+	if err := paths.ReadStructuredFile(configPath, cfg); err != nil {
+		return nil, fmt.Errorf("couldn't read the passphrase providers configuration: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
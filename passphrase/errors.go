@@ -0,0 +1,49 @@
+package passphrase
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrHelperDidNotReturnPassphrase = errors.New("the passphrase helper didn't return any passphrase")
+	ErrProviderHasNoSource          = errors.New("the passphrase provider must have a helper or an environment variable set")
+	ErrProviderHasTooManySources    = errors.New("the passphrase provider can't have both a helper and an environment variable set")
+)
+
+type EnvironmentVariableIsNotSetError struct {
+	Name string
+}
+
+func NewEnvironmentVariableIsNotSetError(name string) EnvironmentVariableIsNotSetError {
+	return EnvironmentVariableIsNotSetError{
+		Name: name,
+	}
+}
+
+func (e EnvironmentVariableIsNotSetError) Error() string {
+	return fmt.Sprintf("environment variable \"%s\" is not set", e.Name)
+}
+
+type InvalidProviderError struct {
+	Wallet string
+	Err    error
+}
+
+func NewInvalidProviderError(wallet string, err error) InvalidProviderError {
+	return InvalidProviderError{
+		Wallet: wallet,
+		Err:    err,
+	}
+}
+
+func (e InvalidProviderError) Error() string {
+	if len(e.Wallet) == 0 {
+		return fmt.Sprintf("invalid default passphrase provider: %v", e.Err)
+	}
+	return fmt.Sprintf("invalid passphrase provider for wallet \"%s\": %v", e.Wallet, e.Err)
+}
+
+func (e InvalidProviderError) Unwrap() error {
+	return e.Err
+}
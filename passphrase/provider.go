@@ -0,0 +1,98 @@
+// Package passphrase provides the wallet passphrases from sources that don't
+// require the user input, configured per wallet or globally.
+//
+// A provider is either an environment variable, or a helper executable,
+// inspired by the git credential helpers. The helper is called with its
+// arguments, and receives on its standard input the description of the
+// passphrase to get, as "key=value" lines, terminated by an empty line:
+//
+//	wallet=my-wallet
+//
+// It must write the passphrase on its standard output, using the same format:
+//
+//	passphrase=my-passphrase
+//
+// The standard error of the helper is forwarded to the user, so the helper can
+// prompt for information. The helper must exit with a non-zero status code when
+// it can't provide the passphrase.
+package passphrase
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Provider describes where the passphrase of a wallet comes from. Only one of
+// the helper or the environment variable can be set.
+type Provider struct {
+	// Helper is the path to the helper executable.
+	Helper string `json:"helper"`
+	// Args are the arguments the helper is called with.
+	Args []string `json:"args"`
+	// Env is the name of the environment variable holding the passphrase.
+	Env string `json:"env"`
+}
+
+func (p *Provider) Validate() error {
+	if len(p.Helper) == 0 && len(p.Env) == 0 {
+		return ErrProviderHasNoSource
+	}
+	if len(p.Helper) != 0 && len(p.Env) != 0 {
+		return ErrProviderHasTooManySources
+	}
+	return nil
+}
+
+// GetPassphrase returns the passphrase of the specified wallet.
+func (p *Provider) GetPassphrase(wallet string) (string, error) {
+	if len(p.Env) != 0 {
+		passphrase, ok := os.LookupEnv(p.Env)
+		if !ok || len(passphrase) == 0 {
+			return "", NewEnvironmentVariableIsNotSetError(p.Env)
+		}
+		return passphrase, nil
+	}
+
+	return p.callHelper(wallet)
+}
+
+func (p *Provider) callHelper(wallet string) (string, error) {
+	input := &bytes.Buffer{}
+	fmt.Fprintf(input, "wallet=%s\n\n", wallet)
+
+	output := &bytes.Buffer{}
+
+	cmd := exec.Command(p.Helper, p.Args...) //nolint:gosec
+	cmd.Stdin = input
+	cmd.Stdout = output
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("couldn't get the passphrase from helper %s: %w", p.Helper, err)
+	}
+
+	scanner := bufio.NewScanner(output)
+	for scanner.Scan() {
+		key, value, found := cut(strings.TrimRight(scanner.Text(), "\r"), "=")
+		if found && key == "passphrase" && len(value) != 0 {
+			return value, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("couldn't read the helper output: %w", err)
+	}
+
+	return "", ErrHelperDidNotReturnPassphrase
+}
+
+// cut is strings.Cut, that is not available in Go 1.17.
+func cut(s, sep string) (string, string, bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
@@ -0,0 +1,243 @@
+package passphrase_test
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"testing"
+
+	vgrand "code.vegaprotocol.io/shared/libs/rand"
+	"code.vegaprotocol.io/shared/paths"
+	"code.vegaprotocol.io/vegawallet/passphrase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHelperProcess isn't a real test. It's used as a fake passphrase helper,
+// when the test binary is called by the provider.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	defer os.Exit(0)
+
+	wallet := ""
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(line) == 0 {
+			break
+		}
+		if len(line) > len("wallet=") && line[:len("wallet=")] == "wallet=" {
+			wallet = line[len("wallet="):]
+		}
+	}
+
+	switch os.Getenv("HELPER_BEHAVIOUR") {
+	case "fail":
+		os.Exit(1)
+	case "empty":
+		return
+	default:
+		fmt.Printf("passphrase=passphrase-of-%s\n", wallet) //nolint:forbidigo
+	}
+}
+
+func TestProvider(t *testing.T) {
+	t.Run("Getting passphrase from environment variable succeeds", testProviderGettingPassphraseFromEnvironmentVariableSucceeds)
+	t.Run("Getting passphrase from unset environment variable fails", testProviderGettingPassphraseFromUnsetEnvironmentVariableFails)
+	t.Run("Getting passphrase from helper succeeds", testProviderGettingPassphraseFromHelperSucceeds)
+	t.Run("Getting passphrase from failing helper fails", testProviderGettingPassphraseFromFailingHelperFails)
+	t.Run("Getting passphrase from helper without output fails", testProviderGettingPassphraseFromHelperWithoutOutputFails)
+	t.Run("Validating provider without source fails", testProviderValidatingProviderWithoutSourceFails)
+	t.Run("Validating provider with too many sources fails", testProviderValidatingProviderWithTooManySourcesFails)
+}
+
+func testProviderGettingPassphraseFromEnvironmentVariableSucceeds(t *testing.T) {
+	// given
+	expectedPassphrase := vgrand.RandomStr(10)
+	t.Setenv("TEST_VEGAWALLET_PASSPHRASE", expectedPassphrase)
+	provider := &passphrase.Provider{Env: "TEST_VEGAWALLET_PASSPHRASE"}
+
+	// when
+	pass, err := provider.GetPassphrase(vgrand.RandomStr(5))
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, expectedPassphrase, pass)
+}
+
+func testProviderGettingPassphraseFromUnsetEnvironmentVariableFails(t *testing.T) {
+	// given
+	name := "TEST_VEGAWALLET_" + vgrand.RandomStr(5)
+	provider := &passphrase.Provider{Env: name}
+
+	// when
+	pass, err := provider.GetPassphrase(vgrand.RandomStr(5))
+
+	// then
+	assert.ErrorIs(t, err, passphrase.NewEnvironmentVariableIsNotSetError(name))
+	assert.Empty(t, pass)
+}
+
+func testProviderGettingPassphraseFromHelperSucceeds(t *testing.T) {
+	// given
+	wallet := vgrand.RandomStr(5)
+	provider := newHelperProvider(t, "")
+
+	// when
+	pass, err := provider.GetPassphrase(wallet)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, "passphrase-of-"+wallet, pass)
+}
+
+func testProviderGettingPassphraseFromFailingHelperFails(t *testing.T) {
+	// given
+	provider := newHelperProvider(t, "fail")
+
+	// when
+	pass, err := provider.GetPassphrase(vgrand.RandomStr(5))
+
+	// then
+	require.Error(t, err)
+	assert.Empty(t, pass)
+}
+
+func testProviderGettingPassphraseFromHelperWithoutOutputFails(t *testing.T) {
+	// given
+	provider := newHelperProvider(t, "empty")
+
+	// when
+	pass, err := provider.GetPassphrase(vgrand.RandomStr(5))
+
+	// then
+	assert.ErrorIs(t, err, passphrase.ErrHelperDidNotReturnPassphrase)
+	assert.Empty(t, pass)
+}
+
+func testProviderValidatingProviderWithoutSourceFails(t *testing.T) {
+	// given
+	provider := &passphrase.Provider{}
+
+	// when
+	err := provider.Validate()
+
+	// then
+	assert.ErrorIs(t, err, passphrase.ErrProviderHasNoSource)
+}
+
+func testProviderValidatingProviderWithTooManySourcesFails(t *testing.T) {
+	// given
+	provider := &passphrase.Provider{
+		Helper: "pass",
+		Env:    "TEST_VEGAWALLET_PASSPHRASE",
+	}
+
+	// when
+	err := provider.Validate()
+
+	// then
+	assert.ErrorIs(t, err, passphrase.ErrProviderHasTooManySources)
+}
+
+func TestConfig(t *testing.T) {
+	t.Run("Loading missing configuration succeeds", testConfigLoadingMissingConfigurationSucceeds)
+	t.Run("Loading configuration succeeds", testConfigLoadingConfigurationSucceeds)
+	t.Run("Loading invalid configuration fails", testConfigLoadingInvalidConfigurationFails)
+	t.Run("Getting provider for wallet falls back on default", testConfigGettingProviderForWalletFallsBackOnDefault)
+}
+
+func testConfigLoadingMissingConfigurationSucceeds(t *testing.T) {
+	// given
+	vegaPaths := paths.New(t.TempDir())
+
+	// when
+	cfg, err := passphrase.LoadConfig(vegaPaths)
+
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+	assert.Nil(t, cfg.ProviderFor(vgrand.RandomStr(5)))
+}
+
+func testConfigLoadingConfigurationSucceeds(t *testing.T) {
+	// given
+	vegaPaths := paths.New(t.TempDir())
+	writeConfig(t, vegaPaths, `
+[Default]
+  Env = "TEST_VEGAWALLET_PASSPHRASE"
+
+[Wallets.my-wallet]
+  Helper = "pass-helper"
+  Args = ["--store", "vega"]
+`)
+
+	// when
+	cfg, err := passphrase.LoadConfig(vegaPaths)
+
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+	assert.Equal(t, &passphrase.Provider{
+		Helper: "pass-helper",
+		Args:   []string{"--store", "vega"},
+	}, cfg.ProviderFor("my-wallet"))
+	assert.Equal(t, &passphrase.Provider{
+		Env: "TEST_VEGAWALLET_PASSPHRASE",
+	}, cfg.ProviderFor("other-wallet"))
+}
+
+func testConfigLoadingInvalidConfigurationFails(t *testing.T) {
+	// given
+	vegaPaths := paths.New(t.TempDir())
+	writeConfig(t, vegaPaths, `
+[Wallets.my-wallet]
+  Args = ["--store", "vega"]
+`)
+
+	// when
+	cfg, err := passphrase.LoadConfig(vegaPaths)
+
+	// then
+	assert.ErrorIs(t, err, passphrase.ErrProviderHasNoSource)
+	assert.Nil(t, cfg)
+}
+
+func testConfigGettingProviderForWalletFallsBackOnDefault(t *testing.T) {
+	// given
+	defaultProvider := &passphrase.Provider{Env: "TEST_VEGAWALLET_PASSPHRASE"}
+	cfg := &passphrase.Config{
+		Default: defaultProvider,
+		Wallets: map[string]passphrase.Provider{
+			"my-wallet": {Helper: "pass-helper"},
+		},
+	}
+
+	// when
+	provider := cfg.ProviderFor("other-wallet")
+
+	// then
+	assert.Equal(t, defaultProvider, provider)
+}
+
+func newHelperProvider(t *testing.T, behaviour string) *passphrase.Provider {
+	t.Helper()
+
+	t.Setenv("GO_WANT_HELPER_PROCESS", "1")
+	t.Setenv("HELPER_BEHAVIOUR", behaviour)
+
+	return &passphrase.Provider{
+		Helper: os.Args[0],
+		Args:   []string{"-test.run=TestHelperProcess", "--"},
+	}
+}
+
+func writeConfig(t *testing.T, vegaPaths paths.Paths, content string) {
+	t.Helper()
+
+	configPath, err := vegaPaths.CreateConfigPathFor(paths.JoinConfigPath(paths.WalletCLIConfigHome, passphrase.ConfigFile))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(configPath, []byte(content), 0o600))
+}
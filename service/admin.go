@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"code.vegaprotocol.io/protos/commands"
+	vgrand "code.vegaprotocol.io/shared/libs/rand"
+	"code.vegaprotocol.io/vegawallet/network"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+const (
+	// AdminTokenPrefix distinguishes the admin tokens from the other
+	// credentials.
+	AdminTokenPrefix = "vwa_"
+
+	adminTokenSecretLength = 32
+)
+
+// AdminToken protects the admin API. Only the hash of its secret is stored.
+type AdminToken struct {
+	SecretHash string    `json:"secretHash"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// AdminTokenStore persists the admin token.
+type AdminTokenStore interface {
+	// GetAdminToken returns nil if no admin token has been generated yet.
+	GetAdminToken() (*AdminToken, error)
+	SaveAdminToken(AdminToken) error
+}
+
+type GenerateAdminTokenResponse struct {
+	// Token is the admin token to use in the Authorization header. It's only
+	// returned at generation.
+	Token     string    `json:"token"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// GenerateAdminToken generates a new admin token, replacing the previous one.
+func GenerateAdminToken(store AdminTokenStore) (*GenerateAdminTokenResponse, error) {
+	secret := hex.EncodeToString(vgrand.RandomBytes(adminTokenSecretLength))
+
+	token := AdminToken{
+		SecretHash: hashAPIKeySecret(secret),
+		CreatedAt:  time.Now(),
+	}
+
+	if err := store.SaveAdminToken(token); err != nil {
+		return nil, fmt.Errorf("couldn't save the admin token: %w", err)
+	}
+
+	return &GenerateAdminTokenResponse{
+		Token:     AdminTokenPrefix + secret,
+		CreatedAt: token.CreatedAt,
+	}, nil
+}
+
+// VerifyAdminToken verifies the token matches the stored admin token.
+func VerifyAdminToken(store AdminTokenStore, token string) error {
+	if !strings.HasPrefix(token, AdminTokenPrefix) {
+		return ErrInvalidAdminToken
+	}
+
+	stored, err := store.GetAdminToken()
+	if err != nil {
+		return fmt.Errorf("couldn't get the admin token: %w", err)
+	}
+	if stored == nil {
+		return ErrAdminTokenNotGenerated
+	}
+
+	secret := strings.TrimPrefix(token, AdminTokenPrefix)
+	if subtle.ConstantTimeCompare([]byte(stored.SecretHash), []byte(hashAPIKeySecret(secret))) != 1 {
+		return ErrInvalidAdminToken
+	}
+
+	return nil
+}
+
+type DecideConsentRequest struct {
+	Approved *bool `json:"approved"`
+}
+
+func ParseDecideConsentRequest(r *http.Request) (*DecideConsentRequest, commands.Errors) {
+	errs := commands.NewErrors()
+
+	req := &DecideConsentRequest{}
+	if err := unmarshalBody(r, &req); err != nil {
+		return nil, errs.FinalAdd(err)
+	}
+
+	if req.Approved == nil {
+		errs.AddForProperty("approved", commands.ErrIsRequired)
+	}
+
+	if !errs.Empty() {
+		return nil, errs
+	}
+
+	return req, nil
+}
+
+// AdminService exposes the consent requests of the PendingConsentPolicy on
+// a listener of its own, so they can be decided by an operator console or
+// by another process. It's protected by the admin token.
+type AdminService struct {
+	*httprouter.Router
+
+	log      *zap.Logger
+	server   *http.Server
+	consents *PendingConsentPolicy
+	tokens   AdminTokenStore
+}
+
+func NewAdminService(log *zap.Logger, cfg network.AdminConfig, consents *PendingConsentPolicy, tokens AdminTokenStore) *AdminService {
+	s := &AdminService{
+		Router:   httprouter.New(),
+		log:      log,
+		consents: consents,
+		tokens:   tokens,
+	}
+
+	s.server = &http.Server{
+		Addr:    fmt.Sprintf("%s:%v", cfg.Host, cfg.Port),
+		Handler: s,
+		TLSConfig: &tls.Config{
+			MinVersion: tls.VersionTLS12,
+		},
+	}
+
+	s.GET("/admin/consents", s.authenticated(s.ListConsents))
+	s.POST("/admin/consents/:txid", s.authenticated(s.DecideConsent))
+
+	return s
+}
+
+func (s *AdminService) Start() error {
+	return s.server.ListenAndServe()
+}
+
+// StartTLS serves HTTPS with the specified certificate and key files.
+func (s *AdminService) StartTLS(certFile, keyFile string) error {
+	return s.server.ListenAndServeTLS(certFile, keyFile)
+}
+
+func (s *AdminService) Stop() error {
+	return s.server.Shutdown(context.Background())
+}
+
+func (s *AdminService) ListConsents(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	writeAdminResponse(w, http.StatusOK, s.consents.ListPendingConsents())
+}
+
+func (s *AdminService) DecideConsent(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	req, errs := ParseDecideConsentRequest(r)
+	if !errs.Empty() {
+		writeAdminResponse(w, http.StatusBadRequest, ErrorsResponse{Errors: errs})
+		return
+	}
+
+	id := ps.ByName("txid")
+	if err := s.consents.Decide(id, *req.Approved); err != nil {
+		writeError(w, newErrorResponse(err.Error()), http.StatusNotFound)
+		return
+	}
+
+	if *req.Approved {
+		s.log.Info("operator approved consent request", zap.String("id", id))
+	} else {
+		s.log.Info("operator rejected consent request", zap.String("id", id))
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// authenticated only lets the requests holding the admin token through.
+func (s *AdminService) authenticated(handle httprouter.Handle) httprouter.Handle {
+	return extractToken(func(token string, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		if err := VerifyAdminToken(s.tokens, token); err != nil {
+			s.log.Warn("admin request refused", zap.String("path", r.URL.Path), zap.Error(err))
+			writeError(w, newErrorResponse(ErrInvalidAdminToken.Error()), http.StatusUnauthorized)
+			return
+		}
+		handle(w, r, ps)
+	})
+}
+
+func writeAdminResponse(w http.ResponseWriter, status int, data interface{}) {
+	buf, err := json.Marshal(data)
+	if err != nil {
+		writeError(w, newErrorResponse(fmt.Sprintf("couldn't marshal the response: %v", err)), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(buf)
+}
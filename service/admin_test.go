@@ -0,0 +1,277 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "code.vegaprotocol.io/protos/vega/wallet/v1"
+	vgrand "code.vegaprotocol.io/shared/libs/rand"
+	"code.vegaprotocol.io/vegawallet/network"
+	"code.vegaprotocol.io/vegawallet/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type memoryAdminTokenStore struct {
+	token *service.AdminToken
+}
+
+func (s *memoryAdminTokenStore) GetAdminToken() (*service.AdminToken, error) {
+	return s.token, nil
+}
+
+func (s *memoryAdminTokenStore) SaveAdminToken(token service.AdminToken) error {
+	s.token = &token
+	return nil
+}
+
+type testAdminService struct {
+	*service.AdminService
+	consents *service.PendingConsentPolicy
+	token    string
+	cancel   context.CancelFunc
+}
+
+func getTestAdminService(t *testing.T) *testAdminService {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	store := &memoryAdminTokenStore{}
+	resp, err := service.GenerateAdminToken(store)
+	require.NoError(t, err)
+
+	consents := service.NewPendingConsentPolicy(ctx)
+
+	return &testAdminService{
+		AdminService: service.NewAdminService(zap.NewNop(), network.AdminConfig{}, consents, store),
+		consents:     consents,
+		token:        resp.Token,
+		cancel:       cancel,
+	}
+}
+
+func TestAdminService(t *testing.T) {
+	t.Run("Listing pending consents succeeds", testAdminServiceListingPendingConsentsSucceeds)
+	t.Run("Approving a pending consent succeeds", testAdminServiceApprovingPendingConsentSucceeds)
+	t.Run("Rejecting a pending connection succeeds", testAdminServiceRejectingPendingConnectionSucceeds)
+	t.Run("Deciding an unknown consent fails", testAdminServiceDecidingUnknownConsentFails)
+	t.Run("Deciding without decision fails", testAdminServiceDecidingWithoutDecisionFails)
+	t.Run("Requesting with an invalid token fails", testAdminServiceRequestingWithInvalidTokenFails)
+	t.Run("Interrupting the service interrupts the pending consents", testAdminServiceInterruptingPendingConsents)
+	t.Run("Disconnecting the client withdraws its pending consent", testAdminServiceDisconnectingClientWithdrawsPendingConsent)
+	t.Run("Queuing too many pending consents fails", testAdminServiceQueuingTooManyPendingConsentsFails)
+}
+
+func testAdminServiceListingPendingConsentsSucceeds(t *testing.T) {
+	s := getTestAdminService(t)
+
+	// given
+	txID := vgrand.RandomStr(5)
+	pubKey := vgrand.RandomStr(5)
+
+	// setup
+	go func() {
+		_, _ = s.consents.Ask(context.Background(), &v1.SubmitTransactionRequest{PubKey: pubKey}, txID, time.Now())
+	}()
+	waitForPendingConsents(t, s, 1)
+
+	// when
+	statusCode, body := serveAdminHTTP(t, s, http.MethodGet, "/admin/consents", "", s.token)
+
+	// then
+	require.Equal(t, http.StatusOK, statusCode)
+	resp := &service.PendingConsentsResponse{}
+	require.NoError(t, json.Unmarshal(body, resp))
+	require.Len(t, resp.Consents, 1)
+	assert.Equal(t, txID, resp.Consents[0].ID)
+	assert.Equal(t, service.PendingTransactionConsent, resp.Consents[0].Kind)
+	require.Len(t, resp.Consents[0].Transactions, 1)
+	assert.Contains(t, string(resp.Consents[0].Transactions[0]), pubKey)
+}
+
+func testAdminServiceApprovingPendingConsentSucceeds(t *testing.T) {
+	s := getTestAdminService(t)
+
+	// given
+	batchID := vgrand.RandomStr(5)
+	decisions := make(chan bool, 1)
+
+	// setup
+	go func() {
+		approved, _ := s.consents.AskBatch(context.Background(), []*v1.SubmitTransactionRequest{{}, {}}, batchID, time.Now())
+		decisions <- approved
+	}()
+	waitForPendingConsents(t, s, 1)
+
+	// when
+	statusCode, _ := serveAdminHTTP(t, s, http.MethodPost, "/admin/consents/"+batchID, `{"approved": true}`, s.token)
+
+	// then
+	require.Equal(t, http.StatusOK, statusCode)
+	assert.True(t, <-decisions)
+	assert.Empty(t, s.consents.ListPendingConsents().Consents)
+}
+
+func testAdminServiceRejectingPendingConnectionSucceeds(t *testing.T) {
+	s := getTestAdminService(t)
+
+	// given
+	origin := "https://" + vgrand.RandomStr(5) + ".example.com"
+	decisions := make(chan bool, 1)
+
+	// setup
+	go func() {
+		approved, _ := s.consents.AskConnection(context.Background(), origin, time.Now())
+		decisions <- approved
+	}()
+	waitForPendingConsents(t, s, 1)
+	pending := s.consents.ListPendingConsents().Consents[0]
+	assert.Equal(t, service.PendingConnectionConsent, pending.Kind)
+	assert.Equal(t, origin, pending.Origin)
+
+	// when
+	statusCode, _ := serveAdminHTTP(t, s, http.MethodPost, "/admin/consents/"+pending.ID, `{"approved": false}`, s.token)
+
+	// then
+	require.Equal(t, http.StatusOK, statusCode)
+	assert.False(t, <-decisions)
+}
+
+func testAdminServiceDecidingUnknownConsentFails(t *testing.T) {
+	s := getTestAdminService(t)
+
+	// when
+	statusCode, body := serveAdminHTTP(t, s, http.MethodPost, "/admin/consents/"+vgrand.RandomStr(5), `{"approved": true}`, s.token)
+
+	// then
+	require.Equal(t, http.StatusNotFound, statusCode)
+	assert.Contains(t, string(body), service.ErrPendingConsentNotFound.Error())
+}
+
+func testAdminServiceDecidingWithoutDecisionFails(t *testing.T) {
+	s := getTestAdminService(t)
+
+	// when
+	statusCode, _ := serveAdminHTTP(t, s, http.MethodPost, "/admin/consents/"+vgrand.RandomStr(5), `{}`, s.token)
+
+	// then
+	require.Equal(t, http.StatusBadRequest, statusCode)
+}
+
+func testAdminServiceRequestingWithInvalidTokenFails(t *testing.T) {
+	s := getTestAdminService(t)
+
+	tcs := []struct {
+		name  string
+		token string
+	}{
+		{
+			name:  "with a random token",
+			token: vgrand.RandomStr(10),
+		}, {
+			name:  "with a wrong admin token",
+			token: service.AdminTokenPrefix + vgrand.RandomStr(64),
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			// when
+			statusCode, _ := serveAdminHTTP(tt, s, http.MethodGet, "/admin/consents", "", tc.token)
+
+			// then
+			assert.Equal(tt, http.StatusUnauthorized, statusCode)
+		})
+	}
+}
+
+func testAdminServiceInterruptingPendingConsents(t *testing.T) {
+	s := getTestAdminService(t)
+
+	// given
+	errs := make(chan error, 1)
+
+	// setup
+	go func() {
+		_, err := s.consents.Ask(context.Background(), &v1.SubmitTransactionRequest{}, vgrand.RandomStr(5), time.Now())
+		errs <- err
+	}()
+	waitForPendingConsents(t, s, 1)
+
+	// when
+	s.cancel()
+
+	// then
+	assert.ErrorIs(t, <-errs, service.ErrInterruptedConsentRequest)
+	assert.Empty(t, s.consents.ListPendingConsents().Consents)
+}
+
+func testAdminServiceDisconnectingClientWithdrawsPendingConsent(t *testing.T) {
+	s := getTestAdminService(t)
+
+	// given
+	ctx, cancelRequest := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+
+	// setup
+	go func() {
+		_, err := s.consents.Ask(ctx, &v1.SubmitTransactionRequest{}, vgrand.RandomStr(5), time.Now())
+		errs <- err
+	}()
+	waitForPendingConsents(t, s, 1)
+
+	// when
+	cancelRequest()
+
+	// then
+	assert.ErrorIs(t, <-errs, service.ErrInterruptedConsentRequest)
+	assert.Empty(t, s.consents.ListPendingConsents().Consents)
+}
+
+func testAdminServiceQueuingTooManyPendingConsentsFails(t *testing.T) {
+	s := getTestAdminService(t)
+
+	// setup
+	for i := 0; i < service.MaxPendingConsents; i++ {
+		go func() {
+			_, _ = s.consents.Ask(context.Background(), &v1.SubmitTransactionRequest{}, vgrand.RandomStr(5), time.Now())
+		}()
+	}
+	waitForPendingConsents(t, s, service.MaxPendingConsents)
+
+	// when
+	approved, err := s.consents.Ask(context.Background(), &v1.SubmitTransactionRequest{}, vgrand.RandomStr(5), time.Now())
+
+	// then
+	assert.ErrorIs(t, err, service.ErrTooManyPendingConsents)
+	assert.False(t, approved)
+	assert.Len(t, s.consents.ListPendingConsents().Consents, service.MaxPendingConsents)
+}
+
+func waitForPendingConsents(t *testing.T, s *testAdminService, count int) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		return len(s.consents.ListPendingConsents().Consents) == count
+	}, time.Second, time.Millisecond)
+}
+
+func serveAdminHTTP(t *testing.T, s *testAdminService, method, path, payload, token string) (int, []byte) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, strings.NewReader(payload))
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	w := httptest.NewRecorder()
+
+	s.ServeHTTP(w, req)
+
+	return w.Code, w.Body.Bytes()
+}
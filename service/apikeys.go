@@ -0,0 +1,229 @@
+package service
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	vgcrypto "code.vegaprotocol.io/shared/libs/crypto"
+	vgrand "code.vegaprotocol.io/shared/libs/rand"
+)
+
+const (
+	// APIKeyPrefix distinguishes the API keys from the JWT tokens, in the
+	// Authorization header.
+	APIKeyPrefix = "vwk_"
+
+	apiKeyIDLength     = 8
+	apiKeySecretLength = 32
+)
+
+// APIKey is a long-lived credential, meant for headless clients like trading
+// bots. Only the hash of its secret is stored.
+type APIKey struct {
+	ID          string    `json:"id"`
+	Wallet      string    `json:"wallet"`
+	Description string    `json:"description,omitempty"`
+	Scopes      *Scopes   `json:"scopes,omitempty"`
+	SecretHash  string    `json:"secretHash"`
+	CreatedAt   time.Time `json:"createdAt"`
+	// PassphraseFile is the path to the file containing the passphrase of the
+	// wallet. It's used to unlock the wallet when the service starts.
+	PassphraseFile string `json:"passphraseFile"`
+}
+
+// APIKeyStore persists the API keys.
+type APIKeyStore interface {
+	GetAPIKeys() ([]APIKey, error)
+	SaveAPIKeys([]APIKey) error
+}
+
+// IsAPIKey verifies the token, from the Authorization header, is an API key.
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
+
+// APIKeyID returns the ID of the API key, without verifying its secret.
+func APIKeyID(token string) (string, error) {
+	id, _, err := splitAPIKey(token)
+	return id, err
+}
+
+// VerifyAPIKey returns the stored API key matching the token.
+func VerifyAPIKey(store APIKeyStore, token string) (*APIKey, error) {
+	id, secret, err := splitAPIKey(token)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := store.GetAPIKeys()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get the API keys: %w", err)
+	}
+
+	for _, key := range keys {
+		if key.ID != id {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(key.SecretHash), []byte(hashAPIKeySecret(secret))) != 1 {
+			return nil, ErrInvalidAPIKey
+		}
+		return &key, nil
+	}
+
+	return nil, ErrAPIKeyNotFound
+}
+
+// CreateAPIKeyRequest describes the request for CreateAPIKey.
+type CreateAPIKeyRequest struct {
+	Wallet         string
+	Description    string
+	Scopes         *Scopes
+	PassphraseFile string
+	// TwoFactorCode is verified when the wallet is unlocked, before the
+	// creation. It's not saved.
+	TwoFactorCode string
+}
+
+type CreateAPIKeyResponse struct {
+	// Key is the API key to use in the Authorization header. It's only
+	// returned at creation.
+	Key    string        `json:"key"`
+	APIKey APIKeySummary `json:"apiKey"`
+}
+
+// CreateAPIKey generates a new API key, and saves it in the store.
+func CreateAPIKey(store APIKeyStore, req *CreateAPIKeyRequest) (*CreateAPIKeyResponse, error) {
+	keys, err := store.GetAPIKeys()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get the API keys: %w", err)
+	}
+
+	id := hex.EncodeToString(vgrand.RandomBytes(apiKeyIDLength))
+	secret := hex.EncodeToString(vgrand.RandomBytes(apiKeySecretLength))
+
+	key := APIKey{
+		ID:             id,
+		Wallet:         req.Wallet,
+		Description:    req.Description,
+		Scopes:         req.Scopes,
+		SecretHash:     hashAPIKeySecret(secret),
+		CreatedAt:      time.Now(),
+		PassphraseFile: req.PassphraseFile,
+	}
+
+	if err := store.SaveAPIKeys(append(keys, key)); err != nil {
+		return nil, fmt.Errorf("couldn't save the API keys: %w", err)
+	}
+
+	return &CreateAPIKeyResponse{
+		Key:    fmt.Sprintf("%s%s_%s", APIKeyPrefix, id, secret),
+		APIKey: summarizeAPIKey(key),
+	}, nil
+}
+
+// APIKeySummary describes an API key, without its secret.
+type APIKeySummary struct {
+	ID             string    `json:"id"`
+	Wallet         string    `json:"wallet"`
+	Description    string    `json:"description,omitempty"`
+	Scopes         *Scopes   `json:"scopes,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+	PassphraseFile string    `json:"passphraseFile"`
+}
+
+func summarizeAPIKey(key APIKey) APIKeySummary {
+	return APIKeySummary{
+		ID:             key.ID,
+		Wallet:         key.Wallet,
+		Description:    key.Description,
+		Scopes:         key.Scopes,
+		CreatedAt:      key.CreatedAt,
+		PassphraseFile: key.PassphraseFile,
+	}
+}
+
+type ListAPIKeysResponse struct {
+	APIKeys []APIKeySummary `json:"apiKeys"`
+}
+
+// ListAPIKeys returns the API keys, without their secrets.
+func ListAPIKeys(store APIKeyStore) (*ListAPIKeysResponse, error) {
+	keys, err := store.GetAPIKeys()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get the API keys: %w", err)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	resp := &ListAPIKeysResponse{
+		APIKeys: []APIKeySummary{},
+	}
+	for _, key := range keys {
+		resp.APIKeys = append(resp.APIKeys, summarizeAPIKey(key))
+	}
+
+	return resp, nil
+}
+
+// RevokeAPIKeyRequest describes the request for RevokeAPIKey.
+type RevokeAPIKeyRequest struct {
+	ID string
+}
+
+type RevokeAPIKeyResponse struct {
+	Revoked APIKeySummary `json:"revoked"`
+}
+
+// RevokeAPIKey removes the API key from the store. The key can't be used
+// anymore, even by a running service.
+func RevokeAPIKey(store APIKeyStore, req *RevokeAPIKeyRequest) (*RevokeAPIKeyResponse, error) {
+	keys, err := store.GetAPIKeys()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get the API keys: %w", err)
+	}
+
+	var revoked *APIKey
+	kept := []APIKey{}
+	for i, key := range keys {
+		if key.ID == req.ID {
+			revoked = &keys[i]
+			continue
+		}
+		kept = append(kept, key)
+	}
+
+	if revoked == nil {
+		return nil, ErrAPIKeyNotFound
+	}
+
+	if err := store.SaveAPIKeys(kept); err != nil {
+		return nil, fmt.Errorf("couldn't save the API keys: %w", err)
+	}
+
+	return &RevokeAPIKeyResponse{
+		Revoked: summarizeAPIKey(*revoked),
+	}, nil
+}
+
+func splitAPIKey(token string) (string, string, error) {
+	if !IsAPIKey(token) {
+		return "", "", ErrInvalidAPIKey
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(token, APIKeyPrefix), "_", 2)
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return "", "", ErrInvalidAPIKey
+	}
+
+	return parts[0], parts[1], nil
+}
+
+func hashAPIKeySecret(secret string) string {
+	return hex.EncodeToString(vgcrypto.Hash([]byte(secret)))
+}
@@ -0,0 +1,110 @@
+package service_test
+
+import (
+	"strings"
+	"testing"
+
+	"code.vegaprotocol.io/vegawallet/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryAPIKeyStore struct {
+	keys []service.APIKey
+}
+
+func (s *memoryAPIKeyStore) GetAPIKeys() ([]service.APIKey, error) {
+	keys := make([]service.APIKey, len(s.keys))
+	copy(keys, s.keys)
+	return keys, nil
+}
+
+func (s *memoryAPIKeyStore) SaveAPIKeys(keys []service.APIKey) error {
+	s.keys = keys
+	return nil
+}
+
+func TestAPIKeys(t *testing.T) {
+	t.Run("Creating an API key succeeds", testCreatingAPIKeySucceeds)
+	t.Run("Verifying an API key with wrong secret fails", testVerifyingAPIKeyWithWrongSecretFails)
+	t.Run("Revoking an API key succeeds", testRevokingAPIKeySucceeds)
+	t.Run("Revoking an unknown API key fails", testRevokingUnknownAPIKeyFails)
+}
+
+func testCreatingAPIKeySucceeds(t *testing.T) {
+	// given
+	store := &memoryAPIKeyStore{}
+	req := &service.CreateAPIKeyRequest{
+		Wallet:         "jeremy",
+		Description:    "my bot",
+		Scopes:         &service.Scopes{Commands: []string{"orderSubmission"}},
+		PassphraseFile: "/path/to/passphrase",
+	}
+
+	// when
+	resp, err := service.CreateAPIKey(store, req)
+
+	// then
+	require.NoError(t, err)
+	assert.True(t, service.IsAPIKey(resp.Key))
+	assert.Equal(t, "jeremy", resp.APIKey.Wallet)
+	require.Len(t, store.keys, 1)
+	assert.NotContains(t, resp.Key, store.keys[0].SecretHash)
+
+	// when
+	key, err := service.VerifyAPIKey(store, resp.Key)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, "jeremy", key.Wallet)
+	assert.Equal(t, req.Scopes, key.Scopes)
+	assert.Equal(t, req.PassphraseFile, key.PassphraseFile)
+}
+
+func testVerifyingAPIKeyWithWrongSecretFails(t *testing.T) {
+	// given
+	store := &memoryAPIKeyStore{}
+	resp, err := service.CreateAPIKey(store, &service.CreateAPIKeyRequest{Wallet: "jeremy"})
+	require.NoError(t, err)
+	forged := resp.Key[:strings.LastIndex(resp.Key, "_")+1] + "deadbeef"
+
+	// when
+	key, err := service.VerifyAPIKey(store, forged)
+
+	// then
+	assert.ErrorIs(t, err, service.ErrInvalidAPIKey)
+	assert.Nil(t, key)
+}
+
+func testRevokingAPIKeySucceeds(t *testing.T) {
+	// given
+	store := &memoryAPIKeyStore{}
+	resp, err := service.CreateAPIKey(store, &service.CreateAPIKeyRequest{Wallet: "jeremy"})
+	require.NoError(t, err)
+
+	// when
+	revokeResp, err := service.RevokeAPIKey(store, &service.RevokeAPIKeyRequest{ID: resp.APIKey.ID})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, resp.APIKey.ID, revokeResp.Revoked.ID)
+
+	// when
+	key, err := service.VerifyAPIKey(store, resp.Key)
+
+	// then
+	assert.ErrorIs(t, err, service.ErrAPIKeyNotFound)
+	assert.Nil(t, key)
+}
+
+func testRevokingUnknownAPIKeyFails(t *testing.T) {
+	// given
+	store := &memoryAPIKeyStore{}
+
+	// when
+	resp, err := service.RevokeAPIKey(store, &service.RevokeAPIKeyRequest{ID: "unknown"})
+
+	// then
+	assert.ErrorIs(t, err, service.ErrAPIKeyNotFound)
+	assert.Nil(t, resp)
+}
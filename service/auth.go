@@ -1,12 +1,13 @@
 package service
 
 import (
-	"crypto/rsa"
+	"crypto"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -32,14 +33,37 @@ type RSAStore interface {
 	GetRsaKeys() (*RSAKeys, error)
 }
 
+// WalletKeeper keeps the wallets unlocked while sessions use them. Each session
+// retains its wallet under its own handle, so ending one session doesn't lock
+// the wallet for the other ones.
+type WalletKeeper interface {
+	RetainWallet(handle, name string) error
+	ReleaseWallet(handle string)
+}
+
 type auth struct {
 	log *zap.Logger
-	// sessionID -> wallet name
-	sessions    map[string]string
-	privKey     *rsa.PrivateKey
-	pubKey      *rsa.PublicKey
+	// sessionID -> session
+	sessions    map[string]Session
 	tokenExpiry time.Duration
 
+	// privKey signs the new tokens, and currentKey verifies them.
+	privKey    crypto.Signer
+	currentKey verificationKey
+	// previousKeys verify the tokens signed before the last rotations, until
+	// the end of their grace period. The oldest key comes first.
+	previousKeys []verificationKey
+
+	// store persists the sessions, if set.
+	store SessionStore
+	// passphrases returns the passphrases to persist alongside the sessions.
+	// If nil, the passphrases are not persisted.
+	passphrases PassphraseGetter
+	// apiKeys holds the API keys accepted in place of the tokens, if set.
+	apiKeys APIKeyStore
+	// wallets is notified when sessions start and end, if set.
+	wallets WalletKeeper
+
 	mu sync.Mutex
 }
 
@@ -48,19 +72,15 @@ func NewAuth(log *zap.Logger, cfgStore RSAStore, tokenExpiry time.Duration) (*au
 	if err != nil {
 		return nil, err
 	}
-	priv, err := jwt.ParseRSAPrivateKeyFromPEM(keys.Priv)
+	current, priv, err := parseSigningKeys(keys)
 	if err != nil {
-		return nil, fmt.Errorf("couldn't parse private RSA key: %w", err)
-	}
-	pub, err := jwt.ParseRSAPublicKeyFromPEM(keys.Pub)
-	if err != nil {
-		return nil, fmt.Errorf("couldn't parse public RSA key: %w", err)
+		return nil, fmt.Errorf("couldn't parse the signing keys: %w", err)
 	}
 
 	return &auth{
-		sessions:    map[string]string{},
+		sessions:    map[string]Session{},
 		privKey:     priv,
-		pubKey:      pub,
+		currentKey:  current,
 		log:         log,
 		tokenExpiry: tokenExpiry,
 	}, nil
@@ -70,19 +90,73 @@ type Claims struct {
 	jwt.StandardClaims
 	Session string
 	Wallet  string
+	// Wallets is only set when the session gives access to several wallets.
+	Wallets []string `json:",omitempty"`
+	Origin  string   `json:",omitempty"`
+	Scopes  *Scopes  `json:",omitempty"`
+}
+
+// NewSession creates a session whose token can only be used from the
+// specified origin. An empty origin is used by clients that are not web pages.
+func (a *auth) NewSession(name, origin string) (string, error) {
+	return a.NewScopedSession(name, origin, nil)
+}
+
+// NewScopedSession creates a session whose token is restricted by the
+// specified scopes. If nil, the token has full access to the wallet.
+func (a *auth) NewScopedSession(name, origin string, scopes *Scopes) (string, error) {
+	return a.NewMultiWalletSession([]string{name}, origin, scopes)
 }
 
-func (a *auth) NewSession(name string) (string, error) {
+// NewMultiWalletSession creates a session whose token gives access to several
+// wallets. The first wallet is the default one, used when a request doesn't
+// specify the wallet. The scopes apply to all the wallets.
+func (a *auth) NewMultiWalletSession(names []string, origin string, scopes *Scopes) (string, error) {
+	if len(names) == 0 {
+		return "", ErrNoWalletSpecified
+	}
+
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	ss, err := a.createSession(names, origin, scopes)
+	if err != nil {
+		return "", err
+	}
+	a.saveSessions()
+
+	return ss, nil
+}
+
+// createSession creates the session and returns its token. It doesn't persist
+// the sessions, and the caller has to hold the lock.
+func (a *auth) createSession(names []string, origin string, scopes *Scopes) (string, error) {
+	a.removeExpiredSessions(time.Now())
+
 	expiresAt := time.Now().Add(a.tokenExpiry)
 
-	session := genSession()
+	session := Session{
+		ID:        genSession(),
+		Wallet:    names[0],
+		CreatedAt: time.Now(),
+		ExpiresAt: expiresAt,
+		Origin:    origin,
+		Scopes:    scopes,
+	}
+	if len(names) > 1 {
+		session.Wallets = names
+	}
+
+	if err := a.retainWallets(session); err != nil {
+		return "", err
+	}
 
 	claims := &Claims{
-		Session: session,
-		Wallet:  name,
+		Session: session.ID,
+		Wallet:  session.Wallet,
+		Wallets: session.Wallets,
+		Origin:  origin,
+		Scopes:  scopes,
 		StandardClaims: jwt.StandardClaims{
 			// these are seconds
 			ExpiresAt: jwt.NewTime((float64)(expiresAt.Unix())),
@@ -90,36 +164,78 @@ func (a *auth) NewSession(name string) (string, error) {
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodPS256, claims)
+	token := jwt.NewWithClaims(a.currentKey.method, claims)
+	token.Header["kid"] = a.currentKey.id
 	ss, err := token.SignedString(a.privKey)
 	if err != nil {
 		a.log.Error("unable to sign token", zap.Error(err))
+		a.releaseWallets(session)
 		return "", err
 	}
 
-	a.sessions[session] = name
+	a.sessions[session.ID] = session
+
 	return ss, nil
 }
 
-// VerifyToken returns the wallet name associated for this session.
-func (a *auth) VerifyToken(token string) (string, error) {
+// VerifyToken returns the default wallet of the session. The token must be
+// used from the origin that obtained it.
+func (a *auth) VerifyToken(token, origin string) (string, error) {
+	names, _, err := a.VerifyScopedToken(token, origin)
+	if err != nil {
+		return "", err
+	}
+	return names[0], nil
+}
+
+// VerifyScopedToken returns the wallets the session gives access to, the
+// default one first, and the scopes restricting the token. Nil scopes mean
+// full access.
+func (a *auth) VerifyScopedToken(token, origin string) ([]string, *Scopes, error) {
+	if IsAPIKey(token) {
+		return a.verifyAPIKey(token, origin)
+	}
+
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	session, err := a.verifySession(token, origin)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return session.WalletNames(), session.Scopes, nil
+}
+
+// verifySession returns the session of the token. The token must be used from
+// the origin that obtained it.
+func (a *auth) verifySession(token, origin string) (*Session, error) {
+	a.removeExpiredSessions(time.Now())
+
 	claims, err := a.parseToken(token)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	w, ok := a.sessions[claims.Session]
+	session, ok := a.sessions[claims.Session]
 	if !ok {
-		return "", ErrSessionNotFound
+		return nil, ErrSessionNotFound
+	}
+
+	if session.Origin != origin {
+		return nil, ErrTokenOriginMismatch
 	}
 
-	return w, nil
+	return &session, nil
 }
 
-func (a *auth) Revoke(token string) (string, error) {
+// Revoke ends the session of the token. As any other use of the token, it
+// must come from the origin that obtained it.
+func (a *auth) Revoke(token, origin string) (string, error) {
+	if IsAPIKey(token) {
+		return "", ErrAPIKeyCannotBeRevoked
+	}
+
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
@@ -128,18 +244,326 @@ func (a *auth) Revoke(token string) (string, error) {
 		return "", err
 	}
 
-	w, ok := a.sessions[claims.Session]
+	session, ok := a.sessions[claims.Session]
 	if !ok {
 		return "", ErrSessionNotFound
 	}
-	delete(a.sessions, claims.Session)
-	return w, nil
+
+	if session.Origin != origin {
+		return "", ErrTokenOriginMismatch
+	}
+
+	a.endSession(claims.Session)
+	a.saveSessions()
+
+	return session.Wallet, nil
 }
 
-func (a *auth) parseToken(tokenStr string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(t *jwt.Token) (interface{}, error) {
-		return a.pubKey, nil
+// IntrospectToken returns the information about the token: the wallets it
+// gives access to, its expiry, and its scopes.
+func (a *auth) IntrospectToken(token, origin string) (*TokenInfoResponse, error) {
+	if IsAPIKey(token) {
+		names, scopes, err := a.verifyAPIKey(token, origin)
+		if err != nil {
+			return nil, err
+		}
+		id, _ := APIKeyID(token)
+		return &TokenInfoResponse{
+			Wallet:  names[0],
+			Wallets: names,
+			APIKey:  id,
+			Scopes:  scopes,
+		}, nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	session, err := a.verifySession(token, origin)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := session.ExpiresAt
+	return &TokenInfoResponse{
+		Wallet:    session.Wallet,
+		Wallets:   session.WalletNames(),
+		Session:   session.ID,
+		ExpiresAt: &expiresAt,
+		Scopes:    session.Scopes,
+	}, nil
+}
+
+// RefreshToken replaces the session of the token by a new one, with a renewed
+// expiry, and returns the new token. The previous token can't be used anymore.
+func (a *auth) RefreshToken(token, origin string) (string, error) {
+	if IsAPIKey(token) {
+		return "", ErrAPIKeyCannotBeRefreshed
+	}
+
+	// The session is verified, replaced and ended under the same lock, so
+	// concurrent refreshes of a token can't both succeed.
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	session, err := a.verifySession(token, origin)
+	if err != nil {
+		return "", err
+	}
+
+	// The new session retains the wallets before the previous one releases
+	// them, so the wallets are never locked in between.
+	refreshed, err := a.createSession(session.WalletNames(), session.Origin, session.Scopes)
+	if err != nil {
+		return "", err
+	}
+
+	a.endSession(session.ID)
+	a.saveSessions()
+
+	return refreshed, nil
+}
+
+// ListWalletSessions returns the active sessions sharing a wallet with the
+// token.
+func (a *auth) ListWalletSessions(token, origin string) ([]SessionSummary, error) {
+	names, _, err := a.VerifyScopedToken(token, origin)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	sessions := []Session{}
+	for _, session := range a.sessions {
+		if session.HasWallet(names...) {
+			sessions = append(sessions, session)
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+
+	summaries := []SessionSummary{}
+	for _, session := range RemoveExpiredSessions(sessions, time.Now()) {
+		summaries = append(summaries, summarizeSession(session))
+	}
+
+	return summaries, nil
+}
+
+// CountActiveSessions returns the number of sessions whose token hasn't
+// expired.
+func (a *auth) CountActiveSessions() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	count := 0
+	for _, session := range a.sessions {
+		if !session.IsExpired(now) {
+			count++
+		}
+	}
+	return count
+}
+
+// UseAPIKeys enables the authentication with the API keys from the store. The
+// store is read on every verification, so a revoked key is refused right away.
+func (a *auth) UseAPIKeys(store APIKeyStore) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.apiKeys = store
+}
+
+// UsePreviousSigningKeys makes the tokens signed by the keys used before the
+// last rotations valid until the end of their grace period.
+func (a *auth) UsePreviousSigningKeys(keys []PreviousSigningKey) error {
+	previousKeys, err := parsePreviousSigningKeys(keys)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.previousKeys = previousKeys
+	return nil
+}
+
+// UseWalletKeeper makes the sessions retain their wallets, so a wallet stays
+// unlocked until its last session ends. The current sessions retain their
+// wallets right away. Those with a wallet that isn't unlocked are revoked.
+func (a *auth) UseWalletKeeper(keeper WalletKeeper) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.wallets = keeper
+
+	for id, session := range a.sessions {
+		if err := a.retainWallets(session); err != nil {
+			a.log.Warn("couldn't retain the wallets of the session, the session has been revoked",
+				zap.Strings("wallets", session.WalletNames()),
+				zap.Error(err),
+			)
+			delete(a.sessions, id)
+		}
+	}
+	a.saveSessions()
+}
+
+// verifyAPIKey returns the wallet and the scopes of the API key. API keys are
+// meant for headless clients, so they can't be used from a web page.
+func (a *auth) verifyAPIKey(token, origin string) ([]string, *Scopes, error) {
+	a.mu.Lock()
+	store := a.apiKeys
+	a.mu.Unlock()
+
+	if store == nil {
+		return nil, nil, ErrAPIKeysAreNotEnabled
+	}
+
+	if len(origin) != 0 {
+		return nil, nil, ErrTokenOriginMismatch
+	}
+
+	key, err := VerifyAPIKey(store, token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return []string{key.Wallet}, key.Scopes, nil
+}
+
+// PersistSessions restores the sessions, whose tokens haven't expired, from
+// the store, and persists the upcoming changes to it. If passphrases is set,
+// the passphrases of the wallets are persisted alongside the sessions, so the
+// wallets can be unlocked when the sessions are restored.
+func (a *auth) PersistSessions(store SessionStore, passphrases PassphraseGetter) ([]Session, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	stored, err := store.GetSessions()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get the stored sessions: %w", err)
+	}
+
+	restored := RemoveExpiredSessions(stored, time.Now())
+	for _, session := range restored {
+		a.sessions[session.ID] = session
+	}
+
+	a.store = store
+	a.passphrases = passphrases
+	a.saveSessions()
+
+	return restored, nil
+}
+
+// RevokeWalletSessions revokes all the sessions giving access to the specified
+// wallet.
+func (a *auth) RevokeWalletSessions(wallet string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for id, session := range a.sessions {
+		if session.HasWallet(wallet) {
+			a.endSession(id)
+		}
+	}
+	a.saveSessions()
+}
+
+// endSession removes the session, and releases its wallets.
+func (a *auth) endSession(id string) {
+	session, ok := a.sessions[id]
+	if !ok {
+		return
+	}
+	delete(a.sessions, id)
+	a.releaseWallets(session)
+}
+
+// retainWallets retains all the wallets of the session, or none.
+func (a *auth) retainWallets(session Session) error {
+	if a.wallets == nil {
+		return nil
+	}
+
+	retained := []string{}
+	for _, name := range session.WalletNames() {
+		handle := walletHandle(session.ID, name)
+		if err := a.wallets.RetainWallet(handle, name); err != nil {
+			for _, h := range retained {
+				a.wallets.ReleaseWallet(h)
+			}
+			return fmt.Errorf("couldn't retain the wallet %s: %w", name, err)
+		}
+		retained = append(retained, handle)
+	}
+
+	return nil
+}
+
+func (a *auth) releaseWallets(session Session) {
+	if a.wallets == nil {
+		return
+	}
+
+	for _, name := range session.WalletNames() {
+		a.wallets.ReleaseWallet(walletHandle(session.ID, name))
+	}
+}
+
+// walletHandle returns the handle under which the session retains the wallet.
+func walletHandle(session, wallet string) string {
+	return session + "/" + wallet
+}
+
+// removeExpiredSessions ends the sessions whose token has expired, so their
+// wallet is released as soon as possible.
+func (a *auth) removeExpiredSessions(now time.Time) {
+	for id, session := range a.sessions {
+		if session.IsExpired(now) {
+			a.endSession(id)
+		}
+	}
+}
+
+// saveSessions persists the sessions, if a store is set. A failure doesn't
+// prevent the service from working, so it's only logged.
+func (a *auth) saveSessions() {
+	if a.store == nil {
+		return
+	}
+
+	a.removeExpiredSessions(time.Now())
+
+	sessions := make([]Session, 0, len(a.sessions))
+	for _, session := range a.sessions {
+		if a.passphrases == nil {
+			session.Passphrase = ""
+			session.Passphrases = nil
+		} else {
+			session.SetPassphrases(a.passphrases)
+		}
+		sessions = append(sessions, session)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
 	})
+
+	if err := a.store.SaveSessions(sessions); err != nil {
+		a.log.Error("couldn't persist the sessions", zap.Error(err))
+	}
+}
+
+func (a *auth) parseToken(tokenStr string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, a.verificationKeyFor)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't parse JWT token: %w", err)
 	}
@@ -152,6 +576,44 @@ func (a *auth) parseToken(tokenStr string) (*Claims, error) {
 	return nil, ErrInvalidClaims
 }
 
+// verificationKeyFor returns the public key of the key that signed the token,
+// found from the "kid" header. The key must be used with its own algorithm.
+func (a *auth) verificationKeyFor(token *jwt.Token) (interface{}, error) {
+	id, _ := token.Header["kid"].(string)
+
+	key, ok := a.findVerificationKey(id, time.Now())
+	if !ok || key.method.Alg() != token.Method.Alg() {
+		return nil, ErrUnknownSigningKey
+	}
+
+	return key.pub, nil
+}
+
+func (a *auth) findVerificationKey(id string, now time.Time) (verificationKey, bool) {
+	if len(id) == 0 {
+		// The tokens without key ID have been issued before the keys could be
+		// rotated, so they have been signed by the oldest key.
+		for _, key := range a.previousKeys {
+			if now.Before(key.expiresAt) {
+				return key, true
+			}
+		}
+		return a.currentKey, true
+	}
+
+	if id == a.currentKey.id {
+		return a.currentKey, true
+	}
+
+	for _, key := range a.previousKeys {
+		if key.id == id && now.Before(key.expiresAt) {
+			return key, true
+		}
+	}
+
+	return verificationKey{}, false
+}
+
 func extractToken(f func(string, http.ResponseWriter, *http.Request, httprouter.Params)) httprouter.Handle {
 	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 		token := strings.TrimSpace(r.Header.Get("Authorization"))
@@ -163,6 +625,23 @@ func extractToken(f func(string, http.ResponseWriter, *http.Request, httprouter.
 	}
 }
 
+// extractAPIKeyID returns the ID of the API key used by the request, if any.
+func extractAPIKeyID(r *http.Request) (string, bool) {
+	token := strings.TrimSpace(r.Header.Get("Authorization"))
+	if !strings.HasPrefix(token, jwtBearer) {
+		return "", false
+	}
+	token = strings.TrimSpace(token[len(jwtBearer):])
+	if !IsAPIKey(token) {
+		return "", false
+	}
+	id, err := APIKeyID(token)
+	if err != nil {
+		return "", false
+	}
+	return id, true
+}
+
 func genSession() string {
 	return hex.EncodeToString(vgcrypto.Hash(vgrand.RandomBytes(LengthForSessionHashSeed)))
 }
@@ -1,19 +1,33 @@
 package service_test
 
 import (
+	"sync"
 	"testing"
 	"time"
 
 	"code.vegaprotocol.io/vegawallet/service"
 	"code.vegaprotocol.io/vegawallet/service/mocks"
+	"code.vegaprotocol.io/vegawallet/wallet"
+	"github.com/dgrijalva/jwt-go/v4"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
-type testAuth struct {
+type persistentAuth interface {
 	service.Auth
+	PersistSessions(service.SessionStore, service.PassphraseGetter) ([]service.Session, error)
+	RevokeWalletSessions(wallet string)
+	UseAPIKeys(service.APIKeyStore)
+	UseWalletKeeper(service.WalletKeeper)
+	UsePreviousSigningKeys([]service.PreviousSigningKey) error
+}
+
+type testAuth struct {
+	persistentAuth
 	ctrl *gomock.Controller
+	keys *service.RSAKeys
 }
 
 func getTestAuth(t *testing.T) *testAuth {
@@ -23,6 +37,12 @@ func getTestAuth(t *testing.T) *testAuth {
 		t.Fatal(err)
 	}
 
+	return getTestAuthWithKeys(t, rsaKeys)
+}
+
+func getTestAuthWithKeys(t *testing.T, rsaKeys *service.RSAKeys) *testAuth {
+	t.Helper()
+
 	ctrl := gomock.NewController(t)
 	store := mocks.NewMockRSAStore(ctrl)
 	store.EXPECT().GetRsaKeys().Return(rsaKeys, nil)
@@ -34,16 +54,39 @@ func getTestAuth(t *testing.T) *testAuth {
 	}
 
 	return &testAuth{
-		Auth: a,
-		ctrl: ctrl,
+		persistentAuth: a,
+		ctrl:           ctrl,
+		keys:           rsaKeys,
 	}
 }
 
 func TestAuth(t *testing.T) {
 	t.Run("verify a valid token", testVerifyValidToken)
 	t.Run("verify an invalid token fail", testVerifyInvalidToken)
+	t.Run("verify a scoped token", testVerifyScopedToken)
+	t.Run("verify a token from another origin fails", testVerifyTokenFromAnotherOriginFails)
+	t.Run("verify an API key", testVerifyAPIKey)
+	t.Run("introspect a token", testIntrospectToken)
+	t.Run("refresh a token", testRefreshToken)
+	t.Run("list the sessions of a wallet", testListWalletSessions)
 	t.Run("revoke a valid token", testRevokeValidToken)
 	t.Run("revoke an invalid token fail", testRevokeInvalidToken)
+	t.Run("revoke a token from another origin fails", testRevokeTokenFromAnotherOriginFails)
+	t.Run("persisting sessions restores the unexpired ones", testPersistingSessionsRestoresUnexpiredOnes)
+	t.Run("new sessions are persisted", testNewSessionsArePersisted)
+	t.Run("revoked sessions are removed from the store", testRevokedSessionsAreRemovedFromStore)
+	t.Run("revoking wallet sessions succeeds", testRevokingWalletSessionsSucceeds)
+	t.Run("sessions retain their wallet under their own handle", testSessionsRetainTheirWalletUnderTheirOwnHandle)
+	t.Run("refreshing a token retains the wallet before releasing it", testRefreshingTokenRetainsWalletBeforeReleasingIt)
+	t.Run("refreshing a token concurrently succeeds only once", testRefreshingTokenConcurrentlySucceedsOnlyOnce)
+	t.Run("using a wallet keeper revokes the sessions of locked wallets", testUsingWalletKeeperRevokesSessionsOfLockedWallets)
+	t.Run("multi-wallet sessions retain all their wallets", testMultiWalletSessionsRetainAllTheirWallets)
+	t.Run("multi-wallet sessions fail if a wallet is not logged", testMultiWalletSessionsFailIfWalletIsNotLogged)
+	t.Run("tokens are signed with the key ID", testTokensAreSignedWithKeyID)
+	t.Run("tokens can be signed with EdDSA", testTokensCanBeSignedWithEdDSA)
+	t.Run("tokens of a previous key are verified during the grace period", testTokensOfPreviousKeyAreVerifiedDuringGracePeriod)
+	t.Run("tokens of a previous key are refused after the grace period", testTokensOfPreviousKeyAreRefusedAfterGracePeriod)
+	t.Run("tokens without key ID are verified with the oldest key", testTokensWithoutKeyIDAreVerifiedWithOldestKey)
 }
 
 func testVerifyValidToken(t *testing.T) {
@@ -51,11 +94,11 @@ func testVerifyValidToken(t *testing.T) {
 	w := "jeremy"
 
 	// get a new session
-	tok, err := auth.NewSession(w)
+	tok, err := auth.NewSession(w, "")
 	assert.NoError(t, err)
 	assert.NotEmpty(t, tok)
 
-	wallet2, err := auth.VerifyToken(tok)
+	wallet2, err := auth.VerifyToken(tok, "")
 	assert.NoError(t, err)
 	assert.Equal(t, w, wallet2)
 }
@@ -64,30 +107,163 @@ func testVerifyInvalidToken(t *testing.T) {
 	auth := getTestAuth(t)
 	tok := "that's not a token"
 
-	w, err := auth.VerifyToken(tok)
+	w, err := auth.VerifyToken(tok, "")
 	assert.EqualError(t, err, "couldn't parse JWT token: token is malformed: token contains an invalid number of segments")
 	assert.Empty(t, w)
 }
 
+func testVerifyScopedToken(t *testing.T) {
+	auth := getTestAuth(t)
+	w := "jeremy"
+	scopes := &service.Scopes{
+		PublicKeys: []string{"0xCAFEDUDE"},
+		Commands:   []string{"orderSubmission"},
+	}
+
+	tok, err := auth.NewScopedSession(w, "", scopes)
+	require.NoError(t, err)
+	assert.NotEmpty(t, tok)
+
+	wallets, returnedScopes, err := auth.VerifyScopedToken(tok, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{w}, wallets)
+	assert.Equal(t, scopes, returnedScopes)
+
+	// an unscoped token has no scopes
+	tok, err = auth.NewSession(w, "")
+	require.NoError(t, err)
+
+	_, returnedScopes, err = auth.VerifyScopedToken(tok, "")
+	require.NoError(t, err)
+	assert.Nil(t, returnedScopes)
+}
+
+func testVerifyTokenFromAnotherOriginFails(t *testing.T) {
+	auth := getTestAuth(t)
+	w := "jeremy"
+	origin := "https://console.vega.xyz"
+
+	tok, err := auth.NewSession(w, origin)
+	require.NoError(t, err)
+
+	wallet2, err := auth.VerifyToken(tok, origin)
+	require.NoError(t, err)
+	assert.Equal(t, w, wallet2)
+
+	wallet2, err = auth.VerifyToken(tok, "https://evil.com")
+	assert.ErrorIs(t, err, service.ErrTokenOriginMismatch)
+	assert.Empty(t, wallet2)
+
+	wallet2, err = auth.VerifyToken(tok, "")
+	assert.ErrorIs(t, err, service.ErrTokenOriginMismatch)
+	assert.Empty(t, wallet2)
+}
+
+func testVerifyAPIKey(t *testing.T) {
+	auth := getTestAuth(t)
+	store := &memoryAPIKeyStore{}
+	scopes := &service.Scopes{ReadOnly: true}
+	resp, err := service.CreateAPIKey(store, &service.CreateAPIKeyRequest{
+		Wallet: "jeremy",
+		Scopes: scopes,
+	})
+	require.NoError(t, err)
+
+	// API keys are refused until enabled
+	_, _, err = auth.VerifyScopedToken(resp.Key, "")
+	assert.ErrorIs(t, err, service.ErrAPIKeysAreNotEnabled)
+
+	auth.UseAPIKeys(store)
+
+	wallets, returnedScopes, err := auth.VerifyScopedToken(resp.Key, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"jeremy"}, wallets)
+	assert.Equal(t, scopes, returnedScopes)
+
+	// API keys can't be used from web pages
+	_, _, err = auth.VerifyScopedToken(resp.Key, "https://evil.com")
+	assert.ErrorIs(t, err, service.ErrTokenOriginMismatch)
+
+	// API keys can't be revoked through the API
+	_, err = auth.Revoke(resp.Key, "")
+	assert.ErrorIs(t, err, service.ErrAPIKeyCannotBeRevoked)
+}
+
+func testIntrospectToken(t *testing.T) {
+	auth := getTestAuth(t)
+	scopes := &service.Scopes{ReadOnly: true}
+
+	tok, err := auth.NewScopedSession("jeremy", "", scopes)
+	require.NoError(t, err)
+
+	info, err := auth.IntrospectToken(tok, "")
+	require.NoError(t, err)
+	assert.Equal(t, "jeremy", info.Wallet)
+	assert.NotEmpty(t, info.Session)
+	require.NotNil(t, info.ExpiresAt)
+	assert.True(t, info.ExpiresAt.After(time.Now()))
+	assert.Equal(t, scopes, info.Scopes)
+}
+
+func testRefreshToken(t *testing.T) {
+	auth := getTestAuth(t)
+	scopes := &service.Scopes{ReadOnly: true}
+
+	tok, err := auth.NewScopedSession("jeremy", "", scopes)
+	require.NoError(t, err)
+
+	newTok, err := auth.RefreshToken(tok, "")
+	require.NoError(t, err)
+	assert.NotEqual(t, tok, newTok)
+
+	// the previous token can't be used anymore
+	_, err = auth.VerifyToken(tok, "")
+	assert.ErrorIs(t, err, service.ErrSessionNotFound)
+
+	// the new token keeps the scopes
+	wallets, returnedScopes, err := auth.VerifyScopedToken(newTok, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"jeremy"}, wallets)
+	assert.Equal(t, scopes, returnedScopes)
+}
+
+func testListWalletSessions(t *testing.T) {
+	auth := getTestAuth(t)
+
+	tok1, err := auth.NewSession("jeremy", "")
+	require.NoError(t, err)
+	_, err = auth.NewSession("jeremy", "")
+	require.NoError(t, err)
+	_, err = auth.NewSession("alice", "")
+	require.NoError(t, err)
+
+	sessions, err := auth.ListWalletSessions(tok1, "")
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	for _, session := range sessions {
+		assert.Equal(t, "jeremy", session.Wallet)
+	}
+}
+
 func testRevokeValidToken(t *testing.T) {
 	auth := getTestAuth(t)
 	walletName := "jeremy"
 
 	// get a new session
-	tok, err := auth.NewSession(walletName)
+	tok, err := auth.NewSession(walletName, "")
 	assert.NoError(t, err)
 	assert.NotEmpty(t, tok)
 
-	wallet2, err := auth.VerifyToken(tok)
+	wallet2, err := auth.VerifyToken(tok, "")
 	assert.NoError(t, err)
 	assert.Equal(t, walletName, wallet2)
 
 	// now we made sure the token exists, let's revoke and re-verify it
-	name, err := auth.Revoke(tok)
+	name, err := auth.Revoke(tok, "")
 	assert.NoError(t, err)
 	assert.Equal(t, walletName, name)
 
-	w, err := auth.VerifyToken(tok)
+	w, err := auth.VerifyToken(tok, "")
 	assert.ErrorIs(t, err, service.ErrSessionNotFound)
 	assert.Empty(t, w)
 }
@@ -96,7 +272,457 @@ func testRevokeInvalidToken(t *testing.T) {
 	auth := getTestAuth(t)
 	tok := "hehehe that's not a toekn"
 
-	name, err := auth.Revoke(tok)
+	name, err := auth.Revoke(tok, "")
 	assert.EqualError(t, err, "couldn't parse JWT token: token is malformed: token contains an invalid number of segments")
 	assert.Empty(t, name)
 }
+
+func testRevokeTokenFromAnotherOriginFails(t *testing.T) {
+	auth := getTestAuth(t)
+	walletName := "jeremy"
+	tok, err := auth.NewSession(walletName, "https://console.vega.xyz")
+	require.NoError(t, err)
+
+	name, err := auth.Revoke(tok, "https://evil.example.com")
+	assert.ErrorIs(t, err, service.ErrTokenOriginMismatch)
+	assert.Empty(t, name)
+
+	wallet, err := auth.VerifyToken(tok, "https://console.vega.xyz")
+	require.NoError(t, err)
+	assert.Equal(t, walletName, wallet)
+}
+
+type memorySessionStore struct {
+	sessions []service.Session
+}
+
+func (s *memorySessionStore) GetSessions() ([]service.Session, error) {
+	return s.sessions, nil
+}
+
+func (s *memorySessionStore) SaveSessions(sessions []service.Session) error {
+	s.sessions = sessions
+	return nil
+}
+
+func testPersistingSessionsRestoresUnexpiredOnes(t *testing.T) {
+	auth := getTestAuth(t)
+	now := time.Now()
+	active := service.Session{
+		ID:         "active",
+		Wallet:     "jeremy",
+		CreatedAt:  now.Add(-time.Hour),
+		ExpiresAt:  now.Add(time.Hour),
+		Passphrase: "passphrase",
+	}
+	expired := service.Session{
+		ID:        "expired",
+		Wallet:    "jeremy",
+		CreatedAt: now.Add(-2 * time.Hour),
+		ExpiresAt: now.Add(-time.Hour),
+	}
+	store := &memorySessionStore{
+		sessions: []service.Session{active, expired},
+	}
+
+	restored, err := auth.PersistSessions(store, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []service.Session{active}, restored)
+
+	// the passphrases are not persisted, without passphrase getter
+	require.Len(t, store.sessions, 1)
+	assert.Equal(t, "active", store.sessions[0].ID)
+	assert.Empty(t, store.sessions[0].Passphrase)
+}
+
+func testNewSessionsArePersisted(t *testing.T) {
+	auth := getTestAuth(t)
+	store := &memorySessionStore{}
+	passphrases := func(wallet string) (string, bool) {
+		return "passphrase-of-" + wallet, true
+	}
+
+	_, err := auth.PersistSessions(store, passphrases)
+	require.NoError(t, err)
+
+	tok, err := auth.NewSession("jeremy", "")
+	require.NoError(t, err)
+	assert.NotEmpty(t, tok)
+
+	require.Len(t, store.sessions, 1)
+	assert.Equal(t, "jeremy", store.sessions[0].Wallet)
+	assert.Equal(t, "passphrase-of-jeremy", store.sessions[0].Passphrase)
+
+	// the token is still valid once restored by another instance
+	restoredAuth := getTestAuthWithKeys(t, auth.keys)
+	_, err = restoredAuth.PersistSessions(store, nil)
+	require.NoError(t, err)
+
+	w, err := restoredAuth.VerifyToken(tok, "")
+	require.NoError(t, err)
+	assert.Equal(t, "jeremy", w)
+}
+
+func testRevokedSessionsAreRemovedFromStore(t *testing.T) {
+	auth := getTestAuth(t)
+	store := &memorySessionStore{}
+
+	_, err := auth.PersistSessions(store, nil)
+	require.NoError(t, err)
+
+	tok, err := auth.NewSession("jeremy", "")
+	require.NoError(t, err)
+	require.Len(t, store.sessions, 1)
+
+	_, err = auth.Revoke(tok, "")
+	require.NoError(t, err)
+	assert.Empty(t, store.sessions)
+}
+
+func testRevokingWalletSessionsSucceeds(t *testing.T) {
+	auth := getTestAuth(t)
+	store := &memorySessionStore{}
+
+	_, err := auth.PersistSessions(store, nil)
+	require.NoError(t, err)
+
+	tok1, err := auth.NewSession("jeremy", "")
+	require.NoError(t, err)
+	tok2, err := auth.NewSession("jeremy", "")
+	require.NoError(t, err)
+	tok3, err := auth.NewSession("alice", "")
+	require.NoError(t, err)
+
+	auth.RevokeWalletSessions("jeremy")
+
+	_, err = auth.VerifyToken(tok1, "")
+	assert.ErrorIs(t, err, service.ErrSessionNotFound)
+	_, err = auth.VerifyToken(tok2, "")
+	assert.ErrorIs(t, err, service.ErrSessionNotFound)
+	w, err := auth.VerifyToken(tok3, "")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", w)
+	require.Len(t, store.sessions, 1)
+	assert.Equal(t, "alice", store.sessions[0].Wallet)
+}
+
+func testSessionsRetainTheirWalletUnderTheirOwnHandle(t *testing.T) {
+	auth := getTestAuth(t)
+	keeper := newMemoryWalletKeeper("jeremy")
+	auth.UseWalletKeeper(keeper)
+
+	tok1, err := auth.NewSession("jeremy", "")
+	require.NoError(t, err)
+	tok2, err := auth.NewSession("jeremy", "")
+	require.NoError(t, err)
+	require.Equal(t, 2, keeper.handlesOf("jeremy"))
+
+	_, err = auth.Revoke(tok1, "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, keeper.handlesOf("jeremy"))
+
+	w, err := auth.VerifyToken(tok2, "")
+	require.NoError(t, err)
+	assert.Equal(t, "jeremy", w)
+
+	auth.RevokeWalletSessions("jeremy")
+	assert.Equal(t, 0, keeper.handlesOf("jeremy"))
+}
+
+func testMultiWalletSessionsRetainAllTheirWallets(t *testing.T) {
+	auth := getTestAuth(t)
+	keeper := newMemoryWalletKeeper("jeremy", "kevin")
+	auth.UseWalletKeeper(keeper)
+
+	tok, err := auth.NewMultiWalletSession([]string{"jeremy", "kevin"}, "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, keeper.handlesOf("jeremy"))
+	assert.Equal(t, 1, keeper.handlesOf("kevin"))
+
+	// the first wallet is the default one
+	w, err := auth.VerifyToken(tok, "")
+	require.NoError(t, err)
+	assert.Equal(t, "jeremy", w)
+
+	wallets, _, err := auth.VerifyScopedToken(tok, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"jeremy", "kevin"}, wallets)
+
+	// the refreshed token keeps all the wallets
+	newTok, err := auth.RefreshToken(tok, "")
+	require.NoError(t, err)
+	wallets, _, err = auth.VerifyScopedToken(newTok, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"jeremy", "kevin"}, wallets)
+
+	// revoking the sessions of one wallet ends the whole session
+	auth.RevokeWalletSessions("kevin")
+	assert.Equal(t, 0, keeper.handlesOf("jeremy"))
+	assert.Equal(t, 0, keeper.handlesOf("kevin"))
+	_, err = auth.VerifyToken(newTok, "")
+	assert.ErrorIs(t, err, service.ErrSessionNotFound)
+}
+
+func testMultiWalletSessionsFailIfWalletIsNotLogged(t *testing.T) {
+	auth := getTestAuth(t)
+	keeper := newMemoryWalletKeeper("jeremy")
+	auth.UseWalletKeeper(keeper)
+
+	tok, err := auth.NewMultiWalletSession([]string{"jeremy", "kevin"}, "", nil)
+	assert.ErrorIs(t, err, wallet.ErrWalletNotLoggedIn)
+	assert.Empty(t, tok)
+
+	// the wallets retained before the failure are released
+	assert.Equal(t, 0, keeper.handlesOf("jeremy"))
+}
+
+func testRefreshingTokenRetainsWalletBeforeReleasingIt(t *testing.T) {
+	auth := getTestAuth(t)
+	keeper := newMemoryWalletKeeper("jeremy")
+	auth.UseWalletKeeper(keeper)
+
+	tok, err := auth.NewSession("jeremy", "")
+	require.NoError(t, err)
+
+	refreshed, err := auth.RefreshToken(tok, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, keeper.handlesOf("jeremy"))
+	// Both sessions held the wallet at the same time.
+	assert.Equal(t, 2, keeper.maxHandles)
+	_, err = auth.VerifyToken(refreshed, "")
+	require.NoError(t, err)
+}
+
+func testRefreshingTokenConcurrentlySucceedsOnlyOnce(t *testing.T) {
+	auth := getTestAuth(t)
+	keeper := newMemoryWalletKeeper("jeremy")
+	auth.UseWalletKeeper(keeper)
+
+	tok, err := auth.NewSession("jeremy", "")
+	require.NoError(t, err)
+
+	const attempts = 10
+	refreshed := make(chan string, attempts)
+	wg := sync.WaitGroup{}
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			newTok, err := auth.RefreshToken(tok, "")
+			if err == nil {
+				refreshed <- newTok
+			}
+		}()
+	}
+	wg.Wait()
+	close(refreshed)
+
+	require.Len(t, refreshed, 1)
+	_, err = auth.VerifyToken(<-refreshed, "")
+	require.NoError(t, err)
+	// Only the session of the refreshed token holds the wallet.
+	assert.Equal(t, 1, keeper.handlesOf("jeremy"))
+}
+
+func testUsingWalletKeeperRevokesSessionsOfLockedWallets(t *testing.T) {
+	auth := getTestAuth(t)
+	keeper := newMemoryWalletKeeper("jeremy")
+
+	tok1, err := auth.NewSession("jeremy", "")
+	require.NoError(t, err)
+	tok2, err := auth.NewSession("alice", "")
+	require.NoError(t, err)
+
+	auth.UseWalletKeeper(keeper)
+
+	assert.Equal(t, 1, keeper.handlesOf("jeremy"))
+	_, err = auth.VerifyToken(tok1, "")
+	require.NoError(t, err)
+	_, err = auth.VerifyToken(tok2, "")
+	assert.ErrorIs(t, err, service.ErrSessionNotFound)
+}
+
+// memoryWalletKeeper only lets the specified wallets be retained.
+type memoryWalletKeeper struct {
+	unlocked map[string]bool
+	// handle -> wallet
+	handles map[string]string
+	// maxHandles is the highest number of handles held at the same time.
+	maxHandles int
+}
+
+func newMemoryWalletKeeper(unlocked ...string) *memoryWalletKeeper {
+	k := &memoryWalletKeeper{
+		unlocked: map[string]bool{},
+		handles:  map[string]string{},
+	}
+	for _, name := range unlocked {
+		k.unlocked[name] = true
+	}
+	return k
+}
+
+func (k *memoryWalletKeeper) RetainWallet(handle, name string) error {
+	if !k.unlocked[name] {
+		return wallet.ErrWalletNotLoggedIn
+	}
+	k.handles[handle] = name
+	if len(k.handles) > k.maxHandles {
+		k.maxHandles = len(k.handles)
+	}
+	return nil
+}
+
+func (k *memoryWalletKeeper) ReleaseWallet(handle string) {
+	delete(k.handles, handle)
+}
+
+func (k *memoryWalletKeeper) handlesOf(name string) int {
+	count := 0
+	for _, w := range k.handles {
+		if w == name {
+			count++
+		}
+	}
+	return count
+}
+
+func testTokensAreSignedWithKeyID(t *testing.T) {
+	auth := getTestAuth(t)
+
+	tok, err := auth.NewSession("jeremy", "")
+	require.NoError(t, err)
+
+	token, _, err := jwt.NewParser().ParseUnverified(tok, &service.Claims{})
+	require.NoError(t, err)
+	id, err := service.SigningKeyID(auth.keys.Pub)
+	require.NoError(t, err)
+	assert.Equal(t, id, token.Header["kid"])
+	assert.Equal(t, service.SigningAlgorithmPS256, token.Method.Alg())
+}
+
+func testTokensCanBeSignedWithEdDSA(t *testing.T) {
+	keys, err := service.GenerateEd25519Keys()
+	require.NoError(t, err)
+	auth := getTestAuthWithKeys(t, keys)
+
+	tok, err := auth.NewSession("jeremy", "")
+	require.NoError(t, err)
+
+	token, _, err := jwt.NewParser().ParseUnverified(tok, &service.Claims{})
+	require.NoError(t, err)
+	assert.Equal(t, service.SigningAlgorithmEdDSA, token.Method.Alg())
+
+	w, err := auth.VerifyToken(tok, "")
+	require.NoError(t, err)
+	assert.Equal(t, "jeremy", w)
+}
+
+func testTokensOfPreviousKeyAreVerifiedDuringGracePeriod(t *testing.T) {
+	auth, store := getTestAuthWithPersistedSessions(t)
+	tok, err := auth.NewSession("jeremy", "")
+	require.NoError(t, err)
+
+	rotatedAuth := getRotatedTestAuth(t, auth.keys, store, time.Now().Add(time.Hour))
+
+	// the token signed by the previous key is still valid
+	w, err := rotatedAuth.VerifyToken(tok, "")
+	require.NoError(t, err)
+	assert.Equal(t, "jeremy", w)
+
+	// the new tokens are signed by the new key
+	newTok, err := rotatedAuth.NewSession("jeremy", "")
+	require.NoError(t, err)
+	token, _, err := jwt.NewParser().ParseUnverified(newTok, &service.Claims{})
+	require.NoError(t, err)
+	id, err := service.SigningKeyID(rotatedAuth.keys.Pub)
+	require.NoError(t, err)
+	assert.Equal(t, id, token.Header["kid"])
+
+	w, err = rotatedAuth.VerifyToken(newTok, "")
+	require.NoError(t, err)
+	assert.Equal(t, "jeremy", w)
+}
+
+func testTokensOfPreviousKeyAreRefusedAfterGracePeriod(t *testing.T) {
+	auth, store := getTestAuthWithPersistedSessions(t)
+	tok, err := auth.NewSession("jeremy", "")
+	require.NoError(t, err)
+
+	rotatedAuth := getRotatedTestAuth(t, auth.keys, store, time.Now().Add(-time.Minute))
+
+	w, err := rotatedAuth.VerifyToken(tok, "")
+	assert.Error(t, err)
+	assert.Empty(t, w)
+}
+
+func testTokensWithoutKeyIDAreVerifiedWithOldestKey(t *testing.T) {
+	auth, store := getTestAuthWithPersistedSessions(t)
+	_, err := auth.NewSession("jeremy", "")
+	require.NoError(t, err)
+	require.Len(t, store.sessions, 1)
+
+	// the tokens issued before the keys had an ID don't have the kid header
+	priv, err := jwt.ParseRSAPrivateKeyFromPEM(auth.keys.Priv)
+	require.NoError(t, err)
+	legacyTok, err := jwt.NewWithClaims(jwt.SigningMethodPS256, &service.Claims{
+		Session: store.sessions[0].ID,
+		Wallet:  "jeremy",
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: jwt.At(time.Now().Add(time.Hour)),
+		},
+	}).SignedString(priv)
+	require.NoError(t, err)
+
+	w, err := auth.VerifyToken(legacyTok, "")
+	require.NoError(t, err)
+	assert.Equal(t, "jeremy", w)
+
+	rotatedAuth := getRotatedTestAuth(t, auth.keys, store, time.Now().Add(time.Hour))
+
+	w, err = rotatedAuth.VerifyToken(legacyTok, "")
+	require.NoError(t, err)
+	assert.Equal(t, "jeremy", w)
+}
+
+func getTestAuthWithPersistedSessions(t *testing.T) (*testAuth, *memorySessionStore) {
+	t.Helper()
+
+	auth := getTestAuth(t)
+	store := &memorySessionStore{}
+	if _, err := auth.PersistSessions(store, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	return auth, store
+}
+
+// getRotatedTestAuth returns an instance signing the tokens with a new Ed25519
+// key, and restoring the sessions of the instance whose keys are rotated.
+func getRotatedTestAuth(t *testing.T, previousKeys *service.RSAKeys, store *memorySessionStore, expiresAt time.Time) *testAuth {
+	t.Helper()
+
+	id, err := service.SigningKeyID(previousKeys.Pub)
+	require.NoError(t, err)
+
+	keys, err := service.GenerateEd25519Keys()
+	require.NoError(t, err)
+
+	rotatedAuth := getTestAuthWithKeys(t, keys)
+	err = rotatedAuth.UsePreviousSigningKeys([]service.PreviousSigningKey{
+		{
+			ID:        id,
+			Algorithm: service.SigningAlgorithmPS256,
+			Pub:       string(previousKeys.Pub),
+			RetiredAt: expiresAt.Add(-time.Hour),
+			ExpiresAt: expiresAt,
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = rotatedAuth.PersistSessions(store, nil)
+	require.NoError(t, err)
+
+	return rotatedAuth
+}
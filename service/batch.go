@@ -0,0 +1,255 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"code.vegaprotocol.io/protos/commands"
+	api "code.vegaprotocol.io/protos/vega/api/v1"
+	commandspb "code.vegaprotocol.io/protos/vega/commands/v1"
+	walletpb "code.vegaprotocol.io/protos/vega/wallet/v1"
+	vgrand "code.vegaprotocol.io/shared/libs/rand"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+// MaxBatchSize is the maximum number of transactions in a batch.
+const MaxBatchSize = 100
+
+// BatchItemStatus is the outcome of a transaction of a batch.
+type BatchItemStatus string
+
+const (
+	BatchItemSent   BatchItemStatus = "sent"
+	BatchItemFailed BatchItemStatus = "failed"
+	// BatchItemSkipped is the status of the transactions following a failed
+	// one, when the batch stops on the first error.
+	BatchItemSkipped BatchItemStatus = "skipped"
+)
+
+// BatchCommandRequest describes the request for SignTxBatch.
+type BatchCommandRequest struct {
+	// SendingMode is the way the transactions are sent to the node:
+	// "TYPE_ASYNC", "TYPE_SYNC" or "TYPE_COMMIT". It defaults to "TYPE_ASYNC".
+	SendingMode string `json:"sendingMode,omitempty"`
+	// StopOnFirstError skips the remaining transactions once one failed.
+	StopOnFirstError bool `json:"stopOnFirstError,omitempty"`
+	// Transactions are the transaction requests, as sent to `/api/v1/command`,
+	// in the order they are sent.
+	Transactions []*walletpb.SubmitTransactionRequest `json:"transactions"`
+
+	sendingType api.SubmitTransactionRequest_Type
+}
+
+// BatchCommandResponse describes the response for SignTxBatch.
+type BatchCommandResponse struct {
+	BatchID    string               `json:"batchId"`
+	ReceivedAt time.Time            `json:"receivedAt"`
+	Results    []BatchCommandResult `json:"results"`
+}
+
+// BatchCommandResult is the outcome of a transaction of a batch, in the order
+// of the request.
+type BatchCommandResult struct {
+	Status BatchItemStatus         `json:"status"`
+	TxID   string                  `json:"txId"`
+	TxHash string                  `json:"txHash,omitempty"`
+	SentAt *time.Time              `json:"sentAt,omitempty"`
+	Tx     *commandspb.Transaction `json:"tx,omitempty"`
+	Error  string                  `json:"error,omitempty"`
+}
+
+func ParseBatchCommandRequest(r *http.Request) (*BatchCommandRequest, commands.Errors) {
+	errs := commands.NewErrors()
+
+	body := struct {
+		SendingMode      string            `json:"sendingMode"`
+		StopOnFirstError bool              `json:"stopOnFirstError"`
+		Transactions     []json.RawMessage `json:"transactions"`
+	}{}
+	if err := unmarshalBody(r, &body); err != nil {
+		return nil, errs.FinalAdd(err)
+	}
+
+	req := &BatchCommandRequest{
+		SendingMode:      body.SendingMode,
+		StopOnFirstError: body.StopOnFirstError,
+	}
+
+	if ty, ok := parseSendingMode(body.SendingMode); ok {
+		req.sendingType = ty
+	} else {
+		errs.AddForProperty("sendingMode", commands.ErrIsNotSupported)
+	}
+
+	if len(body.Transactions) == 0 {
+		errs.AddForProperty("transactions", commands.ErrIsRequired)
+	} else if len(body.Transactions) > MaxBatchSize {
+		errs.AddForProperty("transactions", ErrBatchIsTooLarge)
+	}
+
+	for i, rawTx := range body.Transactions {
+		tx, txErrs := parseSubmitTransactionRequest(bytes.NewReader(rawTx))
+		for prop, propErrs := range txErrs {
+			for _, err := range propErrs {
+				errs.AddForProperty(fmt.Sprintf("transactions.%d.%s", i, prop), err)
+			}
+		}
+		req.Transactions = append(req.Transactions, tx)
+	}
+
+	if !errs.Empty() {
+		return nil, errs
+	}
+
+	return req, nil
+}
+
+// parseSendingMode returns the type of submission matching the sending mode,
+// "TYPE_ASYNC" if not set.
+func parseSendingMode(mode string) (api.SubmitTransactionRequest_Type, bool) {
+	if len(mode) == 0 {
+		return api.SubmitTransactionRequest_TYPE_ASYNC, true
+	}
+	ty, ok := api.SubmitTransactionRequest_Type_value[mode]
+	if !ok || ty == int32(api.SubmitTransactionRequest_TYPE_UNSPECIFIED) {
+		return api.SubmitTransactionRequest_TYPE_UNSPECIFIED, false
+	}
+	return api.SubmitTransactionRequest_Type(ty), true
+}
+
+// SignTxBatch asks the consent once for all the transactions of the batch,
+// and signs and sends them in order.
+func (s *Service) SignTxBatch(token string, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	defer r.Body.Close()
+
+	names, scopes, err := s.auth.VerifyScopedToken(token, r.Header.Get("Origin"))
+	if err != nil {
+		s.writeForbiddenError(w, err)
+		return
+	}
+
+	idempotency, err := ReadIdempotencyRequest(r)
+	if err != nil {
+		s.writeBadRequestErr(w, err)
+		return
+	}
+
+	req, errs := ParseBatchCommandRequest(r)
+	if !errs.Empty() {
+		s.writeBadRequest(w, errs)
+		return
+	}
+
+	s.submitOnce(w, r, names, idempotency, func() (interface{}, int, error) {
+		return s.sendBatch(r.Context(), names, scopes, req)
+	})
+}
+
+// sendBatch verifies the token can send all the transactions of the batch
+// before asking the consent. Once approved, the failure of a transaction is
+// reported in its result, and doesn't fail the batch. On failure, it returns
+// the HTTP status the error is reported with.
+func (s *Service) sendBatch(ctx context.Context, names []string, scopes *Scopes, req *BatchCommandRequest) (*BatchCommandResponse, int, error) {
+	wallets := make([]string, 0, len(req.Transactions))
+	for _, tx := range req.Transactions {
+		if err := scopes.CanSendTransaction(tx); err != nil {
+			return nil, http.StatusForbidden, err
+		}
+
+		name, err := s.resolveWallet(names, tx.GetPubKey())
+		if err != nil {
+			return nil, http.StatusForbidden, err
+		}
+		wallets = append(wallets, name)
+	}
+
+	if err := s.ensureWalletsUnlocked(wallets...); err != nil {
+		return nil, http.StatusForbidden, err
+	}
+
+	batchID := vgrand.RandomStr(TXIDLENGTH)
+	results := make([]BatchCommandResult, 0, len(req.Transactions))
+	for range req.Transactions {
+		results = append(results, BatchCommandResult{
+			TxID: vgrand.RandomStr(TXIDLENGTH),
+		})
+	}
+	publishAll := func(ty TransactionEventType, err error) {
+		for i, tx := range req.Transactions {
+			s.publishTransactionEvent(wallets[i], results[i].TxID, tx, ty, "", err)
+		}
+	}
+
+	receivedAt := time.Now()
+	recordAll := func(status TransactionStatus, err error) {
+		for i, tx := range req.Transactions {
+			s.recordTransaction(wallets[i], results[i].TxID, tx, receivedAt, status, SentTransaction{Error: err})
+		}
+	}
+
+	publishAll(TransactionConsentRequested, nil)
+	approved, err := s.policy.AskBatch(ctx, req.Transactions, batchID, receivedAt)
+	if err != nil {
+		s.log.Error("couldn't get user consent", zap.Error(err))
+		publishAll(TransactionFailed, err)
+		recordAll(TransactionStatusFailed, err)
+		return nil, http.StatusServiceUnavailable, err
+	}
+
+	if !approved {
+		s.log.Info("user rejected batch signing request", zap.String("batch-id", batchID))
+		publishAll(TransactionRejected, nil)
+		recordAll(TransactionStatusRejected, nil)
+		return nil, http.StatusUnauthorized, ErrRejectedSignRequest
+	}
+	s.log.Info("user approved batch signing request", zap.String("batch-id", batchID))
+	publishAll(TransactionApproved, nil)
+
+	blockData, cltIdx, err := s.nodeForward.LastBlockHeightAndHash(ctx)
+	if err != nil {
+		for i, tx := range req.Transactions {
+			s.reportSentTransaction(wallets[i], tx, receivedAt, SentTransaction{
+				TxID:  results[i].TxID,
+				Error: ErrCouldNotGetBlockHeight,
+			})
+		}
+		return nil, http.StatusInternalServerError, ErrCouldNotGetBlockHeight
+	}
+
+	failed := false
+	for i, tx := range req.Transactions {
+		if failed && req.StopOnFirstError {
+			s.reportSentTransaction(wallets[i], tx, receivedAt, SentTransaction{
+				TxID:  results[i].TxID,
+				Error: ErrSkippedAfterFailedTransaction,
+			})
+			results[i].Status = BatchItemSkipped
+			results[i].Error = ErrSkippedAfterFailedTransaction.Error()
+			continue
+		}
+
+		sent := s.signAndSendTransaction(ctx, wallets[i], results[i].TxID, tx, receivedAt, blockData, cltIdx, req.sendingType)
+		results[i].Tx = sent.Tx
+		if sent.Error != nil {
+			failed = true
+			results[i].Status = BatchItemFailed
+			results[i].Error = sent.Error.Error()
+			continue
+		}
+		sentAt := sent.SentAt
+		results[i].Status = BatchItemSent
+		results[i].TxHash = sent.TxHash
+		results[i].SentAt = &sentAt
+	}
+
+	return &BatchCommandResponse{
+		BatchID:    batchID,
+		ReceivedAt: receivedAt,
+		Results:    results,
+	}, 0, nil
+}
@@ -0,0 +1,267 @@
+package service_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"code.vegaprotocol.io/vegawallet/service"
+
+	api "code.vegaprotocol.io/protos/vega/api/v1"
+	commandspb "code.vegaprotocol.io/protos/vega/commands/v1"
+	vgrand "code.vegaprotocol.io/shared/libs/rand"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigningBatch(t *testing.T) {
+	t.Run("Signing a batch succeeds", testSigningBatchSucceeds)
+	t.Run("Signing a batch with a failed transaction continues", testSigningBatchWithFailedTransactionContinues)
+	t.Run("Signing a batch stopping on first error skips the remaining transactions", testSigningBatchStoppingOnFirstErrorSkipsRemainingTransactions)
+	t.Run("Signing a batch with invalid request fails", testSigningBatchWithInvalidRequestFails)
+	t.Run("Signing a batch with command not allowed by token fails", testSigningBatchWithCommandNotAllowedByTokenFails)
+	t.Run("Declining a batch fails", testDecliningBatchFails)
+	t.Run("Signing a batch with locked wallet fails", testSigningBatchWithLockedWalletFails)
+}
+
+func testSigningBatchSucceeds(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+	payload := batchPayload(false, "pubKey1", "pubKey2")
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName).Times(2).Return(true)
+	s.nodeForward.EXPECT().LastBlockHeightAndHash(gomock.Any()).Times(1).Return(testBlockData(), 0, nil)
+	gomock.InOrder(
+		s.handler.EXPECT().SignTx(walletName, gomock.Any(), uint64(42)).Times(1).Return(&commandspb.Transaction{}, nil),
+		s.nodeForward.EXPECT().SendTx(gomock.Any(), gomock.Any(), api.SubmitTransactionRequest_TYPE_ASYNC, gomock.Any()).Times(1).Return("hash1", nil),
+		s.handler.EXPECT().SignTx(walletName, gomock.Any(), uint64(42)).Times(1).Return(&commandspb.Transaction{}, nil),
+		s.nodeForward.EXPECT().SendTx(gomock.Any(), gomock.Any(), api.SubmitTransactionRequest_TYPE_ASYNC, gomock.Any()).Times(1).Return("hash2", nil),
+	)
+
+	// when
+	statusCode, body := serveHTTP(t, s, batchRequest(t, payload, headers))
+
+	// then
+	require.Equal(t, http.StatusOK, statusCode)
+	resp := unmarshalBatchResponse(t, body)
+	assert.NotEmpty(t, resp.BatchID)
+	require.Len(t, resp.Results, 2)
+	assert.Equal(t, service.BatchItemSent, resp.Results[0].Status)
+	assert.Equal(t, "hash1", resp.Results[0].TxHash)
+	assert.Equal(t, service.BatchItemSent, resp.Results[1].Status)
+	assert.Equal(t, "hash2", resp.Results[1].TxHash)
+	assert.NotEqual(t, resp.Results[0].TxID, resp.Results[1].TxID)
+}
+
+func testSigningBatchWithFailedTransactionContinues(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+	payload := batchPayload(false, "pubKey1", "pubKey2")
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName).Times(2).Return(true)
+	s.nodeForward.EXPECT().LastBlockHeightAndHash(gomock.Any()).Times(1).Return(testBlockData(), 0, nil)
+	s.handler.EXPECT().SignTx(walletName, gomock.Any(), uint64(42)).Times(2).Return(&commandspb.Transaction{}, nil)
+	gomock.InOrder(
+		s.nodeForward.EXPECT().SendTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return("", assert.AnError),
+		s.nodeForward.EXPECT().SendTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return("hash2", nil),
+	)
+
+	// when
+	statusCode, body := serveHTTP(t, s, batchRequest(t, payload, headers))
+
+	// then
+	require.Equal(t, http.StatusOK, statusCode)
+	resp := unmarshalBatchResponse(t, body)
+	require.Len(t, resp.Results, 2)
+	assert.Equal(t, service.BatchItemFailed, resp.Results[0].Status)
+	assert.Equal(t, assert.AnError.Error(), resp.Results[0].Error)
+	assert.Equal(t, service.BatchItemSent, resp.Results[1].Status)
+}
+
+func testSigningBatchStoppingOnFirstErrorSkipsRemainingTransactions(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+	payload := batchPayload(true, "pubKey1", "pubKey2", "pubKey3")
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName).Times(3).Return(true)
+	s.nodeForward.EXPECT().LastBlockHeightAndHash(gomock.Any()).Times(1).Return(testBlockData(), 0, nil)
+	gomock.InOrder(
+		s.handler.EXPECT().SignTx(walletName, gomock.Any(), uint64(42)).Times(1).Return(&commandspb.Transaction{}, nil),
+		s.handler.EXPECT().SignTx(walletName, gomock.Any(), uint64(42)).Times(1).Return(nil, assert.AnError),
+	)
+	s.nodeForward.EXPECT().SendTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return("hash1", nil)
+
+	// when
+	statusCode, body := serveHTTP(t, s, batchRequest(t, payload, headers))
+
+	// then
+	require.Equal(t, http.StatusOK, statusCode)
+	resp := unmarshalBatchResponse(t, body)
+	require.Len(t, resp.Results, 3)
+	assert.Equal(t, service.BatchItemSent, resp.Results[0].Status)
+	assert.Equal(t, service.BatchItemFailed, resp.Results[1].Status)
+	assert.Equal(t, service.BatchItemSkipped, resp.Results[2].Status)
+	assert.Equal(t, service.ErrSkippedAfterFailedTransaction.Error(), resp.Results[2].Error)
+}
+
+func testSigningBatchWithInvalidRequestFails(t *testing.T) {
+	tcs := []struct {
+		name    string
+		payload string
+	}{
+		{
+			name:    "without transactions",
+			payload: `{"transactions": []}`,
+		}, {
+			name:    "with invalid transaction",
+			payload: `{"transactions": [{"pubKey": "pubKey1", "orderCancellation": {}}, {"orderCancellation": {}}]}`,
+		}, {
+			name:    "with unsupported sending mode",
+			payload: `{"sendingMode": "TYPE_SOON", "transactions": [{"pubKey": "pubKey1", "orderCancellation": {}}]}`,
+		}, {
+			name:    "with invalid JSON",
+			payload: `{"transactions": [}`,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			s := getTestService(tt, "automatic")
+			defer s.ctrl.Finish()
+
+			// given
+			walletName := vgrand.RandomStr(5)
+			token := vgrand.RandomStr(5)
+			headers := authHeaders(tt, token)
+
+			// setup
+			s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
+
+			// when
+			statusCode, _ := serveHTTP(tt, s, batchRequest(tt, tc.payload, headers))
+
+			// then
+			assert.Equal(tt, http.StatusBadRequest, statusCode)
+		})
+	}
+}
+
+func testSigningBatchWithCommandNotAllowedByTokenFails(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+	payload := batchPayload(false, "pubKey1", "pubKey2")
+	scopes := &service.Scopes{PublicKeys: []string{"pubKey1"}}
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, scopes, nil)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, batchRequest(t, payload, headers))
+
+	// then
+	assert.Equal(t, http.StatusForbidden, statusCode)
+}
+
+func testDecliningBatchFails(t *testing.T) {
+	s := getTestService(t, "manual")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+	payload := batchPayload(false, "pubKey1", "toBeDeclined")
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName).Times(2).Return(true)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, batchRequest(t, payload, headers))
+
+	// then
+	assert.Equal(t, http.StatusUnauthorized, statusCode)
+}
+
+func testSigningBatchWithLockedWalletFails(t *testing.T) {
+	s := getTestService(t, "manual")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+	payload := batchPayload(false, "pubKey1", "pubKey2")
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName).Times(1).Return(false)
+	s.handler.EXPECT().SignTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	s.nodeForward.EXPECT().LastBlockHeightAndHash(gomock.Any()).Times(0)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, batchRequest(t, payload, headers))
+
+	// then
+	assert.Equal(t, http.StatusForbidden, statusCode)
+}
+
+func batchPayload(stopOnFirstError bool, pubKeys ...string) string {
+	txs := make([]string, 0, len(pubKeys))
+	for _, pubKey := range pubKeys {
+		txs = append(txs, fmt.Sprintf(`{"pubKey": "%s", "orderCancellation": {}}`, pubKey))
+	}
+	return fmt.Sprintf(`{"stopOnFirstError": %t, "transactions": [%s]}`, stopOnFirstError, strings.Join(txs, ", "))
+}
+
+func testBlockData() *api.LastBlockHeightResponse {
+	return &api.LastBlockHeightResponse{
+		Height:              42,
+		Hash:                "0292041e2f0cf741894503fb3ead4cb817bca2375e543aa70f7c4d938157b5a6",
+		SpamPowDifficulty:   2,
+		SpamPowHashFunction: "sha3_24_rounds",
+	}
+}
+
+func batchRequest(t *testing.T, payload string, headers map[string]string) *http.Request {
+	t.Helper()
+	return buildRequest(t, http.MethodPost, "/api/v1/command/batch", payload, headers)
+}
+
+func unmarshalBatchResponse(t *testing.T, body []byte) *service.BatchCommandResponse {
+	t.Helper()
+	resp := &service.BatchCommandResponse{}
+	if err := json.Unmarshal(body, resp); err != nil {
+		t.Fatalf("couldn't unmarshal response: %v", err)
+	}
+	return resp
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const clientTimeout = 30 * time.Second
+
+// Client calls the service, either on its Unix domain socket, or on its TCP
+// address.
+type Client struct {
+	baseURL string
+	http    *http.Client
+}
+
+// NewUnixSocketClient returns a client calling the service on the socket.
+func NewUnixSocketClient(socketPath string) *Client {
+	dialer := &net.Dialer{}
+	return &Client{
+		// The host is ignored, as the connections are always made to the
+		// socket.
+		baseURL: "http://unix",
+		http: &http.Client{
+			Timeout: clientTimeout,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return dialer.DialContext(ctx, "unix", socketPath)
+				},
+			},
+		},
+	}
+}
+
+// NewClient returns a client calling the service on its URL, like
+// "http://127.0.0.1:1789".
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		http: &http.Client{
+			Timeout: clientTimeout,
+		},
+	}
+}
+
+// NewTLSClient returns a client calling the service over HTTPS, trusting the
+// certificates issued by the certificate authority, like the local one.
+func NewTLSClient(baseURL string, caCert []byte) (*Client, error) {
+	rootCAs := x509.NewCertPool()
+	if !rootCAs.AppendCertsFromPEM(caCert) {
+		return nil, ErrCertificateIsNotPEMEncoded
+	}
+
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		http: &http.Client{
+			Timeout: clientTimeout,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					RootCAs:    rootCAs,
+					MinVersion: tls.VersionTLS12,
+				},
+			},
+		},
+	}, nil
+}
+
+// ClientResponse is the raw response of the service.
+type ClientResponse struct {
+	StatusCode int    `json:"statusCode"`
+	Body       []byte `json:"-"`
+}
+
+// Do sends the request to the endpoint, like "/api/v1/keys". The token, if
+// set, is sent in the Authorization header. It can be an API key.
+func (c *Client) Do(method, endpoint, token string, body []byte) (*ClientResponse, error) {
+	if !strings.HasPrefix(endpoint, "/") {
+		endpoint = "/" + endpoint
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't build the request: %w", err)
+	}
+	if len(body) != 0 {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if len(token) != 0 {
+		req.Header.Set("Authorization", jwtBearer+token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't call the service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read the response of the service: %w", err)
+	}
+
+	return &ClientResponse{
+		StatusCode: resp.StatusCode,
+		Body:       respBody,
+	}, nil
+}
@@ -7,15 +7,55 @@ import (
 )
 
 var (
-	ErrInvalidToken              = errors.New("invalid token")
-	ErrInvalidClaims             = errors.New("invalid claims")
-	ErrInvalidOrMissingToken     = newErrorResponse("invalid or missing token")
-	ErrCouldNotReadRequest       = errors.New("couldn't read request")
-	ErrCouldNotGetBlockHeight    = errors.New("couldn't get last block height")
-	ErrShouldBeBase64Encoded     = errors.New("should be base64 encoded")
-	ErrRSAKeysAlreadyExists      = errors.New("RSA keys already exist")
-	ErrRejectedSignRequest       = errors.New("user rejected sign request")
-	ErrInterruptedConsentRequest = errors.New("process to request consent has been interrupted")
+	ErrInvalidToken                  = errors.New("invalid token")
+	ErrInvalidClaims                 = errors.New("invalid claims")
+	ErrInvalidOrMissingToken         = newErrorResponse("invalid or missing token")
+	ErrCouldNotReadRequest           = errors.New("couldn't read request")
+	ErrCouldNotGetBlockHeight        = errors.New("couldn't get last block height")
+	ErrShouldBeBase64Encoded         = errors.New("should be base64 encoded")
+	ErrRSAKeysAlreadyExists          = errors.New("RSA keys already exist")
+	ErrRSAKeysDoNotMatch             = errors.New("public RSA key doesn't match the private RSA key")
+	ErrKeyIsNotPEMEncoded            = errors.New("the key is not PEM-encoded")
+	ErrUnsupportedSigningKey         = errors.New("the key can't be used to sign the tokens")
+	ErrUnsupportedSigningAlgorithm   = errors.New("unsupported signing algorithm")
+	ErrUnknownSigningKey             = errors.New("the token has been signed by an unknown key")
+	ErrCertificateIsNotPEMEncoded    = errors.New("the certificate is not PEM-encoded")
+	ErrNoHostSpecified               = errors.New("at least one host should be specified")
+	ErrHostIsNotLocal                = errors.New("the local certificate authority only issues certificates for local names and addresses, the certificate files have to be set for this host")
+	ErrSocketPathIsNotSocket         = errors.New("the socket path already exists and is not a socket")
+	ErrSocketAlreadyInUse            = errors.New("the socket is already used by another service")
+	ErrRejectedSignRequest           = errors.New("user rejected sign request")
+	ErrInterruptedConsentRequest     = errors.New("process to request consent has been interrupted")
+	ErrTokenOriginMismatch           = errors.New("the token has not been obtained from this origin")
+	ErrOriginNotApproved             = errors.New("this origin has not been approved to connect to the wallet")
+	ErrOriginNotFound                = errors.New("origin not found")
+	ErrAPIKeyNotFound                = errors.New("API key not found")
+	ErrInvalidAPIKey                 = errors.New("invalid API key")
+	ErrAPIKeysAreNotEnabled          = errors.New("API keys are not enabled")
+	ErrAPIKeyCannotBeRevoked         = errors.New("API keys can only be revoked with the command line")
+	ErrAPIKeyCannotBeRefreshed       = errors.New("API keys don't expire and can't be refreshed")
+	ErrAPIKeyCannotRevokeSessions    = errors.New("API keys can't revoke the sessions")
+	ErrNoWalletSpecified             = errors.New("at least one wallet should be specified")
+	ErrWalletNotInSession            = errors.New("the token doesn't give access to this wallet")
+	ErrWalletIsLocked                = errors.New("the wallet is locked, it has to be unlocked with POST api/v1/wallets/unlock")
+	ErrIsDuplicated                  = errors.New("is duplicated")
+	ErrIsMutuallyExclusiveWithWallet = errors.New("can't be set alongside the wallet and the passphrase")
+	ErrInvalidJSONRPCRequest         = errors.New("the request is not a valid JSON-RPC 2.0 request")
+	ErrJSONRPCBatchIsEmpty           = errors.New("the batch of requests is empty")
+	ErrJSONRPCMethodNotFound         = errors.New("the method does not exist")
+	ErrJSONRPCParamsMustBeObject     = errors.New("the params should be a JSON object")
+	ErrStreamingIsNotSupported       = errors.New("the connection doesn't support streaming")
+	ErrBatchIsTooLarge               = errors.New("should not hold more than 100 transactions")
+	ErrSkippedAfterFailedTransaction = errors.New("skipped as a previous transaction of the batch failed")
+	ErrShouldBeRFC3339Time           = errors.New("should be a RFC3339 time")
+	ErrShouldBePositiveInteger       = errors.New("should be a positive integer")
+	ErrIdempotencyKeyIsInvalid       = errors.New("the idempotency key should hold between 1 and 255 characters")
+	ErrIdempotencyKeyReused          = errors.New("the idempotency key has already been used with a different request")
+	ErrIdempotentRequestInProgress   = errors.New("a request with this idempotency key is still in progress")
+	ErrPendingConsentNotFound        = errors.New("no pending consent request with this ID")
+	ErrTooManyPendingConsents        = errors.New("too many consent requests are waiting for a decision")
+	ErrInvalidAdminToken             = errors.New("invalid admin token")
+	ErrAdminTokenNotGenerated        = errors.New("no admin token has been generated, use the `service admin-token generate` command")
 )
 
 type ErrorsResponse struct {
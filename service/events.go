@@ -0,0 +1,254 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	walletpb "code.vegaprotocol.io/protos/vega/wallet/v1"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+// TransactionEventType is a step of the processing of a transaction.
+type TransactionEventType string
+
+const (
+	// TransactionConsentRequested is published when the transaction is
+	// waiting for the consent of the user.
+	TransactionConsentRequested TransactionEventType = "consentRequested"
+	TransactionApproved         TransactionEventType = "approved"
+	TransactionRejected         TransactionEventType = "rejected"
+	TransactionSigned           TransactionEventType = "signed"
+	TransactionSent             TransactionEventType = "sent"
+	// TransactionFailed is published when the transaction couldn't be signed
+	// or sent. The error is set.
+	TransactionFailed TransactionEventType = "failed"
+)
+
+const (
+	// eventsBufferSize is the number of events a subscriber can lag behind
+	// before the next ones are dropped.
+	eventsBufferSize = 64
+	// eventsKeepAliveInterval is the interval at which the token of the stream
+	// is verified again, and a comment sent to keep the connection open.
+	eventsKeepAliveInterval = 15 * time.Second
+)
+
+// TransactionEvent is published at each step of the processing of a
+// transaction, identified by the TxID returned by the command endpoints.
+type TransactionEvent struct {
+	// Sequence orders the events published by the service.
+	Sequence uint64               `json:"sequence"`
+	Type     TransactionEventType `json:"type"`
+	TxID     string               `json:"txId"`
+	PubKey   string               `json:"pubKey"`
+	TxHash   string               `json:"txHash,omitempty"`
+	Error    string               `json:"error,omitempty"`
+	At       time.Time            `json:"at"`
+
+	// wallet restricts the event to the tokens giving access to it.
+	wallet string
+}
+
+// TransactionEvents dispatches the transaction events to the subscribers
+// allowed to see them.
+type TransactionEvents struct {
+	log *zap.Logger
+
+	mu          sync.Mutex
+	sequence    uint64
+	nextID      uint64
+	subscribers map[uint64]*eventsSubscriber
+	closed      bool
+}
+
+type eventsSubscriber struct {
+	wallets map[string]struct{}
+	// scopes only lets the events of the keys of the token through.
+	scopes *Scopes
+	// txID only lets the events of this transaction through, if set.
+	txID   string
+	events chan TransactionEvent
+}
+
+func NewTransactionEvents(log *zap.Logger) *TransactionEvents {
+	return &TransactionEvents{
+		log:         log,
+		subscribers: map[uint64]*eventsSubscriber{},
+	}
+}
+
+// Subscribe returns the events of the transactions sent by the wallets with
+// the keys allowed by the scopes, filtered by TxID if set. The channel is
+// closed by the returned function, or when the events are closed.
+func (e *TransactionEvents) Subscribe(wallets []string, scopes *Scopes, txID string) (<-chan TransactionEvent, func()) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	events := make(chan TransactionEvent, eventsBufferSize)
+	if e.closed {
+		close(events)
+		return events, func() {}
+	}
+
+	sub := &eventsSubscriber{
+		wallets: make(map[string]struct{}, len(wallets)),
+		scopes:  scopes,
+		txID:    txID,
+		events:  events,
+	}
+	for _, name := range wallets {
+		sub.wallets[name] = struct{}{}
+	}
+
+	id := e.nextID
+	e.nextID++
+	e.subscribers[id] = sub
+
+	return events, func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+
+		if _, ok := e.subscribers[id]; ok {
+			delete(e.subscribers, id)
+			close(sub.events)
+		}
+	}
+}
+
+// Publish sends the event to the subscribers having access to the wallet and
+// to the key of the event. It never blocks: the event is dropped for the subscribers lagging behind.
+func (e *TransactionEvents) Publish(wallet string, event TransactionEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.closed {
+		return
+	}
+
+	e.sequence++
+	event.Sequence = e.sequence
+	event.wallet = wallet
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+
+	for _, sub := range e.subscribers {
+		if _, ok := sub.wallets[wallet]; !ok {
+			continue
+		}
+		if err := sub.scopes.CanSeeKey(event.PubKey); err != nil {
+			continue
+		}
+		if len(sub.txID) != 0 && sub.txID != event.TxID {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			e.log.Warn("subscriber is lagging behind, the transaction event has been dropped",
+				zap.String("tx-id", event.TxID),
+				zap.String("type", string(event.Type)),
+			)
+		}
+	}
+}
+
+// Close ends all the subscriptions, so the streams can be closed when the
+// service stops.
+func (e *TransactionEvents) Close() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.closed {
+		return
+	}
+	e.closed = true
+
+	for id, sub := range e.subscribers {
+		close(sub.events)
+		delete(e.subscribers, id)
+	}
+}
+
+// StreamEvents streams the events of the transactions sent by the wallets of
+// the token, with the keys it's allowed to use, as Server-Sent Events. The events of a single transaction can be
+// selected with the `txId` query parameter. The stream is closed once the token
+// is no longer valid.
+func (s *Service) StreamEvents(t string, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	origin := r.Header.Get("Origin")
+	names, scopes, err := s.auth.VerifyScopedToken(t, origin)
+	if err != nil {
+		s.writeForbiddenError(w, err)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.writeInternalError(w, ErrStreamingIsNotSupported)
+		return
+	}
+
+	events, unsubscribe := s.events.Subscribe(names, scopes, r.URL.Query().Get("txId"))
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(eventsKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := writeServerSentEvent(w, event); err != nil {
+				s.log.Warn("couldn't write the transaction event", zap.Error(err))
+				return
+			}
+		case <-keepAlive.C:
+			if _, _, err := s.auth.VerifyScopedToken(t, origin); err != nil {
+				s.log.Info("closing the event stream", zap.Error(err))
+				return
+			}
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// publishTransactionEvent publishes the step of the transaction to the
+// subscribers having access to the wallet.
+func (s *Service) publishTransactionEvent(wallet, txID string, req *walletpb.SubmitTransactionRequest, ty TransactionEventType, txHash string, err error) {
+	event := TransactionEvent{
+		Type:   ty,
+		TxID:   txID,
+		PubKey: req.GetPubKey(),
+		TxHash: txHash,
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	s.events.Publish(wallet, event)
+}
+
+func writeServerSentEvent(w http.ResponseWriter, event TransactionEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("couldn't marshal the event: %w", err)
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Sequence, event.Type, data)
+	return err
+}
@@ -0,0 +1,258 @@
+package service_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"code.vegaprotocol.io/vegawallet/service"
+	"code.vegaprotocol.io/vegawallet/wallet"
+
+	api "code.vegaprotocol.io/protos/vega/api/v1"
+	commandspb "code.vegaprotocol.io/protos/vega/commands/v1"
+	vgrand "code.vegaprotocol.io/shared/libs/rand"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestTransactionEvents(t *testing.T) {
+	t.Run("Publishing an event reaches the subscribers of the wallet only", testPublishingEventReachesSubscribersOfWalletOnly)
+	t.Run("Subscribing to a transaction filters the other ones", testSubscribingToTransactionFiltersOtherOnes)
+	t.Run("Publishing events orders them", testPublishingEventsOrdersThem)
+	t.Run("Closing the events ends the subscriptions", testClosingEventsEndsSubscriptions)
+	t.Run("Subscribing with scopes filters the events of the other keys", testSubscribingWithScopesFiltersEventsOfOtherKeys)
+	t.Run("Streaming the events of a sent transaction succeeds", testStreamingEventsOfSentTransactionSucceeds)
+	t.Run("Streaming the events of a failed transaction succeeds", testStreamingEventsOfFailedTransactionSucceeds)
+	t.Run("Streaming the events with invalid token fails", testStreamingEventsWithInvalidTokenFails)
+}
+
+func testPublishingEventReachesSubscribersOfWalletOnly(t *testing.T) {
+	// given
+	events := service.NewTransactionEvents(zap.NewNop())
+	subscribed, unsubscribe := events.Subscribe([]string{"wallet-1", "wallet-2"}, nil, "")
+	defer unsubscribe()
+	other, unsubscribeOther := events.Subscribe([]string{"wallet-3"}, nil, "")
+	defer unsubscribeOther()
+
+	// when
+	events.Publish("wallet-2", service.TransactionEvent{
+		Type: service.TransactionSigned,
+		TxID: "tx-1",
+	})
+
+	// then
+	require.Len(t, subscribed, 1)
+	event := <-subscribed
+	assert.Equal(t, service.TransactionSigned, event.Type)
+	assert.Equal(t, "tx-1", event.TxID)
+	assert.False(t, event.At.IsZero())
+	assert.Len(t, other, 0)
+}
+
+func testSubscribingToTransactionFiltersOtherOnes(t *testing.T) {
+	// given
+	events := service.NewTransactionEvents(zap.NewNop())
+	subscribed, unsubscribe := events.Subscribe([]string{"wallet-1"}, nil, "tx-2")
+	defer unsubscribe()
+
+	// when
+	events.Publish("wallet-1", service.TransactionEvent{Type: service.TransactionSigned, TxID: "tx-1"})
+	events.Publish("wallet-1", service.TransactionEvent{Type: service.TransactionSigned, TxID: "tx-2"})
+
+	// then
+	require.Len(t, subscribed, 1)
+	assert.Equal(t, "tx-2", (<-subscribed).TxID)
+}
+
+func testPublishingEventsOrdersThem(t *testing.T) {
+	// given
+	events := service.NewTransactionEvents(zap.NewNop())
+	subscribed, unsubscribe := events.Subscribe([]string{"wallet-1"}, nil, "")
+	defer unsubscribe()
+
+	// when
+	events.Publish("wallet-1", service.TransactionEvent{Type: service.TransactionSigned, TxID: "tx-1"})
+	events.Publish("wallet-1", service.TransactionEvent{Type: service.TransactionSent, TxID: "tx-1"})
+
+	// then
+	require.Len(t, subscribed, 2)
+	first, second := <-subscribed, <-subscribed
+	assert.Less(t, first.Sequence, second.Sequence)
+	assert.Equal(t, service.TransactionSent, second.Type)
+}
+
+func testClosingEventsEndsSubscriptions(t *testing.T) {
+	// given
+	events := service.NewTransactionEvents(zap.NewNop())
+	subscribed, unsubscribe := events.Subscribe([]string{"wallet-1"}, nil, "")
+
+	// when
+	events.Close()
+
+	// then
+	_, ok := <-subscribed
+	assert.False(t, ok)
+	assert.NotPanics(t, unsubscribe)
+
+	// when
+	late, _ := events.Subscribe([]string{"wallet-1"}, nil, "")
+
+	// then
+	_, ok = <-late
+	assert.False(t, ok)
+}
+
+func testSubscribingWithScopesFiltersEventsOfOtherKeys(t *testing.T) {
+	// given
+	events := service.NewTransactionEvents(zap.NewNop())
+	scopes := &service.Scopes{PublicKeys: []string{"pubKey1"}}
+	subscribed, unsubscribe := events.Subscribe([]string{"wallet-1"}, scopes, "")
+	defer unsubscribe()
+
+	// when
+	events.Publish("wallet-1", service.TransactionEvent{Type: service.TransactionFailed, TxID: "tx-1", PubKey: "pubKey2", Error: "some error"})
+	events.Publish("wallet-1", service.TransactionEvent{Type: service.TransactionSigned, TxID: "tx-2", PubKey: "pubKey1"})
+
+	// then
+	require.Len(t, subscribed, 1)
+	event := <-subscribed
+	assert.Equal(t, "tx-2", event.TxID)
+	assert.Equal(t, "pubKey1", event.PubKey)
+}
+
+func testStreamingEventsOfSentTransactionSucceeds(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+	pubKey := vgrand.RandomStr(5)
+	payload := fmt.Sprintf(`{"pubKey": "%s", "orderCancellation": {}}`, pubKey)
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").AnyTimes().Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName).Times(1).Return(true)
+	s.handler.EXPECT().SignTx(walletName, gomock.Any(), gomock.Any()).Times(1).Return(&commandspb.Transaction{}, nil)
+	s.nodeForward.EXPECT().SendTx(gomock.Any(), gomock.Any(), api.SubmitTransactionRequest_TYPE_ASYNC, gomock.Any()).Times(1).Return("tx-hash", nil)
+	s.nodeForward.EXPECT().LastBlockHeightAndHash(gomock.Any()).Times(1).Return(&api.LastBlockHeightResponse{
+		Height:              42,
+		Hash:                "0292041e2f0cf741894503fb3ead4cb817bca2375e543aa70f7c4d938157b5a6",
+		SpamPowDifficulty:   2,
+		SpamPowHashFunction: "sha3_24_rounds",
+	}, 0, nil)
+	stream := openEventStream(t, s, headers)
+
+	// when
+	statusCode, body := serveHTTP(t, s, signTxRequest(t, payload, headers))
+
+	// then
+	require.Equal(t, http.StatusOK, statusCode)
+	resp := &service.SendTransactionResponse{}
+	require.NoError(t, json.Unmarshal(body, resp))
+
+	events := readEvents(t, stream, 4)
+	assert.Equal(t, service.TransactionConsentRequested, events[0].Type)
+	assert.Equal(t, service.TransactionApproved, events[1].Type)
+	assert.Equal(t, service.TransactionSigned, events[2].Type)
+	assert.Equal(t, service.TransactionSent, events[3].Type)
+	assert.Equal(t, "tx-hash", events[3].TxHash)
+	for _, event := range events {
+		assert.Equal(t, resp.TxID, event.TxID)
+		assert.Equal(t, pubKey, event.PubKey)
+	}
+}
+
+func testStreamingEventsOfFailedTransactionSucceeds(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+	pubKey := vgrand.RandomStr(5)
+	payload := fmt.Sprintf(`{"pubKey": "%s", "orderCancellation": {}}`, pubKey)
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").AnyTimes().Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName).Times(1).Return(true)
+	s.handler.EXPECT().SignTx(walletName, gomock.Any(), gomock.Any()).Times(1).Return(nil, wallet.ErrWalletDoesNotExists)
+	s.nodeForward.EXPECT().LastBlockHeightAndHash(gomock.Any()).Times(1).Return(&api.LastBlockHeightResponse{
+		Height:              42,
+		Hash:                "0292041e2f0cf741894503fb3ead4cb817bca2375e543aa70f7c4d938157b5a6",
+		SpamPowDifficulty:   2,
+		SpamPowHashFunction: "sha3_24_rounds",
+	}, 0, nil)
+	stream := openEventStream(t, s, headers)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, signTxRequest(t, payload, headers))
+
+	// then
+	require.Equal(t, http.StatusInternalServerError, statusCode)
+	events := readEvents(t, stream, 3)
+	assert.Equal(t, service.TransactionApproved, events[1].Type)
+	assert.Equal(t, service.TransactionFailed, events[2].Type)
+	assert.Equal(t, wallet.ErrWalletDoesNotExists.Error(), events[2].Error)
+}
+
+func testStreamingEventsWithInvalidTokenFails(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return(nil, nil, service.ErrSessionNotFound)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, buildRequest(t, http.MethodGet, "/api/v1/events", "", headers))
+
+	// then
+	assert.Equal(t, http.StatusForbidden, statusCode)
+}
+
+// openEventStream subscribes to the events through an actual server, as the
+// response recorder doesn't support streaming.
+func openEventStream(t *testing.T, s *testService, headers map[string]string) *bufio.Reader {
+	t.Helper()
+
+	server := httptest.NewServer(s)
+	resp, err := http.DefaultClient.Do(buildRequest(t, http.MethodGet, server.URL+"/api/v1/events", "", headers))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = resp.Body.Close()
+		server.Close()
+	})
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	return bufio.NewReader(resp.Body)
+}
+
+func readEvents(t *testing.T, stream *bufio.Reader, count int) []service.TransactionEvent {
+	t.Helper()
+
+	events := make([]service.TransactionEvent, 0, count)
+	for len(events) < count {
+		line, err := stream.ReadString('\n')
+		require.NoError(t, err)
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		event := service.TransactionEvent{}
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event))
+		events = append(events, event)
+	}
+	return events
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.vegaprotocol.io/protos/commands"
+	walletpb "code.vegaprotocol.io/protos/vega/wallet/v1"
+	wcommands "code.vegaprotocol.io/vegawallet/commands"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+// TransactionStatus is the final outcome of a transaction submitted to the
+// service.
+type TransactionStatus string
+
+const (
+	TransactionStatusRejected TransactionStatus = "rejected"
+	// TransactionStatusFailed is the status of the transactions that couldn't
+	// be signed or sent. The error is set.
+	TransactionStatusFailed TransactionStatus = "failed"
+	TransactionStatusSent   TransactionStatus = "sent"
+)
+
+// TransactionStatuses lists the supported transaction statuses.
+var TransactionStatuses = []TransactionStatus{
+	TransactionStatusRejected,
+	TransactionStatusFailed,
+	TransactionStatusSent,
+}
+
+// IsTransactionStatus verifies the specified status is supported.
+func IsTransactionStatus(status string) bool {
+	for _, s := range TransactionStatuses {
+		if string(s) == status {
+			return true
+		}
+	}
+	return false
+}
+
+// TransactionRecord is the entry of the transaction history.
+type TransactionRecord struct {
+	TxID    string            `json:"txId"`
+	Wallet  string            `json:"wallet"`
+	PubKey  string            `json:"pubKey"`
+	Command string            `json:"command"`
+	Status  TransactionStatus `json:"status"`
+	// Signed is true when the transaction has been signed, even if it
+	// couldn't be sent.
+	Signed     bool       `json:"signed"`
+	TxHash     string     `json:"txHash,omitempty"`
+	ReceivedAt time.Time  `json:"receivedAt"`
+	SentAt     *time.Time `json:"sentAt,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// TransactionFilter selects the entries of the transaction history. The unset
+// fields match all the entries.
+type TransactionFilter struct {
+	Wallets []string
+	PubKeys []string
+	Command string
+	TxID    string
+	Status  TransactionStatus
+	// Since and Until bound the time the transactions have been received at,
+	// both included.
+	Since *time.Time
+	Until *time.Time
+	// Limit only keeps the latest matching entries.
+	Limit int
+}
+
+// Match verifies the record is selected by the filter. The limit is not
+// considered.
+func (f *TransactionFilter) Match(r TransactionRecord) bool {
+	if len(f.Wallets) != 0 && !containsString(f.Wallets, r.Wallet) {
+		return false
+	}
+	if len(f.PubKeys) != 0 && !containsString(f.PubKeys, r.PubKey) {
+		return false
+	}
+	if len(f.Command) != 0 && f.Command != r.Command {
+		return false
+	}
+	if len(f.TxID) != 0 && f.TxID != r.TxID {
+		return false
+	}
+	if len(f.Status) != 0 && f.Status != r.Status {
+		return false
+	}
+	if f.Since != nil && r.ReceivedAt.Before(*f.Since) {
+		return false
+	}
+	if f.Until != nil && r.ReceivedAt.After(*f.Until) {
+		return false
+	}
+	return true
+}
+
+// TransactionHistory persists the outcome of the transactions submitted to the
+// service.
+type TransactionHistory interface {
+	SaveTransaction(TransactionRecord) error
+	// ListTransactions returns the matching entries, from the oldest to the
+	// latest.
+	ListTransactions(TransactionFilter) ([]TransactionRecord, error)
+}
+
+// TransactionsResponse describes the response for ListTransactions.
+type TransactionsResponse struct {
+	Transactions []TransactionRecord `json:"transactions"`
+}
+
+func ParseListTransactionsRequest(r *http.Request) (*TransactionFilter, commands.Errors) {
+	errs := commands.NewErrors()
+	query := r.URL.Query()
+
+	filter := &TransactionFilter{
+		Wallets: splitQueryValues(query["wallet"]),
+		PubKeys: splitQueryValues(query["pubKey"]),
+		Command: query.Get("command"),
+		TxID:    query.Get("txId"),
+	}
+
+	if len(filter.Command) != 0 && !wcommands.IsCommandName(filter.Command) {
+		errs.AddForProperty("command", commands.ErrIsNotSupported)
+	}
+
+	if status := query.Get("status"); len(status) != 0 {
+		if IsTransactionStatus(status) {
+			filter.Status = TransactionStatus(status)
+		} else {
+			errs.AddForProperty("status", commands.ErrIsNotSupported)
+		}
+	}
+
+	for prop, bound := range map[string]**time.Time{"since": &filter.Since, "until": &filter.Until} {
+		value := query.Get(prop)
+		if len(value) == 0 {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			errs.AddForProperty(prop, ErrShouldBeRFC3339Time)
+			continue
+		}
+		*bound = &t
+	}
+
+	if limit := query.Get("limit"); len(limit) != 0 {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l < 0 {
+			errs.AddForProperty("limit", ErrShouldBePositiveInteger)
+		} else {
+			filter.Limit = l
+		}
+	}
+
+	if !errs.Empty() {
+		return nil, errs
+	}
+
+	return filter, nil
+}
+
+// ListTransactions returns the transaction history of the wallets and keys
+// the token gives access to.
+func (s *Service) ListTransactions(t string, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	names, scopes, err := s.auth.VerifyScopedToken(t, r.Header.Get("Origin"))
+	if err != nil {
+		s.writeForbiddenError(w, err)
+		return
+	}
+
+	filter, errs := ParseListTransactionsRequest(r)
+	if !errs.Empty() {
+		s.writeBadRequest(w, errs)
+		return
+	}
+
+	resp, status, err := s.listTransactions(names, scopes, filter)
+	if err != nil {
+		s.writeStatusError(w, err, status)
+		return
+	}
+
+	s.writeSuccess(w, resp)
+}
+
+// listTransactions restricts the filter to the wallets and keys of the token,
+// before listing the transactions. On failure, it returns the HTTP status the
+// error is reported with.
+func (s *Service) listTransactions(names []string, scopes *Scopes, filter *TransactionFilter) (*TransactionsResponse, int, error) {
+	for _, name := range filter.Wallets {
+		if !containsString(names, name) {
+			return nil, http.StatusForbidden, ErrWalletNotInSession
+		}
+	}
+	if len(filter.Wallets) == 0 {
+		filter.Wallets = names
+	}
+
+	for _, pubKey := range filter.PubKeys {
+		if err := scopes.CanSeeKey(pubKey); err != nil {
+			return nil, http.StatusForbidden, err
+		}
+	}
+	if len(filter.PubKeys) == 0 && scopes != nil {
+		filter.PubKeys = scopes.PublicKeys
+	}
+
+	resp, err := ListTransactions(s.history, *filter)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return resp, 0, nil
+}
+
+// ListTransactions returns the transaction history, from the oldest to the
+// latest transaction.
+func ListTransactions(history TransactionHistory, filter TransactionFilter) (*TransactionsResponse, error) {
+	txs, err := history.ListTransactions(filter)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list the transactions: %w", err)
+	}
+
+	return &TransactionsResponse{
+		Transactions: txs,
+	}, nil
+}
+
+// recordTransaction saves the outcome of the transaction in the history. As
+// the transaction has already been processed, a failure is only logged.
+func (s *Service) recordTransaction(name, txID string, req *walletpb.SubmitTransactionRequest, receivedAt time.Time, status TransactionStatus, sent SentTransaction) {
+	record := TransactionRecord{
+		TxID:       txID,
+		Wallet:     name,
+		PubKey:     req.GetPubKey(),
+		Command:    wcommands.CommandName(req),
+		Status:     status,
+		Signed:     sent.Tx != nil,
+		TxHash:     sent.TxHash,
+		ReceivedAt: receivedAt,
+	}
+	if !sent.SentAt.IsZero() {
+		sentAt := sent.SentAt
+		record.SentAt = &sentAt
+	}
+	if sent.Error != nil {
+		record.Error = sent.Error.Error()
+	}
+
+	if err := s.history.SaveTransaction(record); err != nil {
+		s.log.Error("couldn't save the transaction in the history",
+			zap.String("tx-id", txID),
+			zap.Error(err),
+		)
+	}
+}
+
+// splitQueryValues supports both repeated and comma-separated query values.
+func splitQueryValues(values []string) []string {
+	split := []string{}
+	for _, value := range values {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); len(v) != 0 {
+				split = append(split, v)
+			}
+		}
+	}
+	if len(split) == 0 {
+		return nil
+	}
+	return split
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,341 @@
+package service_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	api "code.vegaprotocol.io/protos/vega/api/v1"
+	commandspb "code.vegaprotocol.io/protos/vega/commands/v1"
+	vgrand "code.vegaprotocol.io/shared/libs/rand"
+	"code.vegaprotocol.io/vegawallet/service"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryTransactionHistory struct {
+	mu  sync.Mutex
+	txs []service.TransactionRecord
+}
+
+func (h *memoryTransactionHistory) SaveTransaction(tx service.TransactionRecord) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.txs = append(h.txs, tx)
+	return nil
+}
+
+func (h *memoryTransactionHistory) ListTransactions(filter service.TransactionFilter) ([]service.TransactionRecord, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	txs := []service.TransactionRecord{}
+	for _, tx := range h.txs {
+		if filter.Match(tx) {
+			txs = append(txs, tx)
+		}
+	}
+	if filter.Limit != 0 && len(txs) > filter.Limit {
+		txs = txs[len(txs)-filter.Limit:]
+	}
+	return txs, nil
+}
+
+func TestTransactionHistory(t *testing.T) {
+	t.Run("Sending a transaction records it", testSendingTransactionRecordsIt)
+	t.Run("Failing to send a transaction records it", testFailingToSendTransactionRecordsIt)
+	t.Run("Declining a transaction records it", testDecliningTransactionRecordsIt)
+	t.Run("Signing a batch records all the transactions", testSigningBatchRecordsAllTransactions)
+	t.Run("Listing transactions succeeds", testListingTransactionsSucceeds)
+	t.Run("Listing transactions of a wallet not in the token fails", testListingTransactionsOfWalletNotInTokenFails)
+	t.Run("Listing transactions with scoped token only returns its keys", testListingTransactionsWithScopedTokenOnlyReturnsItsKeys)
+	t.Run("Listing transactions with invalid filter fails", testListingTransactionsWithInvalidFilterFails)
+}
+
+func testSendingTransactionRecordsIt(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+	pubKey := vgrand.RandomStr(5)
+	payload := fmt.Sprintf(`{"pubKey": "%s", "orderCancellation": {}}`, pubKey)
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName).Times(1).Return(true)
+	s.handler.EXPECT().SignTx(walletName, gomock.Any(), uint64(42)).Times(1).Return(&commandspb.Transaction{}, nil)
+	s.nodeForward.EXPECT().LastBlockHeightAndHash(gomock.Any()).Times(1).Return(testBlockData(), 0, nil)
+	s.nodeForward.EXPECT().SendTx(gomock.Any(), gomock.Any(), api.SubmitTransactionRequest_TYPE_ASYNC, gomock.Any()).Times(1).Return("hash", nil)
+
+	// when
+	statusCode, body := serveHTTP(t, s, signTxRequest(t, payload, headers))
+
+	// then
+	require.Equal(t, http.StatusOK, statusCode)
+	resp := &service.SendTransactionResponse{}
+	require.NoError(t, json.Unmarshal(body, resp))
+	require.Len(t, s.history.txs, 1)
+	record := s.history.txs[0]
+	assert.Equal(t, resp.TxID, record.TxID)
+	assert.Equal(t, walletName, record.Wallet)
+	assert.Equal(t, pubKey, record.PubKey)
+	assert.Equal(t, "orderCancellation", record.Command)
+	assert.Equal(t, service.TransactionStatusSent, record.Status)
+	assert.True(t, record.Signed)
+	assert.Equal(t, "hash", record.TxHash)
+	assert.NotNil(t, record.SentAt)
+	assert.Empty(t, record.Error)
+}
+
+func testFailingToSendTransactionRecordsIt(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+	payload := fmt.Sprintf(`{"pubKey": "%s", "orderCancellation": {}}`, vgrand.RandomStr(5))
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName).Times(1).Return(true)
+	s.handler.EXPECT().SignTx(walletName, gomock.Any(), uint64(42)).Times(1).Return(&commandspb.Transaction{}, nil)
+	s.nodeForward.EXPECT().LastBlockHeightAndHash(gomock.Any()).Times(1).Return(testBlockData(), 0, nil)
+	s.nodeForward.EXPECT().SendTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return("", assert.AnError)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, signTxRequest(t, payload, headers))
+
+	// then
+	require.Equal(t, http.StatusInternalServerError, statusCode)
+	require.Len(t, s.history.txs, 1)
+	record := s.history.txs[0]
+	assert.Equal(t, service.TransactionStatusFailed, record.Status)
+	assert.True(t, record.Signed)
+	assert.Empty(t, record.TxHash)
+	assert.Equal(t, assert.AnError.Error(), record.Error)
+}
+
+func testDecliningTransactionRecordsIt(t *testing.T) {
+	s := getTestService(t, "manual")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+	payload := `{"pubKey": "toBeDeclined", "orderCancellation": {}}`
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName).Times(1).Return(true)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, signTxRequest(t, payload, headers))
+
+	// then
+	require.Equal(t, http.StatusUnauthorized, statusCode)
+	require.Len(t, s.history.txs, 1)
+	record := s.history.txs[0]
+	assert.Equal(t, service.TransactionStatusRejected, record.Status)
+	assert.False(t, record.Signed)
+	assert.Nil(t, record.SentAt)
+}
+
+func testSigningBatchRecordsAllTransactions(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+	payload := batchPayload(true, "pubKey1", "pubKey2", "pubKey3")
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName).Times(3).Return(true)
+	s.nodeForward.EXPECT().LastBlockHeightAndHash(gomock.Any()).Times(1).Return(testBlockData(), 0, nil)
+	gomock.InOrder(
+		s.handler.EXPECT().SignTx(walletName, gomock.Any(), uint64(42)).Times(1).Return(&commandspb.Transaction{}, nil),
+		s.handler.EXPECT().SignTx(walletName, gomock.Any(), uint64(42)).Times(1).Return(nil, assert.AnError),
+	)
+	s.nodeForward.EXPECT().SendTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return("hash1", nil)
+
+	// when
+	statusCode, body := serveHTTP(t, s, batchRequest(t, payload, headers))
+
+	// then
+	require.Equal(t, http.StatusOK, statusCode)
+	resp := unmarshalBatchResponse(t, body)
+	require.Len(t, s.history.txs, 3)
+	for i, status := range []service.TransactionStatus{
+		service.TransactionStatusSent,
+		service.TransactionStatusFailed,
+		service.TransactionStatusFailed,
+	} {
+		assert.Equal(t, resp.Results[i].TxID, s.history.txs[i].TxID)
+		assert.Equal(t, status, s.history.txs[i].Status)
+		assert.WithinDuration(t, resp.ReceivedAt, s.history.txs[i].ReceivedAt, 0)
+	}
+	assert.Equal(t, service.ErrSkippedAfterFailedTransaction.Error(), s.history.txs[2].Error)
+}
+
+func testListingTransactionsSucceeds(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+	receivedAt := time.Now().UTC().Truncate(time.Second)
+	saveTestTransactions(t, s,
+		service.TransactionRecord{TxID: "1", Wallet: walletName, Status: service.TransactionStatusSent, ReceivedAt: receivedAt},
+		service.TransactionRecord{TxID: "2", Wallet: vgrand.RandomStr(5), Status: service.TransactionStatusSent, ReceivedAt: receivedAt},
+		service.TransactionRecord{TxID: "3", Wallet: walletName, Status: service.TransactionStatusRejected, ReceivedAt: receivedAt},
+		service.TransactionRecord{TxID: "4", Wallet: walletName, Status: service.TransactionStatusSent, ReceivedAt: receivedAt.Add(time.Minute)},
+	)
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(2).Return([]string{walletName}, nil, nil)
+
+	// when
+	statusCode, body := serveHTTP(t, s, listTransactionsRequest(t, "", headers))
+
+	// then
+	require.Equal(t, http.StatusOK, statusCode)
+	assert.Equal(t, []string{"1", "3", "4"}, unmarshalTransactionIDs(t, body))
+
+	// when
+	query := fmt.Sprintf("?status=sent&until=%s", receivedAt.Format(time.RFC3339))
+	statusCode, body = serveHTTP(t, s, listTransactionsRequest(t, query, headers))
+
+	// then
+	require.Equal(t, http.StatusOK, statusCode)
+	assert.Equal(t, []string{"1"}, unmarshalTransactionIDs(t, body))
+}
+
+func testListingTransactionsOfWalletNotInTokenFails(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{vgrand.RandomStr(5)}, nil, nil)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, listTransactionsRequest(t, "?wallet="+vgrand.RandomStr(5), headers))
+
+	// then
+	assert.Equal(t, http.StatusForbidden, statusCode)
+}
+
+func testListingTransactionsWithScopedTokenOnlyReturnsItsKeys(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+	scopes := &service.Scopes{PublicKeys: []string{"pubKey1"}, ReadOnly: true}
+	saveTestTransactions(t, s,
+		service.TransactionRecord{TxID: "1", Wallet: walletName, PubKey: "pubKey1"},
+		service.TransactionRecord{TxID: "2", Wallet: walletName, PubKey: "pubKey2"},
+	)
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(2).Return([]string{walletName}, scopes, nil)
+
+	// when
+	statusCode, body := serveHTTP(t, s, listTransactionsRequest(t, "", headers))
+
+	// then
+	require.Equal(t, http.StatusOK, statusCode)
+	assert.Equal(t, []string{"1"}, unmarshalTransactionIDs(t, body))
+
+	// when
+	statusCode, _ = serveHTTP(t, s, listTransactionsRequest(t, "?pubKey=pubKey2", headers))
+
+	// then
+	assert.Equal(t, http.StatusForbidden, statusCode)
+}
+
+func testListingTransactionsWithInvalidFilterFails(t *testing.T) {
+	tcs := []struct {
+		name  string
+		query string
+	}{
+		{
+			name:  "with unsupported command",
+			query: "?command=doSomething",
+		}, {
+			name:  "with unsupported status",
+			query: "?status=pending",
+		}, {
+			name:  "with invalid time",
+			query: "?since=yesterday",
+		}, {
+			name:  "with negative limit",
+			query: "?limit=-1",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			s := getTestService(tt, "automatic")
+			defer s.ctrl.Finish()
+
+			// given
+			token := vgrand.RandomStr(5)
+			headers := authHeaders(tt, token)
+
+			// setup
+			s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{vgrand.RandomStr(5)}, nil, nil)
+
+			// when
+			statusCode, _ := serveHTTP(tt, s, listTransactionsRequest(tt, tc.query, headers))
+
+			// then
+			assert.Equal(tt, http.StatusBadRequest, statusCode)
+		})
+	}
+}
+
+func saveTestTransactions(t *testing.T, s *testService, txs ...service.TransactionRecord) {
+	t.Helper()
+	for _, tx := range txs {
+		if err := s.history.SaveTransaction(tx); err != nil {
+			t.Fatalf("couldn't save transaction: %v", err)
+		}
+	}
+}
+
+func listTransactionsRequest(t *testing.T, query string, headers map[string]string) *http.Request {
+	t.Helper()
+	return buildRequest(t, http.MethodGet, "/api/v1/transactions"+query, "", headers)
+}
+
+func unmarshalTransactionIDs(t *testing.T, body []byte) []string {
+	t.Helper()
+	resp := &service.TransactionsResponse{}
+	if err := json.Unmarshal(body, resp); err != nil {
+		t.Fatalf("couldn't unmarshal response: %v", err)
+	}
+	ids := []string{}
+	for _, tx := range resp.Transactions {
+		ids = append(ids, tx.TxID)
+	}
+	return ids
+}
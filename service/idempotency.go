@@ -0,0 +1,214 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// IdempotencyKeyHeader holds the key, chosen by the client, identifying a
+	// command submission across its retries.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on the responses returning the outcome
+	// of a previous submission, instead of a new one.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+// IdempotencyRequest identifies a command submission across its retries.
+type IdempotencyRequest struct {
+	Key string
+	// Fingerprint is the hash of the request body, so the key can't be reused
+	// for a different request.
+	Fingerprint string
+}
+
+// ReadIdempotencyRequest returns the idempotency key of the request, if set.
+// The body is read to compute its fingerprint, and restored so it can still
+// be parsed.
+func ReadIdempotencyRequest(r *http.Request) (*IdempotencyRequest, error) {
+	values, ok := r.Header[http.CanonicalHeaderKey(IdempotencyKeyHeader)]
+	if !ok {
+		return nil, nil
+	}
+
+	key := strings.TrimSpace(strings.Join(values, ","))
+	if len(key) == 0 || len(key) > maxIdempotencyKeyLength {
+		return nil, ErrIdempotencyKeyIsInvalid
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, ErrCouldNotReadRequest
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	hash := sha256.Sum256(body)
+	return &IdempotencyRequest{
+		Key:         key,
+		Fingerprint: hex.EncodeToString(hash[:]),
+	}, nil
+}
+
+// submissionOutcome is the outcome of a command submission, returned as is
+// to the repeats.
+type submissionOutcome struct {
+	Response interface{}
+	Status   int
+	Err      error
+}
+
+type idempotentSubmission struct {
+	fingerprint string
+	// outcome is nil while the submission is in progress.
+	outcome     *submissionOutcome
+	claimedAt   time.Time
+	completedAt time.Time
+}
+
+// idempotentSubmissions remembers the outcome of the command submissions
+// holding an idempotency key. They are only kept in memory, so they don't
+// outlive the service.
+type idempotentSubmissions struct {
+	mu          sync.Mutex
+	submissions map[string]*idempotentSubmission
+}
+
+func newIdempotentSubmissions() *idempotentSubmissions {
+	return &idempotentSubmissions{
+		submissions: map[string]*idempotentSubmission{},
+	}
+}
+
+// Claim reserves the key for the submission. If the key has already been
+// used for the same request within the window, the outcome of the previous
+// submission is returned instead.
+func (s *idempotentSubmissions) Claim(id string, fingerprint string, window time.Duration, now time.Time) (*submissionOutcome, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeExpiredSubmissions(window, now)
+
+	submission, ok := s.submissions[id]
+	if !ok {
+		s.submissions[id] = &idempotentSubmission{
+			fingerprint: fingerprint,
+			claimedAt:   now,
+		}
+		return nil, 0, nil
+	}
+
+	if submission.fingerprint != fingerprint {
+		return nil, http.StatusUnprocessableEntity, ErrIdempotencyKeyReused
+	}
+
+	if submission.outcome == nil {
+		return nil, http.StatusConflict, ErrIdempotentRequestInProgress
+	}
+
+	return submission.outcome, 0, nil
+}
+
+// Complete records the outcome of the submission, so it can be returned to
+// the repeats until the window ends. An interrupted submission isn't an
+// outcome the client should get back, so its claim is released instead, and
+// the client can retry.
+func (s *idempotentSubmissions) Complete(id string, outcome *submissionOutcome, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	submission, ok := s.submissions[id]
+	if !ok {
+		return
+	}
+	if isInterruption(outcome.Err) {
+		delete(s.submissions, id)
+		return
+	}
+	submission.outcome = outcome
+	submission.completedAt = now
+}
+
+// removeExpiredSubmissions forgets the completed submissions whose window has
+// ended, and the submissions still in progress once the window has ended since
+// their claim, so an abandoned claim doesn't hold the key forever. The lock
+// has to be held.
+func (s *idempotentSubmissions) removeExpiredSubmissions(window time.Duration, now time.Time) {
+	for id, submission := range s.submissions {
+		since := submission.claimedAt
+		if submission.outcome != nil {
+			since = submission.completedAt
+		}
+		if now.Sub(since) >= window {
+			delete(s.submissions, id)
+		}
+	}
+}
+
+// isInterruption tells whether the submission stopped because the request was
+// cancelled, rather than because it failed.
+func isInterruption(err error) bool {
+	return errors.Is(err, ErrInterruptedConsentRequest) ||
+		errors.Is(err, context.Canceled) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
+
+// idempotentSubmissionID scopes the key to the wallets of the token and to
+// the endpoint, so the clients can't read each other's outcomes.
+func idempotentSubmissionID(names []string, path, key string) string {
+	wallets := append([]string{}, names...)
+	sort.Strings(wallets)
+	return strings.Join(wallets, ",") + "\n" + path + "\n" + key
+}
+
+// submitOnce runs the submission, unless the idempotency key has already been
+// used for the same request within the window. The outcome of the previous
+// submission is then written, instead of signing and sending again.
+func (s *Service) submitOnce(w http.ResponseWriter, r *http.Request, names []string, idempotency *IdempotencyRequest, submit func() (interface{}, int, error)) {
+	if idempotency == nil {
+		resp, status, err := submit()
+		s.writeSubmissionOutcome(w, &submissionOutcome{Response: resp, Status: status, Err: err})
+		return
+	}
+
+	id := idempotentSubmissionID(names, r.URL.Path, idempotency.Key)
+	previous, status, err := s.idempotency.Claim(id, idempotency.Fingerprint, s.idempotencyWindow(), time.Now())
+	if err != nil {
+		s.writeStatusError(w, err, status)
+		return
+	}
+	if previous != nil {
+		w.Header().Set(IdempotentReplayedHeader, "true")
+		s.writeSubmissionOutcome(w, previous)
+		return
+	}
+
+	resp, status, err := submit()
+	outcome := &submissionOutcome{Response: resp, Status: status, Err: err}
+	s.idempotency.Complete(id, outcome, time.Now())
+	s.writeSubmissionOutcome(w, outcome)
+}
+
+func (s *Service) writeSubmissionOutcome(w http.ResponseWriter, outcome *submissionOutcome) {
+	if outcome.Err != nil {
+		s.writeStatusError(w, outcome.Err, outcome.Status)
+		return
+	}
+	s.writeSuccess(w, outcome.Response)
+}
+
+func (s *Service) idempotencyWindow() time.Duration {
+	s.networkMu.RLock()
+	defer s.networkMu.RUnlock()
+
+	return s.network.GetIdempotencyWindow()
+}
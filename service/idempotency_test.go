@@ -0,0 +1,290 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	api "code.vegaprotocol.io/protos/vega/api/v1"
+	commandspb "code.vegaprotocol.io/protos/vega/commands/v1"
+	vgrand "code.vegaprotocol.io/shared/libs/rand"
+	"code.vegaprotocol.io/vegawallet/network"
+	"code.vegaprotocol.io/vegawallet/service"
+	"code.vegaprotocol.io/vegawallet/service/encoding"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotency(t *testing.T) {
+	t.Run("Repeating a submission returns the previous outcome", testRepeatingSubmissionReturnsPreviousOutcome)
+	t.Run("Repeating a failed submission returns the previous error", testRepeatingFailedSubmissionReturnsPreviousError)
+	t.Run("Reusing a key for a different request fails", testReusingKeyForDifferentRequestFails)
+	t.Run("Repeating a submission after the window sends it again", testRepeatingSubmissionAfterWindowSendsItAgain)
+	t.Run("Repeating an interrupted submission sends it again", testRepeatingInterruptedSubmissionSendsItAgain)
+	t.Run("Repeating a submission in progress after the window sends it again", testRepeatingSubmissionInProgressAfterWindowSendsItAgain)
+	t.Run("Submitting with an invalid key fails", testSubmittingWithInvalidKeyFails)
+}
+
+func testRepeatingSubmissionReturnsPreviousOutcome(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := idempotentHeaders(t, token, vgrand.RandomStr(10))
+	payload := fmt.Sprintf(`{"pubKey": "%s", "orderCancellation": {}}`, vgrand.RandomStr(5))
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(2).Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName).Times(1).Return(true)
+	s.handler.EXPECT().SignTx(walletName, gomock.Any(), uint64(42)).Times(1).Return(&commandspb.Transaction{}, nil)
+	s.nodeForward.EXPECT().LastBlockHeightAndHash(gomock.Any()).Times(1).Return(testBlockData(), 0, nil)
+	s.nodeForward.EXPECT().SendTx(gomock.Any(), gomock.Any(), api.SubmitTransactionRequest_TYPE_ASYNC, gomock.Any()).Times(1).Return("hash", nil)
+
+	// when
+	statusCode, body := serveHTTP(t, s, signTxRequest(t, payload, headers))
+
+	// then
+	require.Equal(t, http.StatusOK, statusCode)
+	first := &service.SendTransactionResponse{}
+	require.NoError(t, json.Unmarshal(body, first))
+
+	// when
+	w := serveIdempotentHTTP(t, s, signTxRequest(t, payload, headers))
+
+	// then
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "true", w.Header().Get(service.IdempotentReplayedHeader))
+	repeated := &service.SendTransactionResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), repeated))
+	assert.Equal(t, first.TxID, repeated.TxID)
+	assert.Equal(t, "hash", repeated.TxHash)
+	assert.Len(t, s.history.txs, 1)
+}
+
+func testRepeatingFailedSubmissionReturnsPreviousError(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := idempotentHeaders(t, token, vgrand.RandomStr(10))
+	payload := fmt.Sprintf(`{"pubKey": "%s", "orderCancellation": {}}`, vgrand.RandomStr(5))
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(2).Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName).Times(1).Return(true)
+	s.handler.EXPECT().SignTx(walletName, gomock.Any(), uint64(42)).Times(1).Return(&commandspb.Transaction{}, nil)
+	s.nodeForward.EXPECT().LastBlockHeightAndHash(gomock.Any()).Times(1).Return(testBlockData(), 0, nil)
+	s.nodeForward.EXPECT().SendTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return("", assert.AnError)
+
+	// when
+	statusCode, body := serveHTTP(t, s, signTxRequest(t, payload, headers))
+
+	// then
+	require.Equal(t, http.StatusInternalServerError, statusCode)
+
+	// when
+	w := serveIdempotentHTTP(t, s, signTxRequest(t, payload, headers))
+
+	// then
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "true", w.Header().Get(service.IdempotentReplayedHeader))
+	assert.Equal(t, body, w.Body.Bytes())
+}
+
+func testReusingKeyForDifferentRequestFails(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := idempotentHeaders(t, token, vgrand.RandomStr(10))
+	pubKey := vgrand.RandomStr(5)
+	payload := fmt.Sprintf(`{"pubKey": "%s", "orderCancellation": {}}`, pubKey)
+	otherPayload := fmt.Sprintf(`{"pubKey": "%s", "orderCancellation": {"marketId": "%s"}}`, pubKey, vgrand.RandomStr(5))
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(2).Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName).Times(1).Return(true)
+	s.handler.EXPECT().SignTx(walletName, gomock.Any(), uint64(42)).Times(1).Return(&commandspb.Transaction{}, nil)
+	s.nodeForward.EXPECT().LastBlockHeightAndHash(gomock.Any()).Times(1).Return(testBlockData(), 0, nil)
+	s.nodeForward.EXPECT().SendTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return("hash", nil)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, signTxRequest(t, payload, headers))
+
+	// then
+	require.Equal(t, http.StatusOK, statusCode)
+
+	// when
+	statusCode, body := serveHTTP(t, s, signTxRequest(t, otherPayload, headers))
+
+	// then
+	require.Equal(t, http.StatusUnprocessableEntity, statusCode)
+	assert.Contains(t, string(body), service.ErrIdempotencyKeyReused.Error())
+}
+
+func testRepeatingSubmissionAfterWindowSendsItAgain(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := idempotentHeaders(t, token, vgrand.RandomStr(10))
+	payload := fmt.Sprintf(`{"pubKey": "%s", "orderCancellation": {}}`, vgrand.RandomStr(5))
+
+	// setup
+	s.UpdateNetwork(&network.Network{
+		IdempotencyWindow: encoding.Duration{Duration: time.Nanosecond},
+	})
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(2).Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName).Times(2).Return(true)
+	s.handler.EXPECT().SignTx(walletName, gomock.Any(), uint64(42)).Times(2).Return(&commandspb.Transaction{}, nil)
+	s.nodeForward.EXPECT().LastBlockHeightAndHash(gomock.Any()).Times(2).Return(testBlockData(), 0, nil)
+	s.nodeForward.EXPECT().SendTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2).Return("hash", nil)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, signTxRequest(t, payload, headers))
+
+	// then
+	require.Equal(t, http.StatusOK, statusCode)
+
+	// when
+	time.Sleep(time.Millisecond)
+	w := serveIdempotentHTTP(t, s, signTxRequest(t, payload, headers))
+
+	// then
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get(service.IdempotentReplayedHeader))
+	assert.Len(t, s.history.txs, 2)
+}
+
+func testRepeatingInterruptedSubmissionSendsItAgain(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := idempotentHeaders(t, token, vgrand.RandomStr(10))
+	payload := fmt.Sprintf(`{"pubKey": "%s", "orderCancellation": {}}`, vgrand.RandomStr(5))
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(2).Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName).Times(2).Return(true)
+	s.handler.EXPECT().SignTx(walletName, gomock.Any(), uint64(42)).Times(2).Return(&commandspb.Transaction{}, nil)
+	s.nodeForward.EXPECT().LastBlockHeightAndHash(gomock.Any()).Times(2).Return(testBlockData(), 0, nil)
+	gomock.InOrder(
+		s.nodeForward.EXPECT().SendTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return("", context.Canceled),
+		s.nodeForward.EXPECT().SendTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return("hash", nil),
+	)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, signTxRequest(t, payload, headers))
+
+	// then
+	require.Equal(t, http.StatusInternalServerError, statusCode)
+
+	// when
+	w := serveIdempotentHTTP(t, s, signTxRequest(t, payload, headers))
+
+	// then
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get(service.IdempotentReplayedHeader))
+}
+
+func testRepeatingSubmissionInProgressAfterWindowSendsItAgain(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := idempotentHeaders(t, token, vgrand.RandomStr(10))
+	payload := fmt.Sprintf(`{"pubKey": "%s", "orderCancellation": {}}`, vgrand.RandomStr(5))
+	sending := make(chan struct{})
+	release := make(chan struct{})
+
+	// setup
+	s.UpdateNetwork(&network.Network{
+		IdempotencyWindow: encoding.Duration{Duration: time.Millisecond},
+	})
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(2).Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName).Times(2).Return(true)
+	s.handler.EXPECT().SignTx(walletName, gomock.Any(), uint64(42)).Times(2).Return(&commandspb.Transaction{}, nil)
+	s.nodeForward.EXPECT().LastBlockHeightAndHash(gomock.Any()).Times(2).Return(testBlockData(), 0, nil)
+	gomock.InOrder(
+		s.nodeForward.EXPECT().SendTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
+			func(_ context.Context, _ *commandspb.Transaction, _ api.SubmitTransactionRequest_Type, _ int) (string, error) {
+				close(sending)
+				<-release
+				return "hash", nil
+			},
+		),
+		s.nodeForward.EXPECT().SendTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return("hash", nil),
+	)
+
+	// when
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		serveIdempotentHTTP(t, s, signTxRequest(t, payload, headers))
+	}()
+	<-sending
+	time.Sleep(2 * time.Millisecond)
+	w := serveIdempotentHTTP(t, s, signTxRequest(t, payload, headers))
+	close(release)
+	<-done
+
+	// then
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get(service.IdempotentReplayedHeader))
+}
+
+func testSubmittingWithInvalidKeyFails(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := idempotentHeaders(t, token, vgrand.RandomStr(256))
+	payload := fmt.Sprintf(`{"pubKey": "%s", "orderCancellation": {}}`, vgrand.RandomStr(5))
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
+
+	// when
+	statusCode, body := serveHTTP(t, s, signTxRequest(t, payload, headers))
+
+	// then
+	require.Equal(t, http.StatusBadRequest, statusCode)
+	assert.Contains(t, string(body), service.ErrIdempotencyKeyIsInvalid.Error())
+	assert.Empty(t, s.history.txs)
+}
+
+func idempotentHeaders(t *testing.T, token, key string) map[string]string {
+	t.Helper()
+	headers := authHeaders(t, token)
+	headers[service.IdempotencyKeyHeader] = key
+	return headers
+}
+
+// serveIdempotentHTTP serves the request and returns the recorder, so the
+// headers of the response can be verified.
+func serveIdempotentHTTP(t *testing.T, s *testService, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	return w
+}
@@ -0,0 +1,653 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+
+	"code.vegaprotocol.io/protos/commands"
+	"code.vegaprotocol.io/vegawallet/wallet"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+const JSONRPCVersion = "2.0"
+
+// The error codes defined by the JSON-RPC 2.0 specification.
+const (
+	JSONRPCErrorCodeParseError     = -32700
+	JSONRPCErrorCodeInvalidRequest = -32600
+	JSONRPCErrorCodeMethodNotFound = -32601
+	JSONRPCErrorCodeInvalidParams  = -32602
+	JSONRPCErrorCodeInternalError  = -32603
+)
+
+// The error codes of the wallet, in the range the JSON-RPC 2.0 specification
+// reserves to the implementations.
+const (
+	// JSONRPCErrorCodeAccessDenied is returned when the token is missing or
+	// invalid, when its scopes don't allow the operation, or when the
+	// passphrase is wrong.
+	JSONRPCErrorCodeAccessDenied = -32001
+	// JSONRPCErrorCodeRequestRejected is returned when the user rejected the
+	// transaction.
+	JSONRPCErrorCodeRequestRejected = -32002
+	// JSONRPCErrorCodeConsentUnavailable is returned when the consent of the
+	// user couldn't be asked.
+	JSONRPCErrorCodeConsentUnavailable = -32003
+	// JSONRPCErrorCodeNotFound is returned when the public key doesn't exist.
+	JSONRPCErrorCodeNotFound = -32004
+)
+
+// JSONRPCRequest is a JSON-RPC 2.0 request. A request without ID is a
+// notification, and doesn't get any response.
+type JSONRPCRequest struct {
+	Version string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+func (r *JSONRPCRequest) IsNotification() bool {
+	return len(r.ID) == 0
+}
+
+// JSONRPCResponse is a JSON-RPC 2.0 response. Either the result or the error
+// is set.
+type JSONRPCResponse struct {
+	Version string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *JSONRPCError   `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+type JSONRPCError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (e *JSONRPCError) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+func newJSONRPCError(code int, err error) *JSONRPCError {
+	return &JSONRPCError{
+		Code:    code,
+		Message: err.Error(),
+	}
+}
+
+// JSONRPCMethod describes a method of the JSON-RPC API.
+type JSONRPCMethod struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// RequiresToken is true when the token, or the API key, has to be set in
+	// the Authorization header.
+	RequiresToken bool `json:"requiresToken"`
+}
+
+// JSONRPCMethodsResponse describes the response for ListJSONRPCMethods, and
+// the result of "rpc.methods".
+type JSONRPCMethodsResponse struct {
+	Methods []JSONRPCMethod `json:"methods"`
+}
+
+// SignMessageResult is the result of "message.sign".
+type SignMessageResult struct {
+	// Signature is base64-encoded, like the signed data.
+	Signature string `json:"signature"`
+}
+
+// VerifyMessageResult is the result of "message.verify".
+type VerifyMessageResult struct {
+	Valid bool `json:"valid"`
+}
+
+// SendCommandParams are the parameters of "command.send".
+type SendCommandParams struct {
+	// SendingMode is the way the transaction is sent to the node:
+	// "TYPE_ASYNC", "TYPE_SYNC" or "TYPE_COMMIT". It defaults to "TYPE_ASYNC".
+	SendingMode string `json:"sendingMode,omitempty"`
+	// Transaction is the transaction request, as sent to the REST API.
+	Transaction json.RawMessage `json:"transaction"`
+}
+
+// KeyParams are the parameters of the methods targeting a key pair.
+type KeyParams struct {
+	PubKey string `json:"pubKey"`
+	// Passphrase is required to taint and to annotate the key pair.
+	Passphrase string `json:"passphrase,omitempty"`
+	// Meta replaces the metadata of the key pair, when annotating it.
+	Meta []wallet.Meta `json:"meta,omitempty"`
+}
+
+// jsonRPCCall is a request to a method, with the credentials of the HTTP
+// request.
+type jsonRPCCall struct {
+	ctx    context.Context
+	token  string
+	origin string
+	params json.RawMessage
+}
+
+type jsonRPCHandler func(*jsonRPCCall) (interface{}, *JSONRPCError)
+
+type jsonRPCMethod struct {
+	JSONRPCMethod
+	handle jsonRPCHandler
+}
+
+func (s *Service) registerJSONRPCMethods() {
+	s.rpcMethods = map[string]jsonRPCMethod{}
+
+	register := func(name, description string, requiresToken bool, handle jsonRPCHandler) {
+		s.rpcMethods[name] = jsonRPCMethod{
+			JSONRPCMethod: JSONRPCMethod{
+				Name:          name,
+				Description:   description,
+				RequiresToken: requiresToken,
+			},
+			handle: handle,
+		}
+	}
+
+	register("rpc.methods", "List the methods of the API", false, s.rpcListMethods)
+
+	register("session.login", "Unlock one or several wallets, and open a session on them", false, s.rpcLogin)
+	register("session.logout", "Close the session of the token", true, s.rpcLogout)
+	register("session.describe", "Describe the session of the token", true, s.rpcDescribeSession)
+	register("session.refresh", "Issue a new token for the session", true, s.rpcRefreshSession)
+	register("session.list", "List the sessions of the wallets of the token", true, s.rpcListSessions)
+	register("session.revokeAll", "Revoke all the sessions of the wallets of the token", true, s.rpcRevokeSessions)
+	register("session.unlockWallet", "Unlock a wallet of the session, locked for inactivity", true, s.rpcUnlockWallet)
+
+	register("keys.list", "List the public keys of the wallets of the token", true, s.rpcListKeys)
+	register("keys.get", "Describe a public key", true, s.rpcGetKey)
+	register("keys.generate", "Generate a key pair", true, s.rpcGenerateKey)
+	register("keys.taint", "Taint a key pair", true, s.rpcTaintKey)
+	register("keys.annotate", "Replace the metadata of a key pair", true, s.rpcAnnotateKey)
+
+	register("command.send", "Sign a command, and send it to the network", true, s.rpcSendCommand)
+	register("command.check", "Sign a command, and check it against the network without sending it", true, s.rpcCheckCommand)
+
+	register("message.sign", "Sign base64-encoded data", true, s.rpcSignMessage)
+	register("message.verify", "Verify the signature of base64-encoded data", false, s.rpcVerifyMessage)
+
+	register("network.get", "Describe the network configuration of the service", false, s.rpcGetNetwork)
+}
+
+// ListJSONRPCMethods lists the methods of the JSON-RPC API, so the clients
+// can discover them.
+func (s *Service) ListJSONRPCMethods(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	s.writeSuccess(w, s.jsonRPCMethods())
+}
+
+func (s *Service) jsonRPCMethods() JSONRPCMethodsResponse {
+	methods := make([]JSONRPCMethod, 0, len(s.rpcMethods))
+	for _, method := range s.rpcMethods {
+		methods = append(methods, method.JSONRPCMethod)
+	}
+	sort.Slice(methods, func(i, j int) bool {
+		return methods[i].Name < methods[j].Name
+	})
+	return JSONRPCMethodsResponse{Methods: methods}
+}
+
+// HandleJSONRPC serves the JSON-RPC 2.0 API. The body is either a single
+// request, or a batch of requests, answered in a single array.
+func (s *Service) HandleJSONRPC(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	defer r.Body.Close()
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		s.writeJSONRPC(w, newJSONRPCErrorResponse(nil, newJSONRPCError(JSONRPCErrorCodeParseError, ErrCouldNotReadRequest)))
+		return
+	}
+
+	token, _ := bearerToken(r)
+	origin := r.Header.Get("Origin")
+
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) != 0 && trimmed[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(trimmed, &batch); err != nil {
+			s.writeJSONRPC(w, newJSONRPCErrorResponse(nil, newJSONRPCError(JSONRPCErrorCodeParseError, err)))
+			return
+		}
+		if len(batch) == 0 {
+			s.writeJSONRPC(w, newJSONRPCErrorResponse(nil, newJSONRPCError(JSONRPCErrorCodeInvalidRequest, ErrJSONRPCBatchIsEmpty)))
+			return
+		}
+
+		responses := make([]*JSONRPCResponse, 0, len(batch))
+		for _, raw := range batch {
+			if resp := s.handleJSONRPCRequest(r.Context(), token, origin, raw); resp != nil {
+				responses = append(responses, resp)
+			}
+		}
+		if len(responses) == 0 {
+			// A batch of notifications doesn't get any response.
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		s.writeJSONRPC(w, responses)
+		return
+	}
+
+	resp := s.handleJSONRPCRequest(r.Context(), token, origin, trimmed)
+	if resp == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	s.writeJSONRPC(w, resp)
+}
+
+// handleJSONRPCRequest calls the method of the request. It returns nil if the
+// request is a notification.
+func (s *Service) handleJSONRPCRequest(ctx context.Context, token, origin string, raw json.RawMessage) *JSONRPCResponse {
+	req := &JSONRPCRequest{}
+	if err := json.Unmarshal(raw, req); err != nil {
+		return newJSONRPCErrorResponse(nil, newJSONRPCError(JSONRPCErrorCodeParseError, err))
+	}
+
+	if req.Version != JSONRPCVersion || len(req.Method) == 0 {
+		return newJSONRPCErrorResponse(req.ID, newJSONRPCError(JSONRPCErrorCodeInvalidRequest, ErrInvalidJSONRPCRequest))
+	}
+
+	result, rpcErr := s.callJSONRPCMethod(&jsonRPCCall{
+		ctx:    ctx,
+		token:  token,
+		origin: origin,
+		params: req.Params,
+	}, req.Method)
+
+	if req.IsNotification() {
+		return nil
+	}
+
+	if rpcErr != nil {
+		s.log.Info("JSON-RPC method failed", zap.String("method", req.Method), zap.Int("code", rpcErr.Code))
+		return newJSONRPCErrorResponse(req.ID, rpcErr)
+	}
+
+	buf, err := json.Marshal(result)
+	if err != nil {
+		s.log.Error("couldn't marshal JSON-RPC result", zap.String("method", req.Method), zap.Error(err))
+		return newJSONRPCErrorResponse(req.ID, newJSONRPCError(JSONRPCErrorCodeInternalError, err))
+	}
+
+	return &JSONRPCResponse{
+		Version: JSONRPCVersion,
+		Result:  buf,
+		ID:      req.ID,
+	}
+}
+
+func (s *Service) callJSONRPCMethod(call *jsonRPCCall, name string) (interface{}, *JSONRPCError) {
+	method, ok := s.rpcMethods[name]
+	if !ok {
+		return nil, newJSONRPCError(JSONRPCErrorCodeMethodNotFound, fmt.Errorf("%w: %s", ErrJSONRPCMethodNotFound, name))
+	}
+
+	if method.RequiresToken && len(call.token) == 0 {
+		return nil, newJSONRPCError(JSONRPCErrorCodeAccessDenied, ErrInvalidOrMissingToken)
+	}
+
+	s.log.Info(fmt.Sprintf("Calling JSON-RPC method %s", name))
+	return method.handle(call)
+}
+
+func (s *Service) writeJSONRPC(w http.ResponseWriter, data interface{}) {
+	// The errors are part of the JSON-RPC response, so the status is always
+	// 200.
+	s.writeSuccess(w, data)
+}
+
+func newJSONRPCErrorResponse(id json.RawMessage, rpcErr *JSONRPCError) *JSONRPCResponse {
+	return &JSONRPCResponse{
+		Version: JSONRPCVersion,
+		Error:   rpcErr,
+		ID:      id,
+	}
+}
+
+// jsonRPCErrorFromStatus converts the error of an operation shared with the
+// REST API, using the HTTP status it's reported with.
+func jsonRPCErrorFromStatus(err error, status int) *JSONRPCError {
+	switch status {
+	case http.StatusForbidden:
+		return newJSONRPCError(JSONRPCErrorCodeAccessDenied, err)
+	case http.StatusUnauthorized:
+		return newJSONRPCError(JSONRPCErrorCodeRequestRejected, err)
+	case http.StatusServiceUnavailable:
+		return newJSONRPCError(JSONRPCErrorCodeConsentUnavailable, err)
+	case http.StatusNotFound:
+		return newJSONRPCError(JSONRPCErrorCodeNotFound, err)
+	default:
+		return newJSONRPCError(JSONRPCErrorCodeInternalError, err)
+	}
+}
+
+func jsonRPCInvalidParams(errs commands.Errors) *JSONRPCError {
+	return &JSONRPCError{
+		Code:    JSONRPCErrorCodeInvalidParams,
+		Message: "invalid params",
+		Data:    errs,
+	}
+}
+
+// decodeParams decodes the parameters, as a JSON object, into the request.
+// Missing parameters leave the request empty.
+func decodeParams(params json.RawMessage, into interface{}) *JSONRPCError {
+	trimmed := bytes.TrimSpace(params)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return nil
+	}
+	if trimmed[0] != '{' {
+		return newJSONRPCError(JSONRPCErrorCodeInvalidParams, ErrJSONRPCParamsMustBeObject)
+	}
+	if err := json.Unmarshal(trimmed, into); err != nil {
+		return newJSONRPCError(JSONRPCErrorCodeInvalidParams, err)
+	}
+	return nil
+}
+
+func (s *Service) verifyCall(call *jsonRPCCall) ([]string, *Scopes, *JSONRPCError) {
+	names, scopes, err := s.auth.VerifyScopedToken(call.token, call.origin)
+	if err != nil {
+		return nil, nil, newJSONRPCError(JSONRPCErrorCodeAccessDenied, err)
+	}
+	return names, scopes, nil
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	token := strings.TrimSpace(r.Header.Get("Authorization"))
+	if !strings.HasPrefix(token, jwtBearer) {
+		return "", false
+	}
+	return strings.TrimSpace(token[len(jwtBearer):]), true
+}
+
+func (s *Service) rpcListMethods(_ *jsonRPCCall) (interface{}, *JSONRPCError) {
+	return s.jsonRPCMethods(), nil
+}
+
+func (s *Service) rpcLogin(call *jsonRPCCall) (interface{}, *JSONRPCError) {
+	req := &LoginWalletRequest{}
+	if rpcErr := decodeParams(call.params, req); rpcErr != nil {
+		return nil, rpcErr
+	}
+	req, errs := checkLoginWalletRequest(req)
+	if !errs.Empty() {
+		return nil, jsonRPCInvalidParams(errs)
+	}
+
+	resp, status, err := s.login(req, call.origin)
+	if err != nil {
+		return nil, jsonRPCErrorFromStatus(err, status)
+	}
+	return resp, nil
+}
+
+func (s *Service) rpcLogout(call *jsonRPCCall) (interface{}, *JSONRPCError) {
+	if _, err := s.auth.Revoke(call.token, call.origin); err != nil {
+		return nil, newJSONRPCError(JSONRPCErrorCodeAccessDenied, err)
+	}
+	return nil, nil
+}
+
+func (s *Service) rpcDescribeSession(call *jsonRPCCall) (interface{}, *JSONRPCError) {
+	info, err := s.introspectToken(call.token, call.origin)
+	if err != nil {
+		return nil, newJSONRPCError(JSONRPCErrorCodeAccessDenied, err)
+	}
+	return info, nil
+}
+
+func (s *Service) rpcRefreshSession(call *jsonRPCCall) (interface{}, *JSONRPCError) {
+	token, err := s.auth.RefreshToken(call.token, call.origin)
+	if err != nil {
+		return nil, newJSONRPCError(JSONRPCErrorCodeAccessDenied, err)
+	}
+	return TokenResponse{Token: token}, nil
+}
+
+func (s *Service) rpcListSessions(call *jsonRPCCall) (interface{}, *JSONRPCError) {
+	sessions, err := s.auth.ListWalletSessions(call.token, call.origin)
+	if err != nil {
+		return nil, newJSONRPCError(JSONRPCErrorCodeAccessDenied, err)
+	}
+	return SessionsResponse{Sessions: sessions}, nil
+}
+
+func (s *Service) rpcRevokeSessions(call *jsonRPCCall) (interface{}, *JSONRPCError) {
+	if err := s.revokeSessions(call.token, call.origin); err != nil {
+		return nil, newJSONRPCError(JSONRPCErrorCodeAccessDenied, err)
+	}
+	return nil, nil
+}
+
+func (s *Service) rpcUnlockWallet(call *jsonRPCCall) (interface{}, *JSONRPCError) {
+	req := &UnlockWalletRequest{}
+	if rpcErr := decodeParams(call.params, req); rpcErr != nil {
+		return nil, rpcErr
+	}
+	req, errs := checkUnlockWalletRequest(req)
+	if !errs.Empty() {
+		return nil, jsonRPCInvalidParams(errs)
+	}
+
+	names, scopes, rpcErr := s.verifyCall(call)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	if status, err := s.unlockWallet(names, scopes, req); err != nil {
+		return nil, jsonRPCErrorFromStatus(err, status)
+	}
+	return nil, nil
+}
+
+func (s *Service) rpcListKeys(call *jsonRPCCall) (interface{}, *JSONRPCError) {
+	names, scopes, rpcErr := s.verifyCall(call)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	resp, err := s.listPublicKeys(names, scopes)
+	if err != nil {
+		return nil, newJSONRPCError(JSONRPCErrorCodeInternalError, err)
+	}
+	return resp, nil
+}
+
+func (s *Service) rpcGetKey(call *jsonRPCCall) (interface{}, *JSONRPCError) {
+	params := &KeyParams{}
+	if rpcErr := decodeParams(call.params, params); rpcErr != nil {
+		return nil, rpcErr
+	}
+	if len(params.PubKey) == 0 {
+		return nil, jsonRPCInvalidParams(commands.NewErrors().FinalAddForProperty("pubKey", commands.ErrIsRequired))
+	}
+
+	names, scopes, rpcErr := s.verifyCall(call)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	resp, status, err := s.getPublicKey(names, scopes, params.PubKey)
+	if err != nil {
+		return nil, jsonRPCErrorFromStatus(err, status)
+	}
+	return resp, nil
+}
+
+func (s *Service) rpcGenerateKey(call *jsonRPCCall) (interface{}, *JSONRPCError) {
+	req := &GenKeyPairRequest{}
+	if rpcErr := decodeParams(call.params, req); rpcErr != nil {
+		return nil, rpcErr
+	}
+	req, errs := checkGenKeyPairRequest(req)
+	if !errs.Empty() {
+		return nil, jsonRPCInvalidParams(errs)
+	}
+
+	names, scopes, rpcErr := s.verifyCall(call)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	resp, status, err := s.generateKeyPair(names, scopes, req)
+	if err != nil {
+		return nil, jsonRPCErrorFromStatus(err, status)
+	}
+	return resp, nil
+}
+
+func (s *Service) rpcTaintKey(call *jsonRPCCall) (interface{}, *JSONRPCError) {
+	params := &KeyParams{}
+	if rpcErr := decodeParams(call.params, params); rpcErr != nil {
+		return nil, rpcErr
+	}
+	req, errs := checkTaintKeyRequest(&TaintKeyRequest{Passphrase: params.Passphrase}, params.PubKey)
+	if !errs.Empty() {
+		return nil, jsonRPCInvalidParams(errs)
+	}
+
+	names, scopes, rpcErr := s.verifyCall(call)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	if status, err := s.taintKey(names, scopes, params.PubKey, req); err != nil {
+		return nil, jsonRPCErrorFromStatus(err, status)
+	}
+	return nil, nil
+}
+
+func (s *Service) rpcAnnotateKey(call *jsonRPCCall) (interface{}, *JSONRPCError) {
+	params := &KeyParams{}
+	if rpcErr := decodeParams(call.params, params); rpcErr != nil {
+		return nil, rpcErr
+	}
+	req, errs := checkUpdateMetaRequest(&UpdateMetaRequest{
+		Passphrase: params.Passphrase,
+		Meta:       params.Meta,
+	}, params.PubKey)
+	if !errs.Empty() {
+		return nil, jsonRPCInvalidParams(errs)
+	}
+
+	names, scopes, rpcErr := s.verifyCall(call)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	if status, err := s.updateMeta(names, scopes, params.PubKey, req); err != nil {
+		return nil, jsonRPCErrorFromStatus(err, status)
+	}
+	return nil, nil
+}
+
+func (s *Service) rpcSendCommand(call *jsonRPCCall) (interface{}, *JSONRPCError) {
+	params := &SendCommandParams{}
+	if rpcErr := decodeParams(call.params, params); rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	ty, ok := parseSendingMode(params.SendingMode)
+	if !ok {
+		return nil, jsonRPCInvalidParams(commands.NewErrors().FinalAddForProperty("sendingMode", commands.ErrIsNotSupported))
+	}
+
+	req, errs := parseSubmitTransactionRequest(bytes.NewReader(params.Transaction))
+	if !errs.Empty() {
+		return nil, jsonRPCInvalidParams(errs)
+	}
+
+	names, scopes, rpcErr := s.verifyCall(call)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	resp, status, err := s.sendTransaction(call.ctx, names, scopes, req, ty)
+	if err != nil {
+		return nil, jsonRPCErrorFromStatus(err, status)
+	}
+	return resp, nil
+}
+
+func (s *Service) rpcCheckCommand(call *jsonRPCCall) (interface{}, *JSONRPCError) {
+	params := &SendCommandParams{}
+	if rpcErr := decodeParams(call.params, params); rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	req, errs := parseSubmitTransactionRequest(bytes.NewReader(params.Transaction))
+	if !errs.Empty() {
+		return nil, jsonRPCInvalidParams(errs)
+	}
+
+	names, scopes, rpcErr := s.verifyCall(call)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	resp, status, err := s.checkTransaction(call.ctx, names, scopes, req)
+	if err != nil {
+		return nil, jsonRPCErrorFromStatus(err, status)
+	}
+	return resp, nil
+}
+
+func (s *Service) rpcSignMessage(call *jsonRPCCall) (interface{}, *JSONRPCError) {
+	req := &SignAnyRequest{}
+	if rpcErr := decodeParams(call.params, req); rpcErr != nil {
+		return nil, rpcErr
+	}
+	req, errs := checkSignAnyRequest(req)
+	if !errs.Empty() {
+		return nil, jsonRPCInvalidParams(errs)
+	}
+
+	names, scopes, rpcErr := s.verifyCall(call)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	resp, status, err := s.signAny(names, scopes, req)
+	if err != nil {
+		return nil, jsonRPCErrorFromStatus(err, status)
+	}
+	return SignMessageResult{Signature: resp.Base64Signature}, nil
+}
+
+func (s *Service) rpcVerifyMessage(call *jsonRPCCall) (interface{}, *JSONRPCError) {
+	req := &VerifyAnyRequest{}
+	if rpcErr := decodeParams(call.params, req); rpcErr != nil {
+		return nil, rpcErr
+	}
+	req, errs := checkVerifyAnyRequest(req)
+	if !errs.Empty() {
+		return nil, jsonRPCInvalidParams(errs)
+	}
+
+	valid, err := s.handler.VerifyAny(req.decodedInputData, req.decodedSignature, req.PubKey)
+	if err != nil {
+		return nil, newJSONRPCError(JSONRPCErrorCodeInternalError, err)
+	}
+	return VerifyMessageResult{Valid: valid}, nil
+}
+
+func (s *Service) rpcGetNetwork(_ *jsonRPCCall) (interface{}, *JSONRPCError) {
+	s.networkMu.RLock()
+	defer s.networkMu.RUnlock()
+
+	return NetworkResponse{Network: *s.network}, nil
+}
@@ -0,0 +1,365 @@
+package service_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	api "code.vegaprotocol.io/protos/vega/api/v1"
+	commandspb "code.vegaprotocol.io/protos/vega/commands/v1"
+	vgrand "code.vegaprotocol.io/shared/libs/rand"
+	"code.vegaprotocol.io/vegawallet/service"
+	"code.vegaprotocol.io/vegawallet/wallet"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONRPC(t *testing.T) {
+	t.Run("Logging in succeeds", testJSONRPCLoggingInSucceeds)
+	t.Run("Logging in a single wallet of the wallets list succeeds", testJSONRPCLoggingInSingleWalletOfWalletsListSucceeds)
+	t.Run("Logging in with invalid params fails", testJSONRPCLoggingInWithInvalidParamsFails)
+	t.Run("Calling a method without token fails", testJSONRPCCallingMethodWithoutTokenFails)
+	t.Run("Calling an unknown method fails", testJSONRPCCallingUnknownMethodFails)
+	t.Run("Calling with an invalid version fails", testJSONRPCCallingWithInvalidVersionFails)
+	t.Run("Calling with malformed JSON fails", testJSONRPCCallingWithMalformedJSONFails)
+	t.Run("Generating a key with wrong passphrase fails", testJSONRPCGeneratingKeyWithWrongPassphraseFails)
+	t.Run("Getting an unknown key fails", testJSONRPCGettingUnknownKeyFails)
+	t.Run("Sending a command uses the sending mode", testJSONRPCSendingCommandUsesSendingMode)
+	t.Run("Verifying a message succeeds", testJSONRPCVerifyingMessageSucceeds)
+	t.Run("Batch of requests is answered in order", testJSONRPCBatchIsAnsweredInOrder)
+	t.Run("Batch of notifications is not answered", testJSONRPCBatchOfNotificationsIsNotAnswered)
+	t.Run("Empty batch fails", testJSONRPCEmptyBatchFails)
+	t.Run("Listing methods succeeds", testJSONRPCListingMethodsSucceeds)
+}
+
+func testJSONRPCLoggingInSucceeds(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	passphrase := vgrand.RandomStr(5)
+	payload := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "session.login", "params": {"wallet": "%s", "passphrase": "%s"}}`, walletName, passphrase)
+
+	// setup
+	s.handler.EXPECT().LoginWallet(walletName, passphrase, "").Times(1).Return(nil)
+	s.auth.EXPECT().NewSession(walletName, "").Times(1).Return("this is a token", nil)
+
+	// when
+	resp := callJSONRPC(t, s, payload, nil)
+
+	// then
+	require.Nil(t, resp.Error)
+	assert.Equal(t, json.RawMessage("1"), resp.ID)
+	result := service.TokenResponse{}
+	require.NoError(t, json.Unmarshal(resp.Result, &result))
+	assert.Equal(t, "this is a token", result.Token)
+}
+
+func testJSONRPCLoggingInSingleWalletOfWalletsListSucceeds(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	passphrase := vgrand.RandomStr(5)
+	payload := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "session.login", "params": {"wallets": [{"wallet": "%s", "passphrase": "%s"}]}}`, walletName, passphrase)
+
+	// setup
+	s.handler.EXPECT().LoginWallet(walletName, passphrase, "").Times(1).Return(nil)
+	s.auth.EXPECT().NewSession(walletName, "").Times(1).Return("this is a token", nil)
+
+	// when
+	resp := callJSONRPC(t, s, payload, nil)
+
+	// then
+	require.Nil(t, resp.Error)
+	result := service.TokenResponse{}
+	require.NoError(t, json.Unmarshal(resp.Result, &result))
+	assert.Equal(t, "this is a token", result.Token)
+}
+
+func testJSONRPCLoggingInWithInvalidParamsFails(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	payload := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "session.login", "params": {"wallet": "%s"}}`, vgrand.RandomStr(5))
+
+	// when
+	resp := callJSONRPC(t, s, payload, nil)
+
+	// then
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, service.JSONRPCErrorCodeInvalidParams, resp.Error.Code)
+	assert.Nil(t, resp.Result)
+}
+
+func testJSONRPCCallingMethodWithoutTokenFails(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	payload := `{"jsonrpc": "2.0", "id": "keys", "method": "keys.list"}`
+
+	// when
+	resp := callJSONRPC(t, s, payload, nil)
+
+	// then
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, service.JSONRPCErrorCodeAccessDenied, resp.Error.Code)
+	assert.Equal(t, json.RawMessage(`"keys"`), resp.ID)
+}
+
+func testJSONRPCCallingUnknownMethodFails(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	payload := `{"jsonrpc": "2.0", "id": 1, "method": "wallet.delete"}`
+
+	// when
+	resp := callJSONRPC(t, s, payload, nil)
+
+	// then
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, service.JSONRPCErrorCodeMethodNotFound, resp.Error.Code)
+}
+
+func testJSONRPCCallingWithInvalidVersionFails(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	payload := `{"jsonrpc": "1.0", "id": 1, "method": "network.get"}`
+
+	// when
+	resp := callJSONRPC(t, s, payload, nil)
+
+	// then
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, service.JSONRPCErrorCodeInvalidRequest, resp.Error.Code)
+}
+
+func testJSONRPCCallingWithMalformedJSONFails(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	payload := `{"jsonrpc": "2.0", "id": 1, "method"`
+
+	// when
+	resp := callJSONRPC(t, s, payload, nil)
+
+	// then
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, service.JSONRPCErrorCodeParseError, resp.Error.Code)
+	assert.Equal(t, json.RawMessage("null"), resp.ID)
+}
+
+func testJSONRPCGeneratingKeyWithWrongPassphraseFails(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	passphrase := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	payload := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "keys.generate", "params": {"passphrase": "%s"}}`, passphrase)
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().SecureGenerateKeyPair(walletName, passphrase, gomock.Any()).Times(1).Return("", wallet.ErrWrongPassphrase)
+
+	// when
+	resp := callJSONRPC(t, s, payload, authHeaders(t, token))
+
+	// then
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, service.JSONRPCErrorCodeAccessDenied, resp.Error.Code)
+}
+
+func testJSONRPCGettingUnknownKeyFails(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	pubKey := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	payload := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "keys.get", "params": {"pubKey": "%s"}}`, pubKey)
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().GetPublicKey(walletName, pubKey).Times(1).Return(nil, wallet.ErrPubKeyDoesNotExist)
+
+	// when
+	resp := callJSONRPC(t, s, payload, authHeaders(t, token))
+
+	// then
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, service.JSONRPCErrorCodeNotFound, resp.Error.Code)
+}
+
+func testJSONRPCSendingCommandUsesSendingMode(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	pubKey := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	payload := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "command.send", "params": {"sendingMode": "TYPE_SYNC", "transaction": {"pubKey": "%s", "orderCancellation": {}}}}`, pubKey)
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName).Times(1).Return(true)
+	s.handler.EXPECT().SignTx(walletName, gomock.Any(), uint64(42)).Times(1).Return(&commandspb.Transaction{}, nil)
+	s.nodeForward.EXPECT().LastBlockHeightAndHash(gomock.Any()).Times(1).Return(&api.LastBlockHeightResponse{
+		Height:              42,
+		Hash:                "0292041e2f0cf741894503fb3ead4cb817bca2375e543aa70f7c4d938157b5a6",
+		SpamPowDifficulty:   2,
+		SpamPowHashFunction: "sha3_24_rounds",
+	}, 0, nil)
+	s.nodeForward.EXPECT().SendTx(gomock.Any(), gomock.Any(), api.SubmitTransactionRequest_TYPE_SYNC, gomock.Any()).Times(1).Return("tx-hash", nil)
+
+	// when
+	resp := callJSONRPC(t, s, payload, authHeaders(t, token))
+
+	// then
+	require.Nil(t, resp.Error)
+	result := service.SendTransactionResponse{}
+	require.NoError(t, json.Unmarshal(resp.Result, &result))
+	assert.Equal(t, "tx-hash", result.TxHash)
+	assert.NotEmpty(t, result.TxID)
+}
+
+func testJSONRPCVerifyingMessageSucceeds(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	pubKey := vgrand.RandomStr(5)
+	data := base64.StdEncoding.EncodeToString([]byte("hello"))
+	signature := base64.StdEncoding.EncodeToString([]byte("signature"))
+	payload := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "message.verify", "params": {"inputData": "%s", "signature": "%s", "pubKey": "%s"}}`, data, signature, pubKey)
+
+	// setup
+	s.handler.EXPECT().VerifyAny([]byte("hello"), []byte("signature"), pubKey).Times(1).Return(true, nil)
+
+	// when
+	resp := callJSONRPC(t, s, payload, nil)
+
+	// then
+	require.Nil(t, resp.Error)
+	result := service.VerifyMessageResult{}
+	require.NoError(t, json.Unmarshal(resp.Result, &result))
+	assert.True(t, result.Valid)
+}
+
+func testJSONRPCBatchIsAnsweredInOrder(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	payload := `[
+		{"jsonrpc": "2.0", "id": 1, "method": "keys.list"},
+		{"jsonrpc": "2.0", "method": "network.get"},
+		{"jsonrpc": "2.0", "id": 2, "method": "wallet.delete"}
+	]`
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().ListPublicKeys(walletName).Times(1).Return([]wallet.PublicKey{}, nil)
+
+	// when
+	statusCode, body := serveHTTP(t, s, jsonRPCRequest(t, payload, authHeaders(t, token)))
+
+	// then
+	require.Equal(t, http.StatusOK, statusCode)
+	resps := []service.JSONRPCResponse{}
+	require.NoError(t, json.Unmarshal(body, &resps))
+	require.Len(t, resps, 2)
+	assert.Equal(t, json.RawMessage("1"), resps[0].ID)
+	assert.Nil(t, resps[0].Error)
+	assert.Equal(t, json.RawMessage("2"), resps[1].ID)
+	require.NotNil(t, resps[1].Error)
+	assert.Equal(t, service.JSONRPCErrorCodeMethodNotFound, resps[1].Error.Code)
+}
+
+func testJSONRPCBatchOfNotificationsIsNotAnswered(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	payload := `[{"jsonrpc": "2.0", "method": "network.get"}]`
+
+	// when
+	statusCode, body := serveHTTP(t, s, jsonRPCRequest(t, payload, nil))
+
+	// then
+	assert.Equal(t, http.StatusNoContent, statusCode)
+	assert.Empty(t, body)
+}
+
+func testJSONRPCEmptyBatchFails(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// when
+	resp := callJSONRPC(t, s, `[]`, nil)
+
+	// then
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, service.JSONRPCErrorCodeInvalidRequest, resp.Error.Code)
+}
+
+func testJSONRPCListingMethodsSucceeds(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// when
+	statusCode, body := serveHTTP(t, s, buildRequest(t, http.MethodGet, "/api/v2/methods", "", nil))
+
+	// then
+	require.Equal(t, http.StatusOK, statusCode)
+	resp := service.JSONRPCMethodsResponse{}
+	require.NoError(t, json.Unmarshal(body, &resp))
+	names := make([]string, 0, len(resp.Methods))
+	for _, method := range resp.Methods {
+		names = append(names, method.Name)
+	}
+	assert.Subset(t, names, []string{
+		"session.login",
+		"session.logout",
+		"keys.list",
+		"keys.generate",
+		"command.send",
+		"command.check",
+		"message.sign",
+		"message.verify",
+		"network.get",
+	})
+}
+
+func callJSONRPC(t *testing.T, s *testService, payload string, headers map[string]string) *service.JSONRPCResponse {
+	t.Helper()
+
+	statusCode, body := serveHTTP(t, s, jsonRPCRequest(t, payload, headers))
+	require.Equal(t, http.StatusOK, statusCode)
+
+	resp := &service.JSONRPCResponse{}
+	require.NoError(t, json.Unmarshal(body, resp))
+	assert.Equal(t, service.JSONRPCVersion, resp.Version)
+	return resp
+}
+
+func jsonRPCRequest(t *testing.T, payload string, headers map[string]string) *http.Request {
+	t.Helper()
+	return buildRequest(t, http.MethodPost, "/api/v2/requests", payload, headers)
+}
@@ -0,0 +1,64 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	v1 "code.vegaprotocol.io/protos/vega/wallet/v1"
+	"code.vegaprotocol.io/vegawallet/metrics"
+	"code.vegaprotocol.io/vegawallet/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Run("Recording the request metrics succeeds", testRecordingRequestMetricsSucceeds)
+	t.Run("Metered policy records the consent outcome", testMeteredPolicyRecordsConsentOutcome)
+}
+
+func testRecordingRequestMetricsSucceeds(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// setup
+	m := metrics.New()
+	s.UseMetrics(m)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, buildRequest(t, http.MethodGet, "/api/v1/version", "", nil))
+
+	// then
+	require.Equal(t, http.StatusOK, statusCode)
+	assert.Contains(t, metricsOutput(t, m), `vegawallet_http_requests_total{method="GET",route="/api/v1/version",status="200"} 1`)
+
+	// when
+	statusCode, _ = serveHTTP(t, s, buildRequest(t, http.MethodGet, "/metrics", "", nil))
+
+	// then
+	assert.Equal(t, http.StatusNotFound, statusCode)
+}
+
+func testMeteredPolicyRecordsConsentOutcome(t *testing.T) {
+	// given
+	m := metrics.New()
+	policy := service.NewMeteredPolicy(service.NewAutomaticConsentPolicy(), m)
+
+	// when
+	approved, err := policy.Ask(context.Background(), &v1.SubmitTransactionRequest{}, "txID", time.Now())
+
+	// then
+	require.NoError(t, err)
+	assert.True(t, approved)
+	assert.Contains(t, metricsOutput(t, m), `vegawallet_consent_wait_seconds_count{outcome="approved",request="transaction"} 1`)
+}
+
+func metricsOutput(t *testing.T, m *metrics.Metrics) string {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	return w.Body.String()
+}
@@ -5,6 +5,7 @@
 package mocks
 
 import (
+	service "code.vegaprotocol.io/vegawallet/service"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
 )
@@ -32,47 +33,150 @@ func (m *MockAuth) EXPECT() *MockAuthMockRecorder {
 	return m.recorder
 }
 
+// IntrospectToken mocks base method
+func (m *MockAuth) IntrospectToken(arg0 string, arg1 string) (*service.TokenInfoResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IntrospectToken", arg0, arg1)
+	ret0, _ := ret[0].(*service.TokenInfoResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IntrospectToken indicates an expected call of IntrospectToken
+func (mr *MockAuthMockRecorder) IntrospectToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IntrospectToken", reflect.TypeOf((*MockAuth)(nil).IntrospectToken), arg0, arg1)
+}
+
+// ListWalletSessions mocks base method
+func (m *MockAuth) ListWalletSessions(arg0 string, arg1 string) ([]service.SessionSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWalletSessions", arg0, arg1)
+	ret0, _ := ret[0].([]service.SessionSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWalletSessions indicates an expected call of ListWalletSessions
+func (mr *MockAuthMockRecorder) ListWalletSessions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWalletSessions", reflect.TypeOf((*MockAuth)(nil).ListWalletSessions), arg0, arg1)
+}
+
+// NewMultiWalletSession mocks base method
+func (m *MockAuth) NewMultiWalletSession(arg0 []string, arg1 string, arg2 *service.Scopes) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewMultiWalletSession", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewMultiWalletSession indicates an expected call of NewMultiWalletSession
+func (mr *MockAuthMockRecorder) NewMultiWalletSession(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewMultiWalletSession", reflect.TypeOf((*MockAuth)(nil).NewMultiWalletSession), arg0, arg1, arg2)
+}
+
 // NewSession mocks base method
-func (m *MockAuth) NewSession(arg0 string) (string, error) {
+func (m *MockAuth) NewSession(arg0, arg1 string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "NewSession", arg0)
+	ret := m.ctrl.Call(m, "NewSession", arg0, arg1)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // NewSession indicates an expected call of NewSession
-func (mr *MockAuthMockRecorder) NewSession(arg0 interface{}) *gomock.Call {
+func (mr *MockAuthMockRecorder) NewSession(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewSession", reflect.TypeOf((*MockAuth)(nil).NewSession), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewSession", reflect.TypeOf((*MockAuth)(nil).NewSession), arg0, arg1)
+}
+
+// NewScopedSession mocks base method
+func (m *MockAuth) NewScopedSession(arg0, arg1 string, arg2 *service.Scopes) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewScopedSession", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewScopedSession indicates an expected call of NewScopedSession
+func (mr *MockAuthMockRecorder) NewScopedSession(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewScopedSession", reflect.TypeOf((*MockAuth)(nil).NewScopedSession), arg0, arg1, arg2)
+}
+
+// RefreshToken mocks base method
+func (m *MockAuth) RefreshToken(arg0 string, arg1 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshToken", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshToken indicates an expected call of RefreshToken
+func (mr *MockAuthMockRecorder) RefreshToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshToken", reflect.TypeOf((*MockAuth)(nil).RefreshToken), arg0, arg1)
 }
 
 // Revoke mocks base method
-func (m *MockAuth) Revoke(arg0 string) (string, error) {
+func (m *MockAuth) Revoke(arg0, arg1 string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Revoke", arg0)
+	ret := m.ctrl.Call(m, "Revoke", arg0, arg1)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Revoke indicates an expected call of Revoke
-func (mr *MockAuthMockRecorder) Revoke(arg0 interface{}) *gomock.Call {
+func (mr *MockAuthMockRecorder) Revoke(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockAuth)(nil).Revoke), arg0, arg1)
+}
+
+// RevokeWalletSessions mocks base method
+func (m *MockAuth) RevokeWalletSessions(arg0 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RevokeWalletSessions", arg0)
+}
+
+// RevokeWalletSessions indicates an expected call of RevokeWalletSessions
+func (mr *MockAuthMockRecorder) RevokeWalletSessions(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockAuth)(nil).Revoke), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeWalletSessions", reflect.TypeOf((*MockAuth)(nil).RevokeWalletSessions), arg0)
 }
 
 // VerifyToken mocks base method
-func (m *MockAuth) VerifyToken(arg0 string) (string, error) {
+func (m *MockAuth) VerifyToken(arg0, arg1 string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "VerifyToken", arg0)
+	ret := m.ctrl.Call(m, "VerifyToken", arg0, arg1)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // VerifyToken indicates an expected call of VerifyToken
-func (mr *MockAuthMockRecorder) VerifyToken(arg0 interface{}) *gomock.Call {
+func (mr *MockAuthMockRecorder) VerifyToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyToken", reflect.TypeOf((*MockAuth)(nil).VerifyToken), arg0, arg1)
+}
+
+// VerifyScopedToken mocks base method
+func (m *MockAuth) VerifyScopedToken(arg0, arg1 string) ([]string, *service.Scopes, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyScopedToken", arg0, arg1)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(*service.Scopes)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// VerifyScopedToken indicates an expected call of VerifyScopedToken
+func (mr *MockAuthMockRecorder) VerifyScopedToken(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyToken", reflect.TypeOf((*MockAuth)(nil).VerifyToken), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyScopedToken", reflect.TypeOf((*MockAuth)(nil).VerifyScopedToken), arg0, arg1)
 }
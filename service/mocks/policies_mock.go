@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"context"
 	"time"
 
 	v1 "code.vegaprotocol.io/protos/vega/wallet/v1"
@@ -23,9 +24,25 @@ func NewMockConsentPolicy(pending chan service.ConsentRequest, sentTxs chan serv
 	}
 }
 
-func (p *MockConsentPolicy) Ask(tx *v1.SubmitTransactionRequest, txID string, receivedAt time.Time) (bool, error) {
+func (p *MockConsentPolicy) Ask(_ context.Context, tx *v1.SubmitTransactionRequest, txID string, receivedAt time.Time) (bool, error) {
 	if tx.PubKey == "toBeDeclined" {
 		return false, nil
 	}
 	return true, nil
 }
+
+func (p *MockConsentPolicy) AskBatch(_ context.Context, txs []*v1.SubmitTransactionRequest, _ string, _ time.Time) (bool, error) {
+	for _, tx := range txs {
+		if tx.PubKey == "toBeDeclined" {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (p *MockConsentPolicy) AskConnection(_ context.Context, origin string, _ time.Time) (bool, error) {
+	if origin == "toBeDeclined" {
+		return false, nil
+	}
+	return true, nil
+}
@@ -35,6 +35,18 @@ func (m *MockWalletHandler) EXPECT() *MockWalletHandlerMockRecorder {
 	return m.recorder
 }
 
+// AbortLogin mocks base method
+func (m *MockWalletHandler) AbortLogin(arg0 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AbortLogin", arg0)
+}
+
+// AbortLogin indicates an expected call of AbortLogin
+func (mr *MockWalletHandlerMockRecorder) AbortLogin(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AbortLogin", reflect.TypeOf((*MockWalletHandler)(nil).AbortLogin), arg0)
+}
+
 // CreateWallet mocks base method
 func (m *MockWalletHandler) CreateWallet(arg0, arg1 string) (string, error) {
 	m.ctrl.T.Helper()
@@ -79,6 +91,20 @@ func (mr *MockWalletHandlerMockRecorder) ImportWallet(arg0, arg1, arg2, arg3 int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportWallet", reflect.TypeOf((*MockWalletHandler)(nil).ImportWallet), arg0, arg1, arg2, arg3)
 }
 
+// IsWalletUnlocked mocks base method
+func (m *MockWalletHandler) IsWalletUnlocked(arg0 string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsWalletUnlocked", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsWalletUnlocked indicates an expected call of IsWalletUnlocked
+func (mr *MockWalletHandlerMockRecorder) IsWalletUnlocked(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsWalletUnlocked", reflect.TypeOf((*MockWalletHandler)(nil).IsWalletUnlocked), arg0)
+}
+
 // ListPublicKeys mocks base method
 func (m *MockWalletHandler) ListPublicKeys(arg0 string) ([]wallet.PublicKey, error) {
 	m.ctrl.T.Helper()
@@ -95,29 +121,17 @@ func (mr *MockWalletHandlerMockRecorder) ListPublicKeys(arg0 interface{}) *gomoc
 }
 
 // LoginWallet mocks base method
-func (m *MockWalletHandler) LoginWallet(arg0, arg1 string) error {
+func (m *MockWalletHandler) LoginWallet(arg0, arg1, arg2 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "LoginWallet", arg0, arg1)
+	ret := m.ctrl.Call(m, "LoginWallet", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // LoginWallet indicates an expected call of LoginWallet
-func (mr *MockWalletHandlerMockRecorder) LoginWallet(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockWalletHandlerMockRecorder) LoginWallet(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginWallet", reflect.TypeOf((*MockWalletHandler)(nil).LoginWallet), arg0, arg1)
-}
-
-// LogoutWallet mocks base method
-func (m *MockWalletHandler) LogoutWallet(arg0 string) {
-	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "LogoutWallet", arg0)
-}
-
-// LogoutWallet indicates an expected call of LogoutWallet
-func (mr *MockWalletHandlerMockRecorder) LogoutWallet(arg0 interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogoutWallet", reflect.TypeOf((*MockWalletHandler)(nil).LogoutWallet), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginWallet", reflect.TypeOf((*MockWalletHandler)(nil).LoginWallet), arg0, arg1, arg2)
 }
 
 // SecureGenerateKeyPair mocks base method
@@ -185,6 +199,20 @@ func (mr *MockWalletHandlerMockRecorder) TaintKey(arg0, arg1, arg2 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TaintKey", reflect.TypeOf((*MockWalletHandler)(nil).TaintKey), arg0, arg1, arg2)
 }
 
+// UnlockWallet mocks base method
+func (m *MockWalletHandler) UnlockWallet(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlockWallet", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnlockWallet indicates an expected call of UnlockWallet
+func (mr *MockWalletHandlerMockRecorder) UnlockWallet(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockWallet", reflect.TypeOf((*MockWalletHandler)(nil).UnlockWallet), arg0, arg1)
+}
+
 // UpdateMeta mocks base method
 func (m *MockWalletHandler) UpdateMeta(arg0, arg1, arg2 string, arg3 []wallet.Meta) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,486 @@
+package service
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+
+	"code.vegaprotocol.io/protos/commands"
+	walletpb "code.vegaprotocol.io/protos/vega/wallet/v1"
+	"code.vegaprotocol.io/vegawallet/version"
+	"code.vegaprotocol.io/vegawallet/wallet"
+	"github.com/julienschmidt/httprouter"
+)
+
+const (
+	OpenAPIVersion = "3.0.3"
+
+	openAPISchemasRef  = "#/components/schemas/"
+	openAPIBearerAuth  = "bearerAuth"
+	openAPIContentJSON = "application/json"
+)
+
+// Endpoint describes an endpoint of the API. It's the source of the OpenAPI
+// document, so it has to be declared alongside its route in NewService.
+type Endpoint struct {
+	// Operation is the name of the handler serving the endpoint.
+	Operation string
+	Method    string
+	// Path uses the syntax of the router, like `/api/v1/keys/:keyid`.
+	Path    string
+	Group   string
+	Summary string
+	// Authenticated is true when the endpoint requires a token.
+	Authenticated bool
+	// Request is a value of the type of the request body, nil if there is no
+	// body.
+	Request interface{}
+	// Response is a value of the type of the response body, nil if the
+	// endpoint only returns a status code.
+	Response interface{}
+	// ContentType is the content type of the response, JSON if not set.
+	ContentType string
+	// Query lists the optional query parameters.
+	Query []string
+	// Headers lists the optional header parameters.
+	Headers []string
+}
+
+var endpoints = []Endpoint{
+	{Operation: "Login", Method: http.MethodPost, Path: "/api/v1/auth/token", Group: "Authentication", Summary: "Log in to a wallet", Request: LoginWalletRequest{}, Response: TokenResponse{}},
+	{Operation: "IntrospectToken", Method: http.MethodGet, Path: "/api/v1/auth/token", Group: "Authentication", Summary: "Describe the token", Authenticated: true, Response: TokenInfoResponse{}},
+	{Operation: "Revoke", Method: http.MethodDelete, Path: "/api/v1/auth/token", Group: "Authentication", Summary: "Log out", Authenticated: true},
+	{Operation: "RefreshToken", Method: http.MethodPost, Path: "/api/v1/auth/token/refresh", Group: "Authentication", Summary: "Refresh the token", Authenticated: true, Response: TokenResponse{}},
+	{Operation: "ListSessions", Method: http.MethodGet, Path: "/api/v1/auth/sessions", Group: "Authentication", Summary: "List the sessions", Authenticated: true, Response: SessionsResponse{}},
+	{Operation: "RevokeSessions", Method: http.MethodDelete, Path: "/api/v1/auth/sessions", Group: "Authentication", Summary: "Revoke the sessions", Authenticated: true},
+
+	{Operation: "GetNetwork", Method: http.MethodGet, Path: "/api/v1/network", Group: "Network management", Summary: "Describe the network", Response: NetworkResponse{}},
+
+	{Operation: "CreateWallet", Method: http.MethodPost, Path: "/api/v1/wallets", Group: "Wallet management", Summary: "Create a wallet", Request: CreateWalletRequest{}, Response: CreateWalletResponse{}},
+	{Operation: "ImportWallet", Method: http.MethodPost, Path: "/api/v1/wallets/import", Group: "Wallet management", Summary: "Import a wallet", Request: ImportWalletRequest{}, Response: TokenResponse{}},
+	{Operation: "UnlockWallet", Method: http.MethodPost, Path: "/api/v1/wallets/unlock", Group: "Wallet management", Summary: "Unlock a wallet", Authenticated: true, Request: UnlockWalletRequest{}},
+
+	{Operation: "ListPublicKeys", Method: http.MethodGet, Path: "/api/v1/keys", Group: "Key pair management", Summary: "List keys", Authenticated: true, Response: KeysResponse{}},
+	{Operation: "GenerateKeyPair", Method: http.MethodPost, Path: "/api/v1/keys", Group: "Key pair management", Summary: "Generate a key pair", Authenticated: true, Request: GenKeyPairRequest{}, Response: KeyResponse{}},
+	{Operation: "GetPublicKey", Method: http.MethodGet, Path: "/api/v1/keys/:keyid", Group: "Key pair management", Summary: "Describe a key pair", Authenticated: true, Response: KeyResponse{}},
+	{Operation: "TaintKey", Method: http.MethodPut, Path: "/api/v1/keys/:keyid/taint", Group: "Key pair management", Summary: "Taint a key pair", Authenticated: true, Request: TaintKeyRequest{}},
+	{Operation: "UpdateMeta", Method: http.MethodPut, Path: "/api/v1/keys/:keyid/metadata", Group: "Key pair management", Summary: "Annotate a key pair", Authenticated: true, Request: UpdateMetaRequest{}},
+
+	{Operation: "SignTx", Method: http.MethodPost, Path: "/api/v1/command", Group: "Commands", Summary: "Sign a command", Authenticated: true, Request: &walletpb.SubmitTransactionRequest{}, Response: SendTransactionResponse{}, Headers: []string{IdempotencyKeyHeader}},
+	{Operation: "SignTxSync", Method: http.MethodPost, Path: "/api/v1/command/sync", Group: "Commands", Summary: "Sign a command (sync)", Authenticated: true, Request: &walletpb.SubmitTransactionRequest{}, Response: SendTransactionResponse{}, Headers: []string{IdempotencyKeyHeader}},
+	{Operation: "CheckTx", Method: http.MethodPost, Path: "/api/v1/command/check", Group: "Commands", Summary: "Check a command", Authenticated: true, Request: &walletpb.SubmitTransactionRequest{}, Response: CheckTransactionResponse{}},
+	{Operation: "SignTxCommit", Method: http.MethodPost, Path: "/api/v1/command/commit", Group: "Commands", Summary: "Sign a command (commit)", Authenticated: true, Request: &walletpb.SubmitTransactionRequest{}, Response: SendTransactionResponse{}, Headers: []string{IdempotencyKeyHeader}},
+	{Operation: "SignTxBatch", Method: http.MethodPost, Path: "/api/v1/command/batch", Group: "Commands", Summary: "Sign a batch of commands", Authenticated: true, Request: BatchCommandRequest{}, Response: BatchCommandResponse{}, Headers: []string{IdempotencyKeyHeader}},
+	{Operation: "SignAny", Method: http.MethodPost, Path: "/api/v1/sign", Group: "Commands", Summary: "Sign data", Authenticated: true, Request: SignAnyRequest{}, Response: SignAnyResponse{}},
+	{Operation: "VerifyAny", Method: http.MethodPost, Path: "/api/v1/verify", Group: "Commands", Summary: "Verify data", Request: VerifyAnyRequest{}, Response: VerifyAnyResponse{}},
+	{Operation: "ListTransactions", Method: http.MethodGet, Path: "/api/v1/transactions", Group: "Commands", Summary: "List the transaction history", Authenticated: true, Response: TransactionsResponse{}, Query: []string{"wallet", "pubKey", "command", "txId", "status", "since", "until", "limit"}},
+	{Operation: "StreamEvents", Method: http.MethodGet, Path: "/api/v1/events", Group: "Commands", Summary: "Stream the transaction events", Authenticated: true, Response: TransactionEvent{}, ContentType: "text/event-stream"},
+
+	{Operation: "Version", Method: http.MethodGet, Path: "/api/v1/version", Group: "Information", Summary: "Get the version", Response: VersionResponse{}},
+	{Operation: "Health", Method: http.MethodGet, Path: "/api/v1/status", Group: "Information", Summary: "Get the service status"},
+	{Operation: "GetOpenAPIDocument", Method: http.MethodGet, Path: "/api/v1/openapi.json", Group: "Information", Summary: "Describe the API"},
+
+	{Operation: "HandleJSONRPC", Method: http.MethodPost, Path: "/api/v2/requests", Group: "JSON-RPC", Summary: "Call a JSON-RPC method", Request: JSONRPCRequest{}, Response: JSONRPCResponse{}},
+	{Operation: "ListJSONRPCMethods", Method: http.MethodGet, Path: "/api/v2/methods", Group: "JSON-RPC", Summary: "List the JSON-RPC methods", Response: JSONRPCMethodsResponse{}},
+}
+
+// Endpoints returns the endpoints of the API, in the order they are
+// registered.
+func Endpoints() []Endpoint {
+	eps := make([]Endpoint, len(endpoints))
+	copy(eps, endpoints)
+	return eps
+}
+
+// Route is an HTTP route registered on the router of the service.
+type Route struct {
+	Method string
+	Path   string
+}
+
+// OpenAPIDocument is an OpenAPI 3 description of the API.
+type OpenAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       OpenAPIInfo                             `json:"info"`
+	Servers    []OpenAPIServer                         `json:"servers,omitempty"`
+	Paths      map[string]map[string]*OpenAPIOperation `json:"paths"`
+	Components OpenAPIComponents                       `json:"components"`
+}
+
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type OpenAPIServer struct {
+	URL string `json:"url"`
+}
+
+type OpenAPIOperation struct {
+	OperationID string                     `json:"operationId"`
+	Summary     string                     `json:"summary"`
+	Tags        []string                   `json:"tags,omitempty"`
+	Security    []map[string][]string      `json:"security,omitempty"`
+	Parameters  []OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]OpenAPIResponse `json:"responses"`
+}
+
+type OpenAPIParameter struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required"`
+	Schema   *OpenAPISchema `json:"schema"`
+}
+
+type OpenAPIRequestBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]OpenAPIMediaType `json:"content"`
+}
+
+type OpenAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+type OpenAPIMediaType struct {
+	Schema *OpenAPISchema `json:"schema"`
+}
+
+type OpenAPIComponents struct {
+	Schemas         map[string]*OpenAPISchema        `json:"schemas"`
+	SecuritySchemes map[string]OpenAPISecurityScheme `json:"securitySchemes"`
+}
+
+type OpenAPISecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme"`
+	Description string `json:"description,omitempty"`
+}
+
+// OpenAPISchema is the subset of the JSON schema used to describe the
+// requests and the responses. An empty schema accepts any value.
+type OpenAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Properties           map[string]*OpenAPISchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	Items                *OpenAPISchema            `json:"items,omitempty"`
+	AdditionalProperties *OpenAPISchema            `json:"additionalProperties,omitempty"`
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+	// openAPIOverrides describes the types that are not encoded with the
+	// standard library, or not as their fields suggest.
+	openAPIOverrides = map[reflect.Type]*OpenAPISchema{
+		reflect.TypeOf(walletpb.SubmitTransactionRequest{}): {
+			Type:        "object",
+			Description: "The command to sign is set in the property named after it, like `orderSubmission`, as described by the Vega protocol buffers.",
+			Properties: map[string]*OpenAPISchema{
+				"pubKey":    {Type: "string"},
+				"propagate": {Type: "boolean"},
+			},
+			Required:             []string{"pubKey"},
+			AdditionalProperties: &OpenAPISchema{},
+		},
+		reflect.TypeOf(commands.Errors{}): {
+			Type:                 "object",
+			Description:          "The errors, by property.",
+			AdditionalProperties: &OpenAPISchema{Type: "array", Items: &OpenAPISchema{Type: "string"}},
+		},
+	}
+
+	// openAPISubstitutes describes the interfaces by the type implementing
+	// them.
+	openAPISubstitutes = map[reflect.Type]reflect.Type{
+		reflect.TypeOf((*wallet.PublicKey)(nil)).Elem(): reflect.TypeOf(wallet.HDPublicKey{}),
+	}
+)
+
+// NewOpenAPIDocument describes the API served at the specified URL. The
+// schemas are generated from the types of the requests and the responses.
+func NewOpenAPIDocument(serverURL string) *OpenAPIDocument {
+	schemas := newOpenAPISchemas()
+
+	doc := &OpenAPIDocument{
+		OpenAPI: OpenAPIVersion,
+		Info: OpenAPIInfo{
+			Title:       "Vega Wallet API",
+			Description: "The API of the Vega wallet service.",
+			Version:     version.Version,
+		},
+		Paths: map[string]map[string]*OpenAPIOperation{},
+		Components: OpenAPIComponents{
+			SecuritySchemes: map[string]OpenAPISecurityScheme{
+				openAPIBearerAuth: {
+					Type:        "http",
+					Scheme:      "bearer",
+					Description: "The token returned by the login, or an API key.",
+				},
+			},
+		},
+	}
+	if len(serverURL) != 0 {
+		doc.Servers = []OpenAPIServer{{URL: serverURL}}
+	}
+
+	errorSchema := schemas.schemaOf(reflect.TypeOf(ErrorResponse{}))
+	errorsSchema := schemas.schemaOf(reflect.TypeOf(ErrorsResponse{}))
+
+	for _, e := range endpoints {
+		p, params := openAPIPath(e.Path)
+		for _, q := range e.Query {
+			params = append(params, OpenAPIParameter{
+				Name:   q,
+				In:     "query",
+				Schema: &OpenAPISchema{Type: "string"},
+			})
+		}
+		for _, h := range e.Headers {
+			params = append(params, OpenAPIParameter{
+				Name:   h,
+				In:     "header",
+				Schema: &OpenAPISchema{Type: "string"},
+			})
+		}
+
+		op := &OpenAPIOperation{
+			OperationID: e.Operation,
+			Summary:     e.Summary,
+			Tags:        []string{e.Group},
+			Parameters:  params,
+			Responses: map[string]OpenAPIResponse{
+				"default": {
+					Description: "The error",
+					Content:     jsonContent(errorSchema),
+				},
+			},
+		}
+
+		if e.Authenticated {
+			op.Security = []map[string][]string{{openAPIBearerAuth: {}}}
+		}
+
+		if e.Request != nil {
+			op.RequestBody = &OpenAPIRequestBody{
+				Required: true,
+				Content:  jsonContent(schemas.schemaOf(reflect.TypeOf(e.Request))),
+			}
+			op.Responses["400"] = OpenAPIResponse{
+				Description: "The request is not valid",
+				Content:     jsonContent(errorsSchema),
+			}
+		}
+
+		success := OpenAPIResponse{Description: "Success"}
+		if e.Response != nil {
+			contentType := e.ContentType
+			if len(contentType) == 0 {
+				contentType = openAPIContentJSON
+			}
+			success.Content = map[string]OpenAPIMediaType{
+				contentType: {Schema: schemas.schemaOf(reflect.TypeOf(e.Response))},
+			}
+		}
+		op.Responses["200"] = success
+
+		if _, ok := doc.Paths[p]; !ok {
+			doc.Paths[p] = map[string]*OpenAPIOperation{}
+		}
+		doc.Paths[p][strings.ToLower(e.Method)] = op
+	}
+
+	doc.Components.Schemas = schemas.definitions
+
+	return doc
+}
+
+// GetOpenAPIDocument returns the OpenAPI document of the API, served at the
+// address the request has been sent to.
+func (s *Service) GetOpenAPIDocument(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	s.writeSuccess(w, NewOpenAPIDocument(fmt.Sprintf("%s://%s", scheme, r.Host)))
+}
+
+// openAPIPath converts the path of the router to the OpenAPI syntax, and
+// returns its parameters.
+func openAPIPath(routerPath string) (string, []OpenAPIParameter) {
+	segments := strings.Split(routerPath, "/")
+	params := []OpenAPIParameter{}
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+		name := segment[1:]
+		segments[i] = "{" + name + "}"
+		params = append(params, OpenAPIParameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   &OpenAPISchema{Type: "string"},
+		})
+	}
+	return strings.Join(segments, "/"), params
+}
+
+func jsonContent(schema *OpenAPISchema) map[string]OpenAPIMediaType {
+	return map[string]OpenAPIMediaType{
+		openAPIContentJSON: {Schema: schema},
+	}
+}
+
+// openAPISchemas generates the schemas of the types, the structures being
+// declared once in the components of the document.
+type openAPISchemas struct {
+	definitions map[string]*OpenAPISchema
+	names       map[reflect.Type]string
+}
+
+func newOpenAPISchemas() *openAPISchemas {
+	return &openAPISchemas{
+		definitions: map[string]*OpenAPISchema{},
+		names:       map[reflect.Type]string{},
+	}
+}
+
+func (s *openAPISchemas) schemaOf(t reflect.Type) *OpenAPISchema {
+	if schema, ok := openAPIOverrides[t]; ok {
+		return schema
+	}
+	if sub, ok := openAPISubstitutes[t]; ok {
+		return s.refOf(sub)
+	}
+
+	switch t {
+	case timeType:
+		return &OpenAPISchema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return &OpenAPISchema{}
+	}
+
+	if t.Kind() == reflect.Ptr {
+		return s.schemaOf(t.Elem())
+	}
+
+	ptr := reflect.PtrTo(t)
+	if t.Implements(textMarshalerType) || ptr.Implements(textMarshalerType) {
+		return &OpenAPISchema{Type: "string"}
+	}
+	if t.Implements(jsonMarshalerType) || ptr.Implements(jsonMarshalerType) {
+		return &OpenAPISchema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &OpenAPISchema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &OpenAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &OpenAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &OpenAPISchema{Type: "number"}
+	case reflect.String:
+		return &OpenAPISchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &OpenAPISchema{Type: "string", Format: "byte"}
+		}
+		return &OpenAPISchema{Type: "array", Items: s.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &OpenAPISchema{Type: "object", AdditionalProperties: s.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if len(t.Name()) == 0 {
+			return s.structSchema(t)
+		}
+		return s.refOf(t)
+	default:
+		return &OpenAPISchema{}
+	}
+}
+
+// refOf declares the structure in the components, and returns a reference to
+// it.
+func (s *openAPISchemas) refOf(t reflect.Type) *OpenAPISchema {
+	name, ok := s.names[t]
+	if !ok {
+		name = t.Name()
+		if _, taken := s.definitions[name]; taken {
+			name = path.Base(t.PkgPath()) + "." + name
+		}
+		s.names[t] = name
+		// Declared before generating the schema, so recursive structures
+		// refer to themselves.
+		s.definitions[name] = &OpenAPISchema{}
+		s.definitions[name] = s.structSchema(t)
+	}
+	return &OpenAPISchema{Ref: openAPISchemasRef + name}
+}
+
+func (s *openAPISchemas) structSchema(t reflect.Type) *OpenAPISchema {
+	schema := &OpenAPISchema{
+		Type:       "object",
+		Properties: map[string]*OpenAPISchema{},
+	}
+	s.addFields(schema, t)
+	return schema
+}
+
+// addFields adds the fields of the structure as they are encoded by the
+// standard library.
+func (s *openAPISchemas) addFields(schema *OpenAPISchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag := strings.SplitN(field.Tag.Get("json"), ",", 2)
+		name, opts := tag[0], ""
+		if len(tag) > 1 {
+			opts = tag[1]
+		}
+		if name == "-" && len(opts) == 0 {
+			continue
+		}
+
+		fieldType := field.Type
+		if field.Anonymous && len(name) == 0 {
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				s.addFields(schema, fieldType)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if len(name) == 0 {
+			name = field.Name
+		}
+		schema.Properties[name] = s.schemaOf(fieldType)
+
+		optional := strings.Contains(opts, "omitempty") ||
+			fieldType.Kind() == reflect.Ptr ||
+			fieldType.Kind() == reflect.Interface
+		if !optional {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package service_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"code.vegaprotocol.io/vegawallet/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPIDocument(t *testing.T) {
+	t.Run("Routes and endpoints match", testRoutesAndEndpointsMatch)
+	t.Run("Document describes all the endpoints", testDocumentDescribesAllEndpoints)
+	t.Run("Document only references declared schemas", testDocumentOnlyReferencesDeclaredSchemas)
+	t.Run("Document describes the fields of the requests", testDocumentDescribesFieldsOfRequests)
+	t.Run("Getting the document succeeds", testGettingDocumentSucceeds)
+}
+
+func testRoutesAndEndpointsMatch(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	described := []service.Route{}
+	for _, e := range service.Endpoints() {
+		described = append(described, service.Route{Method: e.Method, Path: e.Path})
+	}
+
+	// then
+	assert.ElementsMatch(t, s.Routes(), described, "the endpoints of the OpenAPI document and the routes of the service have drifted apart")
+}
+
+func testDocumentDescribesAllEndpoints(t *testing.T) {
+	// when
+	doc := service.NewOpenAPIDocument("http://127.0.0.1:1789")
+
+	// then
+	assert.Equal(t, service.OpenAPIVersion, doc.OpenAPI)
+	operations := 0
+	for _, e := range service.Endpoints() {
+		path := regexp.MustCompile(`:([a-z]+)`).ReplaceAllString(e.Path, "{$1}")
+		op := doc.Paths[path][strings.ToLower(e.Method)]
+		require.NotNil(t, op, "%s %s is not described", e.Method, path)
+		assert.Equal(t, e.Operation, op.OperationID)
+		assert.Equal(t, e.Authenticated, len(op.Security) != 0)
+		assert.Equal(t, e.Request != nil, op.RequestBody != nil)
+		assert.Contains(t, op.Responses, "200")
+		if strings.Contains(e.Path, ":") {
+			assert.NotEmpty(t, op.Parameters)
+		}
+	}
+	for _, ops := range doc.Paths {
+		operations += len(ops)
+	}
+	assert.Equal(t, len(service.Endpoints()), operations)
+}
+
+func testDocumentOnlyReferencesDeclaredSchemas(t *testing.T) {
+	// given
+	doc := service.NewOpenAPIDocument("")
+
+	// when
+	content, err := json.Marshal(doc)
+
+	// then
+	require.NoError(t, err)
+	refs := regexp.MustCompile(`"\$ref":"#/components/schemas/([^"]+)"`).FindAllStringSubmatch(string(content), -1)
+	require.NotEmpty(t, refs)
+	for _, ref := range refs {
+		assert.Contains(t, doc.Components.Schemas, ref[1])
+	}
+}
+
+func testDocumentDescribesFieldsOfRequests(t *testing.T) {
+	// when
+	doc := service.NewOpenAPIDocument("")
+
+	// then
+	schema := doc.Components.Schemas["GenKeyPairRequest"]
+	require.NotNil(t, schema)
+	assert.Equal(t, "object", schema.Type)
+	assert.Contains(t, schema.Properties, "wallet")
+	assert.Contains(t, schema.Properties, "passphrase")
+	assert.Equal(t, "array", schema.Properties["meta"].Type)
+	assert.Equal(t, []string{"passphrase", "meta"}, schema.Required)
+
+	// then
+	keys := doc.Components.Schemas["KeyResponse"]
+	require.NotNil(t, keys)
+	assert.Equal(t, "#/components/schemas/HDPublicKey", keys.Properties["key"].Ref)
+}
+
+func testGettingDocumentSucceeds(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	req := buildRequest(t, http.MethodGet, "/api/v1/openapi.json", "", nil)
+	req.Host = "127.0.0.1:1789"
+
+	// when
+	statusCode, body := serveHTTP(t, s, req)
+
+	// then
+	require.Equal(t, http.StatusOK, statusCode)
+	doc := &service.OpenAPIDocument{}
+	require.NoError(t, json.Unmarshal(body, doc))
+	assert.Equal(t, service.OpenAPIVersion, doc.OpenAPI)
+	assert.Equal(t, []service.OpenAPIServer{{URL: "http://127.0.0.1:1789"}}, doc.Servers)
+	assert.Contains(t, doc.Paths, "/api/v1/keys/{keyid}")
+}
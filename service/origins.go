@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ApprovedOrigin is the origin of a web page the user approved to connect to
+// the service.
+type ApprovedOrigin struct {
+	Origin     string    `json:"origin"`
+	ApprovedAt time.Time `json:"approvedAt"`
+}
+
+// OriginStore persists the approved origins, so the user doesn't have to
+// approve them again after a restart of the service.
+type OriginStore interface {
+	GetApprovedOrigins() ([]ApprovedOrigin, error)
+	SaveApprovedOrigins([]ApprovedOrigin) error
+}
+
+// OriginGuard only lets through the requests coming from origins the user
+// approved. The first request from an unknown origin asks the policy for an
+// approval. The requests without origin, that don't come from a web page, are
+// always let through. The approved origins are cached, and have to be
+// reloaded to take into account the ones revoked from the command line.
+type OriginGuard struct {
+	log    *zap.Logger
+	store  OriginStore
+	policy Policy
+
+	// origin -> approved origin
+	approved map[string]ApprovedOrigin
+	// rejected holds the origins the user rejected during this run, so the
+	// user isn't asked again for every request.
+	rejected map[string]struct{}
+	mu       sync.RWMutex
+
+	// askMu ensures the user is asked only once per origin, when several
+	// requests from the same origin come simultaneously.
+	askMu sync.Mutex
+}
+
+func NewOriginGuard(log *zap.Logger, store OriginStore, policy Policy) (*OriginGuard, error) {
+	origins, err := store.GetApprovedOrigins()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get the approved origins: %w", err)
+	}
+
+	approved := make(map[string]ApprovedOrigin, len(origins))
+	for _, origin := range origins {
+		approved[origin.Origin] = origin
+	}
+
+	return &OriginGuard{
+		log:      log,
+		store:    store,
+		policy:   policy,
+		approved: approved,
+		rejected: map[string]struct{}{},
+	}, nil
+}
+
+// IsAllowed verifies the origin has been approved, and asks for an approval
+// if the origin is unknown. The context is the one of the request, so the
+// approval isn't waited for anymore when the client disconnects.
+func (g *OriginGuard) IsAllowed(ctx context.Context, origin string) bool {
+	if len(origin) == 0 {
+		return true
+	}
+
+	if allowed, known := g.lookup(origin); known {
+		return allowed
+	}
+
+	g.askMu.Lock()
+	defer g.askMu.Unlock()
+
+	// The origin might have been approved while the service was running, so
+	// the store is read again before asking.
+	if err := g.Reload(); err != nil {
+		g.log.Error("couldn't reload the approved origins", zap.Error(err))
+	}
+
+	// The origin might have been approved, or rejected, while waiting.
+	if allowed, known := g.lookup(origin); known {
+		return allowed
+	}
+
+	approved, err := g.policy.AskConnection(ctx, origin, time.Now())
+	if err != nil {
+		g.log.Error("couldn't get the approval of the origin", zap.String("origin", origin), zap.Error(err))
+		return false
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !approved {
+		g.log.Info("origin rejected", zap.String("origin", origin))
+		g.rejected[origin] = struct{}{}
+		return false
+	}
+
+	g.log.Info("origin approved", zap.String("origin", origin))
+	g.approved[origin] = ApprovedOrigin{
+		Origin:     origin,
+		ApprovedAt: time.Now(),
+	}
+	g.saveOrigins()
+
+	return true
+}
+
+// Handler wraps the specified handler so only the requests from allowed
+// origins reach it.
+func (g *OriginGuard) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !g.IsAllowed(r.Context(), r.Header.Get("Origin")) {
+			writeError(w, newErrorResponse(ErrOriginNotApproved.Error()), http.StatusForbidden)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// Reload replaces the cached approved origins by the ones from the store, so
+// the origins revoked from the command line are no longer let through.
+func (g *OriginGuard) Reload() error {
+	origins, err := g.store.GetApprovedOrigins()
+	if err != nil {
+		return fmt.Errorf("couldn't get the approved origins: %w", err)
+	}
+
+	approved := make(map[string]ApprovedOrigin, len(origins))
+	for _, origin := range origins {
+		approved[origin.Origin] = origin
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for origin := range g.approved {
+		if _, ok := approved[origin]; !ok {
+			g.log.Info("origin revoked", zap.String("origin", origin))
+		}
+	}
+	g.approved = approved
+
+	return nil
+}
+
+func (g *OriginGuard) lookup(origin string) (allowed bool, known bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if _, ok := g.approved[origin]; ok {
+		return true, true
+	}
+	if _, ok := g.rejected[origin]; ok {
+		return false, true
+	}
+	return false, false
+}
+
+// saveOrigins persists the approved origins. A failure doesn't prevent the
+// service from working, so it's only logged.
+func (g *OriginGuard) saveOrigins() {
+	origins := make([]ApprovedOrigin, 0, len(g.approved))
+	for _, origin := range g.approved {
+		origins = append(origins, origin)
+	}
+
+	sortApprovedOrigins(origins)
+
+	if err := g.store.SaveApprovedOrigins(origins); err != nil {
+		g.log.Error("couldn't persist the approved origins", zap.Error(err))
+	}
+}
+
+func sortApprovedOrigins(origins []ApprovedOrigin) {
+	sort.Slice(origins, func(i, j int) bool {
+		return origins[i].ApprovedAt.Before(origins[j].ApprovedAt)
+	})
+}
+
+type ListApprovedOriginsResponse struct {
+	Origins []ApprovedOrigin `json:"origins"`
+}
+
+// ListApprovedOrigins returns the origins approved to connect to the service.
+func ListApprovedOrigins(store OriginStore) (*ListApprovedOriginsResponse, error) {
+	origins, err := store.GetApprovedOrigins()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get the approved origins: %w", err)
+	}
+
+	sortApprovedOrigins(origins)
+
+	return &ListApprovedOriginsResponse{
+		Origins: origins,
+	}, nil
+}
+
+// RevokeOriginsRequest describes the origins to revoke. Only one of the fields
+// should be set.
+type RevokeOriginsRequest struct {
+	Origin string
+	All    bool
+}
+
+type RevokeOriginsResponse struct {
+	Revoked []ApprovedOrigin `json:"revoked"`
+}
+
+// RevokeOrigins removes the specified origins from the store, so the user is
+// asked again for an approval on their next connection.
+func RevokeOrigins(store OriginStore, req *RevokeOriginsRequest) (*RevokeOriginsResponse, error) {
+	origins, err := store.GetApprovedOrigins()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get the approved origins: %w", err)
+	}
+
+	resp := &RevokeOriginsResponse{
+		Revoked: []ApprovedOrigin{},
+	}
+	kept := []ApprovedOrigin{}
+	for _, origin := range origins {
+		if req.All || origin.Origin == req.Origin {
+			resp.Revoked = append(resp.Revoked, origin)
+			continue
+		}
+		kept = append(kept, origin)
+	}
+
+	if len(req.Origin) != 0 && len(resp.Revoked) == 0 {
+		return nil, ErrOriginNotFound
+	}
+
+	if err := store.SaveApprovedOrigins(kept); err != nil {
+		return nil, fmt.Errorf("couldn't save the approved origins: %w", err)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,143 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"code.vegaprotocol.io/vegawallet/service"
+	"code.vegaprotocol.io/vegawallet/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type memoryOriginStore struct {
+	origins []service.ApprovedOrigin
+}
+
+func (s *memoryOriginStore) GetApprovedOrigins() ([]service.ApprovedOrigin, error) {
+	return s.origins, nil
+}
+
+func (s *memoryOriginStore) SaveApprovedOrigins(origins []service.ApprovedOrigin) error {
+	s.origins = origins
+	return nil
+}
+
+func getTestOriginGuard(t *testing.T, store service.OriginStore) *service.OriginGuard {
+	t.Helper()
+
+	policy := mocks.NewMockConsentPolicy(make(chan service.ConsentRequest, 1), make(chan service.SentTransaction, 1))
+	guard, err := service.NewOriginGuard(zap.NewNop(), store, policy)
+	if err != nil {
+		t.Fatalf("couldn't create origin guard: %v", err)
+	}
+
+	return guard
+}
+
+func TestOriginGuard(t *testing.T) {
+	t.Run("Requests without origin are allowed", testOriginGuardRequestsWithoutOriginAreAllowed)
+	t.Run("Approved origins are persisted", testOriginGuardApprovedOriginsArePersisted)
+	t.Run("Rejected origins are not persisted", testOriginGuardRejectedOriginsAreNotPersisted)
+	t.Run("Stored origins are allowed", testOriginGuardStoredOriginsAreAllowed)
+	t.Run("Requests from rejected origins are forbidden", testOriginGuardRequestsFromRejectedOriginsAreForbidden)
+	t.Run("Revoked origins are no longer allowed once reloaded", testOriginGuardRevokedOriginsAreNoLongerAllowedOnceReloaded)
+}
+
+func testOriginGuardRequestsWithoutOriginAreAllowed(t *testing.T) {
+	// given
+	store := &memoryOriginStore{}
+	guard := getTestOriginGuard(t, store)
+
+	// when
+	allowed := guard.IsAllowed(context.Background(), "")
+
+	// then
+	assert.True(t, allowed)
+	assert.Empty(t, store.origins)
+}
+
+func testOriginGuardApprovedOriginsArePersisted(t *testing.T) {
+	// given
+	store := &memoryOriginStore{}
+	guard := getTestOriginGuard(t, store)
+
+	// when
+	allowed := guard.IsAllowed(context.Background(), "https://console.vega.xyz")
+
+	// then
+	assert.True(t, allowed)
+	require.Len(t, store.origins, 1)
+	assert.Equal(t, "https://console.vega.xyz", store.origins[0].Origin)
+}
+
+func testOriginGuardRejectedOriginsAreNotPersisted(t *testing.T) {
+	// given
+	store := &memoryOriginStore{}
+	guard := getTestOriginGuard(t, store)
+
+	// when
+	allowed := guard.IsAllowed(context.Background(), "toBeDeclined")
+
+	// then
+	assert.False(t, allowed)
+	assert.Empty(t, store.origins)
+}
+
+func testOriginGuardStoredOriginsAreAllowed(t *testing.T) {
+	// given
+	store := &memoryOriginStore{
+		origins: []service.ApprovedOrigin{
+			{Origin: "toBeDeclined", ApprovedAt: time.Now()},
+		},
+	}
+	guard := getTestOriginGuard(t, store)
+
+	// when
+	allowed := guard.IsAllowed(context.Background(), "toBeDeclined")
+
+	// then
+	assert.True(t, allowed)
+}
+
+func testOriginGuardRequestsFromRejectedOriginsAreForbidden(t *testing.T) {
+	// given
+	guard := getTestOriginGuard(t, &memoryOriginStore{})
+	handler := guard.Handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/keys", nil)
+	req.Header.Set("Origin", "toBeDeclined")
+	rec := httptest.NewRecorder()
+
+	// when
+	handler.ServeHTTP(rec, req)
+
+	// then
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func testOriginGuardRevokedOriginsAreNoLongerAllowedOnceReloaded(t *testing.T) {
+	// given
+	store := &memoryOriginStore{
+		origins: []service.ApprovedOrigin{
+			{Origin: "toBeDeclined", ApprovedAt: time.Now()},
+		},
+	}
+	guard := getTestOriginGuard(t, store)
+	require.True(t, guard.IsAllowed(context.Background(), "toBeDeclined"))
+
+	// setup
+	store.origins = []service.ApprovedOrigin{}
+
+	// when
+	err := guard.Reload()
+
+	// then
+	require.NoError(t, err)
+	assert.False(t, guard.IsAllowed(context.Background(), "toBeDeclined"))
+}
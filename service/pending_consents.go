@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	v1 "code.vegaprotocol.io/protos/vega/wallet/v1"
+	vgrand "code.vegaprotocol.io/shared/libs/rand"
+	"github.com/golang/protobuf/jsonpb"
+)
+
+// MaxPendingConsents is the maximum number of consent requests waiting for a
+// decision. The following ones are rejected, so a client can't exhaust the
+// memory of the service by flooding it with requests.
+const MaxPendingConsents = 100
+
+type PendingConsentKind string
+
+const (
+	PendingTransactionConsent PendingConsentKind = "transaction"
+	PendingBatchConsent       PendingConsentKind = "batch"
+	PendingConnectionConsent  PendingConsentKind = "connection"
+)
+
+// PendingConsent is a consent request waiting for a decision of the operator.
+type PendingConsent struct {
+	// ID is the ID of the transaction, or of the batch. A connection request
+	// gets its own ID.
+	ID   string             `json:"id"`
+	Kind PendingConsentKind `json:"kind"`
+	// Transactions holds the transaction, or the transactions of the batch,
+	// as submitted to the service.
+	Transactions []json.RawMessage `json:"transactions,omitempty"`
+	// Origin is the origin of the web page asking to connect.
+	Origin     string    `json:"origin,omitempty"`
+	ReceivedAt time.Time `json:"receivedAt"`
+}
+
+type PendingConsentsResponse struct {
+	Consents []PendingConsent `json:"consents"`
+}
+
+type pendingConsent struct {
+	consent  PendingConsent
+	decision chan bool
+}
+
+// PendingConsentPolicy queues the consent requests until an operator decides
+// on them through the admin API. Unlike the ExplicitConsentPolicy, it doesn't
+// need a terminal, so it suits the headless deployments.
+type PendingConsentPolicy struct {
+	// ctx is used to interrupt the wait for the decisions.
+	ctx context.Context
+
+	mu      sync.Mutex
+	pending map[string]*pendingConsent
+}
+
+func NewPendingConsentPolicy(ctx context.Context) *PendingConsentPolicy {
+	return &PendingConsentPolicy{
+		ctx:     ctx,
+		pending: map[string]*pendingConsent{},
+	}
+}
+
+func (p *PendingConsentPolicy) Ask(ctx context.Context, tx *v1.SubmitTransactionRequest, txID string, receivedAt time.Time) (bool, error) {
+	txs, err := marshalPendingTransactions(tx)
+	if err != nil {
+		return false, err
+	}
+
+	return p.wait(ctx, PendingConsent{
+		ID:           txID,
+		Kind:         PendingTransactionConsent,
+		Transactions: txs,
+		ReceivedAt:   receivedAt,
+	})
+}
+
+func (p *PendingConsentPolicy) AskBatch(ctx context.Context, txs []*v1.SubmitTransactionRequest, batchID string, receivedAt time.Time) (bool, error) {
+	rawTxs, err := marshalPendingTransactions(txs...)
+	if err != nil {
+		return false, err
+	}
+
+	return p.wait(ctx, PendingConsent{
+		ID:           batchID,
+		Kind:         PendingBatchConsent,
+		Transactions: rawTxs,
+		ReceivedAt:   receivedAt,
+	})
+}
+
+func (p *PendingConsentPolicy) AskConnection(ctx context.Context, origin string, receivedAt time.Time) (bool, error) {
+	return p.wait(ctx, PendingConsent{
+		ID:         vgrand.RandomStr(TXIDLENGTH),
+		Kind:       PendingConnectionConsent,
+		Origin:     origin,
+		ReceivedAt: receivedAt,
+	})
+}
+
+func (p *PendingConsentPolicy) Report(_ SentTransaction) {
+	// Nothing to report, as the outcome of the transactions is available in
+	// the transaction history.
+}
+
+// ListPendingConsents returns the consent requests waiting for a decision,
+// from the oldest to the latest.
+func (p *PendingConsentPolicy) ListPendingConsents() *PendingConsentsResponse {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	consents := make([]PendingConsent, 0, len(p.pending))
+	for _, pending := range p.pending {
+		consents = append(consents, pending.consent)
+	}
+
+	sort.Slice(consents, func(i, j int) bool {
+		return consents[i].ReceivedAt.Before(consents[j].ReceivedAt)
+	})
+
+	return &PendingConsentsResponse{
+		Consents: consents,
+	}
+}
+
+// Decide approves or rejects the pending consent request.
+func (p *PendingConsentPolicy) Decide(id string, approved bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pending, ok := p.pending[id]
+	if !ok {
+		return ErrPendingConsentNotFound
+	}
+	delete(p.pending, id)
+
+	// The channel is buffered, so the decision doesn't block if the request
+	// has just been interrupted.
+	pending.decision <- approved
+	return nil
+}
+
+// wait queues the consent request until it's decided. It's removed from the
+// queue when the service stops, or when the client asking for it disconnects.
+func (p *PendingConsentPolicy) wait(ctx context.Context, consent PendingConsent) (bool, error) {
+	pending := &pendingConsent{
+		consent:  consent,
+		decision: make(chan bool, 1),
+	}
+
+	p.mu.Lock()
+	if len(p.pending) >= MaxPendingConsents {
+		p.mu.Unlock()
+		return false, ErrTooManyPendingConsents
+	}
+	p.pending[consent.ID] = pending
+	p.mu.Unlock()
+
+	select {
+	case <-p.ctx.Done():
+		p.withdraw(consent.ID)
+		return false, ErrInterruptedConsentRequest
+	case <-ctx.Done():
+		p.withdraw(consent.ID)
+		return false, ErrInterruptedConsentRequest
+	case approved := <-pending.decision:
+		return approved, nil
+	}
+}
+
+func (p *PendingConsentPolicy) withdraw(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.pending, id)
+}
+
+func marshalPendingTransactions(txs ...*v1.SubmitTransactionRequest) ([]json.RawMessage, error) {
+	m := jsonpb.Marshaler{}
+	rawTxs := make([]json.RawMessage, 0, len(txs))
+	for _, tx := range txs {
+		rawTx, err := m.MarshalToString(tx)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't marshal the transaction: %w", err)
+		}
+		rawTxs = append(rawTxs, json.RawMessage(rawTx))
+	}
+	return rawTxs, nil
+}
@@ -6,6 +6,7 @@ import (
 
 	commandspb "code.vegaprotocol.io/protos/vega/commands/v1"
 	v1 "code.vegaprotocol.io/protos/vega/wallet/v1"
+	"code.vegaprotocol.io/vegawallet/metrics"
 )
 
 type ConsentConfirmation struct {
@@ -14,8 +15,20 @@ type ConsentConfirmation struct {
 }
 
 type ConsentRequest struct {
-	TxID         string
-	Tx           *v1.SubmitTransactionRequest
+	TxID string
+	Tx   *v1.SubmitTransactionRequest
+	// Batch is set, in place of Tx, when the consent is asked for a batch of
+	// transactions. TxID is then the ID of the batch, and a SentTransaction
+	// is reported for each of them.
+	Batch        []*v1.SubmitTransactionRequest
+	ReceivedAt   time.Time
+	Confirmation chan ConsentConfirmation
+}
+
+// ConnectionRequest is sent when a web page, from an origin that hasn't been
+// approved yet, tries to connect to the service.
+type ConnectionRequest struct {
+	Origin       string
 	ReceivedAt   time.Time
 	Confirmation chan ConsentConfirmation
 }
@@ -28,8 +41,13 @@ type SentTransaction struct {
 	SentAt time.Time
 }
 
+// Policy asks the consent of the user. The context is the one of the request
+// asking for it, so the policy can give up when the client disconnects.
 type Policy interface {
-	Ask(tx *v1.SubmitTransactionRequest, txID string, receivedAt time.Time) (bool, error)
+	Ask(ctx context.Context, tx *v1.SubmitTransactionRequest, txID string, receivedAt time.Time) (bool, error)
+	// AskBatch asks the consent once for all the transactions of the batch.
+	AskBatch(ctx context.Context, txs []*v1.SubmitTransactionRequest, batchID string, receivedAt time.Time) (bool, error)
+	AskConnection(ctx context.Context, origin string, receivedAt time.Time) (bool, error)
 	Report(tx SentTransaction)
 }
 
@@ -39,7 +57,17 @@ func NewAutomaticConsentPolicy() Policy {
 	return &AutomaticConsentPolicy{}
 }
 
-func (p *AutomaticConsentPolicy) Ask(_ *v1.SubmitTransactionRequest, _ string, _ time.Time) (bool, error) {
+func (p *AutomaticConsentPolicy) Ask(_ context.Context, _ *v1.SubmitTransactionRequest, _ string, _ time.Time) (bool, error) {
+	return true, nil
+}
+
+func (p *AutomaticConsentPolicy) AskBatch(_ context.Context, _ []*v1.SubmitTransactionRequest, _ string, _ time.Time) (bool, error) {
+	return true, nil
+}
+
+// AskConnection approves all the origins, as this policy is meant to be used
+// when the incoming requests are absolutely trusted.
+func (p *AutomaticConsentPolicy) AskConnection(_ context.Context, _ string, _ time.Time) (bool, error) {
 	return true, nil
 }
 
@@ -51,19 +79,24 @@ type ExplicitConsentPolicy struct {
 	// ctx is used to interrupt the wait for consent confirmation
 	ctx context.Context
 
-	consentRequestsChan  chan ConsentRequest
-	sentTransactionsChan chan SentTransaction
+	consentRequestsChan    chan ConsentRequest
+	connectionRequestsChan chan ConnectionRequest
+	sentTransactionsChan   chan SentTransaction
 }
 
-func NewExplicitConsentPolicy(ctx context.Context, consentRequests chan ConsentRequest, sentTransactions chan SentTransaction) Policy {
+func NewExplicitConsentPolicy(ctx context.Context, consentRequests chan ConsentRequest, connectionRequests chan ConnectionRequest, sentTransactions chan SentTransaction) Policy {
 	return &ExplicitConsentPolicy{
-		ctx:                  ctx,
-		consentRequestsChan:  consentRequests,
-		sentTransactionsChan: sentTransactions,
+		ctx:                    ctx,
+		consentRequestsChan:    consentRequests,
+		connectionRequestsChan: connectionRequests,
+		sentTransactionsChan:   sentTransactions,
 	}
 }
 
-func (p *ExplicitConsentPolicy) Ask(tx *v1.SubmitTransactionRequest, txID string, receivedAt time.Time) (bool, error) {
+// Ask sends the consent request to the terminal. The request context is
+// ignored, as the terminal waits for the outcome of the transactions it
+// approves, so a request can't be withdrawn once asked.
+func (p *ExplicitConsentPolicy) Ask(_ context.Context, tx *v1.SubmitTransactionRequest, txID string, receivedAt time.Time) (bool, error) {
 	confirmationChan := make(chan ConsentConfirmation, 1)
 	defer close(confirmationChan)
 
@@ -78,15 +111,50 @@ func (p *ExplicitConsentPolicy) Ask(tx *v1.SubmitTransactionRequest, txID string
 		return false, err
 	}
 
-	return p.receiveConsentConfirmation(consentRequest)
+	return p.receiveConsentConfirmation(consentRequest.Confirmation)
 }
 
-func (p *ExplicitConsentPolicy) receiveConsentConfirmation(consentRequest ConsentRequest) (bool, error) {
+func (p *ExplicitConsentPolicy) AskBatch(_ context.Context, txs []*v1.SubmitTransactionRequest, batchID string, receivedAt time.Time) (bool, error) {
+	confirmationChan := make(chan ConsentConfirmation, 1)
+	defer close(confirmationChan)
+
+	consentRequest := ConsentRequest{
+		TxID:         batchID,
+		Batch:        txs,
+		ReceivedAt:   receivedAt,
+		Confirmation: confirmationChan,
+	}
+
+	if err := p.sendConsentRequest(consentRequest); err != nil {
+		return false, err
+	}
+
+	return p.receiveConsentConfirmation(consentRequest.Confirmation)
+}
+
+func (p *ExplicitConsentPolicy) AskConnection(_ context.Context, origin string, receivedAt time.Time) (bool, error) {
+	confirmationChan := make(chan ConsentConfirmation, 1)
+	defer close(confirmationChan)
+
+	connectionRequest := ConnectionRequest{
+		Origin:       origin,
+		ReceivedAt:   receivedAt,
+		Confirmation: confirmationChan,
+	}
+
+	if err := p.sendConnectionRequest(connectionRequest); err != nil {
+		return false, err
+	}
+
+	return p.receiveConsentConfirmation(confirmationChan)
+}
+
+func (p *ExplicitConsentPolicy) receiveConsentConfirmation(confirmationChan chan ConsentConfirmation) (bool, error) {
 	for {
 		select {
 		case <-p.ctx.Done():
 			return false, ErrInterruptedConsentRequest
-		case decision := <-consentRequest.Confirmation:
+		case decision := <-confirmationChan:
 			return decision.Decision, nil
 		}
 	}
@@ -103,6 +171,66 @@ func (p *ExplicitConsentPolicy) sendConsentRequest(consentRequest ConsentRequest
 	}
 }
 
+func (p *ExplicitConsentPolicy) sendConnectionRequest(connectionRequest ConnectionRequest) error {
+	for {
+		select {
+		case <-p.ctx.Done():
+			return ErrInterruptedConsentRequest
+		case p.connectionRequestsChan <- connectionRequest:
+			return nil
+		}
+	}
+}
+
 func (p *ExplicitConsentPolicy) Report(tx SentTransaction) {
 	p.sentTransactionsChan <- tx
 }
+
+// MeteredPolicy records the time the wrapped policy takes to get the consent
+// of the user, and its outcome.
+type MeteredPolicy struct {
+	policy  Policy
+	metrics *metrics.Metrics
+}
+
+func NewMeteredPolicy(policy Policy, m *metrics.Metrics) Policy {
+	return &MeteredPolicy{
+		policy:  policy,
+		metrics: m,
+	}
+}
+
+func (p *MeteredPolicy) Ask(ctx context.Context, tx *v1.SubmitTransactionRequest, txID string, receivedAt time.Time) (bool, error) {
+	startedAt := time.Now()
+	approved, err := p.policy.Ask(ctx, tx, txID, receivedAt)
+	p.observe("transaction", startedAt, approved, err)
+	return approved, err
+}
+
+func (p *MeteredPolicy) AskBatch(ctx context.Context, txs []*v1.SubmitTransactionRequest, batchID string, receivedAt time.Time) (bool, error) {
+	startedAt := time.Now()
+	approved, err := p.policy.AskBatch(ctx, txs, batchID, receivedAt)
+	p.observe("batch", startedAt, approved, err)
+	return approved, err
+}
+
+func (p *MeteredPolicy) AskConnection(ctx context.Context, origin string, receivedAt time.Time) (bool, error) {
+	startedAt := time.Now()
+	approved, err := p.policy.AskConnection(ctx, origin, receivedAt)
+	p.observe("connection", startedAt, approved, err)
+	return approved, err
+}
+
+func (p *MeteredPolicy) Report(tx SentTransaction) {
+	p.policy.Report(tx)
+}
+
+func (p *MeteredPolicy) observe(request string, startedAt time.Time, approved bool, err error) {
+	outcome := metrics.ConsentRejected
+	if err != nil {
+		outcome = metrics.ConsentFailed
+	} else if approved {
+		outcome = metrics.ConsentApproved
+	}
+	p.metrics.ObserveConsent(request, outcome, time.Since(startedAt))
+}
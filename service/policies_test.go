@@ -18,6 +18,7 @@ func TestExplicitConsentPolicy(t *testing.T) {
 	t.Run("Requesting explicit consent succeeds", testRequestingExplicitConsentSucceeds)
 	t.Run("Canceling consent requests succeeds", testCancelingConsentRequestSucceeds)
 	t.Run("Reporting sent transaction succeeds", testReportingSentTransactionSucceeds)
+	t.Run("Requesting connection approval succeeds", testRequestingConnectionApprovalSucceeds)
 }
 
 func testRequestingExplicitConsentSucceeds(t *testing.T) {
@@ -25,10 +26,11 @@ func testRequestingExplicitConsentSucceeds(t *testing.T) {
 	txn := &walletpb.SubmitTransactionRequest{}
 	txID := vgrand.RandomStr(5)
 	consentRequestsChan := make(chan service.ConsentRequest, 1)
+	connectionRequestsChan := make(chan service.ConnectionRequest, 1)
 	sentTransactionsChan := make(chan service.SentTransaction, 1)
 
 	// setup
-	p := service.NewExplicitConsentPolicy(context.Background(), consentRequestsChan, sentTransactionsChan)
+	p := service.NewExplicitConsentPolicy(context.Background(), consentRequestsChan, connectionRequestsChan, sentTransactionsChan)
 
 	go func() {
 		req := <-consentRequestsChan
@@ -37,11 +39,35 @@ func testRequestingExplicitConsentSucceeds(t *testing.T) {
 	}()
 
 	// when
-	answer, err := p.Ask(txn, txID, time.Now())
+	answer, err := p.Ask(context.Background(), txn, txID, time.Now())
 	require.Nil(t, err)
 	require.False(t, answer)
 }
 
+func testRequestingConnectionApprovalSucceeds(t *testing.T) {
+	// given
+	origin := "https://" + vgrand.RandomStr(5) + ".com"
+	consentRequestsChan := make(chan service.ConsentRequest, 1)
+	connectionRequestsChan := make(chan service.ConnectionRequest, 1)
+	sentTransactionsChan := make(chan service.SentTransaction, 1)
+
+	// setup
+	p := service.NewExplicitConsentPolicy(context.Background(), consentRequestsChan, connectionRequestsChan, sentTransactionsChan)
+
+	go func() {
+		req := <-connectionRequestsChan
+		assert.Equal(t, origin, req.Origin)
+		req.Confirmation <- service.ConsentConfirmation{Decision: true}
+	}()
+
+	// when
+	answer, err := p.AskConnection(context.Background(), origin, time.Now())
+
+	// then
+	require.NoError(t, err)
+	require.True(t, answer)
+}
+
 func testCancelingConsentRequestSucceeds(t *testing.T) {
 	// given
 	ctx, cancelFn := context.WithCancel(context.Background())
@@ -51,17 +77,18 @@ func testCancelingConsentRequestSucceeds(t *testing.T) {
 	// We have to ensure channels are not blocking and preventing interruption
 	// when full.
 	consentRequestsChan := make(chan service.ConsentRequest, 1)
+	connectionRequestsChan := make(chan service.ConnectionRequest, 1)
 	sentTransactionsChan := make(chan service.SentTransaction, 1)
 
 	// setup
-	p := service.NewExplicitConsentPolicy(ctx, consentRequestsChan, sentTransactionsChan)
+	p := service.NewExplicitConsentPolicy(ctx, consentRequestsChan, connectionRequestsChan, sentTransactionsChan)
 
 	var wg sync.WaitGroup
 	for i := 0; i < 5; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			answer, err := p.Ask(txn, txID, time.Now())
+			answer, err := p.Ask(context.Background(), txn, txID, time.Now())
 			require.ErrorIs(t, err, service.ErrInterruptedConsentRequest)
 			assert.False(t, answer)
 		}()
@@ -78,10 +105,11 @@ func testReportingSentTransactionSucceeds(t *testing.T) {
 	txID := vgrand.RandomStr(5)
 	txHash := vgrand.RandomStr(5)
 	consentRequestsChan := make(chan service.ConsentRequest, 1)
+	connectionRequestsChan := make(chan service.ConnectionRequest, 1)
 	sentTransactionsChan := make(chan service.SentTransaction, 1)
 
 	// setup
-	p := service.NewExplicitConsentPolicy(context.Background(), consentRequestsChan, sentTransactionsChan)
+	p := service.NewExplicitConsentPolicy(context.Background(), consentRequestsChan, connectionRequestsChan, sentTransactionsChan)
 
 	// when
 	p.Report(service.SentTransaction{
@@ -2,6 +2,7 @@ package service
 
 import (
 	"bytes"
+	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -9,6 +10,8 @@ import (
 	"fmt"
 )
 
+// RSAKeys holds the PEM-encoded key pair signing the tokens. Despite its name,
+// kept for compatibility, it can also hold an Ed25519 key pair.
 type RSAKeys struct {
 	Pub  []byte
 	Priv []byte
@@ -38,6 +41,22 @@ func GenerateRSAKeys() (*RSAKeys, error) {
 	}, nil
 }
 
+// VerifyRSAKeys ensures the keys can be parsed and belong to the same pair,
+// whatever their algorithm.
+func VerifyRSAKeys(keys *RSAKeys) error {
+	current, priv, err := parseSigningKeys(keys)
+	if err != nil {
+		return err
+	}
+
+	pub, ok := priv.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(current.pub) {
+		return ErrRSAKeysDoNotMatch
+	}
+
+	return nil
+}
+
 func toPrivatePKCS1Key(key *rsa.PrivateKey) ([]byte, error) {
 	privateKey := &pem.Block{
 		Type:  "RSA PRIVATE KEY",
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"fmt"
+
+	"code.vegaprotocol.io/protos/commands"
+	walletpb "code.vegaprotocol.io/protos/vega/wallet/v1"
+	wcommands "code.vegaprotocol.io/vegawallet/commands"
+)
+
+// Scopes restricts what a token allows. An empty list of public keys or
+// commands means no restriction.
+type Scopes struct {
+	// PublicKeys are the only keys the token can use.
+	PublicKeys []string `json:"publicKeys,omitempty"`
+	// Commands are the only commands the token can send, using their names as
+	// in the transaction request, like "orderSubmission".
+	Commands []string `json:"commands,omitempty"`
+	// ReadOnly forbids any operation that signs or modifies the wallet.
+	ReadOnly bool `json:"readOnly,omitempty"`
+}
+
+// ForbiddenByScopesError is returned when the operation is not allowed by the
+// scopes of the token.
+type ForbiddenByScopesError struct {
+	Reason string
+}
+
+func NewForbiddenByScopesError(reason string) ForbiddenByScopesError {
+	return ForbiddenByScopesError{
+		Reason: reason,
+	}
+}
+
+func (e ForbiddenByScopesError) Error() string {
+	return fmt.Sprintf("the token doesn't allow this operation: %s", e.Reason)
+}
+
+func (s *Scopes) Validate(errs commands.Errors) {
+	for i, pubKey := range s.PublicKeys {
+		if len(pubKey) == 0 {
+			errs.AddForProperty(fmt.Sprintf("scopes.publicKeys.%d", i), commands.ErrIsRequired)
+		}
+	}
+	for i, command := range s.Commands {
+		if !wcommands.IsCommandName(command) {
+			errs.AddForProperty(fmt.Sprintf("scopes.commands.%d", i), commands.ErrIsNotSupported)
+		}
+	}
+}
+
+// CanGenerateKey verifies the token can generate keys. A token restricted to
+// a set of keys can't generate new ones.
+func (s *Scopes) CanGenerateKey() error {
+	if s == nil {
+		return nil
+	}
+	if s.ReadOnly {
+		return NewForbiddenByScopesError("the token is read-only")
+	}
+	if len(s.PublicKeys) != 0 {
+		return NewForbiddenByScopesError("the token is restricted to specific keys")
+	}
+	return nil
+}
+
+// CanUnlockWallet verifies the token can unlock its wallets. A read-only
+// token, or a token restricted to a set of keys, can't, as it would act on
+// the whole wallet.
+func (s *Scopes) CanUnlockWallet() error {
+	return s.canManageWallet()
+}
+
+// CanRevokeSessions verifies the token can revoke all the sessions of its
+// wallets. A read-only token, or a token restricted to a set of keys, can't,
+// as it would end the sessions of the other clients.
+func (s *Scopes) CanRevokeSessions() error {
+	return s.canManageWallet()
+}
+
+func (s *Scopes) canManageWallet() error {
+	if s == nil {
+		return nil
+	}
+	if s.ReadOnly {
+		return NewForbiddenByScopesError("the token is read-only")
+	}
+	if len(s.PublicKeys) != 0 {
+		return NewForbiddenByScopesError("the token is restricted to specific keys")
+	}
+	return nil
+}
+
+// CanUseKey verifies the token can sign with, or update, the specified key.
+func (s *Scopes) CanUseKey(pubKey string) error {
+	if s == nil {
+		return nil
+	}
+	if s.ReadOnly {
+		return NewForbiddenByScopesError("the token is read-only")
+	}
+	if len(s.PublicKeys) == 0 {
+		return nil
+	}
+	for _, k := range s.PublicKeys {
+		if k == pubKey {
+			return nil
+		}
+	}
+	return NewForbiddenByScopesError(fmt.Sprintf("the key %s is not allowed", pubKey))
+}
+
+// CanSendTransaction verifies the token can sign the specified transaction.
+func (s *Scopes) CanSendTransaction(req *walletpb.SubmitTransactionRequest) error {
+	if s == nil {
+		return nil
+	}
+	if err := s.CanUseKey(req.PubKey); err != nil {
+		return err
+	}
+	if len(s.Commands) == 0 {
+		return nil
+	}
+	name := wcommands.CommandName(req)
+	for _, c := range s.Commands {
+		if c == name {
+			return nil
+		}
+	}
+	return NewForbiddenByScopesError(fmt.Sprintf("the command %s is not allowed", name))
+}
+
+// CanSeeKey verifies the token gives access to the specified key. Unlike
+// CanUseKey, it is allowed to read-only tokens.
+func (s *Scopes) CanSeeKey(pubKey string) error {
+	if s == nil || len(s.PublicKeys) == 0 {
+		return nil
+	}
+	for _, k := range s.PublicKeys {
+		if k == pubKey {
+			return nil
+		}
+	}
+	return NewForbiddenByScopesError(fmt.Sprintf("the key %s is not allowed", pubKey))
+}
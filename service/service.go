@@ -2,13 +2,16 @@ package service
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"sync"
 	"time"
 
 	vgrand "code.vegaprotocol.io/shared/libs/rand"
@@ -19,6 +22,7 @@ import (
 	walletpb "code.vegaprotocol.io/protos/vega/wallet/v1"
 	vgcrypto "code.vegaprotocol.io/shared/libs/crypto"
 	wcommands "code.vegaprotocol.io/vegawallet/commands"
+	"code.vegaprotocol.io/vegawallet/metrics"
 	"code.vegaprotocol.io/vegawallet/network"
 	"code.vegaprotocol.io/vegawallet/version"
 	"code.vegaprotocol.io/vegawallet/wallet"
@@ -33,12 +37,24 @@ type Service struct {
 	*httprouter.Router
 
 	network     *network.Network
+	networkMu   sync.RWMutex
 	log         *zap.Logger
+	auditLog    *zap.Logger
 	server      *http.Server
 	handler     WalletHandler
 	auth        Auth
 	nodeForward NodeForward
 	policy      Policy
+	origins     *OriginGuard
+	history     TransactionHistory
+	rpcMethods  map[string]jsonRPCMethod
+	events      *TransactionEvents
+	routes      []Route
+	// idempotency remembers the outcome of the submissions holding an
+	// idempotency key.
+	idempotency *idempotentSubmissions
+	// metrics records the requests and the transactions, if set.
+	metrics *metrics.Metrics
 }
 
 // CreateWalletRequest describes the request for CreateWallet.
@@ -59,6 +75,8 @@ func ParseCreateWalletRequest(r *http.Request) (*CreateWalletRequest, commands.E
 
 	if len(req.Wallet) == 0 {
 		errs.AddForProperty("wallet", commands.ErrIsRequired)
+	} else if err := wallet.ValidateName(req.Wallet); err != nil {
+		errs.AddForProperty("wallet", err)
 	}
 
 	if len(req.Passphrase) == 0 {
@@ -97,6 +115,8 @@ func ParseImportWalletRequest(r *http.Request) (*ImportWalletRequest, commands.E
 
 	if len(req.Wallet) == 0 {
 		errs.AddForProperty("wallet", commands.ErrIsRequired)
+	} else if err := wallet.ValidateName(req.Wallet); err != nil {
+		errs.AddForProperty("wallet", err)
 	}
 
 	if len(req.Passphrase) == 0 {
@@ -122,22 +142,77 @@ func ParseImportWalletRequest(r *http.Request) (*ImportWalletRequest, commands.E
 type LoginWalletRequest struct {
 	Wallet     string `json:"wallet"`
 	Passphrase string `json:"passphrase"`
+	// TwoFactorCode is the current TOTP code, or a recovery code. It's only
+	// required when the wallet has the two-factor authentication enabled.
+	TwoFactorCode string `json:"twoFactorCode,omitempty"`
+	// Wallets unlocks several wallets into the same session, in place of
+	// Wallet and Passphrase. The first one is the default wallet.
+	Wallets []WalletCredentials `json:"wallets,omitempty"`
+	// Scopes restricts the token. If not set, the token has full access.
+	Scopes *Scopes `json:"scopes,omitempty"`
 }
 
-func ParseLoginWalletRequest(r *http.Request) (*LoginWalletRequest, commands.Errors) {
-	errs := commands.NewErrors()
+// WalletCredentials describes a wallet to unlock, in a LoginWalletRequest.
+type WalletCredentials struct {
+	Wallet        string `json:"wallet"`
+	Passphrase    string `json:"passphrase"`
+	TwoFactorCode string `json:"twoFactorCode,omitempty"`
+}
+
+// Credentials returns the wallets to unlock, the default one first.
+func (r *LoginWalletRequest) Credentials() []WalletCredentials {
+	if len(r.Wallets) != 0 {
+		return r.Wallets
+	}
+	return []WalletCredentials{{
+		Wallet:        r.Wallet,
+		Passphrase:    r.Passphrase,
+		TwoFactorCode: r.TwoFactorCode,
+	}}
+}
 
+func ParseLoginWalletRequest(r *http.Request) (*LoginWalletRequest, commands.Errors) {
 	req := &LoginWalletRequest{}
 	if err := unmarshalBody(r, &req); err != nil {
-		return nil, errs.FinalAdd(err)
+		return nil, commands.NewErrors().FinalAdd(err)
 	}
 
-	if len(req.Wallet) == 0 {
-		errs.AddForProperty("wallet", commands.ErrIsRequired)
+	return checkLoginWalletRequest(req)
+}
+
+func checkLoginWalletRequest(req *LoginWalletRequest) (*LoginWalletRequest, commands.Errors) {
+	errs := commands.NewErrors()
+
+	if len(req.Wallets) != 0 {
+		if len(req.Wallet) != 0 || len(req.Passphrase) != 0 || len(req.TwoFactorCode) != 0 {
+			errs.AddForProperty("wallets", ErrIsMutuallyExclusiveWithWallet)
+		}
+
+		seen := map[string]bool{}
+		for i, creds := range req.Wallets {
+			if len(creds.Wallet) == 0 {
+				errs.AddForProperty(fmt.Sprintf("wallets.%d.wallet", i), commands.ErrIsRequired)
+			} else if seen[creds.Wallet] {
+				errs.AddForProperty(fmt.Sprintf("wallets.%d.wallet", i), ErrIsDuplicated)
+			}
+			seen[creds.Wallet] = true
+
+			if len(creds.Passphrase) == 0 {
+				errs.AddForProperty(fmt.Sprintf("wallets.%d.passphrase", i), commands.ErrIsRequired)
+			}
+		}
+	} else {
+		if len(req.Wallet) == 0 {
+			errs.AddForProperty("wallet", commands.ErrIsRequired)
+		}
+
+		if len(req.Passphrase) == 0 {
+			errs.AddForProperty("passphrase", commands.ErrIsRequired)
+		}
 	}
 
-	if len(req.Passphrase) == 0 {
-		errs.AddForProperty("passphrase", commands.ErrIsRequired)
+	if req.Scopes != nil {
+		req.Scopes.Validate(errs)
 	}
 
 	if !errs.Empty() {
@@ -153,17 +228,56 @@ type TaintKeyRequest struct {
 }
 
 func ParseTaintKeyRequest(r *http.Request, keyID string) (*TaintKeyRequest, commands.Errors) {
+	req := &TaintKeyRequest{}
+	if err := unmarshalBody(r, &req); err != nil {
+		errs := commands.NewErrors()
+		if len(keyID) == 0 {
+			errs.AddForProperty("keyid", commands.ErrIsRequired)
+		}
+		return nil, errs.FinalAdd(err)
+	}
+
+	return checkTaintKeyRequest(req, keyID)
+}
+
+func checkTaintKeyRequest(req *TaintKeyRequest, keyID string) (*TaintKeyRequest, commands.Errors) {
 	errs := commands.NewErrors()
 
 	if len(keyID) == 0 {
 		errs.AddForProperty("keyid", commands.ErrIsRequired)
 	}
 
-	req := &TaintKeyRequest{}
+	if len(req.Passphrase) == 0 {
+		errs.AddForProperty("passphrase", commands.ErrIsRequired)
+	}
+
+	if !errs.Empty() {
+		return nil, errs
+	}
+
+	return req, errs
+}
+
+// UnlockWalletRequest describes the request for UnlockWallet.
+type UnlockWalletRequest struct {
+	// Wallet is the wallet to unlock. If not set, the default wallet of the
+	// session is unlocked.
+	Wallet     string `json:"wallet,omitempty"`
+	Passphrase string `json:"passphrase"`
+}
+
+func ParseUnlockWalletRequest(r *http.Request) (*UnlockWalletRequest, commands.Errors) {
+	req := &UnlockWalletRequest{}
 	if err := unmarshalBody(r, &req); err != nil {
-		return nil, errs.FinalAdd(err)
+		return nil, commands.NewErrors().FinalAdd(err)
 	}
 
+	return checkUnlockWalletRequest(req)
+}
+
+func checkUnlockWalletRequest(req *UnlockWalletRequest) (*UnlockWalletRequest, commands.Errors) {
+	errs := commands.NewErrors()
+
 	if len(req.Passphrase) == 0 {
 		errs.AddForProperty("passphrase", commands.ErrIsRequired)
 	}
@@ -177,18 +291,25 @@ func ParseTaintKeyRequest(r *http.Request, keyID string) (*TaintKeyRequest, comm
 
 // GenKeyPairRequest describes the request for GenerateKeyPair.
 type GenKeyPairRequest struct {
+	// Wallet is the wallet to generate the key pair into. If not set, the
+	// default wallet of the session is used.
+	Wallet     string        `json:"wallet,omitempty"`
 	Passphrase string        `json:"passphrase"`
 	Meta       []wallet.Meta `json:"meta"`
 }
 
 func ParseGenKeyPairRequest(r *http.Request) (*GenKeyPairRequest, commands.Errors) {
-	errs := commands.NewErrors()
-
 	req := &GenKeyPairRequest{}
 	if err := unmarshalBody(r, &req); err != nil {
-		return nil, errs.FinalAdd(err)
+		return nil, commands.NewErrors().FinalAdd(err)
 	}
 
+	return checkGenKeyPairRequest(req)
+}
+
+func checkGenKeyPairRequest(req *GenKeyPairRequest) (*GenKeyPairRequest, commands.Errors) {
+	errs := commands.NewErrors()
+
 	if len(req.Passphrase) == 0 {
 		errs.AddForProperty("passphrase", commands.ErrIsRequired)
 	}
@@ -207,17 +328,25 @@ type UpdateMetaRequest struct {
 }
 
 func ParseUpdateMetaRequest(r *http.Request, keyID string) (*UpdateMetaRequest, commands.Errors) {
+	req := &UpdateMetaRequest{}
+	if err := unmarshalBody(r, &req); err != nil {
+		errs := commands.NewErrors()
+		if len(keyID) == 0 {
+			errs.AddForProperty("keyid", commands.ErrIsRequired)
+		}
+		return nil, errs.FinalAdd(err)
+	}
+
+	return checkUpdateMetaRequest(req, keyID)
+}
+
+func checkUpdateMetaRequest(req *UpdateMetaRequest, keyID string) (*UpdateMetaRequest, commands.Errors) {
 	errs := commands.NewErrors()
 
 	if len(keyID) == 0 {
 		errs.AddForProperty("keyid", commands.ErrIsRequired)
 	}
 
-	req := &UpdateMetaRequest{}
-	if err := unmarshalBody(r, &req); err != nil {
-		return nil, errs.FinalAdd(err)
-	}
-
 	if len(req.Passphrase) == 0 {
 		errs.AddForProperty("passphrase", commands.ErrIsRequired)
 	}
@@ -241,13 +370,17 @@ type SignAnyRequest struct {
 }
 
 func ParseSignAnyRequest(r *http.Request) (*SignAnyRequest, commands.Errors) {
-	errs := commands.NewErrors()
-
 	req := &SignAnyRequest{}
 	if err := unmarshalBody(r, &req); err != nil {
-		return nil, errs.FinalAdd(err)
+		return nil, commands.NewErrors().FinalAdd(err)
 	}
 
+	return checkSignAnyRequest(req)
+}
+
+func checkSignAnyRequest(req *SignAnyRequest) (*SignAnyRequest, commands.Errors) {
+	errs := commands.NewErrors()
+
 	if len(req.InputData) == 0 {
 		errs.AddForProperty("inputData", commands.ErrIsRequired)
 	}
@@ -284,13 +417,17 @@ type VerifyAnyRequest struct {
 }
 
 func ParseVerifyAnyRequest(r *http.Request) (*VerifyAnyRequest, commands.Errors) {
-	errs := commands.NewErrors()
-
 	req := &VerifyAnyRequest{}
 	if err := unmarshalBody(r, &req); err != nil {
-		return nil, errs.FinalAdd(err)
+		return nil, commands.NewErrors().FinalAdd(err)
 	}
 
+	return checkVerifyAnyRequest(req)
+}
+
+func checkVerifyAnyRequest(req *VerifyAnyRequest) (*VerifyAnyRequest, commands.Errors) {
+	errs := commands.NewErrors()
+
 	if len(req.InputData) == 0 {
 		errs.AddForProperty("inputData", commands.ErrIsRequired)
 	} else {
@@ -325,12 +462,16 @@ func ParseVerifyAnyRequest(r *http.Request) (*VerifyAnyRequest, commands.Errors)
 }
 
 func ParseSubmitTransactionRequest(r *http.Request) (*walletpb.SubmitTransactionRequest, commands.Errors) {
+	return parseSubmitTransactionRequest(r.Body)
+}
+
+func parseSubmitTransactionRequest(body io.Reader) (*walletpb.SubmitTransactionRequest, commands.Errors) {
 	errs := commands.NewErrors()
 
 	req := &walletpb.SubmitTransactionRequest{
 		Propagate: true,
 	}
-	if err := jsonpb.Unmarshal(r.Body, req); err != nil {
+	if err := jsonpb.Unmarshal(body, req); err != nil {
 		return nil, errs.FinalAdd(err)
 	}
 
@@ -348,7 +489,16 @@ type KeyResponse struct {
 
 // KeysResponse describes the response to a request that returns a list of keys.
 type KeysResponse struct {
+	// Keys holds the keys of all the wallets of the token.
 	Keys []wallet.PublicKey `json:"keys"`
+	// Wallets holds the same keys, grouped by wallet.
+	Wallets []WalletKeys `json:"wallets"`
+}
+
+// WalletKeys describes the public keys of a wallet.
+type WalletKeys struct {
+	Wallet string             `json:"wallet"`
+	Keys   []wallet.PublicKey `json:"keys"`
 }
 
 // SignAnyResponse describes the response for SignAny.
@@ -362,6 +512,25 @@ type VerifyAnyResponse struct {
 	Valid bool `json:"success"`
 }
 
+// SendTransactionResponse describes the response for SignTx, SignTxSync and
+// SignTxCommit.
+type SendTransactionResponse struct {
+	TxHash     string                  `json:"txHash"`
+	ReceivedAt time.Time               `json:"receivedAt"`
+	SentAt     time.Time               `json:"sentAt"`
+	TxID       string                  `json:"txId"`
+	Tx         *commandspb.Transaction `json:"tx"`
+}
+
+// CheckTransactionResponse describes the response for CheckTx.
+type CheckTransactionResponse struct {
+	Success   bool                    `json:"success"`
+	Code      uint32                  `json:"code"`
+	GasWanted int64                   `json:"gas_wanted"`
+	GasUsed   int64                   `json:"gas_used"`
+	Tx        *commandspb.Transaction `json:"tx"`
+}
+
 // SuccessResponse describes the response to a request that returns a simple true/false answer.
 type SuccessResponse struct {
 	Success bool `json:"success"`
@@ -372,6 +541,29 @@ type TokenResponse struct {
 	Token string `json:"token"`
 }
 
+// TokenInfoResponse describes the response for IntrospectToken.
+type TokenInfoResponse struct {
+	Wallet string `json:"wallet"`
+	// Wallets holds all the wallets the token gives access to, the default
+	// one first.
+	Wallets []string `json:"wallets"`
+	// Session is set when the token has been obtained by logging in.
+	Session string `json:"session,omitempty"`
+	// APIKey is set when the token is an API key.
+	APIKey string `json:"apiKey,omitempty"`
+	// ExpiresAt is not set for API keys, as they don't expire.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	Scopes    *Scopes    `json:"scopes,omitempty"`
+	// Locked is true when one of the wallets has to be unlocked with its
+	// passphrase before being used, like after being idle for too long.
+	Locked bool `json:"locked"`
+}
+
+// SessionsResponse describes the response for ListSessions.
+type SessionsResponse struct {
+	Sessions []SessionSummary `json:"sessions"`
+}
+
 // VersionResponse describes the response to a request that returns app version info.
 type VersionResponse struct {
 	Version     string `json:"version"`
@@ -388,8 +580,10 @@ type NetworkResponse struct {
 type WalletHandler interface {
 	CreateWallet(name, passphrase string) (string, error)
 	ImportWallet(name, passphrase, recoveryPhrase string, version uint32) error
-	LoginWallet(name, passphrase string) error
-	LogoutWallet(name string)
+	LoginWallet(name, passphrase, twoFactorCode string) error
+	AbortLogin(name string)
+	UnlockWallet(name, passphrase string) error
+	IsWalletUnlocked(name string) bool
 	SecureGenerateKeyPair(name, passphrase string, meta []wallet.Meta) (string, error)
 	GetPublicKey(name, pubKey string) (wallet.PublicKey, error)
 	ListPublicKeys(name string) ([]wallet.PublicKey, error)
@@ -403,9 +597,16 @@ type WalletHandler interface {
 // Auth ...
 //go:generate go run github.com/golang/mock/mockgen -destination mocks/auth_mock.go -package mocks code.vegaprotocol.io/vegawallet/service Auth
 type Auth interface {
-	NewSession(name string) (string, error)
-	VerifyToken(token string) (string, error)
-	Revoke(token string) (string, error)
+	NewSession(name, origin string) (string, error)
+	NewScopedSession(name, origin string, scopes *Scopes) (string, error)
+	NewMultiWalletSession(names []string, origin string, scopes *Scopes) (string, error)
+	VerifyToken(token, origin string) (string, error)
+	VerifyScopedToken(token, origin string) ([]string, *Scopes, error)
+	IntrospectToken(token, origin string) (*TokenInfoResponse, error)
+	RefreshToken(token, origin string) (string, error)
+	ListWalletSessions(token, origin string) ([]SessionSummary, error)
+	Revoke(token, origin string) (string, error)
+	RevokeWalletSessions(wallet string)
 }
 
 // NodeForward ...
@@ -417,29 +618,62 @@ type NodeForward interface {
 	LastBlockHeightAndHash(context.Context) (*api.LastBlockHeightResponse, int, error)
 }
 
-func NewService(log *zap.Logger, net *network.Network, h WalletHandler, a Auth, n NodeForward, policy Policy) (*Service, error) {
+func NewService(log *zap.Logger, net *network.Network, h WalletHandler, a Auth, n NodeForward, policy Policy, origins OriginStore, history TransactionHistory) (*Service, error) {
+	guard, err := NewOriginGuard(log.Named("origins"), origins, policy)
+	if err != nil {
+		return nil, err
+	}
+
 	s := &Service{
 		Router:      httprouter.New(),
 		log:         log,
+		auditLog:    log.Named("audit"),
 		handler:     h,
 		auth:        a,
 		nodeForward: n,
 		network:     net,
 		policy:      policy,
+		origins:     guard,
+		history:     history,
+		events:      NewTransactionEvents(log.Named("events")),
+		idempotency: newIdempotentSubmissions(),
 	}
 
 	s.server = &http.Server{
-		Addr:    fmt.Sprintf("%s:%v", net.Host, net.Port),
-		Handler: cors.AllowAll().Handler(s),
+		Addr: fmt.Sprintf("%s:%v", net.Host, net.Port),
+		Handler: cors.New(cors.Options{
+			AllowOriginRequestFunc: func(r *http.Request, origin string) bool {
+				return guard.IsAllowed(r.Context(), origin)
+			},
+			AllowedMethods: []string{
+				http.MethodHead,
+				http.MethodGet,
+				http.MethodPost,
+				http.MethodPut,
+				http.MethodPatch,
+				http.MethodDelete,
+			},
+			AllowedHeaders: []string{"*"},
+		}).Handler(guard.Handler(s)),
+		// The TLS configuration is set once, here, as the Unix domain socket
+		// and the TLS listener are served concurrently by the same server.
+		TLSConfig: &tls.Config{
+			MinVersion: tls.VersionTLS12,
+		},
 	}
 
 	s.handle(http.MethodPost, "/api/v1/auth/token", s.Login)
+	s.handle(http.MethodGet, "/api/v1/auth/token", extractToken(s.IntrospectToken))
 	s.handle(http.MethodDelete, "/api/v1/auth/token", extractToken(s.Revoke))
+	s.handle(http.MethodPost, "/api/v1/auth/token/refresh", extractToken(s.RefreshToken))
+	s.handle(http.MethodGet, "/api/v1/auth/sessions", extractToken(s.ListSessions))
+	s.handle(http.MethodDelete, "/api/v1/auth/sessions", extractToken(s.RevokeSessions))
 
 	s.handle(http.MethodGet, "/api/v1/network", s.GetNetwork)
 
 	s.handle(http.MethodPost, "/api/v1/wallets", s.CreateWallet)
 	s.handle(http.MethodPost, "/api/v1/wallets/import", s.ImportWallet)
+	s.handle(http.MethodPost, "/api/v1/wallets/unlock", extractToken(s.UnlockWallet))
 
 	s.handle(http.MethodGet, "/api/v1/keys", extractToken(s.ListPublicKeys))
 	s.handle(http.MethodPost, "/api/v1/keys", extractToken(s.GenerateKeyPair))
@@ -451,20 +685,62 @@ func NewService(log *zap.Logger, net *network.Network, h WalletHandler, a Auth,
 	s.handle(http.MethodPost, "/api/v1/command/sync", extractToken(s.SignTxSync))
 	s.handle(http.MethodPost, "/api/v1/command/check", extractToken(s.CheckTx))
 	s.handle(http.MethodPost, "/api/v1/command/commit", extractToken(s.SignTxCommit))
+	s.handle(http.MethodPost, "/api/v1/command/batch", extractToken(s.SignTxBatch))
 	s.handle(http.MethodPost, "/api/v1/sign", extractToken(s.SignAny))
 	s.handle(http.MethodPost, "/api/v1/verify", s.VerifyAny)
 
+	s.handle(http.MethodGet, "/api/v1/transactions", extractToken(s.ListTransactions))
+
+	s.handle(http.MethodGet, "/api/v1/events", extractToken(s.StreamEvents))
+
 	s.handle(http.MethodGet, "/api/v1/version", s.Version)
 	s.handle(http.MethodGet, "/api/v1/status", s.Health)
+	s.handle(http.MethodGet, "/api/v1/openapi.json", s.GetOpenAPIDocument)
+
+	s.registerJSONRPCMethods()
+	s.handle(http.MethodPost, "/api/v2/requests", s.HandleJSONRPC)
+	s.handle(http.MethodGet, "/api/v2/methods", s.ListJSONRPCMethods)
 
 	return s, nil
 }
 
+// Routes returns the routes registered on the router, so they can be checked
+// against the endpoints described by the OpenAPI document.
+func (s *Service) Routes() []Route {
+	routes := make([]Route, len(s.routes))
+	copy(routes, s.routes)
+	return routes
+}
+
+// UseMetrics records the metrics of the requests and the transactions. They
+// are served by a metrics.Server, not by the service.
+func (s *Service) UseMetrics(m *metrics.Metrics) {
+	s.metrics = m
+}
+
 func (s *Service) Start() error {
 	return s.server.ListenAndServe()
 }
 
+// StartUnixSocket serves the API on a Unix domain socket. It can be called
+// alongside Start or StartTLS, as the listeners share the same server.
+func (s *Service) StartUnixSocket(cfg network.SocketConfig) error {
+	listener, err := ListenUnixSocket(cfg)
+	if err != nil {
+		return err
+	}
+	return s.server.Serve(listener)
+}
+
+// StartTLS serves HTTPS with the specified certificate and key files.
+func (s *Service) StartTLS(certFile, keyFile string) error {
+	return s.server.ListenAndServeTLS(certFile, keyFile)
+}
+
 func (s *Service) Stop() error {
+	// The event streams never end on their own, so they have to be closed for
+	// the shutdown to complete.
+	s.events.Close()
 	return s.server.Shutdown(context.Background())
 }
 
@@ -481,8 +757,9 @@ func (s *Service) CreateWallet(w http.ResponseWriter, r *http.Request, _ httprou
 		return
 	}
 
-	token, err := s.auth.NewSession(req.Wallet)
+	token, err := s.auth.NewSession(req.Wallet, r.Header.Get("Origin"))
 	if err != nil {
+		s.handler.AbortLogin(req.Wallet)
 		s.writeInternalError(w, err)
 		return
 	}
@@ -506,8 +783,9 @@ func (s *Service) ImportWallet(w http.ResponseWriter, r *http.Request, _ httprou
 		return
 	}
 
-	token, err := s.auth.NewSession(req.Wallet)
+	token, err := s.auth.NewSession(req.Wallet, r.Header.Get("Origin"))
 	if err != nil {
+		s.handler.AbortLogin(req.Wallet)
 		s.writeInternalError(w, err)
 		return
 	}
@@ -522,33 +800,170 @@ func (s *Service) Login(w http.ResponseWriter, r *http.Request, _ httprouter.Par
 		return
 	}
 
-	err := s.handler.LoginWallet(req.Wallet, req.Passphrase)
+	resp, status, err := s.login(req, r.Header.Get("Origin"))
+	if err != nil {
+		s.writeStatusError(w, err, status)
+		return
+	}
+
+	s.writeSuccess(w, resp)
+}
+
+// login unlocks the wallets of the request, and opens a session on them. On
+// failure, it returns the HTTP status the error is reported with.
+func (s *Service) login(req *LoginWalletRequest, origin string) (*TokenResponse, int, error) {
+	credentials := req.Credentials()
+	names := make([]string, 0, len(credentials))
+	for _, creds := range credentials {
+		if err := s.handler.LoginWallet(creds.Wallet, creds.Passphrase, creds.TwoFactorCode); err != nil {
+			s.abortLogins(names)
+			return nil, http.StatusForbidden, err
+		}
+		names = append(names, creds.Wallet)
+	}
+
+	var token string
+	var err error
+	if len(names) > 1 {
+		token, err = s.auth.NewMultiWalletSession(names, origin, req.Scopes)
+	} else if req.Scopes != nil {
+		token, err = s.auth.NewScopedSession(names[0], origin, req.Scopes)
+	} else {
+		token, err = s.auth.NewSession(names[0], origin)
+	}
+	if err != nil {
+		s.abortLogins(names)
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return &TokenResponse{Token: token}, 0, nil
+}
+
+// UnlockWallet decrypts again the wallet of the token, after it has been locked
+// for inactivity. The sessions of the wallet are kept.
+func (s *Service) UnlockWallet(t string, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	req, errs := ParseUnlockWalletRequest(r)
+	if !errs.Empty() {
+		s.writeBadRequest(w, errs)
+		return
+	}
+
+	names, scopes, err := s.auth.VerifyScopedToken(t, r.Header.Get("Origin"))
+	if err != nil {
+		s.writeForbiddenError(w, err)
+		return
+	}
+
+	if status, err := s.unlockWallet(names, scopes, req); err != nil {
+		s.writeStatusError(w, err, status)
+		return
+	}
+
+	s.writeSuccess(w, nil)
+}
+
+func (s *Service) unlockWallet(names []string, scopes *Scopes, req *UnlockWalletRequest) (int, error) {
+	if err := scopes.CanUnlockWallet(); err != nil {
+		return http.StatusForbidden, err
+	}
+
+	name, err := selectWallet(names, req.Wallet)
 	if err != nil {
+		return http.StatusForbidden, err
+	}
+
+	if err := s.handler.UnlockWallet(name, req.Passphrase); err != nil {
+		return http.StatusForbidden, err
+	}
+
+	return 0, nil
+}
+
+func (s *Service) Revoke(t string, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	if _, err := s.auth.Revoke(t, r.Header.Get("Origin")); err != nil {
 		s.writeForbiddenError(w, err)
 		return
 	}
 
-	token, err := s.auth.NewSession(req.Wallet)
+	s.writeSuccess(w, nil)
+}
+
+func (s *Service) IntrospectToken(t string, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	info, err := s.introspectToken(t, r.Header.Get("Origin"))
 	if err != nil {
-		s.writeInternalError(w, err)
+		s.writeForbiddenError(w, err)
+		return
+	}
+
+	s.writeSuccess(w, info)
+}
+
+func (s *Service) introspectToken(token, origin string) (*TokenInfoResponse, error) {
+	info, err := s.auth.IntrospectToken(token, origin)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range info.Wallets {
+		if !s.handler.IsWalletUnlocked(name) {
+			info.Locked = true
+		}
+	}
+	return info, nil
+}
+
+func (s *Service) RefreshToken(t string, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	token, err := s.auth.RefreshToken(t, r.Header.Get("Origin"))
+	if err != nil {
+		s.writeForbiddenError(w, err)
 		return
 	}
 
 	s.writeSuccess(w, TokenResponse{Token: token})
 }
 
-func (s *Service) Revoke(t string, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
-	name, err := s.auth.Revoke(t)
+func (s *Service) ListSessions(t string, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	sessions, err := s.auth.ListWalletSessions(t, r.Header.Get("Origin"))
 	if err != nil {
 		s.writeForbiddenError(w, err)
 		return
 	}
 
-	s.handler.LogoutWallet(name)
+	s.writeSuccess(w, SessionsResponse{Sessions: sessions})
+}
+
+// RevokeSessions revokes all the sessions of the wallets the token gives
+// access to, including the one of the token. The wallets are locked, unless
+// API keys still use them.
+func (s *Service) RevokeSessions(t string, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	if err := s.revokeSessions(t, r.Header.Get("Origin")); err != nil {
+		s.writeForbiddenError(w, err)
+		return
+	}
 
 	s.writeSuccess(w, nil)
 }
 
+func (s *Service) revokeSessions(token, origin string) error {
+	if IsAPIKey(token) {
+		return ErrAPIKeyCannotRevokeSessions
+	}
+
+	names, scopes, err := s.auth.VerifyScopedToken(token, origin)
+	if err != nil {
+		return err
+	}
+
+	if err := scopes.CanRevokeSessions(); err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		s.auth.RevokeWalletSessions(name)
+	}
+
+	return nil
+}
+
 func (s *Service) GenerateKeyPair(t string, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	req, errs := ParseGenKeyPairRequest(r)
 	if !errs.Empty() {
@@ -556,67 +971,115 @@ func (s *Service) GenerateKeyPair(t string, w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	name, err := s.auth.VerifyToken(t)
+	names, scopes, err := s.auth.VerifyScopedToken(t, r.Header.Get("Origin"))
 	if err != nil {
 		s.writeForbiddenError(w, err)
 		return
 	}
 
-	pubKey, err := s.handler.SecureGenerateKeyPair(name, req.Passphrase, req.Meta)
+	resp, status, err := s.generateKeyPair(names, scopes, req)
 	if err != nil {
-		if errors.Is(err, wallet.ErrWrongPassphrase) {
-			s.writeForbiddenError(w, err)
-		} else {
-			s.writeInternalError(w, err)
-		}
+		s.writeStatusError(w, err, status)
 		return
 	}
 
+	s.writeSuccess(w, resp)
+}
+
+func (s *Service) generateKeyPair(names []string, scopes *Scopes, req *GenKeyPairRequest) (*KeyResponse, int, error) {
+	if err := scopes.CanGenerateKey(); err != nil {
+		return nil, http.StatusForbidden, err
+	}
+
+	name, err := selectWallet(names, req.Wallet)
+	if err != nil {
+		return nil, http.StatusForbidden, err
+	}
+
+	pubKey, err := s.handler.SecureGenerateKeyPair(name, req.Passphrase, req.Meta)
+	if err != nil {
+		return nil, statusForWalletError(err), err
+	}
+
 	key, err := s.handler.GetPublicKey(name, pubKey)
 	if err != nil {
-		s.writeInternalError(w, err)
-		return
+		return nil, http.StatusInternalServerError, err
 	}
 
-	s.writeSuccess(w, KeyResponse{Key: key})
+	return &KeyResponse{Key: key}, 0, nil
 }
 
-func (s *Service) GetPublicKey(t string, w http.ResponseWriter, _ *http.Request, ps httprouter.Params) {
-	name, err := s.auth.VerifyToken(t)
+func (s *Service) GetPublicKey(t string, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	names, scopes, err := s.auth.VerifyScopedToken(t, r.Header.Get("Origin"))
 	if err != nil {
 		s.writeForbiddenError(w, err)
 		return
 	}
 
-	key, err := s.handler.GetPublicKey(name, ps.ByName("keyid"))
+	resp, status, err := s.getPublicKey(names, scopes, ps.ByName("keyid"))
+	if err != nil {
+		s.writeError(w, newErrorResponse(err.Error()), status)
+		return
+	}
+
+	s.writeSuccess(w, resp)
+}
+
+func (s *Service) getPublicKey(names []string, scopes *Scopes, pubKey string) (*KeyResponse, int, error) {
+	if err := scopes.CanSeeKey(pubKey); err != nil {
+		return nil, http.StatusForbidden, err
+	}
+
+	key, err := s.findPublicKey(names, pubKey)
 	if err != nil {
-		var statusCode int
 		if errors.Is(err, wallet.ErrPubKeyDoesNotExist) {
-			statusCode = http.StatusNotFound
-		} else {
-			statusCode = http.StatusInternalServerError
+			return nil, http.StatusNotFound, err
 		}
-		s.writeError(w, newErrorResponse(err.Error()), statusCode)
-		return
+		return nil, http.StatusInternalServerError, err
 	}
 
-	s.writeSuccess(w, KeyResponse{Key: key})
+	return &KeyResponse{Key: key}, 0, nil
 }
 
-func (s *Service) ListPublicKeys(t string, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
-	name, err := s.auth.VerifyToken(t)
+func (s *Service) ListPublicKeys(t string, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	names, scopes, err := s.auth.VerifyScopedToken(t, r.Header.Get("Origin"))
 	if err != nil {
 		s.writeForbiddenError(w, err)
 		return
 	}
 
-	keys, err := s.handler.ListPublicKeys(name)
+	resp, err := s.listPublicKeys(names, scopes)
 	if err != nil {
 		s.writeInternalError(w, err)
 		return
 	}
 
-	s.writeSuccess(w, KeysResponse{Keys: keys})
+	s.writeSuccess(w, resp)
+}
+
+// listPublicKeys returns the keys of the wallets, only keeping the ones the
+// token gives access to.
+func (s *Service) listPublicKeys(names []string, scopes *Scopes) (*KeysResponse, error) {
+	resp := &KeysResponse{
+		Keys:    []wallet.PublicKey{},
+		Wallets: make([]WalletKeys, 0, len(names)),
+	}
+	for _, name := range names {
+		allKeys, err := s.handler.ListPublicKeys(name)
+		if err != nil {
+			return nil, err
+		}
+		keys := make([]wallet.PublicKey, 0, len(allKeys))
+		for _, key := range allKeys {
+			if scopes.CanSeeKey(key.Key()) == nil {
+				keys = append(keys, key)
+			}
+		}
+		resp.Keys = append(resp.Keys, keys...)
+		resp.Wallets = append(resp.Wallets, WalletKeys{Wallet: name, Keys: keys})
+	}
+
+	return resp, nil
 }
 
 func (s *Service) TaintKey(t string, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
@@ -627,24 +1090,37 @@ func (s *Service) TaintKey(t string, w http.ResponseWriter, r *http.Request, ps
 		return
 	}
 
-	name, err := s.auth.VerifyToken(t)
+	names, scopes, err := s.auth.VerifyScopedToken(t, r.Header.Get("Origin"))
 	if err != nil {
 		s.writeForbiddenError(w, err)
 		return
 	}
 
-	if err = s.handler.TaintKey(name, keyID, req.Passphrase); err != nil {
-		if errors.Is(err, wallet.ErrWrongPassphrase) {
-			s.writeForbiddenError(w, err)
-		} else {
-			s.writeInternalError(w, err)
-		}
+	if status, err := s.taintKey(names, scopes, keyID, req); err != nil {
+		s.writeStatusError(w, err, status)
 		return
 	}
 
 	s.writeSuccess(w, nil)
 }
 
+func (s *Service) taintKey(names []string, scopes *Scopes, keyID string, req *TaintKeyRequest) (int, error) {
+	if err := scopes.CanUseKey(keyID); err != nil {
+		return http.StatusForbidden, err
+	}
+
+	name, err := s.resolveWallet(names, keyID)
+	if err != nil {
+		return http.StatusForbidden, err
+	}
+
+	if err := s.handler.TaintKey(name, keyID, req.Passphrase); err != nil {
+		return statusForWalletError(err), err
+	}
+
+	return 0, nil
+}
+
 func (s *Service) UpdateMeta(t string, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	keyID := ps.ByName("keyid")
 	req, errs := ParseUpdateMetaRequest(r, keyID)
@@ -653,24 +1129,37 @@ func (s *Service) UpdateMeta(t string, w http.ResponseWriter, r *http.Request, p
 		return
 	}
 
-	name, err := s.auth.VerifyToken(t)
+	names, scopes, err := s.auth.VerifyScopedToken(t, r.Header.Get("Origin"))
 	if err != nil {
 		s.writeForbiddenError(w, err)
 		return
 	}
 
-	if err = s.handler.UpdateMeta(name, keyID, req.Passphrase, req.Meta); err != nil {
-		if errors.Is(err, wallet.ErrWrongPassphrase) {
-			s.writeForbiddenError(w, err)
-		} else {
-			s.writeInternalError(w, err)
-		}
+	if status, err := s.updateMeta(names, scopes, keyID, req); err != nil {
+		s.writeStatusError(w, err, status)
 		return
 	}
 
 	s.writeSuccess(w, nil)
 }
 
+func (s *Service) updateMeta(names []string, scopes *Scopes, keyID string, req *UpdateMetaRequest) (int, error) {
+	if err := scopes.CanUseKey(keyID); err != nil {
+		return http.StatusForbidden, err
+	}
+
+	name, err := s.resolveWallet(names, keyID)
+	if err != nil {
+		return http.StatusForbidden, err
+	}
+
+	if err := s.handler.UpdateMeta(name, keyID, req.Passphrase, req.Meta); err != nil {
+		return statusForWalletError(err), err
+	}
+
+	return 0, nil
+}
+
 func (s *Service) SignAny(t string, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	req, errs := ParseSignAnyRequest(r)
 	if !errs.Empty() {
@@ -678,24 +1167,40 @@ func (s *Service) SignAny(t string, w http.ResponseWriter, r *http.Request, _ ht
 		return
 	}
 
-	name, err := s.auth.VerifyToken(t)
+	names, scopes, err := s.auth.VerifyScopedToken(t, r.Header.Get("Origin"))
 	if err != nil {
 		s.writeForbiddenError(w, err)
 		return
 	}
 
-	signature, err := s.handler.SignAny(name, req.decodedInputData, req.PubKey)
+	resp, status, err := s.signAny(names, scopes, req)
 	if err != nil {
-		s.writeInternalError(w, err)
+		s.writeStatusError(w, err, status)
 		return
 	}
 
-	res := SignAnyResponse{
-		HexSignature:    hex.EncodeToString(signature),
-		Base64Signature: base64.StdEncoding.EncodeToString(signature),
+	s.writeSuccess(w, resp)
+}
+
+func (s *Service) signAny(names []string, scopes *Scopes, req *SignAnyRequest) (*SignAnyResponse, int, error) {
+	if err := scopes.CanUseKey(req.PubKey); err != nil {
+		return nil, http.StatusForbidden, err
 	}
 
-	s.writeSuccess(w, res)
+	name, err := s.resolveWallet(names, req.PubKey)
+	if err != nil {
+		return nil, http.StatusForbidden, err
+	}
+
+	signature, err := s.handler.SignAny(name, req.decodedInputData, req.PubKey)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return &SignAnyResponse{
+		HexSignature:    hex.EncodeToString(signature),
+		Base64Signature: base64.StdEncoding.EncodeToString(signature),
+	}, 0, nil
 }
 
 func (s *Service) VerifyAny(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
@@ -717,7 +1222,7 @@ func (s *Service) VerifyAny(w http.ResponseWriter, r *http.Request, _ httprouter
 func (s *Service) CheckTx(token string, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	defer r.Body.Close()
 
-	name, err := s.auth.VerifyToken(token)
+	names, scopes, err := s.auth.VerifyScopedToken(token, r.Header.Get("Origin"))
 	if err != nil {
 		s.writeForbiddenError(w, err)
 		return
@@ -729,49 +1234,60 @@ func (s *Service) CheckTx(token string, w http.ResponseWriter, r *http.Request,
 		return
 	}
 
-	blockData, cltIdx, err := s.nodeForward.LastBlockHeightAndHash(r.Context())
+	resp, status, err := s.checkTransaction(r.Context(), names, scopes, req)
 	if err != nil {
-		s.writeInternalError(w, ErrCouldNotGetBlockHeight)
+		s.writeStatusError(w, err, status)
 		return
 	}
 
-	tx, err := s.handler.SignTx(name, req, blockData.Height)
+	s.writeSuccess(w, resp)
+}
+
+// checkTransaction signs the transaction with the wallet holding its public
+// key, and checks it against the node, without sending it. On failure, it
+// returns the HTTP status the error is reported with.
+func (s *Service) checkTransaction(ctx context.Context, names []string, scopes *Scopes, req *walletpb.SubmitTransactionRequest) (*CheckTransactionResponse, int, error) {
+	if err := scopes.CanSendTransaction(req); err != nil {
+		return nil, http.StatusForbidden, err
+	}
+
+	name, err := s.resolveWallet(names, req.GetPubKey())
 	if err != nil {
-		s.writeInternalError(w, err)
-		return
+		return nil, http.StatusForbidden, err
 	}
 
-	// generate proof of work for the transaction
-	tid := vgcrypto.RandomHash()
-	powNonce, _, err := vgcrypto.PoW(blockData.Hash, tid, uint(blockData.SpamPowDifficulty), vgcrypto.Sha3)
+	if err := s.ensureWalletsUnlocked(name); err != nil {
+		return nil, http.StatusForbidden, err
+	}
+
+	blockData, cltIdx, err := s.nodeForward.LastBlockHeightAndHash(ctx)
 	if err != nil {
-		s.writeInternalError(w, err)
-		return
+		return nil, http.StatusInternalServerError, ErrCouldNotGetBlockHeight
 	}
-	tx.Pow = &commandspb.ProofOfWork{
-		Tid:   tid,
-		Nonce: powNonce,
+
+	tx, err := s.handler.SignTx(name, req, blockData.Height)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
 	}
 
-	result, err := s.nodeForward.CheckTx(r.Context(), tx, cltIdx)
+	pow, err := s.proofOfWork(blockData)
 	if err != nil {
-		s.writeInternalError(w, err)
-		return
+		return nil, http.StatusInternalServerError, err
 	}
+	tx.Pow = pow
 
-	s.writeSuccess(w, struct {
-		Success   bool                    `json:"success"`
-		Code      uint32                  `json:"code"`
-		GasWanted int64                   `json:"gas_wanted"`
-		GasUsed   int64                   `json:"gas_used"`
-		Tx        *commandspb.Transaction `json:"tx"`
-	}{
+	result, err := s.nodeForward.CheckTx(ctx, tx, cltIdx)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return &CheckTransactionResponse{
 		Success:   result.Success,
 		Code:      result.Code,
 		GasWanted: result.GasWanted,
 		GasUsed:   result.GasUsed,
 		Tx:        tx,
-	})
+	}, 0, nil
 }
 
 func (s *Service) SignTxSync(token string, w http.ResponseWriter, r *http.Request, p httprouter.Params) {
@@ -789,104 +1305,164 @@ func (s *Service) SignTx(token string, w http.ResponseWriter, r *http.Request, p
 func (s *Service) signTx(token string, w http.ResponseWriter, r *http.Request, _ httprouter.Params, ty api.SubmitTransactionRequest_Type) {
 	defer r.Body.Close()
 
-	name, err := s.auth.VerifyToken(token)
+	names, scopes, err := s.auth.VerifyScopedToken(token, r.Header.Get("Origin"))
 	if err != nil {
 		s.writeForbiddenError(w, err)
 		return
 	}
 
+	idempotency, err := ReadIdempotencyRequest(r)
+	if err != nil {
+		s.writeBadRequestErr(w, err)
+		return
+	}
+
 	req, errs := ParseSubmitTransactionRequest(r)
 	if !errs.Empty() {
 		s.writeBadRequest(w, errs)
 		return
 	}
 
+	s.submitOnce(w, r, names, idempotency, func() (interface{}, int, error) {
+		return s.sendTransaction(r.Context(), names, scopes, req, ty)
+	})
+}
+
+// sendTransaction asks the consent for the transaction, signs it with the
+// wallet holding its public key, and sends it to the node. On failure, it
+// returns the HTTP status the error is reported with.
+func (s *Service) sendTransaction(ctx context.Context, names []string, scopes *Scopes, req *walletpb.SubmitTransactionRequest, ty api.SubmitTransactionRequest_Type) (*SendTransactionResponse, int, error) {
+	if err := scopes.CanSendTransaction(req); err != nil {
+		return nil, http.StatusForbidden, err
+	}
+
+	name, err := s.resolveWallet(names, req.GetPubKey())
+	if err != nil {
+		return nil, http.StatusForbidden, err
+	}
+
+	if err := s.ensureWalletsUnlocked(name); err != nil {
+		return nil, http.StatusForbidden, err
+	}
+
 	txID := vgrand.RandomStr(TXIDLENGTH)
+	publish := func(ty TransactionEventType, err error) {
+		s.publishTransactionEvent(name, txID, req, ty, "", err)
+	}
+
 	receivedAt := time.Now()
-	approved, err := s.policy.Ask(req, txID, receivedAt)
+	publish(TransactionConsentRequested, nil)
+	approved, err := s.policy.Ask(ctx, req, txID, receivedAt)
 	if err != nil {
 		s.log.Error("couldn't get user consent", zap.Error(err))
-		s.writeError(w, err, http.StatusServiceUnavailable)
-		return
+		publish(TransactionFailed, err)
+		s.recordTransaction(name, txID, req, receivedAt, TransactionStatusFailed, SentTransaction{Error: err})
+		return nil, http.StatusServiceUnavailable, err
 	}
 
 	if !approved {
 		s.log.Info("user rejected transaction signing request", zap.Any("request", req))
-		s.writeError(w, ErrRejectedSignRequest, http.StatusUnauthorized)
-		return
+		publish(TransactionRejected, nil)
+		s.recordTransaction(name, txID, req, receivedAt, TransactionStatusRejected, SentTransaction{})
+		return nil, http.StatusUnauthorized, ErrRejectedSignRequest
 	}
 	s.log.Info("user approved transaction signing request", zap.Any("request", req))
+	publish(TransactionApproved, nil)
 
-	blockData, cltIdx, err := s.nodeForward.LastBlockHeightAndHash(r.Context())
+	blockData, cltIdx, err := s.nodeForward.LastBlockHeightAndHash(ctx)
 	if err != nil {
-		s.policy.Report(SentTransaction{
+		s.reportSentTransaction(name, req, receivedAt, SentTransaction{
 			TxID:  txID,
 			Error: ErrCouldNotGetBlockHeight,
 		})
-		s.writeInternalError(w, ErrCouldNotGetBlockHeight)
-		return
+		return nil, http.StatusInternalServerError, ErrCouldNotGetBlockHeight
+	}
+
+	sent := s.signAndSendTransaction(ctx, name, txID, req, receivedAt, blockData, cltIdx, ty)
+	if sent.Error != nil {
+		return nil, http.StatusInternalServerError, sent.Error
 	}
 
+	return &SendTransactionResponse{
+		TxHash:     sent.TxHash,
+		ReceivedAt: receivedAt,
+		SentAt:     sent.SentAt,
+		TxID:       txID,
+		Tx:         sent.Tx,
+	}, 0, nil
+}
+
+// signAndSendTransaction signs the approved transaction with the wallet,
+// generates its proof of work, and sends it to the node. The outcome is
+// reported to the policy, and returned.
+func (s *Service) signAndSendTransaction(ctx context.Context, name, txID string, req *walletpb.SubmitTransactionRequest, receivedAt time.Time, blockData *api.LastBlockHeightResponse, cltIdx int, ty api.SubmitTransactionRequest_Type) SentTransaction {
 	tx, err := s.handler.SignTx(name, req, blockData.Height)
 	if err != nil {
-		s.policy.Report(SentTransaction{
+		return s.reportSentTransaction(name, req, receivedAt, SentTransaction{
 			TxID:  txID,
 			Error: err,
 		})
-		s.writeInternalError(w, err)
-		return
 	}
 
-	// generate proof of work for the transaction
-	tid := vgcrypto.RandomHash()
-	powNonce, _, err := vgcrypto.PoW(blockData.Hash, tid, uint(blockData.SpamPowDifficulty), vgcrypto.Sha3)
+	pow, err := s.proofOfWork(blockData)
 	if err != nil {
-		s.policy.Report(SentTransaction{
+		return s.reportSentTransaction(name, req, receivedAt, SentTransaction{
 			Tx:    tx,
 			TxID:  txID,
 			Error: err,
 		})
-		s.writeInternalError(w, err)
-		return
-	}
-	tx.Pow = &commandspb.ProofOfWork{
-		Tid:   tid,
-		Nonce: powNonce,
 	}
+	tx.Pow = pow
+	s.publishTransactionEvent(name, txID, req, TransactionSigned, "", nil)
 
 	sentAt := time.Now()
-	txHash, err := s.nodeForward.SendTx(r.Context(), tx, ty, cltIdx)
+	txHash, err := s.nodeForward.SendTx(ctx, tx, ty, cltIdx)
 	if err != nil {
-		s.policy.Report(SentTransaction{
+		return s.reportSentTransaction(name, req, receivedAt, SentTransaction{
 			Tx:     tx,
 			TxID:   txID,
 			Error:  err,
 			SentAt: sentAt,
 		})
-		s.writeInternalError(w, err)
-		return
 	}
 
-	s.policy.Report(SentTransaction{
+	return s.reportSentTransaction(name, req, receivedAt, SentTransaction{
 		TxHash: txHash,
 		TxID:   txID,
 		Tx:     tx,
 		SentAt: sentAt,
 	})
+}
 
-	s.writeSuccess(w, struct {
-		TxHash     string                  `json:"txHash"`
-		ReceivedAt time.Time               `json:"receivedAt"`
-		SentAt     time.Time               `json:"sentAt"`
-		TxID       string                  `json:"txId"`
-		Tx         *commandspb.Transaction `json:"tx"`
-	}{
-		TxHash:     txHash,
-		ReceivedAt: receivedAt,
-		SentAt:     sentAt,
-		TxID:       txID,
-		Tx:         tx,
-	})
+// proofOfWork generates the proof of work for a transaction, with the
+// difficulty of the last block.
+func (s *Service) proofOfWork(blockData *api.LastBlockHeightResponse) (*commandspb.ProofOfWork, error) {
+	startedAt := time.Now()
+	tid := vgcrypto.RandomHash()
+	powNonce, _, err := vgcrypto.PoW(blockData.Hash, tid, uint(blockData.SpamPowDifficulty), vgcrypto.Sha3)
+	if err != nil {
+		return nil, err
+	}
+	s.metrics.ObserveProofOfWork(uint(blockData.SpamPowDifficulty), time.Since(startedAt))
+
+	return &commandspb.ProofOfWork{
+		Tid:   tid,
+		Nonce: powNonce,
+	}, nil
+}
+
+// reportSentTransaction reports the outcome of the transaction to the policy,
+// publishes it, and records it in the history.
+func (s *Service) reportSentTransaction(name string, req *walletpb.SubmitTransactionRequest, receivedAt time.Time, sent SentTransaction) SentTransaction {
+	s.policy.Report(sent)
+	if sent.Error != nil {
+		s.publishTransactionEvent(name, sent.TxID, req, TransactionFailed, "", sent.Error)
+		s.recordTransaction(name, sent.TxID, req, receivedAt, TransactionStatusFailed, sent)
+	} else {
+		s.publishTransactionEvent(name, sent.TxID, req, TransactionSent, sent.TxHash, nil)
+		s.recordTransaction(name, sent.TxID, req, receivedAt, TransactionStatusSent, sent)
+	}
+	return sent
 }
 
 func (s *Service) Version(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
@@ -899,12 +1475,29 @@ func (s *Service) Version(w http.ResponseWriter, _ *http.Request, _ httprouter.P
 }
 
 func (s *Service) GetNetwork(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	s.networkMu.RLock()
 	res := NetworkResponse{
 		Network: *s.network,
 	}
+	s.networkMu.RUnlock()
 	s.writeSuccess(w, res)
 }
 
+// ReloadApprovedOrigins reads the approved origins from the store again, so
+// the origins revoked while the service is running are no longer let through.
+func (s *Service) ReloadApprovedOrigins() error {
+	return s.origins.Reload()
+}
+
+// UpdateNetwork replaces the network configuration exposed by the service.
+// The service address is bound at start, so changing it has no effect.
+func (s *Service) UpdateNetwork(net *network.Network) {
+	s.networkMu.Lock()
+	defer s.networkMu.Unlock()
+
+	s.network = net
+}
+
 func (s *Service) Health(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	if err := s.nodeForward.HealthCheck(r.Context()); err != nil {
 		s.writeError(w, newErrorResponse(err.Error()), http.StatusFailedDependency)
@@ -913,6 +1506,79 @@ func (s *Service) Health(w http.ResponseWriter, r *http.Request, _ httprouter.Pa
 	s.writeSuccess(w, nil)
 }
 
+// resolveWallet returns the wallet, among the ones of the token, that holds the
+// public key. A token with a single wallet doesn't need any lookup.
+func (s *Service) resolveWallet(names []string, pubKey string) (string, error) {
+	if len(names) == 1 {
+		return names[0], nil
+	}
+
+	var lookupErr error = wallet.ErrPubKeyDoesNotExist
+	for _, name := range names {
+		_, err := s.handler.GetPublicKey(name, pubKey)
+		if err == nil {
+			return name, nil
+		}
+		// A locked wallet might hold the key, so its error is more helpful.
+		if !errors.Is(err, wallet.ErrPubKeyDoesNotExist) {
+			lookupErr = err
+		}
+	}
+
+	return "", lookupErr
+}
+
+// ensureWalletsUnlocked verifies the key material of the wallets is available,
+// so the user isn't asked to consent to transactions that can't be signed.
+func (s *Service) ensureWalletsUnlocked(names ...string) error {
+	for _, name := range names {
+		if !s.handler.IsWalletUnlocked(name) {
+			return ErrWalletIsLocked
+		}
+	}
+	return nil
+}
+
+// findPublicKey returns the public key, from one of the wallets of the token.
+func (s *Service) findPublicKey(names []string, pubKey string) (wallet.PublicKey, error) {
+	name, err := s.resolveWallet(names, pubKey)
+	if err != nil {
+		return nil, err
+	}
+	return s.handler.GetPublicKey(name, pubKey)
+}
+
+// statusForWalletError returns the HTTP status the error of a wallet
+// operation requiring the passphrase is reported with.
+func statusForWalletError(err error) int {
+	if errors.Is(err, wallet.ErrWrongPassphrase) {
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
+
+// abortLogins cancels the logins of the wallets, when the session couldn't be
+// created, so they don't stay unlocked.
+func (s *Service) abortLogins(names []string) {
+	for _, name := range names {
+		s.handler.AbortLogin(name)
+	}
+}
+
+// selectWallet returns the requested wallet, if the token gives access to it,
+// or the default wallet of the token, if none is requested.
+func selectWallet(names []string, requested string) (string, error) {
+	if len(requested) == 0 {
+		return names[0], nil
+	}
+	for _, name := range names {
+		if name == requested {
+			return name, nil
+		}
+	}
+	return "", ErrWalletNotInSession
+}
+
 func (s *Service) writeBadRequestErr(w http.ResponseWriter, err error) {
 	errs := commands.NewErrors()
 	s.writeErrors(w, http.StatusBadRequest, errs.FinalAdd(err))
@@ -951,6 +1617,19 @@ func unmarshalBody(r *http.Request, into interface{}) error {
 	return json.Unmarshal(body, into)
 }
 
+// writeStatusError writes the error of an operation shared with the JSON-RPC
+// API, with the HTTP status it has been returned with.
+func (s *Service) writeStatusError(w http.ResponseWriter, e error, status int) {
+	switch status {
+	case http.StatusForbidden:
+		s.writeForbiddenError(w, e)
+	case http.StatusInternalServerError:
+		s.writeInternalError(w, e)
+	default:
+		s.writeError(w, newErrorResponse(e.Error()), status)
+	}
+}
+
 func (s *Service) writeForbiddenError(w http.ResponseWriter, e error) {
 	s.writeError(w, newErrorResponse(e.Error()), http.StatusForbidden)
 }
@@ -1006,8 +1685,40 @@ func (s *Service) writeSuccess(w http.ResponseWriter, data interface{}) {
 func (s *Service) handle(method string, path string, handle httprouter.Handle) {
 	loggedEndpoint := func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 		s.log.Info(fmt.Sprintf("Entering %s %s", method, path))
-		handle(w, r, p)
+		startedAt := time.Now()
+		sw := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		handle(sw, r, p)
+		if keyID, ok := extractAPIKeyID(r); ok {
+			s.auditLog.Info("request authenticated with API key",
+				zap.String("api-key", keyID),
+				zap.String("method", method),
+				zap.String("path", r.URL.Path),
+				zap.Int("status", sw.status),
+				zap.String("remote-address", r.RemoteAddr),
+			)
+		}
+		s.metrics.ObserveHTTPRequest(method, path, sw.status, time.Since(startedAt))
 		s.log.Info(fmt.Sprintf("Leaving %s %s", method, path))
 	}
 	s.Handle(method, path, loggedEndpoint)
+	s.routes = append(s.routes, Route{Method: method, Path: path})
+}
+
+// statusRecorder records the status code of the response, for the audit log
+// and the metrics.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Flush lets the event streams go through the recorder.
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
 }
@@ -21,6 +21,7 @@ import (
 	"code.vegaprotocol.io/vegawallet/wallet"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -37,6 +38,7 @@ type testService struct {
 	handler     *mocks.MockWalletHandler
 	nodeForward *mocks.MockNodeForward
 	auth        *mocks.MockAuth
+	history     *memoryTransactionHistory
 }
 
 func getTestService(t *testing.T, consentPolicy string) *testService {
@@ -60,7 +62,8 @@ func getTestService(t *testing.T, consentPolicy string) *testService {
 		t.Fatalf("unknown consent policy: %s", consentPolicy)
 	}
 	// no needs of the conf or path as we do not run an actual service
-	s, err := service.NewService(zap.NewNop(), &network.Network{}, handler, auth, nodeForward, policy)
+	history := &memoryTransactionHistory{}
+	s, err := service.NewService(zap.NewNop(), &network.Network{}, handler, auth, nodeForward, policy, &memoryOriginStore{}, history)
 	if err != nil {
 		t.Fatalf("couldn't create service: %v", err)
 	}
@@ -71,21 +74,46 @@ func getTestService(t *testing.T, consentPolicy string) *testService {
 		handler:     handler,
 		auth:        auth,
 		nodeForward: nodeForward,
+		history:     history,
 	}
 }
 
 func TestService(t *testing.T) {
 	t.Run("create wallet ok", testServiceCreateWalletOK)
 	t.Run("create wallet fail invalid request", testServiceCreateWalletFailInvalidRequest)
+	t.Run("Creating a wallet without session aborts the login", testServiceCreateWalletWithoutSessionAbortsLogin)
 	t.Run("Importing a wallet succeeds", testServiceImportWalletOK)
 	t.Run("Importing a wallet with and invalid request fails", testServiceImportWalletFailInvalidRequest)
+	t.Run("Importing a wallet without session aborts the login", testServiceImportWalletWithoutSessionAbortsLogin)
 	t.Run("login wallet ok", testServiceLoginWalletOK)
 	t.Run("login wallet fail invalid request", testServiceLoginWalletFailInvalidRequest)
+	t.Run("login wallet with scopes ok", testServiceLoginWalletWithScopesOK)
+	t.Run("login wallet with two-factor code ok", testServiceLoginWalletWithTwoFactorCodeOK)
+	t.Run("login wallet without required two-factor code fails", testServiceLoginWalletWithoutRequiredTwoFactorCodeFails)
+	t.Run("login several wallets ok", testServiceLoginSeveralWalletsOK)
+	t.Run("login a single wallet of the wallets list ok", testServiceLoginSingleWalletOfWalletsListOK)
+	t.Run("login several wallets with wrong passphrase fails", testServiceLoginSeveralWalletsWithWrongPassphraseFails)
+	t.Run("login wallet from web page binds token to origin", testServiceLoginWalletFromWebPageBindsTokenToOrigin)
+	t.Run("login wallet with unsupported command scope fails", testServiceLoginWalletWithUnsupportedCommandScopeFails)
 	t.Run("revoke token ok", testServiceRevokeTokenOK)
 	t.Run("revoke token fail invalid request", testServiceRevokeTokenFailInvalidRequest)
+	t.Run("introspect token ok", testServiceIntrospectTokenOK)
+	t.Run("introspect token of locked wallet ok", testServiceIntrospectTokenOfLockedWalletOK)
+	t.Run("unlock wallet ok", testServiceUnlockWalletOK)
+	t.Run("unlock wallet with wrong passphrase fails", testServiceUnlockWalletWithWrongPassphraseFails)
+	t.Run("unlock wallet without passphrase fails", testServiceUnlockWalletWithoutPassphraseFails)
+	t.Run("unlock wallet with key-restricted token fails", testServiceUnlockWalletWithKeyRestrictedTokenFails)
+	t.Run("refresh token ok", testServiceRefreshTokenOK)
+	t.Run("list sessions ok", testServiceListSessionsOK)
+	t.Run("revoke sessions ok", testServiceRevokeSessionsOK)
+	t.Run("revoke sessions with API key fails", testServiceRevokeSessionsWithAPIKeyFails)
+	t.Run("revoke sessions with read-only token fails", testServiceRevokeSessionsWithReadOnlyTokenFails)
 	t.Run("gen keypair ok", testServiceGenKeypairOK)
 	t.Run("gen keypair fail invalid request", testServiceGenKeypairFailInvalidRequest)
+	t.Run("gen keypair with read-only token fails", testServiceGenKeypairWithReadOnlyTokenFails)
 	t.Run("list keypair ok", testServiceListPublicKeysOK)
+	t.Run("list keypair of several wallets ok", testServiceListPublicKeysOfSeveralWalletsOK)
+	t.Run("list keypair only lists the keys of the scopes", testServiceListPublicKeysOnlyListsKeysOfScopes)
 	t.Run("list keypair fail invalid request", testServiceListPublicKeysFailInvalidRequest)
 	t.Run("get keypair ok", testServiceGetPublicKeyOK)
 	t.Run("get keypair fail invalid request", testServiceGetPublicKeyFailInvalidRequest)
@@ -93,6 +121,7 @@ func TestService(t *testing.T) {
 	t.Run("get keypair fail misc error", testServiceGetPublicKeyFailMiscError)
 	t.Run("taint ok", testServiceTaintOK)
 	t.Run("taint fail invalid request", testServiceTaintFailInvalidRequest)
+	t.Run("taint with key not allowed by token fails", testServiceTaintWithKeyNotAllowedByTokenFails)
 	t.Run("update metadata", testServiceUpdateMetaOK)
 	t.Run("update metadata invalid request", testServiceUpdateMetaFailInvalidRequest)
 	t.Run("Signing transaction succeeds", testAcceptSigningTransactionSucceeds)
@@ -100,12 +129,18 @@ func TestService(t *testing.T) {
 	t.Run("Checking transaction with rejected transaction succeeds", testCheckTransactionWithRejectedTransactionSucceeds)
 	t.Run("Checking transaction with failed transaction fails", testCheckTransactionWithFailedTransactionFails)
 	t.Run("Decline signing transaction manually succeeds", testDeclineSigningTransactionManuallySucceeds)
+	t.Run("Signing transaction with locked wallet fails", testSigningTransactionWithLockedWalletFails)
+	t.Run("Signing transaction resolves the wallet from the public key", testSigningTransactionResolvesWalletFromPubKey)
+	t.Run("Signing transaction with key of no wallet fails", testSigningTransactionWithKeyOfNoWalletFails)
 	t.Run("Signing transaction with propagation succeeds", testSigningTransactionWithPropagationSucceeds)
 	t.Run("Signing transaction with failed propagation fails", testSigningTransactionWithFailedPropagationFails)
 	t.Run("Failed signing of transaction fails", testFailedTransactionSigningFails)
 	t.Run("Signing transaction with invalid request fails", testSigningTransactionWithInvalidRequestFails)
+	t.Run("Signing transaction with command not allowed by token fails", testSigningTransactionWithCommandNotAllowedByTokenFails)
+	t.Run("Signing transaction with command allowed by token succeeds", testSigningTransactionWithCommandAllowedByTokenSucceeds)
 	t.Run("Signing anything succeeds", testSigningAnythingSucceeds)
 	t.Run("Signing anything with invalid request fails", testSigningAnyDataWithInvalidRequestFails)
+	t.Run("Signing anything with key not allowed by token fails", testSigningAnythingWithKeyNotAllowedByTokenFails)
 	t.Run("Verifying anything succeeds", testVerifyingAnythingSucceeds)
 	t.Run("Failed verification fails", testVerifyingAnythingFails)
 	t.Run("Verifying anything with invalid request fails", testVerifyingAnyDataWithInvalidRequestFails)
@@ -124,7 +159,7 @@ func testServiceCreateWalletOK(t *testing.T) {
 
 	// setup
 	s.handler.EXPECT().CreateWallet(walletName, passphrase).Times(1).Return(testRecoveryPhrase, nil)
-	s.auth.EXPECT().NewSession(walletName).Times(1).Return("this is a token", nil)
+	s.auth.EXPECT().NewSession(walletName, "").Times(1).Return("this is a token", nil)
 
 	// when
 	statusCode, _ := serveHTTP(t, s, createWalletRequest(t, payload))
@@ -144,6 +179,15 @@ func testServiceCreateWalletFailInvalidRequest(t *testing.T) {
 		}, {
 			name:    "misspelled passphrase property",
 			payload: `{"wallet": "jeremy", "passrase": "oh yea?"}`,
+		}, {
+			name:    "wallets with wallet property",
+			payload: `{"wallet": "jeremy", "passphrase": "oh yea?", "wallets": [{"wallet": "kevin", "passphrase": "oh yea?"}]}`,
+		}, {
+			name:    "wallets without passphrase",
+			payload: `{"wallets": [{"wallet": "jeremy"}]}`,
+		}, {
+			name:    "duplicated wallets",
+			payload: `{"wallets": [{"wallet": "jeremy", "passphrase": "oh yea?"}, {"wallet": "jeremy", "passphrase": "oh yea?"}]}`,
 		},
 	}
 
@@ -163,6 +207,29 @@ func testServiceCreateWalletFailInvalidRequest(t *testing.T) {
 	}
 }
 
+func testServiceCreateWalletWithoutSessionAbortsLogin(t *testing.T) {
+	s := getTestService(t, "automatic")
+	t.Cleanup(func() {
+		s.ctrl.Finish()
+	})
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	passphrase := vgrand.RandomStr(5)
+	payload := fmt.Sprintf(`{"wallet": "%s", "passphrase": "%s"}`, walletName, passphrase)
+
+	// setup
+	s.handler.EXPECT().CreateWallet(walletName, passphrase).Times(1).Return(testRecoveryPhrase, nil)
+	s.auth.EXPECT().NewSession(walletName, "").Times(1).Return("", assert.AnError)
+	s.handler.EXPECT().AbortLogin(walletName).Times(1)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, createWalletRequest(t, payload))
+
+	// then
+	assert.Equal(t, http.StatusInternalServerError, statusCode)
+}
+
 func testServiceImportWalletOK(t *testing.T) {
 	tcs := []struct {
 		name    string
@@ -191,7 +258,7 @@ func testServiceImportWalletOK(t *testing.T) {
 
 			// setup
 			s.handler.EXPECT().ImportWallet(walletName, passphrase, testRecoveryPhrase, tc.version).Times(1).Return(nil)
-			s.auth.EXPECT().NewSession(walletName).Times(1).Return("this is a token", nil)
+			s.auth.EXPECT().NewSession(walletName, "").Times(1).Return("this is a token", nil)
 
 			// when
 			statusCode, _ := serveHTTP(tt, s, importWalletRequest(tt, payload))
@@ -202,6 +269,29 @@ func testServiceImportWalletOK(t *testing.T) {
 	}
 }
 
+func testServiceImportWalletWithoutSessionAbortsLogin(t *testing.T) {
+	s := getTestService(t, "automatic")
+	t.Cleanup(func() {
+		s.ctrl.Finish()
+	})
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	passphrase := vgrand.RandomStr(5)
+	payload := fmt.Sprintf(`{"wallet": "%s", "passphrase": "%s", "recoveryPhrase": "%s", "version": 2}`, walletName, passphrase, testRecoveryPhrase)
+
+	// setup
+	s.handler.EXPECT().ImportWallet(walletName, passphrase, testRecoveryPhrase, uint32(2)).Times(1).Return(nil)
+	s.auth.EXPECT().NewSession(walletName, "").Times(1).Return("", assert.AnError)
+	s.handler.EXPECT().AbortLogin(walletName).Times(1)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, importWalletRequest(t, payload))
+
+	// then
+	assert.Equal(t, http.StatusInternalServerError, statusCode)
+}
+
 func testServiceImportWalletFailInvalidRequest(t *testing.T) {
 	tcs := []struct {
 		name    string
@@ -235,7 +325,484 @@ func testServiceImportWalletFailInvalidRequest(t *testing.T) {
 	}
 }
 
-func testServiceLoginWalletOK(t *testing.T) {
+func testServiceLoginWalletOK(t *testing.T) {
+	s := getTestService(t, "automatic")
+	t.Cleanup(func() {
+		s.ctrl.Finish()
+	})
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	passphrase := vgrand.RandomStr(5)
+	payload := fmt.Sprintf(`{"wallet": "%s", "passphrase": "%s"}`, walletName, passphrase)
+
+	// setup
+	s.handler.EXPECT().LoginWallet(walletName, passphrase, "").Times(1).Return(nil)
+	s.auth.EXPECT().NewSession(walletName, "").Times(1).Return("this is a token", nil)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, loginRequest(t, payload))
+
+	// then
+	assert.Equal(t, http.StatusOK, statusCode)
+}
+
+func testServiceLoginWalletFromWebPageBindsTokenToOrigin(t *testing.T) {
+	s := getTestService(t, "automatic")
+	t.Cleanup(func() {
+		s.ctrl.Finish()
+	})
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	passphrase := vgrand.RandomStr(5)
+	origin := "https://console.vega.xyz"
+	payload := fmt.Sprintf(`{"wallet": "%s", "passphrase": "%s"}`, walletName, passphrase)
+	req := loginRequest(t, payload)
+	req.Header.Set("Origin", origin)
+
+	// setup
+	s.handler.EXPECT().LoginWallet(walletName, passphrase, "").Times(1).Return(nil)
+	s.auth.EXPECT().NewSession(walletName, origin).Times(1).Return("this is a token", nil)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, req)
+
+	// then
+	assert.Equal(t, http.StatusOK, statusCode)
+}
+
+func testServiceLoginWalletFailInvalidRequest(t *testing.T) {
+	tcs := []struct {
+		name    string
+		payload string
+	}{
+		{
+			name:    "misspelled wallet property",
+			payload: `{"wall": "jeremy", "passphrase": "oh yea?"}`,
+		}, {
+			name:    "misspelled passphrase property",
+			payload: `{"wallet": "jeremy", "passrase": "oh yea?"}`,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			s := getTestService(tt, "automatic")
+			t.Cleanup(func() {
+				s.ctrl.Finish()
+			})
+
+			// setup
+			s.handler.EXPECT().LoginWallet(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			s.auth.EXPECT().NewSession(gomock.Any(), gomock.Any()).Times(0)
+
+			// when
+			statusCode, _ := serveHTTP(tt, s, loginRequest(tt, tc.payload))
+
+			// then
+			assert.Equal(tt, http.StatusBadRequest, statusCode)
+		})
+	}
+}
+
+func testServiceLoginWalletWithTwoFactorCodeOK(t *testing.T) {
+	s := getTestService(t, "automatic")
+	t.Cleanup(func() {
+		s.ctrl.Finish()
+	})
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	passphrase := vgrand.RandomStr(5)
+	payload := fmt.Sprintf(`{"wallet": "%s", "passphrase": "%s", "twoFactorCode": "123456"}`, walletName, passphrase)
+
+	// setup
+	s.handler.EXPECT().LoginWallet(walletName, passphrase, "123456").Times(1).Return(nil)
+	s.auth.EXPECT().NewSession(walletName, "").Times(1).Return("this is a token", nil)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, loginRequest(t, payload))
+
+	// then
+	assert.Equal(t, http.StatusOK, statusCode)
+}
+
+func testServiceLoginWalletWithoutRequiredTwoFactorCodeFails(t *testing.T) {
+	s := getTestService(t, "automatic")
+	t.Cleanup(func() {
+		s.ctrl.Finish()
+	})
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	passphrase := vgrand.RandomStr(5)
+	payload := fmt.Sprintf(`{"wallet": "%s", "passphrase": "%s"}`, walletName, passphrase)
+
+	// setup
+	s.handler.EXPECT().LoginWallet(walletName, passphrase, "").Times(1).Return(wallet.ErrTwoFactorCodeRequired)
+	s.auth.EXPECT().NewSession(gomock.Any(), gomock.Any()).Times(0)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, loginRequest(t, payload))
+
+	// then
+	assert.Equal(t, http.StatusForbidden, statusCode)
+}
+
+func testServiceLoginSeveralWalletsOK(t *testing.T) {
+	s := getTestService(t, "automatic")
+	t.Cleanup(func() {
+		s.ctrl.Finish()
+	})
+
+	// given
+	walletName1 := vgrand.RandomStr(5)
+	walletName2 := vgrand.RandomStr(5)
+	passphrase1 := vgrand.RandomStr(5)
+	passphrase2 := vgrand.RandomStr(5)
+	payload := fmt.Sprintf(`{"wallets": [{"wallet": "%s", "passphrase": "%s"}, {"wallet": "%s", "passphrase": "%s"}]}`, walletName1, passphrase1, walletName2, passphrase2)
+
+	// setup
+	s.handler.EXPECT().LoginWallet(walletName1, passphrase1, "").Times(1).Return(nil)
+	s.handler.EXPECT().LoginWallet(walletName2, passphrase2, "").Times(1).Return(nil)
+	s.auth.EXPECT().NewMultiWalletSession([]string{walletName1, walletName2}, "", nil).Times(1).Return("this is a token", nil)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, loginRequest(t, payload))
+
+	// then
+	assert.Equal(t, http.StatusOK, statusCode)
+}
+
+func testServiceLoginSingleWalletOfWalletsListOK(t *testing.T) {
+	s := getTestService(t, "automatic")
+	t.Cleanup(func() {
+		s.ctrl.Finish()
+	})
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	passphrase := vgrand.RandomStr(5)
+	payload := fmt.Sprintf(`{"wallets": [{"wallet": "%s", "passphrase": "%s"}]}`, walletName, passphrase)
+
+	// setup
+	s.handler.EXPECT().LoginWallet(walletName, passphrase, "").Times(1).Return(nil)
+	s.auth.EXPECT().NewSession(walletName, "").Times(1).Return("this is a token", nil)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, loginRequest(t, payload))
+
+	// then
+	assert.Equal(t, http.StatusOK, statusCode)
+}
+
+func testServiceLoginSeveralWalletsWithWrongPassphraseFails(t *testing.T) {
+	s := getTestService(t, "automatic")
+	t.Cleanup(func() {
+		s.ctrl.Finish()
+	})
+
+	// given
+	walletName1 := vgrand.RandomStr(5)
+	walletName2 := vgrand.RandomStr(5)
+	passphrase1 := vgrand.RandomStr(5)
+	passphrase2 := vgrand.RandomStr(5)
+	payload := fmt.Sprintf(`{"wallets": [{"wallet": "%s", "passphrase": "%s"}, {"wallet": "%s", "passphrase": "%s"}]}`, walletName1, passphrase1, walletName2, passphrase2)
+
+	// setup
+	s.handler.EXPECT().LoginWallet(walletName1, passphrase1, "").Times(1).Return(nil)
+	s.handler.EXPECT().LoginWallet(walletName2, passphrase2, "").Times(1).Return(wallet.ErrWrongPassphrase)
+	s.handler.EXPECT().AbortLogin(walletName1).Times(1)
+	s.auth.EXPECT().NewMultiWalletSession(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, loginRequest(t, payload))
+
+	// then
+	assert.Equal(t, http.StatusForbidden, statusCode)
+}
+
+func testServiceLoginWalletWithScopesOK(t *testing.T) {
+	s := getTestService(t, "automatic")
+	t.Cleanup(func() {
+		s.ctrl.Finish()
+	})
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	passphrase := vgrand.RandomStr(5)
+	pubKey := vgrand.RandomStr(5)
+	payload := fmt.Sprintf(`{"wallet": "%s", "passphrase": "%s", "scopes": {"publicKeys": ["%s"], "commands": ["orderSubmission", "orderCancellation"]}}`, walletName, passphrase, pubKey)
+
+	// setup
+	s.handler.EXPECT().LoginWallet(walletName, passphrase, "").Times(1).Return(nil)
+	s.auth.EXPECT().NewScopedSession(walletName, "", &service.Scopes{
+		PublicKeys: []string{pubKey},
+		Commands:   []string{"orderSubmission", "orderCancellation"},
+	}).Times(1).Return("this is a token", nil)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, loginRequest(t, payload))
+
+	// then
+	assert.Equal(t, http.StatusOK, statusCode)
+}
+
+func testServiceLoginWalletWithUnsupportedCommandScopeFails(t *testing.T) {
+	s := getTestService(t, "automatic")
+	t.Cleanup(func() {
+		s.ctrl.Finish()
+	})
+
+	// given
+	payload := `{"wallet": "jeremy", "passphrase": "oh yea?", "scopes": {"commands": ["robMoney"]}}`
+
+	// setup
+	s.handler.EXPECT().LoginWallet(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	s.auth.EXPECT().NewScopedSession(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, loginRequest(t, payload))
+
+	// then
+	assert.Equal(t, http.StatusBadRequest, statusCode)
+}
+
+func testServiceRevokeTokenOK(t *testing.T) {
+	s := getTestService(t, "automatic")
+	t.Cleanup(func() {
+		s.ctrl.Finish()
+	})
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+
+	// setup
+	s.auth.EXPECT().Revoke(token, "").Times(1).Return(walletName, nil)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, logoutRequest(t, headers))
+
+	// then
+	assert.Equal(t, http.StatusOK, statusCode)
+}
+
+func testServiceIntrospectTokenOK(t *testing.T) {
+	s := getTestService(t, "automatic")
+	t.Cleanup(func() {
+		s.ctrl.Finish()
+	})
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	// setup
+	s.auth.EXPECT().IntrospectToken(token, "").Times(1).Return(&service.TokenInfoResponse{
+		Wallet:    walletName,
+		Wallets:   []string{walletName},
+		ExpiresAt: &expiresAt,
+		Scopes:    &service.Scopes{ReadOnly: true},
+	}, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName).Times(1).Return(true)
+
+	// when
+	statusCode, body := serveHTTP(t, s, introspectTokenRequest(t, headers))
+
+	// then
+	assert.Equal(t, http.StatusOK, statusCode)
+	resp := &service.TokenInfoResponse{}
+	if err := json.Unmarshal(body, resp); err != nil {
+		t.Fatalf("couldn't unmarshal response: %v", err)
+	}
+	assert.Equal(t, walletName, resp.Wallet)
+	assert.True(t, expiresAt.Equal(*resp.ExpiresAt))
+	assert.True(t, resp.Scopes.ReadOnly)
+	assert.False(t, resp.Locked)
+}
+
+func testServiceIntrospectTokenOfLockedWalletOK(t *testing.T) {
+	s := getTestService(t, "automatic")
+	t.Cleanup(func() {
+		s.ctrl.Finish()
+	})
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+
+	// setup
+	s.auth.EXPECT().IntrospectToken(token, "").Times(1).Return(&service.TokenInfoResponse{
+		Wallet:  walletName,
+		Wallets: []string{walletName},
+	}, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName).Times(1).Return(false)
+
+	// when
+	statusCode, body := serveHTTP(t, s, introspectTokenRequest(t, headers))
+
+	// then
+	assert.Equal(t, http.StatusOK, statusCode)
+	resp := &service.TokenInfoResponse{}
+	if err := json.Unmarshal(body, resp); err != nil {
+		t.Fatalf("couldn't unmarshal response: %v", err)
+	}
+	assert.True(t, resp.Locked)
+}
+
+func testServiceUnlockWalletOK(t *testing.T) {
+	s := getTestService(t, "automatic")
+	t.Cleanup(func() {
+		s.ctrl.Finish()
+	})
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	passphrase := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+	payload := fmt.Sprintf(`{"passphrase": "%s"}`, passphrase)
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().UnlockWallet(walletName, passphrase).Times(1).Return(nil)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, unlockWalletRequest(t, payload, headers))
+
+	// then
+	assert.Equal(t, http.StatusOK, statusCode)
+}
+
+func testServiceUnlockWalletWithWrongPassphraseFails(t *testing.T) {
+	s := getTestService(t, "automatic")
+	t.Cleanup(func() {
+		s.ctrl.Finish()
+	})
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	passphrase := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+	payload := fmt.Sprintf(`{"passphrase": "%s"}`, passphrase)
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().UnlockWallet(walletName, passphrase).Times(1).Return(wallet.ErrWrongPassphrase)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, unlockWalletRequest(t, payload, headers))
+
+	// then
+	assert.Equal(t, http.StatusForbidden, statusCode)
+}
+
+func testServiceUnlockWalletWithoutPassphraseFails(t *testing.T) {
+	s := getTestService(t, "automatic")
+	t.Cleanup(func() {
+		s.ctrl.Finish()
+	})
+
+	// given
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(gomock.Any(), gomock.Any()).Times(0)
+	s.handler.EXPECT().UnlockWallet(gomock.Any(), gomock.Any()).Times(0)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, unlockWalletRequest(t, `{}`, headers))
+
+	// then
+	assert.Equal(t, http.StatusBadRequest, statusCode)
+}
+
+func testServiceUnlockWalletWithKeyRestrictedTokenFails(t *testing.T) {
+	s := getTestService(t, "automatic")
+	t.Cleanup(func() {
+		s.ctrl.Finish()
+	})
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+	payload := fmt.Sprintf(`{"passphrase": "%s"}`, vgrand.RandomStr(5))
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, &service.Scopes{PublicKeys: []string{"pubKey1"}}, nil)
+	s.handler.EXPECT().UnlockWallet(gomock.Any(), gomock.Any()).Times(0)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, unlockWalletRequest(t, payload, headers))
+
+	// then
+	assert.Equal(t, http.StatusForbidden, statusCode)
+}
+
+func testServiceRefreshTokenOK(t *testing.T) {
+	s := getTestService(t, "automatic")
+	t.Cleanup(func() {
+		s.ctrl.Finish()
+	})
+
+	// given
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+
+	// setup
+	s.auth.EXPECT().RefreshToken(token, "").Times(1).Return("this is a new token", nil)
+
+	// when
+	statusCode, body := serveHTTP(t, s, refreshTokenRequest(t, headers))
+
+	// then
+	assert.Equal(t, http.StatusOK, statusCode)
+	resp := &service.TokenResponse{}
+	if err := json.Unmarshal(body, resp); err != nil {
+		t.Fatalf("couldn't unmarshal response: %v", err)
+	}
+	assert.Equal(t, "this is a new token", resp.Token)
+}
+
+func testServiceListSessionsOK(t *testing.T) {
+	s := getTestService(t, "automatic")
+	t.Cleanup(func() {
+		s.ctrl.Finish()
+	})
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+
+	// setup
+	s.auth.EXPECT().ListWalletSessions(token, "").Times(1).Return([]service.SessionSummary{
+		{ID: "session-1", Wallet: walletName},
+		{ID: "session-2", Wallet: walletName},
+	}, nil)
+
+	// when
+	statusCode, body := serveHTTP(t, s, listSessionsRequest(t, headers))
+
+	// then
+	assert.Equal(t, http.StatusOK, statusCode)
+	resp := &service.SessionsResponse{}
+	if err := json.Unmarshal(body, resp); err != nil {
+		t.Fatalf("couldn't unmarshal response: %v", err)
+	}
+	assert.Len(t, resp.Sessions, 2)
+}
+
+func testServiceRevokeSessionsOK(t *testing.T) {
 	s := getTestService(t, "automatic")
 	t.Cleanup(func() {
 		s.ctrl.Finish()
@@ -243,55 +810,40 @@ func testServiceLoginWalletOK(t *testing.T) {
 
 	// given
 	walletName := vgrand.RandomStr(5)
-	passphrase := vgrand.RandomStr(5)
-	payload := fmt.Sprintf(`{"wallet": "%s", "passphrase": "%s"}`, walletName, passphrase)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
 
 	// setup
-	s.handler.EXPECT().LoginWallet(walletName, passphrase).Times(1).Return(nil)
-	s.auth.EXPECT().NewSession(walletName).Times(1).Return("this is a token", nil)
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
+	s.auth.EXPECT().RevokeWalletSessions(walletName).Times(1)
 
 	// when
-	statusCode, _ := serveHTTP(t, s, loginRequest(t, payload))
+	statusCode, _ := serveHTTP(t, s, revokeSessionsRequest(t, headers))
 
 	// then
 	assert.Equal(t, http.StatusOK, statusCode)
 }
 
-func testServiceLoginWalletFailInvalidRequest(t *testing.T) {
-	tcs := []struct {
-		name    string
-		payload string
-	}{
-		{
-			name:    "misspelled wallet property",
-			payload: `{"wall": "jeremy", "passphrase": "oh yea?"}`,
-		}, {
-			name:    "misspelled passphrase property",
-			payload: `{"wallet": "jeremy", "passrase": "oh yea?"}`,
-		},
-	}
+func testServiceRevokeSessionsWithAPIKeyFails(t *testing.T) {
+	s := getTestService(t, "automatic")
+	t.Cleanup(func() {
+		s.ctrl.Finish()
+	})
 
-	for _, tc := range tcs {
-		t.Run(tc.name, func(tt *testing.T) {
-			s := getTestService(tt, "automatic")
-			t.Cleanup(func() {
-				s.ctrl.Finish()
-			})
+	// given
+	headers := authHeaders(t, service.APIKeyPrefix+"id_secret")
 
-			// setup
-			s.handler.EXPECT().LoginWallet(gomock.Any(), gomock.Any()).Times(0)
-			s.auth.EXPECT().NewSession(gomock.Any()).Times(0)
+	// setup
+	s.auth.EXPECT().RevokeWalletSessions(gomock.Any()).Times(0)
 
-			// when
-			statusCode, _ := serveHTTP(tt, s, loginRequest(tt, tc.payload))
+	// when
+	statusCode, _ := serveHTTP(t, s, revokeSessionsRequest(t, headers))
 
-			// then
-			assert.Equal(tt, http.StatusBadRequest, statusCode)
-		})
-	}
+	// then
+	assert.Equal(t, http.StatusForbidden, statusCode)
 }
 
-func testServiceRevokeTokenOK(t *testing.T) {
+func testServiceRevokeSessionsWithReadOnlyTokenFails(t *testing.T) {
 	s := getTestService(t, "automatic")
 	t.Cleanup(func() {
 		s.ctrl.Finish()
@@ -303,14 +855,14 @@ func testServiceRevokeTokenOK(t *testing.T) {
 	headers := authHeaders(t, token)
 
 	// setup
-	s.auth.EXPECT().Revoke(token).Times(1).Return(walletName, nil)
-	s.handler.EXPECT().LogoutWallet(walletName).Times(1)
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, &service.Scopes{ReadOnly: true}, nil)
+	s.auth.EXPECT().RevokeWalletSessions(gomock.Any()).Times(0)
 
 	// when
-	statusCode, _ := serveHTTP(t, s, logoutRequest(t, headers))
+	statusCode, _ := serveHTTP(t, s, revokeSessionsRequest(t, headers))
 
 	// then
-	assert.Equal(t, http.StatusOK, statusCode)
+	assert.Equal(t, http.StatusForbidden, statusCode)
 }
 
 func testServiceRevokeTokenFailInvalidRequest(t *testing.T) {
@@ -367,7 +919,7 @@ func testServiceGenKeypairOK(t *testing.T) {
 	payload := fmt.Sprintf(`{"passphrase": "%s"}`, passphrase)
 
 	// setup
-	s.auth.EXPECT().VerifyToken(token).Times(1).Return(walletName, nil)
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
 	s.handler.EXPECT().SecureGenerateKeyPair(walletName, passphrase, gomock.Len(0)).Times(1).Return(key.PublicKey, nil)
 	s.handler.EXPECT().GetPublicKey(walletName, key.PublicKey).Times(1).Return(key, nil)
 
@@ -414,6 +966,27 @@ func testServiceGenKeypairFailInvalidRequest(t *testing.T) {
 	}
 }
 
+func testServiceGenKeypairWithReadOnlyTokenFails(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+	payload := fmt.Sprintf(`{"passphrase": "%s"}`, vgrand.RandomStr(5))
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, &service.Scopes{ReadOnly: true}, nil)
+	s.handler.EXPECT().SecureGenerateKeyPair(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, generateKeyRequest(t, payload, headers))
+
+	// then
+	assert.Equal(t, http.StatusForbidden, statusCode)
+}
+
 func testServiceListPublicKeysOK(t *testing.T) {
 	s := getTestService(t, "automatic")
 	t.Cleanup(func() {
@@ -426,7 +999,7 @@ func testServiceListPublicKeysOK(t *testing.T) {
 	headers := authHeaders(t, token)
 
 	// setup
-	s.auth.EXPECT().VerifyToken(token).Times(1).Return(walletName, nil)
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
 	s.handler.EXPECT().ListPublicKeys(walletName).Times(1).Return([]wallet.PublicKey{}, nil)
 
 	// when
@@ -436,6 +1009,76 @@ func testServiceListPublicKeysOK(t *testing.T) {
 	assert.Equal(t, http.StatusOK, statusCode)
 }
 
+func testServiceListPublicKeysOfSeveralWalletsOK(t *testing.T) {
+	s := getTestService(t, "automatic")
+	t.Cleanup(func() {
+		s.ctrl.Finish()
+	})
+
+	// given
+	walletName1 := vgrand.RandomStr(5)
+	walletName2 := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName1, walletName2}, nil, nil)
+	s.handler.EXPECT().ListPublicKeys(walletName1).Times(1).Return([]wallet.PublicKey{}, nil)
+	s.handler.EXPECT().ListPublicKeys(walletName2).Times(1).Return([]wallet.PublicKey{}, nil)
+
+	// when
+	statusCode, body := serveHTTP(t, s, listKeysRequest(t, headers))
+
+	// then
+	assert.Equal(t, http.StatusOK, statusCode)
+	resp := &struct {
+		Wallets []struct {
+			Wallet string `json:"wallet"`
+		} `json:"wallets"`
+	}{}
+	if err := json.Unmarshal(body, resp); err != nil {
+		t.Fatalf("couldn't unmarshal response: %v", err)
+	}
+	require.Len(t, resp.Wallets, 2)
+	assert.Equal(t, walletName1, resp.Wallets[0].Wallet)
+	assert.Equal(t, walletName2, resp.Wallets[1].Wallet)
+}
+
+func testServiceListPublicKeysOnlyListsKeysOfScopes(t *testing.T) {
+	s := getTestService(t, "automatic")
+	t.Cleanup(func() {
+		s.ctrl.Finish()
+	})
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+	allowedKey := &wallet.HDPublicKey{PublicKey: vgrand.RandomStr(5)}
+	otherKey := &wallet.HDPublicKey{PublicKey: vgrand.RandomStr(5)}
+	scopes := &service.Scopes{PublicKeys: []string{allowedKey.PublicKey}}
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, scopes, nil)
+	s.handler.EXPECT().ListPublicKeys(walletName).Times(1).Return([]wallet.PublicKey{allowedKey, otherKey}, nil)
+
+	// when
+	statusCode, body := serveHTTP(t, s, listKeysRequest(t, headers))
+
+	// then
+	assert.Equal(t, http.StatusOK, statusCode)
+	resp := &struct {
+		Keys []struct {
+			PublicKey string `json:"pub"`
+		} `json:"keys"`
+	}{}
+	if err := json.Unmarshal(body, resp); err != nil {
+		t.Fatalf("couldn't unmarshal response: %v", err)
+	}
+	require.Len(t, resp.Keys, 1)
+	assert.Equal(t, allowedKey.PublicKey, resp.Keys[0].PublicKey)
+}
+
 func testServiceListPublicKeysFailInvalidRequest(t *testing.T) {
 	tcs := []struct {
 		name    string
@@ -486,7 +1129,7 @@ func testServiceGetPublicKeyOK(t *testing.T) {
 	headers := authHeaders(t, token)
 
 	// setup
-	s.auth.EXPECT().VerifyToken(token).Times(1).Return(walletName, nil)
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
 	s.handler.EXPECT().GetPublicKey(walletName, hdPubKey.PublicKey).Times(1).Return(hdPubKey, nil)
 
 	// when
@@ -537,7 +1180,7 @@ func testServiceGetPublicKeyFailKeyNotFound(t *testing.T) {
 	headers := authHeaders(t, token)
 
 	// setup
-	s.auth.EXPECT().VerifyToken(token).Times(1).Return(walletName, nil)
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
 	s.handler.EXPECT().GetPublicKey(walletName, pubKey).Times(1).Return(nil, wallet.ErrPubKeyDoesNotExist)
 
 	// when
@@ -558,7 +1201,7 @@ func testServiceGetPublicKeyFailMiscError(t *testing.T) {
 	headers := authHeaders(t, token)
 
 	// setup
-	s.auth.EXPECT().VerifyToken(token).Times(1).Return(walletName, nil)
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
 	s.handler.EXPECT().GetPublicKey(walletName, pubKey).Times(1).Return(nil, assert.AnError)
 
 	// when
@@ -581,7 +1224,7 @@ func testServiceTaintOK(t *testing.T) {
 	payload := fmt.Sprintf(`{"passphrase": "%s"}`, passphrase)
 
 	// setup
-	s.auth.EXPECT().VerifyToken(token).Times(1).Return(walletName, nil)
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
 	s.handler.EXPECT().TaintKey(walletName, pubKey, passphrase).Times(1).Return(nil)
 
 	// when
@@ -628,6 +1271,30 @@ func testServiceTaintFailInvalidRequest(t *testing.T) {
 	}
 }
 
+func testServiceTaintWithKeyNotAllowedByTokenFails(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	pubKey := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+	payload := fmt.Sprintf(`{"passphrase": "%s"}`, vgrand.RandomStr(5))
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, &service.Scopes{
+		PublicKeys: []string{vgrand.RandomStr(5)},
+	}, nil)
+	s.handler.EXPECT().TaintKey(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, taintKeyRequest(t, pubKey, payload, headers))
+
+	// then
+	assert.Equal(t, http.StatusForbidden, statusCode)
+}
+
 func testServiceUpdateMetaOK(t *testing.T) {
 	s := getTestService(t, "automatic")
 	defer s.ctrl.Finish()
@@ -642,7 +1309,7 @@ func testServiceUpdateMetaOK(t *testing.T) {
 	payload := fmt.Sprintf(`{"passphrase": "%s", "meta": [{"key":"role", "value":"%s"}]}`, passphrase, metaRole)
 
 	// setup
-	s.auth.EXPECT().VerifyToken(token).Times(1).Return(walletName, nil)
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
 	s.handler.EXPECT().UpdateMeta(walletName, pubKey, passphrase, []wallet.Meta{{
 		Key:   "role",
 		Value: metaRole,
@@ -704,7 +1371,8 @@ func testCheckTransactionSucceeds(t *testing.T) {
 	payload := fmt.Sprintf(`{"pubKey": "%s", "orderCancellation": {}}`, pubKey)
 
 	// setup
-	s.auth.EXPECT().VerifyToken(token).Times(1).Return(walletName, nil)
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName).Times(1).Return(true)
 	s.handler.EXPECT().SignTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(&commandspb.Transaction{}, nil)
 	s.nodeForward.EXPECT().CheckTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(&api.CheckTransactionResponse{
 		Success:   true,
@@ -744,7 +1412,8 @@ func testCheckTransactionWithRejectedTransactionSucceeds(t *testing.T) {
 	payload := fmt.Sprintf(`{"pubKey": "%s", "orderCancellation": {}}`, pubKey)
 
 	// setup
-	s.auth.EXPECT().VerifyToken(token).Times(1).Return(walletName, nil)
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName).Times(1).Return(true)
 	s.handler.EXPECT().SignTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(&commandspb.Transaction{}, nil)
 	s.nodeForward.EXPECT().CheckTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(&api.CheckTransactionResponse{
 		Success: false,
@@ -782,7 +1451,8 @@ func testCheckTransactionWithFailedTransactionFails(t *testing.T) {
 	payload := fmt.Sprintf(`{"pubKey": "%s", "orderCancellation": {}}`, pubKey)
 
 	// setup
-	s.auth.EXPECT().VerifyToken(token).Times(1).Return(walletName, nil)
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName).Times(1).Return(true)
 	s.handler.EXPECT().SignTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(&commandspb.Transaction{}, nil)
 	s.nodeForward.EXPECT().CheckTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil, assert.AnError)
 	s.nodeForward.EXPECT().LastBlockHeightAndHash(gomock.Any()).Times(1).Return(&api.LastBlockHeightResponse{
@@ -809,7 +1479,8 @@ func testAcceptSigningTransactionSucceeds(t *testing.T) {
 	payload := fmt.Sprintf(`{"pubKey": "%s", "orderCancellation": {}}`, pubKey)
 
 	// setup
-	s.auth.EXPECT().VerifyToken(token).Times(1).Return(walletName, nil)
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName).Times(1).Return(true)
 	s.handler.EXPECT().SignTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(&commandspb.Transaction{}, nil)
 	s.nodeForward.EXPECT().SendTx(gomock.Any(), gomock.Any(), api.SubmitTransactionRequest_TYPE_ASYNC, gomock.Any()).Times(1)
 	s.nodeForward.EXPECT().LastBlockHeightAndHash(gomock.Any()).Times(1).Return(&api.LastBlockHeightResponse{
@@ -824,6 +1495,63 @@ func testAcceptSigningTransactionSucceeds(t *testing.T) {
 	assert.Equal(t, http.StatusOK, statusCode)
 }
 
+func testSigningTransactionResolvesWalletFromPubKey(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName1 := vgrand.RandomStr(5)
+	walletName2 := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+	pubKey := vgrand.RandomStr(5)
+	payload := fmt.Sprintf(`{"pubKey": "%s", "orderCancellation": {}}`, pubKey)
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName1, walletName2}, nil, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName2).Times(1).Return(true)
+	s.handler.EXPECT().GetPublicKey(walletName1, pubKey).Times(1).Return(nil, wallet.ErrPubKeyDoesNotExist)
+	s.handler.EXPECT().GetPublicKey(walletName2, pubKey).Times(1).Return(&wallet.HDPublicKey{}, nil)
+	s.handler.EXPECT().SignTx(walletName2, gomock.Any(), gomock.Any()).Times(1).Return(&commandspb.Transaction{}, nil)
+	s.nodeForward.EXPECT().SendTx(gomock.Any(), gomock.Any(), api.SubmitTransactionRequest_TYPE_ASYNC, gomock.Any()).Times(1)
+	s.nodeForward.EXPECT().LastBlockHeightAndHash(gomock.Any()).Times(1).Return(&api.LastBlockHeightResponse{
+		Height:              42,
+		Hash:                "0292041e2f0cf741894503fb3ead4cb817bca2375e543aa70f7c4d938157b5a6",
+		SpamPowDifficulty:   2,
+		SpamPowHashFunction: "sha3_24_rounds",
+	}, 0, nil)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, signTxRequest(t, payload, headers))
+
+	// then
+	assert.Equal(t, http.StatusOK, statusCode)
+}
+
+func testSigningTransactionWithKeyOfNoWalletFails(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName1 := vgrand.RandomStr(5)
+	walletName2 := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+	pubKey := vgrand.RandomStr(5)
+	payload := fmt.Sprintf(`{"pubKey": "%s", "orderCancellation": {}}`, pubKey)
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName1, walletName2}, nil, nil)
+	s.handler.EXPECT().GetPublicKey(gomock.Any(), pubKey).Times(2).Return(nil, wallet.ErrPubKeyDoesNotExist)
+	s.handler.EXPECT().SignTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, signTxRequest(t, payload, headers))
+
+	// then
+	assert.Equal(t, http.StatusForbidden, statusCode)
+}
+
 func testDeclineSigningTransactionManuallySucceeds(t *testing.T) {
 	s := getTestService(t, "manual")
 	defer s.ctrl.Finish()
@@ -836,7 +1564,8 @@ func testDeclineSigningTransactionManuallySucceeds(t *testing.T) {
 	payload := fmt.Sprintf(`{"propagate": true, "pubKey": "%s", "orderCancellation": {}}`, pubKey)
 
 	// setup
-	s.auth.EXPECT().VerifyToken(token).Times(1).Return(walletName, nil)
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName).Times(1).Return(true)
 	s.handler.EXPECT().SignTx(walletName, gomock.Any(), gomock.Any()).Times(0).Return(&commandspb.Transaction{}, nil)
 	s.nodeForward.EXPECT().SendTx(gomock.Any(), gomock.Any(), api.SubmitTransactionRequest_TYPE_ASYNC, gomock.Any()).Times(0)
 	s.nodeForward.EXPECT().LastBlockHeightAndHash(gomock.Any()).Times(0)
@@ -846,6 +1575,30 @@ func testDeclineSigningTransactionManuallySucceeds(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, statusCode)
 }
 
+func testSigningTransactionWithLockedWalletFails(t *testing.T) {
+	s := getTestService(t, "manual")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+	payload := fmt.Sprintf(`{"pubKey": "%s", "orderCancellation": {}}`, vgrand.RandomStr(5))
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName).Times(1).Return(false)
+	s.handler.EXPECT().SignTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	s.nodeForward.EXPECT().LastBlockHeightAndHash(gomock.Any()).Times(0)
+
+	// when
+	statusCode, body := serveHTTP(t, s, signTxRequest(t, payload, headers))
+
+	// then
+	assert.Equal(t, http.StatusForbidden, statusCode)
+	assert.Contains(t, string(body), service.ErrWalletIsLocked.Error())
+}
+
 func testSigningTransactionWithPropagationSucceeds(t *testing.T) {
 	s := getTestService(t, "automatic")
 	defer s.ctrl.Finish()
@@ -857,7 +1610,8 @@ func testSigningTransactionWithPropagationSucceeds(t *testing.T) {
 	payload := fmt.Sprintf(`{"propagate": true, "pubKey": "%s", "orderCancellation": {}}`, vgrand.RandomStr(5))
 
 	// setup
-	s.auth.EXPECT().VerifyToken(token).Times(1).Return(walletName, nil)
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName).Times(1).Return(true)
 	s.handler.EXPECT().SignTx(walletName, gomock.Any(), gomock.Any()).Times(1).Return(&commandspb.Transaction{}, nil)
 	s.nodeForward.EXPECT().SendTx(gomock.Any(), gomock.Any(), api.SubmitTransactionRequest_TYPE_ASYNC, gomock.Any()).Times(1)
 	s.nodeForward.EXPECT().LastBlockHeightAndHash(gomock.Any()).Times(1).Return(&api.LastBlockHeightResponse{
@@ -885,7 +1639,8 @@ func testSigningTransactionWithFailedPropagationFails(t *testing.T) {
 	payload := fmt.Sprintf(`{"propagate": true, "pubKey": "%s", "orderCancellation": {}}`, vgrand.RandomStr(5))
 
 	// setup
-	s.auth.EXPECT().VerifyToken(token).Times(1).Return(walletName, nil)
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName).Times(1).Return(true)
 	s.handler.EXPECT().SignTx(walletName, gomock.Any(), gomock.Any()).Times(1).Return(&commandspb.Transaction{}, nil)
 	s.nodeForward.EXPECT().SendTx(gomock.Any(), gomock.Any(), api.SubmitTransactionRequest_TYPE_ASYNC, gomock.Any()).Times(1).Return("", assert.AnError)
 	s.nodeForward.EXPECT().LastBlockHeightAndHash(gomock.Any()).Times(1).Return(&api.LastBlockHeightResponse{
@@ -913,7 +1668,8 @@ func testFailedTransactionSigningFails(t *testing.T) {
 	payload := fmt.Sprintf(`{"propagate": true, "pubKey": "%s", "orderCancellation": {}}`, vgrand.RandomStr(5))
 
 	// setup
-	s.auth.EXPECT().VerifyToken(token).Times(1).Return(walletName, nil)
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName).Times(1).Return(true)
 	s.handler.EXPECT().SignTx(walletName, gomock.Any(), gomock.Any()).Times(1).Return(nil, assert.AnError)
 	s.nodeForward.EXPECT().SendTx(gomock.Any(), &commandspb.Transaction{}, api.SubmitTransactionRequest_TYPE_ASYNC, gomock.Any()).Times(0)
 	s.nodeForward.EXPECT().LastBlockHeightAndHash(gomock.Any()).Times(1).Return(&api.LastBlockHeightResponse{
@@ -968,7 +1724,7 @@ func testSigningTransactionWithInvalidRequestFails(t *testing.T) {
 				s.ctrl.Finish()
 			})
 			if tc.name != "no header" && tc.name != "no token" {
-				s.auth.EXPECT().VerifyToken(token).Times(1)
+				s.auth.EXPECT().VerifyScopedToken(token, "").Times(1)
 			}
 			// when
 			statusCode, _ := serveHTTP(tt, s, signTxRequest(tt, tc.payload, tc.headers))
@@ -978,6 +1734,64 @@ func testSigningTransactionWithInvalidRequestFails(t *testing.T) {
 	}
 }
 
+func testSigningTransactionWithCommandNotAllowedByTokenFails(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+	payload := fmt.Sprintf(`{"pubKey": "%s", "voteSubmission": {}}`, vgrand.RandomStr(5))
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, &service.Scopes{
+		Commands: []string{"orderSubmission", "orderCancellation"},
+	}, nil)
+	s.handler.EXPECT().SignTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	s.nodeForward.EXPECT().LastBlockHeightAndHash(gomock.Any()).Times(0)
+	s.nodeForward.EXPECT().SendTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, signTxRequest(t, payload, headers))
+
+	// then
+	assert.Equal(t, http.StatusForbidden, statusCode)
+}
+
+func testSigningTransactionWithCommandAllowedByTokenSucceeds(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+	pubKey := vgrand.RandomStr(5)
+	payload := fmt.Sprintf(`{"pubKey": "%s", "orderCancellation": {}}`, pubKey)
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, &service.Scopes{
+		PublicKeys: []string{pubKey},
+		Commands:   []string{"orderCancellation"},
+	}, nil)
+	s.handler.EXPECT().IsWalletUnlocked(walletName).Times(1).Return(true)
+	s.handler.EXPECT().SignTx(walletName, gomock.Any(), gomock.Any()).Times(1).Return(&commandspb.Transaction{}, nil)
+	s.nodeForward.EXPECT().SendTx(gomock.Any(), gomock.Any(), api.SubmitTransactionRequest_TYPE_ASYNC, gomock.Any()).Times(1)
+	s.nodeForward.EXPECT().LastBlockHeightAndHash(gomock.Any()).Times(1).Return(&api.LastBlockHeightResponse{
+		Height:              42,
+		Hash:                "0292041e2f0cf741894503fb3ead4cb817bca2375e543aa70f7c4d938157b5a6",
+		SpamPowDifficulty:   2,
+		SpamPowHashFunction: "sha3_24_rounds",
+	}, 0, nil)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, signTxRequest(t, payload, headers))
+
+	// then
+	assert.Equal(t, http.StatusOK, statusCode)
+}
+
 func testSigningAnythingSucceeds(t *testing.T) {
 	s := getTestService(t, "automatic")
 	defer s.ctrl.Finish()
@@ -990,7 +1804,7 @@ func testSigningAnythingSucceeds(t *testing.T) {
 	payload := fmt.Sprintf(`{"inputData": "c3BpY2Ugb2YgZHVuZQ==", "pubKey": "%s"}`, pubKey)
 
 	// setup
-	s.auth.EXPECT().VerifyToken(token).Times(1).Return(walletName, nil)
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, nil, nil)
 	s.handler.EXPECT().SignAny(walletName, []byte("spice of dune"), pubKey).Times(1).Return([]byte("some sig"), nil)
 
 	// when
@@ -1000,6 +1814,30 @@ func testSigningAnythingSucceeds(t *testing.T) {
 	assert.Equal(t, http.StatusOK, statusCode)
 }
 
+func testSigningAnythingWithKeyNotAllowedByTokenFails(t *testing.T) {
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+
+	// given
+	walletName := vgrand.RandomStr(5)
+	pubKey := vgrand.RandomStr(5)
+	token := vgrand.RandomStr(5)
+	headers := authHeaders(t, token)
+	payload := fmt.Sprintf(`{"inputData": "c3BpY2Ugb2YgZHVuZQ==", "pubKey": "%s"}`, pubKey)
+
+	// setup
+	s.auth.EXPECT().VerifyScopedToken(token, "").Times(1).Return([]string{walletName}, &service.Scopes{
+		PublicKeys: []string{vgrand.RandomStr(5)},
+	}, nil)
+	s.handler.EXPECT().SignAny(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	// when
+	statusCode, _ := serveHTTP(t, s, signAnyRequest(t, payload, headers))
+
+	// then
+	assert.Equal(t, http.StatusForbidden, statusCode)
+}
+
 func testSigningAnyDataWithInvalidRequestFails(t *testing.T) {
 	tcs := []struct {
 		name    string
@@ -1132,6 +1970,31 @@ func logoutRequest(t *testing.T, headers map[string]string) *http.Request {
 	return buildRequest(t, http.MethodDelete, "/api/v1/auth/token", "", headers)
 }
 
+func introspectTokenRequest(t *testing.T, headers map[string]string) *http.Request {
+	t.Helper()
+	return buildRequest(t, http.MethodGet, "/api/v1/auth/token", "", headers)
+}
+
+func unlockWalletRequest(t *testing.T, payload string, headers map[string]string) *http.Request {
+	t.Helper()
+	return buildRequest(t, http.MethodPost, "/api/v1/wallets/unlock", payload, headers)
+}
+
+func refreshTokenRequest(t *testing.T, headers map[string]string) *http.Request {
+	t.Helper()
+	return buildRequest(t, http.MethodPost, "/api/v1/auth/token/refresh", "", headers)
+}
+
+func listSessionsRequest(t *testing.T, headers map[string]string) *http.Request {
+	t.Helper()
+	return buildRequest(t, http.MethodGet, "/api/v1/auth/sessions", "", headers)
+}
+
+func revokeSessionsRequest(t *testing.T, headers map[string]string) *http.Request {
+	t.Helper()
+	return buildRequest(t, http.MethodDelete, "/api/v1/auth/sessions", "", headers)
+}
+
 func createWalletRequest(t *testing.T, payload string) *http.Request {
 	t.Helper()
 	return buildRequest(t, http.MethodPost, "/api/v1/wallets", payload, nil)
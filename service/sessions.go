@@ -0,0 +1,192 @@
+package service
+
+import (
+	"fmt"
+	"time"
+)
+
+// Session describes an authenticated session, bound to a token.
+type Session struct {
+	ID string `json:"id"`
+	// Wallet is the default wallet of the session.
+	Wallet string `json:"wallet"`
+	// Wallets is only set when the session gives access to several wallets,
+	// the default one first.
+	Wallets   []string  `json:"wallets,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	// Origin is the origin of the web page that obtained the token. It's empty
+	// when the token has been obtained by a client that is not a web page.
+	Origin string  `json:"origin,omitempty"`
+	Scopes *Scopes `json:"scopes,omitempty"`
+	// Passphrase is the passphrase of the wallet, only persisted when the
+	// wallets should be unlocked automatically when the sessions are restored.
+	Passphrase string `json:"passphrase,omitempty"`
+	// Passphrases holds the passphrases of the wallets, instead of Passphrase,
+	// when the session gives access to several wallets.
+	Passphrases map[string]string `json:"passphrases,omitempty"`
+}
+
+func (s Session) IsExpired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
+
+// WalletNames returns the wallets the session gives access to, the default
+// one first.
+func (s Session) WalletNames() []string {
+	if len(s.Wallets) != 0 {
+		return s.Wallets
+	}
+	return []string{s.Wallet}
+}
+
+// HasWallet verifies the session gives access to one of the wallets.
+func (s Session) HasWallet(names ...string) bool {
+	for _, w := range s.WalletNames() {
+		for _, name := range names {
+			if w == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PassphraseFor returns the saved passphrase of the wallet, if any.
+func (s Session) PassphraseFor(wallet string) string {
+	if len(s.Wallets) == 0 {
+		return s.Passphrase
+	}
+	return s.Passphrases[wallet]
+}
+
+// SetPassphrases saves the known passphrases of the wallets in the session.
+func (s *Session) SetPassphrases(passphrases PassphraseGetter) {
+	if len(s.Wallets) == 0 {
+		if passphrase, ok := passphrases(s.Wallet); ok {
+			s.Passphrase = passphrase
+		}
+		return
+	}
+
+	saved := make(map[string]string, len(s.Wallets))
+	for name, passphrase := range s.Passphrases {
+		saved[name] = passphrase
+	}
+	for _, name := range s.Wallets {
+		if passphrase, ok := passphrases(name); ok {
+			saved[name] = passphrase
+		}
+	}
+	s.Passphrases = saved
+}
+
+// SessionStore persists the sessions, so they survive a restart of the
+// service.
+type SessionStore interface {
+	GetSessions() ([]Session, error)
+	SaveSessions([]Session) error
+}
+
+// PassphraseGetter returns the passphrase of the specified wallet, if known.
+type PassphraseGetter func(wallet string) (string, bool)
+
+// RemoveExpiredSessions returns the sessions that haven't expired yet.
+func RemoveExpiredSessions(sessions []Session, now time.Time) []Session {
+	active := make([]Session, 0, len(sessions))
+	for _, session := range sessions {
+		if !session.IsExpired(now) {
+			active = append(active, session)
+		}
+	}
+	return active
+}
+
+// SessionSummary describes a persisted session, without its secrets.
+type SessionSummary struct {
+	ID              string    `json:"id"`
+	Wallet          string    `json:"wallet"`
+	Wallets         []string  `json:"wallets,omitempty"`
+	CreatedAt       time.Time `json:"createdAt"`
+	ExpiresAt       time.Time `json:"expiresAt"`
+	Origin          string    `json:"origin,omitempty"`
+	Scopes          *Scopes   `json:"scopes,omitempty"`
+	PassphraseSaved bool      `json:"passphraseSaved"`
+}
+
+func summarizeSession(session Session) SessionSummary {
+	return SessionSummary{
+		ID:              session.ID,
+		Wallet:          session.Wallet,
+		Wallets:         session.Wallets,
+		CreatedAt:       session.CreatedAt,
+		ExpiresAt:       session.ExpiresAt,
+		Origin:          session.Origin,
+		Scopes:          session.Scopes,
+		PassphraseSaved: len(session.Passphrase) != 0 || len(session.Passphrases) != 0,
+	}
+}
+
+type ListSessionsResponse struct {
+	Sessions []SessionSummary `json:"sessions"`
+}
+
+// ListSessions returns the persisted sessions that haven't expired.
+func ListSessions(store SessionStore) (*ListSessionsResponse, error) {
+	sessions, err := store.GetSessions()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get the sessions: %w", err)
+	}
+
+	resp := &ListSessionsResponse{
+		Sessions: []SessionSummary{},
+	}
+	for _, session := range RemoveExpiredSessions(sessions, time.Now()) {
+		resp.Sessions = append(resp.Sessions, summarizeSession(session))
+	}
+
+	return resp, nil
+}
+
+// RevokeSessionsRequest describes the sessions to revoke. Only one of the
+// fields should be set.
+type RevokeSessionsRequest struct {
+	Session string
+	Wallet  string
+	All     bool
+}
+
+type RevokeSessionsResponse struct {
+	Revoked []SessionSummary `json:"revoked"`
+}
+
+// RevokeSessions removes the specified sessions from the store. The expired
+// sessions are removed as well.
+func RevokeSessions(store SessionStore, req *RevokeSessionsRequest) (*RevokeSessionsResponse, error) {
+	sessions, err := store.GetSessions()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get the sessions: %w", err)
+	}
+
+	resp := &RevokeSessionsResponse{
+		Revoked: []SessionSummary{},
+	}
+	kept := []Session{}
+	for _, session := range RemoveExpiredSessions(sessions, time.Now()) {
+		if req.All || session.ID == req.Session || (len(req.Wallet) != 0 && session.HasWallet(req.Wallet)) {
+			resp.Revoked = append(resp.Revoked, summarizeSession(session))
+			continue
+		}
+		kept = append(kept, session)
+	}
+
+	if len(req.Session) != 0 && len(resp.Revoked) == 0 {
+		return nil, ErrSessionNotFound
+	}
+
+	if err := store.SaveSessions(kept); err != nil {
+		return nil, fmt.Errorf("couldn't save the sessions: %w", err)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,327 @@
+package service
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"time"
+
+	"code.vegaprotocol.io/vegawallet/network"
+	"github.com/dgrijalva/jwt-go/v4"
+)
+
+const (
+	// SigningAlgorithmPS256 signs the tokens with an RSA-4096 key. It's the
+	// algorithm used by default.
+	SigningAlgorithmPS256 = "PS256"
+	// SigningAlgorithmEdDSA signs the tokens with an Ed25519 key. Generating
+	// the key, and verifying the tokens, is much faster than with RSA.
+	SigningAlgorithmEdDSA = "EdDSA"
+
+	// DefaultKeysGracePeriod is the duration the previous public key is still
+	// accepted after a rotation, when no network is configured. It matches the
+	// default token expiry.
+	DefaultKeysGracePeriod = 24 * time.Hour
+
+	keyIDLength = 8
+)
+
+// SupportedSigningAlgorithms lists the algorithms the tokens can be signed
+// with.
+var SupportedSigningAlgorithms = []string{SigningAlgorithmPS256, SigningAlgorithmEdDSA}
+
+//go:generate go run github.com/golang/mock/mockgen -destination mocks/signing_keys_store_mock.go -package mocks code.vegaprotocol.io/vegawallet/service SigningKeysStore
+
+// SigningKeysStore persists the keys signing the tokens. The current key pair
+// is stored as the RSA keys used to be, whatever its algorithm.
+type SigningKeysStore interface {
+	GetRsaKeys() (*RSAKeys, error)
+	SaveRSAKeys(*RSAKeys) error
+	GetPreviousSigningKeys() ([]PreviousSigningKey, error)
+	SavePreviousSigningKeys([]PreviousSigningKey) error
+}
+
+// PreviousSigningKey is a public key that signed the tokens before a rotation.
+// It's kept to verify these tokens until the end of the grace period.
+type PreviousSigningKey struct {
+	ID        string    `json:"id"`
+	Algorithm string    `json:"algorithm"`
+	Pub       string    `json:"pub"`
+	RetiredAt time.Time `json:"retiredAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+func (k PreviousSigningKey) IsExpired(now time.Time) bool {
+	return !now.Before(k.ExpiresAt)
+}
+
+// GenerateSigningKeys generates a key pair for the specified algorithm. The
+// keys are PEM-encoded, so they can be stored as the RSA keys.
+func GenerateSigningKeys(algorithm string) (*RSAKeys, error) {
+	switch algorithm {
+	case SigningAlgorithmPS256:
+		return GenerateRSAKeys()
+	case SigningAlgorithmEdDSA:
+		return GenerateEd25519Keys()
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedSigningAlgorithm, algorithm)
+	}
+}
+
+func GenerateEd25519Keys() (*RSAKeys, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't generate Ed25519 keys: %w", err)
+	}
+
+	privBytes, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't marshal private Ed25519 key: %w", err)
+	}
+
+	pubBytes, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't marshal public Ed25519 key: %w", err)
+	}
+
+	return &RSAKeys{
+		Pub:  pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubBytes}),
+		Priv: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privBytes}),
+	}, nil
+}
+
+// SigningKeyID returns the ID of the public key, set in the "kid" header of
+// the tokens. It's derived from the key, so it doesn't have to be stored.
+func SigningKeyID(pub []byte) (string, error) {
+	block, _ := pem.Decode(pub)
+	if block == nil {
+		return "", ErrKeyIsNotPEMEncoded
+	}
+	sum := sha256.Sum256(block.Bytes)
+	return hex.EncodeToString(sum[:keyIDLength]), nil
+}
+
+// SigningAlgorithmOf returns the algorithm the public key verifies the tokens
+// with.
+func SigningAlgorithmOf(pub []byte) (string, error) {
+	method, _, err := parseSigningPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	return method.Alg(), nil
+}
+
+// KeysGracePeriod returns the longest token expiry of the networks, so the
+// tokens issued before a rotation can be used until they expire, whatever the
+// network the service runs with. It falls back to DefaultKeysGracePeriod when
+// there is no network.
+func KeysGracePeriod(nets []*network.Network) time.Duration {
+	gracePeriod := time.Duration(0)
+	for _, net := range nets {
+		if expiry := net.TokenExpiry.Get(); expiry > gracePeriod {
+			gracePeriod = expiry
+		}
+	}
+	if gracePeriod == 0 {
+		return DefaultKeysGracePeriod
+	}
+	return gracePeriod
+}
+
+// RotateServiceKeysRequest describes the request for RotateServiceKeys.
+type RotateServiceKeysRequest struct {
+	Algorithm   string
+	GracePeriod time.Duration
+}
+
+type RotateServiceKeysResponse struct {
+	CurrentKey   SigningKeySummary    `json:"currentKey"`
+	PreviousKeys []PreviousSigningKey `json:"previousKeys"`
+}
+
+type SigningKeySummary struct {
+	ID        string `json:"id"`
+	Algorithm string `json:"algorithm"`
+}
+
+// RotateServiceKeys replaces the key pair signing the tokens. The previous
+// public key keeps verifying the tokens it signed until the end of the grace
+// period. The previous keys whose grace period is over are removed. A running
+// service uses the new key once restarted.
+func RotateServiceKeys(store SigningKeysStore, req *RotateServiceKeysRequest) (*RotateServiceKeysResponse, error) {
+	currentKeys, err := store.GetRsaKeys()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get the current keys: %w", err)
+	}
+
+	currentID, err := SigningKeyID(currentKeys.Pub)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't compute the ID of the current key: %w", err)
+	}
+
+	currentAlgorithm, err := SigningAlgorithmOf(currentKeys.Pub)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get the algorithm of the current key: %w", err)
+	}
+
+	previousKeys, err := store.GetPreviousSigningKeys()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get the previous keys: %w", err)
+	}
+
+	newKeys, err := GenerateSigningKeys(req.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	newID, err := SigningKeyID(newKeys.Pub)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't compute the ID of the new key: %w", err)
+	}
+
+	now := time.Now()
+	keptKeys := make([]PreviousSigningKey, 0, len(previousKeys)+1)
+	for _, key := range previousKeys {
+		if !key.IsExpired(now) {
+			keptKeys = append(keptKeys, key)
+		}
+	}
+	keptKeys = append(keptKeys, PreviousSigningKey{
+		ID:        currentID,
+		Algorithm: currentAlgorithm,
+		Pub:       string(currentKeys.Pub),
+		RetiredAt: now,
+		ExpiresAt: now.Add(req.GracePeriod),
+	})
+
+	// The previous keys are saved first, so the tokens signed by the current
+	// key are still accepted if saving the new key fails.
+	if err := store.SavePreviousSigningKeys(keptKeys); err != nil {
+		return nil, fmt.Errorf("couldn't save the previous keys: %w", err)
+	}
+
+	if err := store.SaveRSAKeys(newKeys); err != nil {
+		return nil, fmt.Errorf("couldn't save the new keys: %w", err)
+	}
+
+	return &RotateServiceKeysResponse{
+		CurrentKey: SigningKeySummary{
+			ID:        newID,
+			Algorithm: req.Algorithm,
+		},
+		PreviousKeys: keptKeys,
+	}, nil
+}
+
+// verificationKey is a public key accepted to verify the tokens.
+type verificationKey struct {
+	id     string
+	method jwt.SigningMethod
+	pub    interface{}
+	// retiredAt and expiresAt are only set on the previous keys.
+	retiredAt time.Time
+	expiresAt time.Time
+}
+
+func parseSigningKeys(keys *RSAKeys) (verificationKey, crypto.Signer, error) {
+	method, pub, err := parseSigningPublicKey(keys.Pub)
+	if err != nil {
+		return verificationKey{}, nil, err
+	}
+
+	priv, err := parseSigningPrivateKey(keys.Priv)
+	if err != nil {
+		return verificationKey{}, nil, err
+	}
+
+	id, err := SigningKeyID(keys.Pub)
+	if err != nil {
+		return verificationKey{}, nil, err
+	}
+
+	return verificationKey{
+		id:     id,
+		method: method,
+		pub:    pub,
+	}, priv, nil
+}
+
+func parsePreviousSigningKeys(keys []PreviousSigningKey) ([]verificationKey, error) {
+	parsedKeys := make([]verificationKey, 0, len(keys))
+	for _, key := range keys {
+		method, pub, err := parseSigningPublicKey([]byte(key.Pub))
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse the previous key %s: %w", key.ID, err)
+		}
+		parsedKeys = append(parsedKeys, verificationKey{
+			id:        key.ID,
+			method:    method,
+			pub:       pub,
+			retiredAt: key.RetiredAt,
+			expiresAt: key.ExpiresAt,
+		})
+	}
+
+	sort.Slice(parsedKeys, func(i, j int) bool {
+		return parsedKeys[i].retiredAt.Before(parsedKeys[j].retiredAt)
+	})
+
+	return parsedKeys, nil
+}
+
+func parseSigningPublicKey(pub []byte) (jwt.SigningMethod, crypto.PublicKey, error) {
+	block, _ := pem.Decode(pub)
+	if block == nil {
+		return nil, nil, ErrKeyIsNotPEMEncoded
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't parse public key: %w", err)
+	}
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodPS256, k, nil
+	case ed25519.PublicKey:
+		return SigningMethodEdDSA, k, nil
+	default:
+		return nil, nil, ErrUnsupportedSigningKey
+	}
+}
+
+func parseSigningPrivateKey(priv []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(priv)
+	if block == nil {
+		return nil, ErrKeyIsNotPEMEncoded
+	}
+
+	if block.Type == "RSA PRIVATE KEY" {
+		key, err := jwt.ParseRSAPrivateKeyFromPEM(priv)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse private RSA key: %w", err)
+		}
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse private key: %w", err)
+	}
+
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return k, nil
+	case ed25519.PrivateKey:
+		return k, nil
+	default:
+		return nil, ErrUnsupportedSigningKey
+	}
+}
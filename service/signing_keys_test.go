@@ -0,0 +1,210 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"code.vegaprotocol.io/vegawallet/network"
+	"code.vegaprotocol.io/vegawallet/service"
+	"code.vegaprotocol.io/vegawallet/service/encoding"
+	"code.vegaprotocol.io/vegawallet/service/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigningKeys(t *testing.T) {
+	t.Run("Verifying Ed25519 keys succeeds", testVerifyingEd25519KeysSucceeds)
+	t.Run("Verifying keys from different pairs fails", testVerifyingKeysFromDifferentPairsFails)
+	t.Run("Key ID is derived from the public key", testKeyIDIsDerivedFromPublicKey)
+	t.Run("Rotating keys keeps the previous public key", testRotatingKeysKeepsPreviousPublicKey)
+	t.Run("Rotating keys removes the expired previous keys", testRotatingKeysRemovesExpiredPreviousKeys)
+	t.Run("Rotating keys with unsupported algorithm fails", testRotatingKeysWithUnsupportedAlgorithmFails)
+	t.Run("Grace period is the longest token expiry", testGracePeriodIsLongestTokenExpiry)
+	t.Run("Grace period without network is the default one", testGracePeriodWithoutNetworkIsDefaultOne)
+}
+
+func testVerifyingEd25519KeysSucceeds(t *testing.T) {
+	// given
+	keys, err := service.GenerateEd25519Keys()
+	require.NoError(t, err)
+
+	// when
+	err = service.VerifyRSAKeys(keys)
+
+	// then
+	require.NoError(t, err)
+}
+
+func testVerifyingKeysFromDifferentPairsFails(t *testing.T) {
+	// given
+	keys1, err := service.GenerateEd25519Keys()
+	require.NoError(t, err)
+	keys2, err := service.GenerateEd25519Keys()
+	require.NoError(t, err)
+
+	// when
+	err = service.VerifyRSAKeys(&service.RSAKeys{
+		Pub:  keys1.Pub,
+		Priv: keys2.Priv,
+	})
+
+	// then
+	require.ErrorIs(t, err, service.ErrRSAKeysDoNotMatch)
+}
+
+func testKeyIDIsDerivedFromPublicKey(t *testing.T) {
+	// given
+	keys1, err := service.GenerateEd25519Keys()
+	require.NoError(t, err)
+	keys2, err := service.GenerateEd25519Keys()
+	require.NoError(t, err)
+
+	// when
+	id1, err := service.SigningKeyID(keys1.Pub)
+	require.NoError(t, err)
+	sameID1, err := service.SigningKeyID(keys1.Pub)
+	require.NoError(t, err)
+	id2, err := service.SigningKeyID(keys2.Pub)
+	require.NoError(t, err)
+
+	// then
+	assert.Equal(t, id1, sameID1)
+	assert.NotEqual(t, id1, id2)
+}
+
+func testRotatingKeysKeepsPreviousPublicKey(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	store := mocks.NewMockSigningKeysStore(ctrl)
+	currentKeys, err := service.GenerateRSAKeys()
+	require.NoError(t, err)
+	currentID, err := service.SigningKeyID(currentKeys.Pub)
+	require.NoError(t, err)
+	req := &service.RotateServiceKeysRequest{
+		Algorithm:   service.SigningAlgorithmEdDSA,
+		GracePeriod: time.Hour,
+	}
+
+	// setup
+	var savedKeys *service.RSAKeys
+	store.EXPECT().GetRsaKeys().Times(1).Return(currentKeys, nil)
+	store.EXPECT().GetPreviousSigningKeys().Times(1).Return([]service.PreviousSigningKey{}, nil)
+	store.EXPECT().SavePreviousSigningKeys(gomock.Any()).Times(1).Return(nil)
+	store.EXPECT().SaveRSAKeys(gomock.Any()).Times(1).DoAndReturn(func(keys *service.RSAKeys) error {
+		savedKeys = keys
+		return nil
+	})
+
+	// when
+	resp, err := service.RotateServiceKeys(store, req)
+
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	require.NotNil(t, savedKeys)
+	require.NoError(t, service.VerifyRSAKeys(savedKeys))
+	algorithm, err := service.SigningAlgorithmOf(savedKeys.Pub)
+	require.NoError(t, err)
+	assert.Equal(t, service.SigningAlgorithmEdDSA, algorithm)
+	newID, err := service.SigningKeyID(savedKeys.Pub)
+	require.NoError(t, err)
+	assert.Equal(t, newID, resp.CurrentKey.ID)
+	assert.Equal(t, service.SigningAlgorithmEdDSA, resp.CurrentKey.Algorithm)
+	require.Len(t, resp.PreviousKeys, 1)
+	assert.Equal(t, currentID, resp.PreviousKeys[0].ID)
+	assert.Equal(t, service.SigningAlgorithmPS256, resp.PreviousKeys[0].Algorithm)
+	assert.Equal(t, string(currentKeys.Pub), resp.PreviousKeys[0].Pub)
+	assert.Equal(t, time.Hour, resp.PreviousKeys[0].ExpiresAt.Sub(resp.PreviousKeys[0].RetiredAt))
+}
+
+func testRotatingKeysRemovesExpiredPreviousKeys(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	store := mocks.NewMockSigningKeysStore(ctrl)
+	currentKeys, err := service.GenerateEd25519Keys()
+	require.NoError(t, err)
+	now := time.Now()
+	expiredKey := service.PreviousSigningKey{
+		ID:        "expired",
+		RetiredAt: now.Add(-2 * time.Hour),
+		ExpiresAt: now.Add(-time.Hour),
+	}
+	activeKey := service.PreviousSigningKey{
+		ID:        "active",
+		RetiredAt: now.Add(-time.Hour),
+		ExpiresAt: now.Add(time.Hour),
+	}
+	req := &service.RotateServiceKeysRequest{
+		Algorithm:   service.SigningAlgorithmEdDSA,
+		GracePeriod: time.Hour,
+	}
+
+	// setup
+	var savedPreviousKeys []service.PreviousSigningKey
+	store.EXPECT().GetRsaKeys().Times(1).Return(currentKeys, nil)
+	store.EXPECT().GetPreviousSigningKeys().Times(1).Return([]service.PreviousSigningKey{expiredKey, activeKey}, nil)
+	store.EXPECT().SavePreviousSigningKeys(gomock.Any()).Times(1).DoAndReturn(func(keys []service.PreviousSigningKey) error {
+		savedPreviousKeys = keys
+		return nil
+	})
+	store.EXPECT().SaveRSAKeys(gomock.Any()).Times(1).Return(nil)
+
+	// when
+	resp, err := service.RotateServiceKeys(store, req)
+
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	require.Len(t, savedPreviousKeys, 2)
+	assert.Equal(t, "active", savedPreviousKeys[0].ID)
+	assert.Equal(t, resp.PreviousKeys, savedPreviousKeys)
+}
+
+func testRotatingKeysWithUnsupportedAlgorithmFails(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	store := mocks.NewMockSigningKeysStore(ctrl)
+	currentKeys, err := service.GenerateEd25519Keys()
+	require.NoError(t, err)
+	req := &service.RotateServiceKeysRequest{
+		Algorithm:   "HS256",
+		GracePeriod: time.Hour,
+	}
+
+	// setup
+	store.EXPECT().GetRsaKeys().Times(1).Return(currentKeys, nil)
+	store.EXPECT().GetPreviousSigningKeys().Times(1).Return([]service.PreviousSigningKey{}, nil)
+	store.EXPECT().SavePreviousSigningKeys(gomock.Any()).Times(0)
+	store.EXPECT().SaveRSAKeys(gomock.Any()).Times(0)
+
+	// when
+	resp, err := service.RotateServiceKeys(store, req)
+
+	// then
+	require.ErrorIs(t, err, service.ErrUnsupportedSigningAlgorithm)
+	assert.Nil(t, resp)
+}
+
+func testGracePeriodIsLongestTokenExpiry(t *testing.T) {
+	// given
+	nets := []*network.Network{
+		{Name: "mainnet", TokenExpiry: encoding.Duration{Duration: time.Hour}},
+		{Name: "fairground", TokenExpiry: encoding.Duration{Duration: 72 * time.Hour}},
+		{Name: "local", TokenExpiry: encoding.Duration{Duration: 10 * time.Minute}},
+	}
+
+	// when
+	gracePeriod := service.KeysGracePeriod(nets)
+
+	// then
+	assert.Equal(t, 72*time.Hour, gracePeriod)
+}
+
+func testGracePeriodWithoutNetworkIsDefaultOne(t *testing.T) {
+	// when
+	gracePeriod := service.KeysGracePeriod([]*network.Network{})
+
+	// then
+	assert.Equal(t, service.DefaultKeysGracePeriod, gracePeriod)
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"strconv"
+	"time"
+
+	"code.vegaprotocol.io/vegawallet/network"
+)
+
+// ListenUnixSocket creates the Unix domain socket, with the configured mode
+// and ownership. The socket is created private to its owner, and only opened
+// to the configured mode once its ownership is set. A socket file left by a
+// service that didn't stop properly is removed.
+func ListenUnixSocket(cfg network.SocketConfig) (net.Listener, error) {
+	mode, err := cfg.FileMode()
+	if err != nil {
+		return nil, err
+	}
+
+	uid, gid, err := lookupSocketOwnership(cfg.Owner, cfg.Group)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := removeStaleSocket(cfg.Path); err != nil {
+		return nil, err
+	}
+
+	listener, err := listenUnix(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't listen on the socket %s: %w", cfg.Path, err)
+	}
+
+	if uid != -1 || gid != -1 {
+		if err := os.Chown(cfg.Path, uid, gid); err != nil {
+			_ = listener.Close()
+			return nil, fmt.Errorf("couldn't set the ownership of the socket %s: %w", cfg.Path, err)
+		}
+	}
+
+	if err := os.Chmod(cfg.Path, mode); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("couldn't set the mode of the socket %s: %w", cfg.Path, err)
+	}
+
+	return listener, nil
+}
+
+// removeStaleSocket removes the socket file if no service listens on it
+// anymore. Any other kind of file is left untouched.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't verify the socket %s: %w", path, err)
+	}
+
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%w: %s", ErrSocketPathIsNotSocket, path)
+	}
+
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("%w: %s", ErrSocketAlreadyInUse, path)
+	}
+
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("couldn't remove the stale socket %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// lookupSocketOwnership returns the IDs of the owner and the group. An empty
+// name returns -1, so the ownership isn't changed.
+func lookupSocketOwnership(owner, group string) (int, int, error) {
+	uid, gid := -1, -1
+
+	if len(owner) != 0 {
+		id := owner
+		if _, err := strconv.Atoi(owner); err != nil {
+			u, err := user.Lookup(owner)
+			if err != nil {
+				return 0, 0, fmt.Errorf("couldn't find the socket owner %s: %w", owner, err)
+			}
+			id = u.Uid
+		}
+		uid, _ = strconv.Atoi(id)
+	}
+
+	if len(group) != 0 {
+		id := group
+		if _, err := strconv.Atoi(group); err != nil {
+			g, err := user.LookupGroup(group)
+			if err != nil {
+				return 0, 0, fmt.Errorf("couldn't find the socket group %s: %w", group, err)
+			}
+			id = g.Gid
+		}
+		gid, _ = strconv.Atoi(id)
+	}
+
+	return uid, gid, nil
+}
@@ -0,0 +1,132 @@
+package service_test
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"code.vegaprotocol.io/vegawallet/network"
+	"code.vegaprotocol.io/vegawallet/service"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnixSocket(t *testing.T) {
+	t.Run("Serving on a Unix socket succeeds", testServingOnUnixSocketSucceeds)
+	t.Run("Listening on a Unix socket applies the mode", testListeningOnUnixSocketAppliesMode)
+	t.Run("Listening on a stale Unix socket replaces it", testListeningOnStaleUnixSocketReplacesIt)
+	t.Run("Listening on a Unix socket in use fails", testListeningOnUnixSocketInUseFails)
+	t.Run("Listening on a path that is not a socket fails", testListeningOnPathThatIsNotSocketFails)
+}
+
+func testServingOnUnixSocketSucceeds(t *testing.T) {
+	// given
+	s := getTestService(t, "automatic")
+	defer s.ctrl.Finish()
+	cfg := network.SocketConfig{
+		Path: filepath.Join(t.TempDir(), "wallet.sock"),
+	}
+	s.nodeForward.EXPECT().HealthCheck(gomock.Any()).Times(1).Return(nil)
+
+	// when
+	go func() {
+		if err := s.StartUnixSocket(cfg); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			t.Errorf("couldn't serve on the socket: %v", err)
+		}
+	}()
+	defer func() {
+		_ = s.Stop()
+	}()
+	waitForSocket(t, cfg.Path)
+	resp, err := service.NewUnixSocketClient(cfg.Path).Do(http.MethodGet, "/api/v1/status", "", nil)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func testListeningOnUnixSocketAppliesMode(t *testing.T) {
+	// given
+	cfg := network.SocketConfig{
+		Path: filepath.Join(t.TempDir(), "wallet.sock"),
+		Mode: "0660",
+	}
+
+	// when
+	listener, err := service.ListenUnixSocket(cfg)
+
+	// then
+	require.NoError(t, err)
+	defer listener.Close()
+	info, err := os.Stat(cfg.Path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o660), info.Mode().Perm())
+}
+
+func testListeningOnStaleUnixSocketReplacesIt(t *testing.T) {
+	// given
+	cfg := network.SocketConfig{
+		Path: filepath.Join(t.TempDir(), "wallet.sock"),
+	}
+	// The socket file is kept on close, as if the service didn't stop
+	// properly.
+	stale, err := net.Listen("unix", cfg.Path)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	// when
+	listener, err := service.ListenUnixSocket(cfg)
+
+	// then
+	require.NoError(t, err)
+	assert.NoError(t, listener.Close())
+}
+
+func testListeningOnUnixSocketInUseFails(t *testing.T) {
+	// given
+	cfg := network.SocketConfig{
+		Path: filepath.Join(t.TempDir(), "wallet.sock"),
+	}
+	listener, err := service.ListenUnixSocket(cfg)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	// when
+	_, err = service.ListenUnixSocket(cfg)
+
+	// then
+	require.ErrorIs(t, err, service.ErrSocketAlreadyInUse)
+}
+
+func testListeningOnPathThatIsNotSocketFails(t *testing.T) {
+	// given
+	cfg := network.SocketConfig{
+		Path: filepath.Join(t.TempDir(), "wallet.sock"),
+	}
+	require.NoError(t, os.WriteFile(cfg.Path, []byte("not a socket"), 0o600))
+
+	// when
+	_, err := service.ListenUnixSocket(cfg)
+
+	// then
+	require.ErrorIs(t, err, service.ErrSocketPathIsNotSocket)
+}
+
+func waitForSocket(t *testing.T, path string) {
+	t.Helper()
+
+	deadline := time.Now().Add(testRequestTimeout)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(path); err == nil {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("the socket %s hasn't been created", path)
+}
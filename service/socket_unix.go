@@ -0,0 +1,27 @@
+//go:build !windows
+// +build !windows
+
+package service
+
+import (
+	"net"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+// umaskMu serializes the umask changes, as the umask is shared by the whole
+// process.
+var umaskMu sync.Mutex
+
+// listenUnix creates the socket only accessible to its owner, so it can't be
+// reached before its mode and ownership are set.
+func listenUnix(path string) (net.Listener, error) {
+	umaskMu.Lock()
+	defer umaskMu.Unlock()
+
+	oldMask := unix.Umask(0o177)
+	defer unix.Umask(oldMask)
+
+	return net.Listen("unix", path)
+}
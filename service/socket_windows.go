@@ -0,0 +1,9 @@
+package service
+
+import "net"
+
+// listenUnix creates the socket. Windows doesn't apply a umask to the socket
+// files, their access is controlled by the directory they are created in.
+func listenUnix(path string) (net.Listener, error) {
+	return net.Listen("unix", path)
+}
@@ -0,0 +1,76 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	vgfs "code.vegaprotocol.io/shared/libs/fs"
+	"code.vegaprotocol.io/shared/paths"
+	"code.vegaprotocol.io/vegawallet/service"
+)
+
+// AdminTokensDataHome is the folder holding the admin tokens, one file per
+// network.
+var AdminTokensDataHome = paths.JoinDataPath(paths.WalletServiceDataHome, "admin-tokens")
+
+// AdminTokenStore persists the admin token of a network. Only the hash of the
+// secret is saved.
+type AdminTokenStore struct {
+	adminTokenFilePath string
+}
+
+func InitialiseAdminTokenStore(p paths.Paths, network string) (*AdminTokenStore, error) {
+	adminTokenFilePath, err := p.CreateDataPathFor(paths.JoinDataPath(AdminTokensDataHome, network))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get data path for %s: %w", AdminTokensDataHome, err)
+	}
+
+	return &AdminTokenStore{
+		adminTokenFilePath: adminTokenFilePath,
+	}, nil
+}
+
+func (s *AdminTokenStore) AdminTokenExists() (bool, error) {
+	return vgfs.FileExists(s.adminTokenFilePath)
+}
+
+func (s *AdminTokenStore) GetAdminTokenPath() string {
+	return s.adminTokenFilePath
+}
+
+// GetAdminToken returns the admin token. If no token has been generated yet,
+// nil is returned.
+func (s *AdminTokenStore) GetAdminToken() (*service.AdminToken, error) {
+	exists, err := s.AdminTokenExists()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't verify the admin token file existence: %w", err)
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	buf, err := vgfs.ReadFile(s.adminTokenFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read admin token file: %w", err)
+	}
+
+	token := &service.AdminToken{}
+	if err := json.Unmarshal(buf, token); err != nil {
+		return nil, fmt.Errorf("couldn't unmarshal admin token: %w", err)
+	}
+
+	return token, nil
+}
+
+func (s *AdminTokenStore) SaveAdminToken(token service.AdminToken) error {
+	buf, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("couldn't marshal admin token: %w", err)
+	}
+
+	if err := vgfs.WriteFile(s.adminTokenFilePath, buf); err != nil {
+		return fmt.Errorf("unable to save admin token: %w", err)
+	}
+
+	return nil
+}
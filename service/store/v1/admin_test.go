@@ -0,0 +1,59 @@
+package v1_test
+
+import (
+	"testing"
+	"time"
+
+	vgrand "code.vegaprotocol.io/shared/libs/rand"
+	vgtest "code.vegaprotocol.io/shared/libs/test"
+	"code.vegaprotocol.io/vegawallet/service"
+	v1 "code.vegaprotocol.io/vegawallet/service/store/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminTokenStoreV1(t *testing.T) {
+	t.Run("Getting admin token without file succeeds", testAdminTokenStoreV1GettingAdminTokenWithoutFileSucceeds)
+	t.Run("Saving admin token succeeds", testAdminTokenStoreV1SavingAdminTokenSucceeds)
+}
+
+func testAdminTokenStoreV1GettingAdminTokenWithoutFileSucceeds(t *testing.T) {
+	vegaHome := newVegaHome(t)
+
+	// given
+	s, err := v1.InitialiseAdminTokenStore(vegaHome, vgrand.RandomStr(5))
+	require.NoError(t, err)
+
+	// when
+	token, err := s.GetAdminToken()
+
+	// then
+	require.NoError(t, err)
+	assert.Nil(t, token)
+}
+
+func testAdminTokenStoreV1SavingAdminTokenSucceeds(t *testing.T) {
+	vegaHome := newVegaHome(t)
+
+	// given
+	s, err := v1.InitialiseAdminTokenStore(vegaHome, vgrand.RandomStr(5))
+	require.NoError(t, err)
+	token := service.AdminToken{
+		SecretHash: vgrand.RandomStr(64),
+		CreatedAt:  time.Now().UTC().Truncate(time.Second),
+	}
+
+	// when
+	err = s.SaveAdminToken(token)
+
+	// then
+	require.NoError(t, err)
+	vgtest.AssertFileAccess(t, s.GetAdminTokenPath())
+
+	// when
+	returnedToken, err := s.GetAdminToken()
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, &token, returnedToken)
+}
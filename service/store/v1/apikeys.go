@@ -0,0 +1,75 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	vgfs "code.vegaprotocol.io/shared/libs/fs"
+	"code.vegaprotocol.io/shared/paths"
+	"code.vegaprotocol.io/vegawallet/service"
+)
+
+// APIKeysDataHome is the folder holding the API keys, one file per network.
+var APIKeysDataHome = paths.JoinDataPath(paths.WalletServiceDataHome, "api-keys")
+
+// APIKeysStore persists the API keys of a network. Only the hashes of the
+// secrets are saved.
+type APIKeysStore struct {
+	apiKeysFilePath string
+}
+
+func InitialiseAPIKeysStore(p paths.Paths, network string) (*APIKeysStore, error) {
+	apiKeysFilePath, err := p.CreateDataPathFor(paths.JoinDataPath(APIKeysDataHome, network))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get data path for %s: %w", APIKeysDataHome, err)
+	}
+
+	return &APIKeysStore{
+		apiKeysFilePath: apiKeysFilePath,
+	}, nil
+}
+
+func (s *APIKeysStore) APIKeysExists() (bool, error) {
+	return vgfs.FileExists(s.apiKeysFilePath)
+}
+
+func (s *APIKeysStore) GetAPIKeysPath() string {
+	return s.apiKeysFilePath
+}
+
+// GetAPIKeys returns the API keys. If no key has been created yet, an empty
+// list is returned.
+func (s *APIKeysStore) GetAPIKeys() ([]service.APIKey, error) {
+	exists, err := s.APIKeysExists()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't verify the API keys file existence: %w", err)
+	}
+	if !exists {
+		return []service.APIKey{}, nil
+	}
+
+	buf, err := vgfs.ReadFile(s.apiKeysFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read API keys file: %w", err)
+	}
+
+	keys := []service.APIKey{}
+	if err := json.Unmarshal(buf, &keys); err != nil {
+		return nil, fmt.Errorf("couldn't unmarshal API keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+func (s *APIKeysStore) SaveAPIKeys(keys []service.APIKey) error {
+	buf, err := json.Marshal(keys)
+	if err != nil {
+		return fmt.Errorf("couldn't marshal API keys: %w", err)
+	}
+
+	if err := vgfs.WriteFile(s.apiKeysFilePath, buf); err != nil {
+		return fmt.Errorf("unable to save API keys: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package v1_test
+
+import (
+	"testing"
+	"time"
+
+	vgrand "code.vegaprotocol.io/shared/libs/rand"
+	vgtest "code.vegaprotocol.io/shared/libs/test"
+	"code.vegaprotocol.io/vegawallet/service"
+	v1 "code.vegaprotocol.io/vegawallet/service/store/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeysStoreV1(t *testing.T) {
+	t.Run("Getting API keys without file succeeds", testAPIKeysStoreV1GettingAPIKeysWithoutFileSucceeds)
+	t.Run("Saving API keys succeeds", testAPIKeysStoreV1SavingAPIKeysSucceeds)
+}
+
+func testAPIKeysStoreV1GettingAPIKeysWithoutFileSucceeds(t *testing.T) {
+	vegaHome := newVegaHome(t)
+
+	// given
+	s, err := v1.InitialiseAPIKeysStore(vegaHome, vgrand.RandomStr(5))
+	require.NoError(t, err)
+
+	// when
+	keys, err := s.GetAPIKeys()
+
+	// then
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func testAPIKeysStoreV1SavingAPIKeysSucceeds(t *testing.T) {
+	vegaHome := newVegaHome(t)
+
+	// given
+	s, err := v1.InitialiseAPIKeysStore(vegaHome, vgrand.RandomStr(5))
+	require.NoError(t, err)
+	keys := []service.APIKey{
+		{
+			ID:             vgrand.RandomStr(10),
+			Wallet:         vgrand.RandomStr(5),
+			SecretHash:     vgrand.RandomStr(64),
+			CreatedAt:      time.Now().UTC().Truncate(time.Second),
+			PassphraseFile: vgrand.RandomStr(10),
+			Scopes: &service.Scopes{
+				ReadOnly: true,
+			},
+		},
+	}
+
+	// when
+	err = s.SaveAPIKeys(keys)
+
+	// then
+	require.NoError(t, err)
+	vgtest.AssertFileAccess(t, s.GetAPIKeysPath())
+
+	// when
+	returnedKeys, err := s.GetAPIKeys()
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, keys, returnedKeys)
+}
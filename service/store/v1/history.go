@@ -0,0 +1,124 @@
+package v1
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	vgfs "code.vegaprotocol.io/shared/libs/fs"
+	"code.vegaprotocol.io/shared/paths"
+	"code.vegaprotocol.io/vegawallet/service"
+	"go.uber.org/zap"
+)
+
+// TransactionsDataHome is the folder holding the transaction history, one file
+// per network.
+var TransactionsDataHome = paths.JoinDataPath(paths.WalletServiceDataHome, "transactions")
+
+// HistoryStore persists the transactions submitted to the service on a
+// network. The records are appended to the file, one JSON object per line, so
+// a record is never rewritten.
+type HistoryStore struct {
+	log             *zap.Logger
+	historyFilePath string
+	mu              sync.Mutex
+}
+
+func InitialiseHistoryStore(log *zap.Logger, p paths.Paths, network string) (*HistoryStore, error) {
+	historyFilePath, err := p.CreateDataPathFor(paths.JoinDataPath(TransactionsDataHome, network))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get data path for %s: %w", TransactionsDataHome, err)
+	}
+
+	return &HistoryStore{
+		log:             log,
+		historyFilePath: historyFilePath,
+	}, nil
+}
+
+func (s *HistoryStore) HistoryExists() (bool, error) {
+	return vgfs.FileExists(s.historyFilePath)
+}
+
+func (s *HistoryStore) GetHistoryPath() string {
+	return s.historyFilePath
+}
+
+func (s *HistoryStore) SaveTransaction(record service.TransactionRecord) error {
+	buf, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("couldn't marshal transaction: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.historyFilePath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600) // nolint:gomnd
+	if err != nil {
+		return fmt.Errorf("couldn't open transaction history file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(buf, '\n')); err != nil {
+		return fmt.Errorf("unable to save transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ListTransactions returns the transactions matching the filter, from the
+// oldest to the latest. If no transaction has been saved yet, an empty list
+// is returned. The lines that can't be decoded, like a record torn by a crash
+// during its append, are skipped, so they don't hide the rest of the history.
+func (s *HistoryStore) ListTransactions(filter service.TransactionFilter) ([]service.TransactionRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records := []service.TransactionRecord{}
+
+	f, err := os.Open(s.historyFilePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return records, nil
+		}
+		return nil, fmt.Errorf("couldn't open transaction history file: %w", err)
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("couldn't read transaction history file: %w", err)
+		}
+		reachedEnd := err != nil
+
+		if line = bytes.TrimSpace(line); len(line) != 0 {
+			record := service.TransactionRecord{}
+			if err := json.Unmarshal(line, &record); err != nil {
+				s.log.Warn("skipping transaction that couldn't be unmarshalled",
+					zap.String("file", s.historyFilePath),
+					zap.Int("line", lineNumber),
+					zap.Error(err),
+				)
+			} else if filter.Match(record) {
+				records = append(records, record)
+			}
+		}
+
+		if reachedEnd {
+			break
+		}
+	}
+
+	if filter.Limit != 0 && len(records) > filter.Limit {
+		records = records[len(records)-filter.Limit:]
+	}
+
+	return records, nil
+}
@@ -0,0 +1,146 @@
+package v1_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	vgrand "code.vegaprotocol.io/shared/libs/rand"
+	vgtest "code.vegaprotocol.io/shared/libs/test"
+	"code.vegaprotocol.io/vegawallet/service"
+	v1 "code.vegaprotocol.io/vegawallet/service/store/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestHistoryStoreV1(t *testing.T) {
+	t.Run("Listing transactions without file succeeds", testHistoryStoreV1ListingTransactionsWithoutFileSucceeds)
+	t.Run("Saving transactions succeeds", testHistoryStoreV1SavingTransactionsSucceeds)
+	t.Run("Listing transactions with filter succeeds", testHistoryStoreV1ListingTransactionsWithFilterSucceeds)
+	t.Run("Listing transactions with truncated last record succeeds", testHistoryStoreV1ListingTransactionsWithTruncatedLastRecordSucceeds)
+}
+
+func testHistoryStoreV1ListingTransactionsWithoutFileSucceeds(t *testing.T) {
+	vegaHome := newVegaHome(t)
+
+	// given
+	s, err := v1.InitialiseHistoryStore(zap.NewNop(), vegaHome, vgrand.RandomStr(5))
+	require.NoError(t, err)
+
+	// when
+	txs, err := s.ListTransactions(service.TransactionFilter{})
+
+	// then
+	require.NoError(t, err)
+	assert.Empty(t, txs)
+}
+
+func testHistoryStoreV1SavingTransactionsSucceeds(t *testing.T) {
+	vegaHome := newVegaHome(t)
+
+	// given
+	s, err := v1.InitialiseHistoryStore(zap.NewNop(), vegaHome, vgrand.RandomStr(5))
+	require.NoError(t, err)
+	sentAt := time.Now().UTC().Truncate(time.Second)
+	txs := []service.TransactionRecord{
+		{
+			TxID:       vgrand.RandomStr(5),
+			Wallet:     vgrand.RandomStr(5),
+			PubKey:     vgrand.RandomStr(5),
+			Command:    "orderCancellation",
+			Status:     service.TransactionStatusSent,
+			Signed:     true,
+			TxHash:     vgrand.RandomStr(5),
+			ReceivedAt: sentAt.Add(-time.Second),
+			SentAt:     &sentAt,
+		}, {
+			TxID:       vgrand.RandomStr(5),
+			Wallet:     vgrand.RandomStr(5),
+			PubKey:     vgrand.RandomStr(5),
+			Command:    "orderSubmission",
+			Status:     service.TransactionStatusFailed,
+			ReceivedAt: sentAt,
+			Error:      "couldn't get last block height",
+		},
+	}
+
+	// when
+	for _, tx := range txs {
+		require.NoError(t, s.SaveTransaction(tx))
+	}
+
+	// then
+	vgtest.AssertFileAccess(t, s.GetHistoryPath())
+
+	// when
+	returnedTxs, err := s.ListTransactions(service.TransactionFilter{})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, txs, returnedTxs)
+}
+
+func testHistoryStoreV1ListingTransactionsWithFilterSucceeds(t *testing.T) {
+	vegaHome := newVegaHome(t)
+
+	// given
+	s, err := v1.InitialiseHistoryStore(zap.NewNop(), vegaHome, vgrand.RandomStr(5))
+	require.NoError(t, err)
+	receivedAt := time.Now().UTC().Truncate(time.Second)
+	for i, status := range []service.TransactionStatus{
+		service.TransactionStatusSent,
+		service.TransactionStatusRejected,
+		service.TransactionStatusSent,
+		service.TransactionStatusSent,
+	} {
+		require.NoError(t, s.SaveTransaction(service.TransactionRecord{
+			TxID:       vgrand.RandomStr(5),
+			Wallet:     "wallet",
+			Status:     status,
+			ReceivedAt: receivedAt.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+	since := receivedAt.Add(time.Minute)
+
+	// when
+	txs, err := s.ListTransactions(service.TransactionFilter{
+		Status: service.TransactionStatusSent,
+		Since:  &since,
+		Limit:  1,
+	})
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+	assert.Equal(t, receivedAt.Add(3*time.Minute), txs[0].ReceivedAt)
+}
+
+func testHistoryStoreV1ListingTransactionsWithTruncatedLastRecordSucceeds(t *testing.T) {
+	vegaHome := newVegaHome(t)
+
+	// given
+	s, err := v1.InitialiseHistoryStore(zap.NewNop(), vegaHome, vgrand.RandomStr(5))
+	require.NoError(t, err)
+	tx := service.TransactionRecord{
+		TxID:       vgrand.RandomStr(5),
+		Wallet:     vgrand.RandomStr(5),
+		Status:     service.TransactionStatusSent,
+		ReceivedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	require.NoError(t, s.SaveTransaction(tx))
+
+	// setup
+	f, err := os.OpenFile(s.GetHistoryPath(), os.O_WRONLY|os.O_APPEND, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"txId": "torn", "wallet": "wal`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// when
+	txs, err := s.ListTransactions(service.TransactionFilter{})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []service.TransactionRecord{tx}, txs)
+}
@@ -0,0 +1,76 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	vgfs "code.vegaprotocol.io/shared/libs/fs"
+	"code.vegaprotocol.io/shared/paths"
+	"code.vegaprotocol.io/vegawallet/service"
+)
+
+// OriginsDataHome is the folder holding the approved origins, one file per
+// network.
+var OriginsDataHome = paths.JoinDataPath(paths.WalletServiceDataHome, "origins")
+
+// OriginsStore persists the origins approved to connect to the service on a
+// network.
+type OriginsStore struct {
+	originsFilePath string
+}
+
+func InitialiseOriginsStore(p paths.Paths, network string) (*OriginsStore, error) {
+	originsFilePath, err := p.CreateDataPathFor(paths.JoinDataPath(OriginsDataHome, network))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get data path for %s: %w", OriginsDataHome, err)
+	}
+
+	return &OriginsStore{
+		originsFilePath: originsFilePath,
+	}, nil
+}
+
+func (s *OriginsStore) OriginsExists() (bool, error) {
+	return vgfs.FileExists(s.originsFilePath)
+}
+
+func (s *OriginsStore) GetOriginsPath() string {
+	return s.originsFilePath
+}
+
+// GetApprovedOrigins returns the approved origins. If no origin has been
+// approved yet, an empty list is returned.
+func (s *OriginsStore) GetApprovedOrigins() ([]service.ApprovedOrigin, error) {
+	exists, err := s.OriginsExists()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't verify the origins file existence: %w", err)
+	}
+	if !exists {
+		return []service.ApprovedOrigin{}, nil
+	}
+
+	buf, err := vgfs.ReadFile(s.originsFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read origins file: %w", err)
+	}
+
+	origins := []service.ApprovedOrigin{}
+	if err := json.Unmarshal(buf, &origins); err != nil {
+		return nil, fmt.Errorf("couldn't unmarshal origins: %w", err)
+	}
+
+	return origins, nil
+}
+
+func (s *OriginsStore) SaveApprovedOrigins(origins []service.ApprovedOrigin) error {
+	buf, err := json.Marshal(origins)
+	if err != nil {
+		return fmt.Errorf("couldn't marshal origins: %w", err)
+	}
+
+	if err := vgfs.WriteFile(s.originsFilePath, buf); err != nil {
+		return fmt.Errorf("unable to save origins: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package v1_test
+
+import (
+	"testing"
+	"time"
+
+	vgrand "code.vegaprotocol.io/shared/libs/rand"
+	vgtest "code.vegaprotocol.io/shared/libs/test"
+	"code.vegaprotocol.io/vegawallet/service"
+	v1 "code.vegaprotocol.io/vegawallet/service/store/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOriginsStoreV1(t *testing.T) {
+	t.Run("Getting origins without file succeeds", testOriginsStoreV1GettingOriginsWithoutFileSucceeds)
+	t.Run("Saving origins succeeds", testOriginsStoreV1SavingOriginsSucceeds)
+}
+
+func testOriginsStoreV1GettingOriginsWithoutFileSucceeds(t *testing.T) {
+	vegaHome := newVegaHome(t)
+
+	// given
+	s, err := v1.InitialiseOriginsStore(vegaHome, vgrand.RandomStr(5))
+	require.NoError(t, err)
+
+	// when
+	origins, err := s.GetApprovedOrigins()
+
+	// then
+	require.NoError(t, err)
+	assert.Empty(t, origins)
+}
+
+func testOriginsStoreV1SavingOriginsSucceeds(t *testing.T) {
+	vegaHome := newVegaHome(t)
+
+	// given
+	s, err := v1.InitialiseOriginsStore(vegaHome, vgrand.RandomStr(5))
+	require.NoError(t, err)
+	origins := []service.ApprovedOrigin{
+		{
+			Origin:     "https://" + vgrand.RandomStr(5) + ".com",
+			ApprovedAt: time.Now().UTC().Truncate(time.Second),
+		},
+	}
+
+	// when
+	err = s.SaveApprovedOrigins(origins)
+
+	// then
+	require.NoError(t, err)
+	vgtest.AssertFileAccess(t, s.GetOriginsPath())
+
+	// when
+	returnedOrigins, err := s.GetApprovedOrigins()
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, origins, returnedOrigins)
+}
@@ -0,0 +1,95 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	vgcrypto "code.vegaprotocol.io/shared/libs/crypto"
+	vgfs "code.vegaprotocol.io/shared/libs/fs"
+	"code.vegaprotocol.io/shared/paths"
+	"code.vegaprotocol.io/vegawallet/service"
+)
+
+var ErrWrongSessionsPassphrase = errors.New("wrong passphrase for the sessions store")
+
+// SessionsDataHome is the folder holding the persisted sessions, one file per
+// network.
+var SessionsDataHome = paths.JoinDataPath(paths.WalletServiceDataHome, "sessions")
+
+// SessionsStore persists the sessions of a network in a file, encrypted with
+// a passphrase.
+type SessionsStore struct {
+	sessionsFilePath string
+	passphrase       string
+}
+
+func InitialiseSessionsStore(p paths.Paths, network, passphrase string) (*SessionsStore, error) {
+	sessionsFilePath, err := p.CreateDataPathFor(paths.JoinDataPath(SessionsDataHome, network))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get data path for %s: %w", SessionsDataHome, err)
+	}
+
+	return &SessionsStore{
+		sessionsFilePath: sessionsFilePath,
+		passphrase:       passphrase,
+	}, nil
+}
+
+func (s *SessionsStore) SessionsExists() (bool, error) {
+	return vgfs.FileExists(s.sessionsFilePath)
+}
+
+func (s *SessionsStore) GetSessionsPath() string {
+	return s.sessionsFilePath
+}
+
+// GetSessions returns the persisted sessions. If no session has been
+// persisted yet, an empty list is returned.
+func (s *SessionsStore) GetSessions() ([]service.Session, error) {
+	exists, err := s.SessionsExists()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't verify the sessions file existence: %w", err)
+	}
+	if !exists {
+		return []service.Session{}, nil
+	}
+
+	buf, err := vgfs.ReadFile(s.sessionsFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read sessions file: %w", err)
+	}
+
+	decBuf, err := vgcrypto.Decrypt(buf, s.passphrase)
+	if err != nil {
+		if err.Error() == "cipher: message authentication failed" {
+			return nil, ErrWrongSessionsPassphrase
+		}
+		return nil, fmt.Errorf("couldn't decrypt sessions file: %w", err)
+	}
+
+	sessions := []service.Session{}
+	if err := json.Unmarshal(decBuf, &sessions); err != nil {
+		return nil, fmt.Errorf("couldn't unmarshal sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+func (s *SessionsStore) SaveSessions(sessions []service.Session) error {
+	buf, err := json.Marshal(sessions)
+	if err != nil {
+		return fmt.Errorf("couldn't marshal sessions: %w", err)
+	}
+
+	encBuf, err := vgcrypto.Encrypt(buf, s.passphrase)
+	if err != nil {
+		return fmt.Errorf("couldn't encrypt sessions: %w", err)
+	}
+
+	if err := vgfs.WriteFile(s.sessionsFilePath, encBuf); err != nil {
+		return fmt.Errorf("unable to save sessions: %w", err)
+	}
+
+	return nil
+}
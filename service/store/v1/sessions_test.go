@@ -0,0 +1,85 @@
+package v1_test
+
+import (
+	"testing"
+	"time"
+
+	vgrand "code.vegaprotocol.io/shared/libs/rand"
+	vgtest "code.vegaprotocol.io/shared/libs/test"
+	"code.vegaprotocol.io/vegawallet/service"
+	v1 "code.vegaprotocol.io/vegawallet/service/store/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionsStoreV1(t *testing.T) {
+	t.Run("Getting sessions without file succeeds", testSessionsStoreV1GettingSessionsWithoutFileSucceeds)
+	t.Run("Saving sessions succeeds", testSessionsStoreV1SavingSessionsSucceeds)
+	t.Run("Getting sessions with wrong passphrase fails", testSessionsStoreV1GettingSessionsWithWrongPassphraseFails)
+}
+
+func testSessionsStoreV1GettingSessionsWithoutFileSucceeds(t *testing.T) {
+	vegaHome := newVegaHome(t)
+
+	// given
+	s, err := v1.InitialiseSessionsStore(vegaHome, vgrand.RandomStr(5), vgrand.RandomStr(10))
+	require.NoError(t, err)
+
+	// when
+	sessions, err := s.GetSessions()
+
+	// then
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+}
+
+func testSessionsStoreV1SavingSessionsSucceeds(t *testing.T) {
+	vegaHome := newVegaHome(t)
+
+	// given
+	s, err := v1.InitialiseSessionsStore(vegaHome, vgrand.RandomStr(5), vgrand.RandomStr(10))
+	require.NoError(t, err)
+	now := time.Now().UTC().Truncate(time.Second)
+	sessions := []service.Session{
+		{
+			ID:         vgrand.RandomStr(10),
+			Wallet:     vgrand.RandomStr(5),
+			CreatedAt:  now,
+			ExpiresAt:  now.Add(time.Hour),
+			Passphrase: vgrand.RandomStr(10),
+		},
+	}
+
+	// when
+	err = s.SaveSessions(sessions)
+
+	// then
+	require.NoError(t, err)
+	vgtest.AssertFileAccess(t, s.GetSessionsPath())
+
+	// when
+	returnedSessions, err := s.GetSessions()
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, sessions, returnedSessions)
+}
+
+func testSessionsStoreV1GettingSessionsWithWrongPassphraseFails(t *testing.T) {
+	vegaHome := newVegaHome(t)
+
+	// given
+	network := vgrand.RandomStr(5)
+	s, err := v1.InitialiseSessionsStore(vegaHome, network, vgrand.RandomStr(10))
+	require.NoError(t, err)
+	require.NoError(t, s.SaveSessions([]service.Session{}))
+	otherStore, err := v1.InitialiseSessionsStore(vegaHome, network, vgrand.RandomStr(10))
+	require.NoError(t, err)
+
+	// when
+	sessions, err := otherStore.GetSessions()
+
+	// then
+	assert.ErrorIs(t, err, v1.ErrWrongSessionsPassphrase)
+	assert.Nil(t, sessions)
+}
@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"encoding/json"
 	"fmt"
 
 	vgfs "code.vegaprotocol.io/shared/libs/fs"
@@ -8,9 +9,14 @@ import (
 	"code.vegaprotocol.io/vegawallet/service"
 )
 
+// PreviousSigningKeysDataFile is the file holding the public keys that signed
+// the tokens before the last rotations.
+var PreviousSigningKeysDataFile = paths.JoinDataPath(paths.WalletServiceRSAKeysDataHome, "previous-keys.json")
+
 type Store struct {
-	pubRsaKeyFilePath  string
-	privRsaKeyFilePath string
+	pubRsaKeyFilePath    string
+	privRsaKeyFilePath   string
+	previousKeysFilePath string
 }
 
 func InitialiseStore(p paths.Paths) (*Store, error) {
@@ -24,9 +30,15 @@ func InitialiseStore(p paths.Paths) (*Store, error) {
 		return nil, fmt.Errorf("couldn't get data path for %s: %w", paths.WalletServicePrivateRSAKeyDataFile, err)
 	}
 
+	previousKeysFilePath, err := p.CreateDataPathFor(PreviousSigningKeysDataFile)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get data path for %s: %w", PreviousSigningKeysDataFile, err)
+	}
+
 	return &Store{
-		pubRsaKeyFilePath:  pubRsaKeyFilePath,
-		privRsaKeyFilePath: privRsaKeyFilePath,
+		pubRsaKeyFilePath:    pubRsaKeyFilePath,
+		privRsaKeyFilePath:   privRsaKeyFilePath,
+		previousKeysFilePath: previousKeysFilePath,
 	}, nil
 }
 
@@ -74,3 +86,41 @@ func (s *Store) GetRsaKeys() (*service.RSAKeys, error) {
 		Priv: priv,
 	}, nil
 }
+
+// GetPreviousSigningKeys returns the public keys that signed the tokens before
+// the last rotations. If the keys have never been rotated, an empty list is
+// returned.
+func (s *Store) GetPreviousSigningKeys() ([]service.PreviousSigningKey, error) {
+	exists, err := vgfs.FileExists(s.previousKeysFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't verify the previous keys file existence: %w", err)
+	}
+	if !exists {
+		return []service.PreviousSigningKey{}, nil
+	}
+
+	buf, err := vgfs.ReadFile(s.previousKeysFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read previous keys file: %w", err)
+	}
+
+	keys := []service.PreviousSigningKey{}
+	if err := json.Unmarshal(buf, &keys); err != nil {
+		return nil, fmt.Errorf("couldn't unmarshal previous keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+func (s *Store) SavePreviousSigningKeys(keys []service.PreviousSigningKey) error {
+	buf, err := json.Marshal(keys)
+	if err != nil {
+		return fmt.Errorf("couldn't marshal previous keys: %w", err)
+	}
+
+	if err := vgfs.WriteFile(s.previousKeysFilePath, buf); err != nil {
+		return fmt.Errorf("unable to save previous keys: %w", err)
+	}
+
+	return nil
+}
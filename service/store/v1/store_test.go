@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	vgrand "code.vegaprotocol.io/shared/libs/rand"
 	vgtest "code.vegaprotocol.io/shared/libs/test"
@@ -22,6 +23,8 @@ func TestFileStoreV1(t *testing.T) {
 	t.Run("Verifying existing RSA keys succeeds", testFileStoreV1VerifyingExistingRSAKeysSucceeds)
 	t.Run("Getting non-existing RSA keys fails", testFileStoreV1GetNonExistingRSAKeysFails)
 	t.Run("Getting existing RSA keys succeeds", testFileStoreV1GetExistingRSAKeysSucceeds)
+	t.Run("Getting non-existing previous signing keys succeeds", testFileStoreV1GetNonExistingPreviousSigningKeysSucceeds)
+	t.Run("Saving previous signing keys succeeds", testFileStoreV1SavePreviousSigningKeysSucceeds)
 }
 
 func testNewStoreSucceeds(t *testing.T) {
@@ -163,6 +166,51 @@ func testFileStoreV1GetExistingRSAKeysSucceeds(t *testing.T) {
 	assert.Equal(t, keys, returnedKeys)
 }
 
+func testFileStoreV1GetNonExistingPreviousSigningKeysSucceeds(t *testing.T) {
+	vegaHome := newVegaHome(t)
+
+	// given
+	s := initialiseFromPath(t, vegaHome)
+
+	// when
+	keys, err := s.GetPreviousSigningKeys()
+
+	// then
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func testFileStoreV1SavePreviousSigningKeysSucceeds(t *testing.T) {
+	vegaHome := newVegaHome(t)
+
+	// given
+	s := initialiseFromPath(t, vegaHome)
+	now := time.Now().UTC().Truncate(time.Second)
+	keys := []service.PreviousSigningKey{
+		{
+			ID:        vgrand.RandomStr(16),
+			Algorithm: service.SigningAlgorithmPS256,
+			Pub:       "my public key",
+			RetiredAt: now,
+			ExpiresAt: now.Add(time.Hour),
+		},
+	}
+
+	// when
+	err := s.SavePreviousSigningKeys(keys)
+
+	// then
+	require.NoError(t, err)
+	vgtest.AssertFileAccess(t, vegaHome.DataPathFor(v1.PreviousSigningKeysDataFile))
+
+	// when
+	returnedKeys, err := s.GetPreviousSigningKeys()
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, keys, returnedKeys)
+}
+
 func initialiseFromPath(t *testing.T, vegaHome *paths.CustomPaths) *v1.Store {
 	t.Helper()
 	s, err := v1.InitialiseStore(vegaHome)
@@ -0,0 +1,141 @@
+package v1
+
+import (
+	"fmt"
+
+	vgfs "code.vegaprotocol.io/shared/libs/fs"
+	"code.vegaprotocol.io/shared/paths"
+	"code.vegaprotocol.io/vegawallet/service"
+)
+
+var (
+	// TLSDataHome is the folder holding the local certificate authority, and
+	// the server certificates it issued, one per network.
+	TLSDataHome = paths.JoinDataPath(paths.WalletServiceDataHome, "tls")
+
+	caCertDataFile = paths.JoinDataPath(TLSDataHome, "ca.pem")
+	caKeyDataFile  = paths.JoinDataPath(TLSDataHome, "ca-key.pem")
+)
+
+type TLSStore struct {
+	vegaPaths      paths.Paths
+	caCertFilePath string
+	caKeyFilePath  string
+}
+
+func InitialiseTLSStore(p paths.Paths) (*TLSStore, error) {
+	caCertFilePath, err := p.CreateDataPathFor(caCertDataFile)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get data path for %s: %w", caCertDataFile, err)
+	}
+
+	caKeyFilePath, err := p.CreateDataPathFor(caKeyDataFile)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get data path for %s: %w", caKeyDataFile, err)
+	}
+
+	return &TLSStore{
+		vegaPaths:      p,
+		caCertFilePath: caCertFilePath,
+		caKeyFilePath:  caKeyFilePath,
+	}, nil
+}
+
+func (s *TLSStore) CertificateAuthorityExists() (bool, error) {
+	return certificateExists(s.caCertFilePath, s.caKeyFilePath)
+}
+
+// GetCertificateAuthorityPath returns the path of the certificate the
+// clients have to trust.
+func (s *TLSStore) GetCertificateAuthorityPath() string {
+	return s.caCertFilePath
+}
+
+func (s *TLSStore) GetCertificateAuthority() (*service.Certificate, error) {
+	return readCertificate(s.caCertFilePath, s.caKeyFilePath)
+}
+
+func (s *TLSStore) SaveCertificateAuthority(cert *service.Certificate) error {
+	return writeCertificate(s.caCertFilePath, s.caKeyFilePath, cert)
+}
+
+// GetServerCertificatePaths returns the paths of the certificate and the key
+// of the network.
+func (s *TLSStore) GetServerCertificatePaths(network string) (string, string, error) {
+	certFilePath, err := s.vegaPaths.CreateDataPathFor(paths.JoinDataPath(TLSDataHome, network+".pem"))
+	if err != nil {
+		return "", "", fmt.Errorf("couldn't get data path for %s: %w", TLSDataHome, err)
+	}
+
+	keyFilePath, err := s.vegaPaths.CreateDataPathFor(paths.JoinDataPath(TLSDataHome, network+"-key.pem"))
+	if err != nil {
+		return "", "", fmt.Errorf("couldn't get data path for %s: %w", TLSDataHome, err)
+	}
+
+	return certFilePath, keyFilePath, nil
+}
+
+func (s *TLSStore) ServerCertificateExists(network string) (bool, error) {
+	certFilePath, keyFilePath, err := s.GetServerCertificatePaths(network)
+	if err != nil {
+		return false, err
+	}
+	return certificateExists(certFilePath, keyFilePath)
+}
+
+func (s *TLSStore) GetServerCertificate(network string) (*service.Certificate, error) {
+	certFilePath, keyFilePath, err := s.GetServerCertificatePaths(network)
+	if err != nil {
+		return nil, err
+	}
+	return readCertificate(certFilePath, keyFilePath)
+}
+
+func (s *TLSStore) SaveServerCertificate(network string, cert *service.Certificate) error {
+	certFilePath, keyFilePath, err := s.GetServerCertificatePaths(network)
+	if err != nil {
+		return err
+	}
+	return writeCertificate(certFilePath, keyFilePath, cert)
+}
+
+func certificateExists(certFilePath, keyFilePath string) (bool, error) {
+	certExists, err := vgfs.FileExists(certFilePath)
+	if err != nil {
+		return false, err
+	}
+	keyExists, err := vgfs.FileExists(keyFilePath)
+	if err != nil {
+		return false, err
+	}
+	return certExists && keyExists, nil
+}
+
+func readCertificate(certFilePath, keyFilePath string) (*service.Certificate, error) {
+	cert, err := vgfs.ReadFile(certFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := vgfs.ReadFile(keyFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &service.Certificate{
+		Cert: cert,
+		Key:  key,
+	}, nil
+}
+
+func writeCertificate(certFilePath, keyFilePath string, cert *service.Certificate) error {
+	if err := vgfs.WriteFile(keyFilePath, cert.Key); err != nil {
+		return fmt.Errorf("unable to save certificate key: %w", err)
+	}
+
+	if err := vgfs.WriteFile(certFilePath, cert.Cert); err != nil {
+		return fmt.Errorf("unable to save certificate: %w", err)
+	}
+
+	return nil
+}
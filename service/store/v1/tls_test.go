@@ -0,0 +1,108 @@
+package v1_test
+
+import (
+	"testing"
+
+	vgtest "code.vegaprotocol.io/shared/libs/test"
+	"code.vegaprotocol.io/vegawallet/service"
+	v1 "code.vegaprotocol.io/vegawallet/service/store/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLSStoreV1(t *testing.T) {
+	t.Run("Verifying non-existing certificate authority fails", testTLSStoreV1VerifyingNonExistingCertificateAuthorityFails)
+	t.Run("Saving certificate authority succeeds", testTLSStoreV1SavingCertificateAuthoritySucceeds)
+	t.Run("Saving server certificate succeeds", testTLSStoreV1SavingServerCertificateSucceeds)
+}
+
+func testTLSStoreV1VerifyingNonExistingCertificateAuthorityFails(t *testing.T) {
+	vegaHome := newVegaHome(t)
+
+	// given
+	s, err := v1.InitialiseTLSStore(vegaHome)
+	require.NoError(t, err)
+
+	// when
+	exists, err := s.CertificateAuthorityExists()
+
+	// then
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func testTLSStoreV1SavingCertificateAuthoritySucceeds(t *testing.T) {
+	vegaHome := newVegaHome(t)
+
+	// given
+	s, err := v1.InitialiseTLSStore(vegaHome)
+	require.NoError(t, err)
+	ca := &service.Certificate{
+		Cert: []byte("my certificate"),
+		Key:  []byte("my key"),
+	}
+
+	// when
+	err = s.SaveCertificateAuthority(ca)
+
+	// then
+	require.NoError(t, err)
+	vgtest.AssertFileAccess(t, s.GetCertificateAuthorityPath())
+
+	// when
+	exists, err := s.CertificateAuthorityExists()
+
+	// then
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	// when
+	returnedCA, err := s.GetCertificateAuthority()
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, ca, returnedCA)
+}
+
+func testTLSStoreV1SavingServerCertificateSucceeds(t *testing.T) {
+	vegaHome := newVegaHome(t)
+
+	// given
+	s, err := v1.InitialiseTLSStore(vegaHome)
+	require.NoError(t, err)
+	cert := &service.Certificate{
+		Cert: []byte("my certificate"),
+		Key:  []byte("my key"),
+	}
+
+	// when
+	exists, err := s.ServerCertificateExists("fairground")
+
+	// then
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// when
+	err = s.SaveServerCertificate("fairground", cert)
+
+	// then
+	require.NoError(t, err)
+	certFilePath, keyFilePath, err := s.GetServerCertificatePaths("fairground")
+	require.NoError(t, err)
+	vgtest.AssertFileAccess(t, certFilePath)
+	vgtest.AssertFileAccess(t, keyFilePath)
+
+	// when
+	returnedCert, err := s.GetServerCertificate("fairground")
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, cert, returnedCert)
+
+	// when
+	exists, err = s.ServerCertificateExists("mainnet")
+
+	// then
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
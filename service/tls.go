@@ -0,0 +1,372 @@
+package service
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	certificateAuthorityValidity = 10 * 365 * 24 * time.Hour
+	// serverCertificateValidity stays under the 398 days accepted by the
+	// browsers.
+	serverCertificateValidity = 397 * 24 * time.Hour
+	// serverCertificateRenewal is the remaining validity under which the
+	// server certificate is issued again.
+	serverCertificateRenewal = 30 * 24 * time.Hour
+)
+
+// localDNSDomains are the domains the local certificate authority can issue
+// certificates for, along with their subdomains. They can't be registered
+// publicly, so the certificate authority can't be abused to impersonate
+// other websites.
+var localDNSDomains = []string{
+	"localhost",
+	"local",
+	"internal",
+	"lan",
+	"home.arpa",
+}
+
+// localIPRanges are the loopback, private and link-local address ranges the
+// local certificate authority can issue certificates for.
+var localIPRanges = []string{
+	"127.0.0.0/8",
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"169.254.0.0/16",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+}
+
+// Certificate holds a PEM-encoded certificate and its private key.
+type Certificate struct {
+	Cert []byte
+	Key  []byte
+}
+
+// TLSStore persists the local certificate authority, and the server
+// certificates it issued, one per network.
+type TLSStore interface {
+	CertificateAuthorityExists() (bool, error)
+	GetCertificateAuthority() (*Certificate, error)
+	SaveCertificateAuthority(*Certificate) error
+	ServerCertificateExists(network string) (bool, error)
+	GetServerCertificate(network string) (*Certificate, error)
+	SaveServerCertificate(network string, cert *Certificate) error
+}
+
+// InitialiseCertificateAuthority generates the local certificate authority
+// issuing the server certificates. It has to be trusted by the clients.
+func InitialiseCertificateAuthority(store TLSStore, overwrite bool) error {
+	if !overwrite {
+		exists, err := store.CertificateAuthorityExists()
+		if err != nil {
+			return fmt.Errorf("couldn't verify certificate authority existence: %w", err)
+		}
+		if exists {
+			return nil
+		}
+	}
+
+	ca, err := GenerateCertificateAuthority()
+	if err != nil {
+		return err
+	}
+
+	if err := store.SaveCertificateAuthority(ca); err != nil {
+		return fmt.Errorf("couldn't save the certificate authority: %w", err)
+	}
+
+	return nil
+}
+
+// EnsureServerCertificate verifies the server certificate of the network is
+// valid for all the hosts, and issued by the current certificate authority. A
+// new one is issued otherwise. The certificate authority is generated if it
+// doesn't exist yet.
+func EnsureServerCertificate(store TLSStore, network string, hosts ...string) (*Certificate, error) {
+	if err := InitialiseCertificateAuthority(store, false); err != nil {
+		return nil, err
+	}
+
+	ca, err := store.GetCertificateAuthority()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get the certificate authority: %w", err)
+	}
+
+	exists, err := store.ServerCertificateExists(network)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't verify server certificate existence: %w", err)
+	}
+	if exists {
+		cert, err := store.GetServerCertificate(network)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't get the server certificate: %w", err)
+		}
+		// A certificate authority created again, with "init --force", doesn't
+		// trust the certificates issued by the previous one.
+		if isCertificateValidForHosts(cert.Cert, hosts, time.Now().Add(serverCertificateRenewal)) && isCertificateIssuedBy(cert.Cert, ca.Cert) {
+			return cert, nil
+		}
+	}
+
+	cert, err := GenerateServerCertificate(ca, hosts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := store.SaveServerCertificate(network, cert); err != nil {
+		return nil, fmt.Errorf("couldn't save the server certificate: %w", err)
+	}
+
+	return cert, nil
+}
+
+// GenerateCertificateAuthority generates a certificate authority constrained
+// to the local names and addresses, so its key can't be used to impersonate
+// other websites if it leaks.
+func GenerateCertificateAuthority() (*Certificate, error) {
+	ipRanges, err := parseIPRanges(localIPRanges)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't generate certificate authority key: %w", err)
+	}
+
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			Organization: []string{"Vega Wallet"},
+			CommonName:   "Vega Wallet local certificate authority",
+		},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(certificateAuthorityValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+		// The constraints are critical, so the clients that don't support
+		// them reject the certificates instead of ignoring them.
+		PermittedDNSDomainsCritical: true,
+		PermittedDNSDomains:         localDNSDomains,
+		PermittedIPRanges:           ipRanges,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create certificate authority: %w", err)
+	}
+
+	return encodeCertificate(der, key)
+}
+
+// GenerateServerCertificate issues a certificate for the hosts, signed by the
+// certificate authority. It's also valid for the loopback addresses. The hosts
+// have to be local names or addresses, as the certificate authority is
+// constrained to them.
+func GenerateServerCertificate(ca *Certificate, hosts ...string) (*Certificate, error) {
+	if len(hosts) == 0 {
+		return nil, ErrNoHostSpecified
+	}
+	for _, host := range hosts {
+		if !IsLocalHost(host) {
+			return nil, fmt.Errorf("%w: %s", ErrHostIsNotLocal, host)
+		}
+	}
+
+	caCert, err := tls.X509KeyPair(ca.Cert, ca.Key)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse certificate authority: %w", err)
+	}
+	caX509, err := x509.ParseCertificate(caCert.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse certificate authority: %w", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't generate server certificate key: %w", err)
+	}
+
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			Organization: []string{"Vega Wallet"},
+			CommonName:   hosts[0],
+		},
+		NotBefore:   now.Add(-time.Hour),
+		NotAfter:    now.Add(serverCertificateValidity),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:    []string{"localhost"},
+		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			if !ip.IsLoopback() {
+				template.IPAddresses = append(template.IPAddresses, ip)
+			}
+		} else if host != "localhost" {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, caX509, &key.PublicKey, caCert.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create server certificate: %w", err)
+	}
+
+	return encodeCertificate(der, key)
+}
+
+// IsCertificateValidForHost verifies the certificate covers the host, and is
+// still valid at the specified time.
+func IsCertificateValidForHost(certPEM []byte, host string, at time.Time) bool {
+	cert, err := parseCertificate(certPEM)
+	if err != nil {
+		return false
+	}
+	if at.After(cert.NotAfter) {
+		return false
+	}
+	return cert.VerifyHostname(host) == nil
+}
+
+// isCertificateValidForHosts verifies the certificate covers all the hosts,
+// and is still valid at the specified time.
+func isCertificateValidForHosts(certPEM []byte, hosts []string, at time.Time) bool {
+	for _, host := range hosts {
+		if !IsCertificateValidForHost(certPEM, host, at) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsLocalHost verifies the host is a name or an address the local certificate
+// authority can issue certificates for.
+func IsLocalHost(host string) bool {
+	if ip := net.ParseIP(host); ip != nil {
+		ipRanges, err := parseIPRanges(localIPRanges)
+		if err != nil {
+			return false
+		}
+		for _, ipRange := range ipRanges {
+			if ipRange.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	name := strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, domain := range localDNSDomains {
+		if name == domain || strings.HasSuffix(name, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// CertificateFingerprint returns the SHA-256 fingerprint of the certificate,
+// as displayed by the browsers, so the clients can verify they connect to
+// the expected service.
+func CertificateFingerprint(certPEM []byte) (string, error) {
+	cert, err := parseCertificate(certPEM)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(cert.Raw)
+	parts := make([]string, 0, len(sum))
+	for _, b := range sum {
+		parts = append(parts, fmt.Sprintf("%02X", b))
+	}
+	return strings.Join(parts, ":"), nil
+}
+
+// isCertificateIssuedBy verifies the certificate is signed by the
+// certificate authority.
+func isCertificateIssuedBy(certPEM, caPEM []byte) bool {
+	cert, err := parseCertificate(certPEM)
+	if err != nil {
+		return false
+	}
+	ca, err := parseCertificate(caPEM)
+	if err != nil {
+		return false
+	}
+	return cert.CheckSignatureFrom(ca) == nil
+}
+
+func parseIPRanges(cidrs []string) ([]*net.IPNet, error) {
+	ipRanges := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, ipRange, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse IP range %s: %w", cidr, err)
+		}
+		ipRanges = append(ipRanges, ipRange)
+	}
+	return ipRanges, nil
+}
+
+func parseCertificate(certPEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, ErrCertificateIsNotPEMEncoded
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse certificate: %w", err)
+	}
+	return cert, nil
+}
+
+func encodeCertificate(der []byte, key *ecdsa.PrivateKey) (*Certificate, error) {
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't marshal certificate key: %w", err)
+	}
+
+	return &Certificate{
+		Cert: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		Key:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}),
+	}, nil
+}
+
+func newSerialNumber() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't generate certificate serial number: %w", err)
+	}
+	return serial, nil
+}
@@ -0,0 +1,252 @@
+package service_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"code.vegaprotocol.io/vegawallet/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLS(t *testing.T) {
+	t.Run("Server certificate is issued by the certificate authority", testServerCertificateIsIssuedByCertificateAuthority)
+	t.Run("Server certificate covers the host and the loopback addresses", testServerCertificateCoversHostAndLoopbackAddresses)
+	t.Run("Certificate fingerprint is formatted as SHA-256", testCertificateFingerprintIsFormattedAsSHA256)
+	t.Run("Ensuring server certificate issues it once", testEnsuringServerCertificateIssuesItOnce)
+	t.Run("Ensuring server certificate issues a new one when the host changes", testEnsuringServerCertificateIssuesNewOneWhenHostChanges)
+	t.Run("Ensuring server certificate covers all the hosts", testEnsuringServerCertificateCoversAllHosts)
+	t.Run("Ensuring server certificate issues a new one when the certificate authority changes", testEnsuringServerCertificateIssuesNewOneWhenCertificateAuthorityChanges)
+	t.Run("Initialising certificate authority keeps the existing one", testInitialisingCertificateAuthorityKeepsExistingOne)
+	t.Run("Certificate authority is constrained to local hosts", testCertificateAuthorityIsConstrainedToLocalHosts)
+	t.Run("Issuing server certificate for a public host fails", testIssuingServerCertificateForPublicHostFails)
+}
+
+func testServerCertificateIsIssuedByCertificateAuthority(t *testing.T) {
+	// given
+	ca, err := service.GenerateCertificateAuthority()
+	require.NoError(t, err)
+
+	// when
+	cert, err := service.GenerateServerCertificate(ca, "wallet.lan")
+
+	// then
+	require.NoError(t, err)
+	_, err = tls.X509KeyPair(cert.Cert, cert.Key)
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(ca.Cert))
+	_, err = parseTestCertificate(t, cert.Cert).Verify(x509.VerifyOptions{
+		DNSName: "wallet.lan",
+		Roots:   roots,
+	})
+	require.NoError(t, err)
+}
+
+func testServerCertificateCoversHostAndLoopbackAddresses(t *testing.T) {
+	// given
+	ca, err := service.GenerateCertificateAuthority()
+	require.NoError(t, err)
+	now := time.Now()
+
+	// when
+	cert, err := service.GenerateServerCertificate(ca, "192.168.1.10")
+
+	// then
+	require.NoError(t, err)
+	assert.True(t, service.IsCertificateValidForHost(cert.Cert, "192.168.1.10", now))
+	assert.True(t, service.IsCertificateValidForHost(cert.Cert, "127.0.0.1", now))
+	assert.True(t, service.IsCertificateValidForHost(cert.Cert, "localhost", now))
+	assert.False(t, service.IsCertificateValidForHost(cert.Cert, "192.168.1.11", now))
+	assert.False(t, service.IsCertificateValidForHost(cert.Cert, "192.168.1.10", now.Add(2*365*24*time.Hour)))
+}
+
+func testCertificateFingerprintIsFormattedAsSHA256(t *testing.T) {
+	// given
+	ca, err := service.GenerateCertificateAuthority()
+	require.NoError(t, err)
+
+	// when
+	fingerprint, err := service.CertificateFingerprint(ca.Cert)
+
+	// then
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(fingerprint, ":"), 32)
+	assert.Equal(t, strings.ToUpper(fingerprint), fingerprint)
+}
+
+func testEnsuringServerCertificateIssuesItOnce(t *testing.T) {
+	// given
+	store := newMemoryTLSStore()
+
+	// when
+	cert, err := service.EnsureServerCertificate(store, "fairground", "127.0.0.1")
+
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, store.ca)
+	assert.Equal(t, cert, store.certs["fairground"])
+
+	// when
+	sameCert, err := service.EnsureServerCertificate(store, "fairground", "127.0.0.1")
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, cert, sameCert)
+}
+
+func testEnsuringServerCertificateIssuesNewOneWhenHostChanges(t *testing.T) {
+	// given
+	store := newMemoryTLSStore()
+	cert, err := service.EnsureServerCertificate(store, "fairground", "127.0.0.1")
+	require.NoError(t, err)
+
+	// when
+	newCert, err := service.EnsureServerCertificate(store, "fairground", "wallet.lan")
+
+	// then
+	require.NoError(t, err)
+	assert.NotEqual(t, cert, newCert)
+	assert.True(t, service.IsCertificateValidForHost(newCert.Cert, "wallet.lan", time.Now()))
+}
+
+func testEnsuringServerCertificateCoversAllHosts(t *testing.T) {
+	// given
+	store := newMemoryTLSStore()
+	cert, err := service.EnsureServerCertificate(store, "fairground", "wallet.lan")
+	require.NoError(t, err)
+
+	// when
+	newCert, err := service.EnsureServerCertificate(store, "fairground", "wallet.lan", "192.168.1.10")
+
+	// then
+	require.NoError(t, err)
+	assert.NotEqual(t, cert, newCert)
+	assert.True(t, service.IsCertificateValidForHost(newCert.Cert, "wallet.lan", time.Now()))
+	assert.True(t, service.IsCertificateValidForHost(newCert.Cert, "192.168.1.10", time.Now()))
+}
+
+func testEnsuringServerCertificateIssuesNewOneWhenCertificateAuthorityChanges(t *testing.T) {
+	// given
+	store := newMemoryTLSStore()
+	cert, err := service.EnsureServerCertificate(store, "fairground", "127.0.0.1")
+	require.NoError(t, err)
+	require.NoError(t, service.InitialiseCertificateAuthority(store, true))
+
+	// when
+	newCert, err := service.EnsureServerCertificate(store, "fairground", "127.0.0.1")
+
+	// then
+	require.NoError(t, err)
+	assert.NotEqual(t, cert, newCert)
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(store.ca.Cert))
+	_, err = parseTestCertificate(t, newCert.Cert).Verify(x509.VerifyOptions{
+		DNSName: "localhost",
+		Roots:   roots,
+	})
+	require.NoError(t, err)
+}
+
+func testInitialisingCertificateAuthorityKeepsExistingOne(t *testing.T) {
+	// given
+	store := newMemoryTLSStore()
+	require.NoError(t, service.InitialiseCertificateAuthority(store, false))
+	ca := store.ca
+
+	// when
+	err := service.InitialiseCertificateAuthority(store, false)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, ca, store.ca)
+
+	// when
+	err = service.InitialiseCertificateAuthority(store, true)
+
+	// then
+	require.NoError(t, err)
+	assert.NotEqual(t, ca, store.ca)
+}
+
+func testCertificateAuthorityIsConstrainedToLocalHosts(t *testing.T) {
+	// when
+	ca, err := service.GenerateCertificateAuthority()
+
+	// then
+	require.NoError(t, err)
+	caCert := parseTestCertificate(t, ca.Cert)
+	assert.True(t, caCert.PermittedDNSDomainsCritical)
+	assert.Contains(t, caCert.PermittedDNSDomains, "localhost")
+	assert.NotEmpty(t, caCert.PermittedIPRanges)
+	assert.True(t, service.IsLocalHost("wallet.lan"))
+	assert.True(t, service.IsLocalHost("192.168.1.10"))
+	assert.True(t, service.IsLocalHost("::1"))
+	assert.False(t, service.IsLocalHost("wallet.example.com"))
+	assert.False(t, service.IsLocalHost("8.8.8.8"))
+}
+
+func testIssuingServerCertificateForPublicHostFails(t *testing.T) {
+	// given
+	ca, err := service.GenerateCertificateAuthority()
+	require.NoError(t, err)
+
+	// when
+	cert, err := service.GenerateServerCertificate(ca, "wallet.example.com")
+
+	// then
+	require.ErrorIs(t, err, service.ErrHostIsNotLocal)
+	assert.Nil(t, cert)
+}
+
+type memoryTLSStore struct {
+	ca    *service.Certificate
+	certs map[string]*service.Certificate
+}
+
+func newMemoryTLSStore() *memoryTLSStore {
+	return &memoryTLSStore{
+		certs: map[string]*service.Certificate{},
+	}
+}
+
+func (s *memoryTLSStore) CertificateAuthorityExists() (bool, error) {
+	return s.ca != nil, nil
+}
+
+func (s *memoryTLSStore) GetCertificateAuthority() (*service.Certificate, error) {
+	return s.ca, nil
+}
+
+func (s *memoryTLSStore) SaveCertificateAuthority(ca *service.Certificate) error {
+	s.ca = ca
+	return nil
+}
+
+func (s *memoryTLSStore) ServerCertificateExists(network string) (bool, error) {
+	_, ok := s.certs[network]
+	return ok, nil
+}
+
+func (s *memoryTLSStore) GetServerCertificate(network string) (*service.Certificate, error) {
+	return s.certs[network], nil
+}
+
+func (s *memoryTLSStore) SaveServerCertificate(network string, cert *service.Certificate) error {
+	s.certs[network] = cert
+	return nil
+}
+
+func parseTestCertificate(t *testing.T, certPEM []byte) *x509.Certificate {
+	t.Helper()
+	block, _ := pem.Decode(certPEM)
+	require.NotNil(t, block)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	return cert
+}
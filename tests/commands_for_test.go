@@ -60,6 +60,98 @@ func Init(t *testing.T, args []string) (*InitResponse, error) {
 	return resp, nil
 }
 
+type DoctorResponse struct {
+	Healthy bool `json:"healthy"`
+	Wallets struct {
+		UnknownFiles []string `json:"unknownFiles"`
+		Wallets      []struct {
+			Wallet  string   `json:"wallet"`
+			Healthy bool     `json:"healthy"`
+			Issues  []string `json:"issues"`
+		} `json:"wallets"`
+	} `json:"wallets"`
+	Networks struct {
+		Networks []struct {
+			Name    string   `json:"name"`
+			Healthy bool     `json:"healthy"`
+			Issues  []string `json:"issues"`
+		} `json:"networks"`
+	} `json:"networks"`
+	Service struct {
+		RSAKeys struct {
+			Healthy bool     `json:"healthy"`
+			Issues  []string `json:"issues"`
+		} `json:"rsaKeys"`
+	} `json:"service"`
+}
+
+// Doctor returns the response even when the command fails, as the doctor
+// fails when it finds issues.
+func Doctor(t *testing.T, args []string) (*DoctorResponse, error) {
+	t.Helper()
+	argsWithCmd := []string{"doctor"}
+	argsWithCmd = append(argsWithCmd, args...)
+	output, execErr := ExecuteCmd(t, argsWithCmd)
+	if len(output) == 0 {
+		return nil, execErr
+	}
+	resp := &DoctorResponse{}
+	if err := json.Unmarshal(output, resp); err != nil {
+		t.Fatalf("couldn't unmarshal command output: %v", err)
+	}
+	return resp, execErr
+}
+
+type CreateBackupResponse struct {
+	FilePath           string   `json:"filePath"`
+	Wallets            []string `json:"wallets"`
+	Networks           []string `json:"networks"`
+	IncludeServiceKeys bool     `json:"includeServiceKeys"`
+}
+
+func BackupCreate(t *testing.T, args []string) (*CreateBackupResponse, error) {
+	t.Helper()
+	argsWithCmd := []string{"backup", "create"}
+	argsWithCmd = append(argsWithCmd, args...)
+	output, err := ExecuteCmd(t, argsWithCmd)
+	if err != nil {
+		return nil, err
+	}
+	resp := &CreateBackupResponse{}
+	if err := json.Unmarshal(output, resp); err != nil {
+		t.Fatalf("couldn't unmarshal command output: %v", err)
+	}
+	return resp, nil
+}
+
+type RestoredEntry struct {
+	Name       string `json:"name"`
+	RestoredAs string `json:"restoredAs"`
+	Action     string `json:"action"`
+}
+
+type RestoreBackupResponse struct {
+	DryRun      bool            `json:"dryRun"`
+	Wallets     []RestoredEntry `json:"wallets"`
+	Networks    []RestoredEntry `json:"networks"`
+	ServiceKeys *RestoredEntry  `json:"serviceKeys"`
+}
+
+func BackupRestore(t *testing.T, args []string) (*RestoreBackupResponse, error) {
+	t.Helper()
+	argsWithCmd := []string{"backup", "restore"}
+	argsWithCmd = append(argsWithCmd, args...)
+	output, err := ExecuteCmd(t, argsWithCmd)
+	if err != nil {
+		return nil, err
+	}
+	resp := &RestoreBackupResponse{}
+	if err := json.Unmarshal(output, resp); err != nil {
+		t.Fatalf("couldn't unmarshal command output: %v", err)
+	}
+	return resp, nil
+}
+
 func KeyAnnotate(t *testing.T, args []string) error {
 	t.Helper()
 	argsWithCmd := []string{"key", "annotate"}
@@ -0,0 +1,159 @@
+package tests_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	vgrand "code.vegaprotocol.io/shared/libs/rand"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	// given
+	home := t.TempDir()
+	newHome := t.TempDir()
+	_, passphraseFilePath := NewPassphraseFile(t, home)
+	walletName := vgrand.RandomStr(5)
+	networkFile := NewFile(t, home, "my-network.toml", FakeNetwork("my-network"))
+	backupPath := filepath.Join(t.TempDir(), "vegawallet.backup")
+
+	// when
+	_, err := Init(t, []string{
+		"--home", home,
+		"--output", "json",
+	})
+
+	// then
+	require.NoError(t, err)
+
+	// when
+	_, err = WalletCreate(t, []string{
+		"--home", home,
+		"--output", "json",
+		"--wallet", walletName,
+		"--passphrase-file", passphraseFilePath,
+	})
+
+	// then
+	require.NoError(t, err)
+
+	// when
+	_, err = NetworkImport(t, []string{
+		"--home", home,
+		"--output", "json",
+		"--from-file", networkFile,
+	})
+
+	// then
+	require.NoError(t, err)
+
+	// when
+	listKeysResp, err := KeyList(t, []string{
+		"--home", home,
+		"--output", "json",
+		"--wallet", walletName,
+		"--passphrase-file", passphraseFilePath,
+	})
+
+	// then
+	require.NoError(t, err)
+
+	// when
+	createBackupResp, err := BackupCreate(t, []string{
+		"--home", home,
+		"--output", "json",
+		"--file", backupPath,
+		"--passphrase-file", passphraseFilePath,
+		"--with-service-keys",
+	})
+
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, createBackupResp)
+	assert.Equal(t, backupPath, createBackupResp.FilePath)
+	assert.Equal(t, []string{walletName}, createBackupResp.Wallets)
+	assert.Equal(t, []string{"my-network"}, createBackupResp.Networks)
+	assert.True(t, createBackupResp.IncludeServiceKeys)
+
+	// when
+	restoreBackupResp, err := BackupRestore(t, []string{
+		backupPath,
+		"--home", newHome,
+		"--output", "json",
+		"--passphrase-file", passphraseFilePath,
+		"--dry-run",
+	})
+
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, restoreBackupResp)
+	assert.True(t, restoreBackupResp.DryRun)
+	require.Len(t, restoreBackupResp.Wallets, 1)
+	assert.Equal(t, "created", restoreBackupResp.Wallets[0].Action)
+
+	// when
+	listWalletsResp, err := WalletList(t, []string{
+		"--home", newHome,
+		"--output", "json",
+	})
+
+	// then
+	require.NoError(t, err)
+	assert.Empty(t, listWalletsResp.Wallets)
+
+	// when
+	restoreBackupResp, err = BackupRestore(t, []string{
+		backupPath,
+		"--home", newHome,
+		"--output", "json",
+		"--passphrase-file", passphraseFilePath,
+	})
+
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, restoreBackupResp)
+	assert.False(t, restoreBackupResp.DryRun)
+	require.NotNil(t, restoreBackupResp.ServiceKeys)
+	assert.Equal(t, "created", restoreBackupResp.ServiceKeys.Action)
+
+	// when
+	restoredListKeysResp, err := KeyList(t, []string{
+		"--home", newHome,
+		"--output", "json",
+		"--wallet", walletName,
+		"--passphrase-file", passphraseFilePath,
+	})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, listKeysResp, restoredListKeysResp)
+
+	// when
+	listNetsResp, err := NetworkList(t, []string{
+		"--home", newHome,
+		"--output", "json",
+	})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"my-network"}, listNetsResp.Networks)
+
+	// when
+	restoreBackupResp, err = BackupRestore(t, []string{
+		backupPath,
+		"--home", newHome,
+		"--output", "json",
+		"--passphrase-file", passphraseFilePath,
+		"--on-conflict", "rename",
+	})
+
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, restoreBackupResp)
+	require.Len(t, restoreBackupResp.Wallets, 1)
+	assert.Equal(t, "renamed", restoreBackupResp.Wallets[0].Action)
+	assert.Equal(t, walletName+"-restored", restoreBackupResp.Wallets[0].RestoredAs)
+	require.NotNil(t, restoreBackupResp.ServiceKeys)
+	assert.Equal(t, "skipped", restoreBackupResp.ServiceKeys.Action)
+}
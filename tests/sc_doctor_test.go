@@ -0,0 +1,95 @@
+package tests_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	vgrand "code.vegaprotocol.io/shared/libs/rand"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoctor(t *testing.T) {
+	// given
+	home := t.TempDir()
+	_, passphraseFilePath := NewPassphraseFile(t, home)
+	walletName := vgrand.RandomStr(5)
+	networkFile := NewFile(t, home, "my-network.toml", FakeNetwork("my-network"))
+
+	// when
+	_, err := Init(t, []string{
+		"--home", home,
+		"--output", "json",
+	})
+
+	// then
+	require.NoError(t, err)
+
+	// when
+	createWalletResp, err := WalletCreate(t, []string{
+		"--home", home,
+		"--output", "json",
+		"--wallet", walletName,
+		"--passphrase-file", passphraseFilePath,
+	})
+
+	// then
+	require.NoError(t, err)
+	AssertCreateWallet(t, createWalletResp).
+		WithName(walletName).
+		LocatedUnder(home)
+
+	// when
+	_, err = NetworkImport(t, []string{
+		"--home", home,
+		"--output", "json",
+		"--from-file", networkFile,
+	})
+
+	// then
+	require.NoError(t, err)
+
+	// when
+	doctorResp, err := Doctor(t, []string{
+		"--home", home,
+		"--output", "json",
+		"--passphrase-file", passphraseFilePath,
+	})
+
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, doctorResp)
+	assert.True(t, doctorResp.Healthy)
+	require.Len(t, doctorResp.Wallets.Wallets, 1)
+	assert.Equal(t, walletName, doctorResp.Wallets.Wallets[0].Wallet)
+	assert.True(t, doctorResp.Wallets.Wallets[0].Healthy)
+	require.Len(t, doctorResp.Networks.Networks, 1)
+	assert.True(t, doctorResp.Networks.Networks[0].Healthy)
+	assert.True(t, doctorResp.Service.RSAKeys.Healthy)
+
+	// given
+	strayFileName := vgrand.RandomStr(5)
+	NewFile(t, filepath.Dir(createWalletResp.Wallet.FilePath), strayFileName, "not a wallet")
+
+	// when
+	doctorResp, err = Doctor(t, []string{
+		"--home", home,
+		"--output", "json",
+		"--passphrase-file", passphraseFilePath,
+	})
+
+	// then
+	require.Error(t, err)
+	require.NotNil(t, doctorResp)
+	assert.False(t, doctorResp.Healthy)
+	require.Len(t, doctorResp.Wallets.Wallets, 2)
+	for _, w := range doctorResp.Wallets.Wallets {
+		if w.Wallet == walletName {
+			assert.True(t, w.Healthy)
+		} else {
+			assert.Equal(t, strayFileName, w.Wallet)
+			assert.False(t, w.Healthy)
+			assert.NotEmpty(t, w.Issues)
+		}
+	}
+}
@@ -10,12 +10,24 @@ var (
 	ErrIsolatedWalletDoesNotHaveMasterKey = errors.New("isolated wallet doesn't have a master key")
 	ErrCantRotateKeyInIsolatedWallet      = errors.New("isolated wallet can't rotate key")
 	ErrInvalidRecoveryPhrase              = errors.New("recovery phrase is not valid")
+	ErrInvalidTwoFactorCode               = errors.New("two-factor authentication code is not valid")
 	ErrPubKeyAlreadyTainted               = errors.New("public key is already tainted")
 	ErrPubKeyIsTainted                    = errors.New("public key is tainted")
 	ErrPubKeyNotTainted                   = errors.New("public key is not tainted")
 	ErrPubKeyDoesNotExist                 = errors.New("public key does not exist")
+	ErrTwoFactorAlreadyEnabled            = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorCodeAlreadyUsed           = errors.New("two-factor authentication code has already been used, wait for the next one")
+	ErrTwoFactorCodeRequired              = errors.New("a two-factor authentication code is required")
+	ErrTwoFactorNotEnabled                = errors.New("two-factor authentication is not enabled")
 	ErrWalletAlreadyExists                = errors.New("a wallet with the same name already exists")
 	ErrWalletDoesNotExists                = errors.New("wallet does not exist")
+	ErrWalletIsLocked                     = errors.New("wallet is locked, it has to be unlocked with its passphrase")
+	ErrWalletIDDoesNotMatchNode           = errors.New("wallet ID doesn't match the one derived from the wallet node")
+	ErrWalletNameHasForbiddenCharacters   = errors.New("wallet name contains forbidden characters")
+	ErrWalletNameHasSurroundingSpaces     = errors.New("wallet name can't start or end with spaces")
+	ErrWalletNameIsEmpty                  = errors.New("wallet name can't be empty")
+	ErrWalletNameIsReserved               = errors.New("wallet name can't be \".\" or \"..\"")
+	ErrWalletNameIsTooLong                = errors.New("wallet name is too long")
 	ErrWalletNotLoggedIn                  = errors.New("wallet is not logged in")
 	ErrWrongPassphrase                    = errors.New("wrong passphrase")
 )
@@ -33,3 +45,45 @@ func NewUnsupportedWalletVersionError(v uint32) UnsupportedWalletVersionError {
 func (e UnsupportedWalletVersionError) Error() string {
 	return fmt.Sprintf("wallet with version %d isn't supported", e.UnsupportedVersion)
 }
+
+type CorruptedKeyPairError struct {
+	Index uint32
+}
+
+func NewCorruptedKeyPairError(index uint32) CorruptedKeyPairError {
+	return CorruptedKeyPairError{
+		Index: index,
+	}
+}
+
+func (e CorruptedKeyPairError) Error() string {
+	return fmt.Sprintf("public key of key pair %d doesn't match its private key", e.Index)
+}
+
+type KeyPairDoesNotMatchNodeError struct {
+	Index uint32
+}
+
+func NewKeyPairDoesNotMatchNodeError(index uint32) KeyPairDoesNotMatchNodeError {
+	return KeyPairDoesNotMatchNodeError{
+		Index: index,
+	}
+}
+
+func (e KeyPairDoesNotMatchNodeError) Error() string {
+	return fmt.Sprintf("key pair %d doesn't match the one derived from the wallet node", e.Index)
+}
+
+type DuplicatedKeyIndexError struct {
+	Index uint32
+}
+
+func NewDuplicatedKeyIndexError(index uint32) DuplicatedKeyIndexError {
+	return DuplicatedKeyIndexError{
+		Index: index,
+	}
+}
+
+func (e DuplicatedKeyIndexError) Error() string {
+	return fmt.Sprintf("key index %d is used by more than one key pair", e.Index)
+}
@@ -131,9 +131,10 @@ func UntaintKey(store Store, req *UntaintKeyRequest) error {
 }
 
 type IsolateKeyRequest struct {
-	Wallet     string `json:"wallet"`
-	PubKey     string `json:"pubKey"`
-	Passphrase string `json:"passphrase"`
+	Wallet        string `json:"wallet"`
+	PubKey        string `json:"pubKey"`
+	Passphrase    string `json:"passphrase"`
+	TwoFactorCode string `json:"twoFactorCode,omitempty"`
 }
 
 type IsolateKeyResponse struct {
@@ -147,6 +148,10 @@ func IsolateKey(store Store, req *IsolateKeyRequest) (*IsolateKeyResponse, error
 		return nil, err
 	}
 
+	if err := verifyTwoFactorCode(store, w, req.Passphrase, req.TwoFactorCode); err != nil {
+		return nil, err
+	}
+
 	isolatedWallet, err := w.IsolateWithKey(req.PubKey)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't isolate wallet %s: %w", req.Wallet, err)
@@ -236,6 +241,7 @@ type RotateKeyRequest struct {
 	CurrentPublicKey  string `json:"currentPublicKey"`
 	TxBlockHeight     uint64 `json:"txBlockHeight"`
 	TargetBlockHeight uint64 `json:"targetBlockHeight"`
+	TwoFactorCode     string `json:"twoFactorCode,omitempty"`
 }
 
 type RotateKeyResponse struct {
@@ -249,6 +255,10 @@ func RotateKey(store Store, req *RotateKeyRequest) (*RotateKeyResponse, error) {
 		return nil, err
 	}
 
+	if err := verifyTwoFactorCode(store, w, req.Passphrase, req.TwoFactorCode); err != nil {
+		return nil, err
+	}
+
 	mKeyPair, err := w.GetMasterKeyPair()
 	if errors.Is(err, ErrIsolatedWalletDoesNotHaveMasterKey) {
 		return nil, ErrCantRotateKeyInIsolatedWallet
@@ -314,6 +324,79 @@ func RotateKey(store Store, req *RotateKeyRequest) (*RotateKeyResponse, error) {
 	}, nil
 }
 
+type EnableTwoFactorRequest struct {
+	Wallet     string `json:"wallet"`
+	Passphrase string `json:"passphrase"`
+}
+
+type EnableTwoFactorResponse struct {
+	Wallet          string   `json:"wallet"`
+	Secret          string   `json:"secret"`
+	ProvisioningURI string   `json:"provisioningUri"`
+	RecoveryCodes   []string `json:"recoveryCodes"`
+}
+
+// EnableTwoFactor generates a TOTP secret, and recovery codes, for the wallet.
+// From then on, a code is required to log in to the wallet through the API,
+// and for the sensitive operations.
+func EnableTwoFactor(store Store, req *EnableTwoFactorRequest) (*EnableTwoFactorResponse, error) {
+	w, err := getWallet(store, req.Wallet, req.Passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.TwoFactor() != nil {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	tf, recoveryCodes, err := NewTwoFactor()
+	if err != nil {
+		return nil, err
+	}
+	w.SetTwoFactor(tf)
+
+	if err := store.SaveWallet(w, req.Passphrase); err != nil {
+		return nil, fmt.Errorf("couldn't save wallet %s: %w", req.Wallet, err)
+	}
+
+	return &EnableTwoFactorResponse{
+		Wallet:          req.Wallet,
+		Secret:          tf.Secret,
+		ProvisioningURI: tf.ProvisioningURI(req.Wallet),
+		RecoveryCodes:   recoveryCodes,
+	}, nil
+}
+
+type DisableTwoFactorRequest struct {
+	Wallet        string `json:"wallet"`
+	Passphrase    string `json:"passphrase"`
+	TwoFactorCode string `json:"twoFactorCode"`
+}
+
+// DisableTwoFactor removes the two-factor authentication of the wallet. It
+// requires a current code, or a recovery code.
+func DisableTwoFactor(store Store, req *DisableTwoFactorRequest) error {
+	w, err := getWallet(store, req.Wallet, req.Passphrase)
+	if err != nil {
+		return err
+	}
+
+	if w.TwoFactor() == nil {
+		return ErrTwoFactorNotEnabled
+	}
+
+	if _, err := CheckTwoFactorCode(w, req.TwoFactorCode); err != nil {
+		return err
+	}
+	w.SetTwoFactor(nil)
+
+	if err := store.SaveWallet(w, req.Passphrase); err != nil {
+		return fmt.Errorf("couldn't save wallet %s: %w", req.Wallet, err)
+	}
+
+	return nil
+}
+
 type GetWalletInfoRequest struct {
 	Wallet     string `json:"wallet"`
 	Passphrase string `json:"passphrase"`
@@ -338,6 +421,44 @@ func GetWalletInfo(store Store, req *GetWalletInfoRequest) (*GetWalletInfoRespon
 	}, nil
 }
 
+type DiagnoseWalletRequest struct {
+	Wallet     string `json:"wallet"`
+	Passphrase string `json:"passphrase"`
+}
+
+type DiagnoseWalletResponse struct {
+	Wallet  string   `json:"wallet"`
+	Healthy bool     `json:"healthy"`
+	Issues  []string `json:"issues"`
+}
+
+// DiagnoseWallet verifies the wallet can be decrypted with the passphrase and
+// its content is consistent. Failing to decrypt the wallet is reported as an
+// issue, not as an error.
+func DiagnoseWallet(store Store, req *DiagnoseWalletRequest) (*DiagnoseWalletResponse, error) {
+	resp := &DiagnoseWalletResponse{
+		Wallet: req.Wallet,
+		Issues: []string{},
+	}
+
+	w, err := getWallet(store, req.Wallet, req.Passphrase)
+	if err != nil {
+		if errors.Is(err, ErrWalletDoesNotExists) {
+			return nil, err
+		}
+		resp.Issues = append(resp.Issues, err.Error())
+		return resp, nil
+	}
+
+	for _, issue := range w.Diagnose() {
+		resp.Issues = append(resp.Issues, issue.Error())
+	}
+
+	resp.Healthy = len(resp.Issues) == 0
+
+	return resp, nil
+}
+
 type CreateWalletRequest struct {
 	Wallet     string `json:"wallet"`
 	Passphrase string `json:"passphrase"`
@@ -364,6 +485,10 @@ type FirstPublicKey struct {
 func CreateWallet(store Store, req *CreateWalletRequest) (*CreateWalletResponse, error) {
 	resp := &CreateWalletResponse{}
 
+	if err := ValidateName(req.Wallet); err != nil {
+		return nil, err
+	}
+
 	if store.WalletExists(req.Wallet) {
 		return nil, ErrWalletAlreadyExists
 	}
@@ -413,6 +538,10 @@ type ImportedWallet struct {
 }
 
 func ImportWallet(store Store, req *ImportWalletRequest) (*ImportWalletResponse, error) {
+	if err := ValidateName(req.Wallet); err != nil {
+		return nil, err
+	}
+
 	if store.WalletExists(req.Wallet) {
 		return nil, ErrWalletAlreadyExists
 	}
@@ -552,6 +681,23 @@ func getWallet(store Store, wallet, passphrase string) (Wallet, error) {
 	return w, nil
 }
 
+// verifyTwoFactorCode verifies the code when the wallet requires one. The
+// wallet is saved once the code is accepted, so it can't be used again.
+func verifyTwoFactorCode(store Store, w Wallet, passphrase, code string) error {
+	codeAccepted, err := CheckTwoFactorCode(w, code)
+	if err != nil {
+		return err
+	}
+
+	if codeAccepted {
+		if err := store.SaveWallet(w, passphrase); err != nil {
+			return fmt.Errorf("couldn't save wallet %s: %w", w.Name(), err)
+		}
+	}
+
+	return nil
+}
+
 func addDefaultKeyName(w Wallet, meta []Meta) []Meta {
 	for _, m := range meta {
 		if m.Key == KeyNameMeta {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 	"testing"
+	"time"
 
 	"code.vegaprotocol.io/protos/vega"
 	commandspb "code.vegaprotocol.io/protos/vega/commands/v1"
@@ -243,6 +244,7 @@ func testUntaintingKeyOfNonExistingWalletFails(t *testing.T) {
 func TestIsolateKey(t *testing.T) {
 	t.Run("Isolating key succeeds", testIsolatingKeySucceeds)
 	t.Run("Isolating key of non-existing wallet fails", testIsolatingKeyOfNonExistingWalletFails)
+	t.Run("Isolating key without required two-factor code fails", testIsolatingKeyWithoutRequiredTwoFactorCodeFails)
 }
 
 func testIsolatingKeySucceeds(t *testing.T) {
@@ -297,6 +299,32 @@ func testIsolatingKeyOfNonExistingWalletFails(t *testing.T) {
 	assert.Nil(t, resp)
 }
 
+func testIsolatingKeyWithoutRequiredTwoFactorCodeFails(t *testing.T) {
+	// given
+	w := newWalletWithKey(t)
+	tf, _, err := wallet.NewTwoFactor()
+	require.NoError(t, err)
+	w.SetTwoFactor(tf)
+	req := &wallet.IsolateKeyRequest{
+		Wallet:     w.Name(),
+		PubKey:     w.ListKeyPairs()[0].PublicKey(),
+		Passphrase: "passphrase",
+	}
+
+	// setup
+	store := handlerMocks(t)
+	store.EXPECT().WalletExists(req.Wallet).Times(1).Return(true)
+	store.EXPECT().GetWallet(req.Wallet, req.Passphrase).Times(1).Return(w, nil)
+	store.EXPECT().SaveWallet(gomock.Any(), req.Passphrase).Times(0)
+
+	// when
+	resp, err := wallet.IsolateKey(store, req)
+
+	// then
+	require.ErrorIs(t, err, wallet.ErrTwoFactorCodeRequired)
+	assert.Nil(t, resp)
+}
+
 func TestListKeys(t *testing.T) {
 	t.Run("List keys succeeds", testListKeysSucceeds)
 	t.Run("List keys of non-existing wallet fails", testListKeysOfNonExistingWalletFails)
@@ -360,6 +388,121 @@ func testListKeysOfNonExistingWalletFails(t *testing.T) {
 	assert.Nil(t, resp)
 }
 
+func TestEnableTwoFactor(t *testing.T) {
+	t.Run("Enabling two-factor authentication succeeds", testEnablingTwoFactorSucceeds)
+	t.Run("Enabling two-factor authentication twice fails", testEnablingTwoFactorTwiceFails)
+}
+
+func testEnablingTwoFactorSucceeds(t *testing.T) {
+	// given
+	w := newWallet(t)
+	req := &wallet.EnableTwoFactorRequest{
+		Wallet:     w.Name(),
+		Passphrase: "passphrase",
+	}
+
+	// setup
+	store := handlerMocks(t)
+	store.EXPECT().WalletExists(req.Wallet).Times(1).Return(true)
+	store.EXPECT().GetWallet(req.Wallet, req.Passphrase).Times(1).Return(w, nil)
+	store.EXPECT().SaveWallet(w, req.Passphrase).Times(1).Return(nil)
+
+	// when
+	resp, err := wallet.EnableTwoFactor(store, req)
+
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	require.NotNil(t, w.TwoFactor())
+	assert.Equal(t, w.TwoFactor().Secret, resp.Secret)
+	assert.Contains(t, resp.ProvisioningURI, "otpauth://totp/")
+	assert.Len(t, resp.RecoveryCodes, wallet.RecoveryCodesCount)
+}
+
+func testEnablingTwoFactorTwiceFails(t *testing.T) {
+	// given
+	w := newWallet(t)
+	tf, _, err := wallet.NewTwoFactor()
+	require.NoError(t, err)
+	w.SetTwoFactor(tf)
+	req := &wallet.EnableTwoFactorRequest{
+		Wallet:     w.Name(),
+		Passphrase: "passphrase",
+	}
+
+	// setup
+	store := handlerMocks(t)
+	store.EXPECT().WalletExists(req.Wallet).Times(1).Return(true)
+	store.EXPECT().GetWallet(req.Wallet, req.Passphrase).Times(1).Return(w, nil)
+	store.EXPECT().SaveWallet(gomock.Any(), gomock.Any()).Times(0)
+
+	// when
+	resp, err := wallet.EnableTwoFactor(store, req)
+
+	// then
+	require.ErrorIs(t, err, wallet.ErrTwoFactorAlreadyEnabled)
+	assert.Nil(t, resp)
+	assert.Equal(t, tf, w.TwoFactor())
+}
+
+func TestDisableTwoFactor(t *testing.T) {
+	t.Run("Disabling two-factor authentication succeeds", testDisablingTwoFactorSucceeds)
+	t.Run("Disabling two-factor authentication without code fails", testDisablingTwoFactorWithoutCodeFails)
+}
+
+func testDisablingTwoFactorSucceeds(t *testing.T) {
+	// given
+	w := newWallet(t)
+	tf, _, err := wallet.NewTwoFactor()
+	require.NoError(t, err)
+	w.SetTwoFactor(tf)
+	code, err := wallet.TOTPCode(tf.Secret, time.Now())
+	require.NoError(t, err)
+	req := &wallet.DisableTwoFactorRequest{
+		Wallet:        w.Name(),
+		Passphrase:    "passphrase",
+		TwoFactorCode: code,
+	}
+
+	// setup
+	store := handlerMocks(t)
+	store.EXPECT().WalletExists(req.Wallet).Times(1).Return(true)
+	store.EXPECT().GetWallet(req.Wallet, req.Passphrase).Times(1).Return(w, nil)
+	store.EXPECT().SaveWallet(w, req.Passphrase).Times(1).Return(nil)
+
+	// when
+	err = wallet.DisableTwoFactor(store, req)
+
+	// then
+	require.NoError(t, err)
+	assert.Nil(t, w.TwoFactor())
+}
+
+func testDisablingTwoFactorWithoutCodeFails(t *testing.T) {
+	// given
+	w := newWallet(t)
+	tf, _, err := wallet.NewTwoFactor()
+	require.NoError(t, err)
+	w.SetTwoFactor(tf)
+	req := &wallet.DisableTwoFactorRequest{
+		Wallet:     w.Name(),
+		Passphrase: "passphrase",
+	}
+
+	// setup
+	store := handlerMocks(t)
+	store.EXPECT().WalletExists(req.Wallet).Times(1).Return(true)
+	store.EXPECT().GetWallet(req.Wallet, req.Passphrase).Times(1).Return(w, nil)
+	store.EXPECT().SaveWallet(gomock.Any(), gomock.Any()).Times(0)
+
+	// when
+	err = wallet.DisableTwoFactor(store, req)
+
+	// then
+	require.ErrorIs(t, err, wallet.ErrTwoFactorCodeRequired)
+	assert.NotNil(t, w.TwoFactor())
+}
+
 func TestGetWalletInfo(t *testing.T) {
 	t.Run("Get wallet info succeeds", testGetWalletInfoSucceeds)
 	t.Run("Get wallet info of non-existing wallet fails", testGetWalletInfoOfNonExistingWalletFails)
@@ -650,6 +793,9 @@ func TestRotateKey(t *testing.T) {
 	t.Run("Rotate key with non existing new public key fails", testRotateKeyWithNonExistingNewPublicKeyFails)
 	t.Run("Rotate key with non existing current public key fails", testRotateKeyWithNonExistingCurrentPublicKeyFails)
 	t.Run("Rotate key tained public key fails", testRotateKeyWithTaintedPublicKeyFails)
+	t.Run("Rotate key with two-factor code succeeds", testRotateKeyWithTwoFactorCodeSucceeds)
+	t.Run("Rotate key with recovery code saves the wallet", testRotateKeyWithRecoveryCodeSavesWallet)
+	t.Run("Rotate key with invalid two-factor code fails", testRotateKeyWithInvalidTwoFactorCodeFails)
 }
 
 func testRotateKeySucceeds(t *testing.T) {
@@ -705,6 +851,102 @@ func testRotateKeySucceeds(t *testing.T) {
 	require.Equal(t, req.NewPublicKey, keyRotate.KeyRotateSubmission.NewPubKey)
 }
 
+func testRotateKeyWithTwoFactorCodeSucceeds(t *testing.T) {
+	// given
+	w := importWalletWithTwoKeys(t)
+	tf, _, err := wallet.NewTwoFactor()
+	require.NoError(t, err)
+	w.SetTwoFactor(tf)
+	code, err := wallet.TOTPCode(tf.Secret, time.Now())
+	require.NoError(t, err)
+
+	req := &wallet.RotateKeyRequest{
+		Wallet:            w.Name(),
+		Passphrase:        "passphrase",
+		NewPublicKey:      w.ListPublicKeys()[1].Key(),
+		CurrentPublicKey:  w.ListPublicKeys()[0].Key(),
+		TxBlockHeight:     20,
+		TargetBlockHeight: 25,
+		TwoFactorCode:     code,
+	}
+
+	// setup
+	store := handlerMocks(t)
+	store.EXPECT().WalletExists(req.Wallet).Times(1).Return(true)
+	store.EXPECT().GetWallet(req.Wallet, req.Passphrase).Times(1).Return(w, nil)
+	store.EXPECT().SaveWallet(w, req.Passphrase).Times(1).Return(nil)
+
+	// when
+	resp, err := wallet.RotateKey(store, req)
+
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	assert.NotZero(t, w.TwoFactor().LastUsedStep)
+}
+
+func testRotateKeyWithRecoveryCodeSavesWallet(t *testing.T) {
+	// given
+	w := importWalletWithTwoKeys(t)
+	tf, recoveryCodes, err := wallet.NewTwoFactor()
+	require.NoError(t, err)
+	w.SetTwoFactor(tf)
+
+	req := &wallet.RotateKeyRequest{
+		Wallet:            w.Name(),
+		Passphrase:        "passphrase",
+		NewPublicKey:      w.ListPublicKeys()[1].Key(),
+		CurrentPublicKey:  w.ListPublicKeys()[0].Key(),
+		TxBlockHeight:     20,
+		TargetBlockHeight: 25,
+		TwoFactorCode:     recoveryCodes[0],
+	}
+
+	// setup
+	store := handlerMocks(t)
+	store.EXPECT().WalletExists(req.Wallet).Times(1).Return(true)
+	store.EXPECT().GetWallet(req.Wallet, req.Passphrase).Times(1).Return(w, nil)
+	store.EXPECT().SaveWallet(w, req.Passphrase).Times(1).Return(nil)
+
+	// when
+	resp, err := wallet.RotateKey(store, req)
+
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	assert.Len(t, w.TwoFactor().RecoveryCodes, wallet.RecoveryCodesCount-1)
+}
+
+func testRotateKeyWithInvalidTwoFactorCodeFails(t *testing.T) {
+	// given
+	w := importWalletWithTwoKeys(t)
+	tf, _, err := wallet.NewTwoFactor()
+	require.NoError(t, err)
+	w.SetTwoFactor(tf)
+
+	req := &wallet.RotateKeyRequest{
+		Wallet:            w.Name(),
+		Passphrase:        "passphrase",
+		NewPublicKey:      w.ListPublicKeys()[1].Key(),
+		CurrentPublicKey:  w.ListPublicKeys()[0].Key(),
+		TxBlockHeight:     20,
+		TargetBlockHeight: 25,
+		TwoFactorCode:     "not-a-code",
+	}
+
+	// setup
+	store := handlerMocks(t)
+	store.EXPECT().WalletExists(req.Wallet).Times(1).Return(true)
+	store.EXPECT().GetWallet(req.Wallet, req.Passphrase).Times(1).Return(w, nil)
+
+	// when
+	resp, err := wallet.RotateKey(store, req)
+
+	// then
+	require.ErrorIs(t, err, wallet.ErrInvalidTwoFactorCode)
+	assert.Nil(t, resp)
+}
+
 func testRotateWithNonExistingWalletFails(t *testing.T) {
 	// given
 	req := &wallet.RotateKeyRequest{
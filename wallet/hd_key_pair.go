@@ -123,6 +123,20 @@ func (k *HDKeyPair) Sign(data []byte) (*Signature, error) {
 	}, nil
 }
 
+// isConsistent verifies the public key is the one embedded in the private key.
+func (k *HDKeyPair) isConsistent() bool {
+	if len(k.privateKey.bytes) != ed25519.PrivateKeySize || len(k.publicKey.bytes) != ed25519.PublicKeySize {
+		return false
+	}
+
+	pubKey, ok := ed25519.PrivateKey(k.privateKey.bytes).Public().(ed25519.PublicKey)
+	if !ok {
+		return false
+	}
+
+	return pubKey.Equal(ed25519.PublicKey(k.publicKey.bytes))
+}
+
 func (k *HDKeyPair) DeepCopy() *HDKeyPair {
 	copiedK := *k
 	return &copiedK
@@ -1,9 +1,11 @@
 package wallet
 
 import (
+	"bytes"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"sort"
 
 	"github.com/tyler-smith/go-bip39"
 	"github.com/vegaprotocol/go-slip10"
@@ -31,6 +33,9 @@ type HDWallet struct {
 	// "wallet node".
 	node *slip10.Node
 	id   string
+
+	// twoFactor is only set when the two-factor authentication is enabled.
+	twoFactor *TwoFactor
 }
 
 // NewHDWallet creates a wallet with auto-generated recovery phrase. This is
@@ -99,6 +104,18 @@ func (w *HDWallet) SetName(newName string) {
 	w.name = newName
 }
 
+// TwoFactor returns the two-factor authentication settings, or nil if it's not
+// enabled.
+func (w *HDWallet) TwoFactor() *TwoFactor {
+	return w.twoFactor
+}
+
+// SetTwoFactor enables the two-factor authentication with the specified
+// settings. Setting nil disables it.
+func (w *HDWallet) SetTwoFactor(tf *TwoFactor) {
+	w.twoFactor = tf
+}
+
 // DescribeKeyPair returns all the information associated with a public key.
 func (w *HDWallet) DescribeKeyPair(pubKey string) (KeyPair, error) {
 	keyPair, ok := w.keyRing.FindPair(pubKey)
@@ -265,34 +282,101 @@ func (w *HDWallet) IsolateWithKey(pubKey string) (Wallet, error) {
 		return nil, ErrPubKeyIsTainted
 	}
 
-	return &HDWallet{
+	isolatedWallet := &HDWallet{
 		version: w.version,
 		name:    fmt.Sprintf("%s.%s.isolated", w.name, keyPair.PublicKey()[0:8]),
 		keyRing: LoadHDKeyRing([]HDKeyPair{keyPair}),
 		id:      w.id,
-	}, nil
+	}
+
+	// The isolated wallet stays protected by the same authenticator. The
+	// recovery codes are not copied, as they would otherwise be usable once
+	// per wallet file. They stay tied to the master wallet.
+	if w.twoFactor != nil {
+		isolatedWallet.twoFactor = &TwoFactor{
+			Secret:        w.twoFactor.Secret,
+			RecoveryCodes: []string{},
+			LastUsedStep:  w.twoFactor.LastUsedStep,
+		}
+	}
+
+	return isolatedWallet, nil
 }
 
 func (w *HDWallet) IsIsolated() bool {
 	return w.node == nil
 }
 
+// Diagnose verifies the consistency of the wallet. The wallet ID and the key
+// pairs are compared with the ones derived from the wallet node. For isolated
+// wallets, as there is no node, only the key pairs' consistency is verified.
+// It returns all the inconsistencies found.
+func (w *HDWallet) Diagnose() []error {
+	issues := []error{}
+
+	if !w.IsIsolated() && w.id != walletID(w.node) {
+		issues = append(issues, ErrWalletIDDoesNotMatchNode)
+	}
+
+	usedIndexes := map[uint32]int{}
+	for _, keyPair := range w.keyRing.ListKeyPairs() {
+		usedIndexes[keyPair.Index()]++
+
+		if !keyPair.isConsistent() {
+			issues = append(issues, NewCorruptedKeyPairError(keyPair.Index()))
+			continue
+		}
+
+		if w.IsIsolated() {
+			continue
+		}
+
+		keyNode, err := w.deriveKeyNode(keyPair.Index())
+		if err != nil {
+			issues = append(issues, fmt.Errorf("couldn't derive key pair %d: %w", keyPair.Index(), err))
+			continue
+		}
+
+		derivedPubKey, derivedPrivKey := keyNode.Keypair()
+		if !bytes.Equal(derivedPubKey, keyPair.publicKey.bytes) || !bytes.Equal(derivedPrivKey, keyPair.privateKey.bytes) {
+			issues = append(issues, NewKeyPairDoesNotMatchNodeError(keyPair.Index()))
+		}
+	}
+
+	duplicatedIndexes := []uint32{}
+	for index, count := range usedIndexes {
+		if count > 1 {
+			duplicatedIndexes = append(duplicatedIndexes, index)
+		}
+	}
+	sort.Slice(duplicatedIndexes, func(i, j int) bool {
+		return duplicatedIndexes[i] < duplicatedIndexes[j]
+	})
+	for _, index := range duplicatedIndexes {
+		issues = append(issues, NewDuplicatedKeyIndexError(index))
+	}
+
+	return issues
+}
+
 type jsonHDWallet struct {
 	// The wallet name is retrieved from the file name it is stored in, so no
 	// need to serialize it.
 
-	Version uint32       `json:"version"`
-	Node    *slip10.Node `json:"node,omitempty"`
-	ID      string       `json:"id,omitempty"`
-	Keys    []HDKeyPair  `json:"keys"`
+	Version   uint32       `json:"version"`
+	Node      *slip10.Node `json:"node,omitempty"`
+	ID        string       `json:"id,omitempty"`
+	Keys      []HDKeyPair  `json:"keys"`
+	TwoFactor *TwoFactor   `json:"twoFactor,omitempty"`
 }
 
 func (w *HDWallet) MarshalJSON() ([]byte, error) {
 	jsonW := jsonHDWallet{
-		Version: w.Version(),
-		Keys:    w.keyRing.ListKeyPairs(),
-		Node:    w.node,
-		ID:      w.id,
+		Version:   w.Version(),
+		Keys:      w.keyRing.ListKeyPairs(),
+		Node:      w.node,
+		ID:        w.id,
+		TwoFactor: w.twoFactor,
 	}
 	return json.Marshal(jsonW)
 }
@@ -304,10 +388,11 @@ func (w *HDWallet) UnmarshalJSON(data []byte) error {
 	}
 
 	*w = HDWallet{
-		version: jsonW.Version,
-		keyRing: LoadHDKeyRing(jsonW.Keys),
-		node:    jsonW.Node,
-		id:      jsonW.ID,
+		version:   jsonW.Version,
+		keyRing:   LoadHDKeyRing(jsonW.Keys),
+		node:      jsonW.Node,
+		id:        jsonW.ID,
+		twoFactor: jsonW.TwoFactor,
 	}
 
 	if len(w.id) == 0 {
@@ -52,6 +52,8 @@ func TestHDWallet(t *testing.T) {
 	t.Run("Isolating wallet with tainted key pair fails", testHDWalletIsolatingWalletWithTaintedKeyPairFails)
 	t.Run("Isolating wallet with non-existing key pair fails", testHDWalletIsolatingWalletWithNonExistingKeyPairFails)
 	t.Run("Getting master key pair succeeds", testHDWalletGettingWalletMasterKeySucceeds)
+	t.Run("Diagnosing healthy wallet succeeds", testHDWalletDiagnosingHealthyWalletSucceeds)
+	t.Run("Diagnosing corrupted wallet reports issues", testHDWalletDiagnosingCorruptedWalletReportsIssues)
 }
 
 func testHDWalletCreateWalletSucceeds(t *testing.T) {
@@ -1546,3 +1548,70 @@ func testHDWalletGettingWalletMasterKeySucceeds(t *testing.T) {
 		})
 	}
 }
+
+func testHDWalletDiagnosingHealthyWalletSucceeds(t *testing.T) {
+	// given
+	w, err := wallet.ImportHDWallet(vgrand.RandomStr(5), TestRecoveryPhrase1, wallet.LatestVersion)
+	require.NoError(t, err)
+	kp, err := w.GenerateKeyPair(nil)
+	require.NoError(t, err)
+	_, err = w.GenerateKeyPair(nil)
+	require.NoError(t, err)
+
+	// when
+	issues := w.Diagnose()
+
+	// then
+	assert.Empty(t, issues)
+
+	// given
+	isolatedWallet, err := w.IsolateWithKey(kp.PublicKey())
+	require.NoError(t, err)
+
+	// when
+	issues = isolatedWallet.Diagnose()
+
+	// then
+	assert.Empty(t, issues)
+}
+
+func testHDWalletDiagnosingCorruptedWalletReportsIssues(t *testing.T) {
+	// given
+	w, err := wallet.ImportHDWallet(vgrand.RandomStr(5), TestRecoveryPhrase1, wallet.LatestVersion)
+	require.NoError(t, err)
+	_, err = w.GenerateKeyPair(nil)
+	require.NoError(t, err)
+	_, err = w.GenerateKeyPair(nil)
+	require.NoError(t, err)
+
+	rawWallet, err := json.Marshal(w)
+	require.NoError(t, err)
+
+	jsonWallet := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(rawWallet, &jsonWallet))
+
+	// Tampering with the wallet ID, and moving the second key to the first
+	// index.
+	jsonWallet["id"] = vgrand.RandomStr(64)
+	keys, ok := jsonWallet["keys"].([]interface{})
+	require.True(t, ok)
+	secondKey, ok := keys[1].(map[string]interface{})
+	require.True(t, ok)
+	secondKey["index"] = 1
+
+	rawCorruptedWallet, err := json.Marshal(jsonWallet)
+	require.NoError(t, err)
+
+	corruptedWallet := &wallet.HDWallet{}
+	require.NoError(t, json.Unmarshal(rawCorruptedWallet, corruptedWallet))
+
+	// when
+	issues := corruptedWallet.Diagnose()
+
+	// then
+	assert.Equal(t, []error{
+		wallet.ErrWalletIDDoesNotMatchNode,
+		wallet.NewKeyPairDoesNotMatchNodeError(1),
+		wallet.NewDuplicatedKeyIndexError(1),
+	}, issues)
+}
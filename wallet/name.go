@@ -0,0 +1,43 @@
+package wallet
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MaxNameLength is the maximum number of characters a wallet name can have.
+const MaxNameLength = 128
+
+// forbiddenNameCharacters are the characters that can't be used in a wallet
+// name, because they are path separators, or they are not supported by the
+// file systems of some platforms.
+const forbiddenNameCharacters = `/\<>:"|?*`
+
+// ValidateName verifies the wallet name can be safely used as a display name.
+// The wallet name is never used as a file name as is, but keeping them sane
+// avoids surprises on platforms that don't share the same restrictions.
+func ValidateName(name string) error {
+	if len(name) == 0 {
+		return ErrWalletNameIsEmpty
+	}
+
+	if len([]rune(name)) > MaxNameLength {
+		return ErrWalletNameIsTooLong
+	}
+
+	if strings.TrimSpace(name) != name {
+		return ErrWalletNameHasSurroundingSpaces
+	}
+
+	if name == "." || name == ".." {
+		return ErrWalletNameIsReserved
+	}
+
+	for _, c := range name {
+		if unicode.IsControl(c) || strings.ContainsRune(forbiddenNameCharacters, c) {
+			return ErrWalletNameHasForbiddenCharacters
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package wallet_test
+
+import (
+	"strings"
+	"testing"
+
+	"code.vegaprotocol.io/vegawallet/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateName(t *testing.T) {
+	tcs := []struct {
+		name       string
+		walletName string
+		err        error
+	}{
+		{
+			name:       "simple name",
+			walletName: "my-wallet",
+			err:        nil,
+		}, {
+			name:       "name with spaces and dots",
+			walletName: "my wallet.12345678.isolated",
+			err:        nil,
+		}, {
+			name:       "name with unicode characters",
+			walletName: "portefeuille épargne",
+			err:        nil,
+		}, {
+			name:       "empty name",
+			walletName: "",
+			err:        wallet.ErrWalletNameIsEmpty,
+		}, {
+			name:       "too long name",
+			walletName: strings.Repeat("a", wallet.MaxNameLength+1),
+			err:        wallet.ErrWalletNameIsTooLong,
+		}, {
+			name:       "name with surrounding spaces",
+			walletName: " my-wallet ",
+			err:        wallet.ErrWalletNameHasSurroundingSpaces,
+		}, {
+			name:       "current directory",
+			walletName: ".",
+			err:        wallet.ErrWalletNameIsReserved,
+		}, {
+			name:       "parent directory",
+			walletName: "..",
+			err:        wallet.ErrWalletNameIsReserved,
+		}, {
+			name:       "relative path",
+			walletName: "../my-wallet",
+			err:        wallet.ErrWalletNameHasForbiddenCharacters,
+		}, {
+			name:       "windows path",
+			walletName: `..\my-wallet`,
+			err:        wallet.ErrWalletNameHasForbiddenCharacters,
+		}, {
+			name:       "name with colon",
+			walletName: "my:wallet",
+			err:        wallet.ErrWalletNameHasForbiddenCharacters,
+		}, {
+			name:       "name with control character",
+			walletName: "my\nwallet",
+			err:        wallet.ErrWalletNameHasForbiddenCharacters,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(tt *testing.T) {
+			// when
+			err := wallet.ValidateName(tc.walletName)
+
+			// then
+			assert.ErrorIs(tt, err, tc.err)
+		})
+	}
+}
@@ -1,20 +1,68 @@
 package v1
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
+	"sync"
 
 	vgcrypto "code.vegaprotocol.io/shared/libs/crypto"
 	vgfs "code.vegaprotocol.io/shared/libs/fs"
 	"code.vegaprotocol.io/vegawallet/wallet"
 )
 
+const (
+	// indexFileName is the name of the file mapping the wallet names to the
+	// files they are stored in. It's hidden so it's never mistaken for a
+	// wallet.
+	indexFileName = ".index.json"
+
+	// indexLockFileName is the name of the file locked while the index is
+	// modified.
+	indexLockFileName = ".index.lock"
+
+	indexVersion = 1
+
+	walletFileIDByteSize = 16
+
+	// minEncryptedWalletSize is the size of the nonce, the authentication tag
+	// and the salt surrounding an encrypted wallet. A smaller file can't be a
+	// wallet.
+	minEncryptedWalletSize = 12 + 16 + 32
+)
+
+var ErrStoreIsReadOnly = errors.New("the wallet store is read-only")
+
 type Store struct {
 	walletsHome string
+
+	// readOnly prevents the store from modifying the wallets folder, including
+	// from migrating the legacy wallets.
+	readOnly bool
+
+	// mu ensures the index is not read and written concurrently in the same
+	// process. The lock on the index lock file does the same across processes,
+	// like the command line and a running service.
+	mu sync.Mutex
+}
+
+// index maps the wallet names, as displayed to the user, to the generated file
+// names the wallets are stored in. This way, the user input is never used to
+// build a path.
+type index struct {
+	Version uint32            `json:"version"`
+	Wallets map[string]string `json:"wallets"`
+	// Migrations holds the legacy wallet files being moved to a generated file
+	// name. The entry is recorded before the file is moved, so an interrupted
+	// migration is resumed instead of leaving an orphan file behind.
+	Migrations map[string]string `json:"migrations,omitempty"`
 }
 
 func InitialiseStore(walletsHome string) (*Store, error) {
@@ -22,51 +70,273 @@ func InitialiseStore(walletsHome string) (*Store, error) {
 		return nil, fmt.Errorf("couldn't ensure directories at %s: %w", walletsHome, err)
 	}
 
+	s := &Store{
+		walletsHome: walletsHome,
+	}
+
+	if err := s.repairIndex(); err != nil {
+		return nil, fmt.Errorf("couldn't repair wallets index: %w", err)
+	}
+
+	return s, nil
+}
+
+// InitialiseReadOnlyStore builds a store that only reads the wallets folder,
+// as it is. The index is not repaired, and the legacy wallets are read from
+// their file without being migrated.
+func InitialiseReadOnlyStore(walletsHome string) *Store {
 	return &Store{
 		walletsHome: walletsHome,
-	}, nil
+		readOnly:    true,
+	}
+}
+
+// repairIndex resumes the interrupted migrations, and removes the index
+// entries pointing to a file that no longer exists.
+func (s *Store) repairIndex() error {
+	unlock, err := s.lockIndex()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	idx, err := s.readIndex()
+	if err != nil {
+		return err
+	}
+
+	repaired := len(idx.Migrations) > 0
+	if err := s.resumeMigrations(idx); err != nil {
+		return err
+	}
+
+	for name, fileName := range idx.Wallets {
+		if exists, _ := vgfs.FileExists(filepath.Join(s.walletsHome, fileName)); !exists {
+			delete(idx.Wallets, name)
+			repaired = true
+		}
+	}
+
+	if !repaired {
+		return nil
+	}
+
+	return s.writeIndex(idx)
+}
+
+// ListUnindexedFiles returns the files, located in the wallets home, that are
+// neither referenced by the index nor legacy wallets waiting to be migrated.
+func (s *Store) ListUnindexedFiles() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx, err := s.readIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := s.listUnindexedFiles(idx)
+	if err != nil {
+		return nil, err
+	}
+
+	unknownFiles := make([]string, 0, len(files))
+	for _, fileName := range files {
+		if !s.isLegacyWalletFile(fileName) {
+			unknownFiles = append(unknownFiles, fileName)
+		}
+	}
+	return unknownFiles, nil
 }
 
 func (s *Store) DeleteWallet(name string) error {
-	walletPath := s.walletPath(name)
+	unlock, err := s.lockIndex()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	idx, err := s.readIndex()
+	if err != nil {
+		return err
+	}
 
-	if exists, _ := vgfs.PathExists(walletPath); !exists {
-		return wallet.ErrWalletDoesNotExists
+	fileName, ok := idx.Wallets[name]
+	if !ok {
+		if isIndexedFile(idx, name) || !s.isLegacyWalletFile(name) {
+			return wallet.ErrWalletDoesNotExists
+		}
+		fileName = name
 	}
 
-	if err := os.Remove(walletPath); err != nil {
+	walletPath := filepath.Join(s.walletsHome, fileName)
+	if err := os.Remove(walletPath); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("couldn't remove wallet file at %s: %w", walletPath, err)
 	}
-	return nil
+
+	delete(idx.Wallets, name)
+
+	return s.writeIndex(idx)
 }
 
+// WalletExists also considers the legacy wallet files that haven't been
+// migrated yet, so a new wallet never shadows them.
 func (s *Store) WalletExists(name string) bool {
-	walletPath := s.walletPath(name)
+	walletPath, ok := s.lookUpWalletPath(name)
+	if !ok {
+		_, ok = s.lookUpLegacyWalletPath(name)
+		return ok
+	}
 
 	exists, _ := vgfs.PathExists(walletPath)
 	return exists
 }
 
+// ListWallets returns the indexed wallets, and the legacy wallets that haven't
+// been migrated yet, as they are only migrated once opened with their
+// passphrase.
 func (s *Store) ListWallets() ([]string, error) {
-	walletsParentDir, walletsDir := filepath.Split(s.walletsHome)
-	entries, err := fs.ReadDir(os.DirFS(walletsParentDir), walletsDir)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx, err := s.readIndex()
 	if err != nil {
-		return nil, fmt.Errorf("couldn't read directory at %s: %w", s.walletsHome, err)
+		return nil, err
 	}
-	wallets := make([]string, len(entries))
-	for i, entry := range entries {
-		wallets[i] = entry.Name()
+
+	files, err := s.listUnindexedFiles(idx)
+	if err != nil {
+		return nil, err
+	}
+
+	wallets := make([]string, 0, len(idx.Wallets)+len(files))
+	for name := range idx.Wallets {
+		wallets = append(wallets, name)
+	}
+	for _, fileName := range files {
+		if _, indexed := idx.Wallets[fileName]; !indexed && s.isLegacyWalletFile(fileName) {
+			wallets = append(wallets, fileName)
+		}
 	}
 	sort.Strings(wallets)
 	return wallets, nil
 }
 
 func (s *Store) GetWallet(name, passphrase string) (wallet.Wallet, error) {
-	buf, err := fs.ReadFile(os.DirFS(s.walletsHome), name)
+	walletPath, ok := s.lookUpWalletPath(name)
+	if !ok {
+		return s.migrateLegacyWallet(name, passphrase)
+	}
+
+	buf, err := vgfs.ReadFile(walletPath)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read file at %s: %w", walletPath, err)
+	}
+
+	return decodeWallet(name, buf, passphrase)
+}
+
+// migrateLegacyWallet registers in the index the wallet stored in a file named
+// after it, as done by previous versions of the software, and moves it to a
+// generated file name. The file is only migrated once it has been decrypted
+// as a wallet, so a stray file is never mistaken for one. A read-only store
+// only decrypts it.
+func (s *Store) migrateLegacyWallet(name, passphrase string) (wallet.Wallet, error) {
+	legacyPath, ok := s.lookUpLegacyWalletPath(name)
+	if !ok {
+		return nil, wallet.ErrWalletDoesNotExists
+	}
+
+	buf, err := vgfs.ReadFile(legacyPath)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read file at %s: %w", legacyPath, err)
+	}
+
+	w, err := decodeWallet(name, buf, passphrase)
 	if err != nil {
-		return nil, fmt.Errorf("couldn't read file at %s: %w", s.walletsHome, err)
+		return nil, err
+	}
+
+	if s.readOnly {
+		return w, nil
+	}
+
+	unlock, err := s.lockIndex()
+	if err != nil {
+		return nil, err
 	}
+	defer unlock()
+
+	idx, err := s.readIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	// Another process may have migrated the wallet in the meantime.
+	if _, indexed := idx.Wallets[name]; indexed {
+		return w, nil
+	}
+	if isIndexedFile(idx, name) {
+		return nil, wallet.ErrWalletDoesNotExists
+	}
+
+	fileName, err := generateFileName()
+	if err != nil {
+		return nil, err
+	}
+
+	idx.Migrations[name] = fileName
+	if err := s.writeIndex(idx); err != nil {
+		return nil, err
+	}
+
+	if err := s.resumeMigrations(idx); err != nil {
+		return nil, err
+	}
+
+	if err := s.writeIndex(idx); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// resumeMigrations moves the legacy wallet files recorded in the migration
+// journal to their generated file name, and registers them in the index. The
+// index still has to be written afterwards.
+func (s *Store) resumeMigrations(idx *index) error {
+	for name, fileName := range idx.Migrations {
+		legacyPath := filepath.Join(s.walletsHome, name)
+		newPath := filepath.Join(s.walletsHome, fileName)
+
+		moved, err := vgfs.FileExists(newPath)
+		if err != nil {
+			return fmt.Errorf("couldn't verify the existence of wallet file %s: %w", newPath, err)
+		}
+
+		if !moved {
+			legacyExists, err := vgfs.FileExists(legacyPath)
+			if err != nil {
+				return fmt.Errorf("couldn't verify the existence of wallet file %s: %w", legacyPath, err)
+			}
+			if !legacyExists {
+				delete(idx.Migrations, name)
+				continue
+			}
+
+			if err := os.Rename(legacyPath, newPath); err != nil {
+				return fmt.Errorf("couldn't move wallet file %s to %s: %w", legacyPath, newPath, err)
+			}
+		}
 
+		idx.Wallets[name] = fileName
+		delete(idx.Migrations, name)
+	}
+
+	return nil
+}
+
+func decodeWallet(name string, buf []byte, passphrase string) (wallet.Wallet, error) {
 	decBuf, err := vgcrypto.Decrypt(buf, passphrase)
 	if err != nil {
 		if err.Error() == "cipher: message authentication failed" {
@@ -95,13 +365,17 @@ func (s *Store) GetWallet(name, passphrase string) (wallet.Wallet, error) {
 	}
 
 	// The wallet name is not saved in the file to avoid de-synchronisation
-	// between file name and file content
+	// between the index and file content
 	w.SetName(name)
 
 	return w, nil
 }
 
 func (s *Store) SaveWallet(w wallet.Wallet, passphrase string) error {
+	if err := wallet.ValidateName(w.Name()); err != nil {
+		return err
+	}
+
 	buf, err := json.Marshal(w)
 	if err != nil {
 		return fmt.Errorf("couldn't marshal wallet: %w", err)
@@ -112,18 +386,279 @@ func (s *Store) SaveWallet(w wallet.Wallet, passphrase string) error {
 		return fmt.Errorf("couldn't encrypt wallet: %w", err)
 	}
 
-	walletPath := s.walletPath(w.Name())
-	err = vgfs.WriteFile(walletPath, encBuf)
+	unlock, err := s.lockIndex()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	idx, err := s.readIndex()
+	if err != nil {
+		return err
+	}
+
+	return s.writeWalletFile(idx, w.Name(), encBuf)
+}
+
+// GetRawWallet returns the content of the wallet file, as stored on disk. It's
+// still encrypted with the wallet passphrase. The legacy wallets are read from
+// their file, without being migrated.
+func (s *Store) GetRawWallet(name string) ([]byte, error) {
+	walletPath, ok := s.lookUpWalletPath(name)
+	if !ok {
+		walletPath, ok = s.lookUpLegacyWalletPath(name)
+	}
+	if !ok {
+		return nil, wallet.ErrWalletDoesNotExists
+	}
+
+	buf, err := vgfs.ReadFile(walletPath)
 	if err != nil {
+		return nil, fmt.Errorf("couldn't read file at %s: %w", walletPath, err)
+	}
+
+	return buf, nil
+}
+
+// SaveRawWallet saves the content of a wallet file, as returned by
+// GetRawWallet, under the specified name. If a wallet with the same name
+// already exists, it's overwritten.
+func (s *Store) SaveRawWallet(name string, buf []byte) error {
+	if err := wallet.ValidateName(name); err != nil {
+		return err
+	}
+
+	unlock, err := s.lockIndex()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	idx, err := s.readIndex()
+	if err != nil {
+		return err
+	}
+
+	return s.writeWalletFile(idx, name, buf)
+}
+
+// GetWalletPath returns the path of the file the wallet is stored in. If the
+// wallet doesn't exist, it returns an empty string.
+func (s *Store) GetWalletPath(name string) string {
+	if walletPath, ok := s.lookUpWalletPath(name); ok {
+		return walletPath
+	}
+	walletPath, _ := s.lookUpLegacyWalletPath(name)
+	return walletPath
+}
+
+// GetWalletsPath returns the path of the directory the wallets are stored in.
+func (s *Store) GetWalletsPath() string {
+	return s.walletsHome
+}
+
+func (s *Store) lookUpWalletPath(name string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx, err := s.readIndex()
+	if err != nil {
+		return "", false
+	}
+
+	fileName, ok := idx.Wallets[name]
+	if !ok {
+		return "", false
+	}
+	return filepath.Join(s.walletsHome, fileName), true
+}
+
+// lookUpLegacyWalletPath returns the path of the file named after the wallet,
+// if it can be a legacy wallet and is not referenced by the index.
+func (s *Store) lookUpLegacyWalletPath(name string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx, err := s.readIndex()
+	if err != nil || isIndexedFile(idx, name) || !s.isLegacyWalletFile(name) {
+		return "", false
+	}
+
+	return filepath.Join(s.walletsHome, name), true
+}
+
+// isLegacyWalletFile tells if the file, located in the wallets home, can be a
+// wallet stored by previous versions of the software: it's named after a valid
+// wallet name, and is large enough to hold an encrypted wallet. Its content
+// can only be verified with the passphrase.
+func (s *Store) isLegacyWalletFile(fileName string) bool {
+	if err := wallet.ValidateName(fileName); err != nil || strings.HasPrefix(fileName, ".") {
+		return false
+	}
+
+	info, err := os.Stat(filepath.Join(s.walletsHome, fileName))
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	return info.Size() >= minEncryptedWalletSize
+}
+
+func (s *Store) writeWalletFile(idx *index, name string, buf []byte) error {
+	fileName, indexed := idx.Wallets[name]
+	if !indexed {
+		var err error
+		fileName, err = generateFileName()
+		if err != nil {
+			return err
+		}
+	}
+
+	walletPath := filepath.Join(s.walletsHome, fileName)
+	if err := vgfs.WriteFile(walletPath, buf); err != nil {
 		return fmt.Errorf("couldn't write wallet file at %s: %w", walletPath, err)
 	}
+
+	if indexed {
+		return nil
+	}
+
+	idx.Wallets[name] = fileName
+	return s.writeIndex(idx)
+}
+
+func (s *Store) listUnindexedFiles(idx *index) ([]string, error) {
+	walletsParentDir, walletsDir := filepath.Split(s.walletsHome)
+	entries, err := fs.ReadDir(os.DirFS(walletsParentDir), walletsDir)
+	if errors.Is(err, fs.ErrNotExist) && s.readOnly {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read directory at %s: %w", s.walletsHome, err)
+	}
+
+	files := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if isIndexedFile(idx, entry.Name()) {
+			continue
+		}
+		files = append(files, entry.Name())
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// isIndexedFile tells if the file is referenced by the index, including by an
+// ongoing migration.
+func isIndexedFile(idx *index, fileName string) bool {
+	for _, indexedFileName := range idx.Wallets {
+		if indexedFileName == fileName {
+			return true
+		}
+	}
+	for _, migratedFileName := range idx.Migrations {
+		if migratedFileName == fileName {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Store) readIndex() (*index, error) {
+	idx := &index{
+		Version:    indexVersion,
+		Wallets:    map[string]string{},
+		Migrations: map[string]string{},
+	}
+
+	indexPath := s.indexPath()
+	exists, err := vgfs.FileExists(indexPath)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't verify the existence of the wallets index at %s: %w", indexPath, err)
+	}
+	if !exists {
+		return idx, nil
+	}
+
+	buf, err := vgfs.ReadFile(indexPath)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read wallets index at %s: %w", indexPath, err)
+	}
+
+	if err := json.Unmarshal(buf, idx); err != nil {
+		return nil, fmt.Errorf("couldn't unmarshal wallets index: %w", err)
+	}
+
+	if idx.Wallets == nil {
+		idx.Wallets = map[string]string{}
+	}
+	if idx.Migrations == nil {
+		idx.Migrations = map[string]string{}
+	}
+
+	return idx, nil
+}
+
+// writeIndex writes the index in a temporary file first, and then replaces
+// the previous one, so a crash never leaves a partially written index.
+func (s *Store) writeIndex(idx *index) error {
+	buf, err := json.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("couldn't marshal wallets index: %w", err)
+	}
+
+	indexPath := s.indexPath()
+	tmpIndexPath := indexPath + ".tmp"
+	if err := vgfs.WriteFile(tmpIndexPath, buf); err != nil {
+		return fmt.Errorf("couldn't write wallets index at %s: %w", tmpIndexPath, err)
+	}
+
+	if err := os.Rename(tmpIndexPath, indexPath); err != nil {
+		return fmt.Errorf("couldn't replace wallets index at %s: %w", indexPath, err)
+	}
+
 	return nil
 }
 
-func (s *Store) GetWalletPath(name string) string {
-	return s.walletPath(name)
+// lockIndex prevents the index from being modified concurrently, by this
+// process or by another one. The returned function releases the lock.
+func (s *Store) lockIndex() (func(), error) {
+	if s.readOnly {
+		return nil, ErrStoreIsReadOnly
+	}
+
+	s.mu.Lock()
+
+	lockPath := filepath.Join(s.walletsHome, indexLockFileName)
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("couldn't open wallets index lock at %s: %w", lockPath, err)
+	}
+
+	if err := lockFile(f); err != nil {
+		_ = f.Close()
+		s.mu.Unlock()
+		return nil, fmt.Errorf("couldn't lock wallets index at %s: %w", lockPath, err)
+	}
+
+	return func() {
+		_ = unlockFile(f)
+		_ = f.Close()
+		s.mu.Unlock()
+	}, nil
 }
 
-func (s *Store) walletPath(name string) string {
-	return filepath.Join(s.walletsHome, name)
+func (s *Store) indexPath() string {
+	return filepath.Join(s.walletsHome, indexFileName)
+}
+
+func generateFileName() (string, error) {
+	id := make([]byte, walletFileIDByteSize)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("couldn't generate wallet file name: %w", err)
+	}
+	return hex.EncodeToString(id), nil
 }
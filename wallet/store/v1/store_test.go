@@ -28,6 +28,13 @@ func TestFileStoreV1(t *testing.T) {
 	t.Run("Verifying non-existing wallet fails", testFileStoreV1NonExistingWalletFails)
 	t.Run("Verifying existing wallet succeeds", testFileStoreV1ExistingWalletSucceeds)
 	t.Run("Saving HD wallet succeeds", testFileStoreV1SaveHDWalletSucceeds)
+	t.Run("Saving wallet with invalid name fails", testFileStoreV1SaveWalletWithInvalidNameFails)
+	t.Run("Deleting wallet succeeds", testFileStoreV1DeleteWalletSucceeds)
+	t.Run("Copying raw wallet succeeds", testFileStoreV1CopyingRawWalletSucceeds)
+	t.Run("Migrating legacy wallets succeeds", testFileStoreV1MigratingLegacyWalletsSucceeds)
+	t.Run("Resuming interrupted migration succeeds", testFileStoreV1ResumingInterruptedMigrationSucceeds)
+	t.Run("Read-only store does not migrate legacy wallets", testFileStoreV1ReadOnlyStoreDoesNotMigrateLegacyWallets)
+	t.Run("Reading legacy wallets before their migration succeeds", testFileStoreV1ReadingLegacyWalletsBeforeMigrationSucceeds)
 }
 
 func testInitialisingStoreSucceeds(t *testing.T) {
@@ -135,13 +142,27 @@ func testFileStoreV1GetWalletPathSucceeds(t *testing.T) {
 
 	// given
 	s := initialiseStore(t, walletsDir)
-	name := vgrand.RandomStr(5)
+	w := newHDWalletWithKeys(t)
+	passphrase := vgrand.RandomStr(5)
+
+	// when
+	path := s.GetWalletPath(w.Name())
+
+	// then
+	assert.Empty(t, path)
+
+	// when
+	err := s.SaveWallet(w, passphrase)
+
+	// then
+	require.NoError(t, err)
 
 	// when
-	path := s.GetWalletPath(name)
+	path = s.GetWalletPath(w.Name())
 
 	// then
-	assert.Equal(t, filepath.Join(walletsDir, name), path)
+	assert.Equal(t, walletsDir, filepath.Dir(path))
+	assert.NotEqual(t, w.Name(), filepath.Base(path))
 }
 
 func testFileStoreV1NonExistingWalletFails(t *testing.T) {
@@ -192,15 +213,272 @@ func testFileStoreV1SaveHDWalletSucceeds(t *testing.T) {
 
 	// then
 	require.NoError(t, err)
-	vgtest.AssertFileAccess(t, filepath.Join(walletsDir, w.Name()))
+	walletPath := s.GetWalletPath(w.Name())
+	vgtest.AssertFileAccess(t, walletPath)
 
-	buf, err := ioutil.ReadFile(filepath.Join(walletsDir, w.Name()))
+	buf, err := ioutil.ReadFile(walletPath)
 	if err != nil {
 		t.Fatalf("couldn't read wallet file: %v", w.Name())
 	}
 	assert.NotEmpty(t, buf)
 }
 
+func testFileStoreV1SaveWalletWithInvalidNameFails(t *testing.T) {
+	walletsDir := newWalletsDir(t)
+
+	// given
+	passphrase := vgrand.RandomStr(5)
+	s := initialiseStore(t, walletsDir)
+	w := newHDWalletWithKeys(t)
+	w.SetName("../" + w.Name())
+
+	// when
+	err := s.SaveWallet(w, passphrase)
+
+	// then
+	require.ErrorIs(t, err, wallet.ErrWalletNameHasForbiddenCharacters)
+	assert.False(t, s.WalletExists(w.Name()))
+	vgtest.AssertNoFile(t, filepath.Join(walletsDir, w.Name()))
+}
+
+func testFileStoreV1DeleteWalletSucceeds(t *testing.T) {
+	walletsDir := newWalletsDir(t)
+
+	// given
+	passphrase := vgrand.RandomStr(5)
+	s := initialiseStore(t, walletsDir)
+	w := newHDWalletWithKeys(t)
+
+	// when
+	err := s.SaveWallet(w, passphrase)
+
+	// then
+	require.NoError(t, err)
+	walletPath := s.GetWalletPath(w.Name())
+
+	// when
+	err = s.DeleteWallet(w.Name())
+
+	// then
+	require.NoError(t, err)
+	assert.False(t, s.WalletExists(w.Name()))
+	vgtest.AssertNoFile(t, walletPath)
+
+	// when
+	err = s.DeleteWallet(w.Name())
+
+	// then
+	require.ErrorIs(t, err, wallet.ErrWalletDoesNotExists)
+}
+
+func testFileStoreV1CopyingRawWalletSucceeds(t *testing.T) {
+	// given
+	passphrase := vgrand.RandomStr(5)
+	s := initialiseStore(t, newWalletsDir(t))
+	otherStore := initialiseStore(t, newWalletsDir(t))
+	w := newHDWalletWithKeys(t)
+	err := s.SaveWallet(w, passphrase)
+	require.NoError(t, err)
+
+	// when
+	buf, err := s.GetRawWallet(w.Name())
+
+	// then
+	require.NoError(t, err)
+	assert.NotEmpty(t, buf)
+
+	// when
+	err = otherStore.SaveRawWallet(w.Name(), buf)
+
+	// then
+	require.NoError(t, err)
+	copiedWallet, err := otherStore.GetWallet(w.Name(), passphrase)
+	require.NoError(t, err)
+	assert.Equal(t, w, copiedWallet)
+
+	// when
+	buf, err = s.GetRawWallet(vgrand.RandomStr(5))
+
+	// then
+	require.ErrorIs(t, err, wallet.ErrWalletDoesNotExists)
+	assert.Nil(t, buf)
+}
+
+func testFileStoreV1MigratingLegacyWalletsSucceeds(t *testing.T) {
+	walletsDir := newWalletsDir(t)
+
+	// given
+	passphrase := vgrand.RandomStr(5)
+	s := initialiseStore(t, walletsDir)
+	w := newHDWalletWithKeys(t)
+
+	// when
+	err := s.SaveWallet(w, passphrase)
+
+	// then
+	require.NoError(t, err)
+
+	// given
+	legacyName := "legacy-" + vgrand.RandomStr(5)
+	strayName := "stray-" + vgrand.RandomStr(5)
+	invalidName := "invalid:" + vgrand.RandomStr(5)
+	legacyPath := filepath.Join(walletsDir, legacyName)
+	strayPath := filepath.Join(walletsDir, strayName)
+	invalidPath := filepath.Join(walletsDir, invalidName)
+	require.NoError(t, os.Rename(s.GetWalletPath(w.Name()), legacyPath))
+	require.NoError(t, ioutil.WriteFile(strayPath, []byte("not a wallet"), 0o600))
+	require.NoError(t, ioutil.WriteFile(invalidPath, []byte("not a wallet"), 0o600))
+
+	// when
+	s = initialiseStore(t, walletsDir)
+
+	// then
+	returnedWallets, err := s.ListWallets()
+	require.NoError(t, err)
+	assert.Equal(t, []string{legacyName}, returnedWallets)
+	vgtest.AssertFileAccess(t, legacyPath)
+	assert.True(t, s.WalletExists(legacyName))
+	assert.False(t, s.WalletExists(strayName))
+
+	// when
+	returnedWallet, err := s.GetWallet(strayName, passphrase)
+
+	// then
+	require.ErrorIs(t, err, wallet.ErrWalletDoesNotExists)
+	assert.Nil(t, returnedWallet)
+	vgtest.AssertFileAccess(t, strayPath)
+
+	// when
+	returnedWallet, err = s.GetWallet(legacyName, passphrase)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, w.ID(), returnedWallet.ID())
+	assert.Equal(t, legacyName, returnedWallet.Name())
+	vgtest.AssertNoFile(t, legacyPath)
+	returnedWallets, err = s.ListWallets()
+	require.NoError(t, err)
+	assert.Equal(t, []string{legacyName}, returnedWallets)
+
+	// when
+	unindexedFiles, err := s.ListUnindexedFiles()
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{invalidName, strayName}, unindexedFiles)
+}
+
+func testFileStoreV1ResumingInterruptedMigrationSucceeds(t *testing.T) {
+	walletsDir := newWalletsDir(t)
+
+	// given
+	passphrase := vgrand.RandomStr(5)
+	s := initialiseStore(t, walletsDir)
+	w := newHDWalletWithKeys(t)
+	require.NoError(t, s.SaveWallet(w, passphrase))
+	legacyName := "legacy-" + vgrand.RandomStr(5)
+	legacyPath := filepath.Join(walletsDir, legacyName)
+	require.NoError(t, os.Rename(s.GetWalletPath(w.Name()), legacyPath))
+
+	// setup
+	// The migration has been recorded in the index, but the process stopped
+	// before the file could be moved.
+	fileName := "0123456789abcdef0123456789abcdef"
+	journal := fmt.Sprintf(`{"version":1,"wallets":{},"migrations":{%q:%q}}`, legacyName, fileName)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(walletsDir, ".index.json"), []byte(journal), 0o600))
+
+	// when
+	s = initialiseStore(t, walletsDir)
+
+	// then
+	vgtest.AssertNoFile(t, legacyPath)
+	assert.Equal(t, filepath.Join(walletsDir, fileName), s.GetWalletPath(legacyName))
+	returnedWallet, err := s.GetWallet(legacyName, passphrase)
+	require.NoError(t, err)
+	assert.Equal(t, w.ID(), returnedWallet.ID())
+	unindexedFiles, err := s.ListUnindexedFiles()
+	require.NoError(t, err)
+	assert.Empty(t, unindexedFiles)
+}
+
+func testFileStoreV1ReadOnlyStoreDoesNotMigrateLegacyWallets(t *testing.T) {
+	walletsDir := newWalletsDir(t)
+
+	// given
+	passphrase := vgrand.RandomStr(5)
+	s := initialiseStore(t, walletsDir)
+	w := newHDWalletWithKeys(t)
+	require.NoError(t, s.SaveWallet(w, passphrase))
+	legacyName := "legacy-" + vgrand.RandomStr(5)
+	legacyPath := filepath.Join(walletsDir, legacyName)
+	require.NoError(t, os.Rename(s.GetWalletPath(w.Name()), legacyPath))
+
+	// when
+	readOnlyStore := storev1.InitialiseReadOnlyStore(walletsDir)
+
+	// then
+	unindexedFiles, err := readOnlyStore.ListUnindexedFiles()
+	require.NoError(t, err)
+	assert.Empty(t, unindexedFiles)
+	returnedWallets, err := readOnlyStore.ListWallets()
+	require.NoError(t, err)
+	assert.Equal(t, []string{legacyName}, returnedWallets)
+
+	// when
+	returnedWallet, err := readOnlyStore.GetWallet(legacyName, passphrase)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, w.ID(), returnedWallet.ID())
+	vgtest.AssertFileAccess(t, legacyPath)
+
+	// when
+	err = readOnlyStore.SaveWallet(newHDWalletWithKeys(t), passphrase)
+
+	// then
+	require.ErrorIs(t, err, storev1.ErrStoreIsReadOnly)
+}
+
+func testFileStoreV1ReadingLegacyWalletsBeforeMigrationSucceeds(t *testing.T) {
+	walletsDir := newWalletsDir(t)
+
+	// given
+	passphrase := vgrand.RandomStr(5)
+	s := initialiseStore(t, walletsDir)
+	w := newHDWalletWithKeys(t)
+	require.NoError(t, s.SaveWallet(w, passphrase))
+	content, err := s.GetRawWallet(w.Name())
+	require.NoError(t, err)
+	legacyName := "legacy-" + vgrand.RandomStr(5)
+	legacyPath := filepath.Join(walletsDir, legacyName)
+	require.NoError(t, os.Rename(s.GetWalletPath(w.Name()), legacyPath))
+
+	// when
+	s = initialiseStore(t, walletsDir)
+
+	// then
+	returnedWallets, err := s.ListWallets()
+	require.NoError(t, err)
+	assert.Equal(t, []string{legacyName}, returnedWallets)
+	assert.Equal(t, legacyPath, s.GetWalletPath(legacyName))
+
+	// when
+	returnedContent, err := s.GetRawWallet(legacyName)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, content, returnedContent)
+	vgtest.AssertFileAccess(t, legacyPath)
+
+	// when
+	err = s.DeleteWallet(legacyName)
+
+	// then
+	require.NoError(t, err)
+	vgtest.AssertNoFile(t, legacyPath)
+	assert.False(t, s.WalletExists(legacyName))
+}
+
 func initialiseStore(t *testing.T, walletsDir string) *storev1.Store {
 	t.Helper()
 	s, err := storev1.InitialiseStore(walletsDir)
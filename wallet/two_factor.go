@@ -0,0 +1,184 @@
+package wallet
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// TOTPPeriod is the duration a TOTP code is valid for, as expected by the
+	// authenticator applications.
+	TOTPPeriod = 30 * time.Second
+	// TOTPDigits is the number of digits of a TOTP code.
+	TOTPDigits = 6
+	// RecoveryCodesCount is the number of recovery codes issued at enrolment.
+	RecoveryCodesCount = 10
+
+	// totpSecretSize is the size of the TOTP secret, in bytes, as recommended
+	// by RFC 4226.
+	totpSecretSize = 20
+	// totpSkew is the number of periods, before and after the current one,
+	// whose codes are still accepted, to cope with clock drift.
+	totpSkew   = 1
+	totpIssuer = "Vega Wallet"
+)
+
+var base32NoPadding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TwoFactor holds the TOTP secret of a wallet, and the hashes of its unused
+// recovery codes. It is stored in the wallet file, so it is encrypted with the
+// wallet passphrase.
+type TwoFactor struct {
+	// Secret is the base32-encoded TOTP secret.
+	Secret string `json:"secret"`
+	// RecoveryCodes holds the SHA-256 hashes of the recovery codes that haven't
+	// been used yet. A recovery code can only be used once.
+	RecoveryCodes []string `json:"recoveryCodes"`
+	// LastUsedStep is the time step of the last accepted TOTP code. The codes
+	// of this step, and of the previous ones, are refused, so a code can't be
+	// replayed while it's still valid.
+	LastUsedStep uint64 `json:"lastUsedStep,omitempty"`
+}
+
+// NewTwoFactor generates a TOTP secret, and the recovery codes. The recovery
+// codes are returned in clear, as only their hashes are kept.
+func NewTwoFactor() (*TwoFactor, []string, error) {
+	secret := make([]byte, totpSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, nil, fmt.Errorf("couldn't generate the TOTP secret: %w", err)
+	}
+
+	tf := &TwoFactor{
+		Secret:        base32NoPadding.EncodeToString(secret),
+		RecoveryCodes: make([]string, 0, RecoveryCodesCount),
+	}
+
+	codes := make([]string, 0, RecoveryCodesCount)
+	for i := 0; i < RecoveryCodesCount; i++ {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, nil, err
+		}
+		codes = append(codes, code)
+		tf.RecoveryCodes = append(tf.RecoveryCodes, hashRecoveryCode(code))
+	}
+
+	return tf, codes, nil
+}
+
+// ProvisioningURI returns the URI to register the secret in an authenticator
+// application, usually through a QR code.
+func (t *TwoFactor) ProvisioningURI(walletName string) string {
+	params := url.Values{}
+	params.Set("secret", t.Secret)
+	params.Set("issuer", totpIssuer)
+	params.Set("digits", fmt.Sprintf("%d", TOTPDigits))
+	params.Set("period", fmt.Sprintf("%d", int(TOTPPeriod.Seconds())))
+
+	label := url.PathEscape(fmt.Sprintf("%s:%s", totpIssuer, walletName))
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, params.Encode())
+}
+
+// Verify checks the code is either a current TOTP code that hasn't been used
+// yet, or an unused recovery code. The step of the TOTP code is recorded, and
+// a recovery code is removed once used, so neither can be used again. The
+// wallet then has to be saved, and true is returned.
+func (t *TwoFactor) Verify(code string, now time.Time) (bool, error) {
+	code = strings.TrimSpace(code)
+	if len(code) == 0 {
+		return false, ErrTwoFactorCodeRequired
+	}
+
+	for skew := -totpSkew; skew <= totpSkew; skew++ {
+		at := now.Add(time.Duration(skew) * TOTPPeriod)
+		expected, err := TOTPCode(t.Secret, at)
+		if err != nil {
+			return false, err
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			step := totpStep(at)
+			if step <= t.LastUsedStep {
+				return false, ErrTwoFactorCodeAlreadyUsed
+			}
+			t.LastUsedStep = step
+			return true, nil
+		}
+	}
+
+	hash := hashRecoveryCode(code)
+	for i, recoveryCode := range t.RecoveryCodes {
+		if subtle.ConstantTimeCompare([]byte(recoveryCode), []byte(hash)) == 1 {
+			t.RecoveryCodes = append(t.RecoveryCodes[:i], t.RecoveryCodes[i+1:]...)
+			return true, nil
+		}
+	}
+
+	return false, ErrInvalidTwoFactorCode
+}
+
+// TOTPCode computes the TOTP code of the secret at the specified time, as
+// described by RFC 6238.
+func TOTPCode(secret string, at time.Time) (string, error) {
+	key, err := base32NoPadding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("couldn't decode the TOTP secret: %w", err)
+	}
+
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, totpStep(at))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < TOTPDigits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", TOTPDigits, value%mod), nil
+}
+
+// CheckTwoFactorCode verifies the code when the wallet has the two-factor
+// authentication enabled. It returns true when a code has been accepted, in
+// which case the wallet has to be saved so the code can't be used again.
+func CheckTwoFactorCode(w Wallet, code string) (bool, error) {
+	tf := w.TwoFactor()
+	if tf == nil {
+		return false, nil
+	}
+
+	return tf.Verify(code, time.Now())
+}
+
+// totpStep returns the number of TOTP periods elapsed since the Unix epoch.
+func totpStep(at time.Time) uint64 {
+	return uint64(at.Unix() / int64(TOTPPeriod.Seconds()))
+}
+
+func newRecoveryCode() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("couldn't generate a recovery code: %w", err)
+	}
+	code := hex.EncodeToString(buf)
+	return code[:8] + "-" + code[8:], nil
+}
+
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(code))))
+	return hex.EncodeToString(sum[:])
+}
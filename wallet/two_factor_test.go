@@ -0,0 +1,197 @@
+package wallet_test
+
+import (
+	"encoding/base32"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"code.vegaprotocol.io/vegawallet/wallet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTwoFactor(t *testing.T) {
+	t.Run("Computing TOTP code matches RFC 6238", testComputingTOTPCodeMatchesRFC6238)
+	t.Run("Verifying current code succeeds", testVerifyingCurrentCodeSucceeds)
+	t.Run("Verifying code of previous period succeeds", testVerifyingCodeOfPreviousPeriodSucceeds)
+	t.Run("Verifying already used code fails", testVerifyingAlreadyUsedCodeFails)
+	t.Run("Verifying outdated code fails", testVerifyingOutdatedCodeFails)
+	t.Run("Verifying empty code fails", testVerifyingEmptyCodeFails)
+	t.Run("Recovery code can only be used once", testRecoveryCodeCanOnlyBeUsedOnce)
+	t.Run("Two-factor settings are saved with the wallet", testTwoFactorSettingsAreSavedWithWallet)
+	t.Run("Isolated wallet only keeps the TOTP secret", testIsolatedWalletOnlyKeepsTOTPSecret)
+}
+
+func testComputingTOTPCodeMatchesRFC6238(t *testing.T) {
+	// given
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+	// when
+	code, err := wallet.TOTPCode(secret, time.Unix(59, 0))
+
+	// then
+	require.NoError(t, err)
+	// The RFC uses 8 digits: 94287082.
+	assert.Equal(t, "287082", code)
+
+	// when
+	code, err = wallet.TOTPCode(secret, time.Unix(1111111109, 0))
+
+	// then
+	require.NoError(t, err)
+	// The RFC uses 8 digits: 07081804.
+	assert.Equal(t, "081804", code)
+}
+
+func testVerifyingCurrentCodeSucceeds(t *testing.T) {
+	// given
+	tf, _, err := wallet.NewTwoFactor()
+	require.NoError(t, err)
+	now := time.Now()
+	code, err := wallet.TOTPCode(tf.Secret, now)
+	require.NoError(t, err)
+
+	// when
+	codeAccepted, err := tf.Verify(code, now)
+
+	// then
+	require.NoError(t, err)
+	assert.True(t, codeAccepted)
+	assert.Len(t, tf.RecoveryCodes, wallet.RecoveryCodesCount)
+}
+
+func testVerifyingCodeOfPreviousPeriodSucceeds(t *testing.T) {
+	// given
+	tf, _, err := wallet.NewTwoFactor()
+	require.NoError(t, err)
+	now := time.Now()
+	code, err := wallet.TOTPCode(tf.Secret, now.Add(-wallet.TOTPPeriod))
+	require.NoError(t, err)
+
+	// when
+	_, err = tf.Verify(code, now)
+
+	// then
+	require.NoError(t, err)
+}
+
+func testVerifyingAlreadyUsedCodeFails(t *testing.T) {
+	// given
+	tf, _, err := wallet.NewTwoFactor()
+	require.NoError(t, err)
+	now := time.Now()
+	code, err := wallet.TOTPCode(tf.Secret, now)
+	require.NoError(t, err)
+	previousCode, err := wallet.TOTPCode(tf.Secret, now.Add(-wallet.TOTPPeriod))
+	require.NoError(t, err)
+
+	// when
+	_, err = tf.Verify(code, now)
+
+	// then
+	require.NoError(t, err)
+
+	// when
+	codeAccepted, err := tf.Verify(code, now.Add(wallet.TOTPPeriod))
+
+	// then
+	require.ErrorIs(t, err, wallet.ErrTwoFactorCodeAlreadyUsed)
+	assert.False(t, codeAccepted)
+
+	// when
+	_, err = tf.Verify(previousCode, now)
+
+	// then
+	require.ErrorIs(t, err, wallet.ErrTwoFactorCodeAlreadyUsed)
+}
+
+func testVerifyingOutdatedCodeFails(t *testing.T) {
+	// given
+	tf, _, err := wallet.NewTwoFactor()
+	require.NoError(t, err)
+	now := time.Now()
+	code, err := wallet.TOTPCode(tf.Secret, now.Add(-5*wallet.TOTPPeriod))
+	require.NoError(t, err)
+
+	// when
+	_, err = tf.Verify(code, now)
+
+	// then
+	require.ErrorIs(t, err, wallet.ErrInvalidTwoFactorCode)
+}
+
+func testVerifyingEmptyCodeFails(t *testing.T) {
+	// given
+	tf, _, err := wallet.NewTwoFactor()
+	require.NoError(t, err)
+
+	// when
+	_, err = tf.Verify("", time.Now())
+
+	// then
+	require.ErrorIs(t, err, wallet.ErrTwoFactorCodeRequired)
+}
+
+func testRecoveryCodeCanOnlyBeUsedOnce(t *testing.T) {
+	// given
+	tf, recoveryCodes, err := wallet.NewTwoFactor()
+	require.NoError(t, err)
+	require.Len(t, recoveryCodes, wallet.RecoveryCodesCount)
+
+	// when
+	recoveryCodeUsed, err := tf.Verify(recoveryCodes[3], time.Now())
+
+	// then
+	require.NoError(t, err)
+	assert.True(t, recoveryCodeUsed)
+	assert.Len(t, tf.RecoveryCodes, wallet.RecoveryCodesCount-1)
+
+	// when
+	_, err = tf.Verify(recoveryCodes[3], time.Now())
+
+	// then
+	require.ErrorIs(t, err, wallet.ErrInvalidTwoFactorCode)
+}
+
+func testTwoFactorSettingsAreSavedWithWallet(t *testing.T) {
+	// given
+	w := newWallet(t)
+	tf, _, err := wallet.NewTwoFactor()
+	require.NoError(t, err)
+	w.SetTwoFactor(tf)
+
+	// when
+	buf, err := json.Marshal(w)
+	require.NoError(t, err)
+	loadedWallet := &wallet.HDWallet{}
+	err = json.Unmarshal(buf, loadedWallet)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, tf, loadedWallet.TwoFactor())
+}
+
+func testIsolatedWalletOnlyKeepsTOTPSecret(t *testing.T) {
+	// given
+	w := newWalletWithKey(t)
+	tf, recoveryCodes, err := wallet.NewTwoFactor()
+	require.NoError(t, err)
+	w.SetTwoFactor(tf)
+
+	// when
+	isolatedWallet, err := w.IsolateWithKey(w.ListPublicKeys()[0].Key())
+
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, isolatedWallet.TwoFactor())
+	assert.Equal(t, tf.Secret, isolatedWallet.TwoFactor().Secret)
+	assert.Empty(t, isolatedWallet.TwoFactor().RecoveryCodes)
+	assert.Len(t, tf.RecoveryCodes, wallet.RecoveryCodesCount)
+
+	// when
+	_, err = isolatedWallet.TwoFactor().Verify(recoveryCodes[0], time.Now())
+
+	// then
+	require.ErrorIs(t, err, wallet.ErrInvalidTwoFactorCode)
+}
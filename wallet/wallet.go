@@ -19,6 +19,9 @@ type Wallet interface {
 	VerifyAny(pubKey string, data, sig []byte) (bool, error)
 	SignTx(pubKey string, data []byte) (*Signature, error)
 	IsolateWithKey(pubKey string) (Wallet, error)
+	Diagnose() []error
+	TwoFactor() *TwoFactor
+	SetTwoFactor(tf *TwoFactor)
 }
 
 type KeyPair interface {
@@ -1,9 +1,13 @@
 package wallets
 
 import (
+	"crypto/sha256"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"code.vegaprotocol.io/protos/commands"
 	commandspb "code.vegaprotocol.io/protos/vega/commands/v1"
@@ -13,7 +17,10 @@ import (
 	"code.vegaprotocol.io/vegawallet/wallet"
 )
 
-var ErrWalletDoesNotExists = errors.New("wallet does not exist")
+var (
+	ErrWalletDoesNotExists   = errors.New("wallet does not exist")
+	ErrPassphraseIsNotCached = errors.New("the wallet passphrase is not cached, so the wallet can't be reloaded")
+)
 
 // Store abstracts the underlying storage for wallet data.
 type Store interface {
@@ -21,13 +28,43 @@ type Store interface {
 	SaveWallet(w wallet.Wallet, passphrase string) error
 	GetWallet(name, passphrase string) (wallet.Wallet, error)
 	GetWalletPath(name string) string
+	GetRawWallet(name string) ([]byte, error)
 	ListWallets() ([]string, error)
 }
 
+// ReloadedWallet describes what happened to a logged wallet whose file has
+// been changed outside the handler.
+type ReloadedWallet struct {
+	Name string
+	// LoggedOut is true when the wallet couldn't be reloaded, and has been
+	// logged out instead, so a stale version of the wallet is never used.
+	LoggedOut bool
+	// Reason explains why the wallet has been logged out.
+	Reason error
+}
+
 type Handler struct {
 	store         Store
 	loggedWallets wallets
 
+	// handles holds the wallet each handle gives access to. A handle is held
+	// by a session, so a wallet stays unlocked as long as one of its sessions
+	// uses it, and the key material is shared between them.
+	handles map[string]string
+
+	// pinnedHandles holds the handles exempting their wallet from the idle
+	// locking, as their clients can't unlock it.
+	pinnedHandles map[string]struct{}
+
+	// fingerprints holds a hash of the files of the logged wallets, as they
+	// were when loaded, to detect the changes made outside the handler.
+	fingerprints map[string][sha256.Size]byte
+
+	// passphrases holds the passphrases of the logged wallets, only when
+	// passphrase caching is enabled.
+	passphrases      map[string]string
+	cachePassphrases bool
+
 	// just to make sure we do not access same file concurrently or the map
 	mu sync.RWMutex
 }
@@ -36,9 +73,33 @@ func NewHandler(store Store) *Handler {
 	return &Handler{
 		store:         store,
 		loggedWallets: newWallets(),
+		handles:       map[string]string{},
+		pinnedHandles: map[string]struct{}{},
+		fingerprints:  map[string][sha256.Size]byte{},
+		passphrases:   map[string]string{},
 	}
 }
 
+// EnablePassphraseCaching keeps the passphrases of the logged wallets in
+// memory, so the wallets changed on disk can be decrypted again, and reloaded.
+// Without it, these wallets are logged out.
+func (h *Handler) EnablePassphraseCaching() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.cachePassphrases = true
+}
+
+// CachedPassphrase returns the passphrase of the specified logged wallet, if
+// passphrase caching is enabled.
+func (h *Handler) CachedPassphrase(name string) (string, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	passphrase, ok := h.passphrases[name]
+	return passphrase, ok
+}
+
 func (h *Handler) WalletExists(name string) bool {
 	h.mu.Lock()
 	defer h.mu.Unlock()
@@ -54,6 +115,10 @@ func (h *Handler) ListWallets() ([]string, error) {
 }
 
 func (h *Handler) CreateWallet(name, passphrase string) (string, error) {
+	if err := wallet.ValidateName(name); err != nil {
+		return "", err
+	}
+
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
@@ -70,11 +135,16 @@ func (h *Handler) CreateWallet(name, passphrase string) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	h.loggedWallets.AddPendingLogin(name)
 
 	return recoveryPhrase, nil
 }
 
 func (h *Handler) ImportWallet(name, passphrase, recoveryPhrase string, version uint32) error {
+	if err := wallet.ValidateName(name); err != nil {
+		return err
+	}
+
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
@@ -87,15 +157,57 @@ func (h *Handler) ImportWallet(name, passphrase, recoveryPhrase string, version
 		return err
 	}
 
-	return h.saveWallet(w, passphrase)
+	if err := h.saveWallet(w, passphrase); err != nil {
+		return err
+	}
+	h.loggedWallets.AddPendingLogin(name)
+
+	return nil
 }
 
-func (h *Handler) LoginWallet(name, passphrase string) error {
+// LoginWallet unlocks the wallet. If the wallet is already unlocked, the
+// passphrase is still verified, and the key material is shared. When the
+// wallet has the two-factor authentication enabled, the code is verified as
+// well. The login is pending until a handle retains the wallet, so the release
+// of another handle doesn't lock the wallet in between.
+func (h *Handler) LoginWallet(name, passphrase, twoFactorCode string) error {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	if !h.store.WalletExists(name) {
-		return ErrWalletDoesNotExists
+	return h.loginWallet(name, passphrase, func(w wallet.Wallet) error {
+		codeAccepted, err := wallet.CheckTwoFactorCode(w, twoFactorCode)
+		if err != nil {
+			return err
+		}
+		if codeAccepted {
+			return h.saveWallet(w, passphrase)
+		}
+		return nil
+	})
+}
+
+// LoginAuthorisedWallet unlocks the wallet like LoginWallet, without verifying
+// its second factor. It's meant for the logins the user already authorised,
+// like the ones of the restored sessions, of the API keys, or of the local
+// commands.
+func (h *Handler) LoginAuthorisedWallet(name, passphrase string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.loginWallet(name, passphrase, func(wallet.Wallet) error {
+		return nil
+	})
+}
+
+// UnlockWallet decrypts again the logged wallet locked for inactivity. Its
+// handles are kept, so the sessions using it can carry on.
+func (h *Handler) UnlockWallet(name, passphrase string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	uw, ok := h.loggedWallets[name]
+	if !ok {
+		return wallet.ErrWalletNotLoggedIn
 	}
 
 	w, err := h.store.GetWallet(name, passphrase)
@@ -106,13 +218,195 @@ func (h *Handler) LoginWallet(name, passphrase string) error {
 		return fmt.Errorf("couldn't get wallet %s: %w", name, err)
 	}
 
-	h.loggedWallets.Add(w)
+	if uw.IsLocked() {
+		h.loggedWallets.Add(w)
+		h.trackWallet(name, passphrase)
+	}
+	uw.Touch()
 
 	return nil
 }
 
+// IsWalletUnlocked verifies the key material of the wallet is available.
+func (h *Handler) IsWalletUnlocked(name string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	_, unlocked := h.loggedWallets.Get(name)
+	return unlocked
+}
+
+// CountUnlockedWallets returns the number of logged wallets whose key
+// material is available.
+func (h *Handler) CountUnlockedWallets() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	count := 0
+	for _, uw := range h.loggedWallets {
+		if !uw.IsLocked() {
+			count++
+		}
+	}
+	return count
+}
+
+// LockIdleWallets drops the key material, and the cached passphrase, of the
+// wallets that haven't been used for the specified duration. Their handles
+// are kept, so the sessions only have to unlock them with the passphrase. The
+// pinned wallets are never locked. It returns the names of the locked wallets.
+func (h *Handler) LockIdleWallets(idleTimeout time.Duration) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	locked := []string{}
+	for name, uw := range h.loggedWallets {
+		if uw.IsLocked() || now.Sub(uw.LastUsedAt()) < idleTimeout || h.isPinned(uw) {
+			continue
+		}
+		uw.wallet = nil
+		delete(h.fingerprints, name)
+		delete(h.passphrases, name)
+		locked = append(locked, name)
+	}
+	sort.Strings(locked)
+
+	return locked
+}
+
+// LogoutWallet locks the wallet, whatever the handles retaining it.
 func (h *Handler) LogoutWallet(name string) {
-	h.loggedWallets.Remove(name)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.logoutWallet(name)
+}
+
+// RetainWallet binds the handle, usually a session ID, to the logged wallet,
+// so the wallet stays unlocked until the handle is released.
+func (h *Handler) RetainWallet(handle, name string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.retainWallet(handle, name)
+}
+
+// PinWallet retains the wallet like RetainWallet, and exempts it from the idle
+// locking as long as the handle retains it. It's meant for the headless
+// clients, like the ones of the API keys, that can't unlock the wallet.
+func (h *Handler) PinWallet(handle, name string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.retainWallet(handle, name); err != nil {
+		return err
+	}
+	h.pinnedHandles[handle] = struct{}{}
+
+	return nil
+}
+
+func (h *Handler) retainWallet(handle, name string) error {
+	uw, ok := h.loggedWallets[name]
+	if !ok {
+		return wallet.ErrWalletNotLoggedIn
+	}
+
+	if current, ok := h.handles[handle]; ok && current != name {
+		h.releaseWallet(handle)
+	}
+
+	h.handles[handle] = name
+	uw.handles[handle] = struct{}{}
+	if uw.pendingLogins > 0 {
+		uw.pendingLogins--
+	}
+
+	return nil
+}
+
+// ReleaseWallet unbinds the handle from its wallet. The wallet is locked when
+// no handle, nor pending login, retains it anymore. Releasing an unknown handle
+// does nothing.
+func (h *Handler) ReleaseWallet(handle string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.releaseWallet(handle)
+}
+
+// AbortLogin cancels a pending login of the wallet, when no session could be
+// bound to it. The wallet is locked if nothing else retains it.
+func (h *Handler) AbortLogin(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	uw, ok := h.loggedWallets[name]
+	if !ok {
+		return
+	}
+
+	if uw.pendingLogins > 0 {
+		uw.pendingLogins--
+	}
+
+	if len(uw.handles) == 0 && uw.pendingLogins == 0 {
+		h.logoutWallet(name)
+	}
+}
+
+// ReloadChangedWallets reloads the logged wallets whose file has been changed
+// outside the handler, like by the command line, so their stale version is no
+// longer used. A wallet that can't be decrypted again is logged out.
+func (h *Handler) ReloadChangedWallets() []ReloadedWallet {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	names := make([]string, 0, len(h.loggedWallets))
+	for name, uw := range h.loggedWallets {
+		// There is nothing to reload in a locked wallet.
+		if uw.IsLocked() {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	reloaded := []ReloadedWallet{}
+	for _, name := range names {
+		buf, err := h.store.GetRawWallet(name)
+		if err != nil {
+			h.logoutWallet(name)
+			reloaded = append(reloaded, ReloadedWallet{Name: name, LoggedOut: true, Reason: err})
+			continue
+		}
+
+		fingerprint := sha256.Sum256(buf)
+		if knownFingerprint, ok := h.fingerprints[name]; ok && knownFingerprint == fingerprint {
+			continue
+		}
+
+		passphrase, cached := h.passphrases[name]
+		if !cached {
+			h.logoutWallet(name)
+			reloaded = append(reloaded, ReloadedWallet{Name: name, LoggedOut: true, Reason: ErrPassphraseIsNotCached})
+			continue
+		}
+
+		w, err := h.store.GetWallet(name, passphrase)
+		if err != nil {
+			h.logoutWallet(name)
+			reloaded = append(reloaded, ReloadedWallet{Name: name, LoggedOut: true, Reason: err})
+			continue
+		}
+
+		h.loggedWallets.Add(w)
+		h.fingerprints[name] = fingerprint
+		reloaded = append(reloaded, ReloadedWallet{Name: name})
+	}
+
+	return reloaded
 }
 
 func (h *Handler) GenerateKeyPair(name, passphrase string, meta []wallet.Meta) (wallet.KeyPair, error) {
@@ -309,20 +603,107 @@ func (h *Handler) saveWallet(w wallet.Wallet, passphrase string) error {
 	}
 
 	h.loggedWallets.Add(w)
+	h.trackWallet(w.Name(), passphrase)
 
 	return nil
 }
 
+// trackWallet records the state of the wallet file, so changes made outside
+// the handler can be detected. If the file can't be read, no fingerprint is
+// recorded, and the wallet will be considered as changed.
+func (h *Handler) trackWallet(name, passphrase string) {
+	if h.cachePassphrases {
+		h.passphrases[name] = passphrase
+	}
+
+	buf, err := h.store.GetRawWallet(name)
+	if err != nil {
+		delete(h.fingerprints, name)
+		return
+	}
+	h.fingerprints[name] = sha256.Sum256(buf)
+}
+
+func (h *Handler) loginWallet(name, passphrase string, verify func(wallet.Wallet) error) error {
+	if !h.store.WalletExists(name) {
+		return ErrWalletDoesNotExists
+	}
+
+	w, err := h.store.GetWallet(name, passphrase)
+	if err != nil {
+		if errors.Is(err, wallet.ErrWrongPassphrase) {
+			return err
+		}
+		return fmt.Errorf("couldn't get wallet %s: %w", name, err)
+	}
+
+	if err := verify(w); err != nil {
+		return err
+	}
+
+	if _, unlocked := h.loggedWallets.Get(name); !unlocked {
+		h.loggedWallets.Add(w)
+		h.trackWallet(name, passphrase)
+	}
+	h.loggedWallets.AddPendingLogin(name)
+
+	return nil
+}
+
+func (h *Handler) releaseWallet(handle string) {
+	name, ok := h.handles[handle]
+	if !ok {
+		return
+	}
+	delete(h.handles, handle)
+	delete(h.pinnedHandles, handle)
+
+	uw, ok := h.loggedWallets[name]
+	if !ok {
+		return
+	}
+	delete(uw.handles, handle)
+
+	if len(uw.handles) == 0 && uw.pendingLogins == 0 {
+		h.logoutWallet(name)
+	}
+}
+
+func (h *Handler) logoutWallet(name string) {
+	if uw, ok := h.loggedWallets[name]; ok {
+		for handle := range uw.handles {
+			delete(h.handles, handle)
+			delete(h.pinnedHandles, handle)
+		}
+	}
+	h.loggedWallets.Remove(name)
+	delete(h.fingerprints, name)
+	delete(h.passphrases, name)
+}
+
+func (h *Handler) isPinned(uw *unlockedWallet) bool {
+	for handle := range uw.handles {
+		if _, ok := h.pinnedHandles[handle]; ok {
+			return true
+		}
+	}
+	return false
+}
+
 func (h *Handler) getLoggedWallet(name string) (wallet.Wallet, error) {
 	if exists := h.store.WalletExists(name); !exists {
 		return nil, ErrWalletDoesNotExists
 	}
 
-	w, loggedIn := h.loggedWallets.Get(name)
+	uw, loggedIn := h.loggedWallets[name]
 	if !loggedIn {
 		return nil, wallet.ErrWalletNotLoggedIn
 	}
-	return w, nil
+	if uw.IsLocked() {
+		return nil, wallet.ErrWalletIsLocked
+	}
+	uw.Touch()
+	return uw.wallet, nil
 }
 
 func addDefaultAlias(meta []wallet.Meta, w wallet.Wallet) []wallet.Meta {
@@ -343,19 +724,62 @@ func addDefaultAlias(meta []wallet.Meta, w wallet.Wallet) []wallet.Meta {
 	return meta
 }
 
-type wallets map[string]wallet.Wallet
+// unlockedWallet is a logged wallet, shared by the handles retaining it. Its
+// key material is dropped when locked for inactivity.
+type unlockedWallet struct {
+	wallet        wallet.Wallet
+	handles       map[string]struct{}
+	pendingLogins int
+	// lastUsedAt is a Unix time in nanoseconds. It's updated by the readers of
+	// the wallet, so it's accessed atomically.
+	lastUsedAt int64
+}
+
+func (uw *unlockedWallet) IsLocked() bool {
+	return uw.wallet == nil
+}
+
+func (uw *unlockedWallet) Touch() {
+	atomic.StoreInt64(&uw.lastUsedAt, time.Now().UnixNano())
+}
+
+func (uw *unlockedWallet) LastUsedAt() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&uw.lastUsedAt))
+}
+
+type wallets map[string]*unlockedWallet
 
 func newWallets() wallets {
-	return map[string]wallet.Wallet{}
+	return map[string]*unlockedWallet{}
 }
 
-func (w wallets) Add(wallet wallet.Wallet) {
-	w[wallet.Name()] = wallet
+// Add adds the wallet, or replaces the key material of the logged wallet
+// while keeping its handles.
+func (w wallets) Add(wal wallet.Wallet) {
+	uw, ok := w[wal.Name()]
+	if !ok {
+		uw = &unlockedWallet{
+			handles: map[string]struct{}{},
+		}
+		w[wal.Name()] = uw
+	}
+	uw.wallet = wal
+	uw.Touch()
 }
 
+func (w wallets) AddPendingLogin(name string) {
+	if uw, ok := w[name]; ok {
+		uw.pendingLogins++
+	}
+}
+
+// Get returns the wallet, only if its key material is available.
 func (w wallets) Get(name string) (wallet.Wallet, bool) {
-	wal, ok := w[name]
-	return wal, ok
+	uw, ok := w[name]
+	if !ok || uw.IsLocked() {
+		return nil, false
+	}
+	return uw.wallet, true
 }
 
 func (w wallets) Remove(name string) {
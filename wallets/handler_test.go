@@ -2,7 +2,9 @@ package wallets_test
 
 import (
 	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	commandspb "code.vegaprotocol.io/protos/vega/commands/v1"
 	walletpb "code.vegaprotocol.io/protos/vega/wallet/v1"
@@ -50,8 +52,26 @@ func TestHandler(t *testing.T) {
 	t.Run("Recreating a wallet with same name and different passphrase fails", testHandlerRecreatingWalletWithSameNameButDifferentPassphraseFails)
 	t.Run("Login to existing wallet succeeds", testHandlerLoginToExistingWalletSucceeds)
 	t.Run("Login to non-existing wallet fails", testHandlerLoginToNonExistingWalletFails)
+	t.Run("Login with two-factor code succeeds", testHandlerLoginWithTwoFactorCodeSucceeds)
+	t.Run("Login without required two-factor code fails", testHandlerLoginWithoutRequiredTwoFactorCodeFails)
+	t.Run("Login with already used two-factor code fails", testHandlerLoginWithAlreadyUsedTwoFactorCodeFails)
+	t.Run("Login of authorised wallet doesn't require two-factor code", testHandlerLoginOfAuthorisedWalletDoesNotRequireTwoFactorCode)
 	t.Run("Logout logged in wallet succeeds", testHandlerLogoutLoggedInWalletSucceeds)
 	t.Run("Logout not-logged in wallet succeeds", testHandlerLogoutNotLoggedInWalletSucceeds)
+	t.Run("Releasing a session keeps the wallet unlocked for the other sessions", testHandlerReleasingSessionKeepsWalletUnlockedForOtherSessions)
+	t.Run("Releasing the last session locks the wallet", testHandlerReleasingLastSessionLocksWallet)
+	t.Run("Retaining a logged out wallet fails", testHandlerRetainingLoggedOutWalletFails)
+	t.Run("Login to unlocked wallet with wrong passphrase fails", testHandlerLoginToUnlockedWalletWithWrongPassphraseFails)
+	t.Run("Logout locks the wallet for all the sessions", testHandlerLogoutLocksWalletForAllSessions)
+	t.Run("Concurrent sessions on one wallet are independent", testHandlerConcurrentSessionsOnOneWalletAreIndependent)
+	t.Run("Locking idle wallets keeps their sessions", testHandlerLockingIdleWalletsKeepsTheirSessions)
+	t.Run("Locking idle wallets ignores the recently used ones", testHandlerLockingIdleWalletsIgnoresRecentlyUsedOnes)
+	t.Run("Locking idle wallets ignores the pinned ones", testHandlerLockingIdleWalletsIgnoresPinnedOnes)
+	t.Run("Unlocking a locked wallet with wrong passphrase fails", testHandlerUnlockingLockedWalletWithWrongPassphraseFails)
+	t.Run("Unlocking a not-logged in wallet fails", testHandlerUnlockingNotLoggedInWalletFails)
+	t.Run("Reloading unchanged wallet does nothing", testHandlerReloadingUnchangedWalletDoesNothing)
+	t.Run("Reloading changed wallet with cached passphrase succeeds", testHandlerReloadingChangedWalletWithCachedPassphraseSucceeds)
+	t.Run("Reloading changed wallet without cached passphrase logs it out", testHandlerReloadingChangedWalletWithoutCachedPassphraseLogsItOut)
 	t.Run("Generating new key pair securely succeeds", testHandlerGeneratingNewKeyPairSecurelySucceeds)
 	t.Run("Generating new key pair securely with invalid name fails", testHandlerGeneratingNewKeyPairSecurelyWithInvalidNameFails)
 	t.Run("Generating new key pair securely without wallet fails", testHandlerGeneratingNewKeyPairSecurelyWithoutWalletFails)
@@ -329,7 +349,7 @@ func testHandlerLoginToExistingWalletSucceeds(t *testing.T) {
 	assert.NotEmpty(t, recoveryPhrase)
 
 	// then
-	err = h.LoginWallet(name, passphrase)
+	err = h.LoginWallet(name, passphrase, "")
 
 	require.NoError(t, err)
 }
@@ -343,12 +363,110 @@ func testHandlerLoginToNonExistingWalletFails(t *testing.T) {
 	name := vgrand.RandomStr(5)
 
 	// when
-	err := h.LoginWallet(name, passphrase)
+	err := h.LoginWallet(name, passphrase, "")
 
 	// then
 	assert.ErrorIs(t, err, wallets.ErrWalletDoesNotExists)
 }
 
+func testHandlerLoginWithTwoFactorCodeSucceeds(t *testing.T) {
+	h := getTestHandler(t)
+	defer h.ctrl.Finish()
+
+	// given
+	name, passphrase, tf := createWalletWithTwoFactor(t, h)
+	code, err := wallet.TOTPCode(tf.Secret, time.Now())
+	require.NoError(t, err)
+
+	// when
+	err = h.LoginWallet(name, passphrase, code)
+
+	// then
+	require.NoError(t, err)
+	assert.True(t, h.IsWalletUnlocked(name))
+}
+
+func testHandlerLoginWithoutRequiredTwoFactorCodeFails(t *testing.T) {
+	h := getTestHandler(t)
+	defer h.ctrl.Finish()
+
+	// given
+	name, passphrase, _ := createWalletWithTwoFactor(t, h)
+
+	// when
+	err := h.LoginWallet(name, passphrase, "")
+
+	// then
+	require.ErrorIs(t, err, wallet.ErrTwoFactorCodeRequired)
+	assert.False(t, h.IsWalletUnlocked(name))
+
+	// when
+	err = h.LoginWallet(name, passphrase, "000000-not-a-code")
+
+	// then
+	require.ErrorIs(t, err, wallet.ErrInvalidTwoFactorCode)
+	assert.False(t, h.IsWalletUnlocked(name))
+}
+
+func testHandlerLoginWithAlreadyUsedTwoFactorCodeFails(t *testing.T) {
+	h := getTestHandler(t)
+	defer h.ctrl.Finish()
+
+	// given
+	name, passphrase, tf := createWalletWithTwoFactor(t, h)
+	code, err := wallet.TOTPCode(tf.Secret, time.Now())
+	require.NoError(t, err)
+
+	// when
+	err = h.LoginWallet(name, passphrase, code)
+
+	// then
+	require.NoError(t, err)
+
+	// given
+	h.LogoutWallet(name)
+
+	// when
+	err = h.LoginWallet(name, passphrase, code)
+
+	// then
+	require.ErrorIs(t, err, wallet.ErrTwoFactorCodeAlreadyUsed)
+	assert.False(t, h.IsWalletUnlocked(name))
+}
+
+func testHandlerLoginOfAuthorisedWalletDoesNotRequireTwoFactorCode(t *testing.T) {
+	h := getTestHandler(t)
+	defer h.ctrl.Finish()
+
+	// given
+	name, passphrase, _ := createWalletWithTwoFactor(t, h)
+
+	// when
+	err := h.LoginAuthorisedWallet(name, passphrase)
+
+	// then
+	require.NoError(t, err)
+	assert.True(t, h.IsWalletUnlocked(name))
+}
+
+// createWalletWithTwoFactor creates a logged out wallet, with the two-factor
+// authentication enabled.
+func createWalletWithTwoFactor(t *testing.T, h *testHandler) (string, string, *wallet.TwoFactor) {
+	t.Helper()
+
+	passphrase := vgrand.RandomStr(5)
+	name := vgrand.RandomStr(5)
+	_, err := h.CreateWallet(name, passphrase)
+	require.NoError(t, err)
+	h.LogoutWallet(name)
+
+	tf, _, err := wallet.NewTwoFactor()
+	require.NoError(t, err)
+	h.store.wallets[name].SetTwoFactor(tf)
+
+	return name, passphrase, tf
+}
+
 func testHandlerLogoutLoggedInWalletSucceeds(t *testing.T) {
 	h := getTestHandler(t)
 	defer h.ctrl.Finish()
@@ -380,6 +498,375 @@ func testHandlerLogoutNotLoggedInWalletSucceeds(t *testing.T) {
 	})
 }
 
+func testHandlerReleasingSessionKeepsWalletUnlockedForOtherSessions(t *testing.T) {
+	h := getTestHandler(t)
+	defer h.ctrl.Finish()
+
+	// given
+	passphrase := vgrand.RandomStr(5)
+	name := vgrand.RandomStr(5)
+	_, err := h.CreateWallet(name, passphrase)
+	require.NoError(t, err)
+	require.NoError(t, h.RetainWallet("session-1", name))
+	require.NoError(t, h.LoginWallet(name, passphrase, ""))
+	require.NoError(t, h.RetainWallet("session-2", name))
+
+	// when
+	h.ReleaseWallet("session-1")
+
+	// then
+	keys, err := h.ListPublicKeys(name)
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func testHandlerReleasingLastSessionLocksWallet(t *testing.T) {
+	h := getTestHandler(t)
+	defer h.ctrl.Finish()
+
+	// given
+	passphrase := vgrand.RandomStr(5)
+	name := vgrand.RandomStr(5)
+	_, err := h.CreateWallet(name, passphrase)
+	require.NoError(t, err)
+	require.NoError(t, h.RetainWallet("session-1", name))
+	require.NoError(t, h.LoginWallet(name, passphrase, ""))
+	require.NoError(t, h.RetainWallet("session-2", name))
+
+	// when
+	h.ReleaseWallet("session-1")
+	h.ReleaseWallet("session-2")
+
+	// then
+	keys, err := h.ListPublicKeys(name)
+	assert.ErrorIs(t, err, wallet.ErrWalletNotLoggedIn)
+	assert.Nil(t, keys)
+	_, cached := h.CachedPassphrase(name)
+	assert.False(t, cached)
+}
+
+func testHandlerRetainingLoggedOutWalletFails(t *testing.T) {
+	h := getTestHandler(t)
+	defer h.ctrl.Finish()
+
+	// given
+	passphrase := vgrand.RandomStr(5)
+	name := vgrand.RandomStr(5)
+	_, err := h.CreateWallet(name, passphrase)
+	require.NoError(t, err)
+	h.LogoutWallet(name)
+
+	// when
+	err = h.RetainWallet("session-1", name)
+
+	// then
+	assert.ErrorIs(t, err, wallet.ErrWalletNotLoggedIn)
+}
+
+func testHandlerLoginToUnlockedWalletWithWrongPassphraseFails(t *testing.T) {
+	h := getTestHandler(t)
+	defer h.ctrl.Finish()
+
+	// given
+	passphrase := vgrand.RandomStr(5)
+	name := vgrand.RandomStr(5)
+	_, err := h.CreateWallet(name, passphrase)
+	require.NoError(t, err)
+	require.NoError(t, h.RetainWallet("session-1", name))
+
+	// when
+	err = h.LoginWallet(name, vgrand.RandomStr(5), "")
+
+	// then
+	assert.Error(t, err)
+}
+
+func testHandlerLogoutLocksWalletForAllSessions(t *testing.T) {
+	h := getTestHandler(t)
+	defer h.ctrl.Finish()
+
+	// given
+	passphrase := vgrand.RandomStr(5)
+	name := vgrand.RandomStr(5)
+	_, err := h.CreateWallet(name, passphrase)
+	require.NoError(t, err)
+	require.NoError(t, h.RetainWallet("session-1", name))
+	require.NoError(t, h.LoginWallet(name, passphrase, ""))
+	require.NoError(t, h.RetainWallet("session-2", name))
+
+	// when
+	h.LogoutWallet(name)
+
+	// then
+	_, err = h.ListPublicKeys(name)
+	assert.ErrorIs(t, err, wallet.ErrWalletNotLoggedIn)
+	assert.NotPanics(t, func() {
+		h.ReleaseWallet("session-1")
+		h.ReleaseWallet("session-2")
+	})
+}
+
+func testHandlerConcurrentSessionsOnOneWalletAreIndependent(t *testing.T) {
+	h := getTestHandler(t)
+	defer h.ctrl.Finish()
+
+	// given
+	passphrase := vgrand.RandomStr(5)
+	name := vgrand.RandomStr(5)
+	_, err := h.CreateWallet(name, passphrase)
+	require.NoError(t, err)
+	key, err := h.SecureGenerateKeyPair(name, passphrase, []wallet.Meta{})
+	require.NoError(t, err)
+	require.NoError(t, h.RetainWallet("long-lived-session", name))
+
+	// when
+	sessionsCount := 20
+	errs := make(chan error, sessionsCount)
+	var wg sync.WaitGroup
+	for i := 0; i < sessionsCount; i++ {
+		wg.Add(1)
+		go func(handle string) {
+			defer wg.Done()
+			if err := h.LoginWallet(name, passphrase, ""); err != nil {
+				errs <- err
+				return
+			}
+			if err := h.RetainWallet(handle, name); err != nil {
+				errs <- err
+				return
+			}
+			if _, err := h.SignAny(name, []byte("data"), key); err != nil {
+				errs <- err
+			}
+			h.ReleaseWallet(handle)
+		}(fmt.Sprintf("session-%d", i))
+	}
+	wg.Wait()
+	close(errs)
+
+	// then
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	_, err = h.SignAny(name, []byte("data"), key)
+	require.NoError(t, err)
+
+	// when
+	h.ReleaseWallet("long-lived-session")
+
+	// then
+	_, err = h.SignAny(name, []byte("data"), key)
+	assert.ErrorIs(t, err, wallet.ErrWalletNotLoggedIn)
+}
+
+func testHandlerLockingIdleWalletsKeepsTheirSessions(t *testing.T) {
+	h := getTestHandler(t)
+	defer h.ctrl.Finish()
+
+	// given
+	passphrase := vgrand.RandomStr(5)
+	name := vgrand.RandomStr(5)
+	_, err := h.CreateWallet(name, passphrase)
+	require.NoError(t, err)
+	require.NoError(t, h.RetainWallet("session-1", name))
+
+	// when
+	locked := h.LockIdleWallets(0)
+
+	// then
+	assert.Equal(t, []string{name}, locked)
+	assert.False(t, h.IsWalletUnlocked(name))
+	_, err = h.ListPublicKeys(name)
+	assert.ErrorIs(t, err, wallet.ErrWalletIsLocked)
+
+	// when
+	err = h.UnlockWallet(name, passphrase)
+
+	// then
+	require.NoError(t, err)
+	assert.True(t, h.IsWalletUnlocked(name))
+	_, err = h.ListPublicKeys(name)
+	require.NoError(t, err)
+}
+
+func testHandlerLockingIdleWalletsIgnoresRecentlyUsedOnes(t *testing.T) {
+	h := getTestHandler(t)
+	defer h.ctrl.Finish()
+
+	// given
+	passphrase := vgrand.RandomStr(5)
+	name := vgrand.RandomStr(5)
+	_, err := h.CreateWallet(name, passphrase)
+	require.NoError(t, err)
+	require.NoError(t, h.RetainWallet("session-1", name))
+
+	// when
+	locked := h.LockIdleWallets(time.Hour)
+
+	// then
+	assert.Empty(t, locked)
+	assert.True(t, h.IsWalletUnlocked(name))
+}
+
+func testHandlerLockingIdleWalletsIgnoresPinnedOnes(t *testing.T) {
+	h := getTestHandler(t)
+	defer h.ctrl.Finish()
+
+	// given
+	passphrase := vgrand.RandomStr(5)
+	name := vgrand.RandomStr(5)
+	_, err := h.CreateWallet(name, passphrase)
+	require.NoError(t, err)
+	require.NoError(t, h.RetainWallet("session-1", name))
+	require.NoError(t, h.PinWallet("api-key-1", name))
+
+	// when
+	locked := h.LockIdleWallets(0)
+
+	// then
+	assert.Empty(t, locked)
+	assert.True(t, h.IsWalletUnlocked(name))
+
+	// given
+	h.ReleaseWallet("api-key-1")
+
+	// when
+	locked = h.LockIdleWallets(0)
+
+	// then
+	assert.Equal(t, []string{name}, locked)
+	assert.False(t, h.IsWalletUnlocked(name))
+}
+
+func testHandlerUnlockingLockedWalletWithWrongPassphraseFails(t *testing.T) {
+	h := getTestHandler(t)
+	defer h.ctrl.Finish()
+
+	// given
+	passphrase := vgrand.RandomStr(5)
+	name := vgrand.RandomStr(5)
+	_, err := h.CreateWallet(name, passphrase)
+	require.NoError(t, err)
+	require.NoError(t, h.RetainWallet("session-1", name))
+	h.LockIdleWallets(0)
+
+	// when
+	err = h.UnlockWallet(name, vgrand.RandomStr(5))
+
+	// then
+	assert.Error(t, err)
+	assert.False(t, h.IsWalletUnlocked(name))
+}
+
+func testHandlerUnlockingNotLoggedInWalletFails(t *testing.T) {
+	h := getTestHandler(t)
+	defer h.ctrl.Finish()
+
+	// given
+	passphrase := vgrand.RandomStr(5)
+	name := vgrand.RandomStr(5)
+	_, err := h.CreateWallet(name, passphrase)
+	require.NoError(t, err)
+	h.LogoutWallet(name)
+
+	// when
+	err = h.UnlockWallet(name, passphrase)
+
+	// then
+	assert.ErrorIs(t, err, wallet.ErrWalletNotLoggedIn)
+}
+
+func testHandlerReloadingUnchangedWalletDoesNothing(t *testing.T) {
+	h := getTestHandler(t)
+	defer h.ctrl.Finish()
+
+	// given
+	passphrase := vgrand.RandomStr(5)
+	name := vgrand.RandomStr(5)
+	_, err := h.CreateWallet(name, passphrase)
+	require.NoError(t, err)
+	err = h.LoginWallet(name, passphrase, "")
+	require.NoError(t, err)
+	key, err := h.SecureGenerateKeyPair(name, passphrase, []wallet.Meta{})
+	require.NoError(t, err)
+
+	// when
+	reloaded := h.ReloadChangedWallets()
+
+	// then
+	assert.Empty(t, reloaded)
+	keys, err := h.ListPublicKeys(name)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, key, keys[0].Key())
+}
+
+func testHandlerReloadingChangedWalletWithCachedPassphraseSucceeds(t *testing.T) {
+	h := getTestHandler(t)
+	defer h.ctrl.Finish()
+
+	// given
+	h.EnablePassphraseCaching()
+	passphrase := vgrand.RandomStr(5)
+	name := vgrand.RandomStr(5)
+	_, err := h.CreateWallet(name, passphrase)
+	require.NoError(t, err)
+	err = h.LoginWallet(name, passphrase, "")
+	require.NoError(t, err)
+
+	// when
+	changedWallet := changeWalletOutsideHandler(t, h, name)
+	reloaded := h.ReloadChangedWallets()
+
+	// then
+	assert.Equal(t, []wallets.ReloadedWallet{{Name: name}}, reloaded)
+	keys, err := h.ListPublicKeys(name)
+	require.NoError(t, err)
+	assert.Equal(t, changedWallet.ListPublicKeys(), keys)
+}
+
+func testHandlerReloadingChangedWalletWithoutCachedPassphraseLogsItOut(t *testing.T) {
+	h := getTestHandler(t)
+	defer h.ctrl.Finish()
+
+	// given
+	passphrase := vgrand.RandomStr(5)
+	name := vgrand.RandomStr(5)
+	_, err := h.CreateWallet(name, passphrase)
+	require.NoError(t, err)
+	err = h.LoginWallet(name, passphrase, "")
+	require.NoError(t, err)
+
+	// when
+	changeWalletOutsideHandler(t, h, name)
+	reloaded := h.ReloadChangedWallets()
+
+	// then
+	assert.Equal(t, []wallets.ReloadedWallet{{
+		Name:      name,
+		LoggedOut: true,
+		Reason:    wallets.ErrPassphraseIsNotCached,
+	}}, reloaded)
+	keys, err := h.ListPublicKeys(name)
+	assert.ErrorIs(t, err, wallet.ErrWalletNotLoggedIn)
+	assert.Nil(t, keys)
+}
+
+// changeWalletOutsideHandler replaces the wallet in the store, as the command
+// line would do while the wallet is logged in the handler.
+func changeWalletOutsideHandler(t *testing.T, h *testHandler, name string) *wallet.HDWallet {
+	t.Helper()
+	w, err := wallet.ImportHDWallet(name, TestRecoveryPhrase2, wallet.LatestVersion)
+	if err != nil {
+		t.Fatalf("couldn't import wallet: %v", err)
+	}
+	if _, err := w.GenerateKeyPair([]wallet.Meta{}); err != nil {
+		t.Fatalf("couldn't generate key pair: %v", err)
+	}
+	h.store.wallets[name] = w
+	return w
+}
+
 func testHandlerGeneratingNewKeyPairSecurelySucceeds(t *testing.T) {
 	h := getTestHandler(t)
 	defer h.ctrl.Finish()
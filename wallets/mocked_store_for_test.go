@@ -1,6 +1,7 @@
 package wallets_test
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 
@@ -52,6 +53,14 @@ func (m *mockedStore) GetWallet(name, passphrase string) (wallet.Wallet, error)
 	return w, nil
 }
 
+func (m *mockedStore) GetRawWallet(name string) ([]byte, error) {
+	w, ok := m.wallets[name]
+	if !ok {
+		return nil, wallets.ErrWalletDoesNotExists
+	}
+	return json.Marshal(w)
+}
+
 func (m *mockedStore) GetWalletPath(name string) string {
 	return fmt.Sprintf("some/path/%v", name)
 }
@@ -16,3 +16,10 @@ func InitialiseStore(vegaHome string) (*wstorev1.Store, error) {
 	}
 	return wstorev1.InitialiseStore(walletsHome)
 }
+
+// InitialiseReadOnlyStore builds a wallet Store for users wallets that never
+// modifies the wallets folder.
+func InitialiseReadOnlyStore(vegaHome string) *wstorev1.Store {
+	p := paths.New(vegaHome)
+	return wstorev1.InitialiseReadOnlyStore(p.DataPathFor(paths.WalletsDataHome))
+}